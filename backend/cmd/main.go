@@ -32,6 +32,24 @@ func main() {
 		}
 	}()
 
+	// Retry undelivered emails in the background until shutdown
+	mailCtx, stopMail := context.WithCancel(context.Background())
+	defer stopMail()
+	go app.Mailer.Run(mailCtx, cfg.Mail.RetryInterval)
+
+	// Run background jobs until shutdown, jobsDone closes once the runs in flight finish
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	jobsDone := make(chan struct{})
+	if cfg.Jobs.Enabled {
+		go func() {
+			app.Jobs.Run(jobsCtx)
+			close(jobsDone)
+		}()
+	} else {
+		close(jobsDone)
+	}
+
 	port := cfg.Application.Port
 
 	// Listen for connections with a goroutine
@@ -48,6 +66,8 @@ func main() {
 	<-quit
 
 	slog.Info("Shutting down server")
+	stopMail()
+	stopJobs()
 
 	// Shutdown server with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -57,5 +77,12 @@ func main() {
 		slog.Error("failed to shutdown server gracefully", "error", err)
 	}
 
+	// Let the job runs in flight finish, unfinished ones are retried once their lease runs out
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		slog.Warn("Background jobs did not finish before shutdown")
+	}
+
 	slog.Info("Server shutdown complete")
 }
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"specialstandard/internal/config"
+	"specialstandard/internal/errs"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+// SupabaseCreateUser creates a confirmed Supabase user with the admin API. It is for users
+// whose email is already proven, such as therapists accepting an emailed invitation.
+func SupabaseCreateUser(cfg *config.Supabase, email, password string) (uuid.UUID, error) {
+	if err := validatePasswordStrength(password); err != nil {
+		return uuid.Nil, errs.BadRequest(fmt.Sprintf("Weak Password: %v", err))
+	}
+
+	supabaseURL := cfg.URL
+	serviceRoleKey := cfg.ServiceRoleKey
+
+	payload := struct {
+		Email        string `json:"email"`
+		Password     string `json:"password"`
+		EmailConfirm bool   `json:"email_confirm"`
+	}{
+		Email:        email,
+		Password:     password,
+		EmailConfirm: true,
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/auth/v1/admin/users", supabaseURL), bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return uuid.Nil, errs.BadRequest(fmt.Sprintf("Failed to create request: %v", err))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", serviceRoleKey))
+	req.Header.Set("apikey", serviceRoleKey)
+
+	res, err := Client.Do(req)
+	if err != nil {
+		return uuid.Nil, errs.InternalServerError(fmt.Sprintf("Failed to execute request: %v", err))
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return uuid.Nil, errs.BadRequest("failed to read response body")
+	}
+
+	// GoTrue answers 422 both for a taken email and for passwords its own policy rejects
+	if res.StatusCode == http.StatusUnprocessableEntity {
+		if strings.Contains(string(body), "email_exists") || strings.Contains(string(body), "already been registered") {
+			return uuid.Nil, errs.Conflict("An account with this email already exists")
+		}
+		return uuid.Nil, errs.BadRequest(fmt.Sprintf("failed to create user: %s", body))
+	}
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return uuid.Nil, errs.InternalServerError(fmt.Sprintf("failed to create user, status: %d, response: %s", res.StatusCode, string(body)))
+	}
+
+	var user struct {
+		ID uuid.UUID `json:"id"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil || user.ID == uuid.Nil {
+		return uuid.Nil, errs.InternalServerError("failed to parse response body")
+	}
+
+	return user.ID, nil
+}
@@ -8,6 +8,8 @@ import (
 	"specialstandard/internal/errs"
 )
 
+// SupabaseDeleteAccount removes the Supabase user. A user that is already gone counts as
+// deleted so a retried account deletion can finish.
 func SupabaseDeleteAccount(cfg *config.Supabase, userID string) error {
 	supabaseURL := cfg.URL
 	serviceRoleKey := cfg.ServiceRoleKey
@@ -21,8 +23,7 @@ func SupabaseDeleteAccount(cfg *config.Supabase, userID string) error {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", serviceRoleKey))
 	req.Header.Set("apikey", serviceRoleKey)
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+	res, err := Client.Do(req)
 	if err != nil {
 		return errs.BadRequest(fmt.Sprintf("Failed to execute request: %v", err))
 	}
@@ -35,6 +36,10 @@ func SupabaseDeleteAccount(cfg *config.Supabase, userID string) error {
 		return errs.BadRequest("failed to read response body")
 	}
 
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
 	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
 		return errs.BadRequest(fmt.Sprintf("failed to delete account, status: %d, response: %s", res.StatusCode, string(body)))
 	}
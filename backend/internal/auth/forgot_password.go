@@ -11,36 +11,42 @@ import (
 	"github.com/goccy/go-json"
 )
 
-func SupabaseForgotPassword(cfg *config.Supabase, email string, redirectURL string) error {
+// SupabaseGenerateRecoveryLink asks Supabase for a password recovery link without having
+// Supabase send it, so the email goes out through our own mailer. An empty link and no
+// error means there is no user with that email.
+func SupabaseGenerateRecoveryLink(cfg *config.Supabase, email string, redirectURL string) (string, error) {
 	supbaseURL := cfg.URL
 	apiKey := cfg.ServiceRoleKey
 
 	payload := struct {
-		Email string `json:"email"`
+		Type       string `json:"type"`
+		Email      string `json:"email"`
+		RedirectTo string `json:"redirect_to"`
 	}{
-		Email: email,
+		Type:       "recovery",
+		Email:      email,
+		RedirectTo: redirectURL,
 	}
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/auth/v1/recover", supbaseURL), bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/auth/v1/admin/generate_link", supbaseURL), bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		fmt.Printf("Failed to create request: %v\n", err)
-		return errs.BadRequest(fmt.Sprintf("failed to create request: %v", err))
+		return "", errs.BadRequest(fmt.Sprintf("failed to create request: %v", err))
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("apikey", apiKey)
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
-	req.Header.Set("redirect_to", redirectURL)
 
 	res, err := Client.Do(req)
 	if err != nil {
 		fmt.Printf("Failed to execute request: %v\n", err)
-		return errs.BadRequest(fmt.Sprintf("failed to execute request: %v", err))
+		return "", errs.BadRequest(fmt.Sprintf("failed to execute request: %v", err))
 	}
 	defer func() {
 		_ = res.Body.Close()
@@ -49,13 +55,32 @@ func SupabaseForgotPassword(cfg *config.Supabase, email string, redirectURL stri
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		fmt.Printf("Failed to read response body: %v\n", err)
-		return errs.BadRequest("failed to read response body")
+		return "", errs.BadRequest("failed to read response body")
+	}
+
+	// Unknown emails must look like known ones to the caller
+	if res.StatusCode == http.StatusNotFound {
+		return "", nil
 	}
 
 	if res.StatusCode != http.StatusOK {
 		fmt.Printf("Failed to initiate password reset: %d, %s\n", res.StatusCode, body)
-		return errs.BadRequest(fmt.Sprintf("failed to initiate password reset %d, %s", res.StatusCode, body))
+		return "", errs.BadRequest(fmt.Sprintf("failed to initiate password reset %d, %s", res.StatusCode, body))
+	}
+
+	// Newer GoTrue versions return the link at the top level, older ones under properties
+	var link struct {
+		ActionLink string `json:"action_link"`
+		Properties struct {
+			ActionLink string `json:"action_link"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(body, &link); err != nil {
+		return "", errs.BadRequest("failed to parse response body")
 	}
 
-	return nil
+	if link.ActionLink != "" {
+		return link.ActionLink, nil
+	}
+	return link.Properties.ActionLink, nil
 }
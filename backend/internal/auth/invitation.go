@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// Invitation tokens are only good for accepting an invitation
+const invitationAudience = "invitation"
+
+var ErrInvitationExpired = errors.New("invitation has expired")
+
+// SignInvitation issues the token an invitation email links to. It names the invitation,
+// which is looked up on accept, so revoking the invitation also disables the token.
+func SignInvitation(key string, id uuid.UUID, email string, expiresAt time.Time) (string, error) {
+	if key == "" {
+		return "", errors.New("INVITATION_SIGNING_KEY is not configured")
+	}
+
+	claims := jwt.RegisteredClaims{
+		ID:        id.String(),
+		Subject:   email,
+		Audience:  jwt.ClaimStrings{invitationAudience},
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+}
+
+// ParseInvitation checks the signature and expiry of an invitation token and returns the
+// invitation it names
+func ParseInvitation(key, token string) (uuid.UUID, error) {
+	if key == "" {
+		return uuid.Nil, errors.New("INVITATION_SIGNING_KEY is not configured")
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"HS256"}))
+
+	parsed, err := parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(key), nil
+	})
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return uuid.Nil, ErrInvitationExpired
+	}
+	if err != nil || !parsed.Valid {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !claims.VerifyAudience(invitationAudience, true) {
+		return uuid.Nil, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Minimum time between two fetches of the key set, so that a flood of tokens with made up
+// kids (or an unreachable Supabase) does not turn into one HTTP call per request
+const jwksMinRefetchInterval = 30 * time.Second
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// JWKSCache keeps the public keys published by Supabase in memory and refreshes them
+// periodically (and whenever a token is signed with a key we have not seen yet).
+type JWKSCache struct {
+	url             string
+	apiKey          string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+func NewJWKSCache(url, apiKey string, refreshInterval time.Duration) *JWKSCache {
+	if refreshInterval <= 0 {
+		refreshInterval = 10 * time.Minute
+	}
+
+	return &JWKSCache{
+		url:             url,
+		apiKey:          apiKey,
+		refreshInterval: refreshInterval,
+		client:          Client,
+		keys:            map[string]crypto.PublicKey{},
+	}
+}
+
+// Key returns the public key for the given key id, fetching the key set when it is stale
+// or does not contain the kid yet.
+func (c *JWKSCache) Key(kid string) (crypto.PublicKey, error) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	stale := time.Since(c.fetchedAt) > c.refreshInterval
+	c.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if err := c.refresh(!ok); err != nil {
+		// Serve the key we already know about rather than failing every request while
+		// Supabase is unreachable
+		if ok {
+			slog.Warn("Failed to refresh JWKS, using cached key", "err", err)
+			return key, nil
+		}
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok = c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("no signing key found for kid %q", kid)
+	}
+	return key, nil
+}
+
+func (c *JWKSCache) refresh(unknownKid bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another goroutine may have refreshed while we were waiting for the lock
+	if time.Since(c.fetchedAt) <= c.refreshInterval && !unknownKid {
+		return nil
+	}
+	if time.Since(c.lastAttempt) < jwksMinRefetchInterval {
+		return nil
+	}
+	c.lastAttempt = time.Now()
+
+	req, err := http.NewRequest("GET", c.url, nil)
+	if err != nil {
+		return err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("apikey", c.apiKey)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS, status: %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var set jsonWebKeySet
+	if err := json.Unmarshal(body, &set); err != nil {
+		return fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			slog.Warn("Skipping unsupported JWK", "kid", jwk.Kid, "err", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("missing key component")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -39,7 +39,7 @@ func SupabaseLogin(cfg *config.Supabase, email string, password string, needsEma
 	res, err := Client.Do(req)
 	if err != nil {
 		slog.Error("Failed to execute Request", "err", err)
-		return models.SignInResponse{}, errs.BadRequest("Failed to execute Request")
+		return models.SignInResponse{}, errs.InternalServerError("Failed to execute Request")
 	}
 	defer func() {
 		_ = res.Body.Close()
@@ -48,7 +48,7 @@ func SupabaseLogin(cfg *config.Supabase, email string, password string, needsEma
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		slog.Error("Failed to read response body", "err", err)
-		return models.SignInResponse{}, errs.BadRequest("Failed to read response body")
+		return models.SignInResponse{}, errs.InternalServerError("Failed to read response body")
 	}
 
 	if res.StatusCode != http.StatusOK {
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"specialstandard/internal/config"
+	"specialstandard/internal/errs"
+)
+
+// ErrSessionExpired is returned by SupabaseLogout when the access token is no longer
+// accepted, in which case the session has to be revoked through a fresh token
+var ErrSessionExpired = errs.Unauthorized("Session expired")
+
+// SupabaseLogout revokes the Supabase session (and with it the refresh token) that the
+// access token belongs to. Scope is one of local, global or others.
+func SupabaseLogout(cfg *config.Supabase, accessToken, scope string) error {
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/auth/v1/logout?scope=%s", cfg.URL, scope), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	req.Header.Set("apikey", cfg.ServiceRoleKey)
+
+	res, err := Client.Do(req)
+	if err != nil {
+		return errs.InternalServerError(fmt.Sprintf("Failed to execute request: %v", err))
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrSessionExpired
+	default:
+		body, _ := io.ReadAll(res.Body)
+		return errs.InternalServerError(fmt.Sprintf("failed to log out, status: %d, response: %s", res.StatusCode, string(body)))
+	}
+}
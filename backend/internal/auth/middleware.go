@@ -1,78 +1,157 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"specialstandard/internal/config"
 	"specialstandard/internal/models"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
-func Middleware(cfg *config.Supabase) fiber.Handler {
+// Supabase marks sessions that passed one of its own second factors with this level
+const aalMFA = "aal2"
+
+// MFASessionChecker tells whether a Supabase session may be used by a therapist who has an
+// authenticator app: it has to be one that passed the app at /auth/mfa/verify
+type MFASessionChecker interface {
+	SessionPassedMFA(ctx context.Context, therapistID, sessionID uuid.UUID) (bool, error)
+}
+
+// Middleware authenticates requests with a Supabase access token or, when keys is not nil,
+// an API key in the X-API-Key header. When sessions is not nil, tokens of therapists with
+// an authenticator app are only accepted for sessions that passed it.
+func Middleware(cfg *config.Supabase, keys APIKeyAuthenticator, sessions MFASessionChecker) fiber.Handler {
+	verifier := NewTokenVerifier(cfg)
+
 	return func(c *fiber.Ctx) error {
-		var token string
-
-		// First, check Authorization header
-		authHeader := c.Get("Authorization")
-		if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
-			token = strings.TrimPrefix(authHeader, "Bearer ")
-			fmt.Println("Token found in Authorization header")
-		} else {
-			// Fallback to cookie if no Authorization header
-			token = c.Cookies("jwt", "")
-			if token != "" {
-				fmt.Println("Token found in cookie")
-			}
+		if key := c.Get(APIKeyHeader); key != "" {
+			return authenticateAPIKey(c, keys, key)
 		}
 
+		token := extractToken(c)
+
 		// If no token found in either place
 		if token == "" {
 			fmt.Println("JWT Not Found in Middleware")
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token Not Found"})
 		}
 
-		// Validate token with Supabase
-		req, err := http.NewRequest("GET", fmt.Sprintf("%s/auth/v1/user", cfg.URL), nil)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create request"})
+		if cfg.RemoteTokenValidation {
+			return validateRemote(c, cfg, sessions, token)
 		}
 
-		req.Header.Set("Authorization", "Bearer "+token)
-		req.Header.Set("apikey", cfg.ServiceRoleKey)
-
-		res, err := Client.Do(req)
+		claims, err := verifier.Verify(token)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate token"})
-		}
-		defer func() {
-			_ = res.Body.Close()
-		}()
-
-		if res.StatusCode != http.StatusOK {
+			slog.Debug("Rejected access token", "err", err)
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid/Expired Token"})
 		}
 
-		// Read and parse the response body to get user data
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read response"})
-		}
-
-		var user models.SupabaseUser
-		if err := json.Unmarshal(body, &user); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse user data"})
+		if err := checkMFASession(c, sessions, claims.Subject, claims); err != nil {
+			return err
 		}
 
 		// Store user ID in context for handlers to use
-		c.Locals("userID", user.ID)
-		
+		c.Locals("userID", claims.Subject)
+
 		// Optionally store email too if handlers need it
-		c.Locals("email", user.Email)
+		c.Locals("email", claims.Email)
+		c.Locals("sessionID", claims.SessionID)
 
 		return c.Next()
 	}
-}
\ No newline at end of file
+}
+
+func extractToken(c *fiber.Ctx) string {
+	// First, check Authorization header
+	authHeader := c.Get("Authorization")
+	if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+
+	// Fallback to cookie if no Authorization header
+	return c.Cookies("jwt", "")
+}
+
+// validateRemote asks Supabase about the token instead of checking it locally
+func validateRemote(c *fiber.Ctx, cfg *config.Supabase, sessions MFASessionChecker, token string) error {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/auth/v1/user", cfg.URL), nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create request"})
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("apikey", cfg.ServiceRoleKey)
+
+	res, err := Client.Do(req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate token"})
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid/Expired Token"})
+	}
+
+	// Read and parse the response body to get user data
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read response"})
+	}
+
+	var user models.SupabaseUser
+	if err := json.Unmarshal(body, &user); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse user data"})
+	}
+
+	if sessions != nil {
+		// Supabase accepted the token, so its claims can be trusted
+		claims, err := UnverifiedClaims(token)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid/Expired Token"})
+		}
+		if err := checkMFASession(c, sessions, user.ID, claims); err != nil {
+			return err
+		}
+		c.Locals("sessionID", claims.SessionID)
+	}
+
+	c.Locals("userID", user.ID)
+	c.Locals("email", user.Email)
+
+	return c.Next()
+}
+
+// checkMFASession stops a password-only session, for example one from a password grant
+// made straight against Supabase, from being used by a therapist with an authenticator app
+func checkMFASession(c *fiber.Ctx, sessions MFASessionChecker, subject string, claims *Claims) error {
+	if sessions == nil || claims.AAL == aalMFA {
+		return nil
+	}
+
+	therapistID, err := uuid.Parse(subject)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid/Expired Token"})
+	}
+	// A missing or malformed session is never recorded as verified
+	sessionID, _ := uuid.Parse(claims.SessionID)
+
+	passed, err := sessions.SessionPassedMFA(c.Context(), therapistID, sessionID)
+	if err != nil {
+		slog.Error("Failed to check MFA of session", "therapist_id", therapistID, "err", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate token"})
+	}
+	if !passed {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Second factor required"})
+	}
+
+	return nil
+}
@@ -0,0 +1,403 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"specialstandard/internal/config"
+	"specialstandard/internal/models"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "super-secret-jwt-token-with-at-least-32-characters"
+
+func mintToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() Claims {
+	return Claims{
+		Email: "therapist@example.com",
+		Role:  "authenticated",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "f20e5948-01ba-4113-b453-db05d8bde3bc",
+			Audience:  jwt.ClaimStrings{"authenticated"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+}
+
+func newTestApp(cfg *config.Supabase) *fiber.App {
+	app := fiber.New()
+	app.Use(Middleware(cfg, nil, nil))
+	app.Get("/me", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"userID": c.Locals("userID"),
+			"email":  c.Locals("email"),
+		})
+	})
+	return app
+}
+
+func doRequest(t *testing.T, app *fiber.App, token string, asCookie bool) (int, map[string]string) {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	if token != "" {
+		if asCookie {
+			req.AddCookie(&http.Cookie{Name: "jwt", Value: token})
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	res, err := app.Test(req, -1)
+	require.NoError(t, err)
+	defer func() { _ = res.Body.Close() }()
+
+	body := map[string]string{}
+	_ = json.NewDecoder(res.Body).Decode(&body)
+	return res.StatusCode, body
+}
+
+func TestMiddleware_HS256(t *testing.T) {
+	// Any call to Supabase would fail the test: the point is to validate locally
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	app := newTestApp(&config.Supabase{URL: ts.URL, JWTSecret: testSecret})
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+	wrongAudience := validClaims()
+	wrongAudience.Audience = jwt.ClaimStrings{"anon"}
+
+	noSubject := validClaims()
+	noSubject.Subject = ""
+
+	tests := []struct {
+		name           string
+		token          string
+		asCookie       bool
+		expectedStatus int
+	}{
+		{
+			name:           "valid token in header",
+			token:          mintToken(t, jwt.SigningMethodHS256, []byte(testSecret), "", validClaims()),
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "valid token in cookie",
+			token:          mintToken(t, jwt.SigningMethodHS256, []byte(testSecret), "", validClaims()),
+			asCookie:       true,
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "missing token",
+			token:          "",
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "wrong secret",
+			token:          mintToken(t, jwt.SigningMethodHS256, []byte("not-the-secret"), "", validClaims()),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "expired token",
+			token:          mintToken(t, jwt.SigningMethodHS256, []byte(testSecret), "", expired),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "wrong audience",
+			token:          mintToken(t, jwt.SigningMethodHS256, []byte(testSecret), "", wrongAudience),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "missing subject",
+			token:          mintToken(t, jwt.SigningMethodHS256, []byte(testSecret), "", noSubject),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "garbage token",
+			token:          "not.a.jwt",
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := doRequest(t, app, tt.token, tt.asCookie)
+			assert.Equal(t, tt.expectedStatus, status)
+			if tt.expectedStatus == fiber.StatusOK {
+				assert.Equal(t, "f20e5948-01ba-4113-b453-db05d8bde3bc", body["userID"])
+				assert.Equal(t, "therapist@example.com", body["email"])
+			}
+		})
+	}
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestMiddleware_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	var fetches int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/auth/v1/.well-known/jwks.json", r.URL.Path)
+		atomic.AddInt32(&fetches, 1)
+		_ = json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{
+			{
+				Kid: "rsa-key",
+				Kty: "RSA",
+				Alg: "RS256",
+				Use: "sig",
+				N:   b64(rsaKey.N.Bytes()),
+				E:   b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				Kid: "ec-key",
+				Kty: "EC",
+				Alg: "ES256",
+				Use: "sig",
+				Crv: "P-256",
+				X:   b64(ecKey.X.FillBytes(make([]byte, 32))),
+				Y:   b64(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		}})
+	}))
+	defer ts.Close()
+
+	// No JWT secret configured: HS256 tokens must be rejected
+	app := newTestApp(&config.Supabase{URL: ts.URL, JWKSRefreshInterval: time.Hour})
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{
+			name:           "RS256 token signed by published key",
+			token:          mintToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-key", validClaims()),
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "ES256 token signed by published key",
+			token:          mintToken(t, jwt.SigningMethodES256, ecKey, "ec-key", validClaims()),
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "RS256 token signed by unknown key",
+			token:          mintToken(t, jwt.SigningMethodRS256, otherKey, "rsa-key", validClaims()),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "unknown kid",
+			token:          mintToken(t, jwt.SigningMethodRS256, rsaKey, "rotated-away", validClaims()),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "HS256 token without configured secret",
+			token:          mintToken(t, jwt.SigningMethodHS256, []byte(testSecret), "", validClaims()),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := doRequest(t, app, tt.token, false)
+			assert.Equal(t, tt.expectedStatus, status)
+		})
+	}
+
+	// The key set is cached: one fetch for all of the requests above
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+func TestMiddleware_RemoteValidation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/auth/v1/user", r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer opaque-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "f20e5948-01ba-4113-b453-db05d8bde3bc", "email": "therapist@example.com"}`))
+	}))
+	defer ts.Close()
+
+	app := newTestApp(&config.Supabase{URL: ts.URL, RemoteTokenValidation: true})
+
+	status, body := doRequest(t, app, "opaque-token", false)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "f20e5948-01ba-4113-b453-db05d8bde3bc", body["userID"])
+
+	status, _ = doRequest(t, app, "revoked-token", false)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+}
+
+// fakeKeys knows a single API key
+type fakeKeys struct {
+	hash string
+	key  *models.APIKey
+}
+
+func (f fakeKeys) AuthenticateAPIKey(_ context.Context, keyHash string) (*models.APIKey, error) {
+	if keyHash != f.hash {
+		return nil, pgx.ErrNoRows
+	}
+	return f.key, nil
+}
+
+func TestMiddleware_APIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, prefix))
+	assert.NotEqual(t, key, HashAPIKey(key))
+
+	owner := uuid.MustParse("f20e5948-01ba-4113-b453-db05d8bde3bc")
+	keys := fakeKeys{hash: HashAPIKey(key), key: &models.APIKey{ID: uuid.New(), CreatedBy: owner}}
+
+	app := fiber.New()
+	app.Use(Middleware(&config.Supabase{JWTSecret: testSecret}, keys, nil))
+	app.Get("/me", func(c *fiber.Ctx) error {
+		_, hasKey := c.Locals("apiKey").(*models.APIKey)
+		return c.JSON(fiber.Map{"userID": c.Locals("userID"), "apiKey": hasKey})
+	})
+
+	request := func(key string) (int, map[string]any) {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set(APIKeyHeader, key)
+		res, err := app.Test(req, -1)
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+
+		body := map[string]any{}
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		return res.StatusCode, body
+	}
+
+	status, body := request(key)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, owner.String(), body["userID"])
+	assert.Equal(t, true, body["apiKey"])
+
+	status, _ = request(key + "x")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	// Routes that do not take API keys reject them instead of falling back to the token
+	app = newTestApp(&config.Supabase{JWTSecret: testSecret})
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set(APIKeyHeader, key)
+	req.Header.Set("Authorization", "Bearer "+mintToken(t, jwt.SigningMethodHS256, []byte(testSecret), "", validClaims()))
+	res, err := app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+}
+
+// fakeSessions knows one therapist with an authenticator app and the sessions that passed it
+type fakeSessions struct {
+	enrolled uuid.UUID
+	verified uuid.UUID
+}
+
+func (f fakeSessions) SessionPassedMFA(_ context.Context, therapistID, sessionID uuid.UUID) (bool, error) {
+	return therapistID != f.enrolled || sessionID == f.verified, nil
+}
+
+func TestMiddleware_MFASession(t *testing.T) {
+	enrolled := uuid.MustParse("f20e5948-01ba-4113-b453-db05d8bde3bc")
+	verified := uuid.New()
+	sessions := fakeSessions{enrolled: enrolled, verified: verified}
+
+	app := fiber.New()
+	app.Use(Middleware(&config.Supabase{JWTSecret: testSecret}, nil, sessions))
+	app.Get("/me", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"userID": c.Locals("userID")})
+	})
+
+	claimsFor := func(subject uuid.UUID, sessionID, aal string) Claims {
+		claims := validClaims()
+		claims.Subject = subject.String()
+		claims.SessionID = sessionID
+		claims.AAL = aal
+		return claims
+	}
+
+	tests := []struct {
+		name           string
+		claims         Claims
+		expectedStatus int
+	}{
+		{
+			name:           "password grant of an enrolled therapist",
+			claims:         claimsFor(enrolled, uuid.NewString(), "aal1"),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "token without a session",
+			claims:         claimsFor(enrolled, "", "aal1"),
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:           "session that passed the authenticator app",
+			claims:         claimsFor(enrolled, verified.String(), "aal1"),
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "second factor passed at Supabase",
+			claims:         claimsFor(enrolled, uuid.NewString(), "aal2"),
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "therapist without an authenticator app",
+			claims:         claimsFor(uuid.New(), uuid.NewString(), "aal1"),
+			expectedStatus: fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := mintToken(t, jwt.SigningMethodHS256, []byte(testSecret), "", tt.claims)
+			status, _ := doRequest(t, app, token, false)
+			assert.Equal(t, tt.expectedStatus, status)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"specialstandard/internal/config"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+
+	"github.com/goccy/go-json"
+)
+
+// SupabaseRefresh exchanges a refresh token for a new session. Supabase rotates refresh
+// tokens, so the returned refresh token replaces the one that was passed in.
+func SupabaseRefresh(cfg *config.Supabase, refreshToken string) (models.SignInResponse, error) {
+	payloadBytes, err := json.Marshal(struct {
+		RefreshToken string `json:"refresh_token"`
+	}{
+		RefreshToken: refreshToken,
+	})
+	if err != nil {
+		return models.SignInResponse{}, err
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/auth/v1/token?grant_type=refresh_token", cfg.URL), bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return models.SignInResponse{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apikey", cfg.ServiceRoleKey)
+
+	res, err := Client.Do(req)
+	if err != nil {
+		slog.Error("Failed to execute Request", "err", err)
+		return models.SignInResponse{}, errs.InternalServerError("Failed to reach authentication server")
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return models.SignInResponse{}, errs.InternalServerError("Failed to read response body")
+	}
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		slog.Error("Supabase refresh failed", "status", res.StatusCode, "body", string(body))
+		return models.SignInResponse{}, errs.InternalServerError("Failed to refresh session")
+	}
+
+	// GoTrue answers 400 for unknown, revoked or already used refresh tokens
+	if res.StatusCode != http.StatusOK {
+		return models.SignInResponse{}, errs.Unauthorized("Invalid or expired refresh token")
+	}
+
+	var signInResponse models.SignInResponse
+	if err := json.Unmarshal(body, &signInResponse); err != nil {
+		slog.Error("Failed to parse response body", "body", err)
+		return models.SignInResponse{}, errs.InternalServerError("Failed to parse response body")
+	}
+
+	return signInResponse, nil
+}
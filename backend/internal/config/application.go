@@ -4,4 +4,7 @@ type Application struct {
 	Port           string `env:"PORT, default=8080"`
 	Environment    string `env:"ENVIRONMENT, default=development"`
 	AllowedOrigins string `env:"ALLOWED_ORIGINS, default=http://localhost:3000"`
+	// ProxyHeader holds the client address when running behind a load balancer (e.g.
+	// X-Forwarded-For), so per-IP lockouts do not lock out everyone behind the proxy
+	ProxyHeader string `env:"PROXY_HEADER"`
 }
@@ -0,0 +1,7 @@
+package config
+
+type Calendar struct {
+	// FeedURL is the public address of the iCalendar feed endpoint, a therapist's feed
+	// token is appended to it
+	FeedURL string `env:"CALENDAR_FEED_URL, default=http://localhost:8080/api/v1/calendar"`
+}
@@ -1,10 +1,16 @@
 package config
 
 type Config struct {
-	Application Application
-	DB          DB
-	Supabase    Supabase
-	S3Bucket    S3
-	TestMode    bool
-	Resend      Resend
+	Application  Application
+	DB           DB
+	Supabase     Supabase
+	S3Bucket     S3
+	TestMode     bool
+	Resend       Resend
+	Mail         Mail
+	MFA          MFA
+	Invitation   Invitation
+	Calendar     Calendar
+	Jobs         Jobs
+	Verification Verification
 }
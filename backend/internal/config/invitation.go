@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+type Invitation struct {
+	// SigningKey signs invitation links. Without it invitations cannot be sent.
+	SigningKey string `env:"INVITATION_SIGNING_KEY"`
+	// TTL is how long an invitation can be accepted
+	TTL time.Duration `env:"INVITATION_TTL, default=168h"`
+	// AcceptURL is the frontend page invitation emails link to, the token is appended as ?token=
+	AcceptURL string `env:"INVITATION_ACCEPT_URL, default=http://localhost:3000/accept-invite"`
+}
@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+type Jobs struct {
+	// Enabled runs background jobs in this instance, every instance may run them
+	Enabled bool `env:"JOBS_ENABLED, default=true"`
+	// PollInterval is how often the queue is checked for due runs
+	PollInterval time.Duration `env:"JOBS_POLL_INTERVAL, default=5s"`
+	// Concurrency is how many runs this instance works on at once
+	Concurrency int `env:"JOBS_CONCURRENCY, default=4"`
+	// RunRetention is how long finished runs are kept for the admin listing
+	RunRetention time.Duration `env:"JOBS_RUN_RETENTION, default=720h"`
+}
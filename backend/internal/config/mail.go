@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+type Mail struct {
+	// Backend is one of resend, smtp or local. Defaults to resend when RESEND_API_KEY is
+	// set and to local otherwise.
+	Backend string `env:"MAIL_BACKEND"`
+	// From overrides RESEND_FROM_EMAIL for every backend
+	From string `env:"MAIL_FROM"`
+
+	SMTPHost     string `env:"SMTP_HOST"`
+	SMTPPort     int    `env:"SMTP_PORT, default=587"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+
+	// OutboxDir is where the local backend writes .eml files, empty keeps them in memory
+	OutboxDir string `env:"MAIL_OUTBOX_DIR, default=tmp/mail"`
+	// RetryInterval is how often undelivered emails in the outbox are retried
+	RetryInterval time.Duration `env:"MAIL_RETRY_INTERVAL, default=1m"`
+	// OutboxRetention is how long emails sent or given up on are kept in the outbox
+	OutboxRetention time.Duration `env:"MAIL_OUTBOX_RETENTION, default=720h"`
+}
@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type MFA struct {
+	// Issuer is the name authenticator apps show next to the account
+	Issuer string `env:"MFA_ISSUER, default=The Special Standard"`
+	// EncryptionKey encrypts TOTP secrets at rest: 32 random bytes, base64 encoded. Without
+	// it authenticator apps cannot be enrolled.
+	EncryptionKey string `env:"MFA_ENCRYPTION_KEY"`
+	// ChallengeTTL is how long a password login waits for the second factor
+	ChallengeTTL time.Duration `env:"MFA_CHALLENGE_TTL, default=5m"`
+}
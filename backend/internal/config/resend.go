@@ -1,6 +1,6 @@
 package config
 
 type Resend struct {
-	APIKey    string `env:"RESEND_API_KEY,required"`
+	APIKey    string `env:"RESEND_API_KEY"`
 	FromEmail string `env:"RESEND_FROM_EMAIL,default=Kevin Matula <kevinmatula@plantkeepr.co>"`
 }
\ No newline at end of file
@@ -1,7 +1,25 @@
 package config
 
+import "time"
+
 type Supabase struct {
 	URL            string `env:"SUPABASE_URL, required"`
 	AnonKey        string `env:"SUPABASE_ANON_KEY, required"`
 	ServiceRoleKey string `env:"SUPABASE_SERVICE_ROLE_KEY, required"`
+
+	// JWTSecret is the legacy HS256 signing secret of the project. When empty only
+	// asymmetric keys published on the JWKS endpoint are accepted.
+	JWTSecret string `env:"SUPABASE_JWT_SECRET"`
+	// JWKSURL overrides the key set location, defaults to {URL}/auth/v1/.well-known/jwks.json
+	JWKSURL             string        `env:"SUPABASE_JWKS_URL"`
+	JWKSRefreshInterval time.Duration `env:"SUPABASE_JWKS_REFRESH_INTERVAL, default=10m"`
+	// RemoteTokenValidation falls back to asking Supabase (/auth/v1/user) about every token
+	RemoteTokenValidation bool `env:"SUPABASE_REMOTE_TOKEN_VALIDATION, default=false"`
+}
+
+func (s *Supabase) JWKSEndpoint() string {
+	if s.JWKSURL != "" {
+		return s.JWKSURL
+	}
+	return s.URL + "/auth/v1/.well-known/jwks.json"
 }
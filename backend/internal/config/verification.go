@@ -0,0 +1,7 @@
+package config
+
+type Verification struct {
+	// CodeKey keys the hashes email verification codes are stored as, so that a copy of
+	// the database is not enough to recover them. Without it codes cannot be sent.
+	CodeKey string `env:"VERIFICATION_CODE_KEY"`
+}
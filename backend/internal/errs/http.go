@@ -85,6 +85,14 @@ func Conflict(msg ...string) HTTPError {
 	return NewHTTPError(http.StatusConflict, fmt.Errorf("%v", msg))
 }
 
+// ConflictDetails is a 409 whose message is data the client can act on
+func ConflictDetails(details any) HTTPError {
+	return HTTPError{
+		Code:    http.StatusConflict,
+		Message: details,
+	}
+}
+
 // InvalidRequestData for validation errors
 func InvalidRequestData(errors map[string]string) HTTPError {
 	return HTTPError{
@@ -129,6 +137,24 @@ func UnprocessableEntity(msg ...string) HTTPError {
 	return NewHTTPError(http.StatusUnprocessableEntity, errors.New(message))
 }
 
+// TooManyRequests accepts optional custom message
+func TooManyRequests(msg ...string) HTTPError {
+	message := "too many requests"
+	if len(msg) > 0 && msg[0] != "" {
+		message = msg[0]
+	}
+	return NewHTTPError(http.StatusTooManyRequests, errors.New(message))
+}
+
+// UnsupportedMediaType accepts optional custom message
+func UnsupportedMediaType(msg ...string) HTTPError {
+	message := "unsupported media type"
+	if len(msg) > 0 && msg[0] != "" {
+		message = msg[0]
+	}
+	return NewHTTPError(http.StatusUnsupportedMediaType, errors.New(message))
+}
+
 // ErrorHandler remains the same
 func ErrorHandler(c *fiber.Ctx, err error) error {
 	var httpErr HTTPError
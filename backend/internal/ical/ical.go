@@ -0,0 +1,290 @@
+// Package ical reads and writes RFC 5545 iCalendar documents: the session feed that
+// calendar apps subscribe to, and calendars exported from other apps for import. Only
+// events are supported, along with the recurrence rules they use.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateTimeFormat      = "20060102T150405Z"
+	localDateTimeFormat = "20060102T150405"
+	dateFormat          = "20060102"
+	// Content lines longer than this many octets are folded
+	maxLineOctets = 75
+	// Time zones of series without an end are written for this many years, clients keep
+	// the last offset after that
+	openEndedYears = 10
+)
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+type Event struct {
+	UID          string
+	Stamp        time.Time
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	Summary      string
+	Location     string
+	Description  string
+	Status       string
+	Rule         *Rule
+	ExceptDates  []time.Time
+	ExtraDates   []time.Time
+	RecurrenceID time.Time
+	Attendees    []Attendee
+	LastModified time.Time
+	// TimeZone, when set, writes DTSTART, DTEND, EXDATE and RDATE as local times with
+	// its TZID, so a recurring event keeps its time of day across daylight saving
+	TimeZone *time.Location
+}
+
+// Attendee is an ATTENDEE of an event, Name is its CN parameter
+type Attendee struct {
+	Name  string
+	Email string
+}
+
+// Marshal renders the calendar with CRLF line endings and folded lines
+func (c *Calendar) Marshal() []byte {
+	var w writer
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", c.ProdID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", escape(c.Name))
+	}
+	c.writeTimeZones(&w)
+
+	for _, e := range c.Events {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", e.UID)
+		w.line("DTSTAMP", formatTime(e.Stamp))
+		w.line(e.zoned("DTSTART"), e.formatTimes(e.Start))
+		w.line(e.zoned("DTEND"), e.formatTimes(e.End))
+		if e.Rule != nil {
+			w.line("RRULE", e.Rule.String())
+		}
+		if len(e.ExceptDates) > 0 {
+			w.line(e.zoned("EXDATE"), e.formatTimes(e.ExceptDates...))
+		}
+		if len(e.ExtraDates) > 0 {
+			w.line(e.zoned("RDATE"), e.formatTimes(e.ExtraDates...))
+		}
+		w.line("SUMMARY", escape(e.Summary))
+		if e.Location != "" {
+			w.line("LOCATION", escape(e.Location))
+		}
+		if e.Description != "" {
+			w.line("DESCRIPTION", escape(e.Description))
+		}
+		if !e.LastModified.IsZero() {
+			w.line("LAST-MODIFIED", formatTime(e.LastModified))
+		}
+		w.line("END", "VEVENT")
+	}
+
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes()
+}
+
+// writeTimeZones writes a VTIMEZONE for every TZID the events use, as RFC 5545 requires,
+// covering the times the events span
+func (c *Calendar) writeTimeZones(w *writer) {
+	type span struct {
+		loc         *time.Location
+		from, until time.Time
+	}
+	var spans []*span
+	byName := map[string]*span{}
+	for _, e := range c.Events {
+		if e.TimeZone == nil {
+			continue
+		}
+		from, until := e.span()
+		s, ok := byName[e.TimeZone.String()]
+		if !ok {
+			s = &span{loc: e.TimeZone, from: from, until: until}
+			byName[e.TimeZone.String()] = s
+			spans = append(spans, s)
+		}
+		if from.Before(s.from) {
+			s.from = from
+		}
+		if until.After(s.until) {
+			s.until = until
+		}
+	}
+
+	for _, s := range spans {
+		writeTimeZone(w, s.loc, s.from, s.until)
+	}
+}
+
+// span is the time from the first to the last instant the event is written with
+func (e *Event) span() (from, until time.Time) {
+	from, until = e.Start, e.End
+	for _, t := range append(append([]time.Time{}, e.ExceptDates...), e.ExtraDates...) {
+		if t.Before(from) {
+			from = t
+		}
+		if t.After(until) {
+			until = t
+		}
+	}
+
+	last := until
+	switch {
+	case e.Rule == nil:
+	case !e.Rule.Until.IsZero():
+		last = e.Rule.Until.Add(e.End.Sub(e.Start))
+	case e.Rule.Count > 0:
+		if starts, _ := e.Rule.Expand(e.Start, e.Start.AddDate(100, 0, 0), e.Rule.Count); len(starts) > 0 {
+			last = starts[len(starts)-1].Add(e.End.Sub(e.Start))
+		}
+	default:
+		last = e.Start.AddDate(openEndedYears, 0, 0)
+	}
+	if last.After(until) {
+		until = last
+	}
+
+	return from, until
+}
+
+// writeTimeZone writes loc from from to until: the observance in effect at from, then one
+// for each change of offset. Go does not expose the rules of a zone, only its offsets, so
+// every change is written out instead of as a recurrence rule.
+func writeTimeZone(w *writer, loc *time.Location, from, until time.Time) {
+	w.line("BEGIN", "VTIMEZONE")
+	w.line("TZID", loc.String())
+
+	t := from.In(loc)
+	start, end := t.ZoneBounds()
+	offsetFrom := offsetAt(t)
+	if !start.IsZero() {
+		offsetFrom = offsetAt(start.Add(-time.Second))
+	} else {
+		start = t
+	}
+	writeObservance(w, start, offsetFrom)
+
+	for !end.IsZero() && !end.After(until) {
+		offsetFrom = offsetAt(end.Add(-time.Second))
+		writeObservance(w, end, offsetFrom)
+		_, end = end.ZoneBounds()
+	}
+
+	w.line("END", "VTIMEZONE")
+}
+
+// writeObservance writes the STANDARD or DAYLIGHT time that starts at t. Its DTSTART is the
+// local time before the change, as RFC 5545 defines it.
+func writeObservance(w *writer, t time.Time, offsetFrom int) {
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	name, offsetTo := t.Zone()
+
+	w.line("BEGIN", kind)
+	w.line("DTSTART", t.UTC().Add(time.Duration(offsetFrom)*time.Second).Format(localDateTimeFormat))
+	w.line("TZOFFSETFROM", formatOffset(offsetFrom))
+	w.line("TZOFFSETTO", formatOffset(offsetTo))
+	if name != "" && !strings.ContainsAny(name, "+-") {
+		w.line("TZNAME", escape(name))
+	}
+	w.line("END", kind)
+}
+
+func offsetAt(t time.Time) int {
+	_, offset := t.Zone()
+	return offset
+}
+
+// formatOffset writes an offset east of UTC in seconds as +hhmm, or +hhmmss when it has seconds
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	formatted := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+	if offset%60 != 0 {
+		formatted += fmt.Sprintf("%02d", offset%60)
+	}
+	return formatted
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// zoned adds the TZID parameter of the event to a date-time property
+func (e *Event) zoned(name string) string {
+	if e.TimeZone == nil {
+		return name
+	}
+	return name + ";TZID=" + e.TimeZone.String()
+}
+
+// formatTimes writes times in UTC, or local to the time zone of the event
+func (e *Event) formatTimes(times ...time.Time) string {
+	formatted := make([]string, len(times))
+	for i, t := range times {
+		if e.TimeZone != nil {
+			formatted[i] = t.In(e.TimeZone).Format(localDateTimeFormat)
+		} else {
+			formatted[i] = formatTime(t)
+		}
+	}
+	return strings.Join(formatted, ",")
+}
+
+// escape quotes the characters that are special in TEXT values
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+// line writes name:value, folding it onto continuation lines that start with a space
+// without splitting a UTF-8 character
+func (w *writer) line(name, value string) {
+	s := name + ":" + value
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space of a continuation line counts towards its length
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
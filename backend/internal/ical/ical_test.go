@@ -0,0 +1,106 @@
+package ical_test
+
+import (
+	"specialstandard/internal/ical"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	start := time.Date(2025, 9, 2, 14, 0, 0, 0, time.UTC)
+	cal := ical.Calendar{
+		ProdID: "-//The Special Standard//Sessions//EN",
+		Name:   "Sessions",
+		Events: []ical.Event{
+			{
+				UID:         "series-1@specialstandard",
+				Stamp:       start.Add(-24 * time.Hour),
+				Start:       start,
+				End:         start.Add(45 * time.Minute),
+				Summary:     "Articulation; group, A",
+				Location:    "Room 12",
+				Description: "Line one\nLine two",
+				Rule: &ical.Rule{
+					Freq:      ical.Weekly,
+					Interval:  2,
+					ByDay:     []ical.WeekdayNum{{Day: time.Thursday}, {Day: time.Tuesday}},
+					Until:     start.AddDate(0, 2, 0),
+					WeekStart: time.Sunday,
+				},
+				ExceptDates: []time.Time{start.AddDate(0, 0, 14), start.AddDate(0, 0, 16)},
+			},
+		},
+	}
+
+	out := string(cal.Marshal())
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	assert.Contains(t, out, "DTSTART:20250902T140000Z\r\n")
+	assert.Contains(t, out, "DTEND:20250902T144500Z\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;UNTIL=20251102T140000Z;WKST=SU\r\n")
+	assert.Contains(t, out, "EXDATE:20250916T140000Z,20250918T140000Z\r\n")
+	assert.Contains(t, out, `SUMMARY:Articulation\; group\, A`+"\r\n")
+	assert.Contains(t, out, `DESCRIPTION:Line one\nLine two`+"\r\n")
+	assert.NotContains(t, out, "LAST-MODIFIED")
+	assert.NotContains(t, out, "VTIMEZONE")
+}
+
+func TestMarshal_TimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	start := time.Date(2026, 10, 26, 10, 0, 0, 0, loc)
+	cal := ical.Calendar{
+		ProdID: "-//Test//EN",
+		Events: []ical.Event{{
+			UID:         "series-1@specialstandard",
+			Start:       start,
+			End:         start.Add(time.Hour),
+			Rule:        &ical.Rule{Freq: ical.Weekly, ByDay: []ical.WeekdayNum{{Day: time.Monday}}, Until: start.AddDate(0, 0, 14)},
+			ExceptDates: []time.Time{start.AddDate(0, 0, 7)},
+			TimeZone:    loc,
+		}},
+	}
+
+	out := string(cal.Marshal())
+
+	// Local 10:00 on both sides of the end of daylight saving, UNTIL stays in UTC
+	assert.Contains(t, out, "DTSTART;TZID=America/New_York:20261026T100000\r\n")
+	assert.Contains(t, out, "DTEND;TZID=America/New_York:20261026T110000\r\n")
+	assert.Contains(t, out, "EXDATE;TZID=America/New_York:20261102T100000\r\n")
+	assert.Contains(t, out, "UNTIL=20261109T150000Z")
+
+	// Every TZID is defined, before the events that use it
+	assert.Equal(t, 1, strings.Count(out, "BEGIN:VTIMEZONE\r\n"))
+	assert.Less(t, strings.Index(out, "END:VTIMEZONE"), strings.Index(out, "BEGIN:VEVENT"))
+	assert.Contains(t, out, "BEGIN:VTIMEZONE\r\nTZID:America/New_York\r\n"+
+		"BEGIN:DAYLIGHT\r\nDTSTART:20260308T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\nEND:DAYLIGHT\r\n"+
+		"BEGIN:STANDARD\r\nDTSTART:20261101T020000\r\nTZOFFSETFROM:-0400\r\nTZOFFSETTO:-0500\r\nTZNAME:EST\r\nEND:STANDARD\r\n"+
+		"END:VTIMEZONE\r\n")
+}
+
+func TestMarshal_FoldsLongLines(t *testing.T) {
+	cal := ical.Calendar{
+		ProdID: "-//Test//EN",
+		Events: []ical.Event{{
+			UID:         "a",
+			Description: strings.Repeat("é", 100),
+		}},
+	}
+
+	out := string(cal.Marshal())
+
+	var description []string
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		if strings.HasPrefix(line, "DESCRIPTION:") || (len(description) > 0 && strings.HasPrefix(line, " ")) {
+			description = append(description, strings.TrimPrefix(line, " "))
+		}
+	}
+
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("é", 100), strings.Join(description, ""))
+}
@@ -0,0 +1,409 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotCalendar is returned when the input has no VCALENDAR
+var ErrNotCalendar = errors.New("not an iCalendar file")
+
+// windowsZones maps the zone names Outlook and Exchange write in TZID to IANA names
+var windowsZones = map[string]string{
+	"Eastern Standard Time":        "America/New_York",
+	"Central Standard Time":        "America/Chicago",
+	"Mountain Standard Time":       "America/Denver",
+	"US Mountain Standard Time":    "America/Phoenix",
+	"Pacific Standard Time":        "America/Los_Angeles",
+	"Alaskan Standard Time":        "America/Anchorage",
+	"Hawaiian Standard Time":       "Pacific/Honolulu",
+	"Atlantic Standard Time":       "America/Halifax",
+	"GMT Standard Time":            "Europe/London",
+	"W. Europe Standard Time":      "Europe/Berlin",
+	"Romance Standard Time":        "Europe/Paris",
+	"Central Europe Standard Time": "Europe/Budapest",
+	"UTC":                          "UTC",
+}
+
+// property is one unfolded content line
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseError describes an event that could not be read. The rest of the calendar is
+// still returned.
+type ParseError struct {
+	UID     string
+	Summary string
+	Reason  string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("event %q: %s", e.Summary, e.Reason)
+}
+
+// Parse reads the events of a calendar. Times without a zone are read in loc. Events
+// that cannot be read are reported in the returned ParseErrors instead of failing the
+// whole calendar.
+func Parse(r io.Reader, loc *time.Location) ([]Event, []ParseError, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		events    []Event
+		problems  []ParseError
+		props     []property
+		depth     []string
+		sawVCal   bool
+		component = func() string {
+			if len(depth) == 0 {
+				return ""
+			}
+			return depth[len(depth)-1]
+		}
+	)
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch p.name {
+		case "BEGIN":
+			name := strings.ToUpper(p.value)
+			if name == "VCALENDAR" {
+				sawVCal = true
+			}
+			if name == "VEVENT" {
+				props = nil
+			}
+			depth = append(depth, name)
+		case "END":
+			if component() != strings.ToUpper(p.value) {
+				return nil, nil, fmt.Errorf("unexpected END:%s", p.value)
+			}
+			if component() == "VEVENT" {
+				event, err := buildEvent(props, loc)
+				if err != nil {
+					problems = append(problems, ParseError{UID: event.UID, Summary: event.Summary, Reason: err.Error()})
+				} else {
+					events = append(events, event)
+				}
+			}
+			depth = depth[:len(depth)-1]
+		default:
+			// Only the event's own properties, not those of its alarms
+			if component() == "VEVENT" {
+				props = append(props, p)
+			}
+		}
+	}
+
+	if !sawVCal {
+		return nil, nil, ErrNotCalendar
+	}
+
+	return events, problems, nil
+}
+
+// unfold joins continuation lines, which start with a space or tab
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseLine splits name;param=value;param="quoted":value
+func parseLine(line string) (property, error) {
+	p := property{params: map[string]string{}}
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return p, fmt.Errorf("invalid content line %q", line)
+	}
+	p.name = strings.ToUpper(line[:i])
+
+	for line[i] == ';' {
+		rest := line[i+1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return p, fmt.Errorf("invalid parameter in %q", line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return p, fmt.Errorf("unterminated quote in %q", line)
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return p, fmt.Errorf("invalid content line %q", line)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		p.params[name] = value
+
+		i = len(line) - len(rest)
+		if i >= len(line) {
+			return p, fmt.Errorf("invalid content line %q", line)
+		}
+	}
+
+	p.value = line[i+1:]
+	return p, nil
+}
+
+func buildEvent(props []property, defaultLoc *time.Location) (Event, error) {
+	var e Event
+	var start, end, duration, rule *property
+
+	for i := range props {
+		p := &props[i]
+		switch p.name {
+		case "UID":
+			e.UID = p.value
+		case "SUMMARY":
+			e.Summary = unescape(p.value)
+		case "LOCATION":
+			e.Location = unescape(p.value)
+		case "DESCRIPTION":
+			e.Description = unescape(p.value)
+		case "STATUS":
+			e.Status = strings.ToUpper(p.value)
+		case "DTSTART":
+			start = p
+		case "DTEND":
+			end = p
+		case "DURATION":
+			duration = p
+		case "RRULE":
+			rule = p
+		case "ATTENDEE":
+			e.Attendees = append(e.Attendees, Attendee{
+				Name:  p.params["CN"],
+				Email: strings.TrimPrefix(strings.TrimPrefix(p.value, "mailto:"), "MAILTO:"),
+			})
+		}
+	}
+
+	if start == nil {
+		return e, errors.New("no start time")
+	}
+
+	loc, err := location(*start, defaultLoc)
+	if err != nil {
+		return e, err
+	}
+	if e.Start, e.AllDay, err = parseDateTime(start.value, loc); err != nil {
+		return e, fmt.Errorf("invalid DTSTART: %w", err)
+	}
+
+	switch {
+	case end != nil:
+		endLoc, err := location(*end, defaultLoc)
+		if err != nil {
+			return e, err
+		}
+		if e.End, _, err = parseDateTime(end.value, endLoc); err != nil {
+			return e, fmt.Errorf("invalid DTEND: %w", err)
+		}
+	case duration != nil:
+		d, err := parseDuration(duration.value)
+		if err != nil {
+			return e, fmt.Errorf("invalid DURATION: %w", err)
+		}
+		e.End = e.Start.Add(d)
+	case e.AllDay:
+		e.End = e.Start.AddDate(0, 0, 1)
+	default:
+		e.End = e.Start
+	}
+
+	if rule != nil {
+		if e.Rule, err = ParseRule(rule.value, loc); err != nil {
+			return e, err
+		}
+	}
+
+	// Times read after DTSTART so date-only exceptions can take its time of day
+	for _, p := range props {
+		switch p.name {
+		case "EXDATE", "RDATE":
+			dates, err := parseDateList(p, e.Start, defaultLoc)
+			if err != nil {
+				return e, fmt.Errorf("invalid %s: %w", p.name, err)
+			}
+			if p.name == "EXDATE" {
+				e.ExceptDates = append(e.ExceptDates, dates...)
+			} else {
+				e.ExtraDates = append(e.ExtraDates, dates...)
+			}
+		case "RECURRENCE-ID":
+			dates, err := parseDateList(p, e.Start, defaultLoc)
+			if err != nil || len(dates) != 1 {
+				return e, errors.New("invalid RECURRENCE-ID")
+			}
+			e.RecurrenceID = dates[0]
+		}
+	}
+
+	return e, nil
+}
+
+// location resolves the TZID parameter of a date-time property
+func location(p property, defaultLoc *time.Location) (*time.Location, error) {
+	tzid := strings.TrimPrefix(p.params["TZID"], "/")
+	if tzid == "" {
+		return defaultLoc, nil
+	}
+	if name, ok := windowsZones[tzid]; ok {
+		tzid = name
+	}
+
+	loc, err := time.LoadLocation(tzid)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", p.params["TZID"])
+	}
+	return loc, nil
+}
+
+// parseDateTime reads a DATE or DATE-TIME value, reporting whether it was a date
+func parseDateTime(value string, loc *time.Location) (time.Time, bool, error) {
+	switch {
+	case len(value) == len(dateFormat):
+		t, err := time.ParseInLocation(dateFormat, value, loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse(dateTimeFormat, value)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		return t, false, err
+	}
+}
+
+// parseDateList reads EXDATE, RDATE and RECURRENCE-ID values. Dates without a time are
+// taken at the time of day of start.
+func parseDateList(p property, start time.Time, defaultLoc *time.Location) ([]time.Time, error) {
+	loc, err := location(p, defaultLoc)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []time.Time
+	for _, v := range strings.Split(p.value, ",") {
+		if strings.Contains(v, "/") {
+			// RDATE periods are not supported
+			return nil, fmt.Errorf("%w: period %q", ErrUnsupportedRule, v)
+		}
+		t, dateOnly, err := parseDateTime(strings.TrimSpace(v), loc)
+		if err != nil {
+			return nil, err
+		}
+		if dateOnly {
+			t = time.Date(t.Year(), t.Month(), t.Day(), start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+		}
+		dates = append(dates, t)
+	}
+
+	return dates, nil
+}
+
+// parseDuration reads an RFC 5545 duration such as PT45M, P1D or P1W
+func parseDuration(value string) (time.Duration, error) {
+	s := strings.ToUpper(value)
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	s = s[1:]
+
+	var total time.Duration
+	inTime := false
+	n := 0
+	digits := false
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			n = n*10 + int(c-'0')
+			digits = true
+			continue
+		case c == 'T':
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+
+		unit := map[rune]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+		if inTime {
+			unit = map[rune]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+		}
+		u, ok := unit[c]
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		total += time.Duration(n) * u
+		n, digits = 0, false
+	}
+	if digits {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return sign * total, nil
+}
+
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package ical_test
+
+import (
+	"errors"
+	"specialstandard/internal/ical"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exported = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Google Inc//Google Calendar 70.9054//EN\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:America/New_York\r\n" +
+	"BEGIN:DAYLIGHT\r\n" +
+	"DTSTART:19700308T020000\r\n" +
+	"END:DAYLIGHT\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:weekly@example.com\r\n" +
+	"DTSTART;TZID=America/New_York:20251028T090000\r\n" +
+	"DTEND;TZID=America/New_York:20251028T093000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=TU;COUNT=3\r\n" +
+	"EXDATE;TZID=America/New_York:20251104T090000\r\n" +
+	"SUMMARY:Fluency\\, group A\r\n" +
+	"DESCRIPTION:A long description that Google folds onto a second line becau\r\n" +
+	" se it is long\r\n" +
+	"ATTENDEE;CN=\"Ada Lovelace\";ROLE=REQ-PARTICIPANT:mailto:ada@example.com\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:outlook@example.com\r\n" +
+	"DTSTART;TZID=Pacific Standard Time:20251030T130000\r\n" +
+	"DURATION:PT45M\r\n" +
+	"SUMMARY:Evaluation\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:broken@example.com\r\n" +
+	"SUMMARY:No start\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, problems, err := ical.Parse(strings.NewReader(exported), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	weekly := events[0]
+	assert.Equal(t, "weekly@example.com", weekly.UID)
+	assert.Equal(t, "Fluency, group A", weekly.Summary)
+	assert.Equal(t, "A long description that Google folds onto a second line because it is long", weekly.Description)
+	assert.True(t, weekly.Start.Equal(time.Date(2025, 10, 28, 9, 0, 0, 0, newYork)))
+	assert.Equal(t, 30*time.Minute, weekly.End.Sub(weekly.Start))
+	assert.Equal(t, []ical.Attendee{{Name: "Ada Lovelace", Email: "ada@example.com"}}, weekly.Attendees)
+	require.NotNil(t, weekly.Rule)
+	assert.Equal(t, ical.Weekly, weekly.Rule.Freq)
+	assert.Equal(t, 3, weekly.Rule.Count)
+	require.Len(t, weekly.ExceptDates, 1)
+
+	outlook := events[1]
+	losAngeles, _ := time.LoadLocation("America/Los_Angeles")
+	assert.True(t, outlook.Start.Equal(time.Date(2025, 10, 30, 13, 0, 0, 0, losAngeles)))
+	assert.Equal(t, 45*time.Minute, outlook.End.Sub(outlook.Start))
+
+	require.Len(t, problems, 1)
+	assert.Equal(t, "broken@example.com", problems[0].UID)
+}
+
+func TestParse_NotCalendar(t *testing.T) {
+	_, _, err := ical.Parse(strings.NewReader("name,start\nAda,9:00\n"), time.UTC)
+	assert.Error(t, err)
+
+	_, _, err = ical.Parse(strings.NewReader("BEGIN:VEVENT\r\nEND:VEVENT\r\n"), time.UTC)
+	assert.True(t, errors.Is(err, ical.ErrNotCalendar))
+}
+
+func TestEvent_Occurrences(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	events, _, err := ical.Parse(strings.NewReader(exported), time.UTC)
+	require.NoError(t, err)
+
+	// COUNT includes the excluded 11/4, and 11/11 falls after the switch to EST
+	occurrences, truncated := events[0].Occurrences(time.Time{}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 100)
+	assert.False(t, truncated)
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 10, 28, 9, 0, 0, 0, newYork),
+		time.Date(2025, 11, 11, 9, 0, 0, 0, newYork),
+	}, occurrences)
+	assert.Equal(t, 14, occurrences[1].UTC().Hour())
+
+	// Only instances from the given time count towards the limit
+	occurrences, truncated = events[0].Occurrences(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 1)
+	assert.False(t, truncated)
+	assert.Equal(t, []time.Time{time.Date(2025, 11, 11, 9, 0, 0, 0, newYork)}, occurrences)
+
+	endless := ical.Event{
+		Start: time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC),
+		Rule:  &ical.Rule{Freq: ical.Daily, Interval: 1},
+	}
+	occurrences, truncated = endless.Occurrences(time.Time{}, time.Date(2025, 9, 3, 9, 0, 0, 0, time.UTC), 10)
+	assert.True(t, truncated)
+	assert.Len(t, occurrences, 3)
+}
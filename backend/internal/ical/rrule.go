@@ -0,0 +1,378 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// ErrUnsupportedRule is returned for valid rules using parts this package cannot expand
+var ErrUnsupportedRule = errors.New("unsupported recurrence rule")
+
+// WeekdayNum is a BYDAY entry. N picks the Nth (or, when negative, Nth from last)
+// weekday of the month or year; zero means every such weekday.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule is an RRULE. Occurrences keep the wall clock time of DTSTART in its location, so
+// a series stays at 9:00 across daylight saving changes.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ParseRule parses the value of an RRULE property. A floating or date-only UNTIL is
+// read in loc.
+func ParseRule(value string, loc *time.Location) (*Rule, error) {
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+
+	for _, part := range strings.Split(strings.TrimSpace(value), ";") {
+		if part == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(val))
+			if !slices.Contains([]Frequency{Daily, Weekly, Monthly, Yearly}, r.Freq) {
+				return nil, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedRule, val)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(val)
+			if err == nil && r.Interval < 1 {
+				err = errors.New("must be positive")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(val)
+			if err == nil && r.Count < 1 {
+				err = errors.New("must be positive")
+			}
+		case "UNTIL":
+			r.Until, _, err = parseDateTime(val, loc)
+			if err == nil && len(val) == len(dateFormat) {
+				// A date-only UNTIL includes the whole day
+				r.Until = r.Until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				var wd WeekdayNum
+				if wd, err = parseWeekdayNum(d); err != nil {
+					break
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(val, ",") {
+				var n int
+				if n, err = strconv.Atoi(d); err != nil || n == 0 || n < -31 || n > 31 {
+					err = fmt.Errorf("invalid month day %q", d)
+					break
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, m := range strings.Split(val, ",") {
+				var n int
+				if n, err = strconv.Atoi(m); err != nil || n < 1 || n > 12 {
+					err = fmt.Errorf("invalid month %q", m)
+					break
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			var wd WeekdayNum
+			wd, err = parseWeekdayNum(val)
+			r.WeekStart = wd.Day
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRule, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, errors.New("rule has no FREQ")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, errors.New("rule has both COUNT and UNTIL")
+	}
+	for _, d := range r.ByDay {
+		if d.N != 0 && (r.Freq == Daily || r.Freq == Weekly || (r.Freq == Yearly && len(r.ByMonth) == 0)) {
+			return nil, fmt.Errorf("%w: numbered BYDAY with FREQ=%s", ErrUnsupportedRule, r.Freq)
+		}
+	}
+
+	return r, nil
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", s)
+	}
+
+	day := slices.Index(weekdayCodes[:], s[len(s)-2:])
+	if day < 0 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", s)
+	}
+
+	var n int
+	if prefix := s[:len(s)-2]; prefix != "" {
+		var err error
+		if n, err = strconv.Atoi(prefix); err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid weekday %q", s)
+		}
+	}
+
+	return WeekdayNum{N: n, Day: time.Weekday(day)}, nil
+}
+
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = strconv.Itoa(int(m))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByDay) > 0 {
+		days := slices.Clone(r.ByDay)
+		sort.SliceStable(days, func(i, j int) bool { return days[i].Day < days[j].Day })
+		codes := make([]string, len(days))
+		for i, d := range days {
+			codes[i] = weekdayCodes[d.Day]
+			if d.N != 0 {
+				codes[i] = strconv.Itoa(d.N) + codes[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+formatTime(r.Until))
+	}
+	parts = append(parts, "WKST="+weekdayCodes[r.WeekStart])
+
+	return strings.Join(parts, ";")
+}
+
+// Occurrences lists the start of every instance of the event from from up to and
+// including end, at most limit of them: DTSTART, the instances of its rule and RDATEs,
+// less EXDATEs. It reports whether the series goes on past what was returned.
+func (e Event) Occurrences(from, end time.Time, limit int) ([]time.Time, bool) {
+	starts := []time.Time{e.Start}
+	more := false
+	if e.Rule != nil {
+		// Unlimited here, limit only counts instances after from
+		var expanded []time.Time
+		expanded, more = e.Rule.Expand(e.Start, end, -1)
+		// DTSTART is always the first instance, even when the rule does not produce it
+		if len(expanded) > 0 && expanded[0].Equal(e.Start) {
+			expanded = expanded[1:]
+		}
+		starts = append(starts, expanded...)
+	}
+	starts = append(starts, e.ExtraDates...)
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	var occurrences []time.Time
+	for i, t := range starts {
+		if t.Before(from) || (i > 0 && t.Equal(starts[i-1])) || slices.ContainsFunc(e.ExceptDates, t.Equal) {
+			continue
+		}
+		if t.After(end) || len(occurrences) == limit {
+			return occurrences, true
+		}
+		occurrences = append(occurrences, t)
+	}
+
+	return occurrences, more
+}
+
+// maxEmptyPeriods stops rules that can never match, such as the 30th of February
+const maxEmptyPeriods = 1000
+
+// Expand lists the occurrences of the rule for a series starting at dtstart, up to and
+// including end, and at most limit of them, or all of them when limit is negative. It
+// reports whether the series goes on past what was returned.
+func (r Rule) Expand(dtstart, end time.Time, limit int) ([]time.Time, bool) {
+	interval := max(r.Interval, 1)
+
+	var occurrences []time.Time
+	generated := 0
+	empty := 0
+	for period := 0; empty < maxEmptyPeriods; period++ {
+		candidates := r.candidates(dtstart, period*interval)
+		if len(candidates) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return occurrences, false
+			}
+			if r.Count > 0 && generated == r.Count {
+				return occurrences, false
+			}
+			if t.After(end) || len(occurrences) == limit {
+				return occurrences, true
+			}
+			generated++
+			occurrences = append(occurrences, t)
+		}
+	}
+
+	return occurrences, false
+}
+
+// candidates lists, in order, the times the rule produces in the period that is offset
+// periods after the one containing dtstart
+func (r Rule) candidates(dtstart time.Time, offset int) []time.Time {
+	loc := dtstart.Location()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, loc)
+	}
+
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		day := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+offset)
+		if r.matchesDay(day) {
+			days = append(days, day)
+		}
+
+	case Weekly:
+		weekday := []WeekdayNum{{Day: dtstart.Weekday()}}
+		if len(r.ByDay) > 0 {
+			weekday = r.ByDay
+		}
+		back := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-back+7*offset)
+		for i := 0; i < 7; i++ {
+			day := at(weekStart.Year(), weekStart.Month(), weekStart.Day()+i)
+			if slices.ContainsFunc(weekday, func(w WeekdayNum) bool { return w.Day == day.Weekday() }) &&
+				(len(r.ByMonth) == 0 || slices.Contains(r.ByMonth, day.Month())) {
+				days = append(days, day)
+			}
+		}
+
+	case Monthly:
+		month := at(dtstart.Year(), dtstart.Month()+time.Month(offset), 1)
+		if len(r.ByMonth) == 0 || slices.Contains(r.ByMonth, month.Month()) {
+			days = r.daysInMonth(month, dtstart, at)
+		}
+
+	case Yearly:
+		year := dtstart.Year() + offset
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{dtstart.Month()}
+		}
+		for _, m := range slices.Sorted(slices.Values(months)) {
+			days = append(days, r.daysInMonth(at(year, m, 1), dtstart, at)...)
+		}
+	}
+
+	return days
+}
+
+// daysInMonth applies BYMONTHDAY and BYDAY within one month, defaulting to the day of
+// the month of dtstart
+func (r Rule) daysInMonth(month, dtstart time.Time, at func(int, time.Month, int) time.Time) []time.Time {
+	y, m := month.Year(), month.Month()
+	last := at(y, m+1, 0).Day()
+
+	var days []time.Time
+	for d := 1; d <= last; d++ {
+		day := at(y, m, d)
+
+		if len(r.ByMonthDay) > 0 && !slices.ContainsFunc(r.ByMonthDay, func(n int) bool {
+			return n == d || (n < 0 && last+1+n == d)
+		}) {
+			continue
+		}
+
+		if len(r.ByDay) > 0 {
+			nth := (d-1)/7 + 1
+			nthFromLast := -((last-d)/7 + 1)
+			if !slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool {
+				return w.Day == day.Weekday() && (w.N == 0 || w.N == nth || w.N == nthFromLast)
+			}) {
+				continue
+			}
+		}
+
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 && d != dtstart.Day() {
+			continue
+		}
+
+		days = append(days, day)
+	}
+
+	return days
+}
+
+// matchesDay applies the BY* filters to a day produced by a daily rule
+func (r Rule) matchesDay(day time.Time) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, day.Month()) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool { return w.Day == day.Weekday() }) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 {
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		return slices.ContainsFunc(r.ByMonthDay, func(n int) bool {
+			return n == day.Day() || (n < 0 && last+1+n == day.Day())
+		})
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package ical_test
+
+import (
+	"errors"
+	"specialstandard/internal/ical"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "weekly",
+			value: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TH,TU;UNTIL=20251219",
+			want:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;UNTIL=20251220T045959Z;WKST=MO",
+		},
+		{
+			name:  "second Tuesday",
+			value: "FREQ=MONTHLY;BYDAY=2TU;COUNT=6",
+			want:  "FREQ=MONTHLY;BYDAY=2TU;COUNT=6;WKST=MO",
+		},
+		{
+			name:    "hourly",
+			value:   "FREQ=HOURLY",
+			wantErr: ical.ErrUnsupportedRule,
+		},
+		{
+			name:    "BYSETPOS",
+			value:   "FREQ=MONTHLY;BYDAY=MO,TU;BYSETPOS=-1",
+			wantErr: ical.ErrUnsupportedRule,
+		},
+		{
+			name:    "numbered weekday in a weekly rule",
+			value:   "FREQ=WEEKLY;BYDAY=1MO",
+			wantErr: ical.ErrUnsupportedRule,
+		},
+	}
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ical.ParseRule(tt.value, newYork)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule.String())
+		})
+	}
+
+	_, err := ical.ParseRule("INTERVAL=2", time.UTC)
+	assert.Error(t, err)
+}
+
+func TestRule_Expand(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	date := func(y int, m time.Month, d, hour int) time.Time {
+		return time.Date(y, m, d, hour, 0, 0, 0, newYork)
+	}
+	end := date(2027, 1, 1, 0)
+
+	tests := []struct {
+		name          string
+		rule          string
+		dtstart       time.Time
+		limit         int
+		want          []time.Time
+		wantTruncated bool
+	}{
+		{
+			name:    "every other week on two days",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=5",
+			dtstart: date(2025, 10, 28, 9),
+			limit:   100,
+			want: []time.Time{
+				date(2025, 10, 28, 9), date(2025, 10, 30, 9),
+				date(2025, 11, 11, 9), date(2025, 11, 13, 9),
+				date(2025, 11, 25, 9),
+			},
+		},
+		{
+			name:    "second Tuesday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=2TU;UNTIL=20260301T000000Z",
+			dtstart: date(2025, 12, 9, 14),
+			limit:   100,
+			want:    []time.Time{date(2025, 12, 9, 14), date(2026, 1, 13, 14), date(2026, 2, 10, 14)},
+		},
+		{
+			name:    "last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			dtstart: date(2026, 1, 31, 8),
+			limit:   100,
+			want:    []time.Time{date(2026, 1, 31, 8), date(2026, 2, 28, 8), date(2026, 3, 31, 8)},
+		},
+		{
+			name:    "31st skips short months",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: date(2026, 1, 31, 8),
+			limit:   100,
+			want:    []time.Time{date(2026, 1, 31, 8), date(2026, 3, 31, 8), date(2026, 5, 31, 8)},
+		},
+		{
+			name:    "weekdays",
+			rule:    "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			dtstart: date(2026, 3, 6, 9),
+			limit:   3,
+			want:    []time.Time{date(2026, 3, 6, 9), date(2026, 3, 9, 9), date(2026, 3, 10, 9)},
+
+			wantTruncated: true,
+		},
+		{
+			name:    "never matches",
+			rule:    "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			dtstart: date(2026, 2, 1, 9),
+			limit:   100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ical.ParseRule(tt.rule, newYork)
+			require.NoError(t, err)
+
+			got, truncated := rule.Expand(tt.dtstart, end, tt.limit)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantTruncated, truncated)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"specialstandard/internal/config"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage"
+	"time"
+)
+
+// RegisterBuiltins registers the jobs the server always runs, and schedules the periodic
+// ones. Finished runs and emails are kept for their configured retention.
+func RegisterBuiltins(s *Scheduler, repo *storage.Repository, cfg config.Config) error {
+	s.Register(Job{
+		Name: "purge_verification_codes",
+		Handler: func(ctx context.Context, _ json.RawMessage) error {
+			deleted, err := repo.Verification.DeleteExpiredCodes(ctx, time.Now())
+			if err == nil && deleted > 0 {
+				slog.Info("Purged expired verification codes", "count", deleted)
+			}
+			return err
+		},
+	})
+
+	// Sent emails no longer hold their bodies, only what was sent to whom is kept
+	s.Register(Job{
+		Name: "purge_email_outbox",
+		Handler: func(ctx context.Context, _ json.RawMessage) error {
+			deleted, err := repo.EmailOutbox.DeleteFinishedEmails(ctx, time.Now().Add(-cfg.Mail.OutboxRetention))
+			if err == nil && deleted > 0 {
+				slog.Info("Purged finished outbox emails", "count", deleted)
+			}
+			return err
+		},
+	})
+
+	s.Register(Job{
+		Name: "purge_job_runs",
+		Handler: func(ctx context.Context, _ json.RawMessage) error {
+			_, err := repo.JobRun.DeleteFinishedJobRuns(ctx, time.Now().Add(-cfg.Jobs.RunRetention))
+			return err
+		},
+	})
+
+	// Queued by therapists ahead of the end of the school year. A retry could promote
+	// students a second time, so a failed run is left for the therapist to check.
+	s.Register(Job{
+		Name:        models.JobPromoteStudents,
+		MaxAttempts: 1,
+		Handler: func(ctx context.Context, payload json.RawMessage) error {
+			var input models.PromoteStudentsInput
+			if err := json.Unmarshal(payload, &input); err != nil {
+				return err
+			}
+			return repo.Student.PromoteStudents(ctx, input)
+		},
+	})
+
+	if err := s.Schedule("purge_verification_codes", "@hourly"); err != nil {
+		return err
+	}
+	if err := s.Schedule("purge_email_outbox", "15 3 * * *"); err != nil {
+		return err
+	}
+	return s.Schedule("purge_job_runs", "30 3 * * *")
+}
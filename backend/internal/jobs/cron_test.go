@@ -0,0 +1,73 @@
+package jobs_test
+
+import (
+	"specialstandard/internal/jobs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@often",
+	} {
+		_, err := jobs.ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// A Tuesday
+	from := time.Date(2025, 9, 2, 14, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"* * * * *", from, time.Date(2025, 9, 2, 14, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", from, time.Date(2025, 9, 2, 14, 15, 0, 0, time.UTC)},
+		{"@hourly", from, time.Date(2025, 9, 2, 15, 0, 0, 0, time.UTC)},
+		{"30 3 * * *", from, time.Date(2025, 9, 3, 3, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", from, time.Date(2025, 9, 2, 17, 0, 0, 0, time.UTC)},
+		// A match is always after from
+		{"7 14 * * *", time.Date(2025, 9, 2, 14, 7, 0, 0, time.UTC), time.Date(2025, 9, 3, 14, 7, 0, 0, time.UTC)},
+		// Sunday as 7
+		{"0 0 * * 7", from, time.Date(2025, 9, 7, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 0 15 * 4", from, time.Date(2025, 9, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", from, time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", from, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", from, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", from, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := jobs.ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(tt.from))
+		})
+	}
+
+	t.Run("In the location given", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+
+		schedule, err := jobs.ParseSchedule("0 7 * * *")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 9, 3, 7, 0, 0, 0, newYork), schedule.Next(from.In(newYork)))
+	})
+}
@@ -0,0 +1,292 @@
+// Package jobs runs periodic and deferred work in the background of every server instance.
+// Runs are queued in Postgres and shared out with SKIP LOCKED, so each is worked on by one
+// instance at a time. Failed runs are retried with backoff, and an instance shutting down
+// lets the runs it started finish.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"specialstandard/internal/config"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTimeout     = time.Minute
+	defaultMaxAttempts = 3
+	// How long past the longest timeout a claimed run stays leased, so a worker slow to
+	// record the outcome is not overtaken by another
+	leaseMargin = time.Minute
+
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = time.Hour
+)
+
+// Handler does the work of a run. ctx is cancelled once the job's timeout passes, handlers
+// are expected to give up then.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Job is a kind of background work
+type Job struct {
+	Name    string
+	Handler Handler
+	// Timeout bounds each attempt, a minute when zero
+	Timeout time.Duration
+	// MaxAttempts a run gets before it is given up on, 3 when zero
+	MaxAttempts int
+}
+
+func (j *Job) timeout() time.Duration {
+	if j.Timeout > 0 {
+		return j.Timeout
+	}
+	return defaultTimeout
+}
+
+func (j *Job) maxAttempts() int {
+	if j.MaxAttempts > 0 {
+		return j.MaxAttempts
+	}
+	return defaultMaxAttempts
+}
+
+// scheduled is a job queued on a cron schedule
+type scheduled struct {
+	job      string
+	schedule *Schedule
+	next     time.Time
+}
+
+type Scheduler struct {
+	repo         storage.JobRunRepository
+	workerID     string
+	pollInterval time.Duration
+	// slots holds a token for every run being worked on
+	slots chan struct{}
+
+	jobs      map[string]Job
+	schedules []*scheduled
+	running   sync.WaitGroup
+}
+
+func New(repo storage.JobRunRepository, cfg config.Jobs) *Scheduler {
+	concurrency := max(cfg.Concurrency, 1)
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+
+	host, _ := os.Hostname()
+	return &Scheduler{
+		repo:         repo,
+		workerID:     fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8]),
+		pollInterval: pollInterval,
+		slots:        make(chan struct{}, concurrency),
+		jobs:         map[string]Job{},
+	}
+}
+
+// Register makes the job available to run. Registering a name twice is a programming
+// error and panics.
+func (s *Scheduler) Register(job Job) {
+	if _, ok := s.jobs[job.Name]; ok {
+		panic(fmt.Sprintf("jobs: %s registered twice", job.Name))
+	}
+	s.jobs[job.Name] = job
+}
+
+// Schedule queues a run of the registered job whenever the cron expression matches, in
+// UTC. Every instance queues the same runs, only one of each is kept.
+func (s *Scheduler) Schedule(name, spec string) error {
+	if _, ok := s.jobs[name]; !ok {
+		return fmt.Errorf("jobs: %s is not registered", name)
+	}
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	s.schedules = append(s.schedules, &scheduled{
+		job:      name,
+		schedule: schedule,
+		next:     schedule.Next(time.Now().UTC()),
+	})
+	return nil
+}
+
+// Enqueue queues a run of the registered job at runAt, with the payload as JSON
+func (s *Scheduler) Enqueue(ctx context.Context, name string, payload any, runAt time.Time) (*models.JobRun, error) {
+	job, ok := s.jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("jobs: %s is not registered", name)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: encode %s payload: %w", name, err)
+	}
+
+	return s.repo.EnqueueJob(ctx, &models.EnqueueJobInput{
+		Name:        name,
+		Payload:     raw,
+		RunAt:       runAt,
+		MaxAttempts: job.maxAttempts(),
+	})
+}
+
+// Tick queues the scheduled runs that are due, then starts as many due runs as there are
+// free workers for, and returns how many it started
+func (s *Scheduler) Tick(ctx context.Context) (int, error) {
+	s.enqueueScheduled(ctx)
+
+	free := cap(s.slots) - len(s.slots)
+	if free == 0 || len(s.jobs) == 0 {
+		return 0, nil
+	}
+
+	runs, err := s.repo.ClaimDueJobs(ctx, s.names(), s.workerID, free, s.lease())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, run := range runs {
+		s.slots <- struct{}{}
+		s.running.Add(1)
+		go s.execute(run)
+	}
+	return len(runs), nil
+}
+
+// Run ticks every poll interval until ctx is cancelled, then waits for the runs it started
+// to finish
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Failed to start due jobs", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			s.running.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueueScheduled queues the occurrences of the schedules that have come. An occurrence
+// that fails to queue is tried again on the next tick.
+func (s *Scheduler) enqueueScheduled(ctx context.Context) {
+	now := time.Now().UTC()
+	for _, e := range s.schedules {
+		if e.next.IsZero() || now.Before(e.next) {
+			continue
+		}
+
+		job := s.jobs[e.job]
+		key := e.job + "@" + e.next.Format(time.RFC3339)
+		_, err := s.repo.EnqueueJob(ctx, &models.EnqueueJobInput{
+			Name:        e.job,
+			Payload:     json.RawMessage("{}"),
+			RunAt:       e.next,
+			MaxAttempts: job.maxAttempts(),
+			DedupeKey:   &key,
+		})
+		if err != nil {
+			slog.Error("Failed to queue scheduled job", "job", e.job, "at", e.next, "err", err)
+			continue
+		}
+		e.next = e.schedule.Next(now)
+	}
+}
+
+// execute works on a claimed run and records the outcome on it
+func (s *Scheduler) execute(run models.JobRun) {
+	defer func() {
+		<-s.slots
+		s.running.Done()
+	}()
+
+	job := s.jobs[run.Name]
+	err := call(job, run)
+
+	// Recorded even while shutting down, so the run is not left waiting on its lease
+	ctx := context.Background()
+	if err == nil {
+		if err := s.repo.CompleteJob(ctx, run.ID, s.workerID); err != nil {
+			slog.Error("Failed to mark job run as succeeded", "job", run.Name, "id", run.ID, "err", err)
+		}
+		return
+	}
+
+	slog.Warn("Job run failed", "job", run.Name, "id", run.ID, "attempt", run.Attempts, "err", err)
+
+	var retryAt *time.Time
+	if run.Attempts < run.MaxAttempts {
+		next := time.Now().Add(retryDelay(run.Attempts))
+		retryAt = &next
+	}
+	if err := s.repo.FailJob(ctx, run.ID, s.workerID, err.Error(), retryAt); err != nil {
+		slog.Error("Failed to mark job run as failed", "job", run.Name, "id", run.ID, "err", err)
+	}
+}
+
+// call runs the job's handler under its timeout, a panic fails the run
+func call(job Job, run models.JobRun) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), job.timeout())
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	err = job.Handler(ctx, run.Payload)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", job.timeout(), err)
+	}
+	return err
+}
+
+func (s *Scheduler) names() []string {
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// lease is how long claimed runs are kept from other workers, past the longest timeout
+func (s *Scheduler) lease() time.Duration {
+	longest := time.Duration(0)
+	for _, job := range s.jobs {
+		longest = max(longest, job.timeout())
+	}
+	return longest + leaseMargin
+}
+
+// retryDelay doubles from baseRetryDelay with every failed attempt, up to maxRetryDelay
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
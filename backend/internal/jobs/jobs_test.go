@@ -0,0 +1,238 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"specialstandard/internal/config"
+	"specialstandard/internal/jobs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newScheduler(repo *mocks.MockJobRunRepository) *jobs.Scheduler {
+	return jobs.New(repo, config.Jobs{Concurrency: 2, PollInterval: time.Millisecond})
+}
+
+// claim makes the repository hand out the runs once, and nothing after
+func claim(repo *mocks.MockJobRunRepository, runs ...models.JobRun) {
+	repo.On("ClaimDueJobs", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(runs, nil).Once()
+	repo.On("ClaimDueJobs", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.JobRun{}, nil).Maybe()
+}
+
+// wait fails the test when done is not closed in time
+func wait(t *testing.T, done chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  jobs.Handler
+		timeout  time.Duration
+		attempts int
+		// expectedError is part of the error the run is failed with, empty when it succeeds
+		expectedError string
+		expectRetry   bool
+	}{
+		{
+			name: "Succeeds with its payload",
+			handler: func(ctx context.Context, payload json.RawMessage) error {
+				if string(payload) != `{"n":1}` {
+					return errors.New("wrong payload")
+				}
+				return nil
+			},
+			attempts: 1,
+		},
+		{
+			name: "Fails and is retried",
+			handler: func(ctx context.Context, payload json.RawMessage) error {
+				return errors.New("connection refused")
+			},
+			attempts:      1,
+			expectedError: "connection refused",
+			expectRetry:   true,
+		},
+		{
+			name: "Fails its last attempt",
+			handler: func(ctx context.Context, payload json.RawMessage) error {
+				return errors.New("connection refused")
+			},
+			attempts:      3,
+			expectedError: "connection refused",
+		},
+		{
+			name: "Panics",
+			handler: func(ctx context.Context, payload json.RawMessage) error {
+				panic("nil map")
+			},
+			attempts:      1,
+			expectedError: "panic: nil map",
+			expectRetry:   true,
+		},
+		{
+			name: "Runs out of time",
+			handler: func(ctx context.Context, payload json.RawMessage) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			timeout:       10 * time.Millisecond,
+			attempts:      1,
+			expectedError: "timed out after 10ms",
+			expectRetry:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockJobRunRepository)
+			s := newScheduler(repo)
+			s.Register(jobs.Job{Name: "work", Handler: tt.handler, Timeout: tt.timeout})
+
+			run := models.JobRun{ID: uuid.New(), Name: "work", Payload: json.RawMessage(`{"n":1}`), Attempts: tt.attempts, MaxAttempts: 3}
+			claim(repo, run)
+
+			done := make(chan struct{})
+			if tt.expectedError == "" {
+				repo.On("CompleteJob", mock.Anything, run.ID, mock.Anything).Return(nil).Run(func(mock.Arguments) { close(done) })
+			} else {
+				repo.On("FailJob", mock.Anything, run.ID, mock.Anything, mock.MatchedBy(func(msg string) bool {
+					return strings.Contains(msg, tt.expectedError)
+				}), mock.MatchedBy(func(retryAt *time.Time) bool {
+					return (retryAt != nil) == tt.expectRetry
+				})).Return(nil).Run(func(mock.Arguments) { close(done) })
+			}
+
+			started, err := s.Tick(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, started)
+			wait(t, done)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestScheduler_TickClaimsForFreeWorkers(t *testing.T) {
+	repo := new(mocks.MockJobRunRepository)
+	s := newScheduler(repo)
+
+	release := make(chan struct{})
+	s.Register(jobs.Job{Name: "work", Handler: func(ctx context.Context, payload json.RawMessage) error {
+		<-release
+		return nil
+	}})
+
+	// One of the two workers is busy
+	claim(repo, models.JobRun{ID: uuid.New(), Name: "work", Attempts: 1, MaxAttempts: 3})
+	done := make(chan struct{})
+	repo.On("CompleteJob", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) { close(done) })
+
+	_, err := s.Tick(context.Background())
+	require.NoError(t, err)
+	_, err = s.Tick(context.Background())
+	require.NoError(t, err)
+	repo.AssertCalled(t, "ClaimDueJobs", mock.Anything, []string{"work"}, mock.Anything, 1, time.Minute+time.Minute)
+
+	close(release)
+	wait(t, done)
+}
+
+func TestScheduler_Schedule(t *testing.T) {
+	repo := new(mocks.MockJobRunRepository)
+	s := newScheduler(repo)
+	s.Register(jobs.Job{Name: "purge", Handler: func(ctx context.Context, payload json.RawMessage) error { return nil }, MaxAttempts: 5})
+
+	assert.Error(t, s.Schedule("unknown", "@hourly"))
+	assert.Error(t, s.Schedule("purge", "@often"))
+	require.NoError(t, s.Schedule("purge", "@hourly"))
+
+	at := time.Date(2025, 9, 2, 15, 0, 0, 0, time.UTC)
+	jobs.DueNow(s, at)
+
+	// Every instance queues the occurrence under the same key
+	repo.On("EnqueueJob", mock.Anything, mock.MatchedBy(func(input *models.EnqueueJobInput) bool {
+		return input.Name == "purge" && input.RunAt.Equal(at) && input.MaxAttempts == 5 &&
+			input.DedupeKey != nil && *input.DedupeKey == "purge@2025-09-02T15:00:00Z"
+	})).Return(nil, nil).Once()
+	claim(repo)
+
+	_, err := s.Tick(context.Background())
+	require.NoError(t, err)
+
+	// Queued once, the next occurrence is not due yet
+	_, err = s.Tick(context.Background())
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestScheduler_Enqueue(t *testing.T) {
+	repo := new(mocks.MockJobRunRepository)
+	s := newScheduler(repo)
+	s.Register(jobs.Job{Name: "promote", Handler: func(ctx context.Context, payload json.RawMessage) error { return nil }})
+
+	_, err := s.Enqueue(context.Background(), "unknown", nil, time.Now())
+	assert.Error(t, err)
+
+	runAt := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	repo.On("EnqueueJob", mock.Anything, &models.EnqueueJobInput{
+		Name:        "promote",
+		Payload:     json.RawMessage(`{"therapist_id":"00000000-0000-0000-0000-000000000000"}`),
+		RunAt:       runAt,
+		MaxAttempts: 3,
+	}).Return(&models.JobRun{ID: uuid.New()}, nil)
+
+	run, err := s.Enqueue(context.Background(), "promote", map[string]string{"therapist_id": uuid.Nil.String()}, runAt)
+	require.NoError(t, err)
+	assert.NotNil(t, run)
+	repo.AssertExpectations(t)
+}
+
+func TestScheduler_RunDrains(t *testing.T) {
+	repo := new(mocks.MockJobRunRepository)
+	s := newScheduler(repo)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	finished := false
+	s.Register(jobs.Job{Name: "work", Handler: func(ctx context.Context, payload json.RawMessage) error {
+		close(started)
+		<-release
+		finished = true
+		return nil
+	}})
+	claim(repo, models.JobRun{ID: uuid.New(), Name: "work", Attempts: 1, MaxAttempts: 3})
+	repo.On("CompleteJob", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(stopped)
+	}()
+
+	wait(t, started)
+	cancel()
+	select {
+	case <-stopped:
+		t.Fatal("stopped before the run in flight finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	wait(t, stopped)
+	assert.True(t, finished)
+	repo.AssertCalled(t, "CompleteJob", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"fmt"
+	"specialstandard/internal/config"
+)
+
+// NewBackend picks the backend configured by MAIL_BACKEND
+func NewBackend(cfg config.Mail, resendCfg config.Resend) (Backend, error) {
+	backend := cfg.Backend
+	if backend == "" {
+		backend = "local"
+		if resendCfg.APIKey != "" {
+			backend = "resend"
+		}
+	}
+
+	switch backend {
+	case "resend":
+		if resendCfg.APIKey == "" {
+			return nil, fmt.Errorf("MAIL_BACKEND=resend requires RESEND_API_KEY")
+		}
+		return NewResendBackend(resendCfg.APIKey), nil
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("MAIL_BACKEND=smtp requires SMTP_HOST")
+		}
+		return NewSMTPBackend(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword), nil
+	case "local":
+		return NewLocalBackend(cfg.OutboxDir), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_BACKEND %q, expected resend, smtp or local", backend)
+	}
+}
+
+// FromAddress is MAIL_FROM, falling back to RESEND_FROM_EMAIL
+func FromAddress(cfg config.Mail, resendCfg config.Resend) string {
+	if cfg.From != "" {
+		return cfg.From
+	}
+	return resendCfg.FromEmail
+}
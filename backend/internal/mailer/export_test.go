@@ -0,0 +1,8 @@
+package mailer
+
+import "github.com/resend/resend-go/v3"
+
+// ResendClient exposes the client so tests can point it at a fake API
+func ResendClient(b *ResendBackend) *resend.Client {
+	return b.client
+}
@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LocalBackend keeps delivered messages in memory and, when dir is set, writes every one of
+// them to an .eml file there. Meant for development and tests, nothing leaves the machine.
+type LocalBackend struct {
+	dir string
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+func NewLocalBackend(dir string) *LocalBackend {
+	return &LocalBackend{dir: dir}
+}
+
+func (b *LocalBackend) Deliver(ctx context.Context, from string, msg Message) (string, error) {
+	id := uuid.NewString()
+
+	if b.dir != "" {
+		raw, err := buildMIME(from, msg, fmt.Sprintf("<%s@localhost>", id))
+		if err != nil {
+			return "", err
+		}
+
+		if err := os.MkdirAll(b.dir, 0o755); err != nil {
+			return "", err
+		}
+
+		name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), id)
+		if err := os.WriteFile(filepath.Join(b.dir, name), raw, 0o644); err != nil {
+			return "", err
+		}
+	}
+
+	b.mu.Lock()
+	b.sent = append(b.sent, msg)
+	b.mu.Unlock()
+
+	return id, nil
+}
+
+// Sent returns the messages delivered so far, oldest first
+func (b *LocalBackend) Sent() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Message(nil), b.sent...)
+}
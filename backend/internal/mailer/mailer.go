@@ -0,0 +1,164 @@
+// Package mailer renders templated emails into the outbox and delivers them through a
+// pluggable backend (Resend, SMTP, or local files for development and tests). Failed
+// deliveries stay in the outbox and are retried with backoff.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage"
+	"time"
+)
+
+const (
+	// Deliveries an email gets before it is given up on
+	defaultMaxAttempts = 5
+	// How long a claimed email is hidden from other workers while it is being delivered
+	claimLease = 5 * time.Minute
+	// Emails retried per tick of Run
+	retryBatchSize = 20
+
+	baseRetryDelay = time.Minute
+	maxRetryDelay  = time.Hour
+)
+
+// Message is a rendered email
+type Message struct {
+	To      []string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Backend delivers a message and returns the provider's ID for it
+type Backend interface {
+	Deliver(ctx context.Context, from string, msg Message) (string, error)
+}
+
+type Mailer struct {
+	backend Backend
+	outbox  storage.EmailOutboxRepository
+	from    string
+}
+
+func New(backend Backend, outbox storage.EmailOutboxRepository, from string) *Mailer {
+	return &Mailer{backend: backend, outbox: outbox, from: from}
+}
+
+// Send renders the template into the outbox and tries to deliver it right away. An error is
+// only returned when the email could not be queued; failed deliveries are retried later.
+func (m *Mailer) Send(ctx context.Context, to []string, template string, data any) (*models.OutboxEmail, error) {
+	msg, err := Render(template, data)
+	if err != nil {
+		return nil, err
+	}
+	msg.To = to
+
+	// Queued as claimed by us, workers only pick it up if this delivery never finishes
+	email, err := m.outbox.EnqueueEmail(ctx, &models.CreateOutboxEmailInput{
+		Recipients:    to,
+		Subject:       msg.Subject,
+		HTMLBody:      msg.HTML,
+		TextBody:      msg.Text,
+		Template:      template,
+		MaxAttempts:   defaultMaxAttempts,
+		NextAttemptAt: time.Now().Add(claimLease),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("queue email: %w", err)
+	}
+
+	m.deliver(ctx, email)
+	return email, nil
+}
+
+// RetryDue delivers the outbox emails whose retry is due and returns how many were sent
+func (m *Mailer) RetryDue(ctx context.Context) (int, error) {
+	emails, err := m.outbox.ClaimDueEmails(ctx, retryBatchSize, claimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range emails {
+		if m.deliver(ctx, &emails[i]) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// Run retries the outbox every interval until ctx is cancelled
+func (m *Mailer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.RetryDue(ctx); err != nil {
+				slog.Error("Failed to retry outbox emails", "err", err)
+			}
+		}
+	}
+}
+
+// deliver sends one outbox email and records the outcome on it
+func (m *Mailer) deliver(ctx context.Context, email *models.OutboxEmail) bool {
+	msg := Message{
+		To:      email.Recipients,
+		Subject: email.Subject,
+		HTML:    email.HTMLBody,
+		Text:    email.TextBody,
+	}
+
+	providerID, err := m.backend.Deliver(ctx, m.from, msg)
+	email.Attempts++
+
+	if err == nil {
+		now := time.Now()
+		email.Status = models.EmailSent
+		email.ProviderMessageID = &providerID
+		email.SentAt = &now
+		if err := m.outbox.MarkEmailSent(ctx, email.ID, providerID); err != nil {
+			slog.Error("Failed to mark email as sent", "id", email.ID, "err", err)
+		}
+		return true
+	}
+
+	slog.Warn("Email delivery failed", "id", email.ID, "template", email.Template, "attempt", email.Attempts, "err", err)
+
+	lastError := err.Error()
+	email.LastError = &lastError
+
+	var retryAt *time.Time
+	if email.Attempts < email.MaxAttempts {
+		next := time.Now().Add(retryDelay(email.Attempts))
+		retryAt = &next
+		email.NextAttemptAt = next
+	} else {
+		email.Status = models.EmailFailed
+	}
+
+	if err := m.outbox.MarkEmailFailed(ctx, email.ID, lastError, retryAt); err != nil {
+		slog.Error("Failed to mark email as failed", "id", email.ID, "err", err)
+	}
+	return false
+}
+
+// retryDelay doubles from baseRetryDelay with every failed attempt, up to maxRetryDelay
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,276 @@
+package mailer_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"specialstandard/internal/config"
+	"specialstandard/internal/mailer"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type failingBackend struct{}
+
+func (failingBackend) Deliver(ctx context.Context, from string, msg mailer.Message) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+// expectEnqueue makes the outbox mock return the queued input as a fresh outbox email
+func expectEnqueue(outbox *mocks.MockEmailOutboxRepository, matcher any) {
+	email := &models.OutboxEmail{}
+	outbox.On("EnqueueEmail", mock.Anything, matcher).Run(func(args mock.Arguments) {
+		*email = queued(args.Get(1).(*models.CreateOutboxEmailInput))
+	}).Return(email, nil)
+}
+
+func queued(input *models.CreateOutboxEmailInput) models.OutboxEmail {
+	return models.OutboxEmail{
+		ID:          uuid.New(),
+		Recipients:  input.Recipients,
+		Subject:     input.Subject,
+		HTMLBody:    input.HTMLBody,
+		TextBody:    input.TextBody,
+		Template:    input.Template,
+		Status:      models.EmailPending,
+		MaxAttempts: input.MaxAttempts,
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Run("Verification code", func(t *testing.T) {
+		msg, err := mailer.Render(mailer.TemplateVerificationCode, mailer.VerificationCodeData{Code: "042917", ExpiresInMinutes: 10})
+		require.NoError(t, err)
+
+		assert.Equal(t, "The Special Standard Verification Code", msg.Subject)
+		assert.Contains(t, msg.HTML, "042917")
+		assert.Contains(t, msg.HTML, "<strong>10 minutes</strong>")
+		assert.Contains(t, msg.Text, "042917")
+		assert.NotContains(t, msg.Text, "<")
+	})
+
+	t.Run("Password reset escapes the link in HTML only", func(t *testing.T) {
+		link := `https://example.supabase.co/auth/v1/verify?token=abc&type=recovery&redirect_to="><script>`
+		msg, err := mailer.Render(mailer.TemplatePasswordReset, mailer.PasswordResetData{ResetURL: link})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Reset your The Special Standard password", msg.Subject)
+		assert.NotContains(t, msg.HTML, "<script>")
+		assert.Contains(t, msg.Text, link)
+	})
+
+	t.Run("Invitation", func(t *testing.T) {
+		link := "https://app.example.com/accept-invite?token=abc.def.ghi"
+		msg, err := mailer.Render(mailer.TemplateInvitation, mailer.InvitationData{
+			DistrictName: "Boston Public Schools",
+			AcceptURL:    link,
+			ExpiresOn:    "December 15, 2025",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "You're invited to join Boston Public Schools on The Special Standard", msg.Subject)
+		assert.Contains(t, msg.HTML, link)
+		assert.Contains(t, msg.Text, link)
+		assert.Contains(t, msg.Text, "December 15, 2025")
+	})
+
+	t.Run("Session digest", func(t *testing.T) {
+		location := "Room <B>"
+		msg, err := mailer.Render(mailer.TemplateSessionDigest, mailer.SessionDigestData{
+			FirstName: "Ada",
+			Date:      "Tuesday, September 2",
+			Sessions: []mailer.SessionSummary{
+				{Name: "Articulation", Start: "9:00 AM", Time: "9:00 AM - 9:30 AM", Location: location, Students: "Emma Johnson, Liam Smith"},
+				{Name: "Fluency", Start: "1:00 PM", Time: "1:00 PM - 1:45 PM"},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Your sessions for Tuesday, September 2", msg.Subject)
+		assert.Contains(t, msg.Text, "9:00 AM - 9:30 AM  Articulation (Room <B>)\n  Students: Emma Johnson, Liam Smith\n")
+		assert.Contains(t, msg.Text, "1:00 PM - 1:45 PM  Fluency\n")
+		assert.Contains(t, msg.HTML, "Room &lt;B&gt;")
+		assert.Contains(t, msg.HTML, "Emma Johnson, Liam Smith")
+	})
+
+	t.Run("Session reminder", func(t *testing.T) {
+		msg, err := mailer.Render(mailer.TemplateSessionReminder, mailer.SessionReminderData{
+			FirstName:       "Ada",
+			StartsInMinutes: 15,
+			Session:         mailer.SessionSummary{Name: "Articulation", Start: "9:00 AM", Time: "9:00 AM - 9:30 AM", Students: "Emma Johnson"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Reminder: Articulation at 9:00 AM", msg.Subject)
+		assert.Contains(t, msg.Text, "starts in 15 minutes")
+		assert.Contains(t, msg.Text, "Students: Emma Johnson")
+		assert.Contains(t, msg.HTML, "<strong>15 minutes</strong>")
+	})
+
+	t.Run("Unknown template", func(t *testing.T) {
+		_, err := mailer.Render("nope", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestMailer_Send(t *testing.T) {
+	t.Run("Delivered right away", func(t *testing.T) {
+		backend := mailer.NewLocalBackend("")
+		outbox := new(mocks.MockEmailOutboxRepository)
+		expectEnqueue(outbox, mock.MatchedBy(func(input *models.CreateOutboxEmailInput) bool {
+			return input.Template == mailer.TemplateVerificationCode && input.MaxAttempts == 5 &&
+				input.NextAttemptAt.After(time.Now())
+		}))
+		outbox.On("MarkEmailSent", mock.Anything, mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+		m := mailer.New(backend, outbox, "The Special Standard <noreply@example.com>")
+		email, err := m.Send(context.Background(), []string{"therapist@example.com"}, mailer.TemplateVerificationCode, mailer.VerificationCodeData{Code: "123456", ExpiresInMinutes: 10})
+		require.NoError(t, err)
+
+		assert.Equal(t, models.EmailSent, email.Status)
+		require.Len(t, backend.Sent(), 1)
+		assert.Equal(t, []string{"therapist@example.com"}, backend.Sent()[0].To)
+		assert.Contains(t, backend.Sent()[0].Text, "123456")
+		outbox.AssertExpectations(t)
+	})
+
+	t.Run("Failed delivery is scheduled for retry", func(t *testing.T) {
+		outbox := new(mocks.MockEmailOutboxRepository)
+		expectEnqueue(outbox, mock.Anything)
+		outbox.On("MarkEmailFailed", mock.Anything, mock.Anything, "connection refused", mock.MatchedBy(func(retryAt *time.Time) bool {
+			return retryAt != nil && time.Until(*retryAt) > 55*time.Second && time.Until(*retryAt) <= time.Minute
+		})).Return(nil)
+
+		m := mailer.New(failingBackend{}, outbox, "noreply@example.com")
+		email, err := m.Send(context.Background(), []string{"therapist@example.com"}, mailer.TemplatePasswordReset, mailer.PasswordResetData{ResetURL: "https://example.com"})
+		require.NoError(t, err)
+
+		assert.Equal(t, models.EmailPending, email.Status)
+		outbox.AssertExpectations(t)
+	})
+
+	t.Run("Outbox unavailable", func(t *testing.T) {
+		outbox := new(mocks.MockEmailOutboxRepository)
+		outbox.On("EnqueueEmail", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+		backend := mailer.NewLocalBackend("")
+		_, err := mailer.New(backend, outbox, "noreply@example.com").Send(context.Background(), []string{"a@example.com"}, mailer.TemplatePasswordReset, mailer.PasswordResetData{})
+		assert.Error(t, err)
+		assert.Empty(t, backend.Sent())
+	})
+}
+
+func TestMailer_RetryDue(t *testing.T) {
+	delivered := models.OutboxEmail{ID: uuid.New(), Recipients: []string{"a@example.com"}, Subject: "Hi", Attempts: 1, MaxAttempts: 5}
+	exhausted := models.OutboxEmail{ID: uuid.New(), Recipients: []string{"b@example.com"}, Subject: "Hi", Attempts: 4, MaxAttempts: 5}
+
+	t.Run("Sends due emails", func(t *testing.T) {
+		outbox := new(mocks.MockEmailOutboxRepository)
+		outbox.On("ClaimDueEmails", mock.Anything, 20, 5*time.Minute).Return([]models.OutboxEmail{delivered}, nil)
+		outbox.On("MarkEmailSent", mock.Anything, delivered.ID, mock.AnythingOfType("string")).Return(nil)
+
+		sent, err := mailer.New(mailer.NewLocalBackend(""), outbox, "noreply@example.com").RetryDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		outbox.AssertExpectations(t)
+	})
+
+	t.Run("Gives up after the last attempt", func(t *testing.T) {
+		outbox := new(mocks.MockEmailOutboxRepository)
+		outbox.On("ClaimDueEmails", mock.Anything, 20, 5*time.Minute).Return([]models.OutboxEmail{exhausted}, nil)
+		outbox.On("MarkEmailFailed", mock.Anything, exhausted.ID, "connection refused", (*time.Time)(nil)).Return(nil)
+
+		sent, err := mailer.New(failingBackend{}, outbox, "noreply@example.com").RetryDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		outbox.AssertExpectations(t)
+	})
+}
+
+func TestLocalBackend_WritesEML(t *testing.T) {
+	dir := t.TempDir()
+	backend := mailer.NewLocalBackend(dir)
+
+	_, err := backend.Deliver(context.Background(), "The Special Standard <noreply@example.com>", mailer.Message{
+		To:      []string{"therapist@example.com"},
+		Subject: "Ünïcode subject",
+		HTML:    "<p>Hello</p>",
+		Text:    "Hello",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	eml := string(raw)
+	assert.Contains(t, eml, "To: therapist@example.com\r\n")
+	assert.Contains(t, eml, "Subject: =?UTF-8?q?")
+	assert.Contains(t, eml, "multipart/alternative")
+	assert.Contains(t, eml, "text/plain; charset=UTF-8")
+	assert.Contains(t, eml, "<p>Hello</p>")
+}
+
+func TestResendBackend(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/emails", r.URL.Path)
+		assert.Equal(t, "Bearer re_test", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "49a3999c-0ce1-4ea6-ab68-afcd6dc2e794"}`))
+	}))
+	defer ts.Close()
+
+	backend := mailer.NewResendBackend("re_test")
+	baseURL, _ := url.Parse(ts.URL + "/")
+	mailer.ResendClient(backend).BaseURL = baseURL
+
+	id, err := backend.Deliver(context.Background(), "noreply@example.com", mailer.Message{To: []string{"a@example.com"}, Subject: "Hi", Text: "Hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "49a3999c-0ce1-4ea6-ab68-afcd6dc2e794", id)
+}
+
+func TestNewBackend(t *testing.T) {
+	tests := []struct {
+		name     string
+		mail     config.Mail
+		resend   config.Resend
+		expected any
+		wantErr  bool
+	}{
+		{name: "Local without Resend key", expected: &mailer.LocalBackend{}},
+		{name: "Resend with key", resend: config.Resend{APIKey: "re_test"}, expected: &mailer.ResendBackend{}},
+		{name: "Explicit local", mail: config.Mail{Backend: "local"}, resend: config.Resend{APIKey: "re_test"}, expected: &mailer.LocalBackend{}},
+		{name: "SMTP", mail: config.Mail{Backend: "smtp", SMTPHost: "smtp.example.com", SMTPPort: 587}, expected: &mailer.SMTPBackend{}},
+		{name: "SMTP without host", mail: config.Mail{Backend: "smtp"}, wantErr: true},
+		{name: "Resend without key", mail: config.Mail{Backend: "resend"}, wantErr: true},
+		{name: "Unknown", mail: config.Mail{Backend: "carrier-pigeon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := mailer.NewBackend(tt.mail, tt.resend)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.expected, backend)
+		})
+	}
+
+	assert.Equal(t, "Override <o@example.com>", mailer.FromAddress(config.Mail{From: "Override <o@example.com>"}, config.Resend{FromEmail: "r@example.com"}))
+	assert.Equal(t, "r@example.com", mailer.FromAddress(config.Mail{}, config.Resend{FromEmail: "r@example.com"}))
+}
@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/resend/resend-go/v3"
+)
+
+// ResendBackend delivers through the Resend API
+type ResendBackend struct {
+	client *resend.Client
+}
+
+func NewResendBackend(apiKey string) *ResendBackend {
+	return &ResendBackend{client: resend.NewClient(apiKey)}
+}
+
+func (b *ResendBackend) Deliver(ctx context.Context, from string, msg Message) (string, error) {
+	sent, err := b.client.Emails.SendWithContext(ctx, &resend.SendEmailRequest{
+		From:    from,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Html:    msg.HTML,
+		Text:    msg.Text,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return sent.Id, nil
+}
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// SMTPBackend delivers through any SMTP relay. STARTTLS is used when the server offers it.
+type SMTPBackend struct {
+	host string
+	addr string
+	auth smtp.Auth
+}
+
+func NewSMTPBackend(host string, port int, username, password string) *SMTPBackend {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPBackend{
+		host: host,
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth: auth,
+	}
+}
+
+func (b *SMTPBackend) Deliver(ctx context.Context, from string, msg Message) (string, error) {
+	// The envelope needs the bare address of "Name <address>"
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("invalid from address %q: %w", from, err)
+	}
+
+	messageID := fmt.Sprintf("<%s@%s>", uuid.NewString(), b.host)
+	raw, err := buildMIME(from, msg, messageID)
+	if err != nil {
+		return "", err
+	}
+
+	// net/smtp has no context support, at least do not start when already cancelled
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if err := smtp.SendMail(b.addr, b.auth, sender.Address, msg.To, raw); err != nil {
+		return "", err
+	}
+
+	return messageID, nil
+}
@@ -0,0 +1,110 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+const (
+	TemplateVerificationCode = "verification_code"
+	TemplatePasswordReset    = "password_reset"
+	TemplateInvitation       = "invitation"
+	TemplateSessionDigest    = "session_digest"
+	TemplateSessionReminder  = "session_reminder"
+)
+
+// VerificationCodeData fills TemplateVerificationCode
+type VerificationCodeData struct {
+	Code             string
+	ExpiresInMinutes int
+}
+
+// PasswordResetData fills TemplatePasswordReset
+type PasswordResetData struct {
+	ResetURL string
+}
+
+// InvitationData fills TemplateInvitation
+type InvitationData struct {
+	DistrictName string
+	AcceptURL    string
+	ExpiresOn    string
+}
+
+// SessionSummary is a session as told to its therapist, with times in their time zone
+type SessionSummary struct {
+	Name     string
+	Start    string
+	Time     string
+	Location string
+	// Students is the names of the session's students, comma separated
+	Students string
+}
+
+// SessionDigestData fills TemplateSessionDigest
+type SessionDigestData struct {
+	FirstName string
+	Date      string
+	Sessions  []SessionSummary
+}
+
+// SessionReminderData fills TemplateSessionReminder
+type SessionReminderData struct {
+	FirstName       string
+	StartsInMinutes int
+	Session         SessionSummary
+}
+
+// Every template is a pair of <name>.html.tmpl and <name>.txt.tmpl. The subject is the
+// "subject" block of the text template.
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+type templatePair struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var templates = mustParseTemplates(TemplateVerificationCode, TemplatePasswordReset, TemplateInvitation,
+	TemplateSessionDigest, TemplateSessionReminder)
+
+func mustParseTemplates(names ...string) map[string]templatePair {
+	parsed := make(map[string]templatePair, len(names))
+	for _, name := range names {
+		parsed[name] = templatePair{
+			html: htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html.tmpl", "templates/"+name+".html.tmpl")),
+			text: texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/"+name+".txt.tmpl")),
+		}
+	}
+	return parsed
+}
+
+// Render fills the named template with data
+func Render(name string, data any) (Message, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return Message{}, fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, fmt.Errorf("render %s html: %w", name, err)
+	}
+
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
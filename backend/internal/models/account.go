@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What happens to the students of a therapist deleting their account
+const (
+	StudentActionTransfer = "transfer"
+	StudentActionArchive  = "archive"
+)
+
+type DeleteAccountInput struct {
+	StudentAction string     `json:"student_action" validate:"omitempty,oneof=transfer archive"`
+	TransferTo    *uuid.UUID `json:"transfer_to" validate:"required_if=StudentAction transfer"`
+}
+
+// TherapistExport is everything stored about a therapist and their caseload, as it is in
+// the database
+type TherapistExport struct {
+	ExportedAt      time.Time         `json:"exported_at"`
+	Therapist       json.RawMessage   `json:"therapist"`
+	Delegates       []json.RawMessage `json:"delegates"`
+	Students        []json.RawMessage `json:"students"`
+	SessionParents  []json.RawMessage `json:"session_parents"`
+	Sessions        []json.RawMessage `json:"sessions"`
+	SessionStudents []json.RawMessage `json:"session_students"`
+	SessionRatings  []json.RawMessage `json:"session_ratings"`
+	GameResults     []json.RawMessage `json:"game_results"`
+	SessionNotes    []json.RawMessage `json:"session_notes"`
+	ServiceMandates []json.RawMessage `json:"service_mandates"`
+	MakeupLedger    []json.RawMessage `json:"makeup_ledger"`
+}
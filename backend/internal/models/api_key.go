@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes an API key can be granted. Each covers a group of endpoints, read for GET
+// requests and write for everything else.
+const (
+	ScopeStudentsRead     = "students:read"
+	ScopeStudentsWrite    = "students:write"
+	ScopeSessionsRead     = "sessions:read"
+	ScopeSessionsWrite    = "sessions:write"
+	ScopeTherapistsRead   = "therapists:read"
+	ScopeTherapistsWrite  = "therapists:write"
+	ScopeDistrictsRead    = "districts:read"
+	ScopeResourcesRead    = "resources:read"
+	ScopeResourcesWrite   = "resources:write"
+	ScopeGameResultsRead  = "game_results:read"
+	ScopeGameResultsWrite = "game_results:write"
+)
+
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	DistrictID *int       `json:"district_id" db:"district_id"`
+	CreatedBy  uuid.UUID  `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+}
+
+// CreatedAPIKey is only returned when the key is minted, it is the one time the key
+// itself can be seen
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type CreateAPIKeyInput struct {
+	Name       string     `json:"name" validate:"required,min=1,max=100"`
+	Scopes     []string   `json:"scopes" validate:"required,min=1,dive,oneof=students:read students:write sessions:read sessions:write therapists:read therapists:write districts:read resources:read resources:write game_results:read game_results:write"`
+	DistrictID *int       `json:"district_id" validate:"omitempty,min=1"`
+	ExpiresAt  *time.Time `json:"expires_at" validate:"omitempty"`
+}
+
+type UpdateAPIKeyInput struct {
+	Name   *string   `json:"name" validate:"omitempty,min=1,max=100"`
+	Scopes *[]string `json:"scopes" validate:"omitempty,min=1,dive,oneof=students:read students:write sessions:read sessions:write therapists:read therapists:write districts:read resources:read resources:write game_results:read game_results:write"`
+}
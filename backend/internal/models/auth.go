@@ -16,6 +16,10 @@ type SignInResponse struct {
 	User         userResponse `json:"user"`
 	Error        interface{}  `json:"error"`
 	RequiresMFA  bool         `json:"needs_mfa"`
+	// Set when RequiresMFA: email or totp. For totp the tokens above are withheld and
+	// MFAToken is traded for them at /auth/mfa/verify.
+	MFAMethod string `json:"mfa_method,omitempty"`
+	MFAToken  string `json:"mfa_token,omitempty"`
 }
 
 type Payload struct {
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// AuthAttempt counts consecutive failures for a throttled key (an email, an IP, a user)
+type AuthAttempt struct {
+	Key           string     `json:"key" db:"key"`
+	Failures      int        `json:"failures" db:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at" db:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+}
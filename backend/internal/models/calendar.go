@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeed is a therapist's secret iCalendar subscription. The token in its URL is
+// only known when the feed is created or rotated.
+type CalendarFeed struct {
+	TherapistID         uuid.UUID `json:"therapist_id" db:"therapist_id"`
+	IncludeStudentNames bool      `json:"include_student_names" db:"include_student_names"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreatedCalendarFeed is returned when a feed token is minted, it is the one time the
+// feed URL can be seen
+type CreatedCalendarFeed struct {
+	CalendarFeed
+	URL string `json:"url"`
+}
+
+type CalendarFeedInput struct {
+	IncludeStudentNames *bool `json:"include_student_names"`
+}
+
+// CalendarSession is a session with what the feed needs to describe it
+type CalendarSession struct {
+	Session
+	StudentFirstNames []string `json:"student_first_names"`
+}
@@ -1,10 +1,38 @@
 package models
 
-import "time"
+import (
+	"specialstandard/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type District struct {
 	ID        int       `db:"id" json:"id"`
 	Name      string    `db:"name" json:"name"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-}
\ No newline at end of file
+}
+
+type GetDistrictStudentsQuery struct {
+	SchoolID *int `query:"school_id" validate:"omitempty,min=1"`
+	utils.Pagination
+}
+
+type GetDistrictSessionsQuery struct {
+	StartTime   *time.Time `query:"startdate" validate:"omitempty"`
+	EndTime     *time.Time `query:"enddate" validate:"omitempty"`
+	TherapistID *uuid.UUID `query:"therapist_id" validate:"omitempty"`
+}
+
+// StudentAttendance is one row of a district attendance report
+type StudentAttendance struct {
+	StudentID    uuid.UUID `json:"student_id" db:"student_id"`
+	FirstName    string    `json:"first_name" db:"first_name"`
+	LastName     string    `json:"last_name" db:"last_name"`
+	SchoolID     int       `json:"school_id" db:"school_id"`
+	SchoolName   string    `json:"school_name" db:"school_name"`
+	TherapistID  uuid.UUID `json:"therapist_id" db:"therapist_id"`
+	PresentCount int       `json:"present_count" db:"present_count"`
+	TotalCount   int       `json:"total_count" db:"total_count"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EmailPending = "pending"
+	EmailSent    = "sent"
+	EmailFailed  = "failed"
+)
+
+// OutboxEmail is a rendered email waiting for, or done with, delivery
+type OutboxEmail struct {
+	ID                uuid.UUID  `json:"id" db:"id"`
+	Recipients        []string   `json:"recipients" db:"recipients"`
+	Subject           string     `json:"subject" db:"subject"`
+	HTMLBody          string     `json:"html_body" db:"html_body"`
+	TextBody          string     `json:"text_body" db:"text_body"`
+	Template          string     `json:"template" db:"template"`
+	Status            string     `json:"status" db:"status"`
+	Attempts          int        `json:"attempts" db:"attempts"`
+	MaxAttempts       int        `json:"max_attempts" db:"max_attempts"`
+	LastError         *string    `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt     time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	ProviderMessageID *string    `json:"provider_message_id,omitempty" db:"provider_message_id"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	SentAt            *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+}
+
+type CreateOutboxEmailInput struct {
+	Recipients    []string
+	Subject       string
+	HTMLBody      string
+	TextBody      string
+	Template      string
+	MaxAttempts   int
+	NextAttemptAt time.Time
+}
@@ -19,6 +19,7 @@ type GameResult struct {
 }
 
 type GetGameResultQuery struct {
+	TherapistID     *uuid.UUID `query:"therapist_id" validate:"omitempty,uuid"`
 	SessionID       *uuid.UUID `query:"session_id" validate:"omitempty,uuid"`
 	StudentID       *uuid.UUID `query:"student_id" validate:"omitempty,uuid"`
 	Category        *string    `query:"category" validate:"omitempty,oneof=receptive_language expressive_language social_pragmatic_language speech"`
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where an invitation stands. Only pending invitations can be accepted or revoked.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+type Invitation struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Email      string     `json:"email" db:"email"`
+	FirstName  *string    `json:"first_name" db:"first_name"`
+	LastName   *string    `json:"last_name" db:"last_name"`
+	DistrictID int        `json:"district_id" db:"district_id"`
+	Schools    []int      `json:"schools" db:"schools"`
+	InvitedBy  *uuid.UUID `json:"invited_by" db:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at" db:"accepted_at"`
+	AcceptedBy *uuid.UUID `json:"accepted_by" db:"accepted_by"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Status works out where the invitation stands at now
+func (i *Invitation) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !i.ExpiresAt.After(now):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+type CreateInvitationInput struct {
+	Email     string  `json:"email" validate:"required,email,max=255"`
+	FirstName *string `json:"first_name" validate:"omitempty,min=1,max=255"`
+	LastName  *string `json:"last_name" validate:"omitempty,min=1,max=255"`
+	Schools   []int   `json:"schools" validate:"required,min=1,dive,min=1"`
+}
+
+// AcceptInvitationInput sets up the invited therapist's login. Names left out are taken
+// from the invitation.
+type AcceptInvitationInput struct {
+	Token     string  `json:"token" validate:"required"`
+	Password  string  `json:"password" validate:"required"`
+	FirstName *string `json:"first_name" validate:"omitempty,min=1,max=255"`
+	LastName  *string `json:"last_name" validate:"omitempty,min=1,max=255"`
+}
+
+// InvitationPreview is what the accept page shows before the therapist signs up
+type InvitationPreview struct {
+	Email        string    `json:"email"`
+	FirstName    *string   `json:"first_name"`
+	LastName     *string   `json:"last_name"`
+	DistrictID   int       `json:"district_id"`
+	DistrictName string    `json:"district_name"`
+	SchoolNames  []string  `json:"school_names"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
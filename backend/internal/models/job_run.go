@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"specialstandard/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobPromoteStudents promotes a therapist's students a grade, with PromoteStudentsInput as
+// its payload
+const JobPromoteStudents = "promote_students"
+
+// JobRun is one run of a background job, queued, running or done with
+type JobRun struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	Name        string          `json:"name" db:"name"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	Status      string          `json:"status" db:"status"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	MaxAttempts int             `json:"max_attempts" db:"max_attempts"`
+	RunAt       time.Time       `json:"run_at" db:"run_at"`
+	DedupeKey   *string         `json:"dedupe_key,omitempty" db:"dedupe_key"`
+	LockedBy    *string         `json:"locked_by,omitempty" db:"locked_by"`
+	LockedUntil *time.Time      `json:"locked_until,omitempty" db:"locked_until"`
+	LastError   *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+type EnqueueJobInput struct {
+	Name    string
+	Payload json.RawMessage
+	RunAt   time.Time
+	// MaxAttempts left at zero takes the table's default
+	MaxAttempts int
+	// DedupeKey, when set, queues the run only if no run with the same key was queued before
+	DedupeKey *string
+}
+
+type GetJobRunsQuery struct {
+	Name   string `query:"name"`
+	Status string `query:"status" validate:"omitempty,oneof=pending running succeeded failed"`
+	utils.Pagination
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of makeup ledger entries
+const (
+	MakeupMissed = "missed"
+	MakeupMadeUp = "made_up"
+)
+
+// MakeupEntry is a line of a student's makeup ledger: the minutes owed for a session
+// that was cancelled or that the student missed, or the minutes a makeup session made up,
+// as a negative number. Entries follow the sessions, so restoring a session or marking
+// the student present removes the minutes owed.
+type MakeupEntry struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	StudentID uuid.UUID `json:"student_id" db:"student_id"`
+	SessionID uuid.UUID `json:"session_id" db:"session_id"`
+	Kind      string    `json:"kind" db:"kind"`
+	Minutes   int       `json:"minutes" db:"minutes"`
+	// Reason is why a session was missed: the status of the session, or student_absent
+	// for a student marked absent from it
+	Reason             *string    `json:"reason" db:"reason"`
+	MakeupForSessionID *uuid.UUID `json:"makeup_for_session_id" db:"makeup_for_session_id"`
+	SessionName        string     `json:"session_name" db:"session_name"`
+	StartDateTime      time.Time  `json:"start_datetime" db:"start_datetime"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// MakeupBalance is what a student is owed for missed sessions, and what was made up
+type MakeupBalance struct {
+	StudentID      uuid.UUID     `json:"student_id"`
+	OwedMinutes    int           `json:"owed_minutes"`
+	MadeUpMinutes  int           `json:"made_up_minutes"`
+	BalanceMinutes int           `json:"balance_minutes"`
+	Entries        []MakeupEntry `json:"entries"`
+}
+
+// NewMakeupBalance totals the student's ledger entries
+func NewMakeupBalance(studentID uuid.UUID, entries []MakeupEntry) *MakeupBalance {
+	balance := &MakeupBalance{StudentID: studentID, Entries: entries}
+	if balance.Entries == nil {
+		balance.Entries = []MakeupEntry{}
+	}
+	for _, e := range entries {
+		if e.Kind == MakeupMadeUp {
+			balance.MadeUpMinutes -= e.Minutes
+		} else {
+			balance.OwedMinutes += e.Minutes
+		}
+	}
+	balance.BalanceMinutes = balance.OwedMinutes - balance.MadeUpMinutes
+	return balance
+}
+
+// OwedMinutes are the minutes a student is still owed for a missed session
+type OwedMinutes struct {
+	StudentID uuid.UUID `json:"student_id" db:"student_id"`
+	Minutes   int       `json:"minutes" db:"minutes"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Second factors a login can ask for
+const (
+	MFAMethodEmail = "email"
+	MFAMethodTOTP  = "totp"
+)
+
+type TOTPFactor struct {
+	TherapistID            uuid.UUID  `json:"therapist_id" db:"therapist_id"`
+	SecretSealed           string     `json:"-" db:"secret_sealed"`
+	Enabled                bool       `json:"enabled" db:"enabled"`
+	LastUsedStep           int64      `json:"-" db:"last_used_step"`
+	CreatedAt              time.Time  `json:"created_at" db:"created_at"`
+	ConfirmedAt            *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining" db:"recovery_codes_remaining"`
+}
+
+type MFAStatus struct {
+	TOTPEnabled            bool       `json:"totp_enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollment is shown once so the secret can be added to an authenticator app, either
+// by scanning the URI as a QR code or typing the secret
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type MFACodeInput struct {
+	Code string `json:"code" validate:"required,min=6,max=32"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallenge is a password login waiting for its second factor
+type MFAChallenge struct {
+	ID           uuid.UUID `db:"id"`
+	TherapistID  uuid.UUID `db:"therapist_id"`
+	RefreshToken string    `db:"refresh_token"`
+	RememberMe   bool      `db:"remember_me"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
+
+type VerifyMFAInput struct {
+	// Falls back to the mfaToken cookie set by the login
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,min=6,max=32"`
+}
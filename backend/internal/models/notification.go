@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Channels session notifications are sent through
+const (
+	NotificationEmail = "email"
+	NotificationNone  = "none"
+)
+
+// Kinds of session notifications
+const (
+	NotificationDigest   = "digest"
+	NotificationReminder = "reminder"
+)
+
+// NotificationPreference is how a therapist is told about their sessions: a digest of the
+// day's sessions at DigestTime and a reminder ReminderLeadMinutes before each one. Times
+// are in the therapist's time zone. A therapist who never saved preferences gets the
+// defaults, with nothing sent.
+type NotificationPreference struct {
+	TherapistID   uuid.UUID `json:"therapist_id" db:"therapist_id"`
+	Channel       string    `json:"channel" db:"channel"`
+	DigestEnabled bool      `json:"digest_enabled" db:"digest_enabled"`
+	// DigestTime is HH:MM
+	DigestTime          string     `json:"digest_time" db:"digest_time"`
+	ReminderEnabled     bool       `json:"reminder_enabled" db:"reminder_enabled"`
+	ReminderLeadMinutes int        `json:"reminder_lead_minutes" db:"reminder_lead_minutes"`
+	TimeZone            string     `json:"time_zone" db:"time_zone"`
+	CreatedAt           *time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt           *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateNotificationPreferenceInput struct {
+	Channel             *string `json:"channel" validate:"omitempty,oneof=email none"`
+	DigestEnabled       *bool   `json:"digest_enabled"`
+	DigestTime          *string `json:"digest_time" validate:"omitempty,datetime=15:04"`
+	ReminderEnabled     *bool   `json:"reminder_enabled"`
+	ReminderLeadMinutes *int    `json:"reminder_lead_minutes" validate:"omitempty,min=1,max=1440"`
+}
+
+// NotificationRecipient is a therapist to be sent notifications, with their preferences
+type NotificationRecipient struct {
+	NotificationPreference
+	Email     string `json:"email" db:"email"`
+	FirstName string `json:"first_name" db:"first_name"`
+}
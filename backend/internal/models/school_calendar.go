@@ -0,0 +1,111 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of school calendar events. Every kind but an early release is a day without
+// classes, which recurring sessions skip.
+const (
+	CalendarHoliday      = "holiday"
+	CalendarBreak        = "break"
+	CalendarClosure      = "closure"
+	CalendarEarlyRelease = "early_release"
+)
+
+// SchoolCalendarEvent is a holiday, break, closure or early release of a whole district,
+// or of one school when SchoolID is set. Both dates are inclusive.
+type SchoolCalendarEvent struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	DistrictID int       `json:"district_id" db:"district_id"`
+	SchoolID   *int      `json:"school_id" db:"school_id"`
+	Name       string    `json:"name" db:"name"`
+	Kind       string    `json:"kind" db:"kind"`
+	StartDate  time.Time `json:"start_date" db:"start_date"`
+	EndDate    time.Time `json:"end_date" db:"end_date"`
+	// DismissalTime is when classes end on an early release day, as HH:MM
+	DismissalTime *string   `json:"dismissal_time" db:"dismissal_time"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NoClasses reports whether the event is a day, or days, without classes
+func (e *SchoolCalendarEvent) NoClasses() bool {
+	return e.Kind != CalendarEarlyRelease
+}
+
+type GetSchoolCalendarQuery struct {
+	// SchoolID limits the calendar to the district wide events and those of the school
+	SchoolID *int       `query:"school_id" validate:"omitempty,min=1"`
+	From     *time.Time `query:"from" validate:"omitempty"`
+	To       *time.Time `query:"to" validate:"omitempty"`
+}
+
+// CreateSchoolCalendarEventInput adds an event to a district's calendar. EndDate defaults
+// to StartDate for a single day.
+type CreateSchoolCalendarEventInput struct {
+	SchoolID      *int       `json:"school_id" validate:"omitempty,min=1"`
+	Name          string     `json:"name" validate:"required,min=1,max=255"`
+	Kind          string     `json:"kind" validate:"required,oneof=holiday break closure early_release"`
+	StartDate     time.Time  `json:"start_date" validate:"required"`
+	EndDate       *time.Time `json:"end_date"`
+	DismissalTime *string    `json:"dismissal_time" validate:"omitempty,datetime=15:04"`
+}
+
+// Event is the event the input creates in the district
+func (in *CreateSchoolCalendarEventInput) Event(districtID int) SchoolCalendarEvent {
+	e := SchoolCalendarEvent{
+		DistrictID:    districtID,
+		SchoolID:      in.SchoolID,
+		Name:          in.Name,
+		Kind:          in.Kind,
+		StartDate:     in.StartDate,
+		EndDate:       in.StartDate,
+		DismissalTime: in.DismissalTime,
+	}
+	if in.EndDate != nil {
+		e.EndDate = *in.EndDate
+	}
+	return e
+}
+
+// UpdateSchoolCalendarEventInput changes the fields given. A school_id of 0 makes the
+// event district wide.
+type UpdateSchoolCalendarEventInput struct {
+	SchoolID      *int       `json:"school_id" validate:"omitempty,min=0"`
+	Name          *string    `json:"name" validate:"omitempty,min=1,max=255"`
+	Kind          *string    `json:"kind" validate:"omitempty,oneof=holiday break closure early_release"`
+	StartDate     *time.Time `json:"start_date"`
+	EndDate       *time.Time `json:"end_date"`
+	DismissalTime *string    `json:"dismissal_time" validate:"omitempty,datetime=15:04"`
+}
+
+// Apply changes the event as the input describes
+func (in *UpdateSchoolCalendarEventInput) Apply(e *SchoolCalendarEvent) {
+	if in.SchoolID != nil {
+		e.SchoolID = in.SchoolID
+		if *in.SchoolID == 0 {
+			e.SchoolID = nil
+		}
+	}
+	if in.Name != nil {
+		e.Name = *in.Name
+	}
+	if in.Kind != nil {
+		e.Kind = *in.Kind
+		if e.Kind != CalendarEarlyRelease {
+			e.DismissalTime = nil
+		}
+	}
+	if in.StartDate != nil {
+		e.StartDate = *in.StartDate
+	}
+	if in.EndDate != nil {
+		e.EndDate = *in.EndDate
+	}
+	if in.DismissalTime != nil {
+		e.DismissalTime = in.DismissalTime
+	}
+}
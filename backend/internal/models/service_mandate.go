@@ -0,0 +1,325 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How often a mandate's minutes are owed. Weeks start on Sunday; both weeks and months
+// follow the calendar of the student's therapist.
+const (
+	MandateWeekly  = "weekly"
+	MandateMonthly = "monthly"
+)
+
+// Settings a mandate's minutes are delivered in. Individual minutes come from sessions
+// with the student alone, group minutes from sessions with other students.
+const (
+	MandateIndividual = "individual"
+	MandateGroup      = "group"
+)
+
+// ServiceMandate is a service the student's IEP requires: Minutes every week or month,
+// from StartDate to EndDate inclusive
+type ServiceMandate struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	StudentID uuid.UUID `json:"student_id" db:"student_id"`
+	Service   string    `json:"service" db:"service"`
+	Minutes   int       `json:"minutes" db:"minutes"`
+	Frequency string    `json:"frequency" db:"frequency"`
+	Setting   string    `json:"setting" db:"setting"`
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	EndDate   time.Time `json:"end_date" db:"end_date"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateServiceMandateInput struct {
+	Service   string    `json:"service" validate:"required,min=1,max=255"`
+	Minutes   int       `json:"minutes" validate:"required,min=1,max=10080"`
+	Frequency string    `json:"frequency" validate:"required,oneof=weekly monthly"`
+	Setting   string    `json:"setting" validate:"required,oneof=individual group"`
+	StartDate time.Time `json:"start_date" validate:"required"`
+	EndDate   time.Time `json:"end_date" validate:"required"`
+}
+
+// Mandate is the mandate the input creates for the student
+func (in *CreateServiceMandateInput) Mandate(studentID uuid.UUID) ServiceMandate {
+	return ServiceMandate{
+		StudentID: studentID,
+		Service:   in.Service,
+		Minutes:   in.Minutes,
+		Frequency: in.Frequency,
+		Setting:   in.Setting,
+		StartDate: in.StartDate,
+		EndDate:   in.EndDate,
+	}
+}
+
+// UpdateServiceMandateInput changes the fields given
+type UpdateServiceMandateInput struct {
+	Service   *string    `json:"service" validate:"omitempty,min=1,max=255"`
+	Minutes   *int       `json:"minutes" validate:"omitempty,min=1,max=10080"`
+	Frequency *string    `json:"frequency" validate:"omitempty,oneof=weekly monthly"`
+	Setting   *string    `json:"setting" validate:"omitempty,oneof=individual group"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+}
+
+// Apply changes the mandate as the input describes
+func (in *UpdateServiceMandateInput) Apply(m *ServiceMandate) {
+	if in.Service != nil {
+		m.Service = *in.Service
+	}
+	if in.Minutes != nil {
+		m.Minutes = *in.Minutes
+	}
+	if in.Frequency != nil {
+		m.Frequency = *in.Frequency
+	}
+	if in.Setting != nil {
+		m.Setting = *in.Setting
+	}
+	if in.StartDate != nil {
+		m.StartDate = *in.StartDate
+	}
+	if in.EndDate != nil {
+		m.EndDate = *in.EndDate
+	}
+}
+
+// GetComplianceQuery is the date range of a compliance report, as YYYY-MM-DD. It defaults
+// to the current month.
+type GetComplianceQuery struct {
+	TherapistID string `query:"therapist_id" validate:"omitempty,uuid"`
+	From        string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To          string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// ServiceRecordQuery selects the students a compliance report covers: one student, or
+// the students of a therapist with a mandate in the range
+type ServiceRecordQuery struct {
+	StudentID   *uuid.UUID
+	TherapistID *uuid.UUID
+	From        time.Time
+	To          time.Time
+}
+
+// ServiceDelivery is a session the student attended that counts towards a mandate
+type ServiceDelivery struct {
+	StudentID     uuid.UUID `db:"student_id"`
+	SessionID     uuid.UUID `db:"session_id"`
+	StartDateTime time.Time `db:"start_datetime"`
+	EndDateTime   time.Time `db:"end_datetime"`
+	// GroupSize is how many students the session was held for
+	GroupSize int `db:"group_size"`
+}
+
+// Setting is the setting the session delivered its minutes in
+func (d *ServiceDelivery) Setting() string {
+	if d.GroupSize > 1 {
+		return MandateGroup
+	}
+	return MandateIndividual
+}
+
+// ServiceRecord is what a student was mandated and delivered over a report's range
+type ServiceRecord struct {
+	StudentID   uuid.UUID         `db:"student_id"`
+	FirstName   string            `db:"first_name"`
+	LastName    string            `db:"last_name"`
+	TherapistID uuid.UUID         `db:"therapist_id"`
+	TimeZone    string            `db:"time_zone"`
+	Mandates    []ServiceMandate  `db:"-"`
+	Deliveries  []ServiceDelivery `db:"-"`
+}
+
+// ServiceTotals are the minutes a report owes, delivered and still has to make up
+type ServiceTotals struct {
+	OwedMinutes      int `json:"owed_minutes"`
+	DeliveredMinutes int `json:"delivered_minutes"`
+	MakeupMinutes    int `json:"makeup_needed_minutes"`
+}
+
+func (t *ServiceTotals) add(o ServiceTotals) {
+	t.OwedMinutes += o.OwedMinutes
+	t.DeliveredMinutes += o.DeliveredMinutes
+	t.MakeupMinutes += o.MakeupMinutes
+}
+
+type MandateCompliance struct {
+	ServiceMandate
+	ServiceTotals
+}
+
+type StudentCompliance struct {
+	StudentID   uuid.UUID `json:"student_id"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	TherapistID uuid.UUID `json:"therapist_id"`
+	ServiceTotals
+	Mandates []MandateCompliance `json:"mandates"`
+}
+
+type TherapistCompliance struct {
+	TherapistID  uuid.UUID `json:"therapist_id"`
+	StudentCount int       `json:"student_count"`
+	ServiceTotals
+}
+
+// ComplianceReport compares the minutes mandated from From to To with those delivered,
+// per student and per therapist
+type ComplianceReport struct {
+	From       time.Time             `json:"from"`
+	To         time.Time             `json:"to"`
+	Students   []StudentCompliance   `json:"students"`
+	Therapists []TherapistCompliance `json:"therapists"`
+}
+
+// NewComplianceReport totals the records from from to to, both dates inclusive.
+//
+// Minutes are owed per week or month of the mandate, prorated by the days of it in the
+// mandate, and never past today. A session counts towards the first mandate in effect on
+// its day with its setting, so minutes are not counted twice. Minutes a period fell short
+// by need a makeup, unless the period has not ended yet. Each period is settled over its
+// whole length, and a period the range only partly covers adds the range's share of it.
+func NewComplianceReport(records []ServiceRecord, from, to, now time.Time) *ComplianceReport {
+	report := &ComplianceReport{
+		From:       from,
+		To:         to,
+		Students:   make([]StudentCompliance, 0, len(records)),
+		Therapists: []TherapistCompliance{},
+	}
+
+	therapists := map[uuid.UUID]int{}
+	for _, rec := range records {
+		loc, err := time.LoadLocation(rec.TimeZone)
+		if err != nil {
+			loc = time.UTC
+		}
+		student := rec.compliance(dateOf(from), dateOf(to), dateOf(now.In(loc)), loc)
+		report.Students = append(report.Students, student)
+
+		i, ok := therapists[rec.TherapistID]
+		if !ok {
+			i = len(report.Therapists)
+			therapists[rec.TherapistID] = i
+			report.Therapists = append(report.Therapists, TherapistCompliance{TherapistID: rec.TherapistID})
+		}
+		report.Therapists[i].StudentCount++
+		report.Therapists[i].add(student.ServiceTotals)
+	}
+	return report
+}
+
+func (rec *ServiceRecord) compliance(from, to, today time.Time, loc *time.Location) StudentCompliance {
+	student := StudentCompliance{
+		StudentID:   rec.StudentID,
+		FirstName:   rec.FirstName,
+		LastName:    rec.LastName,
+		TherapistID: rec.TherapistID,
+		Mandates:    make([]MandateCompliance, 0, len(rec.Mandates)),
+	}
+
+	// Minutes delivered per mandate, keyed by day
+	delivered := make([]map[time.Time]int, len(rec.Mandates))
+	for i := range delivered {
+		delivered[i] = map[time.Time]int{}
+	}
+	for _, d := range rec.Deliveries {
+		day := dateOf(d.StartDateTime.In(loc))
+		for i, m := range rec.Mandates {
+			if m.Setting == d.Setting() && !day.Before(dateOf(m.StartDate)) && !day.After(dateOf(m.EndDate)) {
+				delivered[i][day] += int(d.EndDateTime.Sub(d.StartDateTime).Minutes())
+				break
+			}
+		}
+	}
+
+	for i, m := range rec.Mandates {
+		mc := MandateCompliance{ServiceMandate: m, ServiceTotals: m.totals(from, to, today, delivered[i])}
+		student.Mandates = append(student.Mandates, mc)
+		student.add(mc.ServiceTotals)
+	}
+	return student
+}
+
+// totals goes over the mandate's periods that overlap from to to. A period straddling
+// the range is settled over all of it, so sessions just outside the range still count
+// for its week or month, and the range gets the share of its days.
+func (m *ServiceMandate) totals(from, to, today time.Time, delivered map[time.Time]int) ServiceTotals {
+	var totals ServiceTotals
+
+	mandateStart, mandateEnd := dateOf(m.StartDate), earliest(dateOf(m.EndDate), today)
+	start, end := latest(from, mandateStart), earliest(to, mandateEnd)
+	if end.Before(start) {
+		return totals
+	}
+	for period := m.periodStart(start); !period.After(end); period = m.periodStart(m.periodEnd(period).AddDate(0, 0, 1)) {
+		periodEnd := m.periodEnd(period)
+		first, last := latest(period, mandateStart), earliest(periodEnd, mandateEnd)
+
+		days := daysBetween(first, last) + 1
+		periodDays := daysBetween(period, periodEnd) + 1
+		owed := (m.Minutes*days + periodDays/2) / periodDays
+
+		minutes := 0
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			minutes += delivered[day]
+		}
+		makeup := 0
+		if periodEnd.Before(today) && minutes < owed {
+			makeup = owed - minutes
+		}
+
+		inRange := daysBetween(latest(first, from), earliest(last, to)) + 1
+		share := func(n int) int { return (n*inRange + days/2) / days }
+		totals.OwedMinutes += share(owed)
+		totals.DeliveredMinutes += share(minutes)
+		totals.MakeupMinutes += share(makeup)
+	}
+	return totals
+}
+
+// periodStart is the first day of the week or month day is in
+func (m *ServiceMandate) periodStart(day time.Time) time.Time {
+	if m.Frequency == MandateMonthly {
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// periodEnd is the last day of the week or month starting on start
+func (m *ServiceMandate) periodEnd(start time.Time) time.Time {
+	if m.Frequency == MandateMonthly {
+		return start.AddDate(0, 1, -1)
+	}
+	return start.AddDate(0, 0, 6)
+}
+
+// dateOf is the calendar day of t, as midnight UTC so days can be compared and counted
+func dateOf(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func earliest(first time.Time, rest ...time.Time) time.Time {
+	for _, t := range rest {
+		if t.Before(first) {
+			first = t
+		}
+	}
+	return first
+}
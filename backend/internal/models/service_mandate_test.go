@@ -0,0 +1,59 @@
+package models_test
+
+import (
+	"specialstandard/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewComplianceReport_PartialWeeks(t *testing.T) {
+	studentID := uuid.New()
+	mandate := models.ServiceMandate{
+		ID:        uuid.New(),
+		StudentID: studentID,
+		Minutes:   60,
+		Frequency: models.MandateWeekly,
+		Setting:   models.MandateIndividual,
+		StartDate: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	// 60 minutes every Monday, the first of them before October starts on a Thursday
+	var deliveries []models.ServiceDelivery
+	for day := time.Date(2026, 9, 28, 14, 0, 0, 0, time.UTC); day.Month() <= time.November; day = day.AddDate(0, 0, 7) {
+		deliveries = append(deliveries, models.ServiceDelivery{
+			StudentID:     studentID,
+			SessionID:     uuid.New(),
+			StartDateTime: day,
+			EndDateTime:   day.Add(time.Hour),
+			GroupSize:     1,
+		})
+	}
+
+	report := models.NewComplianceReport([]models.ServiceRecord{{
+		StudentID:  studentID,
+		TimeZone:   "UTC",
+		Mandates:   []models.ServiceMandate{mandate},
+		Deliveries: deliveries,
+	}}, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC))
+
+	// Four whole weeks and three days of the week the Monday before October settled
+	assert.Equal(t, models.ServiceTotals{OwedMinutes: 266, DeliveredMinutes: 266, MakeupMinutes: 0},
+		report.Students[0].ServiceTotals)
+
+	// Without that Monday the week is short, and October owes its share of the makeup
+	report = models.NewComplianceReport([]models.ServiceRecord{{
+		StudentID:  studentID,
+		TimeZone:   "UTC",
+		Mandates:   []models.ServiceMandate{mandate},
+		Deliveries: deliveries[1:],
+	}}, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, models.ServiceTotals{OwedMinutes: 266, DeliveredMinutes: 240, MakeupMinutes: 26},
+		report.Students[0].ServiceTotals)
+}
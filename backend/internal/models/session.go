@@ -1,30 +1,247 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"specialstandard/internal/ical"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Statuses of a session. Missed sessions are cancelled, or marked as missed by the
+// student, instead of being deleted so service delivery can be reported on.
+const (
+	SessionScheduled            = "scheduled"
+	SessionCompleted            = "completed"
+	SessionCancelledByTherapist = "cancelled_by_therapist"
+	SessionCancelledBySchool    = "cancelled_by_school"
+	SessionStudentAbsent        = "student_absent"
+	SessionMakeup               = "makeup"
+)
+
 type Session struct {
-	ID              uuid.UUID   `json:"id" db:"id"`
-	SessionName     string      `json:"session_name" db:"session_name"`
-	StartDateTime   time.Time   `json:"start_datetime" db:"start_datetime"`
-	EndDateTime     time.Time   `json:"end_datetime" db:"end_datetime"`
-	TherapistID     uuid.UUID   `json:"therapist_id" db:"therapist_id"`
-	Notes           *string     `json:"notes" db:"notes"`
-	Location        *string     `json:"location" db:"location"`
-	CreatedAt       *time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt       *time.Time  `json:"updated_at" db:"updated_at"`
-	SessionParentID uuid.UUID   `json:"session_parent_id" db:"session_parent_id"`
-	Repetition      *Repetition `json:"repetition" db:"-"`
+	ID                 uuid.UUID   `json:"id" db:"id"`
+	SessionName        string      `json:"session_name" db:"session_name"`
+	StartDateTime      time.Time   `json:"start_datetime" db:"start_datetime"`
+	EndDateTime        time.Time   `json:"end_datetime" db:"end_datetime"`
+	TherapistID        uuid.UUID   `json:"therapist_id" db:"therapist_id"`
+	Notes              *string     `json:"notes" db:"notes"`
+	Location           *string     `json:"location" db:"location"`
+	Status             string      `json:"status" db:"status"`
+	StatusReason       *string     `json:"status_reason" db:"status_reason"`
+	MakeupForSessionID *uuid.UUID  `json:"makeup_for_session_id" db:"makeup_for_session_id"`
+	SessionTemplateID  *uuid.UUID  `json:"session_template_id" db:"session_template_id"`
+	CreatedAt          *time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          *time.Time  `json:"updated_at" db:"updated_at"`
+	SessionParentID    uuid.UUID   `json:"session_parent_id" db:"session_parent_id"`
+	Repetition         *Repetition `json:"repetition" db:"-"`
+}
+
+// Cancelled reports whether the session was called off, leaving its time free
+func (s *Session) Cancelled() bool {
+	return s.Status == SessionCancelledByTherapist || s.Status == SessionCancelledBySchool
+}
+
+// CancelSessionInput records why a session did not take place. Status defaults to a
+// cancellation by the therapist.
+type CancelSessionInput struct {
+	Status string  `json:"status" validate:"omitempty,oneof=cancelled_by_therapist cancelled_by_school student_absent"`
+	Reason *string `json:"reason" validate:"omitempty,max=1000"`
+}
+
+// SessionConflict is an existing session that overlaps a time being scheduled
+type SessionConflict struct {
+	SessionID     uuid.UUID `json:"session_id"`
+	SessionName   string    `json:"session_name"`
+	StartDateTime time.Time `json:"start_datetime"`
+	EndDateTime   time.Time `json:"end_datetime"`
+	TherapistID   uuid.UUID `json:"therapist_id"`
+	// StudentIDs are the students being scheduled who are already in the session
+	StudentIDs []uuid.UUID `json:"student_ids,omitempty"`
+}
+
+// SchedulingConflicts is the body of the 409 returned when a change would double-book a
+// therapist or a student. Repeating the request with allow_conflicts=true goes ahead anyway.
+type SchedulingConflicts struct {
+	Error     string            `json:"error"`
+	Conflicts []SessionConflict `json:"conflicts"`
+}
+
+type TimeSlot struct {
+	Start time.Time
+	End   time.Time
 }
 
+// ConflictCheck describes times about to be booked for a therapist and students
+type ConflictCheck struct {
+	// TherapistID is checked for other sessions at the same time, unless it is uuid.Nil
+	TherapistID uuid.UUID
+	StudentIDs  []uuid.UUID
+	Slots       []TimeSlot
+	// SessionIDs are existing sessions being moved to Slots. They do not conflict with
+	// themselves, and their students are checked along with StudentIDs.
+	SessionIDs []uuid.UUID
+	// SeriesID is a recurring series being rescheduled, its occurrences are replaced so
+	// they do not count
+	SeriesID *uuid.UUID
+}
+
+// SessionOverlap is a pair of existing sessions that double-book the therapist or, when
+// StudentIDs is set, those students
+type SessionOverlap struct {
+	Session       SessionConflict `json:"session"`
+	ConflictsWith SessionConflict `json:"conflicts_with"`
+	StudentIDs    []uuid.UUID     `json:"student_ids"`
+}
+
+type GetSessionConflictsQuery struct {
+	TherapistID string     `query:"therapist_id" validate:"required,uuid"`
+	StartTime   *time.Time `query:"startdate" validate:"omitempty"`
+	EndTime     *time.Time `query:"enddate" validate:"omitempty"`
+}
+
+// Repetition is either every EveryNWeeks weeks on Days, or an RFC 5545 RRule such as
+// "FREQ=MONTHLY;BYDAY=1TU,3TU". A rule with COUNT or UNTIL does not need a RecurEnd.
 type Repetition struct {
 	RecurStart  time.Time `json:"recur_start" validate:"required"`
-	RecurEnd    time.Time `json:"recur_end" validate:"required,gtfield=RecurStart"`
-	EveryNWeeks int       `json:"every_n_weeks" validate:"required,gte=1"`
-	Days        []int     `json:"days" validate:"required,dive,gte=0,lte=6"`
+	RecurEnd    time.Time `json:"recur_end" validate:"required_without=RRule,omitempty,gtfield=RecurStart"`
+	EveryNWeeks int       `json:"every_n_weeks" validate:"required_without=RRule,omitempty,gte=1"`
+	Days        []int     `json:"days" validate:"required_without=RRule,dive,gte=0,lte=6"`
+	RRule       *string   `json:"rrule,omitempty" validate:"omitempty,max=512"`
+	// ExtraDates are days the repetition meets on besides those it generates (RDATE)
+	ExtraDates []time.Time `json:"extra_dates,omitempty"`
+	// ExceptionDates are days the repetition skips (EXDATE). School holidays and breaks
+	// are added when the sessions are generated.
+	ExceptionDates []time.Time `json:"exception_dates,omitempty"`
+}
+
+// maxRuleOccurrences bounds how many sessions a repetition rule can create
+const maxRuleOccurrences = 500
+
+// Rule parses RRule, reading a floating UNTIL in loc. It is nil for a weekly repetition.
+func (r *Repetition) Rule(loc *time.Location) (*ical.Rule, error) {
+	if r.RRule == nil {
+		return nil, nil
+	}
+	return ical.ParseRule(strings.TrimPrefix(strings.TrimSpace(*r.RRule), "RRULE:"), loc)
+}
+
+// CheckRule reports why the repetition cannot be expanded: a rule that does not parse,
+// never ends or creates too many sessions, or extra dates outside the repetition
+func (r *Repetition) CheckRule() error {
+	rule, err := r.Rule(time.UTC)
+	if err != nil {
+		return fmt.Errorf("Invalid rrule: %w", err)
+	}
+	if rule != nil {
+		if r.RecurEnd.IsZero() && rule.Count == 0 && rule.Until.IsZero() {
+			return errors.New("An rrule without COUNT or UNTIL needs a recur_end")
+		}
+		if starts, _ := rule.Expand(r.RecurStart, r.ruleEnd(time.UTC), maxRuleOccurrences+1); len(starts) > maxRuleOccurrences {
+			return fmt.Errorf("The rrule creates more than %d sessions", maxRuleOccurrences)
+		}
+	}
+
+	for _, d := range r.ExtraDates {
+		if d.Before(r.RecurStart) || (!r.RecurEnd.IsZero() && d.After(r.RecurEnd)) {
+			return errors.New("Extra dates must fall between recur_start and recur_end")
+		}
+	}
+	return nil
+}
+
+// ruleEnd is the end of the last day of the repetition in loc, or, without a RecurEnd,
+// as far as the rule's COUNT or UNTIL goes
+func (r *Repetition) ruleEnd(loc *time.Location) time.Time {
+	if r.RecurEnd.IsZero() {
+		return time.Date(9999, time.December, 31, 0, 0, 0, 0, loc)
+	}
+	y, m, d := r.RecurEnd.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+}
+
+// Excepts reports whether the occurrence starting at t falls on one of the exception dates
+func (r *Repetition) Excepts(t time.Time) bool {
+	day := t.Format(time.DateOnly)
+	for _, d := range r.ExceptionDates {
+		if d.Format(time.DateOnly) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Occurrences lists the start and end of every occurrence of the repetition that begins
+// at or after from, and not on an exception date, taking the time of day from startTime
+// and endTime. The last day of the repetition is inclusive, as session_parent only stores
+// dates. The repetition is expanded in the location of startTime, so occurrences keep
+// their wall clock time across daylight saving changes; a rule that CheckRule rejects has
+// none.
+func (r *Repetition) Occurrences(startTime, endTime, from time.Time) [][2]time.Time {
+	var occurrences [][2]time.Time
+	add := func(day time.Time) {
+		occStart := onWeekday(day, int(day.Weekday()), startTime)
+		if occStart.Before(from) || r.Excepts(occStart) {
+			return
+		}
+		occurrences = append(occurrences, [2]time.Time{occStart, onWeekday(day, int(day.Weekday()), endTime)})
+	}
+
+	if r.RRule != nil {
+		rule, err := r.Rule(startTime.Location())
+		if err != nil {
+			return nil
+		}
+		y, m, d := r.RecurStart.Date()
+		dtstart := time.Date(y, m, d, startTime.Hour(), startTime.Minute(), startTime.Second(),
+			startTime.Nanosecond(), startTime.Location())
+		starts, _ := rule.Expand(dtstart, r.ruleEnd(startTime.Location()), maxRuleOccurrences)
+		for _, start := range starts {
+			add(start)
+		}
+	} else {
+		// The days of the repetition are taken in the location of startTime
+		y, m, d := r.RecurStart.Date()
+		first := time.Date(y, m, d, 0, 0, 0, 0, startTime.Location())
+		y, m, d = r.RecurEnd.Date()
+		until := time.Date(y, m, d+1, 0, 0, 0, 0, startTime.Location())
+
+		for wkStart := first; wkStart.Before(until); wkStart = wkStart.AddDate(0, 0, 7*r.EveryNWeeks) {
+			for _, day := range r.Days {
+				occStart := onWeekday(wkStart, day, startTime)
+				if occStart.Before(first) || !occStart.Before(until) {
+					continue
+				}
+				add(occStart)
+			}
+		}
+	}
+
+	for _, d := range r.ExtraDates {
+		add(d)
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i][0].Before(occurrences[j][0])
+	})
+	return slices.CompactFunc(occurrences, func(a, b [2]time.Time) bool {
+		return a[0].Equal(b[0])
+	})
+}
+
+// onWeekday moves base to the given weekday (Sunday=0) of its week, at the time of day
+// of reference
+func onWeekday(base time.Time, weekday int, reference time.Time) time.Time {
+	d := base.AddDate(0, 0, weekday-int(base.Weekday()))
+	return time.Date(
+		d.Year(), d.Month(), d.Day(),
+		reference.Hour(), reference.Minute(), reference.Second(),
+		reference.Nanosecond(), reference.Location(),
+	)
 }
 
 type PostSessionInput struct {
@@ -36,15 +253,46 @@ type PostSessionInput struct {
 	Location    *string      `json:"location" validate:"omitempty,min=1,max=255"`
 	Repetition  *Repetition  `json:"repetition" validate:"omitempty"`
 	StudentIDs  *[]uuid.UUID `json:"student_ids" validate:"omitempty,dive,uuid"`
+	// MakeupForSessionID makes the session a makeup for a missed one
+	MakeupForSessionID *uuid.UUID `json:"makeup_for_session_id"`
+	// TemplateID fills in what the input leaves out from a session template, whose
+	// resources are then linked to every session created
+	TemplateID *uuid.UUID `json:"template_id"`
+}
+
+// Occurrences lists the start and end of every session PostSession creates for the input
+func (in *PostSessionInput) Occurrences() []TimeSlot {
+	rp := in.Repetition
+	if rp == nil {
+		return []TimeSlot{{Start: in.StartTime, End: in.EndTime}}
+	}
+
+	var slots []TimeSlot
+	for _, occ := range rp.Occurrences(in.StartTime, in.EndTime, time.Time{}) {
+		slots = append(slots, TimeSlot{Start: occ[0], End: occ[1]})
+	}
+	return slots
 }
 
 type PatchSessionInput struct {
-	SessionName *string    `json:"session_name"`
-	StartTime   *time.Time `json:"start_datetime"`
-	EndTime     *time.Time `json:"end_datetime"`
-	TherapistID *uuid.UUID `json:"therapist_id"`
-	Notes       *string    `json:"notes"`
-	Location    *string    `json:"location"`
+	SessionName *string     `json:"session_name"`
+	StartTime   *time.Time  `json:"start_datetime"`
+	EndTime     *time.Time  `json:"end_datetime"`
+	TherapistID *uuid.UUID  `json:"therapist_id"`
+	Notes       *string     `json:"notes"`
+	Location    *string     `json:"location"`
+	Repetition  *Repetition `json:"repetition" validate:"omitempty"`
+}
+
+// Session edit scopes accepted by PATCH /sessions/:id.
+const (
+	SessionScopeThis      = "this"
+	SessionScopeFollowing = "following"
+	SessionScopeAll       = "all"
+)
+
+type PatchSessionQuery struct {
+	Scope string `query:"scope" validate:"omitempty,oneof=this following all"`
 }
 
 type GetSessionRequest struct {
@@ -54,6 +302,8 @@ type GetSessionRequest struct {
 	Year        *int       `query:"year" validate:"omitempty,gte=1776,lte=2200"`
 	StudentIDs  *[]string  `query:"student_ids" validate:"omitempty"`
 	TherapistID string     `query:"therapist_id" validate:"required,uuid"`
+	// Statuses keeps the sessions in any of the statuses given
+	Statuses []string `query:"status" validate:"omitempty,dive,oneof=scheduled completed cancelled_by_therapist cancelled_by_school student_absent makeup"`
 }
 
 // This is what repository uses
@@ -63,6 +313,7 @@ type GetSessionRepositoryRequest struct {
 	Month      *int         `validate:"omitempty,gte=1,lte=12"`
 	Year       *int         `validate:"omitempty,gte=1776,lte=2200"`
 	StudentIDs *[]uuid.UUID `validate:"omitempty"`
+	Statuses   []string     `validate:"omitempty"`
 }
 
 type GetStudentSessionsRequest struct {
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionImportQuery struct {
+	TherapistID   string     `query:"therapist_id" validate:"required,uuid"`
+	MatchStudents bool       `query:"match_students"`
+	From          *time.Time `query:"from" validate:"omitempty"`
+	// TimeZone is the IANA zone used for times the file does not give a zone for
+	TimeZone string `query:"tz" validate:"omitempty,timezone"`
+}
+
+// ImportedSession is what one event of an imported calendar becomes. Weekly events are
+// created as one recurring session, anything else as a session per occurrence.
+type ImportedSession struct {
+	UID                string               `json:"uid"`
+	SessionName        string               `json:"session_name"`
+	Location           *string              `json:"location"`
+	Notes              *string              `json:"notes"`
+	Repetition         *Repetition          `json:"repetition"`
+	Occurrences        []ImportedOccurrence `json:"occurrences"`
+	StudentIDs         []uuid.UUID          `json:"student_ids"`
+	UnmatchedAttendees []string             `json:"unmatched_attendees"`
+	// Truncated is set when the event goes on past what is imported
+	Truncated bool `json:"truncated"`
+}
+
+type ImportedOccurrence struct {
+	StartDateTime time.Time         `json:"start_datetime"`
+	EndDateTime   time.Time         `json:"end_datetime"`
+	Conflicts     []SessionConflict `json:"conflicts"`
+}
+
+// SkippedEvent is an event of the file that will not be imported, and why
+type SkippedEvent struct {
+	UID     string `json:"uid"`
+	Summary string `json:"summary"`
+	Reason  string `json:"reason"`
+}
+
+type SessionImportPreview struct {
+	Sessions []ImportedSession `json:"sessions"`
+	Skipped  []SkippedEvent    `json:"skipped"`
+}
+
+type SessionImportResult struct {
+	Sessions []Session      `json:"sessions"`
+	Skipped  []SkippedEvent `json:"skipped"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// States of a session note
+const (
+	NoteDraft  = "draft"
+	NoteSigned = "signed"
+)
+
+// SessionNote documents a session for one of its students in SOAP form: what the student
+// or family reported, what the therapist measured, the therapist's assessment, and the
+// plan. A note is a draft until it is signed, and is locked from then on.
+type SessionNote struct {
+	ID               uuid.UUID         `json:"id" db:"id"`
+	SessionStudentID int               `json:"session_student_id" db:"session_student_id"`
+	SessionID        uuid.UUID         `json:"session_id" db:"session_id"`
+	StudentID        uuid.UUID         `json:"student_id" db:"student_id"`
+	Subjective       *string           `json:"subjective" db:"subjective"`
+	Objective        *string           `json:"objective" db:"objective"`
+	Assessment       *string           `json:"assessment" db:"assessment"`
+	Plan             *string           `json:"plan" db:"plan"`
+	CustomFields     map[string]string `json:"custom_fields" db:"custom_fields"`
+	Status           string            `json:"status" db:"status"`
+	SignedAt         *time.Time        `json:"signed_at" db:"signed_at"`
+	SignedBy         *uuid.UUID        `json:"signed_by" db:"signed_by"`
+	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// Signed is whether the note is locked
+func (n *SessionNote) Signed() bool {
+	return n.Status == NoteSigned
+}
+
+// Empty is whether the note has nothing written in it yet
+func (n *SessionNote) Empty() bool {
+	for _, section := range []*string{n.Subjective, n.Objective, n.Assessment, n.Plan} {
+		if section != nil && *section != "" {
+			return false
+		}
+	}
+	for _, value := range n.CustomFields {
+		if value != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// SaveSessionNoteInput is the whole of a draft note, replacing what was saved before
+type SaveSessionNoteInput struct {
+	Subjective *string `json:"subjective" validate:"omitempty,max=10000"`
+	Objective  *string `json:"objective" validate:"omitempty,max=10000"`
+	Assessment *string `json:"assessment" validate:"omitempty,max=10000"`
+	Plan       *string `json:"plan" validate:"omitempty,max=10000"`
+	// CustomFields are further sections a district's documentation calls for, by name
+	CustomFields map[string]string `json:"custom_fields" validate:"omitempty,max=20,dive,keys,min=1,max=64,endkeys,max=10000"`
+}
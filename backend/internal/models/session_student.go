@@ -58,6 +58,20 @@ type PatchSessionStudentInput struct {
 	Ratings   *[]RateInput `json:"ratings" validate:"required,dive"`
 }
 
+// SessionAttendanceInput records attendance for the students of a session at once. Notes
+// and ratings given replace the student's, and are kept when left out. Students not
+// listed are left as they are.
+type SessionAttendanceInput struct {
+	Students []StudentAttendanceInput `json:"students" validate:"required,min=1,max=100,unique=StudentID,dive"`
+}
+
+type StudentAttendanceInput struct {
+	StudentID uuid.UUID    `json:"student_id" validate:"required"`
+	Present   *bool        `json:"present" validate:"required"`
+	Notes     *string      `json:"notes,omitempty"`
+	Ratings   *[]RateInput `json:"ratings,omitempty" validate:"omitempty,unique=Category,dive"`
+}
+
 type DeleteSessionStudentInput struct {
 	SessionID uuid.UUID `json:"session_id" validate:"required,uuid"`
 	StudentID uuid.UUID `json:"student_id" validate:"required,uuid"`
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GameContentFilter picks the game content a session plans to play, as GET /game-contents
+// is queried
+type GameContentFilter struct {
+	ThemeID             *uuid.UUID `json:"theme_id,omitempty"`
+	ThemeWeek           *int       `json:"theme_week,omitempty" validate:"omitempty,gte=1"`
+	Category            *string    `json:"category,omitempty" validate:"omitempty,oneof=receptive_language expressive_language social_pragmatic_language speech"`
+	QuestionType        *string    `json:"question_type,omitempty" validate:"omitempty,oneof=sequencing following_directions wh_questions true_false concepts_sorting fill_in_the_blank categorical_language emotions teamwork_talk express_excitement_interest fluency articulation_s articulation_l"`
+	DifficultyLevel     *int       `json:"difficulty_level,omitempty" validate:"omitempty,gte=1"`
+	ExerciseType        *string    `json:"exercise_type,omitempty" validate:"omitempty,oneof=game pdf"`
+	ApplicableGameTypes []string   `json:"applicable_game_types,omitempty" validate:"omitempty,dive,oneof='drag and drop' spinner 'word/image matching' flashcards"`
+}
+
+// SessionTemplate is a session plan a therapist runs again and again. Sessions created
+// from it take its name, length and location unless given their own, and get its
+// resources in the order listed.
+type SessionTemplate struct {
+	ID                uuid.UUID         `json:"id" db:"id"`
+	TherapistID       uuid.UUID         `json:"therapist_id" db:"therapist_id"`
+	Name              string            `json:"name" db:"name"`
+	DurationMinutes   int               `json:"duration_minutes" db:"duration_minutes"`
+	Location          *string           `json:"location" db:"location"`
+	ResourceIDs       []uuid.UUID       `json:"resource_ids" db:"resource_ids"`
+	GameContentFilter GameContentFilter `json:"game_content_filter" db:"game_content_filter"`
+	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// Fill completes the session input with what the template plans
+func (t *SessionTemplate) Fill(in *PostSessionInput) {
+	if in.SessionName == "" {
+		in.SessionName = t.Name
+	}
+	if in.EndTime.IsZero() && !in.StartTime.IsZero() {
+		in.EndTime = in.StartTime.Add(time.Duration(t.DurationMinutes) * time.Minute)
+	}
+	if in.Location == nil {
+		in.Location = t.Location
+	}
+}
+
+type CreateSessionTemplateInput struct {
+	TherapistID       uuid.UUID          `json:"therapist_id" validate:"required"`
+	Name              string             `json:"name" validate:"required,min=1,max=255"`
+	DurationMinutes   int                `json:"duration_minutes" validate:"required,min=1,max=480"`
+	Location          *string            `json:"location" validate:"omitempty,min=1,max=255"`
+	ResourceIDs       []uuid.UUID        `json:"resource_ids" validate:"omitempty,max=50,unique,dive,uuid"`
+	GameContentFilter *GameContentFilter `json:"game_content_filter" validate:"omitempty"`
+}
+
+// Template is the template the input creates
+func (in *CreateSessionTemplateInput) Template() SessionTemplate {
+	t := SessionTemplate{
+		TherapistID:     in.TherapistID,
+		Name:            in.Name,
+		DurationMinutes: in.DurationMinutes,
+		Location:        in.Location,
+		ResourceIDs:     in.ResourceIDs,
+	}
+	if in.GameContentFilter != nil {
+		t.GameContentFilter = *in.GameContentFilter
+	}
+	return t
+}
+
+// UpdateSessionTemplateInput changes the fields given. Resources given replace the
+// template's, as does a game content filter.
+type UpdateSessionTemplateInput struct {
+	Name              *string            `json:"name" validate:"omitempty,min=1,max=255"`
+	DurationMinutes   *int               `json:"duration_minutes" validate:"omitempty,min=1,max=480"`
+	Location          *string            `json:"location" validate:"omitempty,min=1,max=255"`
+	ResourceIDs       *[]uuid.UUID       `json:"resource_ids" validate:"omitempty,max=50,unique,dive,uuid"`
+	GameContentFilter *GameContentFilter `json:"game_content_filter" validate:"omitempty"`
+}
+
+// Apply changes the template as the input describes
+func (in *UpdateSessionTemplateInput) Apply(t *SessionTemplate) {
+	if in.Name != nil {
+		t.Name = *in.Name
+	}
+	if in.DurationMinutes != nil {
+		t.DurationMinutes = *in.DurationMinutes
+	}
+	if in.Location != nil {
+		t.Location = in.Location
+	}
+	if in.ResourceIDs != nil {
+		t.ResourceIDs = *in.ResourceIDs
+	}
+	if in.GameContentFilter != nil {
+		t.GameContentFilter = *in.GameContentFilter
+	}
+}
+
+type GetSessionTemplatesQuery struct {
+	TherapistID uuid.UUID `query:"therapist_id" validate:"required"`
+}
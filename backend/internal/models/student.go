@@ -52,6 +52,8 @@ type UpdateStudentInput struct {
 type PromoteStudentsInput struct {
 	TherapistID        uuid.UUID   `json:"therapist_id" validate:"required"`
 	ExcludedStudentIDs []uuid.UUID `json:"excluded_student_ids" validate:"dive"`
+	// RunAt defers the promotion, such as to the end of the school year
+	RunAt *time.Time `json:"run_at,omitempty"`
 }
 
 type GetStudentAttendanceParams struct {
@@ -6,6 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	RoleTherapist     = "therapist"
+	RoleDistrictAdmin = "district_admin"
+	RoleSystemAdmin   = "system_admin"
+)
+
 type Therapist struct {
 	ID           uuid.UUID `json:"id"`
 	FirstName    string    `json:"first_name"`
@@ -14,6 +20,8 @@ type Therapist struct {
 	Active       bool      `json:"active"`
 	Schools      []int     `json:"schools" db:"schools"`
 	DistrictID   *int      `json:"district_id"`
+	Role         string    `json:"role" db:"role"`
+	TimeZone     string    `json:"time_zone" db:"time_zone"`
 	SchoolNames  *[]string `json:"school_names,omitempty" db:"-"`
 	DistrictName *string   `json:"district_name,omitempty" db:"-"`
 	CreatedAt    time.Time `json:"created_at"`
@@ -27,6 +35,8 @@ type CreateTherapistInput struct {
 	Schools    []int     `json:"schools" validate:"required,dive,min=1"`
 	DistrictID *int      `json:"district_id" validate:"omitempty,min=1"`
 	Email      string    `json:"email" validate:"required,min=1,max=255"`
+	// TimeZone is an IANA zone such as America/New_York, UTC when not given
+	TimeZone *string `json:"time_zone" validate:"omitempty,timezone"`
 }
 
 type UpdateTherapist struct {
@@ -36,4 +46,32 @@ type UpdateTherapist struct {
 	DistrictID *int    `json:"district_id" validate:"omitempty,min=1"`
 	Email      *string `json:"email" validate:"omitempty,min=1,max=255"`
 	Active     *bool   `json:"active" validate:"omitempty"`
+	TimeZone   *string `json:"time_zone" validate:"omitempty,timezone"`
+}
+
+type TherapistDelegate struct {
+	TherapistID uuid.UUID `json:"therapist_id" db:"therapist_id"`
+	DelegateID  uuid.UUID `json:"delegate_id" db:"delegate_id"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateTherapistDelegateInput struct {
+	DelegateID uuid.UUID `json:"delegate_id" validate:"required"`
+}
+
+type UpdateTherapistRoleInput struct {
+	Role string `json:"role" validate:"required,oneof=therapist district_admin system_admin"`
+}
+
+// Principal is who is making a request: the therapist, their role and, for district
+// administrators, the district they administer. Requests made with an API key act as the
+// therapist who created it, narrowed to the key's scopes and district.
+type Principal struct {
+	TherapistID uuid.UUID `json:"therapist_id" db:"id"`
+	Role        string    `json:"role" db:"role"`
+	DistrictID  *int      `json:"district_id" db:"district_id"`
+
+	APIKeyID      *uuid.UUID `json:"api_key_id,omitempty" db:"-"`
+	Scopes        []string   `json:"scopes,omitempty" db:"-"`
+	KeyDistrictID *int       `json:"key_district_id,omitempty" db:"-"`
 }
@@ -6,12 +6,16 @@ import (
 
 // VerificationCode represents a verification code in the database
 type VerificationCode struct {
-	ID        string        `db:"id"`
-	UserID    string     `db:"user_id"`
-	Code      string     `db:"code"`
-	ExpiresAt time.Time  `db:"expires_at"`
-	CreatedAt time.Time  `db:"created_at"`
-	Used      bool 		 `db:"used"`
+	ID     string `db:"id"`
+	UserID string `db:"user_id"`
+	// Code is the plain code sent to the user, only CodeHash is stored
+	Code        string    `db:"-"`
+	CodeHash    string    `db:"code_hash"`
+	ExpiresAt   time.Time `db:"expires_at"`
+	CreatedAt   time.Time `db:"created_at"`
+	Used        bool      `db:"used"`
+	Attempts    int       `db:"attempts"`
+	MaxAttempts int       `db:"max_attempts"`
 }
 
 type SendCodeResponse struct {
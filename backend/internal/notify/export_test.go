@@ -0,0 +1,8 @@
+package notify
+
+import "time"
+
+// SetNow fixes the time the notifier sends what is due by
+func SetNow(n *Notifier, now time.Time) {
+	n.now = func() time.Time { return now }
+}
@@ -0,0 +1,257 @@
+// Package notify tells therapists about their sessions, as they chose in their
+// notification preferences: a digest of the day's sessions every morning and a reminder
+// shortly before each one, by email. It runs as a background job every minute, and
+// remembers what it sent so every server instance sends each notification once.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"specialstandard/internal/jobs"
+	"specialstandard/internal/mailer"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage"
+	"specialstandard/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	JobSessionNotifications   = "session_notifications"
+	jobPurgeSentNotifications = "purge_sent_notifications"
+
+	// A digest not sent within this long of its time, such as while the servers were down,
+	// is skipped rather than sent late in the day
+	digestWindow = 2 * time.Hour
+	// Sent notifications are remembered well past the longest reminder lead time
+	sentRetention = 7 * 24 * time.Hour
+	// Sessions looked at per digest or round of reminders, and students listed per session
+	maxSessions = 100
+
+	clockFormat = "3:04 PM"
+	dateFormat  = "Monday, January 2"
+)
+
+// Sessions still to be run, cancelled ones are left out
+var upcomingStatuses = []string{models.SessionScheduled, models.SessionMakeup}
+
+type Notifier struct {
+	sessions      storage.SessionRepository
+	notifications storage.NotificationRepository
+	mailer        *mailer.Mailer
+	now           func() time.Time
+}
+
+func New(sessions storage.SessionRepository, notifications storage.NotificationRepository, mail *mailer.Mailer) *Notifier {
+	return &Notifier{
+		sessions:      sessions,
+		notifications: notifications,
+		mailer:        mail,
+		now:           time.Now,
+	}
+}
+
+// Register registers the notification jobs with the scheduler and schedules them
+func Register(s *jobs.Scheduler, n *Notifier) error {
+	// Every run sends what is due by then, so a failed one is left to the next
+	s.Register(jobs.Job{
+		Name: JobSessionNotifications,
+		Handler: func(ctx context.Context, _ json.RawMessage) error {
+			return n.Notify(ctx)
+		},
+		Timeout:     5 * time.Minute,
+		MaxAttempts: 1,
+	})
+
+	s.Register(jobs.Job{
+		Name: jobPurgeSentNotifications,
+		Handler: func(ctx context.Context, _ json.RawMessage) error {
+			_, err := n.notifications.DeleteSentNotifications(ctx, time.Now().Add(-sentRetention))
+			return err
+		},
+	})
+
+	if err := s.Schedule(JobSessionNotifications, "* * * * *"); err != nil {
+		return err
+	}
+	return s.Schedule(jobPurgeSentNotifications, "45 3 * * *")
+}
+
+// Notify sends the digests and reminders that are due. A therapist whose notifications
+// fail does not hold up the others, the errors are returned together.
+func (n *Notifier) Notify(ctx context.Context) error {
+	recipients, err := n.notifications.GetNotificationRecipients(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := n.now()
+	var errList []error
+	for i := range recipients {
+		recipient := &recipients[i]
+
+		loc, err := time.LoadLocation(recipient.TimeZone)
+		if err != nil {
+			slog.Warn("Unknown therapist time zone, notifying in UTC", "therapist_id", recipient.TherapistID, "time_zone", recipient.TimeZone)
+			loc = time.UTC
+		}
+		local := now.In(loc)
+
+		if recipient.DigestEnabled {
+			if err := n.sendDigest(ctx, recipient, local); err != nil {
+				errList = append(errList, fmt.Errorf("digest for therapist %s: %w", recipient.TherapistID, err))
+			}
+		}
+		if recipient.ReminderEnabled {
+			if err := n.sendReminders(ctx, recipient, local); err != nil {
+				errList = append(errList, fmt.Errorf("reminders for therapist %s: %w", recipient.TherapistID, err))
+			}
+		}
+	}
+
+	return errors.Join(errList...)
+}
+
+// sendDigest emails the therapist the day's sessions once their digest time has come, now
+// being in their time zone. No digest is sent for a day without sessions.
+func (n *Notifier) sendDigest(ctx context.Context, recipient *models.NotificationRecipient, now time.Time) error {
+	clock, err := time.Parse("15:04", recipient.DigestTime)
+	if err != nil {
+		return fmt.Errorf("invalid digest time %q: %w", recipient.DigestTime, err)
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	digestAt := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if now.Before(digestAt) || !now.Before(digestAt.Add(digestWindow)) {
+		return nil
+	}
+
+	key := fmt.Sprintf("digest:%s:%s", recipient.TherapistID, day.Format(time.DateOnly))
+	claimed, err := n.notifications.ClaimNotification(ctx, recipient.TherapistID, models.NotificationDigest, key)
+	if err != nil || !claimed {
+		return err
+	}
+
+	end := day.AddDate(0, 0, 1)
+	sessions, err := n.sessions.GetSessions(ctx, utils.Pagination{Page: 1, Limit: maxSessions}, &models.GetSessionRepositoryRequest{
+		StartTime: &day,
+		EndTime:   &end,
+		Statuses:  upcomingStatuses,
+	}, recipient.TherapistID)
+	if err != nil {
+		return n.release(ctx, key, err)
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	summaries := make([]mailer.SessionSummary, 0, len(sessions))
+	for i := range sessions {
+		summary, err := n.summarize(ctx, recipient.TherapistID, &sessions[i], now.Location())
+		if err != nil {
+			return n.release(ctx, key, err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	_, err = n.mailer.Send(ctx, []string{recipient.Email}, mailer.TemplateSessionDigest, mailer.SessionDigestData{
+		FirstName: recipient.FirstName,
+		Date:      now.Format(dateFormat),
+		Sessions:  summaries,
+	})
+	if err != nil {
+		return n.release(ctx, key, err)
+	}
+	return nil
+}
+
+// sendReminders emails the therapist about each session starting within their lead time,
+// now being in their time zone. A session moved to another time is reminded of again.
+func (n *Notifier) sendReminders(ctx context.Context, recipient *models.NotificationRecipient, now time.Time) error {
+	sessions, err := n.sessions.GetSessions(ctx, utils.Pagination{Page: 1, Limit: maxSessions}, &models.GetSessionRepositoryRequest{
+		StartTime: &now,
+		Statuses:  upcomingStatuses,
+	}, recipient.TherapistID)
+	if err != nil {
+		return err
+	}
+
+	until := now.Add(time.Duration(recipient.ReminderLeadMinutes) * time.Minute)
+	var errList []error
+	for i := range sessions {
+		session := &sessions[i]
+		// Sessions come earliest first
+		if session.StartDateTime.After(until) {
+			break
+		}
+
+		if err := n.sendReminder(ctx, recipient, session, now); err != nil {
+			errList = append(errList, fmt.Errorf("session %s: %w", session.ID, err))
+		}
+	}
+
+	return errors.Join(errList...)
+}
+
+func (n *Notifier) sendReminder(ctx context.Context, recipient *models.NotificationRecipient, session *models.Session, now time.Time) error {
+	key := fmt.Sprintf("reminder:%s:%s:%s", recipient.TherapistID, session.ID, session.StartDateTime.UTC().Format(time.RFC3339))
+	claimed, err := n.notifications.ClaimNotification(ctx, recipient.TherapistID, models.NotificationReminder, key)
+	if err != nil || !claimed {
+		return err
+	}
+
+	summary, err := n.summarize(ctx, recipient.TherapistID, session, now.Location())
+	if err != nil {
+		return n.release(ctx, key, err)
+	}
+
+	_, err = n.mailer.Send(ctx, []string{recipient.Email}, mailer.TemplateSessionReminder, mailer.SessionReminderData{
+		FirstName:       recipient.FirstName,
+		StartsInMinutes: int(math.Ceil(session.StartDateTime.Sub(now).Minutes())),
+		Session:         summary,
+	})
+	if err != nil {
+		return n.release(ctx, key, err)
+	}
+	return nil
+}
+
+// summarize describes the session with its students, in the therapist's time zone
+func (n *Notifier) summarize(ctx context.Context, therapistID uuid.UUID, session *models.Session, loc *time.Location) (mailer.SessionSummary, error) {
+	students, err := n.sessions.GetSessionStudents(ctx, session.ID, utils.Pagination{Page: 1, Limit: maxSessions}, therapistID)
+	if err != nil {
+		return mailer.SessionSummary{}, err
+	}
+
+	names := make([]string, 0, len(students))
+	for _, s := range students {
+		names = append(names, s.Student.FirstName+" "+s.Student.LastName)
+	}
+
+	start := session.StartDateTime.In(loc).Format(clockFormat)
+	summary := mailer.SessionSummary{
+		Name:     session.SessionName,
+		Start:    start,
+		Time:     start + " - " + session.EndDateTime.In(loc).Format(clockFormat),
+		Students: strings.Join(names, ", "),
+	}
+	if session.Location != nil {
+		summary.Location = *session.Location
+	}
+	return summary, nil
+}
+
+// release forgets the claim on a notification that could not be sent, so the next run
+// tries it again, and returns why it could not be sent
+func (n *Notifier) release(ctx context.Context, key string, cause error) error {
+	if err := n.notifications.ReleaseNotification(ctx, key); err != nil {
+		return errors.Join(cause, fmt.Errorf("release %s: %w", key, err))
+	}
+	return cause
+}
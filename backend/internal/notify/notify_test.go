@@ -0,0 +1,232 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"specialstandard/internal/mailer"
+	"specialstandard/internal/models"
+	"specialstandard/internal/notify"
+	"specialstandard/internal/storage/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var newYork, _ = time.LoadLocation("America/New_York")
+
+type fixture struct {
+	sessions      *mocks.MockSessionRepository
+	notifications *mocks.MockNotificationRepository
+	outbox        *mocks.MockEmailOutboxRepository
+	// sent holds the emails queued, in order
+	sent []*models.CreateOutboxEmailInput
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		sessions:      new(mocks.MockSessionRepository),
+		notifications: new(mocks.MockNotificationRepository),
+		outbox:        new(mocks.MockEmailOutboxRepository),
+	}
+	f.outbox.On("EnqueueEmail", mock.Anything, mock.Anything).Return(&models.OutboxEmail{ID: uuid.New(), MaxAttempts: 5}, nil).
+		Run(func(args mock.Arguments) {
+			f.sent = append(f.sent, args.Get(1).(*models.CreateOutboxEmailInput))
+		}).Maybe()
+	f.outbox.On("MarkEmailSent", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return f
+}
+
+// notify runs the notifier at the time, in New York
+func (f *fixture) notify(t *testing.T, at time.Time) error {
+	t.Helper()
+	n := notify.New(f.sessions, f.notifications, mailer.New(mailer.NewLocalBackend(""), f.outbox, "noreply@example.com"))
+	notify.SetNow(n, at)
+	return n.Notify(context.Background())
+}
+
+func recipient(digest, reminders bool) models.NotificationRecipient {
+	return models.NotificationRecipient{
+		NotificationPreference: models.NotificationPreference{
+			TherapistID:         uuid.New(),
+			Channel:             models.NotificationEmail,
+			DigestEnabled:       digest,
+			DigestTime:          "07:00",
+			ReminderEnabled:     reminders,
+			ReminderLeadMinutes: 15,
+			TimeZone:            "America/New_York",
+		},
+		Email:     "ada@example.com",
+		FirstName: "Ada",
+	}
+}
+
+func session(therapistID uuid.UUID, name string, start time.Time) models.Session {
+	location := "Room 12"
+	return models.Session{
+		ID:            uuid.New(),
+		SessionName:   name,
+		StartDateTime: start,
+		EndDateTime:   start.Add(30 * time.Minute),
+		TherapistID:   therapistID,
+		Location:      &location,
+		Status:        models.SessionScheduled,
+	}
+}
+
+func students(names ...string) []models.SessionStudentsOutput {
+	out := make([]models.SessionStudentsOutput, 0, len(names))
+	for _, name := range names {
+		out = append(out, models.SessionStudentsOutput{Student: models.Student{FirstName: name, LastName: "Smith"}})
+	}
+	return out
+}
+
+func TestNotifier_Digest(t *testing.T) {
+	day := time.Date(2025, 9, 2, 0, 0, 0, 0, newYork)
+
+	t.Run("Sent once the digest time comes", func(t *testing.T) {
+		f := newFixture()
+		r := recipient(true, false)
+		f.notifications.On("GetNotificationRecipients", mock.Anything).Return([]models.NotificationRecipient{r}, nil)
+		f.notifications.On("ClaimNotification", mock.Anything, r.TherapistID, models.NotificationDigest, "digest:"+r.TherapistID.String()+":2025-09-02").Return(true, nil)
+
+		first := session(r.TherapistID, "Articulation", day.Add(9*time.Hour))
+		second := session(r.TherapistID, "Fluency", day.Add(13*time.Hour))
+		f.sessions.On("GetSessions", mock.Anything, mock.Anything, mock.MatchedBy(func(filter *models.GetSessionRepositoryRequest) bool {
+			return filter.StartTime.Equal(day) && filter.EndTime.Equal(day.AddDate(0, 0, 1)) &&
+				assert.ObjectsAreEqual([]string{models.SessionScheduled, models.SessionMakeup}, filter.Statuses)
+		}), r.TherapistID).Return([]models.Session{first, second}, nil)
+		f.sessions.On("GetSessionStudents", mock.Anything, first.ID, mock.Anything, r.TherapistID).Return(students("Emma", "Liam"), nil)
+		f.sessions.On("GetSessionStudents", mock.Anything, second.ID, mock.Anything, r.TherapistID).Return(students(), nil)
+
+		require.NoError(t, f.notify(t, day.Add(7*time.Hour+2*time.Minute).UTC()))
+
+		require.Len(t, f.sent, 1)
+		assert.Equal(t, []string{"ada@example.com"}, f.sent[0].Recipients)
+		assert.Equal(t, mailer.TemplateSessionDigest, f.sent[0].Template)
+		assert.Equal(t, "Your sessions for Tuesday, September 2", f.sent[0].Subject)
+		assert.Contains(t, f.sent[0].TextBody, "9:00 AM - 9:30 AM  Articulation (Room 12)\n  Students: Emma Smith, Liam Smith\n")
+		assert.Contains(t, f.sent[0].TextBody, "1:00 PM - 1:30 PM  Fluency (Room 12)\n")
+		f.notifications.AssertExpectations(t)
+	})
+
+	t.Run("Not before the digest time, nor long after", func(t *testing.T) {
+		for _, at := range []time.Time{day.Add(6*time.Hour + 59*time.Minute), day.Add(9 * time.Hour)} {
+			f := newFixture()
+			f.notifications.On("GetNotificationRecipients", mock.Anything).Return([]models.NotificationRecipient{recipient(true, false)}, nil)
+
+			require.NoError(t, f.notify(t, at))
+			assert.Empty(t, f.sent)
+			f.notifications.AssertNotCalled(t, "ClaimNotification", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("Already sent", func(t *testing.T) {
+		f := newFixture()
+		f.notifications.On("GetNotificationRecipients", mock.Anything).Return([]models.NotificationRecipient{recipient(true, false)}, nil)
+		f.notifications.On("ClaimNotification", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+		require.NoError(t, f.notify(t, day.Add(7*time.Hour)))
+		assert.Empty(t, f.sent)
+		f.sessions.AssertNotCalled(t, "GetSessions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Nothing on the day", func(t *testing.T) {
+		f := newFixture()
+		f.notifications.On("GetNotificationRecipients", mock.Anything).Return([]models.NotificationRecipient{recipient(true, false)}, nil)
+		f.notifications.On("ClaimNotification", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		f.sessions.On("GetSessions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.Session{}, nil)
+
+		require.NoError(t, f.notify(t, day.Add(7*time.Hour)))
+		assert.Empty(t, f.sent)
+		f.notifications.AssertNotCalled(t, "ReleaseNotification", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Tried again when the sessions cannot be read", func(t *testing.T) {
+		f := newFixture()
+		f.notifications.On("GetNotificationRecipients", mock.Anything).Return([]models.NotificationRecipient{recipient(true, false)}, nil)
+		f.notifications.On("ClaimNotification", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		f.notifications.On("ReleaseNotification", mock.Anything, mock.Anything).Return(nil)
+		f.sessions.On("GetSessions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+		err := f.notify(t, day.Add(7*time.Hour))
+		assert.ErrorContains(t, err, "connection refused")
+		assert.Empty(t, f.sent)
+		f.notifications.AssertExpectations(t)
+	})
+}
+
+func TestNotifier_Reminders(t *testing.T) {
+	day := time.Date(2025, 9, 2, 0, 0, 0, 0, newYork)
+	now := day.Add(8*time.Hour + 50*time.Minute)
+
+	t.Run("Sessions starting within the lead time", func(t *testing.T) {
+		f := newFixture()
+		r := recipient(false, true)
+		f.notifications.On("GetNotificationRecipients", mock.Anything).Return([]models.NotificationRecipient{r}, nil)
+
+		soon := session(r.TherapistID, "Articulation", day.Add(9*time.Hour))
+		later := session(r.TherapistID, "Fluency", day.Add(9*time.Hour+30*time.Minute))
+		f.sessions.On("GetSessions", mock.Anything, mock.Anything, mock.MatchedBy(func(filter *models.GetSessionRepositoryRequest) bool {
+			return filter.StartTime.Equal(now) && filter.EndTime == nil
+		}), r.TherapistID).Return([]models.Session{soon, later}, nil)
+		f.sessions.On("GetSessionStudents", mock.Anything, soon.ID, mock.Anything, r.TherapistID).Return(students("Emma"), nil)
+		f.notifications.On("ClaimNotification", mock.Anything, r.TherapistID, models.NotificationReminder,
+			"reminder:"+r.TherapistID.String()+":"+soon.ID.String()+":2025-09-02T13:00:00Z").Return(true, nil)
+
+		require.NoError(t, f.notify(t, now))
+
+		require.Len(t, f.sent, 1)
+		assert.Equal(t, mailer.TemplateSessionReminder, f.sent[0].Template)
+		assert.Equal(t, "Reminder: Articulation at 9:00 AM", f.sent[0].Subject)
+		assert.Contains(t, f.sent[0].TextBody, "starts in 10 minutes")
+		assert.Contains(t, f.sent[0].TextBody, "Students: Emma Smith")
+		f.notifications.AssertExpectations(t)
+		f.sessions.AssertNotCalled(t, "GetSessionStudents", mock.Anything, later.ID, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already reminded", func(t *testing.T) {
+		f := newFixture()
+		r := recipient(false, true)
+		f.notifications.On("GetNotificationRecipients", mock.Anything).Return([]models.NotificationRecipient{r}, nil)
+		f.sessions.On("GetSessions", mock.Anything, mock.Anything, mock.Anything, r.TherapistID).
+			Return([]models.Session{session(r.TherapistID, "Articulation", day.Add(9*time.Hour))}, nil)
+		f.notifications.On("ClaimNotification", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+		require.NoError(t, f.notify(t, now))
+		assert.Empty(t, f.sent)
+	})
+
+	t.Run("Tried again when the email cannot be queued", func(t *testing.T) {
+		f := newFixture()
+		f.outbox = new(mocks.MockEmailOutboxRepository)
+		f.outbox.On("EnqueueEmail", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+		r := recipient(false, true)
+		other := recipient(false, true)
+		f.notifications.On("GetNotificationRecipients", mock.Anything).Return([]models.NotificationRecipient{r, other}, nil)
+		f.sessions.On("GetSessions", mock.Anything, mock.Anything, mock.Anything, r.TherapistID).
+			Return([]models.Session{session(r.TherapistID, "Articulation", day.Add(9*time.Hour))}, nil)
+		f.sessions.On("GetSessions", mock.Anything, mock.Anything, mock.Anything, other.TherapistID).Return([]models.Session{}, nil)
+		f.sessions.On("GetSessionStudents", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(students(), nil)
+		f.notifications.On("ClaimNotification", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		f.notifications.On("ReleaseNotification", mock.Anything, mock.Anything).Return(nil)
+
+		err := f.notify(t, now)
+		assert.ErrorContains(t, err, "connection refused")
+		f.notifications.AssertExpectations(t)
+		// The other therapist was still looked at
+		f.sessions.AssertCalled(t, "GetSessions", mock.Anything, mock.Anything, mock.Anything, other.TherapistID)
+	})
+}
+
+func TestNotifier_Recipients(t *testing.T) {
+	f := newFixture()
+	f.notifications.On("GetNotificationRecipients", mock.Anything).Return(nil, errors.New("connection refused"))
+
+	assert.Error(t, f.notify(t, time.Now()))
+}
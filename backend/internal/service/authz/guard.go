@@ -0,0 +1,637 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Locals keys caching the caller's principal and the therapists they may act for
+const (
+	principalKey  = "principal"
+	accessibleKey = "accessibleTherapistIDs"
+)
+
+// Guard scopes requests to the caseloads the authenticated therapist is allowed to see:
+// their own plus those of every therapist who made them a delegate. District
+// administrators may also read (but not change) every caseload in their district, and
+// system administrators may do anything.
+//
+// Referencing a therapist the caller cannot act for is a 403. Referencing a session,
+// student or session_student that belongs to someone else is a 404, so that callers
+// cannot probe for the existence of records outside their caseload.
+type Guard struct {
+	access  storage.AccessRepository
+	enabled bool
+}
+
+func NewGuard(access storage.AccessRepository, enabled bool) *Guard {
+	return &Guard{
+		access:  access,
+		enabled: enabled,
+	}
+}
+
+// CallerID returns the therapist ID of the authenticated user
+func CallerID(c *fiber.Ctx) (uuid.UUID, error) {
+	userID, ok := c.Locals("userID").(string)
+	if !ok || userID == "" {
+		return uuid.Nil, errs.Unauthorized("Missing authenticated user")
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, errs.Unauthorized("Invalid authenticated user")
+	}
+
+	return id, nil
+}
+
+// scope is the set of therapists whose caseloads the caller may act on in this request
+type scope struct {
+	all bool
+	ids map[uuid.UUID]struct{}
+}
+
+func (s *scope) allows(therapistID uuid.UUID) bool {
+	if s.all {
+		return true
+	}
+	_, ok := s.ids[therapistID]
+	return ok
+}
+
+// principal loads (once per request) the caller's role
+func (g *Guard) principal(c *fiber.Ctx) (*models.Principal, error) {
+	if cached, ok := c.Locals(principalKey).(*models.Principal); ok {
+		return cached, nil
+	}
+
+	callerID, err := CallerID(c)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, err := g.access.GetPrincipal(c.Context(), callerID)
+	if err != nil {
+		slog.Error("Failed to load principal", "user_id", callerID, "err", err)
+		return nil, errs.InternalServerError("Failed to authorize request")
+	}
+
+	if key, ok := c.Locals("apiKey").(*models.APIKey); ok {
+		principal.APIKeyID = &key.ID
+		principal.Scopes = key.Scopes
+		principal.KeyDistrictID = key.DistrictID
+	}
+
+	c.Locals(principalKey, principal)
+	return principal, nil
+}
+
+// accessible loads (once per request) the set of therapist IDs the caller may act for
+func (g *Guard) accessible(c *fiber.Ctx) (*scope, error) {
+	if cached, ok := c.Locals(accessibleKey).(*scope); ok {
+		return cached, nil
+	}
+
+	principal, err := g.principal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal.Role == models.RoleSystemAdmin && principal.KeyDistrictID == nil {
+		s := &scope{all: true}
+		c.Locals(accessibleKey, s)
+		return s, nil
+	}
+
+	ids, err := g.access.GetAccessibleTherapistIDs(c.Context(), principal.TherapistID)
+	if err != nil {
+		slog.Error("Failed to load accessible therapists", "user_id", principal.TherapistID, "err", err)
+		return nil, errs.InternalServerError("Failed to authorize request")
+	}
+
+	// District administrators get read-only access to the whole district
+	if principal.Role == models.RoleDistrictAdmin && principal.DistrictID != nil && isRead(c) {
+		districtIDs, err := g.access.GetDistrictTherapistIDs(c.Context(), *principal.DistrictID)
+		if err != nil {
+			slog.Error("Failed to load district therapists", "district_id", *principal.DistrictID, "err", err)
+			return nil, errs.InternalServerError("Failed to authorize request")
+		}
+		ids = append(ids, districtIDs...)
+	}
+
+	s := &scope{ids: make(map[uuid.UUID]struct{}, len(ids)+1)}
+	s.ids[principal.TherapistID] = struct{}{}
+	for _, id := range ids {
+		s.ids[id] = struct{}{}
+	}
+
+	// A district restricted API key only reaches the therapists of that district, whatever
+	// the therapist who created it can reach
+	if principal.KeyDistrictID != nil {
+		districtIDs, err := g.access.GetDistrictTherapistIDs(c.Context(), *principal.KeyDistrictID)
+		if err != nil {
+			slog.Error("Failed to load district therapists", "district_id", *principal.KeyDistrictID, "err", err)
+			return nil, errs.InternalServerError("Failed to authorize request")
+		}
+
+		restricted := &scope{ids: make(map[uuid.UUID]struct{}, len(districtIDs))}
+		for _, id := range districtIDs {
+			if principal.Role == models.RoleSystemAdmin || s.allows(id) {
+				restricted.ids[id] = struct{}{}
+			}
+		}
+		s = restricted
+	}
+
+	c.Locals(accessibleKey, s)
+	return s, nil
+}
+
+func isRead(c *fiber.Ctx) bool {
+	return c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead
+}
+
+// RequireRole only lets callers with one of the given roles through
+func (g *Guard) RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		principal, err := g.principal(c)
+		if err != nil {
+			return err
+		}
+
+		for _, role := range roles {
+			if principal.Role == role {
+				return c.Next()
+			}
+		}
+
+		return errs.Forbidden("You do not have the required role")
+	}
+}
+
+// DistrictParam checks that the caller administers the district in the path parameter.
+// System administrators administer every district.
+func (g *Guard) DistrictParam(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		principal, err := g.principal(c)
+		if err != nil {
+			return err
+		}
+
+		districtID, err := c.ParamsInt(param)
+		if err != nil {
+			return c.Next()
+		}
+
+		if principal.KeyDistrictID != nil && *principal.KeyDistrictID != districtID {
+			return errs.Forbidden("You do not have access to this district")
+		}
+
+		if principal.Role == models.RoleSystemAdmin {
+			return c.Next()
+		}
+
+		if principal.Role != models.RoleDistrictAdmin || principal.DistrictID == nil || *principal.DistrictID != districtID {
+			return errs.Forbidden("You do not have access to this district")
+		}
+
+		return c.Next()
+	}
+}
+
+// KeepDistrict stops district administrators from moving themselves to another district
+// (and with it their administrator access) by editing their own profile
+func (g *Guard) KeepDistrict(field string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		raw, err := bodyField(c, field)
+		if err != nil {
+			return err
+		}
+
+		var districtID *int
+		if raw == nil || json.Unmarshal(raw, &districtID) != nil {
+			return c.Next()
+		}
+
+		principal, err := g.principal(c)
+		if err != nil {
+			return err
+		}
+
+		if principal.Role == models.RoleDistrictAdmin &&
+			(districtID == nil || principal.DistrictID == nil || *districtID != *principal.DistrictID) {
+			return errs.Forbidden("District administrators cannot change their district")
+		}
+
+		return c.Next()
+	}
+}
+
+// Self only lets the caller through when the path parameter is their own therapist ID.
+// Delegates may work on a caseload but not manage the profile it belongs to.
+func (g *Guard) Self(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		principal, err := g.principal(c)
+		if err != nil {
+			return err
+		}
+
+		if principal.Role == models.RoleSystemAdmin && principal.KeyDistrictID == nil {
+			return c.Next()
+		}
+
+		// Malformed IDs are left for the handler to reject with a 400
+		id, err := uuid.Parse(c.Params(param))
+		if err == nil && id != principal.TherapistID {
+			return errs.Forbidden("You do not have access to this therapist")
+		}
+
+		return c.Next()
+	}
+}
+
+// TherapistQuery checks the therapist_id style query parameter against the caller.
+// When the parameter is absent it is filled in with the caller's own ID so that list
+// endpoints never return data across caseloads.
+func (g *Guard) TherapistQuery(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		key, raw, err := queryParam(c, param)
+		if err != nil {
+			return err
+		}
+		if raw == "" {
+			callerID, err := CallerID(c)
+			if err != nil {
+				return err
+			}
+			// The parameter may have been given empty under another case, which QueryParser
+			// would still match
+			args := c.Request().URI().QueryArgs()
+			if key != "" {
+				args.Del(key)
+			}
+			args.Set(param, callerID.String())
+			return c.Next()
+		}
+
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Next()
+		}
+
+		if err := g.checkTherapists(c, []uuid.UUID{id}); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// TherapistsInBody checks every therapist ID found under the given JSON body fields
+func (g *Guard) TherapistsInBody(fields ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		ids, err := uuidsFromBody(c, fields)
+		if err != nil {
+			return err
+		}
+
+		if err := g.checkTherapists(c, ids); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// SessionParam checks the session referenced by the path parameter
+func (g *Guard) SessionParam(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		id, err := uuid.Parse(c.Params(param))
+		if err != nil {
+			return c.Next()
+		}
+
+		if err := g.checkOwned(c, []uuid.UUID{id}, g.access.GetSessionOwners, "Session not found"); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// SessionsInBody checks every session ID found under the given JSON body fields
+func (g *Guard) SessionsInBody(fields ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		ids, err := uuidsFromBody(c, fields)
+		if err != nil {
+			return err
+		}
+
+		if err := g.checkOwned(c, ids, g.access.GetSessionOwners, "Session not found"); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// SessionTemplateParam checks the session template referenced by the path parameter
+func (g *Guard) SessionTemplateParam(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		id, err := uuid.Parse(c.Params(param))
+		if err != nil {
+			return c.Next()
+		}
+
+		if err := g.checkOwned(c, []uuid.UUID{id}, g.access.GetSessionTemplateOwners, "Session template not found"); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// SessionTemplatesInBody checks every session template ID found under the given JSON body fields
+func (g *Guard) SessionTemplatesInBody(fields ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		ids, err := uuidsFromBody(c, fields)
+		if err != nil {
+			return err
+		}
+
+		if err := g.checkOwned(c, ids, g.access.GetSessionTemplateOwners, "Session template not found"); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// StudentParam checks the student referenced by the path parameter
+func (g *Guard) StudentParam(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		id, err := uuid.Parse(c.Params(param))
+		if err != nil {
+			return c.Next()
+		}
+
+		if err := g.checkOwned(c, []uuid.UUID{id}, g.access.GetStudentOwners, "Student not found"); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// StudentsInBody checks every student ID found under the given JSON body fields
+func (g *Guard) StudentsInBody(fields ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		ids, err := uuidsFromBody(c, fields)
+		if err != nil {
+			return err
+		}
+
+		if err := g.checkOwned(c, ids, g.access.GetStudentOwners, "Student not found"); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// SessionStudentInBody checks the session_student row referenced by the JSON body field
+func (g *Guard) SessionStudentInBody(field string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !g.enabled {
+			return c.Next()
+		}
+
+		raw, err := bodyField(c, field)
+		if err != nil {
+			return err
+		}
+
+		var id int
+		if raw == nil || json.Unmarshal(raw, &id) != nil {
+			return c.Next()
+		}
+
+		allowed, err := g.accessible(c)
+		if err != nil {
+			return err
+		}
+
+		owners, err := g.access.GetSessionStudentOwners(c.Context(), []int{id})
+		if err != nil {
+			slog.Error("Failed to look up session student owner", "session_student_id", id, "err", err)
+			return errs.InternalServerError("Failed to authorize request")
+		}
+
+		owner, ok := owners[id]
+		if !ok || !allowed.allows(owner) {
+			return errs.NotFound("Session student not found")
+		}
+
+		return c.Next()
+	}
+}
+
+func (g *Guard) checkTherapists(c *fiber.Ctx, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	allowed, err := g.accessible(c)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if !allowed.allows(id) {
+			return errs.Forbidden("You do not have access to this therapist")
+		}
+	}
+
+	return nil
+}
+
+type ownerLookup func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]uuid.UUID, error)
+
+// checkOwned treats records that do not exist and records owned by someone else the same way
+func (g *Guard) checkOwned(c *fiber.Ctx, ids []uuid.UUID, lookup ownerLookup, notFound string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	allowed, err := g.accessible(c)
+	if err != nil {
+		return err
+	}
+
+	// Missing records are left for the handler to report
+	if allowed.all {
+		return nil
+	}
+
+	owners, err := lookup(c.Context(), ids)
+	if err != nil {
+		slog.Error("Failed to look up record owners", "err", err)
+		return errs.InternalServerError("Failed to authorize request")
+	}
+
+	for _, id := range ids {
+		owner, ok := owners[id]
+		if !ok || !allowed.allows(owner) {
+			return errs.NotFound(notFound)
+		}
+	}
+
+	return nil
+}
+
+// uuidsFromBody collects the UUIDs found under the given fields of a JSON body. Fields may
+// hold a single ID or an array of IDs; anything malformed is skipped so the handler can
+// report it with its usual validation error.
+func uuidsFromBody(c *fiber.Ctx, fields []string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, field := range fields {
+		raw, err := bodyField(c, field)
+		if err != nil {
+			return nil, err
+		}
+		if raw == nil {
+			continue
+		}
+
+		var values []string
+		var single string
+		if err := json.Unmarshal(raw, &single); err == nil {
+			values = []string{single}
+		} else if err := json.Unmarshal(raw, &values); err != nil {
+			continue
+		}
+
+		for _, v := range values {
+			if id, err := uuid.Parse(v); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// bodyField finds a field of the JSON body the way BodyParser matches it to the handler's
+// struct: with the app's JSON decoder and ignoring case. A field given under two spellings
+// is refused, since the guard cannot tell which of them the handler ends up with. Bodies
+// that are not JSON are refused too, as BodyParser would read fields from them that the
+// guard never sees. A missing field or malformed JSON gives nil, for the handler to report.
+func bodyField(c *fiber.Ctx, field string) (json.RawMessage, error) {
+	if len(c.Body()) == 0 {
+		return nil, nil
+	}
+
+	contentType := strings.ToLower(string(c.Request().Header.ContentType()))
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	if !strings.HasSuffix(strings.TrimSpace(contentType), "json") {
+		return nil, errs.UnsupportedMediaType("Request body must be JSON")
+	}
+
+	var body map[string]json.RawMessage
+	if err := c.App().Config().JSONDecoder(c.Body(), &body); err != nil {
+		return nil, nil
+	}
+
+	var raw json.RawMessage
+	found := false
+	for key, value := range body {
+		if !strings.EqualFold(key, field) {
+			continue
+		}
+		if found {
+			return nil, errs.BadRequest(fmt.Sprintf("Field %s is given more than once", field))
+		}
+		raw, found = value, true
+	}
+
+	return raw, nil
+}
+
+// queryParam finds a query parameter the way QueryParser matches it to the handler's
+// struct, ignoring case, and returns the key it was given under. A parameter given more
+// than once is refused, since the guard cannot tell which value the handler ends up with.
+func queryParam(c *fiber.Ctx, param string) (key, value string, err error) {
+	count := 0
+	c.Request().URI().QueryArgs().VisitAll(func(k, v []byte) {
+		if strings.EqualFold(string(k), param) {
+			key, value = string(k), string(v)
+			count++
+		}
+	})
+
+	if count > 1 {
+		return "", "", errs.BadRequest(fmt.Sprintf("Query parameter %s is given more than once", param))
+	}
+
+	return key, value, nil
+}
@@ -0,0 +1,739 @@
+package authz_test
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/authz"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	callerID    = uuid.MustParse("f20e5948-01ba-4113-b453-db05d8bde3bc")
+	delegatorID = uuid.MustParse("9dad94d8-6534-4510-90d7-e4e97c175a65")
+	strangerID  = uuid.MustParse("77fc5cde-05d9-4a4e-9b6a-2b2d5c1f3f14")
+)
+
+// newApp mounts the guard middleware in front of a handler that echoes the therapist_id query
+func newApp(userID string, method, path string, guard fiber.Handler) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: errs.ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		if userID != "" {
+			c.Locals("userID", userID)
+		}
+		return c.Next()
+	})
+	app.Add(method, path, guard, func(c *fiber.Ctx) error {
+		return c.SendString(c.Query("therapist_id"))
+	})
+	return app
+}
+
+func withRole(m *mocks.MockAccessRepository, role string, districtID *int) {
+	m.On("GetPrincipal", mock.Anything, callerID).
+		Return(&models.Principal{TherapistID: callerID, Role: role, DistrictID: districtID}, nil)
+}
+
+func accessible(m *mocks.MockAccessRepository) {
+	withRole(m, models.RoleTherapist, nil)
+	m.On("GetAccessibleTherapistIDs", mock.Anything, callerID).Return([]uuid.UUID{callerID, delegatorID}, nil)
+}
+
+func TestGuard_TherapistQuery(t *testing.T) {
+	tests := []struct {
+		name              string
+		userID            string
+		url               string
+		mockSetup         func(*mocks.MockAccessRepository)
+		expectedStatus    int
+		expectedTherapist string
+	}{
+		{
+			name:              "defaults to the caller when absent",
+			userID:            callerID.String(),
+			url:               "/students",
+			mockSetup:         func(m *mocks.MockAccessRepository) {},
+			expectedStatus:    fiber.StatusOK,
+			expectedTherapist: callerID.String(),
+		},
+		{
+			name:              "own therapist id",
+			userID:            callerID.String(),
+			url:               "/students?therapist_id=" + callerID.String(),
+			mockSetup:         accessible,
+			expectedStatus:    fiber.StatusOK,
+			expectedTherapist: callerID.String(),
+		},
+		{
+			name:              "delegated therapist id",
+			userID:            callerID.String(),
+			url:               "/students?therapist_id=" + delegatorID.String(),
+			mockSetup:         accessible,
+			expectedStatus:    fiber.StatusOK,
+			expectedTherapist: delegatorID.String(),
+		},
+		{
+			name:           "someone else's therapist id",
+			userID:         callerID.String(),
+			url:            "/students?therapist_id=" + strangerID.String(),
+			mockSetup:      accessible,
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:              "malformed id is left to the handler",
+			userID:            callerID.String(),
+			url:               "/students?therapist_id=not-a-uuid",
+			mockSetup:         func(m *mocks.MockAccessRepository) {},
+			expectedStatus:    fiber.StatusOK,
+			expectedTherapist: "not-a-uuid",
+		},
+		{
+			name:           "mixed case therapist id",
+			userID:         callerID.String(),
+			url:            "/students?Therapist_ID=" + strangerID.String(),
+			mockSetup:      accessible,
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "repeated therapist id",
+			userID:         callerID.String(),
+			url:            "/students?therapist_id=" + callerID.String() + "&THERAPIST_ID=" + strangerID.String(),
+			mockSetup:      func(m *mocks.MockAccessRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:              "empty mixed case therapist id defaults to the caller",
+			userID:            callerID.String(),
+			url:               "/students?Therapist_ID=",
+			mockSetup:         func(m *mocks.MockAccessRepository) {},
+			expectedStatus:    fiber.StatusOK,
+			expectedTherapist: callerID.String(),
+		},
+		{
+			name:           "unauthenticated",
+			url:            "/students",
+			mockSetup:      func(m *mocks.MockAccessRepository) {},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:   "repository error",
+			userID: callerID.String(),
+			url:    "/students?therapist_id=" + delegatorID.String(),
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				withRole(m, models.RoleTherapist, nil)
+				m.On("GetAccessibleTherapistIDs", mock.Anything, callerID).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockAccessRepository)
+			tt.mockSetup(mockRepo)
+
+			guard := authz.NewGuard(mockRepo, true)
+			app := newApp(tt.userID, fiber.MethodGet, "/students", guard.TherapistQuery("therapist_id"))
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedStatus == fiber.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tt.expectedTherapist, string(body))
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGuard_Self(t *testing.T) {
+	mockRepo := new(mocks.MockAccessRepository)
+	withRole(mockRepo, models.RoleTherapist, nil)
+	guard := authz.NewGuard(mockRepo, true)
+	app := newApp(callerID.String(), fiber.MethodPatch, "/therapists/:id", guard.Self("id"))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPatch, "/therapists/"+callerID.String(), nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// Delegates may not manage the profile of the therapist who delegated to them
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodPatch, "/therapists/"+delegatorID.String(), nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+func TestGuard_StudentParam(t *testing.T) {
+	ownStudent := uuid.New()
+	delegatedStudent := uuid.New()
+	foreignStudent := uuid.New()
+	missingStudent := uuid.New()
+
+	tests := []struct {
+		name           string
+		studentID      string
+		mockSetup      func(*mocks.MockAccessRepository)
+		expectedStatus int
+	}{
+		{
+			name:      "own student",
+			studentID: ownStudent.String(),
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetStudentOwners", mock.Anything, []uuid.UUID{ownStudent}).
+					Return(map[uuid.UUID]uuid.UUID{ownStudent: callerID}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:      "delegated student",
+			studentID: delegatedStudent.String(),
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetStudentOwners", mock.Anything, []uuid.UUID{delegatedStudent}).
+					Return(map[uuid.UUID]uuid.UUID{delegatedStudent: delegatorID}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:      "another therapist's student looks missing",
+			studentID: foreignStudent.String(),
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetStudentOwners", mock.Anything, []uuid.UUID{foreignStudent}).
+					Return(map[uuid.UUID]uuid.UUID{foreignStudent: strangerID}, nil)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:      "missing student",
+			studentID: missingStudent.String(),
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetStudentOwners", mock.Anything, []uuid.UUID{missingStudent}).
+					Return(map[uuid.UUID]uuid.UUID{}, nil)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "malformed id is left to the handler",
+			studentID:      "not-a-uuid",
+			mockSetup:      func(m *mocks.MockAccessRepository) {},
+			expectedStatus: fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockAccessRepository)
+			tt.mockSetup(mockRepo)
+
+			guard := authz.NewGuard(mockRepo, true)
+			app := newApp(callerID.String(), fiber.MethodGet, "/students/:id", guard.StudentParam("id"))
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/students/"+tt.studentID, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGuard_Body(t *testing.T) {
+	ownSession := uuid.New()
+	foreignSession := uuid.New()
+	ownStudent := uuid.New()
+
+	tests := []struct {
+		name           string
+		guard          func(*authz.Guard) fiber.Handler
+		body           string
+		contentType    string
+		mockSetup      func(*mocks.MockAccessRepository)
+		expectedStatus int
+	}{
+		{
+			name:           "therapist in body is accessible",
+			guard:          func(g *authz.Guard) fiber.Handler { return g.TherapistsInBody("therapist_id") },
+			body:           `{"therapist_id": "` + delegatorID.String() + `"}`,
+			mockSetup:      accessible,
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "therapist in body is not accessible",
+			guard:          func(g *authz.Guard) fiber.Handler { return g.TherapistsInBody("therapist_id") },
+			body:           `{"therapist_id": "` + strangerID.String() + `"}`,
+			mockSetup:      accessible,
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "mixed case therapist in body",
+			guard:          func(g *authz.Guard) fiber.Handler { return g.TherapistsInBody("therapist_id") },
+			body:           `{"Therapist_ID": "` + strangerID.String() + `"}`,
+			mockSetup:      accessible,
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "therapist in body under two cases",
+			guard:          func(g *authz.Guard) fiber.Handler { return g.TherapistsInBody("therapist_id") },
+			body:           `{"therapist_id": "` + callerID.String() + `", "THERAPIST_ID": "` + strangerID.String() + `"}`,
+			mockSetup:      func(m *mocks.MockAccessRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "form encoded body",
+			guard:          func(g *authz.Guard) fiber.Handler { return g.TherapistsInBody("therapist_id") },
+			body:           "therapist_id=" + strangerID.String(),
+			contentType:    "application/x-www-form-urlencoded",
+			mockSetup:      func(m *mocks.MockAccessRepository) {},
+			expectedStatus: fiber.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "no therapist in body",
+			guard:          func(g *authz.Guard) fiber.Handler { return g.TherapistsInBody("therapist_id") },
+			body:           `{"first_name": "Emma"}`,
+			mockSetup:      func(m *mocks.MockAccessRepository) {},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:  "all sessions in array are accessible",
+			guard: func(g *authz.Guard) fiber.Handler { return g.SessionsInBody("session_ids") },
+			body:  `{"session_ids": ["` + ownSession.String() + `"]}`,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetSessionOwners", mock.Anything, []uuid.UUID{ownSession}).
+					Return(map[uuid.UUID]uuid.UUID{ownSession: callerID}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:  "one foreign session in array",
+			guard: func(g *authz.Guard) fiber.Handler { return g.SessionsInBody("session_ids") },
+			body:  `{"session_ids": ["` + ownSession.String() + `", "` + foreignSession.String() + `"]}`,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetSessionOwners", mock.Anything, []uuid.UUID{ownSession, foreignSession}).
+					Return(map[uuid.UUID]uuid.UUID{ownSession: callerID, foreignSession: strangerID}, nil)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:  "student in body",
+			guard: func(g *authz.Guard) fiber.Handler { return g.StudentsInBody("student_id") },
+			body:  `{"student_id": "` + ownStudent.String() + `"}`,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetStudentOwners", mock.Anything, []uuid.UUID{ownStudent}).
+					Return(map[uuid.UUID]uuid.UUID{ownStudent: callerID}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:  "foreign session template in body",
+			guard: func(g *authz.Guard) fiber.Handler { return g.SessionTemplatesInBody("template_id") },
+			body:  `{"template_id": "` + foreignSession.String() + `"}`,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetSessionTemplateOwners", mock.Anything, []uuid.UUID{foreignSession}).
+					Return(map[uuid.UUID]uuid.UUID{foreignSession: strangerID}, nil)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:  "accessible session student",
+			guard: func(g *authz.Guard) fiber.Handler { return g.SessionStudentInBody("session_student_id") },
+			body:  `{"session_student_id": 7}`,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetSessionStudentOwners", mock.Anything, []int{7}).
+					Return(map[int]uuid.UUID{7: delegatorID}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:  "foreign session student",
+			guard: func(g *authz.Guard) fiber.Handler { return g.SessionStudentInBody("session_student_id") },
+			body:  `{"session_student_id": 8}`,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetSessionStudentOwners", mock.Anything, []int{8}).
+					Return(map[int]uuid.UUID{8: strangerID}, nil)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:  "mixed case foreign session student",
+			guard: func(g *authz.Guard) fiber.Handler { return g.SessionStudentInBody("session_student_id") },
+			body:  `{"Session_Student_ID": 8}`,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				accessible(m)
+				m.On("GetSessionStudentOwners", mock.Anything, []int{8}).
+					Return(map[int]uuid.UUID{8: strangerID}, nil)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "invalid JSON is left to the handler",
+			guard:          func(g *authz.Guard) fiber.Handler { return g.SessionsInBody("session_ids") },
+			body:           `{"session_ids": `,
+			mockSetup:      func(m *mocks.MockAccessRepository) {},
+			expectedStatus: fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockAccessRepository)
+			tt.mockSetup(mockRepo)
+
+			guard := authz.NewGuard(mockRepo, true)
+			app := newApp(callerID.String(), fiber.MethodPost, "/resource", tt.guard(guard))
+
+			req := httptest.NewRequest(fiber.MethodPost, "/resource", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGuard_Disabled(t *testing.T) {
+	mockRepo := new(mocks.MockAccessRepository)
+	guard := authz.NewGuard(mockRepo, false)
+
+	// No user and a foreign therapist: a disabled guard lets everything through untouched
+	app := newApp("", fiber.MethodGet, "/students", guard.TherapistQuery("therapist_id"))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/students?therapist_id="+strangerID.String(), nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGuard_DistrictAdmin(t *testing.T) {
+	districtID := 3
+	districtTherapist := uuid.New()
+	districtStudent := uuid.New()
+
+	districtAdmin := func(m *mocks.MockAccessRepository) {
+		withRole(m, models.RoleDistrictAdmin, &districtID)
+		m.On("GetAccessibleTherapistIDs", mock.Anything, callerID).Return([]uuid.UUID{callerID}, nil)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		mockSetup      func(*mocks.MockAccessRepository)
+		expectedStatus int
+	}{
+		{
+			name:   "reads students of therapists in the district",
+			method: fiber.MethodGet,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				districtAdmin(m)
+				m.On("GetDistrictTherapistIDs", mock.Anything, districtID).Return([]uuid.UUID{districtTherapist}, nil)
+				m.On("GetStudentOwners", mock.Anything, []uuid.UUID{districtStudent}).
+					Return(map[uuid.UUID]uuid.UUID{districtStudent: districtTherapist}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:   "cannot change students of therapists in the district",
+			method: fiber.MethodPatch,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				districtAdmin(m)
+				m.On("GetStudentOwners", mock.Anything, []uuid.UUID{districtStudent}).
+					Return(map[uuid.UUID]uuid.UUID{districtStudent: districtTherapist}, nil)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:   "system admin reads and changes everything",
+			method: fiber.MethodPatch,
+			mockSetup: func(m *mocks.MockAccessRepository) {
+				withRole(m, models.RoleSystemAdmin, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockAccessRepository)
+			tt.mockSetup(mockRepo)
+
+			guard := authz.NewGuard(mockRepo, true)
+			app := newApp(callerID.String(), tt.method, "/students/:id", guard.StudentParam("id"))
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, "/students/"+districtStudent.String(), nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGuard_Roles(t *testing.T) {
+	districtID := 3
+	otherDistrictID := 4
+
+	tests := []struct {
+		name           string
+		role           string
+		districtID     *int
+		url            string
+		expectedStatus int
+	}{
+		{
+			name:           "district admin of the district",
+			role:           models.RoleDistrictAdmin,
+			districtID:     &districtID,
+			url:            "/districts/3/students",
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "district admin of another district",
+			role:           models.RoleDistrictAdmin,
+			districtID:     &otherDistrictID,
+			url:            "/districts/3/students",
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "system admin",
+			role:           models.RoleSystemAdmin,
+			url:            "/districts/3/students",
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "therapist",
+			role:           models.RoleTherapist,
+			districtID:     &districtID,
+			url:            "/districts/3/students",
+			expectedStatus: fiber.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockAccessRepository)
+			withRole(mockRepo, tt.role, tt.districtID)
+
+			guard := authz.NewGuard(mockRepo, true)
+			app := fiber.New(fiber.Config{ErrorHandler: errs.ErrorHandler})
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("userID", callerID.String())
+				return c.Next()
+			})
+			app.Route("/districts/:id", func(r fiber.Router) {
+				r.Use(guard.RequireRole(models.RoleDistrictAdmin, models.RoleSystemAdmin), guard.DistrictParam("id"))
+				r.Get("/students", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGuard_KeepDistrict(t *testing.T) {
+	districtID := 3
+
+	tests := []struct {
+		name           string
+		role           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "district admin keeps their district",
+			role:           models.RoleDistrictAdmin,
+			body:           `{"district_id": 3, "first_name": "Kevin"}`,
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "district admin moves district",
+			role:           models.RoleDistrictAdmin,
+			body:           `{"district_id": 4}`,
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "district admin moves district under another case",
+			role:           models.RoleDistrictAdmin,
+			body:           `{"District_ID": 4}`,
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "therapist moves district",
+			role:           models.RoleTherapist,
+			body:           `{"district_id": 4}`,
+			expectedStatus: fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockAccessRepository)
+			withRole(mockRepo, tt.role, &districtID)
+
+			guard := authz.NewGuard(mockRepo, true)
+			app := newApp(callerID.String(), fiber.MethodPatch, "/therapists/:id", guard.KeepDistrict("district_id"))
+
+			req := httptest.NewRequest(fiber.MethodPatch, "/therapists/"+callerID.String(), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGuard_APIKeyScopes(t *testing.T) {
+	key := &models.APIKey{
+		ID:        uuid.New(),
+		Scopes:    []string{models.ScopeStudentsRead, models.ScopeSessionsWrite},
+		CreatedBy: callerID,
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		withKey        bool
+		expectedStatus int
+	}{
+		{
+			name:           "read with read scope",
+			method:         fiber.MethodGet,
+			url:            "/api/v1/students/" + uuid.NewString(),
+			withKey:        true,
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "write without write scope",
+			method:         fiber.MethodPost,
+			url:            "/api/v1/students",
+			withKey:        true,
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "write scope covers attendance",
+			method:         fiber.MethodPatch,
+			url:            "/api/v1/session_students",
+			withKey:        true,
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "write scope does not grant read",
+			method:         fiber.MethodGet,
+			url:            "/api/v1/sessions",
+			withKey:        true,
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "key management is never reachable",
+			method:         fiber.MethodGet,
+			url:            "/api/v1/api-keys",
+			withKey:        true,
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "user tokens are not scoped",
+			method:         fiber.MethodPost,
+			url:            "/api/v1/api-keys",
+			expectedStatus: fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockAccessRepository)
+			if tt.withKey {
+				withRole(mockRepo, models.RoleTherapist, nil)
+			}
+			guard := authz.NewGuard(mockRepo, true)
+
+			app := fiber.New(fiber.Config{ErrorHandler: errs.ErrorHandler})
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("userID", callerID.String())
+				if tt.withKey {
+					c.Locals("apiKey", key)
+				}
+				return c.Next()
+			}, guard.APIKeyScopes("/api/v1"))
+			app.Use(func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGuard_APIKeyDistrict(t *testing.T) {
+	districtID := 3
+	key := &models.APIKey{
+		ID:         uuid.New(),
+		Scopes:     []string{models.ScopeStudentsRead, models.ScopeDistrictsRead},
+		DistrictID: &districtID,
+		CreatedBy:  callerID,
+	}
+	withKey := func(c *fiber.Ctx) error {
+		c.Locals("userID", callerID.String())
+		c.Locals("apiKey", key)
+		return c.Next()
+	}
+
+	// A system administrator's key restricted to one district only reaches that district
+	mockRepo := new(mocks.MockAccessRepository)
+	withRole(mockRepo, models.RoleSystemAdmin, nil)
+	mockRepo.On("GetAccessibleTherapistIDs", mock.Anything, callerID).Return([]uuid.UUID{}, nil)
+	mockRepo.On("GetDistrictTherapistIDs", mock.Anything, districtID).Return([]uuid.UUID{delegatorID}, nil)
+	guard := authz.NewGuard(mockRepo, true)
+
+	app := fiber.New(fiber.Config{ErrorHandler: errs.ErrorHandler})
+	app.Get("/students", withKey, guard.TherapistQuery("therapist_id"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/districts/:id/students", withKey, guard.DistrictParam("id"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/students?therapist_id="+delegatorID.String(), nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/students?therapist_id="+strangerID.String(), nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/districts/3/students", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/districts/4/students", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
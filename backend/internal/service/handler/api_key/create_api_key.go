@@ -0,0 +1,66 @@
+package api_key
+
+import (
+	"log/slog"
+	"specialstandard/internal/auth"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/authz"
+	"specialstandard/internal/xvalidator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateAPIKey mints a key acting as the caller. The key is in the response and is never
+// shown again.
+func (h *Handler) CreateAPIKey(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	var input models.CreateAPIKeyInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse API key data")
+	}
+
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return errs.BadRequest("expires_at must be in the future")
+	}
+
+	// Only system administrators can restrict a key to a district other than their own
+	if input.DistrictID != nil {
+		principal, err := h.accessRepository.GetPrincipal(c.Context(), callerID)
+		if err != nil {
+			slog.Error("Failed to load principal", "user_id", callerID, "err", err)
+			return errs.InternalServerError("Failed to create API key")
+		}
+
+		if principal.Role != models.RoleSystemAdmin &&
+			(principal.DistrictID == nil || *principal.DistrictID != *input.DistrictID) {
+			return errs.Forbidden("You can only restrict a key to your own district")
+		}
+	}
+
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		slog.Error("Failed to generate API key", "err", err)
+		return errs.InternalServerError("Failed to create API key")
+	}
+
+	apiKey, err := h.apiKeyRepository.CreateAPIKey(c.Context(), callerID, prefix, auth.HashAPIKey(key), &input)
+	if err != nil {
+		slog.Error("Failed to create API key", "user_id", callerID, "err", err)
+		return errs.InternalServerError("Failed to create API key")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.CreatedAPIKey{
+		APIKey: *apiKey,
+		Key:    key,
+	})
+}
@@ -0,0 +1,41 @@
+package api_key
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/service/authz"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) GetAPIKeyByID(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	key, err := h.apiKeyRepository.GetAPIKeyByID(c.Context(), id, callerID)
+	if err != nil {
+		return apiKeyError(err, id, "Failed to retrieve API key")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(key)
+}
+
+// apiKeyError passes the repository's not found through and hides everything else
+func apiKeyError(err error, id uuid.UUID, message string) error {
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	slog.Error(message, "api_key_id", id, "err", err)
+	return errs.InternalServerError(message)
+}
@@ -0,0 +1,25 @@
+package api_key
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/service/authz"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAPIKeys lists the caller's keys, revoked ones included
+func (h *Handler) GetAPIKeys(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	keys, err := h.apiKeyRepository.GetAPIKeys(c.Context(), callerID)
+	if err != nil {
+		slog.Error("Failed to get API keys", "user_id", callerID, "err", err)
+		return errs.InternalServerError("Failed to retrieve API keys")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(keys)
+}
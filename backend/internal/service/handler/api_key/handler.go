@@ -0,0 +1,20 @@
+package api_key
+
+import (
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+)
+
+type Handler struct {
+	apiKeyRepository storage.APIKeyRepository
+	accessRepository storage.AccessRepository
+	validator        *xvalidator.XValidator
+}
+
+func NewHandler(apiKeyRepository storage.APIKeyRepository, accessRepository storage.AccessRepository) *Handler {
+	return &Handler{
+		apiKeyRepository: apiKeyRepository,
+		accessRepository: accessRepository,
+		validator:        xvalidator.Validator,
+	}
+}
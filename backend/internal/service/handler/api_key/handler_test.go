@@ -0,0 +1,215 @@
+package api_key_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"specialstandard/internal/auth"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/api_key"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var callerID = uuid.MustParse("f20e5948-01ba-4113-b453-db05d8bde3bc")
+
+func newApp(handler *api_key.Handler) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", callerID.String())
+		return c.Next()
+	})
+	app.Post("/api-keys", handler.CreateAPIKey)
+	app.Get("/api-keys", handler.GetAPIKeys)
+	app.Get("/api-keys/:id", handler.GetAPIKeyByID)
+	app.Patch("/api-keys/:id", handler.UpdateAPIKey)
+	app.Delete("/api-keys/:id", handler.RevokeAPIKey)
+	return app
+}
+
+func TestHandler_CreateAPIKey(t *testing.T) {
+	districtID := 2
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.MockAPIKeyRepository, *mocks.MockAccessRepository)
+		expectedStatus int
+	}{
+		{
+			name: "successful create",
+			body: `{"name": "SIS sync", "scopes": ["students:read", "students:write"]}`,
+			mockSetup: func(k *mocks.MockAPIKeyRepository, _ *mocks.MockAccessRepository) {
+				k.On("CreateAPIKey", mock.Anything, callerID, mock.Anything, mock.Anything, mock.Anything).
+					Return(&models.APIKey{ID: uuid.New(), Name: "SIS sync", CreatedBy: callerID}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name: "restricted to own district",
+			body: `{"name": "Reporting", "scopes": ["districts:read"], "district_id": 2}`,
+			mockSetup: func(k *mocks.MockAPIKeyRepository, a *mocks.MockAccessRepository) {
+				a.On("GetPrincipal", mock.Anything, callerID).
+					Return(&models.Principal{TherapistID: callerID, Role: models.RoleDistrictAdmin, DistrictID: &districtID}, nil)
+				k.On("CreateAPIKey", mock.Anything, callerID, mock.Anything, mock.Anything, mock.Anything).
+					Return(&models.APIKey{ID: uuid.New(), Name: "Reporting", DistrictID: &districtID, CreatedBy: callerID}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name: "restricted to another district",
+			body: `{"name": "Reporting", "scopes": ["districts:read"], "district_id": 5}`,
+			mockSetup: func(_ *mocks.MockAPIKeyRepository, a *mocks.MockAccessRepository) {
+				a.On("GetPrincipal", mock.Anything, callerID).
+					Return(&models.Principal{TherapistID: callerID, Role: models.RoleDistrictAdmin, DistrictID: &districtID}, nil)
+			},
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:           "unknown scope",
+			body:           `{"name": "SIS sync", "scopes": ["everything"]}`,
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "no scopes",
+			body:           `{"name": "SIS sync", "scopes": []}`,
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "already expired",
+			body:           `{"name": "SIS sync", "scopes": ["students:read"], "expires_at": "2020-01-01T00:00:00Z"}`,
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "repository error",
+			body: `{"name": "SIS sync", "scopes": ["students:read"]}`,
+			mockSetup: func(k *mocks.MockAPIKeyRepository, _ *mocks.MockAccessRepository) {
+				k.On("CreateAPIKey", mock.Anything, callerID, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := new(mocks.MockAPIKeyRepository)
+			access := new(mocks.MockAccessRepository)
+			if tt.mockSetup != nil {
+				tt.mockSetup(keys, access)
+			}
+			app := newApp(api_key.NewHandler(keys, access))
+
+			req := httptest.NewRequest("POST", "/api-keys", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			keys.AssertExpectations(t)
+			access.AssertExpectations(t)
+
+			if tt.expectedStatus == fiber.StatusCreated {
+				var created models.CreatedAPIKey
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+
+				// The stored hash and prefix belong to the key handed out
+				call := keys.Calls[0]
+				assert.True(t, strings.HasPrefix(created.Key, call.Arguments.String(2)))
+				assert.Equal(t, auth.HashAPIKey(created.Key), call.Arguments.String(3))
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateAPIKey(t *testing.T) {
+	keyID := uuid.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.MockAPIKeyRepository)
+		expectedStatus int
+	}{
+		{
+			name: "successful rescope",
+			body: `{"scopes": ["sessions:read"]}`,
+			mockSetup: func(m *mocks.MockAPIKeyRepository) {
+				m.On("UpdateAPIKey", mock.Anything, keyID, callerID, mock.Anything).
+					Return(&models.APIKey{ID: keyID, Scopes: []string{models.ScopeSessionsRead}}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "nothing to update",
+			body:           `{}`,
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "revoked or someone else's key",
+			body: `{"name": "Renamed"}`,
+			mockSetup: func(m *mocks.MockAPIKeyRepository) {
+				m.On("UpdateAPIKey", mock.Anything, keyID, callerID, mock.Anything).
+					Return(nil, errs.NotFound("API key", "id", keyID.String()))
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := new(mocks.MockAPIKeyRepository)
+			if tt.mockSetup != nil {
+				tt.mockSetup(keys)
+			}
+			app := newApp(api_key.NewHandler(keys, new(mocks.MockAccessRepository)))
+
+			req := httptest.NewRequest("PATCH", "/api-keys/"+keyID.String(), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			keys.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ListAndRevokeAPIKeys(t *testing.T) {
+	keyID := uuid.New()
+	revokedAt := time.Now()
+
+	keys := new(mocks.MockAPIKeyRepository)
+	keys.On("GetAPIKeys", mock.Anything, callerID).Return([]models.APIKey{
+		{ID: keyID, Name: "SIS sync", CreatedBy: callerID},
+		{ID: uuid.New(), Name: "Old job", CreatedBy: callerID, RevokedAt: &revokedAt},
+	}, nil)
+	keys.On("GetAPIKeyByID", mock.Anything, keyID, callerID).Return(&models.APIKey{ID: keyID, CreatedBy: callerID}, nil)
+	keys.On("RevokeAPIKey", mock.Anything, keyID, callerID).Return(nil)
+	app := newApp(api_key.NewHandler(keys, new(mocks.MockAccessRepository)))
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/api-keys", nil), -1)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var listed []models.APIKey
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&listed))
+	assert.Len(t, listed, 2)
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/api-keys/"+keyID.String(), nil), -1)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest("DELETE", "/api-keys/"+keyID.String(), nil), -1)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest("DELETE", "/api-keys/not-a-uuid", nil), -1)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	keys.AssertExpectations(t)
+}
@@ -0,0 +1,30 @@
+package api_key
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/service/authz"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RevokeAPIKey stops a key from working. The record stays so it still shows in the list.
+func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	if err := h.apiKeyRepository.RevokeAPIKey(c.Context(), id, callerID); err != nil {
+		return apiKeyError(err, id, "Failed to revoke API key")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "API key revoked successfully",
+	})
+}
@@ -0,0 +1,44 @@
+package api_key
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/authz"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UpdateAPIKey renames a key or changes its scopes. Revoked keys cannot be changed.
+func (h *Handler) UpdateAPIKey(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	var input models.UpdateAPIKeyInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse API key data")
+	}
+
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	if input.Name == nil && input.Scopes == nil {
+		return errs.BadRequest("No fields to update")
+	}
+
+	key, err := h.apiKeyRepository.UpdateAPIKey(c.Context(), id, callerID, &input)
+	if err != nil {
+		return apiKeyError(err, id, "Failed to update API key")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(key)
+}
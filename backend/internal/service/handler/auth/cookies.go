@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Names of the cookies holding a browser session
+var sessionCookies = []string{"userID", "jwt", "refreshToken", mfaTokenCookie}
+
+func rememberMeExpiry(rememberMe bool) time.Time {
+	if rememberMe {
+		return time.Now().Add(7 * 24 * time.Hour)
+	}
+	// Session cookie
+	return time.Time{}
+}
+
+func setSessionCookies(c *fiber.Ctx, session models.SignInResponse, expires time.Time) {
+	// Check if running in production
+	isProduction := true //os.Getenv("ENV") == "production"
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "userID",
+		Value:    session.User.ID.String(),
+		Expires:  expires,
+		Secure:   isProduction,
+		SameSite: "None",
+		Path:     "/",
+		Domain:   "",
+	})
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "jwt",
+		Value:    session.AccessToken,
+		Expires:  expires,
+		Secure:   isProduction,
+		HTTPOnly: true,   // Recommended for JWT security
+		SameSite: "None", // Changed from "Lax" to "None" for cross-origin
+		Path:     "/",
+		Domain:   "", // Leave empty or set to specific domain
+	})
+
+	if session.RefreshToken != "" {
+		c.Cookie(&fiber.Cookie{
+			Name:     "refreshToken",
+			Value:    session.RefreshToken,
+			Expires:  expires,
+			Secure:   isProduction,
+			HTTPOnly: true,
+			SameSite: "None",
+			Path:     "/",
+			Domain:   "",
+		})
+	}
+}
+
+func clearSessionCookies(c *fiber.Ctx) {
+	for _, name := range sessionCookies {
+		c.Cookie(&fiber.Cookie{
+			Name:     name,
+			Value:    "",
+			Expires:  time.Unix(0, 0),
+			MaxAge:   -1,
+			Secure:   true,
+			HTTPOnly: name != "userID",
+			SameSite: "None",
+			Path:     "/",
+		})
+	}
+}
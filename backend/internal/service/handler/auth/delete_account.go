@@ -1,47 +1,155 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"specialstandard/internal/auth"
 	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
-// DeleteAccount handler to revoke session, delete the account and clear cookies
+// DeleteAccount exports the caller's data, hands their students to another therapist or
+// archives them, removes the account and responds with the export as a download. The
+// database work runs in one transaction that is only committed once Supabase has deleted
+// the user, so a failure on either side leaves the account as it was.
 func (h *Handler) DeleteAccount(c *fiber.Ctx) error {
-	id := c.Params("id")
+	therapistID, err := h.ownAccount(c, "You can only delete your own account")
+	if err != nil {
+		return err
+	}
 
-	// Retrieve the JWT token from cookies
-	accessToken := c.Cookies("jwt")
-	if accessToken == "" {
-		return errs.Unauthorized("No authentication token found")
+	var input models.DeleteAccountInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return errs.InvalidJSON("Failed to parse account deletion options")
+		}
 	}
 
-	// Verify that the user ID from the token matches the requested ID to delete
-	// This is a security check to prevent users from deleting other accounts
-	userID := c.Cookies("userID")
-	if userID != id {
-		return errs.Forbidden("You can only delete your own account")
+	if validationErrors := xvalidator.Validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	if input.StudentAction == models.StudentActionTransfer {
+		if *input.TransferTo == therapistID {
+			return errs.BadRequest("Students cannot be transferred to the account being deleted")
+		}
+
+		target, err := h.therapistRepository.GetTherapistByID(c.Context(), input.TransferTo.String())
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !target.Active) {
+			return errs.NotFound("Therapist", "id", input.TransferTo.String())
+		}
+		if err != nil {
+			slog.Error("Failed to look up transfer target", "therapist_id", input.TransferTo, "err", err)
+			return errs.InternalServerError("Failed to delete account")
+		}
 	}
 
-	err := h.therapistRepository.DeleteTherapist(c.Context(), id)
+	ctx := c.Context()
+	tx, err := h.accountRepository.Begin(ctx)
 	if err != nil {
-		fmt.Println("Error deleting user from database:", err)
-		return errs.InternalServerError(fmt.Sprintf("Failed to delete user data: %v", err))
+		slog.Error("Failed to start account deletion", "therapist_id", therapistID, "err", err)
+		return errs.InternalServerError("Failed to delete account")
 	}
+	defer func() {
+		_ = tx.Rollback(context.Background())
+	}()
 
-	err = auth.SupabaseDeleteAccount(&h.config, userID)
+	export, err := h.accountRepository.ExportTherapistData(ctx, tx, therapistID)
 	if err != nil {
-		fmt.Println("Error deleting account from Supabase:", err)
-		return errs.InternalServerError(fmt.Sprintf("Failed to delete account: %v", err))
+		return accountError(err, therapistID, "Failed to export account data")
+	}
+
+	if len(export.Students) > 0 {
+		switch input.StudentAction {
+		case models.StudentActionTransfer:
+			_, err = h.accountRepository.TransferCaseload(ctx, tx, therapistID, *input.TransferTo)
+		case models.StudentActionArchive:
+			_, err = h.accountRepository.ArchiveStudents(ctx, tx, therapistID)
+		default:
+			return errs.Conflict(fmt.Sprintf(
+				"The account still has %d students, choose student_action transfer or archive", len(export.Students),
+			))
+		}
+		if err != nil {
+			return accountError(err, therapistID, "Failed to move students")
+		}
+	}
+
+	if err := h.accountRepository.DeleteTherapistAccount(ctx, tx, therapistID); err != nil {
+		return accountError(err, therapistID, "Failed to delete user data")
 	}
 
-	// Clear cookies related to authentication
-	c.ClearCookie("jwt")
-	c.ClearCookie("userID")
-	c.ClearCookie("refreshToken")
+	// Supabase is the step that cannot be rolled back, so it goes last before the commit
+	if err := auth.SupabaseDeleteAccount(&h.config, therapistID.String()); err != nil {
+		slog.Error("Failed to delete account from Supabase", "therapist_id", therapistID, "err", err)
+		return errs.InternalServerError("Failed to delete account")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		// The login is gone but the data is not, which needs someone to finish by hand
+		slog.Error("Supabase user deleted but account data was not, clean up manually",
+			"therapist_id", therapistID, "err", err)
+		return errs.InternalServerError("Failed to delete account data")
+	}
+
+	clearSessionCookies(c)
+
+	c.Attachment(fmt.Sprintf("account-export-%s.json", therapistID))
+	return c.Status(fiber.StatusOK).JSON(export)
+}
+
+// ExportAccount responds with everything stored about the caller and their caseload
+func (h *Handler) ExportAccount(c *fiber.Ctx) error {
+	therapistID, err := h.ownAccount(c, "You can only export your own account")
+	if err != nil {
+		return err
+	}
+
+	export, err := h.accountRepository.ExportTherapistData(c.Context(), nil, therapistID)
+	if err != nil {
+		return accountError(err, therapistID, "Failed to export account data")
+	}
+
+	c.Attachment(fmt.Sprintf("account-export-%s.json", therapistID))
+	return c.Status(fiber.StatusOK).JSON(export)
+}
+
+// ownAccount checks that the :id in the path is the caller's own account
+func (h *Handler) ownAccount(c *fiber.Ctx, forbidden string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, errs.BadRequest("Invalid UUID format")
+	}
+
+	// Set by the auth middleware, the cookie is left over from before the middleware
+	userID, _ := c.Locals("userID").(string)
+	if userID == "" {
+		userID = c.Cookies("userID")
+	}
+	if userID == "" {
+		return uuid.Nil, errs.Unauthorized("No authentication token found")
+	}
+
+	if userID != id.String() {
+		return uuid.Nil, errs.Forbidden(forbidden)
+	}
+
+	return id, nil
+}
+
+func accountError(err error, therapistID uuid.UUID, message string) error {
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
 
-	// Return success response
-	return c.SendStatus(fiber.StatusOK)
+	slog.Error(message, "therapist_id", therapistID, "err", err)
+	return errs.InternalServerError(message)
 }
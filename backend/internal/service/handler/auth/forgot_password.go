@@ -5,6 +5,7 @@ import (
 	"os"
 	"specialstandard/internal/auth"
 	"specialstandard/internal/errs"
+	"specialstandard/internal/mailer"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -36,11 +37,24 @@ func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
 	// Construct the redirect URL for the password reset link
 	redirectURL := frontendURL + "/resetPassword"
 
-	err := auth.SupabaseForgotPassword(&h.config, payload.Email, redirectURL)
+	resetURL, err := auth.SupabaseGenerateRecoveryLink(&h.config, payload.Email, redirectURL)
 	if err != nil {
 		fmt.Printf("Password reset request failed: %v\n", err)
 		return errs.InternalServerError(fmt.Sprintf("Password reset request failed: %v", err))
 	}
 
+	// Nobody to email, answered like a success so emails cannot be probed
+	if resetURL == "" {
+		return c.SendStatus(fiber.StatusOK)
+	}
+
+	_, err = h.mailer.Send(c.Context(), []string{payload.Email}, mailer.TemplatePasswordReset, mailer.PasswordResetData{
+		ResetURL: resetURL,
+	})
+	if err != nil {
+		fmt.Printf("Failed to queue password reset email: %v\n", err)
+		return errs.InternalServerError("Password reset request failed")
+	}
+
 	return c.SendStatus(fiber.StatusOK)
 }
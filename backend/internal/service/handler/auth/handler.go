@@ -2,13 +2,21 @@ package auth
 
 import (
 	"specialstandard/internal/config"
+	"specialstandard/internal/mailer"
+	"specialstandard/internal/service/lockout"
+	"specialstandard/internal/service/mfa"
 	"specialstandard/internal/storage"
 )
 
 type Handler struct {
 	config                   config.Supabase
 	therapistRepository      storage.TherapistRepository
+	accountRepository        storage.AccountRepository
+	mfa                      *mfa.Handler
+	mailer                   *mailer.Mailer
 	emailVerificationEnabled bool
+	emailLimiter             *lockout.Limiter
+	ipLimiter                *lockout.Limiter
 }
 
 type Credentials struct {
@@ -19,10 +27,15 @@ type Credentials struct {
 	RememberMe bool    `json:"remember_me"`
 }
 
-func NewHandler(config config.Supabase, therapistRepository storage.TherapistRepository, emailVerificationEnabled bool) *Handler {
+func NewHandler(config config.Supabase, therapistRepository storage.TherapistRepository, attemptRepository storage.AttemptRepository, accountRepository storage.AccountRepository, mfaHandler *mfa.Handler, mail *mailer.Mailer, emailVerificationEnabled bool) *Handler {
 	return &Handler{
 		config,
 		therapistRepository,
+		accountRepository,
+		mfaHandler,
+		mail,
 		emailVerificationEnabled,
+		lockout.NewLimiter(attemptRepository, "login:email", lockout.UserPolicy),
+		lockout.NewLimiter(attemptRepository, "login:ip", lockout.IPPolicy),
 	}
 }
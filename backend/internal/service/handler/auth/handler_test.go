@@ -1,19 +1,27 @@
 package auth
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"specialstandard/internal/config"
 	"specialstandard/internal/errs"
+	"specialstandard/internal/mailer"
 	"specialstandard/internal/models"
+	"specialstandard/internal/service/mfa"
 	"specialstandard/internal/storage/mocks"
+	"specialstandard/internal/totp"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -85,7 +93,7 @@ func TestHandler_SignUp(t *testing.T) {
 				ServiceRoleKey: "SRK",
 			}
 
-			handler := NewHandler(mockConfig, mockRepo, true)
+			handler := NewHandler(mockConfig, mockRepo, new(mocks.MockAttemptRepository), new(mocks.MockAccountRepository), nil, nil, true)
 			app.Post("/signup", handler.SignUp)
 
 			req := httptest.NewRequest("POST", "/signup", strings.NewReader(tt.payload))
@@ -99,10 +107,14 @@ func TestHandler_SignUp(t *testing.T) {
 }
 
 func TestHandler_Login(t *testing.T) {
+	lockedUntil := time.Now().Add(90 * time.Second)
+
 	tests := []struct {
 		name               string
 		payload            string
-		mockSetup          func(*mocks.MockTherapistRepository)
+		supabaseStatus     int
+		supabaseDown       bool
+		attemptSetup       func(*mocks.MockAttemptRepository)
 		expectedStatusCode int
 		wantErr            bool
 	}{
@@ -112,20 +124,83 @@ func TestHandler_Login(t *testing.T) {
 				"email": 123,
 				"password": true
 			}`,
-			mockSetup:          func(m *mocks.MockTherapistRepository) {},
+			attemptSetup:       func(m *mocks.MockAttemptRepository) {},
 			expectedStatusCode: fiber.StatusBadRequest,
 			wantErr:            true,
 		},
 		{
 			name: "Successful Login Request",
 			payload: `{
-				"email": "meow.thegato@gmail.com",
+				"email": "Meow.TheGato@gmail.com",
 				"password": "Meow123;TunaToMe"
 			}`,
-			mockSetup:          func(m *mocks.MockTherapistRepository) {},
+			supabaseStatus: http.StatusOK,
+			attemptSetup: func(m *mocks.MockAttemptRepository) {
+				m.On("GetLockedUntil", mock.Anything, "login:email:meow.thegato@gmail.com").Return(nil, nil)
+				m.On("GetLockedUntil", mock.Anything, "login:ip:0.0.0.0").Return(nil, nil)
+				m.On("ResetAttempts", mock.Anything, "login:email:meow.thegato@gmail.com").Return(nil)
+			},
 			expectedStatusCode: fiber.StatusOK,
 			wantErr:            false,
 		},
+		{
+			name: "Wrong password counts against account and address",
+			payload: `{
+				"email": "meow.thegato@gmail.com",
+				"password": "wrong"
+			}`,
+			supabaseStatus: http.StatusBadRequest,
+			attemptSetup: func(m *mocks.MockAttemptRepository) {
+				m.On("GetLockedUntil", mock.Anything, mock.Anything).Return(nil, nil)
+				m.On("RecordFailure", mock.Anything, "login:email:meow.thegato@gmail.com", time.Hour).Return(2, nil)
+				m.On("RecordFailure", mock.Anything, "login:ip:0.0.0.0", time.Hour).Return(2, nil)
+			},
+			expectedStatusCode: fiber.StatusUnauthorized,
+			wantErr:            true,
+		},
+		{
+			name: "Fifth wrong password locks the account",
+			payload: `{
+				"email": "meow.thegato@gmail.com",
+				"password": "wrong"
+			}`,
+			supabaseStatus: http.StatusBadRequest,
+			attemptSetup: func(m *mocks.MockAttemptRepository) {
+				m.On("GetLockedUntil", mock.Anything, mock.Anything).Return(nil, nil)
+				m.On("RecordFailure", mock.Anything, "login:email:meow.thegato@gmail.com", time.Hour).Return(5, nil)
+				m.On("RecordFailure", mock.Anything, "login:ip:0.0.0.0", time.Hour).Return(5, nil)
+				m.On("LockUntil", mock.Anything, "login:email:meow.thegato@gmail.com", mock.MatchedBy(func(until time.Time) bool {
+					return time.Until(until) > 25*time.Second && time.Until(until) <= 30*time.Second
+				})).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusUnauthorized,
+			wantErr:            true,
+		},
+		{
+			name: "Locked out account does not reach Supabase",
+			payload: `{
+				"email": "meow.thegato@gmail.com",
+				"password": "Meow123;TunaToMe"
+			}`,
+			attemptSetup: func(m *mocks.MockAttemptRepository) {
+				m.On("GetLockedUntil", mock.Anything, "login:email:meow.thegato@gmail.com").Return(&lockedUntil, nil)
+			},
+			expectedStatusCode: fiber.StatusTooManyRequests,
+			wantErr:            true,
+		},
+		{
+			name: "Supabase outage is not a failed attempt",
+			payload: `{
+				"email": "meow.thegato@gmail.com",
+				"password": "Meow123;TunaToMe"
+			}`,
+			supabaseDown: true,
+			attemptSetup: func(m *mocks.MockAttemptRepository) {
+				m.On("GetLockedUntil", mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			expectedStatusCode: fiber.StatusUnauthorized,
+			wantErr:            true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -134,25 +209,37 @@ func TestHandler_Login(t *testing.T) {
 				ErrorHandler: errs.ErrorHandler,
 			})
 			mockRepo := new(mocks.MockTherapistRepository)
-			tt.mockSetup(mockRepo)
+			attempts := new(mocks.MockAttemptRepository)
+			tt.attemptSetup(attempts)
 
 			// Test Supabase server for login
 			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.supabaseStatus == 0 {
+					t.Error("Supabase should not be called")
+				}
 				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusOK)
+				w.WriteHeader(tt.supabaseStatus)
+				if tt.supabaseStatus != http.StatusOK {
+					_, _ = w.Write([]byte(`{"error": "invalid_grant", "error_description": "Invalid login credentials"}`))
+					return
+				}
 				_, _ = w.Write([]byte(`{
 					"access_token": "dummy-token",
 					"user": {"id": "f20e5948-01ba-4113-b453-db05d8bde3bc"}
 				}`))
 			}))
-			defer ts.Close()
-
 			mockConfig := config.Supabase{
 				URL:            ts.URL,
 				ServiceRoleKey: "SRK",
 			}
+			if tt.supabaseDown {
+				// Nothing listens on a closed server
+				ts.Close()
+			} else {
+				defer ts.Close()
+			}
 
-			handler := NewHandler(mockConfig, mockRepo, true)
+			handler := NewHandler(mockConfig, mockRepo, attempts, new(mocks.MockAccountRepository), withoutMFA(t), nil, true)
 			app.Post("/login", handler.Login)
 
 			req := httptest.NewRequest("POST", "/login", strings.NewReader(tt.payload))
@@ -160,9 +247,711 @@ func TestHandler_Login(t *testing.T) {
 			res, _ := app.Test(req, -1)
 
 			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			if tt.expectedStatusCode == fiber.StatusTooManyRequests {
+				assert.NotEmpty(t, res.Header.Get(fiber.HeaderRetryAfter))
+			}
 			mockRepo.AssertExpectations(t)
+			attempts.AssertExpectations(t)
+		})
+	}
+}
+
+// liveSessionID is the Supabase session of liveToken, the access token fakeGoTrue hands out
+var (
+	liveSessionID = uuid.MustParse("5c1b6a0e-3f5d-4a8e-9a57-2d0c1e7b9f34")
+	liveToken     = func() string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":        "f20e5948-01ba-4113-b453-db05d8bde3bc",
+			"session_id": liveSessionID.String(),
+		}).SignedString([]byte("fake-gotrue"))
+		if err != nil {
+			panic(err)
+		}
+		return token
+	}()
+)
+
+// fakeGoTrue answers token refreshes for "valid-refresh" and logouts for liveToken. Every
+// other refresh token is rejected like GoTrue does, and every other access token is expired.
+func fakeGoTrue(t *testing.T, revoked *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/auth/v1/token":
+			assert.Equal(t, "refresh_token", r.URL.Query().Get("grant_type"))
+			assert.Equal(t, "SRK", r.Header.Get("apikey"))
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), `"valid-refresh"`) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error": "invalid_grant", "error_description": "Invalid Refresh Token"}`))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprintf(w, `{
+				"access_token": "%s",
+				"refresh_token": "rotated-refresh",
+				"user": {"id": "f20e5948-01ba-4113-b453-db05d8bde3bc"}
+			}`, liveToken)
+		case "/auth/v1/logout":
+			if r.Header.Get("Authorization") != "Bearer "+liveToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			*revoked = append(*revoked, r.URL.Query().Get("scope"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func responseCookies(res *http.Response) map[string]*http.Cookie {
+	cookies := make(map[string]*http.Cookie)
+	for _, cookie := range res.Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+func TestHandler_Refresh(t *testing.T) {
+	tests := []struct {
+		name               string
+		payload            string
+		cookie             *http.Cookie
+		expectedStatusCode int
+		expectedCookie     string
+	}{
+		{
+			name:               "Refresh token in body",
+			payload:            `{"refresh_token": "valid-refresh"}`,
+			expectedStatusCode: fiber.StatusOK,
+			expectedCookie:     "rotated-refresh",
+		},
+		{
+			name:               "Refresh token in cookie",
+			cookie:             &http.Cookie{Name: "refreshToken", Value: "valid-refresh"},
+			expectedStatusCode: fiber.StatusOK,
+			expectedCookie:     "rotated-refresh",
+		},
+		{
+			name:               "Invalid refresh token",
+			payload:            `{"refresh_token": "revoked-refresh"}`,
+			expectedStatusCode: fiber.StatusUnauthorized,
+			expectedCookie:     "",
+		},
+		{
+			name:               "Missing refresh token",
+			expectedStatusCode: fiber.StatusUnauthorized,
+		},
+		{
+			name:               "Invalid Request Body",
+			payload:            `{"refresh_token": 123}`,
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+
+			var revoked []string
+			ts := fakeGoTrue(t, &revoked)
+			defer ts.Close()
+
+			handler := NewHandler(config.Supabase{URL: ts.URL, ServiceRoleKey: "SRK"}, new(mocks.MockTherapistRepository), new(mocks.MockAttemptRepository), new(mocks.MockAccountRepository), nil, nil, true)
+			app.Post("/refresh", handler.Refresh)
+
+			req := httptest.NewRequest("POST", "/refresh", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			res, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			cookies := responseCookies(res)
+			if tt.expectedStatusCode == fiber.StatusOK {
+				var session models.SignInResponse
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&session))
+				assert.Equal(t, liveToken, session.AccessToken)
+				assert.Equal(t, liveToken, cookies["jwt"].Value)
+				assert.True(t, cookies["jwt"].HttpOnly)
+				assert.True(t, cookies["refreshToken"].HttpOnly)
+			}
+			if cookie, ok := cookies["refreshToken"]; ok {
+				assert.Equal(t, tt.expectedCookie, cookie.Value)
+			}
+		})
+	}
+}
+
+func TestHandler_Logout(t *testing.T) {
+	tests := []struct {
+		name               string
+		query              string
+		header             string
+		cookies            []*http.Cookie
+		expectedStatusCode int
+		expectedRevoked    []string
+	}{
+		{
+			name:               "Bearer token",
+			header:             "Bearer " + liveToken,
+			expectedStatusCode: fiber.StatusOK,
+			expectedRevoked:    []string{"local"},
+		},
+		{
+			name:  "Cookies with global scope",
+			query: "?scope=global",
+			cookies: []*http.Cookie{
+				{Name: "jwt", Value: liveToken},
+				{Name: "userID", Value: "f20e5948-01ba-4113-b453-db05d8bde3bc"},
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedRevoked:    []string{"global"},
+		},
+		{
+			name: "Expired access token is refreshed before revoking",
+			cookies: []*http.Cookie{
+				{Name: "jwt", Value: "expired-token"},
+				{Name: "refreshToken", Value: "valid-refresh"},
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedRevoked:    []string{"local"},
+		},
+		{
+			name: "Only a refresh token",
+			cookies: []*http.Cookie{
+				{Name: "refreshToken", Value: "valid-refresh"},
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedRevoked:    []string{"local"},
+		},
+		{
+			name: "Session already gone",
+			cookies: []*http.Cookie{
+				{Name: "jwt", Value: "expired-token"},
+				{Name: "refreshToken", Value: "revoked-refresh"},
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:               "No session",
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:               "Invalid scope",
+			query:              "?scope=everyone",
+			header:             "Bearer " + liveToken,
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+
+			var revoked []string
+			ts := fakeGoTrue(t, &revoked)
+			defer ts.Close()
+
+			handler := NewHandler(config.Supabase{URL: ts.URL, ServiceRoleKey: "SRK"}, new(mocks.MockTherapistRepository), new(mocks.MockAttemptRepository), new(mocks.MockAccountRepository), nil, nil, true)
+			app.Post("/logout", handler.Logout)
+
+			req := httptest.NewRequest("POST", "/logout"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			for _, cookie := range tt.cookies {
+				req.AddCookie(cookie)
+			}
+			res, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			assert.Equal(t, tt.expectedRevoked, revoked)
+
+			if tt.expectedStatusCode == fiber.StatusOK {
+				cookies := responseCookies(res)
+				for _, name := range []string{"jwt", "userID", "refreshToken"} {
+					if assert.Contains(t, cookies, name) {
+						assert.Empty(t, cookies[name].Value)
+						assert.True(t, cookies[name].Expires.Before(time.Now()))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_ForgotPassword(t *testing.T) {
+	tests := []struct {
+		name               string
+		payload            string
+		gotrueStatus       int
+		expectedStatusCode int
+		expectedEmails     int
+	}{
+		{
+			name:               "Reset link is mailed",
+			payload:            `{"email": "meow.thegato@gmail.com"}`,
+			gotrueStatus:       http.StatusOK,
+			expectedStatusCode: fiber.StatusOK,
+			expectedEmails:     1,
+		},
+		{
+			name:               "Unknown email looks the same",
+			payload:            `{"email": "nobody@example.com"}`,
+			gotrueStatus:       http.StatusNotFound,
+			expectedStatusCode: fiber.StatusOK,
+			expectedEmails:     0,
+		},
+		{
+			name:               "Missing email",
+			payload:            `{}`,
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Supabase error",
+			payload:            `{"email": "meow.thegato@gmail.com"}`,
+			gotrueStatus:       http.StatusInternalServerError,
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/auth/v1/admin/generate_link", r.URL.Path)
+				assert.Equal(t, "Bearer SRK", r.Header.Get("Authorization"))
+				body, _ := io.ReadAll(r.Body)
+				assert.Contains(t, string(body), `"type":"recovery"`)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.gotrueStatus)
+				if tt.gotrueStatus == http.StatusOK {
+					_, _ = w.Write([]byte(`{"action_link": "https://example.supabase.co/auth/v1/verify?token=abc&type=recovery"}`))
+				}
+			}))
+			defer ts.Close()
+
+			backend := mailer.NewLocalBackend("")
+			outbox := new(mocks.MockEmailOutboxRepository)
+			outbox.On("EnqueueEmail", mock.Anything, mock.Anything).Return(&models.OutboxEmail{
+				ID:          uuid.New(),
+				Recipients:  []string{"meow.thegato@gmail.com"},
+				Subject:     "Reset",
+				TextBody:    "https://example.supabase.co/auth/v1/verify?token=abc&type=recovery",
+				MaxAttempts: 5,
+			}, nil).Maybe()
+			outbox.On("MarkEmailSent", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			handler := NewHandler(config.Supabase{URL: ts.URL, ServiceRoleKey: "SRK"}, new(mocks.MockTherapistRepository), new(mocks.MockAttemptRepository), new(mocks.MockAccountRepository), nil, mailer.New(backend, outbox, "noreply@example.com"), true)
+			app.Post("/forgot-password", handler.ForgotPassword)
+
+			req := httptest.NewRequest("POST", "/forgot-password", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			res, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			assert.Len(t, backend.Sent(), tt.expectedEmails)
+			if tt.expectedEmails > 0 {
+				enqueued := outbox.Calls[0].Arguments.Get(1).(*models.CreateOutboxEmailInput)
+				assert.Equal(t, mailer.TemplatePasswordReset, enqueued.Template)
+				assert.Equal(t, []string{"meow.thegato@gmail.com"}, enqueued.Recipients)
+				assert.Contains(t, enqueued.TextBody, "token=abc&type=recovery")
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteAccount(t *testing.T) {
+	therapistID := uuid.MustParse("f20e5948-01ba-4113-b453-db05d8bde3bc")
+	targetID := uuid.New()
+	student := json.RawMessage(`{"id": "8d8c8e76-6c1f-4bd1-a7e4-1fda2d3f5f52"}`)
+
+	tests := []struct {
+		name               string
+		id                 string
+		payload            string
+		students           []json.RawMessage
+		mockSetup          func(*mocks.MockTherapistRepository, *mocks.MockAccountRepository, *mocks.MockTx)
+		supabaseStatus     int
+		expectedStatusCode int
+		expectCommit       bool
+		expectSupabase     bool
+	}{
+		{
+			name:     "Archive students",
+			id:       therapistID.String(),
+			payload:  `{"student_action": "archive"}`,
+			students: []json.RawMessage{student},
+			mockSetup: func(_ *mocks.MockTherapistRepository, a *mocks.MockAccountRepository, _ *mocks.MockTx) {
+				a.On("ArchiveStudents", mock.Anything, mock.Anything, therapistID).Return(1, nil)
+				a.On("DeleteTherapistAccount", mock.Anything, mock.Anything, therapistID).Return(nil)
+			},
+			supabaseStatus:     http.StatusOK,
+			expectedStatusCode: fiber.StatusOK,
+			expectCommit:       true,
+			expectSupabase:     true,
+		},
+		{
+			name:     "Transfer students",
+			id:       therapistID.String(),
+			payload:  fmt.Sprintf(`{"student_action": "transfer", "transfer_to": "%s"}`, targetID),
+			students: []json.RawMessage{student},
+			mockSetup: func(tr *mocks.MockTherapistRepository, a *mocks.MockAccountRepository, _ *mocks.MockTx) {
+				tr.On("GetTherapistByID", mock.Anything).Return(&models.Therapist{ID: targetID, Active: true}, nil)
+				a.On("TransferCaseload", mock.Anything, mock.Anything, therapistID, targetID).Return(1, nil)
+				a.On("DeleteTherapistAccount", mock.Anything, mock.Anything, therapistID).Return(nil)
+			},
+			supabaseStatus:     http.StatusOK,
+			expectedStatusCode: fiber.StatusOK,
+			expectCommit:       true,
+			expectSupabase:     true,
+		},
+		{
+			name: "No students needs no choice and Supabase user already gone",
+			id:   therapistID.String(),
+			mockSetup: func(_ *mocks.MockTherapistRepository, a *mocks.MockAccountRepository, _ *mocks.MockTx) {
+				a.On("DeleteTherapistAccount", mock.Anything, mock.Anything, therapistID).Return(nil)
+			},
+			supabaseStatus:     http.StatusNotFound,
+			expectedStatusCode: fiber.StatusOK,
+			expectCommit:       true,
+			expectSupabase:     true,
+		},
+		{
+			name:               "Students without a choice",
+			id:                 therapistID.String(),
+			students:           []json.RawMessage{student},
+			mockSetup:          func(*mocks.MockTherapistRepository, *mocks.MockAccountRepository, *mocks.MockTx) {},
+			expectedStatusCode: fiber.StatusConflict,
+		},
+		{
+			name:     "Supabase failure rolls back",
+			id:       therapistID.String(),
+			payload:  `{"student_action": "archive"}`,
+			students: []json.RawMessage{student},
+			mockSetup: func(_ *mocks.MockTherapistRepository, a *mocks.MockAccountRepository, _ *mocks.MockTx) {
+				a.On("ArchiveStudents", mock.Anything, mock.Anything, therapistID).Return(1, nil)
+				a.On("DeleteTherapistAccount", mock.Anything, mock.Anything, therapistID).Return(nil)
+			},
+			supabaseStatus:     http.StatusInternalServerError,
+			expectedStatusCode: fiber.StatusInternalServerError,
+			expectSupabase:     true,
+		},
+		{
+			name:     "Database failure never reaches Supabase",
+			id:       therapistID.String(),
+			payload:  `{"student_action": "archive"}`,
+			students: []json.RawMessage{student},
+			mockSetup: func(_ *mocks.MockTherapistRepository, a *mocks.MockAccountRepository, _ *mocks.MockTx) {
+				a.On("ArchiveStudents", mock.Anything, mock.Anything, therapistID).Return(0, fmt.Errorf("deadlock detected"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+		{
+			name:               "Transfer without a target",
+			id:                 therapistID.String(),
+			payload:            `{"student_action": "transfer"}`,
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Transfer to self",
+			id:                 therapistID.String(),
+			payload:            fmt.Sprintf(`{"student_action": "transfer", "transfer_to": "%s"}`, therapistID),
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Unknown action",
+			id:                 therapistID.String(),
+			payload:            `{"student_action": "abandon"}`,
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Someone else's account",
+			id:                 targetID.String(),
+			expectedStatusCode: fiber.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+
+			supabaseCalled := false
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "DELETE", r.Method)
+				assert.Equal(t, "/auth/v1/admin/users/"+therapistID.String(), r.URL.Path)
+				supabaseCalled = true
+				w.WriteHeader(tt.supabaseStatus)
+			}))
+			defer ts.Close()
+
+			therapists := new(mocks.MockTherapistRepository)
+			accounts := new(mocks.MockAccountRepository)
+			tx := new(mocks.MockTx)
+			accounts.On("Begin", mock.Anything).Return(tx, nil).Maybe()
+			accounts.On("ExportTherapistData", mock.Anything, tx, therapistID).Return(&models.TherapistExport{
+				Therapist: json.RawMessage(`{"id": "` + therapistID.String() + `"}`),
+				Students:  tt.students,
+			}, nil).Maybe()
+			tx.On("Rollback", mock.Anything).Return(nil).Maybe()
+			tx.On("Commit", mock.Anything).Return(nil).Maybe()
+			if tt.mockSetup != nil {
+				tt.mockSetup(therapists, accounts, tx)
+			}
+
+			handler := NewHandler(config.Supabase{URL: ts.URL, ServiceRoleKey: "SRK"}, therapists, new(mocks.MockAttemptRepository), accounts, nil, nil, true)
+			app.Delete("/delete-account/:id", func(c *fiber.Ctx) error {
+				c.Locals("userID", therapistID.String())
+				return c.Next()
+			}, handler.DeleteAccount)
+
+			req := httptest.NewRequest("DELETE", "/delete-account/"+tt.id, strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			res, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			assert.Equal(t, tt.expectSupabase, supabaseCalled)
+			if tt.expectCommit {
+				tx.AssertCalled(t, "Commit", mock.Anything)
+			} else {
+				tx.AssertNotCalled(t, "Commit", mock.Anything)
+			}
+			therapists.AssertExpectations(t)
+			accounts.AssertExpectations(t)
+
+			if tt.expectedStatusCode == fiber.StatusOK {
+				assert.Contains(t, res.Header.Get("Content-Disposition"), "account-export-"+therapistID.String()+".json")
+
+				var export models.TherapistExport
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&export))
+				assert.Len(t, export.Students, len(tt.students))
+
+				cookies := responseCookies(res)
+				if assert.Contains(t, cookies, "jwt") {
+					assert.Empty(t, cookies["jwt"].Value)
+				}
+			}
 		})
 	}
 }
 
-// TODO: Do test-stuff for auth-stuff later (update & forgot password, delete account).
+// withoutMFA is an MFA handler for a therapist with no authenticator app
+func withoutMFA(t *testing.T) *mfa.Handler {
+	repo := new(mocks.MockMFARepository)
+	repo.On("GetTOTPFactor", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+	handler, err := mfa.NewHandler(repo, new(mocks.MockAttemptRepository), config.MFA{})
+	assert.NoError(t, err)
+	return handler
+}
+
+func TestHandler_LoginWithTOTP(t *testing.T) {
+	userID := uuid.MustParse("f20e5948-01ba-4113-b453-db05d8bde3bc")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"access_token": "dummy-token",
+			"refresh_token": "valid-refresh",
+			"user": {"id": "f20e5948-01ba-4113-b453-db05d8bde3bc"}
+		}`))
+	}))
+	defer ts.Close()
+
+	attempts := new(mocks.MockAttemptRepository)
+	attempts.On("GetLockedUntil", mock.Anything, mock.Anything).Return(nil, nil)
+	attempts.On("ResetAttempts", mock.Anything, "login:email:meow.thegato@gmail.com").Return(nil)
+
+	mfaRepo := new(mocks.MockMFARepository)
+	mfaRepo.On("GetTOTPFactor", mock.Anything, userID).Return(&models.TOTPFactor{TherapistID: userID, Enabled: true}, nil)
+	mfaRepo.On("CreateMFAChallenge", mock.Anything, userID, mock.AnythingOfType("string"), "valid-refresh", true, mock.AnythingOfType("time.Time")).Return(nil)
+
+	mfaHandler, err := mfa.NewHandler(mfaRepo, attempts, config.MFA{ChallengeTTL: 5 * time.Minute})
+	assert.NoError(t, err)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	handler := NewHandler(config.Supabase{URL: ts.URL, ServiceRoleKey: "SRK"}, new(mocks.MockTherapistRepository), attempts, new(mocks.MockAccountRepository), mfaHandler, nil, true)
+	app.Post("/login", handler.Login)
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{
+		"email": "meow.thegato@gmail.com",
+		"password": "Meow123;TunaToMe",
+		"remember_me": true
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, _ := app.Test(req, -1)
+
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var response models.SignInResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+	assert.True(t, response.RequiresMFA)
+	assert.Equal(t, models.MFAMethodTOTP, response.MFAMethod)
+	assert.NotEmpty(t, response.MFAToken)
+	assert.Empty(t, response.AccessToken)
+	assert.Empty(t, response.RefreshToken)
+
+	cookies := responseCookies(res)
+	assert.NotContains(t, cookies, "jwt")
+	assert.NotContains(t, cookies, "refreshToken")
+	assert.Equal(t, response.MFAToken, cookies["mfaToken"].Value)
+	assert.True(t, cookies["mfaToken"].HttpOnly)
+
+	mfaRepo.AssertExpectations(t)
+}
+
+func TestHandler_VerifyMFA(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	sealer, err := totp.NewSealer(key)
+	assert.NoError(t, err)
+
+	userID := uuid.MustParse("f20e5948-01ba-4113-b453-db05d8bde3bc")
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	sealed, err := sealer.Seal(secret, userID.String())
+	assert.NoError(t, err)
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	assert.NoError(t, err)
+
+	factor := &models.TOTPFactor{TherapistID: userID, SecretSealed: sealed, Enabled: true}
+	challenge := &models.MFAChallenge{
+		ID:           uuid.New(),
+		TherapistID:  userID,
+		RefreshToken: "valid-refresh",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		cookie             *http.Cookie
+		mockSetup          func(*mocks.MockMFARepository, *mocks.MockAttemptRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:    "Code from the authenticator app",
+			payload: fmt.Sprintf(`{"mfa_token": "token", "code": "%s"}`, code),
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetMFAChallenge", mock.Anything, mock.AnythingOfType("string")).Return(challenge, nil)
+				m.On("GetTOTPFactor", mock.Anything, userID).Return(factor, nil)
+				m.On("UseTOTPStep", mock.Anything, userID, mock.AnythingOfType("int64")).Return(true, nil)
+				m.On("DeleteMFAChallenge", mock.Anything, challenge.ID).Return(true, nil)
+				m.On("RecordMFASession", mock.Anything, userID, liveSessionID).Return(nil)
+				a.On("ResetAttempts", mock.Anything, "mfa:user:"+userID.String()).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:    "Token in cookie and a recovery code",
+			payload: `{"code": "abcde-fghjk"}`,
+			cookie:  &http.Cookie{Name: "mfaToken", Value: "token"},
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetMFAChallenge", mock.Anything, mock.AnythingOfType("string")).Return(challenge, nil)
+				m.On("GetTOTPFactor", mock.Anything, userID).Return(factor, nil)
+				m.On("UseRecoveryCode", mock.Anything, userID, mock.AnythingOfType("string")).Return(true, nil)
+				m.On("DeleteMFAChallenge", mock.Anything, challenge.ID).Return(true, nil)
+				m.On("RecordMFASession", mock.Anything, userID, liveSessionID).Return(nil)
+				a.On("ResetAttempts", mock.Anything, "mfa:user:"+userID.String()).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:    "Code already used",
+			payload: fmt.Sprintf(`{"mfa_token": "token", "code": "%s"}`, code),
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetMFAChallenge", mock.Anything, mock.AnythingOfType("string")).Return(challenge, nil)
+				m.On("GetTOTPFactor", mock.Anything, userID).Return(factor, nil)
+				m.On("UseTOTPStep", mock.Anything, userID, mock.AnythingOfType("int64")).Return(false, nil)
+				a.On("RecordFailure", mock.Anything, mock.Anything, time.Hour).Return(1, nil)
+			},
+			expectedStatusCode: fiber.StatusUnauthorized,
+		},
+		{
+			name:    "Wrong code",
+			payload: `{"mfa_token": "token", "code": "000000"}`,
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetMFAChallenge", mock.Anything, mock.AnythingOfType("string")).Return(challenge, nil)
+				m.On("GetTOTPFactor", mock.Anything, userID).Return(factor, nil)
+				a.On("RecordFailure", mock.Anything, "mfa:user:"+userID.String(), time.Hour).Return(1, nil)
+				a.On("RecordFailure", mock.Anything, "mfa:ip:0.0.0.0", time.Hour).Return(1, nil)
+			},
+			expectedStatusCode: fiber.StatusUnauthorized,
+		},
+		{
+			name:    "Expired login",
+			payload: fmt.Sprintf(`{"mfa_token": "token", "code": "%s"}`, code),
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetMFAChallenge", mock.Anything, mock.AnythingOfType("string")).Return(nil, pgx.ErrNoRows)
+			},
+			expectedStatusCode: fiber.StatusUnauthorized,
+		},
+		{
+			name:               "Missing token",
+			payload:            fmt.Sprintf(`{"code": "%s"}`, code),
+			mockSetup:          func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var revoked []string
+			ts := fakeGoTrue(t, &revoked)
+			defer ts.Close()
+
+			mfaRepo := new(mocks.MockMFARepository)
+			attempts := new(mocks.MockAttemptRepository)
+			attempts.On("GetLockedUntil", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			tt.mockSetup(mfaRepo, attempts)
+
+			mfaHandler, err := mfa.NewHandler(mfaRepo, attempts, config.MFA{EncryptionKey: key})
+			assert.NoError(t, err)
+
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			handler := NewHandler(config.Supabase{URL: ts.URL, ServiceRoleKey: "SRK"}, new(mocks.MockTherapistRepository), attempts, new(mocks.MockAccountRepository), mfaHandler, nil, true)
+			app.Post("/mfa/verify", handler.VerifyMFA)
+
+			req := httptest.NewRequest("POST", "/mfa/verify", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			res, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			cookies := responseCookies(res)
+			if tt.expectedStatusCode == fiber.StatusOK {
+				var session models.SignInResponse
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&session))
+				assert.Equal(t, liveToken, session.AccessToken)
+				assert.Equal(t, liveToken, cookies["jwt"].Value)
+				assert.Equal(t, "", cookies["mfaToken"].Value)
+			} else {
+				assert.NotContains(t, cookies, "jwt")
+			}
+			mfaRepo.AssertExpectations(t)
+			attempts.AssertExpectations(t)
+		})
+	}
+}
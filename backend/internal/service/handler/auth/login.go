@@ -5,7 +5,8 @@ import (
 	"log/slog"
 	"specialstandard/internal/auth"
 	"specialstandard/internal/errs"
-	"time"
+	"specialstandard/internal/models"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -17,10 +18,24 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		return errs.BadRequest(fmt.Sprintf("Invalid Request Body: %v", cred))
 	}
 
+	email := strings.ToLower(strings.TrimSpace(cred.Email))
+	if err := h.emailLimiter.Check(c, email); err != nil {
+		return err
+	}
+	if err := h.ipLimiter.Check(c, c.IP()); err != nil {
+		return err
+	}
+
 	signInResponse, err := auth.SupabaseLogin(&h.config, cred.Email, cred.Password, h.emailVerificationEnabled)
 	if err != nil {
 		slog.Error("Supabase Login Error: ", "err", err.Error())
 
+		// Supabase being unreachable is not a wrong guess
+		if httpErr, ok := err.(errs.HTTPError); !ok || httpErr.Code != fiber.StatusInternalServerError {
+			h.emailLimiter.Fail(c.Context(), email)
+			h.ipLimiter.Fail(c.Context(), c.IP())
+		}
+
 		// Extract the actual message from HTTPError
 		if httpErr, ok := err.(errs.HTTPError); ok {
 			return errs.Unauthorized(httpErr.Message.(string))
@@ -29,36 +44,26 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		return errs.Unauthorized("Invalid credentials")
 	}
 
-	var cookieExp time.Time
-	if cred.RememberMe {
-		cookieExp = time.Now().Add(7 * 24 * time.Hour)
-	} else {
-		cookieExp = time.Time{}
+	// Only the account is cleared, one good password must not unlock a guessing client
+	h.emailLimiter.Reset(c.Context(), email)
+
+	totpRequired, err := h.mfa.Required(c.Context(), signInResponse.User.ID)
+	if err != nil {
+		slog.Error("Failed to look up MFA factors", "therapist_id", signInResponse.User.ID, "err", err)
+		return errs.InternalServerError("Failed to log in")
 	}
 
-	// Check if running in production
-	isProduction := true //os.Getenv("ENV") == "production"
+	// An authenticator app replaces the email code. The session stays with us until the
+	// second factor is passed at /auth/mfa/verify.
+	if totpRequired {
+		return h.startMFAChallenge(c, signInResponse, cred.RememberMe)
+	}
 
-	c.Cookie(&fiber.Cookie{
-		Name:     "userID",
-		Value:    signInResponse.User.ID.String(),
-		Expires:  cookieExp,
-		Secure:   isProduction,
-		SameSite: "None",
-		Path:     "/",
-		Domain:   "",
-	})
+	if signInResponse.RequiresMFA {
+		signInResponse.MFAMethod = models.MFAMethodEmail
+	}
 
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    signInResponse.AccessToken,
-		Expires:  cookieExp,
-		Secure:   isProduction,
-		HTTPOnly: true,   // Recommended for JWT security
-		SameSite: "None", // Changed from "Lax" to "None" for cross-origin
-		Path:     "/",
-		Domain:   "", // Leave empty or set to specific domain
-	})
+	setSessionCookies(c, signInResponse, rememberMeExpiry(cred.RememberMe))
 
 	return c.Status(fiber.StatusOK).JSON(signInResponse)
 }
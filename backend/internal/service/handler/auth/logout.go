@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/auth"
+	"specialstandard/internal/errs"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Logout revokes the Supabase session and clears the session cookies. With ?scope=global
+// every session of the user is revoked, with ?scope=others every session but this one.
+func (h *Handler) Logout(c *fiber.Ctx) error {
+	scope := c.Query("scope", "local")
+	if scope != "local" && scope != "global" && scope != "others" {
+		return errs.BadRequest("scope must be one of local, global or others")
+	}
+
+	var payload LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return errs.BadRequest("Invalid request body")
+		}
+	}
+
+	accessToken := c.Cookies("jwt")
+	if header := c.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		accessToken = strings.TrimPrefix(header, "Bearer ")
+	}
+
+	refreshToken := payload.RefreshToken
+	if refreshToken == "" {
+		refreshToken = c.Cookies("refreshToken")
+	}
+
+	// Whatever happens with Supabase, the browser is logged out
+	clearSessionCookies(c)
+
+	if accessToken == "" && refreshToken == "" {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Logged out"})
+	}
+
+	err := errors.New("no access token")
+	if accessToken != "" {
+		err = auth.SupabaseLogout(&h.config, accessToken, scope)
+	}
+
+	// An expired access token cannot revoke its session, but the refresh token can still be
+	// traded for one that can
+	if err != nil && refreshToken != "" && (accessToken == "" || errors.Is(err, auth.ErrSessionExpired)) {
+		session, refreshErr := auth.SupabaseRefresh(&h.config, refreshToken)
+		switch {
+		case refreshErr == nil:
+			err = auth.SupabaseLogout(&h.config, session.AccessToken, scope)
+		case isUnauthorized(refreshErr):
+			// The refresh token is already dead, so is the session
+			err = nil
+		default:
+			err = refreshErr
+		}
+	}
+
+	if err != nil && !errors.Is(err, auth.ErrSessionExpired) {
+		slog.Error("Supabase Logout Error", "err", err.Error())
+		return errs.InternalServerError("Failed to revoke session")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Logged out"})
+}
+
+func isUnauthorized(err error) bool {
+	var httpErr errs.HTTPError
+	return errors.As(err, &httpErr) && httpErr.Code == fiber.StatusUnauthorized
+}
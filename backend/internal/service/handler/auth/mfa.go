@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"log/slog"
+	"specialstandard/internal/auth"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Holds the restricted token between the password and the second factor
+const mfaTokenCookie = "mfaToken"
+
+// startMFAChallenge parks the session of a password login and responds with a token that
+// is only good for /auth/mfa/verify
+func (h *Handler) startMFAChallenge(c *fiber.Ctx, session models.SignInResponse, rememberMe bool) error {
+	token, expiresAt, err := h.mfa.StartChallenge(c.Context(), session.User.ID, session.RefreshToken, rememberMe)
+	if err != nil {
+		slog.Error("Failed to start MFA challenge", "therapist_id", session.User.ID, "err", err)
+		return errs.InternalServerError("Failed to log in")
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     mfaTokenCookie,
+		Value:    token,
+		Expires:  expiresAt,
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: "None",
+		Path:     "/",
+	})
+
+	return c.Status(fiber.StatusOK).JSON(models.SignInResponse{
+		User:        session.User,
+		RequiresMFA: true,
+		MFAMethod:   models.MFAMethodTOTP,
+		MFAToken:    token,
+	})
+}
+
+// VerifyMFA finishes a login that stopped at the second factor. The token comes from the
+// body, or the mfaToken cookie, and the code is from the authenticator app or a recovery code.
+func (h *Handler) VerifyMFA(c *fiber.Ctx) error {
+	var input models.VerifyMFAInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse code")
+	}
+	if input.MFAToken == "" {
+		input.MFAToken = c.Cookies(mfaTokenCookie)
+	}
+
+	if validationErrors := xvalidator.Validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	challenge, err := h.mfa.CompleteChallenge(c, input.MFAToken, input.Code)
+	if err != nil {
+		return err
+	}
+
+	// The parked refresh token is traded rather than handed out, so the client gets a fresh
+	// access token and the one from the password step is never used
+	session, err := auth.SupabaseRefresh(&h.config, challenge.RefreshToken)
+	if err != nil {
+		slog.Error("Failed to resume session after MFA", "therapist_id", challenge.TherapistID, "err", err)
+		if httpErr, ok := err.(errs.HTTPError); ok && httpErr.Code == fiber.StatusUnauthorized {
+			return errs.Unauthorized("The login has expired, sign in again")
+		}
+		return errs.InternalServerError("Failed to log in")
+	}
+
+	// The auth middleware only accepts the session once it is recorded as verified
+	claims, err := auth.UnverifiedClaims(session.AccessToken)
+	if err == nil {
+		err = h.mfa.TrustSession(c.Context(), challenge.TherapistID, claims.SessionID)
+	}
+	if err != nil {
+		slog.Error("Failed to record MFA session", "therapist_id", challenge.TherapistID, "err", err)
+		return errs.InternalServerError("Failed to log in")
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     mfaTokenCookie,
+		Value:    "",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: "None",
+		Path:     "/",
+	})
+	setSessionCookies(c, session, rememberMeExpiry(challenge.RememberMe))
+
+	return c.Status(fiber.StatusOK).JSON(session)
+}
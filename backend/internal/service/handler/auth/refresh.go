@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"log/slog"
+	"specialstandard/internal/auth"
+	"specialstandard/internal/errs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	RememberMe   bool   `json:"remember_me"`
+}
+
+// Refresh exchanges the refresh token (from the body, or the refreshToken cookie) for a new
+// access token so clients do not have to log in again when the access token expires
+func (h *Handler) Refresh(c *fiber.Ctx) error {
+	var payload RefreshRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return errs.BadRequest("Invalid request body")
+		}
+	}
+
+	refreshToken := payload.RefreshToken
+	if refreshToken == "" {
+		refreshToken = c.Cookies("refreshToken")
+	}
+	if refreshToken == "" {
+		return errs.Unauthorized("Refresh token is missing")
+	}
+
+	session, err := auth.SupabaseRefresh(&h.config, refreshToken)
+	if err != nil {
+		slog.Error("Supabase Refresh Error", "err", err.Error())
+		if httpErr, ok := err.(errs.HTTPError); ok {
+			if httpErr.Code == fiber.StatusUnauthorized {
+				// The cookies are useless now, don't keep sending them
+				clearSessionCookies(c)
+			}
+			return httpErr
+		}
+		return errs.InternalServerError("Failed to refresh session")
+	}
+
+	setSessionCookies(c, session, rememberMeExpiry(payload.RememberMe))
+
+	return c.Status(fiber.StatusOK).JSON(session)
+}
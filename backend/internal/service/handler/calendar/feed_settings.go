@@ -0,0 +1,104 @@
+package calendar
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/authz"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetCalendarFeed shows the caller's feed settings. The URL itself is not stored.
+func (h *Handler) GetCalendarFeed(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	feed, err := h.calendarFeedRepository.GetCalendarFeed(c.Context(), callerID)
+	if err != nil {
+		return calendarFeedError(err, callerID, "Failed to get calendar feed")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(feed)
+}
+
+// CreateCalendarFeed creates the caller's feed or rotates its token, so any URL handed
+// out before stops working. The new URL is in the response and is never shown again.
+func (h *Handler) CreateCalendarFeed(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	var input models.CalendarFeedInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return errs.InvalidJSON("Failed to parse calendar feed data")
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		slog.Error("Failed to generate calendar feed token", "err", err)
+		return errs.InternalServerError("Failed to create calendar feed")
+	}
+
+	feed, err := h.calendarFeedRepository.CreateCalendarFeed(c.Context(), callerID, hashToken(token), &input)
+	if err != nil {
+		return calendarFeedError(err, callerID, "Failed to create calendar feed")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.CreatedCalendarFeed{
+		CalendarFeed: *feed,
+		URL:          h.feedURL + "/" + token + ".ics",
+	})
+}
+
+func (h *Handler) UpdateCalendarFeed(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	var input models.CalendarFeedInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse calendar feed data")
+	}
+
+	feed, err := h.calendarFeedRepository.UpdateCalendarFeed(c.Context(), callerID, &input)
+	if err != nil {
+		return calendarFeedError(err, callerID, "Failed to update calendar feed")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(feed)
+}
+
+// DeleteCalendarFeed turns the feed off, calendars subscribed to it stop updating
+func (h *Handler) DeleteCalendarFeed(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.calendarFeedRepository.DeleteCalendarFeed(c.Context(), callerID); err != nil {
+		return calendarFeedError(err, callerID, "Failed to delete calendar feed")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Calendar feed deleted successfully",
+	})
+}
+
+func calendarFeedError(err error, therapistID uuid.UUID, message string) error {
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	slog.Error(message, "therapist_id", therapistID, "err", err)
+	return errs.InternalServerError(message)
+}
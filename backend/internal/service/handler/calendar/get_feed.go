@@ -0,0 +1,280 @@
+package calendar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"slices"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/ical"
+	"specialstandard/internal/models"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	prodID       = "-//The Special Standard//Sessions//EN"
+	calendarName = "The Special Standard"
+	uidDomain    = "specialstandard"
+)
+
+// GetFeed serves a therapist's sessions as iCalendar to calendar apps. It is public, the
+// secret token in the URL is what identifies the therapist. Clients revalidate with the
+// ETag so unchanged feeds cost a 304.
+func (h *Handler) GetFeed(c *fiber.Ctx) error {
+	token := strings.TrimSuffix(c.Params("token"), ".ics")
+
+	feed, err := h.calendarFeedRepository.GetCalendarFeedByToken(c.Context(), hashToken(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errs.NotFound("Calendar feed not found")
+	}
+	if err != nil {
+		slog.Error("Failed to look up calendar feed", "err", err)
+		return errs.InternalServerError("Failed to load calendar feed")
+	}
+
+	sessions, err := h.calendarFeedRepository.GetCalendarSessions(c.Context(), feed.TherapistID)
+	if err != nil {
+		slog.Error("Failed to load calendar sessions", "therapist_id", feed.TherapistID, "err", err)
+		return errs.InternalServerError("Failed to load calendar feed")
+	}
+
+	loc, err := h.calendarFeedRepository.GetCalendarLocation(c.Context(), feed.TherapistID)
+	if err != nil {
+		slog.Error("Failed to load therapist time zone", "therapist_id", feed.TherapistID, "err", err)
+		return errs.InternalServerError("Failed to load calendar feed")
+	}
+
+	body := buildCalendar(sessions, feed.IncludeStudentNames, loc).Marshal()
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+
+	for _, candidate := range strings.Split(c.Get(fiber.HeaderIfNoneMatch), ",") {
+		if candidate = strings.TrimSpace(candidate); candidate == etag || candidate == "*" {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	return c.Status(fiber.StatusOK).Send(body)
+}
+
+// buildCalendar turns each recurring series into one event with a weekly rule, leaving
+// out occurrences that were deleted or cancelled with EXDATE. Occurrences that were
+// edited on their own, and series that cannot be expressed as a rule, are listed as
+// single events. Series repeat in the therapist's location loc, like they were created.
+func buildCalendar(sessions []models.CalendarSession, includeStudentNames bool, loc *time.Location) *ical.Calendar {
+	cal := &ical.Calendar{ProdID: prodID, Name: calendarName}
+
+	var series [][]models.CalendarSession
+	for i, s := range sessions {
+		if i == 0 || s.SessionParentID != sessions[i-1].SessionParentID {
+			series = append(series, nil)
+		}
+		series[len(series)-1] = append(series[len(series)-1], s)
+	}
+
+	for _, occurrences := range series {
+		master, covered := seriesEvent(occurrences, includeStudentNames, loc)
+		if master != nil {
+			cal.Events = append(cal.Events, *master)
+		}
+		for _, s := range occurrences {
+			if !covered[s.ID] {
+				cal.Events = append(cal.Events, sessionEvent(s, includeStudentNames))
+			}
+		}
+	}
+
+	return cal
+}
+
+// seriesEvent builds the recurring event for the occurrences of one session_parent and
+// reports which sessions it covers. It returns nil when fewer than two occurrences
+// still match the series.
+func seriesEvent(occurrences []models.CalendarSession, includeStudentNames bool, loc *time.Location) (*ical.Event, map[uuid.UUID]bool) {
+	rp := occurrences[0].Repetition
+	if rp == nil || len(occurrences) < 2 {
+		return nil, nil
+	}
+
+	// The series is defined by the time slot most of its occurrences still use
+	base := mostCommonSlot(occurrences, loc)
+	duration := base.EndDateTime.Sub(base.StartDateTime)
+	start := base.StartDateTime.In(loc)
+	expected := rp.Occurrences(start, start.Add(duration), time.Time{})
+	if len(expected) < 2 {
+		return nil, nil
+	}
+
+	slots := make(map[time.Time]bool, len(expected))
+	for _, occ := range expected {
+		slots[occ[0].UTC()] = true
+	}
+
+	covered := make(map[uuid.UUID]bool)
+	kept := make(map[time.Time]bool)
+	stamp := time.Time{}
+	for _, s := range occurrences {
+		at := s.StartDateTime.UTC()
+		if !slots[at] || kept[at] || s.EndDateTime.Sub(s.StartDateTime) != duration ||
+			!sameDetails(s, base, includeStudentNames) {
+			continue
+		}
+		covered[s.ID] = true
+		kept[at] = true
+		if t := lastChanged(s); t.After(stamp) {
+			stamp = t
+		}
+	}
+	if len(covered) < 2 {
+		return nil, nil
+	}
+
+	// Extra dates of the series become RDATEs, everything else comes from the rule
+	var weekdays []ical.WeekdayNum
+	var generated, except, extra []time.Time
+	for _, occ := range expected {
+		at, local := occ[0].UTC(), occ[0].In(loc)
+		if slices.ContainsFunc(rp.ExtraDates, func(d time.Time) bool {
+			return d.Format(time.DateOnly) == local.Format(time.DateOnly)
+		}) {
+			if kept[at] {
+				extra = append(extra, at)
+			}
+			continue
+		}
+		generated = append(generated, at)
+		day := ical.WeekdayNum{Day: local.Weekday()}
+		if !slices.Contains(weekdays, day) {
+			weekdays = append(weekdays, day)
+		}
+		if !kept[at] {
+			except = append(except, at)
+		}
+	}
+	if len(generated) == 0 {
+		return nil, nil
+	}
+
+	rule := &ical.Rule{
+		Freq:      ical.Weekly,
+		Interval:  rp.EveryNWeeks,
+		ByDay:     weekdays,
+		WeekStart: time.Sunday,
+	}
+	if rp.RRule != nil {
+		var err error
+		if rule, err = rp.Rule(loc); err != nil {
+			return nil, nil
+		}
+		// The occurrences are bounded by UNTIL alone, COUNT would restart at DTSTART
+		rule.Count = 0
+	}
+	rule.Until = generated[len(generated)-1]
+
+	first := generated[0]
+	event := ical.Event{
+		UID:          "series-" + occurrences[0].SessionParentID.String() + "@" + uidDomain,
+		Stamp:        stamp,
+		Start:        first,
+		End:          first.Add(duration),
+		Rule:         rule,
+		ExceptDates:  except,
+		ExtraDates:   extra,
+		LastModified: stamp,
+	}
+	if loc != time.UTC {
+		event.TimeZone = loc
+	}
+	describe(&event, base, includeStudentNames)
+
+	return &event, covered
+}
+
+func sessionEvent(s models.CalendarSession, includeStudentNames bool) ical.Event {
+	event := ical.Event{
+		UID:          "session-" + s.ID.String() + "@" + uidDomain,
+		Stamp:        lastChanged(s),
+		Start:        s.StartDateTime,
+		End:          s.EndDateTime,
+		LastModified: lastChanged(s),
+	}
+	describe(&event, s, includeStudentNames)
+	return event
+}
+
+func describe(event *ical.Event, s models.CalendarSession, includeStudentNames bool) {
+	event.Summary = s.SessionName
+	if s.Location != nil {
+		event.Location = *s.Location
+	}
+
+	var description []string
+	if includeStudentNames && len(s.StudentFirstNames) > 0 {
+		description = append(description, "Students: "+strings.Join(s.StudentFirstNames, ", "))
+	}
+	if s.Notes != nil && *s.Notes != "" {
+		description = append(description, *s.Notes)
+	}
+	event.Description = strings.Join(description, "\n\n")
+}
+
+// mostCommonSlot picks the first session with the most common time of day in loc and length
+func mostCommonSlot(occurrences []models.CalendarSession, loc *time.Location) models.CalendarSession {
+	type slot struct {
+		clock    time.Duration
+		duration time.Duration
+	}
+	key := func(s models.CalendarSession) slot {
+		start := s.StartDateTime.In(loc)
+		return slot{
+			clock:    time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+			duration: s.EndDateTime.Sub(s.StartDateTime),
+		}
+	}
+
+	counts := make(map[slot]int)
+	best := occurrences[0]
+	for _, s := range occurrences {
+		counts[key(s)]++
+		if counts[key(s)] > counts[key(best)] {
+			best = s
+		}
+	}
+	return best
+}
+
+// sameDetails reports whether two occurrences would be described the same way
+func sameDetails(a, b models.CalendarSession, includeStudentNames bool) bool {
+	return a.SessionName == b.SessionName &&
+		equalText(a.Location, b.Location) &&
+		equalText(a.Notes, b.Notes) &&
+		(!includeStudentNames || slices.Equal(a.StudentFirstNames, b.StudentFirstNames))
+}
+
+func equalText(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// lastChanged keeps DTSTAMP stable between requests so the ETag only changes with the data
+func lastChanged(s models.CalendarSession) time.Time {
+	if s.UpdatedAt != nil {
+		return *s.UpdatedAt
+	}
+	if s.CreatedAt != nil {
+		return *s.CreatedAt
+	}
+	return s.StartDateTime
+}
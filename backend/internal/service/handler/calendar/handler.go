@@ -0,0 +1,41 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"specialstandard/internal/config"
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+	"strings"
+)
+
+type Handler struct {
+	calendarFeedRepository storage.CalendarFeedRepository
+	feedURL                string
+	validator              *xvalidator.XValidator
+}
+
+func NewHandler(calendarFeedRepository storage.CalendarFeedRepository, cfg config.Calendar) *Handler {
+	return &Handler{
+		calendarFeedRepository: calendarFeedRepository,
+		feedURL:                strings.TrimSuffix(cfg.FeedURL, "/"),
+		validator:              xvalidator.Validator,
+	}
+}
+
+// generateToken returns a new secret for a feed URL
+func generateToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashToken is how feed tokens are stored and looked up
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
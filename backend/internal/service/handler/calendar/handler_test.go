@@ -0,0 +1,316 @@
+package calendar_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"specialstandard/internal/config"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/calendar"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var callerID = uuid.MustParse("6f0d3f2e-5c55-4d0c-9a52-3c1f4b1f7d10")
+
+func newApp(handler *calendar.Handler) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Get("/calendar/:token", handler.GetFeed)
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", callerID.String())
+		return c.Next()
+	})
+	app.Get("/calendar-feed", handler.GetCalendarFeed)
+	app.Post("/calendar-feed", handler.CreateCalendarFeed)
+	app.Patch("/calendar-feed", handler.UpdateCalendarFeed)
+	app.Delete("/calendar-feed", handler.DeleteCalendarFeed)
+	return app
+}
+
+func newHandler(repo *mocks.MockCalendarFeedRepository) *calendar.Handler {
+	return calendar.NewHandler(repo, config.Calendar{FeedURL: "https://api.example.com/api/v1/calendar/"})
+}
+
+func ptrString(s string) *string {
+	return &s
+}
+
+func TestHandler_CreateCalendarFeed(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.MockCalendarFeedRepository)
+		expectedStatus int
+	}{
+		{
+			name: "new feed",
+			mockSetup: func(m *mocks.MockCalendarFeedRepository) {
+				m.On("CreateCalendarFeed", mock.Anything, callerID, mock.AnythingOfType("string"), &models.CalendarFeedInput{}).
+					Return(&models.CalendarFeed{TherapistID: callerID}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name: "rotate with student names",
+			body: `{"include_student_names": true}`,
+			mockSetup: func(m *mocks.MockCalendarFeedRepository) {
+				m.On("CreateCalendarFeed", mock.Anything, callerID, mock.AnythingOfType("string"), mock.MatchedBy(func(in *models.CalendarFeedInput) bool {
+					return in.IncludeStudentNames != nil && *in.IncludeStudentNames
+				})).Return(&models.CalendarFeed{TherapistID: callerID, IncludeStudentNames: true}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name:           "invalid JSON",
+			body:           `{"include_student_names": `,
+			mockSetup:      func(*mocks.MockCalendarFeedRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "repository error",
+			mockSetup: func(m *mocks.MockCalendarFeedRepository) {
+				m.On("CreateCalendarFeed", mock.Anything, callerID, mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockCalendarFeedRepository)
+			tt.mockSetup(repo)
+			app := newApp(newHandler(repo))
+
+			req := httptest.NewRequest("POST", "/calendar-feed", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedStatus == fiber.StatusCreated {
+				var created models.CreatedCalendarFeed
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+				assert.True(t, strings.HasPrefix(created.URL, "https://api.example.com/api/v1/calendar/"))
+				assert.True(t, strings.HasSuffix(created.URL, ".ics"))
+
+				// The stored hash is of the token in the URL, never the token itself
+				token := strings.TrimSuffix(strings.TrimPrefix(created.URL, "https://api.example.com/api/v1/calendar/"), ".ics")
+				stored := repo.Calls[0].Arguments.String(2)
+				assert.NotEqual(t, token, stored)
+				assert.Len(t, stored, 64)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_CalendarFeedSettings(t *testing.T) {
+	t.Run("get missing feed", func(t *testing.T) {
+		repo := new(mocks.MockCalendarFeedRepository)
+		repo.On("GetCalendarFeed", mock.Anything, callerID).Return(nil, errs.NotFound("Calendar feed not found"))
+
+		resp, err := newApp(newHandler(repo)).Test(httptest.NewRequest("GET", "/calendar-feed", nil), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("turn student names off", func(t *testing.T) {
+		off := false
+		repo := new(mocks.MockCalendarFeedRepository)
+		repo.On("UpdateCalendarFeed", mock.Anything, callerID, &models.CalendarFeedInput{IncludeStudentNames: &off}).
+			Return(&models.CalendarFeed{TherapistID: callerID}, nil)
+
+		req := httptest.NewRequest("PATCH", "/calendar-feed", strings.NewReader(`{"include_student_names": false}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := newApp(newHandler(repo)).Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("delete feed", func(t *testing.T) {
+		repo := new(mocks.MockCalendarFeedRepository)
+		repo.On("DeleteCalendarFeed", mock.Anything, callerID).Return(nil)
+
+		resp, err := newApp(newHandler(repo)).Test(httptest.NewRequest("DELETE", "/calendar-feed", nil), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestHandler_GetFeed(t *testing.T) {
+	parentID := uuid.New()
+	updated := time.Date(2025, 8, 20, 9, 0, 0, 0, time.UTC)
+	repetition := &models.Repetition{
+		RecurStart:  time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+		RecurEnd:    time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC),
+		EveryNWeeks: 1,
+		Days:        []int{2},
+	}
+	occurrence := func(day int, location string) models.CalendarSession {
+		start := time.Date(2025, 9, day, 14, 0, 0, 0, time.UTC)
+		return models.CalendarSession{
+			Session: models.Session{
+				ID:              uuid.New(),
+				SessionName:     "Articulation group",
+				StartDateTime:   start,
+				EndDateTime:     start.Add(45 * time.Minute),
+				Location:        ptrString(location),
+				Notes:           ptrString("Bring /r/ cards"),
+				UpdatedAt:       &updated,
+				SessionParentID: parentID,
+				Repetition:      repetition,
+			},
+			StudentFirstNames: []string{"Ada", "Grace"},
+		}
+	}
+
+	// 9/16 was deleted and 9/9 moved to another room
+	moved := occurrence(9, "Library")
+	oneOffStart := time.Date(2025, 9, 5, 10, 0, 0, 0, time.UTC)
+	sessions := []models.CalendarSession{
+		occurrence(2, "Room 12"),
+		moved,
+		occurrence(23, "Room 12"),
+		{
+			Session: models.Session{
+				ID:              uuid.New(),
+				SessionName:     "Evaluation",
+				StartDateTime:   oneOffStart,
+				EndDateTime:     oneOffStart.Add(time.Hour),
+				UpdatedAt:       &updated,
+				SessionParentID: uuid.New(),
+			},
+		},
+	}
+
+	t.Run("recurring series with exceptions", func(t *testing.T) {
+		repo := new(mocks.MockCalendarFeedRepository)
+		repo.On("GetCalendarFeedByToken", mock.Anything, mock.AnythingOfType("string")).
+			Return(&models.CalendarFeed{TherapistID: callerID}, nil)
+		repo.On("GetCalendarSessions", mock.Anything, callerID).Return(sessions, nil)
+		repo.On("GetCalendarLocation", mock.Anything, callerID).Return(time.UTC, nil)
+
+		resp, err := newApp(newHandler(repo)).Test(httptest.NewRequest("GET", "/calendar/secret.ics", nil), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.NotEmpty(t, resp.Header.Get("ETag"))
+
+		raw, _ := io.ReadAll(resp.Body)
+		body := string(raw)
+		assert.Equal(t, 3, strings.Count(body, "BEGIN:VEVENT"))
+		assert.Contains(t, body, "UID:series-"+parentID.String()+"@specialstandard\r\n")
+		assert.Contains(t, body, "DTSTART:20250902T140000Z\r\n")
+		assert.Contains(t, body, "RRULE:FREQ=WEEKLY;BYDAY=TU;UNTIL=20250923T140000Z;WKST=SU\r\n")
+		assert.Contains(t, body, "EXDATE:20250909T140000Z,20250916T140000Z\r\n")
+		assert.Contains(t, body, "UID:session-"+moved.ID.String()+"@specialstandard\r\n")
+		assert.Contains(t, body, "LOCATION:Library\r\n")
+		assert.Contains(t, body, "SUMMARY:Evaluation\r\n")
+		assert.Contains(t, body, "DESCRIPTION:Bring /r/ cards\r\n")
+		assert.NotContains(t, body, "Ada")
+	})
+
+	t.Run("student names when enabled", func(t *testing.T) {
+		repo := new(mocks.MockCalendarFeedRepository)
+		repo.On("GetCalendarFeedByToken", mock.Anything, mock.Anything).
+			Return(&models.CalendarFeed{TherapistID: callerID, IncludeStudentNames: true}, nil)
+		repo.On("GetCalendarSessions", mock.Anything, callerID).Return(sessions, nil)
+		repo.On("GetCalendarLocation", mock.Anything, callerID).Return(time.UTC, nil)
+
+		resp, err := newApp(newHandler(repo)).Test(httptest.NewRequest("GET", "/calendar/secret.ics", nil), -1)
+		assert.NoError(t, err)
+
+		raw, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(raw), `DESCRIPTION:Students: Ada\, Grace\n\nBring /r/ cards`)
+	})
+
+	t.Run("unchanged feed is not sent again", func(t *testing.T) {
+		repo := new(mocks.MockCalendarFeedRepository)
+		repo.On("GetCalendarFeedByToken", mock.Anything, mock.Anything).
+			Return(&models.CalendarFeed{TherapistID: callerID}, nil)
+		repo.On("GetCalendarSessions", mock.Anything, callerID).Return(sessions, nil)
+		repo.On("GetCalendarLocation", mock.Anything, callerID).Return(time.UTC, nil)
+		app := newApp(newHandler(repo))
+
+		first, err := app.Test(httptest.NewRequest("GET", "/calendar/secret.ics", nil), -1)
+		assert.NoError(t, err)
+		etag := first.Header.Get("ETag")
+
+		req := httptest.NewRequest("GET", "/calendar/secret.ics", nil)
+		req.Header.Set("If-None-Match", etag)
+		second, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotModified, second.StatusCode)
+		assert.Equal(t, etag, second.Header.Get("ETag"))
+	})
+
+	t.Run("series across the end of daylight saving", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		assert.NoError(t, err)
+
+		// Mondays at 10:00 in New York are 14:00 UTC before November 1 and 15:00 after
+		seriesID := uuid.New()
+		mondays := &models.Repetition{
+			RecurStart:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			RecurEnd:    time.Date(2026, 11, 9, 0, 0, 0, 0, time.UTC),
+			EveryNWeeks: 1,
+			Days:        []int{1},
+		}
+		var zoned []models.CalendarSession
+		for _, day := range []time.Time{
+			time.Date(2026, 10, 19, 10, 0, 0, 0, loc),
+			time.Date(2026, 10, 26, 10, 0, 0, 0, loc),
+			time.Date(2026, 11, 2, 10, 0, 0, 0, loc),
+			time.Date(2026, 11, 9, 10, 0, 0, 0, loc),
+		} {
+			zoned = append(zoned, models.CalendarSession{Session: models.Session{
+				ID:              uuid.New(),
+				SessionName:     "Fluency group",
+				StartDateTime:   day.UTC(),
+				EndDateTime:     day.Add(time.Hour).UTC(),
+				UpdatedAt:       &updated,
+				SessionParentID: seriesID,
+				Repetition:      mondays,
+			}})
+		}
+
+		repo := new(mocks.MockCalendarFeedRepository)
+		repo.On("GetCalendarFeedByToken", mock.Anything, mock.Anything).
+			Return(&models.CalendarFeed{TherapistID: callerID}, nil)
+		repo.On("GetCalendarSessions", mock.Anything, callerID).Return(zoned, nil)
+		repo.On("GetCalendarLocation", mock.Anything, callerID).Return(loc, nil)
+
+		resp, err := newApp(newHandler(repo)).Test(httptest.NewRequest("GET", "/calendar/secret.ics", nil), -1)
+		assert.NoError(t, err)
+
+		raw, _ := io.ReadAll(resp.Body)
+		body := string(raw)
+		assert.Equal(t, 1, strings.Count(body, "BEGIN:VEVENT"))
+		assert.Contains(t, body, "DTSTART;TZID=America/New_York:20261019T100000\r\n")
+		assert.Contains(t, body, "RRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20261109T150000Z;WKST=SU\r\n")
+		assert.NotContains(t, body, "EXDATE")
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		repo := new(mocks.MockCalendarFeedRepository)
+		repo.On("GetCalendarFeedByToken", mock.Anything, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		resp, err := newApp(newHandler(repo)).Test(httptest.NewRequest("GET", "/calendar/revoked.ics", nil), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
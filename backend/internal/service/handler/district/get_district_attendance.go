@@ -0,0 +1,41 @@
+package district
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetDistrictAttendance handles GET /districts/:id/attendance
+func (h *Handler) GetDistrictAttendance(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	dateFrom := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC) // old date as default - get all records
+	if dateStr := c.Query("date_from"); dateStr != "" {
+		dateFrom, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return errs.BadRequest("Invalid date_from format")
+		}
+	}
+
+	dateTo := time.Now()
+	if dateStr := c.Query("date_to"); dateStr != "" {
+		dateTo, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return errs.BadRequest("Invalid date_to format")
+		}
+	}
+
+	attendance, err := h.districtRepository.GetDistrictAttendance(c.Context(), districtID, dateFrom, dateTo)
+	if err != nil {
+		slog.Error("Failed to get district attendance", "district_id", districtID, "err", err)
+		return errs.InternalServerError("Failed to fetch district attendance")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(attendance)
+}
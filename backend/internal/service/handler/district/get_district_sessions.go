@@ -0,0 +1,49 @@
+package district
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/utils"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetDistrictSessions handles GET /districts/:id/sessions
+func (h *Handler) GetDistrictSessions(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	var filter models.GetDistrictSessionsQuery
+	if err := c.QueryParser(&filter); err != nil {
+		return errs.BadRequest("Invalid query parameters")
+	}
+
+	if validationErrors := h.validator.Validate(filter); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	if filter.StartTime != nil && filter.EndTime != nil && filter.EndTime.Before(*filter.StartTime) {
+		return errs.BadRequest("enddate must be after startdate")
+	}
+
+	pagination := utils.NewPagination()
+	if err := c.QueryParser(&pagination); err != nil {
+		return errs.BadRequest("Invalid Pagination Query Parameters")
+	}
+
+	if validationErrors := h.validator.Validate(pagination); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	sessions, err := h.districtRepository.GetDistrictSessions(c.Context(), districtID, &filter, pagination)
+	if err != nil {
+		slog.Error("Failed to get district sessions", "district_id", districtID, "err", err)
+		return errs.InternalServerError("Failed to fetch district sessions")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sessions)
+}
@@ -0,0 +1,36 @@
+package district
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/utils"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetDistrictStudents handles GET /districts/:id/students
+func (h *Handler) GetDistrictStudents(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	query := models.GetDistrictStudentsQuery{Pagination: utils.NewPagination()}
+	if err := c.QueryParser(&query); err != nil {
+		return errs.BadRequest("Invalid query parameters")
+	}
+
+	if validationErrors := h.validator.Validate(query); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	students, err := h.districtRepository.GetDistrictStudents(c.Context(), districtID, query.SchoolID, query.Pagination)
+	if err != nil {
+		slog.Error("Failed to get district students", "district_id", districtID, "err", err)
+		return errs.InternalServerError("Failed to fetch district students")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(students)
+}
@@ -0,0 +1,35 @@
+package district
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/utils"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetDistrictTherapists handles GET /districts/:id/therapists
+func (h *Handler) GetDistrictTherapists(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	pagination := utils.NewPagination()
+	if err := c.QueryParser(&pagination); err != nil {
+		return errs.BadRequest("Invalid Pagination Query Parameters")
+	}
+
+	if validationErrors := h.validator.Validate(pagination); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	therapists, err := h.districtRepository.GetDistrictTherapists(c.Context(), districtID, pagination)
+	if err != nil {
+		slog.Error("Failed to get district therapists", "district_id", districtID, "err", err)
+		return errs.InternalServerError("Failed to fetch district therapists")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(therapists)
+}
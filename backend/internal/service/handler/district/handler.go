@@ -2,14 +2,17 @@ package district
 
 import (
 	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
 )
 
 type Handler struct {
 	districtRepository storage.DistrictRepository
+	validator          *xvalidator.XValidator
 }
 
 func NewHandler(districtRepository storage.DistrictRepository) *Handler {
 	return &Handler{
 		districtRepository: districtRepository,
+		validator:          xvalidator.Validator,
 	}
-}
\ No newline at end of file
+}
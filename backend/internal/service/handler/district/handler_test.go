@@ -0,0 +1,234 @@
+package district_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/district"
+	"specialstandard/internal/storage/mocks"
+	"specialstandard/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newApp(mockRepo *mocks.MockDistrictRepository) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	handler := district.NewHandler(mockRepo)
+	app.Get("/districts/:id/therapists", handler.GetDistrictTherapists)
+	app.Get("/districts/:id/students", handler.GetDistrictStudents)
+	app.Get("/districts/:id/sessions", handler.GetDistrictSessions)
+	app.Get("/districts/:id/attendance", handler.GetDistrictAttendance)
+	return app
+}
+
+func TestHandler_GetDistrictTherapists(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func(*mocks.MockDistrictRepository)
+		expectedStatus int
+	}{
+		{
+			name: "successful get district therapists",
+			url:  "/districts/1/therapists",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				m.On("GetDistrictTherapists", mock.Anything, 1, utils.NewPagination()).Return([]models.Therapist{
+					{ID: uuid.New(), FirstName: "Kevin", LastName: "Matula", Role: models.RoleTherapist},
+				}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "custom pagination",
+			url:  "/districts/1/therapists?page=2&limit=5",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				m.On("GetDistrictTherapists", mock.Anything, 1, utils.Pagination{Page: 2, Limit: 5}).Return([]models.Therapist{}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "invalid district id",
+			url:            "/districts/abc/therapists",
+			mockSetup:      func(m *mocks.MockDistrictRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "repository error",
+			url:  "/districts/1/therapists",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				m.On("GetDistrictTherapists", mock.Anything, 1, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockDistrictRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, _ := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetDistrictStudents(t *testing.T) {
+	schoolID := 2
+
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func(*mocks.MockDistrictRepository)
+		expectedStatus int
+	}{
+		{
+			name: "successful get district students",
+			url:  "/districts/1/students",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				m.On("GetDistrictStudents", mock.Anything, 1, (*int)(nil), utils.NewPagination()).Return([]models.Student{
+					{ID: uuid.New(), FirstName: "Emma", LastName: "Johnson", SchoolID: 1},
+				}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "filter by school",
+			url:  "/districts/1/students?school_id=2",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				m.On("GetDistrictStudents", mock.Anything, 1, &schoolID, utils.NewPagination()).Return([]models.Student{}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "invalid school id",
+			url:            "/districts/1/students?school_id=0",
+			mockSetup:      func(m *mocks.MockDistrictRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "repository error",
+			url:  "/districts/1/students",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				m.On("GetDistrictStudents", mock.Anything, 1, mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockDistrictRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, _ := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetDistrictSessions(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func(*mocks.MockDistrictRepository)
+		expectedStatus int
+	}{
+		{
+			name: "successful get district sessions",
+			url:  "/districts/1/sessions?startdate=2025-01-01T00:00:00Z&enddate=2025-02-01T00:00:00Z",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				m.On("GetDistrictSessions", mock.Anything, 1, mock.MatchedBy(func(f *models.GetDistrictSessionsQuery) bool {
+					return f.StartTime != nil && f.EndTime != nil && f.TherapistID == nil
+				}), utils.NewPagination()).Return([]models.Session{{ID: uuid.New(), SessionName: "Speech"}}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "end before start",
+			url:            "/districts/1/sessions?startdate=2025-02-01T00:00:00Z&enddate=2025-01-01T00:00:00Z",
+			mockSetup:      func(m *mocks.MockDistrictRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "repository error",
+			url:  "/districts/1/sessions",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				m.On("GetDistrictSessions", mock.Anything, 1, mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockDistrictRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, _ := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetDistrictAttendance(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func(*mocks.MockDistrictRepository)
+		expectedStatus int
+	}{
+		{
+			name: "successful get district attendance",
+			url:  "/districts/1/attendance?date_from=2025-01-01&date_to=2025-06-30",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+				to := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+				m.On("GetDistrictAttendance", mock.Anything, 1, from, to).Return([]models.StudentAttendance{
+					{StudentID: uuid.New(), FirstName: "Emma", PresentCount: 8, TotalCount: 10},
+				}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "invalid date",
+			url:            "/districts/1/attendance?date_from=01-01-2025",
+			mockSetup:      func(m *mocks.MockDistrictRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "repository error",
+			url:  "/districts/1/attendance",
+			mockSetup: func(m *mocks.MockDistrictRepository) {
+				m.On("GetDistrictAttendance", mock.Anything, 1, mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockDistrictRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, _ := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
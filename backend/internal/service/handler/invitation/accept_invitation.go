@@ -0,0 +1,154 @@
+package invitation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"specialstandard/internal/auth"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetInvitation handles GET /auth/invitation?token=, describing the invitation so the
+// accept page can show where the therapist is joining
+func (h *Handler) GetInvitation(c *fiber.Ctx) error {
+	id, err := h.parseToken(c.Query("token"))
+	if err != nil {
+		return err
+	}
+
+	preview, err := h.invitationRepository.GetInvitationPreview(c.Context(), id)
+	if err != nil {
+		var httpErr errs.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code == fiber.StatusNotFound {
+			return errs.NotFound("The invitation is no longer valid")
+		}
+		slog.Error("Failed to get invitation", "invitation_id", id, "err", err)
+		return errs.InternalServerError("Failed to retrieve invitation")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(preview)
+}
+
+// AcceptInvitation handles POST /auth/accept-invite. It creates the Supabase user and the
+// therapist in the invitation's district and schools together: the invitation stays locked
+// in a transaction until both exist, and the Supabase user is removed again if the
+// therapist cannot be saved.
+func (h *Handler) AcceptInvitation(c *fiber.Ctx) error {
+	var input models.AcceptInvitationInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse invitation data")
+	}
+
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	id, err := h.parseToken(input.Token)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Context()
+	tx, err := h.invitationRepository.Begin(ctx)
+	if err != nil {
+		slog.Error("Failed to start accepting invitation", "invitation_id", id, "err", err)
+		return errs.InternalServerError("Failed to accept invitation")
+	}
+	defer func() {
+		_ = tx.Rollback(context.Background())
+	}()
+
+	invitation, err := h.invitationRepository.ClaimInvitation(ctx, tx, id)
+	if err != nil {
+		var httpErr errs.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code == fiber.StatusNotFound {
+			return errs.NotFound("The invitation is no longer valid")
+		}
+		slog.Error("Failed to load invitation", "invitation_id", id, "err", err)
+		return errs.InternalServerError("Failed to accept invitation")
+	}
+
+	switch invitation.Status(time.Now()) {
+	case models.InvitationAccepted:
+		return errs.Conflict("The invitation has already been accepted")
+	case models.InvitationRevoked:
+		return errs.NotFound("The invitation is no longer valid")
+	case models.InvitationExpired:
+		return errs.BadRequest("The invitation has expired, ask for a new one")
+	}
+
+	therapistInput := models.CreateTherapistInput{
+		FirstName:  valueOr(input.FirstName, invitation.FirstName),
+		LastName:   valueOr(input.LastName, invitation.LastName),
+		Email:      invitation.Email,
+		Schools:    invitation.Schools,
+		DistrictID: &invitation.DistrictID,
+	}
+	if therapistInput.FirstName == "" || therapistInput.LastName == "" {
+		return errs.BadRequest("first_name and last_name are required")
+	}
+
+	userID, err := auth.SupabaseCreateUser(&h.supabase, invitation.Email, input.Password)
+	if err != nil {
+		var httpErr errs.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code != fiber.StatusInternalServerError {
+			return httpErr
+		}
+		slog.Error("Failed to create Supabase user for invitation", "invitation_id", id, "err", err)
+		return errs.InternalServerError("Failed to accept invitation")
+	}
+	therapistInput.ID = userID
+
+	therapist, err := h.invitationRepository.AcceptInvitation(ctx, tx, invitation.ID, &therapistInput)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		h.removeUser(userID)
+		slog.Error("Failed to save invited therapist", "invitation_id", id, "err", err)
+		return errs.InternalServerError("Failed to accept invitation")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(therapist)
+}
+
+// parseToken maps an invitation token to the invitation it names
+func (h *Handler) parseToken(token string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, errs.BadRequest("Invitation token is missing")
+	}
+
+	id, err := auth.ParseInvitation(h.config.SigningKey, token)
+	if errors.Is(err, auth.ErrInvitationExpired) {
+		return uuid.Nil, errs.BadRequest("The invitation has expired, ask for a new one")
+	}
+	if err != nil {
+		return uuid.Nil, errs.BadRequest("Invalid invitation token")
+	}
+
+	return id, nil
+}
+
+// removeUser undoes the Supabase side of an acceptance the database did not take
+func (h *Handler) removeUser(userID uuid.UUID) {
+	if err := auth.SupabaseDeleteAccount(&h.supabase, userID.String()); err != nil {
+		slog.Error("Supabase user created for an invitation was not removed, clean up manually",
+			"user_id", userID, "err", err)
+	}
+}
+
+func valueOr(value, fallback *string) string {
+	if value != nil {
+		return *value
+	}
+	if fallback != nil {
+		return *fallback
+	}
+	return ""
+}
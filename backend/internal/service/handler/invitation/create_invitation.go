@@ -0,0 +1,79 @@
+package invitation
+
+import (
+	"log/slog"
+	"net/url"
+	"specialstandard/internal/auth"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/mailer"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/authz"
+	"specialstandard/internal/xvalidator"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateInvitation handles POST /districts/:id/invitations. It emails the therapist a link
+// to set up an account already placed in the district and schools of the invitation.
+func (h *Handler) CreateInvitation(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	var input models.CreateInvitationInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse invitation data")
+	}
+	input.Email = strings.TrimSpace(input.Email)
+
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	if h.config.SigningKey == "" {
+		return errs.InternalServerError("Invitations are not configured")
+	}
+
+	invitation, err := h.invitationRepository.CreateInvitation(c.Context(), districtID, callerID, &input, time.Now().Add(h.config.TTL))
+	if err != nil {
+		return invitationError(err, districtID, "Failed to create invitation")
+	}
+
+	if err := h.sendInvitation(c, invitation); err != nil {
+		// An invitation nobody received would block inviting the email again
+		if revokeErr := h.invitationRepository.RevokeInvitation(c.Context(), districtID, invitation.ID); revokeErr != nil {
+			slog.Error("Failed to revoke unsent invitation", "invitation_id", invitation.ID, "err", revokeErr)
+		}
+		slog.Error("Failed to send invitation", "invitation_id", invitation.ID, "err", err)
+		return errs.InternalServerError("Failed to send invitation")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(invitation)
+}
+
+func (h *Handler) sendInvitation(c *fiber.Ctx, invitation *models.Invitation) error {
+	token, err := auth.SignInvitation(h.config.SigningKey, invitation.ID, invitation.Email, invitation.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	preview, err := h.invitationRepository.GetInvitationPreview(c.Context(), invitation.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.mailer.Send(c.Context(), []string{invitation.Email}, mailer.TemplateInvitation, mailer.InvitationData{
+		DistrictName: preview.DistrictName,
+		AcceptURL:    h.config.AcceptURL + "?token=" + url.QueryEscape(token),
+		ExpiresOn:    invitation.ExpiresAt.Format("January 2, 2006"),
+	})
+	return err
+}
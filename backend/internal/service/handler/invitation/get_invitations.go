@@ -0,0 +1,25 @@
+package invitation
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetInvitations handles GET /districts/:id/invitations, listing the invitations that can
+// still be accepted
+func (h *Handler) GetInvitations(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	invitations, err := h.invitationRepository.GetPendingInvitations(c.Context(), districtID)
+	if err != nil {
+		slog.Error("Failed to get invitations", "district_id", districtID, "err", err)
+		return errs.InternalServerError("Failed to retrieve invitations")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(invitations)
+}
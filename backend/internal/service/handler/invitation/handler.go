@@ -0,0 +1,26 @@
+package invitation
+
+import (
+	"specialstandard/internal/config"
+	"specialstandard/internal/mailer"
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+)
+
+type Handler struct {
+	invitationRepository storage.InvitationRepository
+	supabase             config.Supabase
+	config               config.Invitation
+	mailer               *mailer.Mailer
+	validator            *xvalidator.XValidator
+}
+
+func NewHandler(invitationRepository storage.InvitationRepository, supabase config.Supabase, cfg config.Invitation, mail *mailer.Mailer) *Handler {
+	return &Handler{
+		invitationRepository: invitationRepository,
+		supabase:             supabase,
+		config:               cfg,
+		mailer:               mail,
+		validator:            xvalidator.Validator,
+	}
+}
@@ -0,0 +1,459 @@
+package invitation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"specialstandard/internal/auth"
+	"specialstandard/internal/config"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/mailer"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const signingKey = "test-signing-key"
+
+var testConfig = config.Invitation{
+	SigningKey: signingKey,
+	TTL:        7 * 24 * time.Hour,
+	AcceptURL:  "https://app.example.com/accept-invite",
+}
+
+func ptrString(s string) *string {
+	return &s
+}
+
+func TestHandler_CreateInvitation(t *testing.T) {
+	callerID := uuid.New()
+	invitation := &models.Invitation{
+		ID:         uuid.New(),
+		Email:      "new.therapist@example.com",
+		DistrictID: 3,
+		Schools:    []int{4, 5},
+		ExpiresAt:  time.Now().Add(testConfig.TTL),
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		config             config.Invitation
+		mockSetup          func(*mocks.MockInvitationRepository)
+		expectedStatusCode int
+		expectEmail        bool
+	}{
+		{
+			name:    "Invitation is emailed",
+			payload: `{"email": " new.therapist@example.com ", "schools": [4, 5]}`,
+			config:  testConfig,
+			mockSetup: func(m *mocks.MockInvitationRepository) {
+				m.On("CreateInvitation", mock.Anything, 3, callerID, mock.MatchedBy(func(input *models.CreateInvitationInput) bool {
+					return input.Email == "new.therapist@example.com"
+				}), mock.MatchedBy(func(expiresAt time.Time) bool {
+					return time.Until(expiresAt) > 6*24*time.Hour
+				})).Return(invitation, nil)
+				m.On("GetInvitationPreview", mock.Anything, invitation.ID).Return(&models.InvitationPreview{
+					Email:        invitation.Email,
+					DistrictName: "Boston Public Schools",
+				}, nil)
+			},
+			expectedStatusCode: fiber.StatusCreated,
+			expectEmail:        true,
+		},
+		{
+			name:    "School outside the district",
+			payload: `{"email": "new.therapist@example.com", "schools": [99]}`,
+			config:  testConfig,
+			mockSetup: func(m *mocks.MockInvitationRepository) {
+				m.On("CreateInvitation", mock.Anything, 3, callerID, mock.Anything, mock.Anything).
+					Return(nil, errs.BadRequest("Every school must belong to the district"))
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Already invited",
+			payload: `{"email": "new.therapist@example.com", "schools": [4]}`,
+			config:  testConfig,
+			mockSetup: func(m *mocks.MockInvitationRepository) {
+				m.On("CreateInvitation", mock.Anything, 3, callerID, mock.Anything, mock.Anything).
+					Return(nil, errs.Conflict("This email already has a pending invitation"))
+			},
+			expectedStatusCode: fiber.StatusConflict,
+		},
+		{
+			name:               "Invalid email",
+			payload:            `{"email": "not-an-email", "schools": [4]}`,
+			config:             testConfig,
+			mockSetup:          func(m *mocks.MockInvitationRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "No schools",
+			payload:            `{"email": "new.therapist@example.com", "schools": []}`,
+			config:             testConfig,
+			mockSetup:          func(m *mocks.MockInvitationRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Signing key missing",
+			payload:            `{"email": "new.therapist@example.com", "schools": [4]}`,
+			config:             config.Invitation{TTL: time.Hour},
+			mockSetup:          func(m *mocks.MockInvitationRepository) {},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockInvitationRepository)
+			tt.mockSetup(repo)
+
+			backend := mailer.NewLocalBackend("")
+			outbox := new(mocks.MockEmailOutboxRepository)
+			outbox.On("EnqueueEmail", mock.Anything, mock.Anything).Return(&models.OutboxEmail{
+				ID:          uuid.New(),
+				Recipients:  []string{invitation.Email},
+				MaxAttempts: 5,
+			}, nil).Maybe()
+			outbox.On("MarkEmailSent", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			handler := NewHandler(repo, config.Supabase{}, tt.config, mailer.New(backend, outbox, "noreply@example.com"))
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			app.Post("/districts/:id/invitations", func(c *fiber.Ctx) error {
+				c.Locals("userID", callerID.String())
+				return c.Next()
+			}, handler.CreateInvitation)
+
+			req := httptest.NewRequest("POST", "/districts/3/invitations", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			res, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			repo.AssertExpectations(t)
+
+			if !tt.expectEmail {
+				outbox.AssertNotCalled(t, "EnqueueEmail", mock.Anything, mock.Anything)
+				return
+			}
+
+			enqueued := outbox.Calls[0].Arguments.Get(1).(*models.CreateOutboxEmailInput)
+			assert.Equal(t, mailer.TemplateInvitation, enqueued.Template)
+			assert.Equal(t, []string{invitation.Email}, enqueued.Recipients)
+			assert.Contains(t, enqueued.Subject, "Boston Public Schools")
+
+			// The emailed link carries a token naming the invitation
+			start := strings.Index(enqueued.TextBody, testConfig.AcceptURL)
+			assert.GreaterOrEqual(t, start, 0)
+			link, err := url.Parse(strings.Fields(enqueued.TextBody[start:])[0])
+			assert.NoError(t, err)
+			id, err := auth.ParseInvitation(signingKey, link.Query().Get("token"))
+			assert.NoError(t, err)
+			assert.Equal(t, invitation.ID, id)
+		})
+	}
+}
+
+func TestHandler_CreateInvitation_EmailFailureRevokes(t *testing.T) {
+	callerID := uuid.New()
+	invitation := &models.Invitation{ID: uuid.New(), Email: "new.therapist@example.com", DistrictID: 3, ExpiresAt: time.Now().Add(time.Hour)}
+
+	repo := new(mocks.MockInvitationRepository)
+	repo.On("CreateInvitation", mock.Anything, 3, callerID, mock.Anything, mock.Anything).Return(invitation, nil)
+	repo.On("GetInvitationPreview", mock.Anything, invitation.ID).Return(&models.InvitationPreview{DistrictName: "Boston Public Schools"}, nil)
+	repo.On("RevokeInvitation", mock.Anything, 3, invitation.ID).Return(nil)
+
+	outbox := new(mocks.MockEmailOutboxRepository)
+	outbox.On("EnqueueEmail", mock.Anything, mock.Anything).Return(nil, errors.New("outbox unavailable"))
+
+	handler := NewHandler(repo, config.Supabase{}, testConfig, mailer.New(mailer.NewLocalBackend(""), outbox, "noreply@example.com"))
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Post("/districts/:id/invitations", func(c *fiber.Ctx) error {
+		c.Locals("userID", callerID.String())
+		return c.Next()
+	}, handler.CreateInvitation)
+
+	req := httptest.NewRequest("POST", "/districts/3/invitations", strings.NewReader(`{"email": "new.therapist@example.com", "schools": [4]}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, _ := app.Test(req, -1)
+
+	assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+	repo.AssertExpectations(t)
+}
+
+func TestHandler_GetInvitations(t *testing.T) {
+	repo := new(mocks.MockInvitationRepository)
+	repo.On("GetPendingInvitations", mock.Anything, 3).Return([]models.Invitation{
+		{ID: uuid.New(), Email: "a@example.com", DistrictID: 3},
+		{ID: uuid.New(), Email: "b@example.com", DistrictID: 3},
+	}, nil)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Get("/districts/:id/invitations", NewHandler(repo, config.Supabase{}, testConfig, nil).GetInvitations)
+
+	res, _ := app.Test(httptest.NewRequest("GET", "/districts/3/invitations", nil), -1)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var invitations []models.Invitation
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&invitations))
+	assert.Len(t, invitations, 2)
+
+	res, _ = app.Test(httptest.NewRequest("GET", "/districts/abc/invitations", nil), -1)
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+}
+
+func TestHandler_RevokeInvitation(t *testing.T) {
+	pending := uuid.New()
+	gone := uuid.New()
+
+	repo := new(mocks.MockInvitationRepository)
+	repo.On("RevokeInvitation", mock.Anything, 3, pending).Return(nil)
+	repo.On("RevokeInvitation", mock.Anything, 3, gone).Return(errs.NotFound("Invitation", "id", gone.String()))
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Delete("/districts/:id/invitations/:invitationId", NewHandler(repo, config.Supabase{}, testConfig, nil).RevokeInvitation)
+
+	res, _ := app.Test(httptest.NewRequest("DELETE", "/districts/3/invitations/"+pending.String(), nil), -1)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	res, _ = app.Test(httptest.NewRequest("DELETE", "/districts/3/invitations/"+gone.String(), nil), -1)
+	assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+
+	res, _ = app.Test(httptest.NewRequest("DELETE", "/districts/3/invitations/not-a-uuid", nil), -1)
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+	repo.AssertExpectations(t)
+}
+
+func TestHandler_GetInvitation(t *testing.T) {
+	id := uuid.New()
+	token, err := auth.SignInvitation(signingKey, id, "new.therapist@example.com", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	forged, err := auth.SignInvitation("another-key", id, "new.therapist@example.com", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	repo := new(mocks.MockInvitationRepository)
+	repo.On("GetInvitationPreview", mock.Anything, id).Return(&models.InvitationPreview{
+		Email:        "new.therapist@example.com",
+		DistrictName: "Boston Public Schools",
+		SchoolNames:  []string{"Lincoln Elementary"},
+	}, nil)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Get("/invitation", NewHandler(repo, config.Supabase{}, testConfig, nil).GetInvitation)
+
+	res, _ := app.Test(httptest.NewRequest("GET", "/invitation?token="+token, nil), -1)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var preview models.InvitationPreview
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&preview))
+	assert.Equal(t, "Boston Public Schools", preview.DistrictName)
+
+	res, _ = app.Test(httptest.NewRequest("GET", "/invitation?token="+forged, nil), -1)
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+	res, _ = app.Test(httptest.NewRequest("GET", "/invitation", nil), -1)
+	assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+}
+
+func TestHandler_AcceptInvitation(t *testing.T) {
+	userID := uuid.MustParse("f20e5948-01ba-4113-b453-db05d8bde3bc")
+	id := uuid.New()
+	token, err := auth.SignInvitation(signingKey, id, "new.therapist@example.com", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	expiredToken, err := auth.SignInvitation(signingKey, id, "new.therapist@example.com", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+
+	pending := func() *models.Invitation {
+		return &models.Invitation{
+			ID:         id,
+			Email:      "new.therapist@example.com",
+			FirstName:  ptrString("Ada"),
+			LastName:   ptrString("Lovelace"),
+			DistrictID: 3,
+			Schools:    []int{4, 5},
+			ExpiresAt:  time.Now().Add(time.Hour),
+		}
+	}
+	accepted := pending()
+	accepted.AcceptedAt = ptrTime(time.Now())
+	revoked := pending()
+	revoked.RevokedAt = ptrTime(time.Now())
+
+	tests := []struct {
+		name               string
+		payload            string
+		supabaseStatus     int
+		mockSetup          func(*mocks.MockInvitationRepository, *mocks.MockTx)
+		expectedStatusCode int
+		expectCreate       bool
+		expectDelete       bool
+		expectCommit       bool
+	}{
+		{
+			name:           "Therapist is created in the invited district and schools",
+			payload:        fmt.Sprintf(`{"token": "%s", "password": "Meow123;TunaToMe", "first_name": "Augusta"}`, token),
+			supabaseStatus: http.StatusOK,
+			mockSetup: func(m *mocks.MockInvitationRepository, tx *mocks.MockTx) {
+				m.On("ClaimInvitation", mock.Anything, tx, id).Return(pending(), nil)
+				m.On("AcceptInvitation", mock.Anything, tx, id, mock.MatchedBy(func(input *models.CreateTherapistInput) bool {
+					return input.ID == userID && input.FirstName == "Augusta" && input.LastName == "Lovelace" &&
+						input.Email == "new.therapist@example.com" && *input.DistrictID == 3 && len(input.Schools) == 2
+				})).Return(&models.Therapist{ID: userID, FirstName: "Augusta", LastName: "Lovelace", DistrictID: ptrInt(3)}, nil)
+			},
+			expectedStatusCode: fiber.StatusCreated,
+			expectCreate:       true,
+			expectCommit:       true,
+		},
+		{
+			name:           "Saving the therapist fails and the Supabase user is removed",
+			payload:        fmt.Sprintf(`{"token": "%s", "password": "Meow123;TunaToMe"}`, token),
+			supabaseStatus: http.StatusOK,
+			mockSetup: func(m *mocks.MockInvitationRepository, tx *mocks.MockTx) {
+				m.On("ClaimInvitation", mock.Anything, tx, id).Return(pending(), nil)
+				m.On("AcceptInvitation", mock.Anything, tx, id, mock.Anything).Return(nil, errors.New("connection reset"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+			expectCreate:       true,
+			expectDelete:       true,
+		},
+		{
+			name:           "Email already has a Supabase account",
+			payload:        fmt.Sprintf(`{"token": "%s", "password": "Meow123;TunaToMe"}`, token),
+			supabaseStatus: http.StatusUnprocessableEntity,
+			mockSetup: func(m *mocks.MockInvitationRepository, tx *mocks.MockTx) {
+				m.On("ClaimInvitation", mock.Anything, tx, id).Return(pending(), nil)
+			},
+			expectedStatusCode: fiber.StatusConflict,
+			expectCreate:       true,
+		},
+		{
+			name:    "Weak password",
+			payload: fmt.Sprintf(`{"token": "%s", "password": "password"}`, token),
+			mockSetup: func(m *mocks.MockInvitationRepository, tx *mocks.MockTx) {
+				m.On("ClaimInvitation", mock.Anything, tx, id).Return(pending(), nil)
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Already accepted",
+			payload: fmt.Sprintf(`{"token": "%s", "password": "Meow123;TunaToMe"}`, token),
+			mockSetup: func(m *mocks.MockInvitationRepository, tx *mocks.MockTx) {
+				m.On("ClaimInvitation", mock.Anything, tx, id).Return(accepted, nil)
+			},
+			expectedStatusCode: fiber.StatusConflict,
+		},
+		{
+			name:    "Revoked",
+			payload: fmt.Sprintf(`{"token": "%s", "password": "Meow123;TunaToMe"}`, token),
+			mockSetup: func(m *mocks.MockInvitationRepository, tx *mocks.MockTx) {
+				m.On("ClaimInvitation", mock.Anything, tx, id).Return(revoked, nil)
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:               "Expired token",
+			payload:            fmt.Sprintf(`{"token": "%s", "password": "Meow123;TunaToMe"}`, expiredToken),
+			mockSetup:          func(*mocks.MockInvitationRepository, *mocks.MockTx) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Tampered token",
+			payload:            fmt.Sprintf(`{"token": "%sx", "password": "Meow123;TunaToMe"}`, token),
+			mockSetup:          func(*mocks.MockInvitationRepository, *mocks.MockTx) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Missing password",
+			payload:            fmt.Sprintf(`{"token": "%s"}`, token),
+			mockSetup:          func(*mocks.MockInvitationRepository, *mocks.MockTx) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created, deleted bool
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Bearer SRK", r.Header.Get("Authorization"))
+				switch {
+				case r.Method == "POST" && r.URL.Path == "/auth/v1/admin/users":
+					created = true
+					w.WriteHeader(tt.supabaseStatus)
+					if tt.supabaseStatus == http.StatusOK {
+						_, _ = w.Write([]byte(`{"id": "` + userID.String() + `"}`))
+					} else {
+						_, _ = w.Write([]byte(`{"error_code": "email_exists"}`))
+					}
+				case r.Method == "DELETE" && r.URL.Path == "/auth/v1/admin/users/"+userID.String():
+					deleted = true
+					w.WriteHeader(http.StatusOK)
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer ts.Close()
+
+			repo := new(mocks.MockInvitationRepository)
+			tx := new(mocks.MockTx)
+			repo.On("Begin", mock.Anything).Return(tx, nil).Maybe()
+			tx.On("Rollback", mock.Anything).Return(nil).Maybe()
+			tx.On("Commit", mock.Anything).Return(nil).Maybe()
+			tt.mockSetup(repo, tx)
+
+			handler := NewHandler(repo, config.Supabase{URL: ts.URL, ServiceRoleKey: "SRK"}, testConfig, nil)
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			app.Post("/accept-invite", handler.AcceptInvitation)
+
+			req := httptest.NewRequest("POST", "/accept-invite", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			res, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			assert.Equal(t, tt.expectCreate, created)
+			assert.Equal(t, tt.expectDelete, deleted)
+			if tt.expectCommit {
+				tx.AssertCalled(t, "Commit", mock.Anything)
+			} else {
+				tx.AssertNotCalled(t, "Commit", mock.Anything)
+			}
+			repo.AssertExpectations(t)
+
+			if tt.expectedStatusCode == fiber.StatusCreated {
+				var therapist models.Therapist
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&therapist))
+				assert.Equal(t, userID, therapist.ID)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func ptrInt(i int) *int {
+	return &i
+}
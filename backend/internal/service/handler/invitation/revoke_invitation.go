@@ -0,0 +1,42 @@
+package invitation
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RevokeInvitation handles DELETE /districts/:id/invitations/:invitationId. The link in the
+// email stops working, the record stays.
+func (h *Handler) RevokeInvitation(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	id, err := uuid.Parse(c.Params("invitationId"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	if err := h.invitationRepository.RevokeInvitation(c.Context(), districtID, id); err != nil {
+		return invitationError(err, districtID, "Failed to revoke invitation")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitation revoked successfully",
+	})
+}
+
+func invitationError(err error, districtID int, message string) error {
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	slog.Error(message, "district_id", districtID, "err", err)
+	return errs.InternalServerError(message)
+}
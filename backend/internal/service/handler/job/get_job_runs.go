@@ -0,0 +1,31 @@
+package job
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/utils"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetJobRuns handles GET /jobs/runs, listing background job runs the latest first.
+// ?status=failed lists the runs given up on, with the error of their last attempt.
+func (h *Handler) GetJobRuns(c *fiber.Ctx) error {
+	query := models.GetJobRunsQuery{Pagination: utils.NewPagination()}
+	if err := c.QueryParser(&query); err != nil {
+		return errs.BadRequest("Invalid query parameters")
+	}
+	if validationErrors := h.validator.Validate(query); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	runs, err := h.jobRunRepository.GetJobRuns(c.Context(), &query)
+	if err != nil {
+		slog.Error("Failed to get job runs", "err", err)
+		return errs.InternalServerError("Failed to retrieve job runs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(runs)
+}
@@ -0,0 +1,18 @@
+package job
+
+import (
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+)
+
+type Handler struct {
+	jobRunRepository storage.JobRunRepository
+	validator        *xvalidator.XValidator
+}
+
+func NewHandler(jobRunRepository storage.JobRunRepository) *Handler {
+	return &Handler{
+		jobRunRepository: jobRunRepository,
+		validator:        xvalidator.Validator,
+	}
+}
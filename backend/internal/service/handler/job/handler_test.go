@@ -0,0 +1,111 @@
+package job_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/job"
+	"specialstandard/internal/storage/mocks"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_GetJobRuns(t *testing.T) {
+	lastError := "connection refused"
+
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func(*mocks.MockJobRunRepository)
+		expectedStatus int
+		wantCount      int
+	}{
+		{
+			name: "lists runs",
+			url:  "/jobs/runs",
+			mockSetup: func(m *mocks.MockJobRunRepository) {
+				m.On("GetJobRuns", mock.Anything, mock.MatchedBy(func(q *models.GetJobRunsQuery) bool {
+					return q.Name == "" && q.Status == "" && q.Limit == 100
+				})).Return([]models.JobRun{
+					{ID: uuid.New(), Name: models.JobPromoteStudents, Status: models.JobSucceeded},
+					{ID: uuid.New(), Name: "purge_job_runs", Status: models.JobPending},
+				}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantCount:      2,
+		},
+		{
+			name: "failed runs of a job",
+			url:  "/jobs/runs?name=promote_students&status=failed",
+			mockSetup: func(m *mocks.MockJobRunRepository) {
+				m.On("GetJobRuns", mock.Anything, mock.MatchedBy(func(q *models.GetJobRunsQuery) bool {
+					return q.Name == models.JobPromoteStudents && q.Status == models.JobFailed
+				})).Return([]models.JobRun{
+					{ID: uuid.New(), Name: models.JobPromoteStudents, Status: models.JobFailed, Attempts: 3, LastError: &lastError},
+				}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantCount:      1,
+		},
+		{
+			name: "no runs",
+			url:  "/jobs/runs",
+			mockSetup: func(m *mocks.MockJobRunRepository) {
+				m.On("GetJobRuns", mock.Anything, mock.Anything).Return([]models.JobRun{}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "invalid status",
+			url:            "/jobs/runs?status=stuck",
+			mockSetup:      func(m *mocks.MockJobRunRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "invalid pagination",
+			url:            "/jobs/runs?limit=0",
+			mockSetup:      func(m *mocks.MockJobRunRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "repository error",
+			url:  "/jobs/runs",
+			mockSetup: func(m *mocks.MockJobRunRepository) {
+				m.On("GetJobRuns", mock.Anything, mock.Anything).Return(nil, errors.New("database connection failed"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockJobRunRepository)
+			tt.mockSetup(mockRepo)
+
+			handler := job.NewHandler(mockRepo)
+			app.Get("/jobs/runs", handler.GetJobRuns)
+
+			req := httptest.NewRequest("GET", tt.url, nil)
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+
+			if tt.expectedStatus == fiber.StatusOK {
+				var runs []models.JobRun
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&runs))
+				assert.Len(t, runs, tt.wantCount)
+			}
+		})
+	}
+}
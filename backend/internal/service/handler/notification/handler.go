@@ -0,0 +1,33 @@
+package notification
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	notificationRepository storage.NotificationRepository
+	validator              *xvalidator.XValidator
+}
+
+func NewHandler(notificationRepository storage.NotificationRepository) *Handler {
+	return &Handler{
+		notificationRepository: notificationRepository,
+		validator:              xvalidator.Validator,
+	}
+}
+
+func notificationError(err error, therapistID uuid.UUID, message string) error {
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	slog.Error(message, "therapist_id", therapistID, "err", err)
+	return errs.InternalServerError(message)
+}
@@ -0,0 +1,171 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/notification"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var callerID = uuid.MustParse("6f0d3f2e-5c55-4d0c-9a52-3c1f4b1f7d10")
+
+func newApp(handler *notification.Handler) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", callerID.String())
+		return c.Next()
+	})
+	app.Get("/notification-preferences", handler.GetNotificationPreference)
+	app.Patch("/notification-preferences", handler.UpdateNotificationPreference)
+	return app
+}
+
+func defaults() *models.NotificationPreference {
+	return &models.NotificationPreference{
+		TherapistID:         callerID,
+		Channel:             models.NotificationNone,
+		DigestEnabled:       true,
+		DigestTime:          "07:00",
+		ReminderEnabled:     true,
+		ReminderLeadMinutes: 15,
+		TimeZone:            "America/New_York",
+	}
+}
+
+func TestHandler_GetNotificationPreference(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*mocks.MockNotificationRepository)
+		expectedStatus int
+	}{
+		{
+			name: "defaults",
+			mockSetup: func(m *mocks.MockNotificationRepository) {
+				m.On("GetNotificationPreference", mock.Anything, callerID).Return(defaults(), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "not a therapist",
+			mockSetup: func(m *mocks.MockNotificationRepository) {
+				m.On("GetNotificationPreference", mock.Anything, callerID).Return(nil, errs.NotFound("Therapist", "id", callerID.String()))
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func(m *mocks.MockNotificationRepository) {
+				m.On("GetNotificationPreference", mock.Anything, callerID).Return(nil, errors.New("database connection failed"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockNotificationRepository)
+			tt.mockSetup(repo)
+
+			req := httptest.NewRequest("GET", "/notification-preferences", nil)
+			resp, _ := newApp(notification.NewHandler(repo)).Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			repo.AssertExpectations(t)
+
+			if tt.expectedStatus == fiber.StatusOK {
+				var preference models.NotificationPreference
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&preference))
+				assert.Equal(t, *defaults(), preference)
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateNotificationPreference(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.MockNotificationRepository)
+		expectedStatus int
+	}{
+		{
+			name: "email with a later digest",
+			body: `{"channel": "email", "digest_time": "06:30", "reminder_lead_minutes": 30}`,
+			mockSetup: func(m *mocks.MockNotificationRepository) {
+				m.On("UpdateNotificationPreference", mock.Anything, callerID, mock.MatchedBy(func(in *models.UpdateNotificationPreferenceInput) bool {
+					return *in.Channel == models.NotificationEmail && *in.DigestTime == "06:30" && *in.ReminderLeadMinutes == 30 &&
+						in.DigestEnabled == nil && in.ReminderEnabled == nil
+				})).Return(defaults(), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "reminders off",
+			body: `{"reminder_enabled": false}`,
+			mockSetup: func(m *mocks.MockNotificationRepository) {
+				m.On("UpdateNotificationPreference", mock.Anything, callerID, mock.MatchedBy(func(in *models.UpdateNotificationPreferenceInput) bool {
+					return in.ReminderEnabled != nil && !*in.ReminderEnabled
+				})).Return(defaults(), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "unknown channel",
+			body:           `{"channel": "sms"}`,
+			mockSetup:      func(*mocks.MockNotificationRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "invalid digest time",
+			body:           `{"digest_time": "7am"}`,
+			mockSetup:      func(*mocks.MockNotificationRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "lead time over a day",
+			body:           `{"reminder_lead_minutes": 1441}`,
+			mockSetup:      func(*mocks.MockNotificationRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "invalid JSON",
+			body:           `{"channel": `,
+			mockSetup:      func(*mocks.MockNotificationRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "repository error",
+			body: `{"channel": "email"}`,
+			mockSetup: func(m *mocks.MockNotificationRepository) {
+				m.On("UpdateNotificationPreference", mock.Anything, callerID, mock.Anything).Return(nil, errors.New("database connection failed"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockNotificationRepository)
+			tt.mockSetup(repo)
+
+			req := httptest.NewRequest("PATCH", "/notification-preferences", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := newApp(notification.NewHandler(repo)).Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			repo.AssertExpectations(t)
+		})
+	}
+}
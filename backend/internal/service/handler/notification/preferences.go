@@ -0,0 +1,51 @@
+package notification
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/authz"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetNotificationPreference shows how the caller is told about their sessions, the
+// defaults when they never saved preferences
+func (h *Handler) GetNotificationPreference(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	preference, err := h.notificationRepository.GetNotificationPreference(c.Context(), callerID)
+	if err != nil {
+		return notificationError(err, callerID, "Failed to get notification preferences")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(preference)
+}
+
+// UpdateNotificationPreference saves the preferences given, keeping the others. Nothing is
+// sent until the channel is set to email.
+func (h *Handler) UpdateNotificationPreference(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	var input models.UpdateNotificationPreferenceInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse notification preferences")
+	}
+
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	preference, err := h.notificationRepository.UpdateNotificationPreference(c.Context(), callerID, &input)
+	if err != nil {
+		return notificationError(err, callerID, "Failed to update notification preferences")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(preference)
+}
@@ -0,0 +1,38 @@
+package school_calendar
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateCalendarEvent handles POST /districts/:id/calendar. Recurring sessions created
+// afterwards skip the days without classes, existing sessions are left as they are.
+func (h *Handler) CreateCalendarEvent(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	var input models.CreateSchoolCalendarEventInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse calendar event data")
+	}
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	event := input.Event(districtID)
+	if err := checkEvent(&event); err != nil {
+		return err
+	}
+
+	created, err := h.schoolCalendarRepository.CreateCalendarEvent(c.Context(), &event)
+	if err != nil {
+		return calendarError(err, districtID, "Failed to create calendar event")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
@@ -0,0 +1,30 @@
+package school_calendar
+
+import (
+	"specialstandard/internal/errs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// DeleteCalendarEvent handles DELETE /districts/:id/calendar/:eventId. Sessions skipped for
+// the event are not added back.
+func (h *Handler) DeleteCalendarEvent(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	id, err := uuid.Parse(c.Params("eventId"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	if err := h.schoolCalendarRepository.DeleteCalendarEvent(c.Context(), districtID, id); err != nil {
+		return calendarError(err, districtID, "Failed to delete calendar event")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Calendar event deleted successfully",
+	})
+}
@@ -0,0 +1,36 @@
+package school_calendar
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetCalendar handles GET /districts/:id/calendar, listing the district's holidays, breaks,
+// closures and early releases
+func (h *Handler) GetCalendar(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	var query models.GetSchoolCalendarQuery
+	if err := c.QueryParser(&query); err != nil {
+		return errs.BadRequest("Invalid query parameters")
+	}
+	if validationErrors := h.validator.Validate(query); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+	if query.From != nil && query.To != nil && query.To.Before(*query.From) {
+		return errs.BadRequest("to cannot be before from")
+	}
+
+	events, err := h.schoolCalendarRepository.GetCalendarEvents(c.Context(), districtID, &query)
+	if err != nil {
+		return calendarError(err, districtID, "Failed to retrieve calendar")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(events)
+}
@@ -0,0 +1,43 @@
+package school_calendar
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+)
+
+type Handler struct {
+	schoolCalendarRepository storage.SchoolCalendarRepository
+	validator                *xvalidator.XValidator
+}
+
+func NewHandler(schoolCalendarRepository storage.SchoolCalendarRepository) *Handler {
+	return &Handler{
+		schoolCalendarRepository: schoolCalendarRepository,
+		validator:                xvalidator.Validator,
+	}
+}
+
+// checkEvent catches what validation of a single field cannot
+func checkEvent(e *models.SchoolCalendarEvent) error {
+	if e.EndDate.Before(e.StartDate) {
+		return errs.BadRequest("end_date cannot be before start_date")
+	}
+	if e.DismissalTime != nil && e.Kind != models.CalendarEarlyRelease {
+		return errs.BadRequest("Only early release days have a dismissal time")
+	}
+	return nil
+}
+
+func calendarError(err error, districtID int, message string) error {
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	slog.Error(message, "district_id", districtID, "err", err)
+	return errs.InternalServerError(message)
+}
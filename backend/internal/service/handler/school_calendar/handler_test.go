@@ -0,0 +1,328 @@
+package school_calendar_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/school_calendar"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func ptrString(s string) *string {
+	return &s
+}
+
+func ptrInt(i int) *int {
+	return &i
+}
+
+func newApp(m *mocks.MockSchoolCalendarRepository) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	h := school_calendar.NewHandler(m)
+	app.Get("/districts/:id/calendar", h.GetCalendar)
+	app.Post("/districts/:id/calendar", h.CreateCalendarEvent)
+	app.Patch("/districts/:id/calendar/:eventId", h.UpdateCalendarEvent)
+	app.Delete("/districts/:id/calendar/:eventId", h.DeleteCalendarEvent)
+	return app
+}
+
+func TestHandler_GetCalendar(t *testing.T) {
+	winterBreak := models.SchoolCalendarEvent{
+		ID:         uuid.New(),
+		DistrictID: 3,
+		Name:       "Winter break",
+		Kind:       models.CalendarBreak,
+		StartDate:  time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name               string
+		url                string
+		mockSetup          func(*mocks.MockSchoolCalendarRepository)
+		expectedStatusCode int
+		expectedCount      int
+	}{
+		{
+			name: "Whole district",
+			url:  "/districts/3/calendar",
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("GetCalendarEvents", mock.Anything, 3, &models.GetSchoolCalendarQuery{}).
+					Return([]models.SchoolCalendarEvent{winterBreak}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedCount:      1,
+		},
+		{
+			name: "One school over a date range",
+			url:  "/districts/3/calendar?school_id=4&from=2025-12-01T00:00:00Z&to=2026-01-31T00:00:00Z",
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("GetCalendarEvents", mock.Anything, 3, mock.MatchedBy(func(q *models.GetSchoolCalendarQuery) bool {
+					return q.SchoolID != nil && *q.SchoolID == 4 && q.From != nil && q.To != nil
+				})).Return([]models.SchoolCalendarEvent{winterBreak}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedCount:      1,
+		},
+		{
+			name:               "Range ends before it starts",
+			url:                "/districts/3/calendar?from=2026-01-31T00:00:00Z&to=2025-12-01T00:00:00Z",
+			mockSetup:          func(m *mocks.MockSchoolCalendarRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Invalid district",
+			url:                "/districts/abc/calendar",
+			mockSetup:          func(m *mocks.MockSchoolCalendarRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Repository error",
+			url:  "/districts/3/calendar",
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("GetCalendarEvents", mock.Anything, 3, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSchoolCalendarRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+
+			if tt.expectedCount > 0 {
+				var events []models.SchoolCalendarEvent
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&events))
+				assert.Len(t, events, tt.expectedCount)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_CreateCalendarEvent(t *testing.T) {
+	tests := []struct {
+		name               string
+		payload            string
+		mockSetup          func(*mocks.MockSchoolCalendarRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:    "Single day holiday",
+			payload: `{"name": "Thanksgiving", "kind": "holiday", "start_date": "2025-11-27T00:00:00Z"}`,
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				day := time.Date(2025, 11, 27, 0, 0, 0, 0, time.UTC)
+				m.On("CreateCalendarEvent", mock.Anything, &models.SchoolCalendarEvent{
+					DistrictID: 3,
+					Name:       "Thanksgiving",
+					Kind:       models.CalendarHoliday,
+					StartDate:  day,
+					EndDate:    day,
+				}).Return(&models.SchoolCalendarEvent{ID: uuid.New(), DistrictID: 3, Name: "Thanksgiving"}, nil)
+			},
+			expectedStatusCode: fiber.StatusCreated,
+		},
+		{
+			name:    "Early release at one school",
+			payload: `{"school_id": 4, "name": "Conferences", "kind": "early_release", "start_date": "2025-11-20T00:00:00Z", "dismissal_time": "12:30"}`,
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("CreateCalendarEvent", mock.Anything, mock.MatchedBy(func(e *models.SchoolCalendarEvent) bool {
+					return *e.SchoolID == 4 && *e.DismissalTime == "12:30"
+				})).Return(&models.SchoolCalendarEvent{ID: uuid.New(), DistrictID: 3, SchoolID: ptrInt(4)}, nil)
+			},
+			expectedStatusCode: fiber.StatusCreated,
+		},
+		{
+			name:               "Unknown kind",
+			payload:            `{"name": "Field day", "kind": "party", "start_date": "2025-11-27T00:00:00Z"}`,
+			mockSetup:          func(m *mocks.MockSchoolCalendarRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Ends before it starts",
+			payload:            `{"name": "Winter break", "kind": "break", "start_date": "2025-12-22T00:00:00Z", "end_date": "2025-12-01T00:00:00Z"}`,
+			mockSetup:          func(m *mocks.MockSchoolCalendarRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Dismissal time on a holiday",
+			payload:            `{"name": "Thanksgiving", "kind": "holiday", "start_date": "2025-11-27T00:00:00Z", "dismissal_time": "12:30"}`,
+			mockSetup:          func(m *mocks.MockSchoolCalendarRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Malformed dismissal time",
+			payload:            `{"name": "Conferences", "kind": "early_release", "start_date": "2025-11-20T00:00:00Z", "dismissal_time": "noon"}`,
+			mockSetup:          func(m *mocks.MockSchoolCalendarRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "School outside the district",
+			payload: `{"school_id": 99, "name": "Snow day", "kind": "closure", "start_date": "2026-01-15T00:00:00Z"}`,
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("CreateCalendarEvent", mock.Anything, mock.Anything).
+					Return(nil, errs.BadRequest("The school must belong to the district"))
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Repository error",
+			payload: `{"name": "Snow day", "kind": "closure", "start_date": "2026-01-15T00:00:00Z"}`,
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("CreateCalendarEvent", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSchoolCalendarRepository)
+			tt.mockSetup(mockRepo)
+
+			req := httptest.NewRequest("POST", "/districts/3/calendar", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := newApp(mockRepo).Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_UpdateCalendarEvent(t *testing.T) {
+	id := uuid.New()
+	existing := func() *models.SchoolCalendarEvent {
+		return &models.SchoolCalendarEvent{
+			ID:            id,
+			DistrictID:    3,
+			SchoolID:      ptrInt(4),
+			Name:          "Conferences",
+			Kind:          models.CalendarEarlyRelease,
+			StartDate:     time.Date(2025, 11, 20, 0, 0, 0, 0, time.UTC),
+			EndDate:       time.Date(2025, 11, 20, 0, 0, 0, 0, time.UTC),
+			DismissalTime: ptrString("12:30"),
+		}
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		mockSetup          func(*mocks.MockSchoolCalendarRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:    "Made a district wide closure",
+			payload: `{"school_id": 0, "kind": "closure"}`,
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("GetCalendarEvent", mock.Anything, 3, id).Return(existing(), nil)
+				m.On("UpdateCalendarEvent", mock.Anything, mock.MatchedBy(func(e *models.SchoolCalendarEvent) bool {
+					// The dismissal time goes with the early release
+					return e.SchoolID == nil && e.Kind == models.CalendarClosure && e.DismissalTime == nil && e.Name == "Conferences"
+				})).Return(existing(), nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:    "Moved to end before it starts",
+			payload: `{"end_date": "2025-11-19T00:00:00Z"}`,
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("GetCalendarEvent", mock.Anything, 3, id).Return(existing(), nil)
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Not in the district",
+			payload: `{"name": "Parent conferences"}`,
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("GetCalendarEvent", mock.Anything, 3, id).Return(nil, errs.NotFound("Calendar event", "id", id.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:               "Invalid JSON",
+			payload:            `{"name": `,
+			mockSetup:          func(m *mocks.MockSchoolCalendarRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSchoolCalendarRepository)
+			tt.mockSetup(mockRepo)
+
+			req := httptest.NewRequest("PATCH", "/districts/3/calendar/"+id.String(), strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := newApp(mockRepo).Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_DeleteCalendarEvent(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name               string
+		url                string
+		mockSetup          func(*mocks.MockSchoolCalendarRepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "Deleted",
+			url:  "/districts/3/calendar/" + id.String(),
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("DeleteCalendarEvent", mock.Anything, 3, id).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name: "Not found",
+			url:  "/districts/3/calendar/" + id.String(),
+			mockSetup: func(m *mocks.MockSchoolCalendarRepository) {
+				m.On("DeleteCalendarEvent", mock.Anything, 3, id).Return(errs.NotFound("Calendar event", "id", id.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:               "Invalid ID",
+			url:                "/districts/3/calendar/not-a-uuid",
+			mockSetup:          func(m *mocks.MockSchoolCalendarRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSchoolCalendarRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("DELETE", tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
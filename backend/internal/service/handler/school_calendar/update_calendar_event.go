@@ -0,0 +1,48 @@
+package school_calendar
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UpdateCalendarEvent handles PATCH /districts/:id/calendar/:eventId
+func (h *Handler) UpdateCalendarEvent(c *fiber.Ctx) error {
+	districtID, err := c.ParamsInt("id")
+	if err != nil || districtID < 1 {
+		return errs.BadRequest("Invalid district ID")
+	}
+
+	id, err := uuid.Parse(c.Params("eventId"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	var input models.UpdateSchoolCalendarEventInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse calendar event data")
+	}
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	event, err := h.schoolCalendarRepository.GetCalendarEvent(c.Context(), districtID, id)
+	if err != nil {
+		return calendarError(err, districtID, "Failed to update calendar event")
+	}
+
+	input.Apply(event)
+	if err := checkEvent(event); err != nil {
+		return err
+	}
+
+	updated, err := h.schoolCalendarRepository.UpdateCalendarEvent(c.Context(), event)
+	if err != nil {
+		return calendarError(err, districtID, "Failed to update calendar event")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(updated)
+}
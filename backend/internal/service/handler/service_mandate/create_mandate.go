@@ -0,0 +1,37 @@
+package service_mandate
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateMandate handles POST /students/:id/mandates
+func (h *Handler) CreateMandate(c *fiber.Ctx) error {
+	studentID, err := studentParam(c)
+	if err != nil {
+		return err
+	}
+
+	var input models.CreateServiceMandateInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse service mandate data")
+	}
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	mandate := input.Mandate(studentID)
+	if err := checkMandate(&mandate); err != nil {
+		return err
+	}
+
+	created, err := h.serviceMandateRepository.CreateMandate(c.Context(), &mandate)
+	if err != nil {
+		return mandateError(err, studentID, "Failed to create service mandate")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
@@ -0,0 +1,29 @@
+package service_mandate
+
+import (
+	"specialstandard/internal/errs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// DeleteMandate handles DELETE /students/:id/mandates/:mandateId
+func (h *Handler) DeleteMandate(c *fiber.Ctx) error {
+	studentID, err := studentParam(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("mandateId"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	if err := h.serviceMandateRepository.DeleteMandate(c.Context(), studentID, id); err != nil {
+		return mandateError(err, studentID, "Failed to delete service mandate")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Service mandate deleted successfully",
+	})
+}
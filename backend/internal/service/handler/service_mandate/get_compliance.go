@@ -0,0 +1,81 @@
+package service_mandate
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetStudentCompliance handles GET /students/:id/compliance, comparing the minutes the
+// student's mandates call for with those the student attended
+func (h *Handler) GetStudentCompliance(c *fiber.Ctx) error {
+	studentID, err := studentParam(c)
+	if err != nil {
+		return err
+	}
+
+	var query models.GetComplianceQuery
+	if err := c.QueryParser(&query); err != nil {
+		return errs.BadRequest("Invalid query parameters")
+	}
+	if validationErrors := h.validator.Validate(query); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+	from, to, err := reportRange(&query)
+	if err != nil {
+		return err
+	}
+
+	records, err := h.serviceMandateRepository.GetServiceRecords(c.Context(), &models.ServiceRecordQuery{
+		StudentID: &studentID,
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		return mandateError(err, studentID, "Failed to report service compliance")
+	}
+	if len(records) == 0 {
+		return errs.NotFound("Student", "id", studentID.String())
+	}
+
+	report := models.NewComplianceReport(records, from, to, time.Now())
+	return c.Status(fiber.StatusOK).JSON(report.Students[0])
+}
+
+// GetCompliance handles GET /compliance, reporting on the students of a therapist with
+// a mandate in the range, and the therapist's totals
+func (h *Handler) GetCompliance(c *fiber.Ctx) error {
+	var query models.GetComplianceQuery
+	if err := c.QueryParser(&query); err != nil {
+		return errs.BadRequest("Invalid query parameters")
+	}
+	if validationErrors := h.validator.Validate(query); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+	from, to, err := reportRange(&query)
+	if err != nil {
+		return err
+	}
+
+	recordQuery := &models.ServiceRecordQuery{From: from, To: to}
+	if query.TherapistID != "" {
+		therapistID, err := uuid.Parse(query.TherapistID)
+		if err != nil {
+			return errs.BadRequest("Invalid therapist ID format")
+		}
+		recordQuery.TherapistID = &therapistID
+	}
+
+	records, err := h.serviceMandateRepository.GetServiceRecords(c.Context(), recordQuery)
+	if err != nil {
+		slog.Error("Failed to report service compliance", "therapist_id", query.TherapistID, "err", err)
+		return errs.InternalServerError("Failed to report service compliance")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.NewComplianceReport(records, from, to, time.Now()))
+}
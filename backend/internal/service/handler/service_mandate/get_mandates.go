@@ -0,0 +1,20 @@
+package service_mandate
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetMandates handles GET /students/:id/mandates
+func (h *Handler) GetMandates(c *fiber.Ctx) error {
+	studentID, err := studentParam(c)
+	if err != nil {
+		return err
+	}
+
+	mandates, err := h.serviceMandateRepository.GetMandates(c.Context(), studentID)
+	if err != nil {
+		return mandateError(err, studentID, "Failed to retrieve service mandates")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(mandates)
+}
@@ -0,0 +1,83 @@
+package service_mandate
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// maxComplianceRange keeps a compliance report to a school year
+const maxComplianceRange = 366
+
+type Handler struct {
+	serviceMandateRepository storage.ServiceMandateRepository
+	validator                *xvalidator.XValidator
+}
+
+func NewHandler(serviceMandateRepository storage.ServiceMandateRepository) *Handler {
+	return &Handler{
+		serviceMandateRepository: serviceMandateRepository,
+		validator:                xvalidator.Validator,
+	}
+}
+
+func studentParam(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, errs.BadRequest("Invalid UUID format for student ID")
+	}
+	return id, nil
+}
+
+// checkMandate catches what validation of a single field cannot
+func checkMandate(m *models.ServiceMandate) error {
+	if m.EndDate.Before(m.StartDate) {
+		return errs.BadRequest("end_date cannot be before start_date")
+	}
+	return nil
+}
+
+// reportRange is the range of dates a compliance report covers, the current month up to
+// today unless the query says otherwise
+func reportRange(query *models.GetComplianceQuery) (time.Time, time.Time, error) {
+	y, m, d := time.Now().Date()
+	from := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if query.From != "" {
+		if from, err = time.Parse(time.DateOnly, query.From); err != nil {
+			return from, to, errs.BadRequest("Invalid from date")
+		}
+	}
+	if query.To != "" {
+		if to, err = time.Parse(time.DateOnly, query.To); err != nil {
+			return from, to, errs.BadRequest("Invalid to date")
+		}
+	}
+
+	if to.Before(from) {
+		return from, to, errs.BadRequest("to cannot be before from")
+	}
+	if to.After(from.AddDate(0, 0, maxComplianceRange)) {
+		return from, to, errs.BadRequest("Compliance can be reported for at most a year at a time")
+	}
+	return from, to, nil
+}
+
+func mandateError(err error, studentID uuid.UUID, message string) error {
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	slog.Error(message, "student_id", studentID, "err", err)
+	return errs.InternalServerError(message)
+}
@@ -0,0 +1,453 @@
+package service_mandate_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/service_mandate"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newApp(m *mocks.MockServiceMandateRepository) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	h := service_mandate.NewHandler(m)
+	app.Get("/students/:id/mandates", h.GetMandates)
+	app.Post("/students/:id/mandates", h.CreateMandate)
+	app.Patch("/students/:id/mandates/:mandateId", h.UpdateMandate)
+	app.Delete("/students/:id/mandates/:mandateId", h.DeleteMandate)
+	app.Get("/students/:id/compliance", h.GetStudentCompliance)
+	app.Get("/compliance", h.GetCompliance)
+	return app
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestHandler_GetMandates(t *testing.T) {
+	studentID := uuid.New()
+
+	tests := []struct {
+		name               string
+		url                string
+		mockSetup          func(*mocks.MockServiceMandateRepository)
+		expectedStatusCode int
+		expectedCount      int
+	}{
+		{
+			name: "Listed",
+			url:  "/students/" + studentID.String() + "/mandates",
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("GetMandates", mock.Anything, studentID).Return([]models.ServiceMandate{
+					{ID: uuid.New(), StudentID: studentID, Service: "Speech", Minutes: 60, Frequency: models.MandateWeekly},
+				}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedCount:      1,
+		},
+		{
+			name:               "Invalid student ID",
+			url:                "/students/abc/mandates",
+			mockSetup:          func(m *mocks.MockServiceMandateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Repository error",
+			url:  "/students/" + studentID.String() + "/mandates",
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("GetMandates", mock.Anything, studentID).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockServiceMandateRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+
+			if tt.expectedCount > 0 {
+				var mandates []models.ServiceMandate
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&mandates))
+				assert.Len(t, mandates, tt.expectedCount)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_CreateMandate(t *testing.T) {
+	studentID := uuid.New()
+
+	tests := []struct {
+		name               string
+		payload            string
+		mockSetup          func(*mocks.MockServiceMandateRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:    "Weekly individual minutes",
+			payload: `{"service": "Speech-language therapy", "minutes": 60, "frequency": "weekly", "setting": "individual", "start_date": "2025-09-01T00:00:00Z", "end_date": "2026-06-30T00:00:00Z"}`,
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("CreateMandate", mock.Anything, &models.ServiceMandate{
+					StudentID: studentID,
+					Service:   "Speech-language therapy",
+					Minutes:   60,
+					Frequency: models.MandateWeekly,
+					Setting:   models.MandateIndividual,
+					StartDate: date(2025, 9, 1),
+					EndDate:   date(2026, 6, 30),
+				}).Return(&models.ServiceMandate{ID: uuid.New(), StudentID: studentID}, nil)
+			},
+			expectedStatusCode: fiber.StatusCreated,
+		},
+		{
+			name:               "Unknown frequency",
+			payload:            `{"service": "Speech", "minutes": 60, "frequency": "daily", "setting": "individual", "start_date": "2025-09-01T00:00:00Z", "end_date": "2026-06-30T00:00:00Z"}`,
+			mockSetup:          func(m *mocks.MockServiceMandateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "No minutes",
+			payload:            `{"service": "Speech", "minutes": 0, "frequency": "weekly", "setting": "group", "start_date": "2025-09-01T00:00:00Z", "end_date": "2026-06-30T00:00:00Z"}`,
+			mockSetup:          func(m *mocks.MockServiceMandateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Ends before it starts",
+			payload:            `{"service": "Speech", "minutes": 60, "frequency": "weekly", "setting": "group", "start_date": "2025-09-01T00:00:00Z", "end_date": "2025-08-01T00:00:00Z"}`,
+			mockSetup:          func(m *mocks.MockServiceMandateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Unknown student",
+			payload: `{"service": "Speech", "minutes": 60, "frequency": "monthly", "setting": "group", "start_date": "2025-09-01T00:00:00Z", "end_date": "2026-06-30T00:00:00Z"}`,
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("CreateMandate", mock.Anything, mock.Anything).Return(nil, errs.NotFound("Student", "id", studentID.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:    "Repository error",
+			payload: `{"service": "Speech", "minutes": 60, "frequency": "monthly", "setting": "group", "start_date": "2025-09-01T00:00:00Z", "end_date": "2026-06-30T00:00:00Z"}`,
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("CreateMandate", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockServiceMandateRepository)
+			tt.mockSetup(mockRepo)
+
+			req := httptest.NewRequest("POST", "/students/"+studentID.String()+"/mandates", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := newApp(mockRepo).Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_UpdateMandate(t *testing.T) {
+	studentID, id := uuid.New(), uuid.New()
+	existing := func() *models.ServiceMandate {
+		return &models.ServiceMandate{
+			ID:        id,
+			StudentID: studentID,
+			Service:   "Speech-language therapy",
+			Minutes:   60,
+			Frequency: models.MandateWeekly,
+			Setting:   models.MandateIndividual,
+			StartDate: date(2025, 9, 1),
+			EndDate:   date(2026, 6, 30),
+		}
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		mockSetup          func(*mocks.MockServiceMandateRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:    "Changed to group minutes",
+			payload: `{"minutes": 90, "setting": "group"}`,
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("GetMandate", mock.Anything, studentID, id).Return(existing(), nil)
+				m.On("UpdateMandate", mock.Anything, mock.MatchedBy(func(md *models.ServiceMandate) bool {
+					return md.Minutes == 90 && md.Setting == models.MandateGroup && md.Frequency == models.MandateWeekly
+				})).Return(existing(), nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:    "Moved to end before it starts",
+			payload: `{"end_date": "2025-08-01T00:00:00Z"}`,
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("GetMandate", mock.Anything, studentID, id).Return(existing(), nil)
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Not the student's",
+			payload: `{"minutes": 90}`,
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("GetMandate", mock.Anything, studentID, id).Return(nil, errs.NotFound("Service mandate", "id", id.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:               "Invalid JSON",
+			payload:            `{"minutes": `,
+			mockSetup:          func(m *mocks.MockServiceMandateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockServiceMandateRepository)
+			tt.mockSetup(mockRepo)
+
+			req := httptest.NewRequest("PATCH", "/students/"+studentID.String()+"/mandates/"+id.String(), strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := newApp(mockRepo).Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_DeleteMandate(t *testing.T) {
+	studentID, id := uuid.New(), uuid.New()
+	url := "/students/" + studentID.String() + "/mandates/"
+
+	tests := []struct {
+		name               string
+		url                string
+		mockSetup          func(*mocks.MockServiceMandateRepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "Deleted",
+			url:  url + id.String(),
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("DeleteMandate", mock.Anything, studentID, id).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name: "Not found",
+			url:  url + id.String(),
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("DeleteMandate", mock.Anything, studentID, id).Return(errs.NotFound("Service mandate", "id", id.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:               "Invalid ID",
+			url:                url + "not-a-uuid",
+			mockSetup:          func(m *mocks.MockServiceMandateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockServiceMandateRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("DELETE", tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// serviceRecord is a student mandated 60 individual minutes a week from September 2025
+// and 120 group minutes a month from the 15th, whose therapist works in New York
+func serviceRecord(studentID, therapistID uuid.UUID) models.ServiceRecord {
+	ny, _ := time.LoadLocation("America/New_York")
+	session := func(day, hour, minutes, groupSize int) models.ServiceDelivery {
+		start := time.Date(2025, 9, day, hour, 0, 0, 0, ny)
+		return models.ServiceDelivery{
+			StudentID:     studentID,
+			SessionID:     uuid.New(),
+			StartDateTime: start.UTC(),
+			EndDateTime:   start.Add(time.Duration(minutes) * time.Minute).UTC(),
+			GroupSize:     groupSize,
+		}
+	}
+
+	return models.ServiceRecord{
+		StudentID:   studentID,
+		FirstName:   "Ada",
+		LastName:    "Lovelace",
+		TherapistID: therapistID,
+		TimeZone:    "America/New_York",
+		Mandates: []models.ServiceMandate{
+			{StudentID: studentID, Service: "Speech", Minutes: 60, Frequency: models.MandateWeekly, Setting: models.MandateIndividual, StartDate: date(2025, 9, 1), EndDate: date(2026, 6, 30)},
+			{StudentID: studentID, Service: "Social skills", Minutes: 120, Frequency: models.MandateMonthly, Setting: models.MandateGroup, StartDate: date(2025, 9, 15), EndDate: date(2026, 6, 30)},
+		},
+		Deliveries: []models.ServiceDelivery{
+			// Still August 31st in New York, before the report
+			{StudentID: studentID, SessionID: uuid.New(), StartDateTime: time.Date(2025, 9, 1, 2, 0, 0, 0, time.UTC), EndDateTime: time.Date(2025, 9, 1, 3, 0, 0, 0, time.UTC), GroupSize: 1},
+			session(2, 9, 30, 1),
+			session(4, 9, 30, 1),
+			session(9, 9, 60, 1),
+			// Nothing the week of the 14th
+			session(23, 9, 30, 1),
+			session(24, 13, 45, 3),
+		},
+	}
+}
+
+func TestHandler_GetStudentCompliance(t *testing.T) {
+	studentID, therapistID := uuid.New(), uuid.New()
+	url := "/students/" + studentID.String() + "/compliance"
+
+	tests := []struct {
+		name               string
+		url                string
+		mockSetup          func(*mocks.MockServiceMandateRepository)
+		expectedStatusCode int
+		expected           *models.StudentCompliance
+	}{
+		{
+			name: "September",
+			url:  url + "?from=2025-09-01&to=2025-09-30",
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("GetServiceRecords", mock.Anything, &models.ServiceRecordQuery{
+					StudentID: &studentID,
+					From:      date(2025, 9, 1),
+					To:        date(2025, 9, 30),
+				}).Return([]models.ServiceRecord{serviceRecord(studentID, therapistID)}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expected: &models.StudentCompliance{
+				StudentID:   studentID,
+				TherapistID: therapistID,
+				// Weekly: 51 + 60 + 60 + 60 + 26 owed for the partial weeks either side,
+				// short 60, 30 and 26 the last three weeks. Monthly: 16 of 30 days owed.
+				ServiceTotals: models.ServiceTotals{OwedMinutes: 257 + 64, DeliveredMinutes: 150 + 45, MakeupMinutes: 116 + 19},
+			},
+		},
+		{
+			name:               "Range ends before it starts",
+			url:                url + "?from=2025-09-30&to=2025-09-01",
+			mockSetup:          func(m *mocks.MockServiceMandateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Range over a year",
+			url:                url + "?from=2024-09-01&to=2025-09-30",
+			mockSetup:          func(m *mocks.MockServiceMandateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Malformed date",
+			url:                url + "?from=09/01/2025",
+			mockSetup:          func(m *mocks.MockServiceMandateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Unknown student",
+			url:  url,
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("GetServiceRecords", mock.Anything, mock.Anything).Return([]models.ServiceRecord{}, nil)
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name: "Repository error",
+			url:  url,
+			mockSetup: func(m *mocks.MockServiceMandateRepository) {
+				m.On("GetServiceRecords", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockServiceMandateRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+
+			if tt.expected != nil {
+				var student models.StudentCompliance
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&student))
+				assert.Equal(t, tt.expected.StudentID, student.StudentID)
+				assert.Equal(t, tt.expected.ServiceTotals, student.ServiceTotals)
+				assert.Len(t, student.Mandates, 2)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetCompliance(t *testing.T) {
+	therapistID := uuid.New()
+	other := uuid.New()
+	records := []models.ServiceRecord{
+		serviceRecord(uuid.New(), therapistID),
+		{
+			StudentID:   other,
+			TherapistID: therapistID,
+			TimeZone:    "UTC",
+			Mandates: []models.ServiceMandate{
+				{StudentID: other, Service: "Occupational therapy", Minutes: 100, Frequency: models.MandateMonthly, Setting: models.MandateIndividual, StartDate: date(2025, 9, 1), EndDate: date(2025, 9, 30)},
+			},
+		},
+	}
+
+	mockRepo := new(mocks.MockServiceMandateRepository)
+	mockRepo.On("GetServiceRecords", mock.Anything, mock.MatchedBy(func(q *models.ServiceRecordQuery) bool {
+		return q.StudentID == nil && *q.TherapistID == therapistID && q.From.Equal(date(2025, 9, 1))
+	})).Return(records, nil)
+
+	resp, err := newApp(mockRepo).Test(httptest.NewRequest("GET", "/compliance?therapist_id="+therapistID.String()+"&from=2025-09-01&to=2025-09-30", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var report models.ComplianceReport
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Len(t, report.Students, 2)
+	assert.Equal(t, []models.TherapistCompliance{{
+		TherapistID:   therapistID,
+		StudentCount:  2,
+		ServiceTotals: models.ServiceTotals{OwedMinutes: 421, DeliveredMinutes: 195, MakeupMinutes: 235},
+	}}, report.Therapists)
+	mockRepo.AssertExpectations(t)
+
+	resp, err = newApp(new(mocks.MockServiceMandateRepository)).Test(httptest.NewRequest("GET", "/compliance?therapist_id=abc", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
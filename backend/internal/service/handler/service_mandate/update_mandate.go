@@ -0,0 +1,48 @@
+package service_mandate
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UpdateMandate handles PATCH /students/:id/mandates/:mandateId
+func (h *Handler) UpdateMandate(c *fiber.Ctx) error {
+	studentID, err := studentParam(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("mandateId"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	var input models.UpdateServiceMandateInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse service mandate data")
+	}
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	mandate, err := h.serviceMandateRepository.GetMandate(c.Context(), studentID, id)
+	if err != nil {
+		return mandateError(err, studentID, "Failed to update service mandate")
+	}
+
+	input.Apply(mandate)
+	if err := checkMandate(mandate); err != nil {
+		return err
+	}
+
+	updated, err := h.serviceMandateRepository.UpdateMandate(c.Context(), mandate)
+	if err != nil {
+		return mandateError(err, studentID, "Failed to update service mandate")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(updated)
+}
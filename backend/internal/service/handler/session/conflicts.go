@@ -0,0 +1,162 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// allowConflictsQuery lets a create or update double-book on purpose
+	allowConflictsQuery = "allow_conflicts"
+	// defaultConflictWindow is reported on when no end date is given
+	defaultConflictWindow = 4 * 7 * 24 * time.Hour
+	// maxConflictWindow keeps the report to a school year
+	maxConflictWindow = 366 * 24 * time.Hour
+)
+
+// GetConflicts reports the pairs of sessions in a date range that double-book the
+// therapist or one of the therapist's students
+func (h *Handler) GetConflicts(c *fiber.Ctx) error {
+	var query models.GetSessionConflictsQuery
+	if err := c.QueryParser(&query); err != nil {
+		return errs.BadRequest("Invalid query parameters")
+	}
+	if validationErrors := h.validator.Validate(query); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	therapistID, err := uuid.Parse(query.TherapistID)
+	if err != nil {
+		return errs.BadRequest("Invalid therapist ID format")
+	}
+
+	from := time.Now()
+	if query.StartTime != nil {
+		from = *query.StartTime
+	}
+	to := from.Add(defaultConflictWindow)
+	if query.EndTime != nil {
+		to = *query.EndTime
+	}
+	if !to.After(from) {
+		return errs.BadRequest("enddate must be after startdate")
+	}
+	if to.Sub(from) > maxConflictWindow {
+		return errs.BadRequest("Conflicts can be reported for at most a year at a time")
+	}
+
+	overlaps, err := h.sessionRepository.GetConflictReport(c.Context(), therapistID, from, to)
+	if err != nil {
+		slog.Error("Failed to get session conflicts", "therapist_id", therapistID, "err", err)
+		return errs.InternalServerError("Failed to get session conflicts")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(overlaps)
+}
+
+// checkConflicts fails with a 409 listing the sessions the check would double-book
+func (h *Handler) checkConflicts(ctx context.Context, check *models.ConflictCheck) error {
+	conflicts, err := h.sessionRepository.FindConflicts(ctx, check)
+	if err != nil {
+		slog.Error("Failed to check for conflicting sessions", "therapist_id", check.TherapistID, "err", err)
+		return errs.InternalServerError("Failed to check for conflicting sessions")
+	}
+	if len(conflicts) > 0 {
+		return schedulingConflict(conflicts)
+	}
+	return nil
+}
+
+// checkPatchConflicts checks the times a patch moves a session, or the rest of its
+// series, to
+func (h *Handler) checkPatchConflicts(ctx context.Context, id uuid.UUID, scope string, input *models.PatchSessionInput) error {
+	current, err := h.sessionRepository.GetSessionByID(ctx, id.String())
+	if err != nil {
+		return patchSessionError(id, err)
+	}
+
+	start, end, therapistID := current.StartDateTime, current.EndDateTime, current.TherapistID
+	if input.StartTime != nil {
+		start = *input.StartTime
+	}
+	if input.EndTime != nil {
+		end = *input.EndTime
+	}
+	if input.TherapistID != nil {
+		therapistID = *input.TherapistID
+	}
+
+	check := &models.ConflictCheck{
+		TherapistID: therapistID,
+		SessionIDs:  []uuid.UUID{id},
+		Slots:       []models.TimeSlot{{Start: start, End: end}},
+	}
+
+	rp := input.Repetition
+	if rp == nil {
+		rp = current.Repetition
+	}
+	if isSeriesScope(scope) && rp != nil {
+		loc, err := h.therapistLocation(ctx, current.TherapistID)
+		if err != nil {
+			return err
+		}
+		start, end = start.In(loc), end.In(loc)
+
+		// The occurrences PatchRecurringSessions regenerates, from the edited one or,
+		// for the whole series, its start, but never the past
+		pivot := current.StartDateTime
+		if scope == models.SessionScopeAll && current.Repetition != nil {
+			pivot = current.Repetition.RecurStart
+		}
+		if now := time.Now(); pivot.Before(now) {
+			pivot = now
+		}
+
+		check.Slots = nil
+		for _, occ := range rp.Occurrences(start, end, pivot) {
+			check.Slots = append(check.Slots, models.TimeSlot{Start: occ[0], End: occ[1]})
+		}
+		check.SeriesID = &current.SessionParentID
+	}
+
+	return h.checkConflicts(ctx, check)
+}
+
+// therapistLocation is the time zone the therapist's sessions are scheduled in
+func (h *Handler) therapistLocation(ctx context.Context, therapistID uuid.UUID) (*time.Location, error) {
+	loc, err := h.sessionRepository.GetTherapistLocation(ctx, therapistID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.BadRequest("Invalid Reference")
+	}
+	if err != nil {
+		slog.Error("Failed to get therapist time zone", "therapist_id", therapistID, "err", err)
+		return nil, errs.InternalServerError("Failed to get therapist time zone")
+	}
+	return loc, nil
+}
+
+// reschedules reports whether a patch can create a conflict
+func reschedules(input *models.PatchSessionInput) bool {
+	return input.StartTime != nil || input.EndTime != nil || input.TherapistID != nil || input.Repetition != nil
+}
+
+func isSeriesScope(scope string) bool {
+	return scope == models.SessionScopeFollowing || scope == models.SessionScopeAll
+}
+
+func schedulingConflict(conflicts []models.SessionConflict) error {
+	return errs.ConflictDetails(models.SchedulingConflicts{
+		Error:     "The session overlaps other sessions of the therapist or its students",
+		Conflicts: conflicts,
+	})
+}
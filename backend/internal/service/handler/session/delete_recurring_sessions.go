@@ -1,6 +1,7 @@
 package session
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"specialstandard/internal/errs"
@@ -17,6 +18,10 @@ func (h *Handler) DeleteRecurringSessions(c *fiber.Ctx) error {
 
 	err = h.sessionRepository.DeleteRecurringSessions(c.Context(), id)
 	if err != nil {
+		var httpErr errs.HTTPError
+		if errors.As(err, &httpErr) {
+			return httpErr
+		}
 		slog.Error("Failed to delete sessions", "id", id, "err", err)
 		return errs.InternalServerError("Internal Server Error")
 	}
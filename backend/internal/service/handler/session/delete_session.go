@@ -1,6 +1,7 @@
 package session
 
 import (
+	"errors"
 	"log/slog"
 	"specialstandard/internal/errs"
 
@@ -16,6 +17,10 @@ func (h *Handler) DeleteSessions(c *fiber.Ctx) error {
 
 	err = h.sessionRepository.DeleteSession(c.Context(), id)
 	if err != nil {
+		var httpErr errs.HTTPError
+		if errors.As(err, &httpErr) {
+			return httpErr
+		}
 		slog.Error("Failed to delete session", "id", id, "err", err)
 		return errs.InternalServerError("Internal Server Error")
 	}
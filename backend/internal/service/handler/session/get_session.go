@@ -58,12 +58,13 @@ func (h *Handler) GetSessions(c *fiber.Ctx) error {
 	// Only create repoFilter if there are actual filters to apply
 	var repoFilter *models.GetSessionRepositoryRequest
 	if filter.StartTime != nil || filter.EndTime != nil || filter.Month != nil || 
-	   filter.Year != nil || len(uuidStudentIDs) > 0 {
+	   filter.Year != nil || len(uuidStudentIDs) > 0 || len(filter.Statuses) > 0 {
 		repoFilter = &models.GetSessionRepositoryRequest{
 			StartTime:  filter.StartTime,
 			EndTime:    filter.EndTime,
 			Month:      filter.Month,
 			Year:       filter.Year,
+			Statuses:   filter.Statuses,
 		}
 		if len(uuidStudentIDs) > 0 {
 			repoFilter.StudentIDs = &uuidStudentIDs
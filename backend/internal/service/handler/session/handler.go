@@ -6,15 +6,19 @@ import (
 )
 
 type Handler struct {
-	sessionRepository        storage.SessionRepository
-	sessionStudentRepository storage.SessionStudentRepository
-	validator                *xvalidator.XValidator
+	sessionRepository         storage.SessionRepository
+	sessionStudentRepository  storage.SessionStudentRepository
+	sessionResourceRepository storage.SessionResourceRepository
+	sessionTemplateRepository storage.SessionTemplateRepository
+	validator                 *xvalidator.XValidator
 }
 
-func NewHandler(sessionRepository storage.SessionRepository, sessionStudentRepository storage.SessionStudentRepository) *Handler {
+func NewHandler(sessionRepository storage.SessionRepository, sessionStudentRepository storage.SessionStudentRepository, sessionResourceRepository storage.SessionResourceRepository, sessionTemplateRepository storage.SessionTemplateRepository) *Handler {
 	return &Handler{
-		sessionRepository:        sessionRepository,
-		sessionStudentRepository: sessionStudentRepository,
-		validator:                xvalidator.Validator,
+		sessionRepository:         sessionRepository,
+		sessionStudentRepository:  sessionStudentRepository,
+		sessionResourceRepository: sessionResourceRepository,
+		sessionTemplateRepository: sessionTemplateRepository,
+		validator:                 xvalidator.Validator,
 	}
 }
@@ -1,6 +1,7 @@
 package session_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -31,6 +32,20 @@ func ptrTime(t time.Time) *time.Time {
 	return &t
 }
 
+// expectNoConflicts lets the conflict check of a create or update pass, unless a test
+// sets up its own
+func expectNoConflicts(m *mocks.MockSessionRepository, id uuid.UUID) {
+	start := time.Now().Add(24 * time.Hour)
+	m.On("GetSessionByID", mock.Anything, id.String()).Return(&models.Session{
+		ID:            id,
+		StartDateTime: start,
+		EndDateTime:   start.Add(time.Hour),
+		TherapistID:   uuid.New(),
+	}, nil).Maybe()
+	m.On("FindConflicts", mock.Anything, mock.Anything).Return([]models.SessionConflict{}, nil).Maybe()
+	m.On("GetTherapistLocation", mock.Anything, mock.Anything).Return(time.UTC, nil).Maybe()
+}
+
 func TestHandler_GetSessions(t *testing.T) {
 	therapistID := uuid.New()
 
@@ -120,6 +135,33 @@ func TestHandler_GetSessions(t *testing.T) {
 			expectedStatus: fiber.StatusOK,
 			wantErr:        false,
 		},
+		{
+			name:        "Filter by status",
+			url:         "?status=cancelled_by_therapist&status=student_absent",
+			therapistID: therapistID,
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessions",
+					mock.Anything,
+					utils.NewPagination(),
+					mock.MatchedBy(func(filter *models.GetSessionRepositoryRequest) bool {
+						return filter != nil && len(filter.Statuses) == 2 &&
+							filter.Statuses[0] == models.SessionCancelledByTherapist &&
+							filter.Statuses[1] == models.SessionStudentAbsent
+					}),
+					therapistID,
+				).Return([]models.Session{}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantErr:        false,
+		},
+		{
+			name:           "Unknown status",
+			url:            "?status=postponed",
+			therapistID:    therapistID,
+			mockSetup:      func(m *mocks.MockSessionRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+			wantErr:        true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,7 +173,7 @@ func TestHandler_GetSessions(t *testing.T) {
 			tt.mockSetup(mockRepo)
 
 			mockRepoSSR := new(mocks.MockSessionStudentRepository)
-			handler := session.NewHandler(mockRepo, mockRepoSSR)
+			handler := session.NewHandler(mockRepo, mockRepoSSR, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
 
 			// Register both possible routes
 			app.Get("/sessions", handler.GetSessions)
@@ -196,6 +238,15 @@ func TestHandler_DeleteSessions(t *testing.T) {
 			expectedStatus: fiber.StatusInternalServerError,
 			wantErr:        true,
 		},
+		{
+			id:   uuid.New(),
+			name: "signed notes",
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				m.On("DeleteSession", mock.Anything, id).Return(errs.Conflict("The session has signed notes and cannot be deleted"))
+			},
+			expectedStatus: fiber.StatusConflict,
+			wantErr:        true,
+		},
 	}
 
 	t.Run("Bad UUID Request", func(t *testing.T) {
@@ -205,7 +256,7 @@ func TestHandler_DeleteSessions(t *testing.T) {
 		mockRepo := new(mocks.MockSessionRepository)
 		mockRepoSSR := new(mocks.MockSessionStudentRepository)
 
-		handler := session.NewHandler(mockRepo, mockRepoSSR)
+		handler := session.NewHandler(mockRepo, mockRepoSSR, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
 		app.Delete("/sessions/:id", handler.DeleteSessions)
 
 		req := httptest.NewRequest("DELETE", "/sessions/1234", nil)
@@ -224,7 +275,7 @@ func TestHandler_DeleteSessions(t *testing.T) {
 			tt.mockSetup(mockRepo, tt.id)
 			mockRepoSSR := new(mocks.MockSessionStudentRepository)
 
-			handler := session.NewHandler(mockRepo, mockRepoSSR)
+			handler := session.NewHandler(mockRepo, mockRepoSSR, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
 			app.Delete("/sessions/:id", handler.DeleteSessions)
 
 			req := httptest.NewRequest("DELETE", fmt.Sprintf("/sessions/%s", tt.id.String()), nil)
@@ -236,6 +287,195 @@ func TestHandler_DeleteSessions(t *testing.T) {
 	}
 }
 
+func TestHandler_SessionStatus(t *testing.T) {
+	id := uuid.New()
+	therapistID := uuid.New()
+	start := time.Date(2025, 11, 4, 14, 0, 0, 0, time.UTC)
+	withStatus := func(status string, reason *string) *models.Session {
+		return &models.Session{
+			ID:            id,
+			SessionName:   "Fluency",
+			StartDateTime: start,
+			EndDateTime:   start.Add(30 * time.Minute),
+			TherapistID:   therapistID,
+			Status:        status,
+			StatusReason:  reason,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		url            string
+		body           string
+		mockSetup      func(*mocks.MockSessionRepository)
+		expectedStatus int
+		wantStatus     string
+	}{
+		{
+			name: "cancel defaults to the therapist",
+			url:  "/sessions/" + id.String() + "/cancel",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(withStatus(models.SessionScheduled, nil), nil)
+				m.On("UpdateSessionStatus", mock.Anything, id, models.SessionCancelledByTherapist, (*string)(nil)).
+					Return(withStatus(models.SessionCancelledByTherapist, nil), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantStatus:     models.SessionCancelledByTherapist,
+		},
+		{
+			name: "cancelled by the school with a reason",
+			url:  "/sessions/" + id.String() + "/cancel",
+			body: `{"status": "cancelled_by_school", "reason": "State testing"}`,
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(withStatus(models.SessionScheduled, nil), nil)
+				m.On("UpdateSessionStatus", mock.Anything, id, models.SessionCancelledBySchool, ptrString("State testing")).
+					Return(withStatus(models.SessionCancelledBySchool, ptrString("State testing")), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantStatus:     models.SessionCancelledBySchool,
+		},
+		{
+			name:           "cancel with a status that is not a cancellation",
+			url:            "/sessions/" + id.String() + "/cancel",
+			body:           `{"status": "completed"}`,
+			mockSetup:      func(m *mocks.MockSessionRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "cancel a completed session",
+			url:  "/sessions/" + id.String() + "/cancel",
+			body: `{"status": "student_absent"}`,
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(withStatus(models.SessionCompleted, nil), nil)
+			},
+			expectedStatus: fiber.StatusConflict,
+		},
+		{
+			name: "cancel a session that does not exist",
+			url:  "/sessions/" + id.String() + "/cancel",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(nil, fmt.Errorf("session not found: %w", pgx.ErrNoRows))
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "cancel with an invalid ID",
+			url:            "/sessions/not-a-uuid/cancel",
+			mockSetup:      func(m *mocks.MockSessionRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "restore a cancelled session",
+			url:  "/sessions/" + id.String() + "/restore",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(withStatus(models.SessionCancelledBySchool, ptrString("Snow")), nil)
+				m.On("FindConflicts", mock.Anything, &models.ConflictCheck{
+					TherapistID: therapistID,
+					SessionIDs:  []uuid.UUID{id},
+					Slots:       []models.TimeSlot{{Start: start, End: start.Add(30 * time.Minute)}},
+				}).Return([]models.SessionConflict{}, nil)
+				m.On("UpdateSessionStatus", mock.Anything, id, models.SessionScheduled, (*string)(nil)).
+					Return(withStatus(models.SessionScheduled, nil), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantStatus:     models.SessionScheduled,
+		},
+		{
+			name: "restore onto a session booked since",
+			url:  "/sessions/" + id.String() + "/restore",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(withStatus(models.SessionCancelledByTherapist, nil), nil)
+				m.On("FindConflicts", mock.Anything, mock.Anything).Return([]models.SessionConflict{{SessionID: uuid.New()}}, nil)
+			},
+			expectedStatus: fiber.StatusConflict,
+		},
+		{
+			name: "restore onto a session booked since, allowed",
+			url:  "/sessions/" + id.String() + "/restore?allow_conflicts=true",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(withStatus(models.SessionCancelledByTherapist, nil), nil)
+				m.On("UpdateSessionStatus", mock.Anything, id, models.SessionScheduled, (*string)(nil)).
+					Return(withStatus(models.SessionScheduled, nil), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantStatus:     models.SessionScheduled,
+		},
+		{
+			name: "restore an absence does not check conflicts",
+			url:  "/sessions/" + id.String() + "/restore",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(withStatus(models.SessionStudentAbsent, nil), nil)
+				m.On("UpdateSessionStatus", mock.Anything, id, models.SessionScheduled, (*string)(nil)).
+					Return(withStatus(models.SessionScheduled, nil), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantStatus:     models.SessionScheduled,
+		},
+		{
+			name: "restore a makeup session as one",
+			url:  "/sessions/" + id.String() + "/restore",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				makeup := withStatus(models.SessionStudentAbsent, nil)
+				makeup.MakeupForSessionID = &therapistID
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(makeup, nil)
+				m.On("UpdateSessionStatus", mock.Anything, id, models.SessionMakeup, (*string)(nil)).
+					Return(withStatus(models.SessionMakeup, nil), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantStatus:     models.SessionMakeup,
+		},
+		{
+			name: "restore a scheduled session",
+			url:  "/sessions/" + id.String() + "/restore",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(withStatus(models.SessionScheduled, nil), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantStatus:     models.SessionScheduled,
+		},
+		{
+			name: "repository error",
+			url:  "/sessions/" + id.String() + "/restore",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetSessionByID", mock.Anything, id.String()).Return(withStatus(models.SessionStudentAbsent, nil), nil)
+				m.On("UpdateSessionStatus", mock.Anything, id, models.SessionScheduled, (*string)(nil)).
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockSessionRepository)
+			tt.mockSetup(mockRepo)
+
+			handler := session.NewHandler(mockRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
+			app.Post("/sessions/:id/cancel", handler.CancelSession)
+			app.Post("/sessions/:id/restore", handler.RestoreSession)
+
+			req := httptest.NewRequest("POST", tt.url, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.wantStatus != "" {
+				var body models.Session
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				assert.Equal(t, tt.wantStatus, body.Status)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_PostSessions(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -371,8 +611,9 @@ func TestHandler_PostSessions(t *testing.T) {
 			mockRepo := new(mocks.MockSessionRepository)
 			mockRepoSSR := new(mocks.MockSessionStudentRepository)
 			tt.mockSetup(mockRepo, mockRepoSSR)
+			expectNoConflicts(mockRepo, uuid.Nil)
 
-			handler := session.NewHandler(mockRepo, mockRepoSSR)
+			handler := session.NewHandler(mockRepo, mockRepoSSR, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
 			app.Post("/sessions", handler.PostSessions)
 
 			req := httptest.NewRequest("POST", "/sessions", strings.NewReader(tt.payload))
@@ -385,151 +626,528 @@ func TestHandler_PostSessions(t *testing.T) {
 	}
 }
 
-func TestHandler_PatchSessions(t *testing.T) {
+func TestHandler_PostSessions_RRule(t *testing.T) {
+	therapistID := uuid.MustParse("28eedfdc-81e1-44e5-a42c-022dc4c3b64d")
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 15, 0, 0, 0, time.UTC)
+	}
+
 	tests := []struct {
-		id                 uuid.UUID
 		name               string
-		payload            string
-		mockSetup          func(*mocks.MockSessionRepository, uuid.UUID)
+		repetition         string
+		mockSetup          func(*mocks.MockSessionRepository)
 		expectedStatusCode int
 	}{
 		{
-			id:                 uuid.New(),
-			name:               "Parsing PatchInputSession Error",
-			payload:            `{"notes": "Missing quote}`,
-			mockSetup:          func(m *mocks.MockSessionRepository, id uuid.UUID) {},
-			expectedStatusCode: fiber.StatusBadRequest,
+			name:       "First and third Tuesday, four times",
+			repetition: `{"recur_start": "2025-09-01T00:00:00Z", "rrule": "RRULE:FREQ=MONTHLY;BYDAY=1TU,3TU;COUNT=4"}`,
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("FindConflicts", mock.Anything, mock.MatchedBy(func(check *models.ConflictCheck) bool {
+					want := []time.Time{at(time.September, 2), at(time.September, 16), at(time.October, 7), at(time.October, 21)}
+					if len(check.Slots) != len(want) {
+						return false
+					}
+					for i, slot := range check.Slots {
+						if !slot.Start.Equal(want[i]) || slot.End.Sub(slot.Start) != 30*time.Minute {
+							return false
+						}
+					}
+					return true
+				})).Return([]models.SessionConflict{{SessionID: uuid.New()}}, nil)
+			},
+			expectedStatusCode: fiber.StatusConflict,
 		},
 		{
-			id:      uuid.New(),
-			name:    "Given ID not found",
-			payload: `{"notes": "Trying to update non-existent"}`,
-			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
-				patch := &models.PatchSessionInput{
-					Notes: ptrString("Trying to update non-existent"),
-				}
-				m.On("PatchSession", mock.Anything, id, patch).Return(nil, pgx.ErrNoRows)
+			name: "Extra and exception dates",
+			repetition: `{"recur_start": "2025-09-01T00:00:00Z", "recur_end": "2025-09-30T00:00:00Z",
+				"rrule": "FREQ=WEEKLY;BYDAY=TU", "extra_dates": ["2025-09-04T00:00:00Z"],
+				"exception_dates": ["2025-09-16T00:00:00Z"]}`,
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("FindConflicts", mock.Anything, mock.MatchedBy(func(check *models.ConflictCheck) bool {
+					want := []time.Time{at(time.September, 2), at(time.September, 4), at(time.September, 9), at(time.September, 23), at(time.September, 30)}
+					if len(check.Slots) != len(want) {
+						return false
+					}
+					for i, slot := range check.Slots {
+						if !slot.Start.Equal(want[i]) {
+							return false
+						}
+					}
+					return true
+				})).Return([]models.SessionConflict{{SessionID: uuid.New()}}, nil)
 			},
-			expectedStatusCode: fiber.StatusNotFound,
+			expectedStatusCode: fiber.StatusConflict,
 		},
 		{
-			id:      uuid.New(),
-			name:    "foreign key violation - DOCTOR WHO? We don't know..",
-			payload: `{"therapist_id": "00000000-0000-0000-0000-000000000999"}`,
-			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
-				therapistID := uuid.MustParse("00000000-0000-0000-0000-000000000999")
-				patch := &models.PatchSessionInput{
-					TherapistID: &therapistID,
-				}
-				m.On("PatchSession", mock.Anything, id, patch).Return(nil, errors.New("foreign key"))
-			},
+			name:               "Rule that does not parse",
+			repetition:         `{"recur_start": "2025-09-01T00:00:00Z", "recur_end": "2025-09-30T00:00:00Z", "rrule": "FREQ=FORTNIGHTLY"}`,
+			mockSetup:          func(m *mocks.MockSessionRepository) {},
 			expectedStatusCode: fiber.StatusBadRequest,
 		},
 		{
-			id:      uuid.New(),
-			name:    "check constraint violation",
-			payload: `{"start_datetime": "2025-09-14T14:00:00Z", "end_datetime": "2025-09-14T12:00:00Z"}`,
-			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
-				startTime, _ := time.Parse(time.RFC3339, "2025-09-14T14:00:00Z")
-				endTime, _ := time.Parse(time.RFC3339, "2025-09-14T12:00:00Z")
-				patch := &models.PatchSessionInput{
-					StartTime: &startTime,
-					EndTime:   &endTime,
-				}
-				m.On("PatchSession", mock.Anything, id, patch).Return(nil, errors.New("check constraint"))
-			},
+			name:               "Rule that never ends",
+			repetition:         `{"recur_start": "2025-09-01T00:00:00Z", "rrule": "FREQ=WEEKLY;BYDAY=MO"}`,
+			mockSetup:          func(m *mocks.MockSessionRepository) {},
 			expectedStatusCode: fiber.StatusBadRequest,
 		},
 		{
-			id:      uuid.New(),
-			name:    "Successfully changed 1 field",
-			payload: `{"notes": "The child seeks to be seen more than they wish to be heard"}`,
-			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
-				notes := ptrString("The child seeks to be seen more than they wish to be heard")
-				createdAt := time.Now().Add(-24 * time.Hour)
-				now := time.Now()
-
-				patch := &models.PatchSessionInput{
-					Notes: notes,
-				}
-
-				patchedSession := &models.Session{
-					ID:            id,
-					SessionName:   "some name",
-					StartDateTime: time.Now(),
-					EndDateTime:   time.Now().Add(time.Hour),
-					TherapistID:   uuid.New(),
-					Notes:         notes,
-					Location:      ptrString("I am homesick"),
-					CreatedAt:     &createdAt,
-					UpdatedAt:     &now,
-				}
-				m.On("PatchSession", mock.Anything, id, patch).Return(patchedSession, nil)
-			},
-			expectedStatusCode: fiber.StatusOK,
+			name:               "Rule creating too many sessions",
+			repetition:         `{"recur_start": "2025-09-01T00:00:00Z", "recur_end": "2028-09-01T00:00:00Z", "rrule": "FREQ=DAILY"}`,
+			mockSetup:          func(m *mocks.MockSessionRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
 		},
 		{
-			id:      uuid.New(),
-			name:    "Successfully changed multiple fields",
-			payload: `{"start_datetime": "2025-09-14T12:00:00Z", "end_datetime": "2025-09-14T13:00:00Z"}`,
-			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
-				startTime, _ := time.Parse(time.RFC3339, "2025-09-14T12:00:00Z")
-				endTime, _ := time.Parse(time.RFC3339, "2025-09-14T13:00:00Z")
-				createdAt := time.Now().Add(-24 * time.Hour)
-				now := time.Now()
-
-				patch := &models.PatchSessionInput{
-					StartTime: &startTime,
-					EndTime:   &endTime,
-				}
-
-				patchedSession := &models.Session{
-					ID:            id,
-					SessionName:   "success on multiple fields",
-					StartDateTime: startTime,
-					EndDateTime:   endTime,
-					TherapistID:   uuid.New(),
-					Notes:         ptrString("Rescheduled for convenience"),
-					Location:      ptrString("The place from where I fell"),
-					CreatedAt:     &createdAt,
-					UpdatedAt:     &now,
-				}
-				m.On("PatchSession", mock.Anything, id, patch).Return(patchedSession, nil)
-			},
-			expectedStatusCode: fiber.StatusOK,
+			name: "Extra date outside the repetition",
+			repetition: `{"recur_start": "2025-09-01T00:00:00Z", "recur_end": "2025-09-30T00:00:00Z",
+				"rrule": "FREQ=WEEKLY;BYDAY=TU", "extra_dates": ["2025-10-02T00:00:00Z"]}`,
+			mockSetup:          func(m *mocks.MockSessionRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
 		},
 		{
-			id:   uuid.New(),
-			name: "Successfully changed all patchable fields",
-			payload: `{
-				"session_name": "All Success",
-				"start_datetime": "2025-09-14T12:00:00Z", 
-				"end_datetime": "2025-09-14T13:00:00Z", 
-				"therapist_id": "28eedfdc-81e1-44e5-a42c-022dc4c3b64d", 
-				"notes": "Starting Over",
-				"location": "Somewhere you will never find me"
-			}`,
-			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
-				startTime, _ := time.Parse(time.RFC3339, "2025-09-14T12:00:00Z")
-				endTime, _ := time.Parse(time.RFC3339, "2025-09-14T13:00:00Z")
-				therapistID := uuid.MustParse("28eedfdc-81e1-44e5-a42c-022dc4c3b64d")
-				notes := ptrString("Starting Over")
-				createdAt := time.Now().Add(-24 * time.Hour)
-				now := time.Now()
+			name:               "Weekly repetition still needs its days",
+			repetition:         `{"recur_start": "2025-09-01T00:00:00Z", "recur_end": "2025-09-30T00:00:00Z", "every_n_weeks": 1}`,
+			mockSetup:          func(m *mocks.MockSessionRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
 
-				patch := &models.PatchSessionInput{
-					SessionName: ptrString("All Success"),
-					StartTime:   &startTime,
-					EndTime:     &endTime,
-					TherapistID: &therapistID,
-					Notes:       notes,
-					Location:    ptrString("Somewhere you will never find me"),
-				}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockSessionRepository)
+			tt.mockSetup(mockRepo)
+			mockRepo.On("GetTherapistLocation", mock.Anything, therapistID).Return(time.UTC, nil).Maybe()
 
-				patchedSession := &models.Session{
-					ID:            id,
-					StartDateTime: startTime,
-					EndDateTime:   endTime,
+			handler := session.NewHandler(mockRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
+			app.Post("/sessions", handler.PostSessions)
+
+			req := httptest.NewRequest("POST", "/sessions", strings.NewReader(`{
+				"session_name": "Pragmatics group",
+				"start_datetime": "2025-09-02T15:00:00Z",
+				"end_datetime": "2025-09-02T15:30:00Z",
+				"therapist_id": "`+therapistID.String()+`",
+				"repetition": `+tt.repetition+`
+			}`))
+			req.Header.Set("Content-Type", "application/json")
+
+			res, _ := app.Test(req, -1)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			mockRepo.AssertNotCalled(t, "GetDB")
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_PostSessions_Makeup(t *testing.T) {
+	therapistID := uuid.New()
+	missedID := uuid.New()
+	owedID, madeUpID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name               string
+		extra              string
+		studentIDs         []uuid.UUID
+		owed               []models.OwedMinutes
+		owedErr            error
+		expectedStatusCode int
+		// reachesConflicts is whether the makeup passes its checks, onto the conflict check
+		reachesConflicts bool
+	}{
+		{
+			name:               "Students owed minutes",
+			studentIDs:         []uuid.UUID{owedID},
+			owed:               []models.OwedMinutes{{StudentID: owedID, Minutes: 30}, {StudentID: uuid.New(), Minutes: 30}},
+			expectedStatusCode: fiber.StatusConflict,
+			reachesConflicts:   true,
+		},
+		{
+			name:               "Student already made up for",
+			studentIDs:         []uuid.UUID{owedID, madeUpID},
+			owed:               []models.OwedMinutes{{StudentID: owedID, Minutes: 30}},
+			expectedStatusCode: fiber.StatusConflict,
+		},
+		{
+			name:               "Session that was not missed",
+			studentIDs:         []uuid.UUID{owedID},
+			owed:               []models.OwedMinutes{},
+			expectedStatusCode: fiber.StatusConflict,
+		},
+		{
+			name:               "Without students",
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Repeating",
+			extra:              `, "repetition": {"recur_start": "2025-09-01T00:00:00Z", "recur_end": "2025-09-30T00:00:00Z", "rrule": "FREQ=WEEKLY;BYDAY=TU"}`,
+			studentIDs:         []uuid.UUID{owedID},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Repository error",
+			studentIDs:         []uuid.UUID{owedID},
+			owedErr:            errors.New("database error"),
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockSessionRepository)
+			if tt.owed != nil || tt.owedErr != nil {
+				mockRepo.On("GetOwedMinutes", mock.Anything, missedID).Return(tt.owed, tt.owedErr)
+			}
+			// A conflict stops the request before it reaches the database
+			mockRepo.On("FindConflicts", mock.Anything, mock.Anything).Return([]models.SessionConflict{{SessionID: uuid.New()}}, nil).Maybe()
+			mockRepo.On("GetTherapistLocation", mock.Anything, therapistID).Return(time.UTC, nil).Maybe()
+
+			handler := session.NewHandler(mockRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
+			app.Post("/sessions", handler.PostSessions)
+
+			studentIDs, _ := json.Marshal(tt.studentIDs)
+			req := httptest.NewRequest("POST", "/sessions", strings.NewReader(`{
+				"session_name": "Articulation makeup",
+				"start_datetime": "2025-09-02T15:00:00Z",
+				"end_datetime": "2025-09-02T15:30:00Z",
+				"therapist_id": "`+therapistID.String()+`",
+				"makeup_for_session_id": "`+missedID.String()+`",
+				"student_ids": `+string(studentIDs)+tt.extra+`
+			}`))
+			req.Header.Set("Content-Type", "application/json")
+
+			res, _ := app.Test(req, -1)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			if tt.reachesConflicts {
+				mockRepo.AssertCalled(t, "FindConflicts", mock.Anything, mock.Anything)
+			} else {
+				mockRepo.AssertNotCalled(t, "FindConflicts", mock.Anything, mock.Anything)
+			}
+			mockRepo.AssertNotCalled(t, "GetDB")
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_PostSessions_Template(t *testing.T) {
+	therapistID := uuid.New()
+	templateID := uuid.New()
+	location := "Room 12"
+	template := &models.SessionTemplate{
+		ID:              templateID,
+		TherapistID:     therapistID,
+		Name:            "Articulation",
+		DurationMinutes: 45,
+		Location:        &location,
+		ResourceIDs:     []uuid.UUID{uuid.New()},
+	}
+	start := time.Date(2025, 9, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		body               string
+		mockSetup          func(*mocks.MockSessionTemplateRepository)
+		expectedStatusCode int
+		// expectedSlot is the time the session is checked for conflicts at, when the
+		// template is applied
+		expectedSlot *models.TimeSlot
+	}{
+		{
+			name: "Takes the template's length",
+			body: `"start_datetime": "2025-09-02T15:00:00Z"`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplate", mock.Anything, templateID).Return(template, nil)
+			},
+			expectedStatusCode: fiber.StatusConflict,
+			expectedSlot:       &models.TimeSlot{Start: start, End: start.Add(45 * time.Minute)},
+		},
+		{
+			name: "Keeps its own end time",
+			body: `"session_name": "Fluency", "start_datetime": "2025-09-02T15:00:00Z", "end_datetime": "2025-09-02T15:30:00Z"`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplate", mock.Anything, templateID).Return(template, nil)
+			},
+			expectedStatusCode: fiber.StatusConflict,
+			expectedSlot:       &models.TimeSlot{Start: start, End: start.Add(30 * time.Minute)},
+		},
+		{
+			name: "Another therapist's template",
+			body: `"start_datetime": "2025-09-02T15:00:00Z"`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				other := *template
+				other.TherapistID = uuid.New()
+				m.On("GetTemplate", mock.Anything, templateID).Return(&other, nil)
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Template not found",
+			body: `"start_datetime": "2025-09-02T15:00:00Z"`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplate", mock.Anything, templateID).Return(nil, errs.NotFound("Session template", "id", templateID.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name: "Repository error",
+			body: `"start_datetime": "2025-09-02T15:00:00Z"`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplate", mock.Anything, templateID).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockSessionRepository)
+			mockTemplateRepo := new(mocks.MockSessionTemplateRepository)
+			tt.mockSetup(mockTemplateRepo)
+			// A conflict stops the request before it reaches the database
+			mockRepo.On("FindConflicts", mock.Anything, mock.Anything).Return([]models.SessionConflict{{SessionID: uuid.New()}}, nil).Maybe()
+			mockRepo.On("GetTherapistLocation", mock.Anything, therapistID).Return(time.UTC, nil).Maybe()
+
+			handler := session.NewHandler(mockRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockSessionResourceRepository), mockTemplateRepo)
+			app.Post("/sessions", handler.PostSessions)
+
+			req := httptest.NewRequest("POST", "/sessions", strings.NewReader(`{
+				"therapist_id": "`+therapistID.String()+`",
+				"template_id": "`+templateID.String()+`",
+				`+tt.body+`
+			}`))
+			req.Header.Set("Content-Type", "application/json")
+
+			res, _ := app.Test(req, -1)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			if tt.expectedSlot != nil {
+				mockRepo.AssertCalled(t, "FindConflicts", mock.Anything, mock.MatchedBy(func(check *models.ConflictCheck) bool {
+					return len(check.Slots) == 1 && check.Slots[0].Start.Equal(tt.expectedSlot.Start) && check.Slots[0].End.Equal(tt.expectedSlot.End)
+				}))
+			} else {
+				mockRepo.AssertNotCalled(t, "FindConflicts", mock.Anything, mock.Anything)
+			}
+			mockRepo.AssertNotCalled(t, "GetDB")
+			mockTemplateRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_PostSessions_TimeZone(t *testing.T) {
+	therapistID := uuid.MustParse("28eedfdc-81e1-44e5-a42c-022dc4c3b64d")
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	newApp := func(m *mocks.MockSessionRepository) *fiber.App {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: errs.ErrorHandler,
+		})
+		handler := session.NewHandler(m, new(mocks.MockSessionStudentRepository), new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
+		app.Post("/sessions", handler.PostSessions)
+		return app
+	}
+
+	// Tuesdays at 10:00 in New York, across the end of daylight saving on November 2
+	payload := `{
+		"session_name": "Fluency",
+		"start_datetime": "2025-10-28T14:00:00Z",
+		"end_datetime": "2025-10-28T14:45:00Z",
+		"therapist_id": "` + therapistID.String() + `",
+		"repetition": {"recur_start": "2025-10-28T00:00:00Z", "recur_end": "2025-11-11T00:00:00Z", "every_n_weeks": 1, "days": [2]}
+	}`
+
+	t.Run("series keeps its local time of day", func(t *testing.T) {
+		mockRepo := new(mocks.MockSessionRepository)
+		mockRepo.On("GetTherapistLocation", mock.Anything, therapistID).Return(newYork, nil)
+		mockRepo.On("FindConflicts", mock.Anything, mock.MatchedBy(func(check *models.ConflictCheck) bool {
+			want := []time.Time{
+				time.Date(2025, 10, 28, 14, 0, 0, 0, time.UTC),
+				time.Date(2025, 11, 4, 15, 0, 0, 0, time.UTC),
+				time.Date(2025, 11, 11, 15, 0, 0, 0, time.UTC),
+			}
+			if len(check.Slots) != len(want) {
+				return false
+			}
+			for i, slot := range check.Slots {
+				if !slot.Start.Equal(want[i]) || slot.End.Sub(slot.Start) != 45*time.Minute {
+					return false
+				}
+			}
+			return true
+		})).Return([]models.SessionConflict{{SessionID: uuid.New()}}, nil)
+
+		req := httptest.NewRequest("POST", "/sessions", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, _ := newApp(mockRepo).Test(req, -1)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown therapist", func(t *testing.T) {
+		mockRepo := new(mocks.MockSessionRepository)
+		mockRepo.On("GetTherapistLocation", mock.Anything, therapistID).Return(nil, pgx.ErrNoRows)
+
+		req := httptest.NewRequest("POST", "/sessions", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, _ := newApp(mockRepo).Test(req, -1)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		mockRepo.AssertNotCalled(t, "FindConflicts", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandler_PatchSessions(t *testing.T) {
+	tests := []struct {
+		id                 uuid.UUID
+		name               string
+		payload            string
+		mockSetup          func(*mocks.MockSessionRepository, uuid.UUID)
+		expectedStatusCode int
+	}{
+		{
+			id:                 uuid.New(),
+			name:               "Parsing PatchInputSession Error",
+			payload:            `{"notes": "Missing quote}`,
+			mockSetup:          func(m *mocks.MockSessionRepository, id uuid.UUID) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			id:      uuid.New(),
+			name:    "Given ID not found",
+			payload: `{"notes": "Trying to update non-existent"}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				patch := &models.PatchSessionInput{
+					Notes: ptrString("Trying to update non-existent"),
+				}
+				m.On("PatchSession", mock.Anything, id, patch).Return(nil, pgx.ErrNoRows)
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			id:      uuid.New(),
+			name:    "foreign key violation - DOCTOR WHO? We don't know..",
+			payload: `{"therapist_id": "00000000-0000-0000-0000-000000000999"}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				therapistID := uuid.MustParse("00000000-0000-0000-0000-000000000999")
+				patch := &models.PatchSessionInput{
+					TherapistID: &therapistID,
+				}
+				m.On("PatchSession", mock.Anything, id, patch).Return(nil, errors.New("foreign key"))
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			id:      uuid.New(),
+			name:    "check constraint violation",
+			payload: `{"start_datetime": "2025-09-14T14:00:00Z", "end_datetime": "2025-09-14T12:00:00Z"}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				startTime, _ := time.Parse(time.RFC3339, "2025-09-14T14:00:00Z")
+				endTime, _ := time.Parse(time.RFC3339, "2025-09-14T12:00:00Z")
+				patch := &models.PatchSessionInput{
+					StartTime: &startTime,
+					EndTime:   &endTime,
+				}
+				m.On("PatchSession", mock.Anything, id, patch).Return(nil, errors.New("check constraint"))
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			id:      uuid.New(),
+			name:    "Successfully changed 1 field",
+			payload: `{"notes": "The child seeks to be seen more than they wish to be heard"}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				notes := ptrString("The child seeks to be seen more than they wish to be heard")
+				createdAt := time.Now().Add(-24 * time.Hour)
+				now := time.Now()
+
+				patch := &models.PatchSessionInput{
+					Notes: notes,
+				}
+
+				patchedSession := &models.Session{
+					ID:            id,
+					SessionName:   "some name",
+					StartDateTime: time.Now(),
+					EndDateTime:   time.Now().Add(time.Hour),
+					TherapistID:   uuid.New(),
+					Notes:         notes,
+					Location:      ptrString("I am homesick"),
+					CreatedAt:     &createdAt,
+					UpdatedAt:     &now,
+				}
+				m.On("PatchSession", mock.Anything, id, patch).Return(patchedSession, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			id:      uuid.New(),
+			name:    "Successfully changed multiple fields",
+			payload: `{"start_datetime": "2025-09-14T12:00:00Z", "end_datetime": "2025-09-14T13:00:00Z"}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				startTime, _ := time.Parse(time.RFC3339, "2025-09-14T12:00:00Z")
+				endTime, _ := time.Parse(time.RFC3339, "2025-09-14T13:00:00Z")
+				createdAt := time.Now().Add(-24 * time.Hour)
+				now := time.Now()
+
+				patch := &models.PatchSessionInput{
+					StartTime: &startTime,
+					EndTime:   &endTime,
+				}
+
+				patchedSession := &models.Session{
+					ID:            id,
+					SessionName:   "success on multiple fields",
+					StartDateTime: startTime,
+					EndDateTime:   endTime,
+					TherapistID:   uuid.New(),
+					Notes:         ptrString("Rescheduled for convenience"),
+					Location:      ptrString("The place from where I fell"),
+					CreatedAt:     &createdAt,
+					UpdatedAt:     &now,
+				}
+				m.On("PatchSession", mock.Anything, id, patch).Return(patchedSession, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			id:   uuid.New(),
+			name: "Successfully changed all patchable fields",
+			payload: `{
+				"session_name": "All Success",
+				"start_datetime": "2025-09-14T12:00:00Z", 
+				"end_datetime": "2025-09-14T13:00:00Z", 
+				"therapist_id": "28eedfdc-81e1-44e5-a42c-022dc4c3b64d", 
+				"notes": "Starting Over",
+				"location": "Somewhere you will never find me"
+			}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				startTime, _ := time.Parse(time.RFC3339, "2025-09-14T12:00:00Z")
+				endTime, _ := time.Parse(time.RFC3339, "2025-09-14T13:00:00Z")
+				therapistID := uuid.MustParse("28eedfdc-81e1-44e5-a42c-022dc4c3b64d")
+				notes := ptrString("Starting Over")
+				createdAt := time.Now().Add(-24 * time.Hour)
+				now := time.Now()
+
+				patch := &models.PatchSessionInput{
+					SessionName: ptrString("All Success"),
+					StartTime:   &startTime,
+					EndTime:     &endTime,
+					TherapistID: &therapistID,
+					Notes:       notes,
+					Location:    ptrString("Somewhere you will never find me"),
+				}
+
+				patchedSession := &models.Session{
+					ID:            id,
+					StartDateTime: startTime,
+					EndDateTime:   endTime,
 					TherapistID:   therapistID,
 					Notes:         notes,
 					CreatedAt:     &createdAt,
@@ -548,7 +1166,7 @@ func TestHandler_PatchSessions(t *testing.T) {
 		mockRepo := new(mocks.MockSessionRepository)
 		mockRepoSSR := new(mocks.MockSessionStudentRepository)
 
-		handler := session.NewHandler(mockRepo, mockRepoSSR)
+		handler := session.NewHandler(mockRepo, mockRepoSSR, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
 		app.Patch("/sessions/:id", handler.PatchSessions)
 
 		req := httptest.NewRequest("PATCH", "/sessions/0345", nil)
@@ -566,9 +1184,10 @@ func TestHandler_PatchSessions(t *testing.T) {
 			})
 			mockRepo := new(mocks.MockSessionRepository)
 			tt.mockSetup(mockRepo, tt.id)
+			expectNoConflicts(mockRepo, tt.id)
 
 			mockRepoSSR := new(mocks.MockSessionStudentRepository)
-			handler := session.NewHandler(mockRepo, mockRepoSSR)
+			handler := session.NewHandler(mockRepo, mockRepoSSR, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
 			app.Patch("/sessions/:id", handler.PatchSessions)
 
 			req := httptest.NewRequest("PATCH", "/sessions/"+tt.id.String(), strings.NewReader(tt.payload))
@@ -580,3 +1199,520 @@ func TestHandler_PatchSessions(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_PatchSessions_Scope(t *testing.T) {
+	recurring := &models.Repetition{
+		RecurStart:  time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+		RecurEnd:    time.Date(2025, 12, 19, 0, 0, 0, 0, time.UTC),
+		EveryNWeeks: 1,
+		Days:        []int{2, 4},
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		payload            string
+		mockSetup          func(*mocks.MockSessionRepository, uuid.UUID)
+		expectedStatusCode int
+		expectedCount      int
+	}{
+		{
+			name:               "Unknown scope",
+			query:              "?scope=some",
+			payload:            `{"location": "Room 12"}`,
+			mockSetup:          func(m *mocks.MockSessionRepository, id uuid.UUID) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Repetition without a series scope",
+			query:              "?scope=this",
+			payload:            `{"repetition": {"recur_start": "2025-09-01T00:00:00Z", "recur_end": "2025-12-19T00:00:00Z", "every_n_weeks": 1, "days": [2, 4]}}`,
+			mockSetup:          func(m *mocks.MockSessionRepository, id uuid.UUID) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Invalid repetition",
+			query:              "?scope=following",
+			payload:            `{"repetition": {"recur_start": "2025-09-01T00:00:00Z", "recur_end": "2025-08-01T00:00:00Z", "every_n_weeks": 1, "days": [2]}}`,
+			mockSetup:          func(m *mocks.MockSessionRepository, id uuid.UUID) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "This occurrence only",
+			query:   "?scope=this",
+			payload: `{"location": "Room 12"}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				patch := &models.PatchSessionInput{Location: ptrString("Room 12")}
+				m.On("PatchSession", mock.Anything, id, patch).Return(&models.Session{ID: id, Location: ptrString("Room 12")}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:    "This and following occurrences",
+			query:   "?scope=following",
+			payload: `{"location": "Room 12"}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				patch := &models.PatchSessionInput{Location: ptrString("Room 12")}
+				sessions := []models.Session{
+					{ID: uuid.New(), Location: ptrString("Room 12"), Repetition: recurring},
+					{ID: uuid.New(), Location: ptrString("Room 12"), Repetition: recurring},
+				}
+				m.On("PatchRecurringSessions", mock.Anything, id, models.SessionScopeFollowing, patch).Return(&sessions, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedCount:      2,
+		},
+		{
+			name:    "All occurrences with a new repetition",
+			query:   "?scope=all",
+			payload: `{"repetition": {"recur_start": "2025-09-01T00:00:00Z", "recur_end": "2025-12-19T00:00:00Z", "every_n_weeks": 1, "days": [2, 4]}}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				patch := &models.PatchSessionInput{Repetition: recurring}
+				sessions := []models.Session{{ID: uuid.New(), Repetition: recurring}}
+				m.On("PatchRecurringSessions", mock.Anything, id, models.SessionScopeAll, patch).Return(&sessions, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedCount:      1,
+		},
+		{
+			name:    "Series not found",
+			query:   "?scope=all",
+			payload: `{"notes": "gone"}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				patch := &models.PatchSessionInput{Notes: ptrString("gone")}
+				m.On("PatchRecurringSessions", mock.Anything, id, models.SessionScopeAll, patch).Return(nil, pgx.ErrNoRows)
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:    "Series check constraint violation",
+			query:   "?scope=following",
+			payload: `{"start_datetime": "2025-09-14T14:00:00Z", "end_datetime": "2025-09-14T12:00:00Z"}`,
+			mockSetup: func(m *mocks.MockSessionRepository, id uuid.UUID) {
+				m.On("PatchRecurringSessions", mock.Anything, id, models.SessionScopeFollowing, mock.AnythingOfType("*models.PatchSessionInput")).
+					Return(nil, errors.New("check constraint"))
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			id := uuid.New()
+			mockRepo := new(mocks.MockSessionRepository)
+			tt.mockSetup(mockRepo, id)
+			expectNoConflicts(mockRepo, id)
+
+			handler := session.NewHandler(mockRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
+			app.Patch("/sessions/:id", handler.PatchSessions)
+
+			req := httptest.NewRequest("PATCH", "/sessions/"+id.String()+tt.query, strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			res, _ := app.Test(req, -1)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedCount > 0 {
+				var body []models.Session
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+				assert.Len(t, body, tt.expectedCount)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_SessionConflicts(t *testing.T) {
+	therapistID := uuid.MustParse("28eedfdc-81e1-44e5-a42c-022dc4c3b64d")
+	studentID := uuid.New()
+	conflict := models.SessionConflict{
+		SessionID:     uuid.New(),
+		SessionName:   "Articulation",
+		StartDateTime: time.Date(2025, 9, 14, 10, 30, 0, 0, time.UTC),
+		EndDateTime:   time.Date(2025, 9, 14, 11, 30, 0, 0, time.UTC),
+		TherapistID:   therapistID,
+	}
+
+	newApp := func(m *mocks.MockSessionRepository) *fiber.App {
+		m.On("GetTherapistLocation", mock.Anything, mock.Anything).Return(time.UTC, nil).Maybe()
+
+		app := fiber.New(fiber.Config{
+			ErrorHandler: errs.ErrorHandler,
+		})
+		handler := session.NewHandler(m, new(mocks.MockSessionStudentRepository), new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
+		app.Post("/sessions", handler.PostSessions)
+		app.Patch("/sessions/:id", handler.PatchSessions)
+		return app
+	}
+
+	decodeConflicts := func(t *testing.T, res *http.Response) models.SchedulingConflicts {
+		var body struct {
+			Code    int                        `json:"code"`
+			Message models.SchedulingConflicts `json:"message"`
+		}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		assert.Equal(t, fiber.StatusConflict, body.Code)
+		return body.Message
+	}
+
+	t.Run("create overlapping every occurrence is checked", func(t *testing.T) {
+		mockRepo := new(mocks.MockSessionRepository)
+		mockRepo.On("FindConflicts", mock.Anything, mock.MatchedBy(func(check *models.ConflictCheck) bool {
+			// Sundays in September from the 14th
+			return check.TherapistID == therapistID && len(check.Slots) == 3 &&
+				len(check.StudentIDs) == 1 && check.StudentIDs[0] == studentID
+		})).Return([]models.SessionConflict{conflict}, nil)
+
+		req := httptest.NewRequest("POST", "/sessions", strings.NewReader(`{
+			"session_name": "Fluency",
+			"start_datetime": "2025-09-14T10:00:00Z",
+			"end_datetime": "2025-09-14T11:00:00Z",
+			"therapist_id": "`+therapistID.String()+`",
+			"student_ids": ["`+studentID.String()+`"],
+			"repetition": {"recur_start": "2025-09-14T00:00:00Z", "recur_end": "2025-09-30T00:00:00Z", "every_n_weeks": 1, "days": [0]}
+		}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, _ := newApp(mockRepo).Test(req, -1)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+		body := decodeConflicts(t, res)
+		assert.Len(t, body.Conflicts, 1)
+		assert.Equal(t, conflict.SessionID, body.Conflicts[0].SessionID)
+		mockRepo.AssertNotCalled(t, "GetDB")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("create with conflicts allowed", func(t *testing.T) {
+		mockRepo := new(mocks.MockSessionRepository)
+		mockRepo.On("GetDB").Return((*pgxpool.Pool)(nil))
+
+		req := httptest.NewRequest("POST", "/sessions?allow_conflicts=true", strings.NewReader(`{
+			"session_name": "Fluency",
+			"start_datetime": "2025-09-14T10:00:00Z",
+			"end_datetime": "2025-09-14T11:00:00Z",
+			"therapist_id": "`+therapistID.String()+`"
+		}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, _ := newApp(mockRepo).Test(req, -1)
+		// Goes on to the transaction
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+		mockRepo.AssertNotCalled(t, "FindConflicts", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("create conflict check fails", func(t *testing.T) {
+		mockRepo := new(mocks.MockSessionRepository)
+		mockRepo.On("FindConflicts", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+		req := httptest.NewRequest("POST", "/sessions", strings.NewReader(`{
+			"session_name": "Fluency",
+			"start_datetime": "2025-09-14T10:00:00Z",
+			"end_datetime": "2025-09-14T11:00:00Z",
+			"therapist_id": "`+therapistID.String()+`"
+		}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, _ := newApp(mockRepo).Test(req, -1)
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("moving a session onto another", func(t *testing.T) {
+		id := uuid.New()
+		mockRepo := new(mocks.MockSessionRepository)
+		mockRepo.On("GetSessionByID", mock.Anything, id.String()).Return(&models.Session{
+			ID:            id,
+			StartDateTime: time.Date(2025, 9, 14, 8, 0, 0, 0, time.UTC),
+			EndDateTime:   time.Date(2025, 9, 14, 9, 0, 0, 0, time.UTC),
+			TherapistID:   therapistID,
+		}, nil)
+		mockRepo.On("FindConflicts", mock.Anything, &models.ConflictCheck{
+			TherapistID: therapistID,
+			SessionIDs:  []uuid.UUID{id},
+			Slots: []models.TimeSlot{{
+				Start: time.Date(2025, 9, 14, 10, 0, 0, 0, time.UTC),
+				End:   time.Date(2025, 9, 14, 11, 0, 0, 0, time.UTC),
+			}},
+		}).Return([]models.SessionConflict{conflict}, nil)
+
+		req := httptest.NewRequest("PATCH", "/sessions/"+id.String(), strings.NewReader(`{
+			"start_datetime": "2025-09-14T10:00:00Z",
+			"end_datetime": "2025-09-14T11:00:00Z"
+		}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, _ := newApp(mockRepo).Test(req, -1)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+		assert.Len(t, decodeConflicts(t, res).Conflicts, 1)
+		mockRepo.AssertNotCalled(t, "PatchSession", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rescheduling the rest of a series", func(t *testing.T) {
+		id := uuid.New()
+		seriesID := uuid.New()
+		start := time.Now().AddDate(0, 0, 1).Truncate(time.Hour)
+		mockRepo := new(mocks.MockSessionRepository)
+		mockRepo.On("GetSessionByID", mock.Anything, id.String()).Return(&models.Session{
+			ID:              id,
+			StartDateTime:   start,
+			EndDateTime:     start.Add(time.Hour),
+			TherapistID:     therapistID,
+			SessionParentID: seriesID,
+			Repetition: &models.Repetition{
+				RecurStart:  start.AddDate(0, 0, -14),
+				RecurEnd:    start.AddDate(0, 0, 14),
+				EveryNWeeks: 1,
+				Days:        []int{int(start.Weekday())},
+			},
+		}, nil)
+		mockRepo.On("FindConflicts", mock.Anything, mock.MatchedBy(func(check *models.ConflictCheck) bool {
+			// This week's occurrence and the two after it, but none already past
+			return check.SeriesID != nil && *check.SeriesID == seriesID && len(check.Slots) == 3 &&
+				!check.Slots[0].Start.Before(start)
+		})).Return([]models.SessionConflict{conflict}, nil)
+
+		req := httptest.NewRequest("PATCH", "/sessions/"+id.String()+"?scope=following", strings.NewReader(`{
+			"therapist_id": "`+therapistID.String()+`"
+		}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, _ := newApp(mockRepo).Test(req, -1)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+		mockRepo.AssertNotCalled(t, "PatchRecurringSessions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("moving a session that does not exist", func(t *testing.T) {
+		id := uuid.New()
+		mockRepo := new(mocks.MockSessionRepository)
+		mockRepo.On("GetSessionByID", mock.Anything, id.String()).Return(nil, pgx.ErrNoRows)
+
+		req := httptest.NewRequest("PATCH", "/sessions/"+id.String(), strings.NewReader(`{"start_datetime": "2025-09-14T10:00:00Z"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, _ := newApp(mockRepo).Test(req, -1)
+		assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestHandler_GetConflicts(t *testing.T) {
+	therapistID := uuid.New()
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		query              string
+		mockSetup          func(*mocks.MockSessionRepository)
+		expectedStatusCode int
+		expectedCount      int
+	}{
+		{
+			name:  "Date range",
+			query: "?therapist_id=" + therapistID.String() + "&startdate=2025-09-01T00:00:00Z&enddate=2025-10-01T00:00:00Z",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetConflictReport", mock.Anything, therapistID, from, from.AddDate(0, 1, 0)).Return([]models.SessionOverlap{
+					{
+						Session:       models.SessionConflict{SessionID: uuid.New(), TherapistID: therapistID},
+						ConflictsWith: models.SessionConflict{SessionID: uuid.New(), TherapistID: therapistID},
+					},
+				}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedCount:      1,
+		},
+		{
+			name:  "Four weeks from the start date by default",
+			query: "?therapist_id=" + therapistID.String() + "&startdate=2025-09-01T00:00:00Z",
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetConflictReport", mock.Anything, therapistID, from, from.AddDate(0, 0, 28)).Return([]models.SessionOverlap{}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:               "Missing therapist",
+			query:              "?startdate=2025-09-01T00:00:00Z",
+			mockSetup:          func(m *mocks.MockSessionRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "End before start",
+			query:              "?therapist_id=" + therapistID.String() + "&startdate=2025-09-01T00:00:00Z&enddate=2025-08-01T00:00:00Z",
+			mockSetup:          func(m *mocks.MockSessionRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "More than a year",
+			query:              "?therapist_id=" + therapistID.String() + "&startdate=2025-09-01T00:00:00Z&enddate=2026-10-01T00:00:00Z",
+			mockSetup:          func(m *mocks.MockSessionRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:  "Repository error",
+			query: "?therapist_id=" + therapistID.String(),
+			mockSetup: func(m *mocks.MockSessionRepository) {
+				m.On("GetConflictReport", mock.Anything, therapistID, mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockSessionRepository)
+			tt.mockSetup(mockRepo)
+
+			handler := session.NewHandler(mockRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
+			app.Get("/sessions/conflicts", handler.GetConflicts)
+
+			res, _ := app.Test(httptest.NewRequest("GET", "/sessions/conflicts"+tt.query, nil), -1)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedCount > 0 {
+				var body []models.SessionOverlap
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+				assert.Len(t, body, tt.expectedCount)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_PutAttendance(t *testing.T) {
+	sessionID := uuid.New()
+	adaID, graceID := uuid.New(), uuid.New()
+	present, absent := true, false
+	notes := "Worked on /r/ blends"
+
+	tests := []struct {
+		name               string
+		url                string
+		payload            string
+		mockSetup          func(*mocks.MockSessionStudentRepository)
+		expectedStatusCode int
+		expectedCount      int
+	}{
+		{
+			name: "Whole group",
+			url:  "/sessions/" + sessionID.String() + "/attendance",
+			payload: `{"students": [
+				{"student_id": "` + adaID.String() + `", "present": true, "notes": "Worked on /r/ blends",
+				 "ratings": [{"category": "visual_cue", "level": "minimal", "description": "Needed one prompt"}]},
+				{"student_id": "` + graceID.String() + `", "present": false}
+			]}`,
+			mockSetup: func(m *mocks.MockSessionStudentRepository) {
+				m.On("SaveAttendance", mock.Anything, sessionID, &models.SessionAttendanceInput{
+					Students: []models.StudentAttendanceInput{
+						{StudentID: adaID, Present: &present, Notes: &notes, Ratings: &[]models.RateInput{
+							{Category: "visual_cue", Level: "minimal", Description: "Needed one prompt"},
+						}},
+						{StudentID: graceID, Present: &absent},
+					},
+				}).Return([]models.SessionStudentsOutput{
+					{SessionID: sessionID, Student: models.Student{ID: adaID}, Present: true},
+					{SessionID: sessionID, Student: models.Student{ID: graceID}},
+				}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedCount:      2,
+		},
+		{
+			name:               "Without presence",
+			url:                "/sessions/" + sessionID.String() + "/attendance",
+			payload:            `{"students": [{"student_id": "` + adaID.String() + `"}]}`,
+			mockSetup:          func(m *mocks.MockSessionStudentRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Student listed twice",
+			url:  "/sessions/" + sessionID.String() + "/attendance",
+			payload: `{"students": [{"student_id": "` + adaID.String() + `", "present": true},
+				{"student_id": "` + adaID.String() + `", "present": false}]}`,
+			mockSetup:          func(m *mocks.MockSessionStudentRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Category rated twice",
+			url:  "/sessions/" + sessionID.String() + "/attendance",
+			payload: `{"students": [{"student_id": "` + adaID.String() + `", "present": true, "ratings": [
+				{"category": "engagement", "level": "low", "description": "Tired"},
+				{"category": "engagement", "level": "high", "description": "Focused"}]}]}`,
+			mockSetup:          func(m *mocks.MockSessionStudentRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Invalid rating level",
+			url:                "/sessions/" + sessionID.String() + "/attendance",
+			payload:            `{"students": [{"student_id": "` + adaID.String() + `", "present": true, "ratings": [{"category": "engagement", "level": "extreme", "description": "Focused"}]}]}`,
+			mockSetup:          func(m *mocks.MockSessionStudentRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Nobody listed",
+			url:                "/sessions/" + sessionID.String() + "/attendance",
+			payload:            `{"students": []}`,
+			mockSetup:          func(m *mocks.MockSessionStudentRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Invalid session ID",
+			url:                "/sessions/abc/attendance",
+			payload:            `{"students": [{"student_id": "` + adaID.String() + `", "present": true}]}`,
+			mockSetup:          func(m *mocks.MockSessionStudentRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Student not in the session",
+			url:     "/sessions/" + sessionID.String() + "/attendance",
+			payload: `{"students": [{"student_id": "` + adaID.String() + `", "present": true}]}`,
+			mockSetup: func(m *mocks.MockSessionStudentRepository) {
+				m.On("SaveAttendance", mock.Anything, sessionID, mock.Anything).Return(nil, errs.NotFound("Session student", "student_id", adaID.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:    "Repository error",
+			url:     "/sessions/" + sessionID.String() + "/attendance",
+			payload: `{"students": [{"student_id": "` + adaID.String() + `", "present": true}]}`,
+			mockSetup: func(m *mocks.MockSessionStudentRepository) {
+				m.On("SaveAttendance", mock.Anything, sessionID, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockStudentRepo := new(mocks.MockSessionStudentRepository)
+			tt.mockSetup(mockStudentRepo)
+
+			handler := session.NewHandler(new(mocks.MockSessionRepository), mockStudentRepo, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
+			app.Put("/sessions/:id/attendance", handler.PutAttendance)
+
+			req := httptest.NewRequest("PUT", tt.url, strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			res, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedCount > 0 {
+				var roster []models.SessionStudentsOutput
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&roster))
+				assert.Len(t, roster, tt.expectedCount)
+			}
+			mockStudentRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -15,40 +15,85 @@ import (
 
 func (h *Handler) PatchSessions(c *fiber.Ctx) error {
 	var session models.PatchSessionInput
+	var query models.PatchSessionQuery
 
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return errs.BadRequest("Parsing Error with Invalid ID Format. ID: " + id.String())
 	}
 
+	if err := c.QueryParser(&query); err != nil {
+		return errs.BadRequest("Invalid query parameters")
+	}
+
 	if err := c.BodyParser(&session); err != nil {
 		return errs.InvalidJSON("Failed to parse PatchSessionInput data")
 	}
 
 	// Validate using XValidator
+	if validationErrors := h.validator.Validate(query); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
 	if validationErrors := h.validator.Validate(session); len(validationErrors) > 0 {
 		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
 	}
 
-	updatedSession, err := h.sessionRepository.PatchSession(c.Context(), id, &session)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return errs.NotFound("Session Not Found")
+	if session.Repetition != nil && !isSeriesScope(query.Scope) {
+		return errs.BadRequest("Repetition can only be changed with scope 'following' or 'all'")
+	}
+	if err := checkRepetition(session.Repetition); err != nil {
+		return err
+	}
+
+	if !c.QueryBool(allowConflictsQuery) && reschedules(&session) {
+		if err := h.checkPatchConflicts(c.Context(), id, query.Scope, &session); err != nil {
+			return err
 		}
+	}
 
-		slog.Error("Failed to patch session", "id", id, "err", err)
-		errStr := err.Error()
-		switch {
-		case strings.Contains(errStr, "foreign key"):
-			return errs.BadRequest("Invalid Reference")
-		case strings.Contains(errStr, "check constraint"):
-			return errs.BadRequest("Violated a check constraint")
-		case strings.Contains(errStr, "connection refused"):
-			return errs.InternalServerError("Database Connection Error")
-		default:
-			return errs.InternalServerError("Failed to Update Session")
+	if isSeriesScope(query.Scope) {
+		updatedSessions, err := h.sessionRepository.PatchRecurringSessions(c.Context(), id, query.Scope, &session)
+		if err != nil {
+			return patchSessionError(id, err)
 		}
+
+		return c.Status(fiber.StatusOK).JSON(updatedSessions)
+	}
+
+	updatedSession, err := h.sessionRepository.PatchSession(c.Context(), id, &session)
+	if err != nil {
+		return patchSessionError(id, err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(updatedSession)
 }
+
+// checkRepetition rejects a repetition rule that cannot be expanded into sessions
+func checkRepetition(rp *models.Repetition) error {
+	if rp == nil {
+		return nil
+	}
+	if err := rp.CheckRule(); err != nil {
+		return errs.BadRequest(err.Error())
+	}
+	return nil
+}
+
+func patchSessionError(id uuid.UUID, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errs.NotFound("Session Not Found")
+	}
+
+	slog.Error("Failed to patch session", "id", id, "err", err)
+	errStr := err.Error()
+	switch {
+	case strings.Contains(errStr, "foreign key"):
+		return errs.BadRequest("Invalid Reference")
+	case strings.Contains(errStr, "check constraint"):
+		return errs.BadRequest("Violated a check constraint")
+	case strings.Contains(errStr, "connection refused"):
+		return errs.InternalServerError("Database Connection Error")
+	default:
+		return errs.InternalServerError("Failed to Update Session")
+	}
+}
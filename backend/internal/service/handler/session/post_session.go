@@ -1,6 +1,8 @@
 package session
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"specialstandard/internal/errs"
@@ -20,10 +22,24 @@ func (h *Handler) PostSessions(c *fiber.Ctx) error {
 		return errs.InvalidJSON("Failed to parse PostSessionInput data")
 	}
 
+	template, err := h.sessionTemplate(c.Context(), &session)
+	if err != nil {
+		return err
+	}
+	if template != nil {
+		template.Fill(&session)
+	}
+
 	// Validate using XValidator
 	if validationErrors := h.validator.Validate(session); len(validationErrors) > 0 {
 		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
 	}
+	if err := checkRepetition(session.Repetition); err != nil {
+		return err
+	}
+	if err := h.checkMakeup(c.Context(), &session); err != nil {
+		return err
+	}
 
 	var sessionIDs []uuid.UUID
 	postSessionStudent := models.CreateSessionStudentInput{
@@ -41,6 +57,24 @@ func (h *Handler) PostSessions(c *fiber.Ctx) error {
 		}
 	}
 
+	// Expanded in the therapist's time zone, as PostSession does
+	loc, err := h.therapistLocation(c.Context(), session.TherapistID)
+	if err != nil {
+		return err
+	}
+	session.StartTime, session.EndTime = session.StartTime.In(loc), session.EndTime.In(loc)
+
+	if !c.QueryBool(allowConflictsQuery) {
+		err := h.checkConflicts(c.Context(), &models.ConflictCheck{
+			TherapistID: session.TherapistID,
+			StudentIDs:  postSessionStudent.StudentIDs,
+			Slots:       session.Occurrences(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	db := h.sessionRepository.GetDB()
 	if db == nil {
 		return errs.InternalServerError("Failed to GetDB")
@@ -101,6 +135,16 @@ func (h *Handler) PostSessions(c *fiber.Ctx) error {
 		}
 	}
 
+	if template != nil {
+		if err := h.sessionResourceRepository.AddSessionResources(c.Context(), tx, sessionIDs, template.ResourceIDs); err != nil {
+			if rollbackErr := tx.Rollback(c.Context()); rollbackErr != nil {
+				slog.Error("Rollback was not successful", "err", rollbackErr)
+			}
+			slog.Error("Failed to add the template's resources", "template_id", template.ID, "err", err)
+			return errs.InternalServerError("Failed to add the session template's resources")
+		}
+	}
+
 	err = tx.Commit(c.Context())
 	if err != nil {
 		return errs.InternalServerError("Failed to commit transaction")
@@ -108,3 +152,55 @@ func (h *Handler) PostSessions(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusCreated).JSON(newSessions)
 }
+
+// checkMakeup makes sure a makeup session is a single session, for students who are still
+// owed minutes for the session it makes up
+func (h *Handler) checkMakeup(ctx context.Context, session *models.PostSessionInput) error {
+	if session.MakeupForSessionID == nil {
+		return nil
+	}
+	if session.Repetition != nil {
+		return errs.BadRequest("A makeup session cannot repeat")
+	}
+	if session.StudentIDs == nil || len(*session.StudentIDs) == 0 {
+		return errs.BadRequest("A makeup session needs the students it makes up for")
+	}
+
+	owed, err := h.sessionRepository.GetOwedMinutes(ctx, *session.MakeupForSessionID)
+	if err != nil {
+		slog.Error("Failed to get owed minutes", "session_id", *session.MakeupForSessionID, "err", err)
+		return errs.InternalServerError("Failed to get owed minutes")
+	}
+	owedStudents := make(map[uuid.UUID]bool, len(owed))
+	for _, o := range owed {
+		owedStudents[o.StudentID] = true
+	}
+	for _, id := range *session.StudentIDs {
+		if !owedStudents[id] {
+			return errs.Conflict(fmt.Sprintf("Student %s is not owed minutes for session %s", id, *session.MakeupForSessionID))
+		}
+	}
+	return nil
+}
+
+// sessionTemplate loads the template the session is created from, if any. Templates are
+// the plans of the therapist they belong to.
+func (h *Handler) sessionTemplate(ctx context.Context, session *models.PostSessionInput) (*models.SessionTemplate, error) {
+	if session.TemplateID == nil {
+		return nil, nil
+	}
+
+	template, err := h.sessionTemplateRepository.GetTemplate(ctx, *session.TemplateID)
+	if err != nil {
+		var httpErr errs.HTTPError
+		if errors.As(err, &httpErr) {
+			return nil, httpErr
+		}
+		slog.Error("Failed to get session template", "template_id", *session.TemplateID, "err", err)
+		return nil, errs.InternalServerError("Failed to get session template")
+	}
+	if template.TherapistID != session.TherapistID {
+		return nil, errs.BadRequest("The session template belongs to another therapist")
+	}
+	return template, nil
+}
@@ -0,0 +1,41 @@
+package session
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// PutAttendance records the attendance, notes and ratings of the session's students all at
+// once, and returns the session's students as they now are
+func (h *Handler) PutAttendance(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid session ID format")
+	}
+
+	var input models.SessionAttendanceInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse SessionAttendanceInput data")
+	}
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	roster, err := h.sessionStudentRepository.SaveAttendance(c.Context(), id, &input)
+	if err != nil {
+		var httpErr errs.HTTPError
+		if errors.As(err, &httpErr) {
+			return httpErr
+		}
+		slog.Error("Failed to save attendance", "session_id", id, "err", err)
+		return errs.InternalServerError("Failed to save attendance")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(roster)
+}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CancelSession records that a session did not take place, and why. The session is kept
+// so service delivery reports can tell missed services apart from removed sessions.
+func (h *Handler) CancelSession(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid session ID format")
+	}
+
+	var input models.CancelSessionInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return errs.InvalidJSON("Failed to parse CancelSessionInput data")
+		}
+	}
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+	if input.Status == "" {
+		input.Status = models.SessionCancelledByTherapist
+	}
+
+	current, err := h.sessionRepository.GetSessionByID(c.Context(), id.String())
+	if err != nil {
+		return sessionStatusError(id, err)
+	}
+	if current.Status == models.SessionCompleted {
+		return errs.Conflict("A completed session cannot be cancelled, restore it first")
+	}
+
+	session, err := h.sessionRepository.UpdateSessionStatus(c.Context(), id, input.Status, input.Reason)
+	if err != nil {
+		return sessionStatusError(id, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(session)
+}
+
+// RestoreSession puts a session back on the schedule, as a makeup if it was one. The time
+// of a cancelled session may have been booked since, so it is checked for conflicts again.
+func (h *Handler) RestoreSession(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid session ID format")
+	}
+
+	current, err := h.sessionRepository.GetSessionByID(c.Context(), id.String())
+	if err != nil {
+		return sessionStatusError(id, err)
+	}
+	status := models.SessionScheduled
+	if current.MakeupForSessionID != nil {
+		status = models.SessionMakeup
+	}
+	if current.Status == status {
+		return c.Status(fiber.StatusOK).JSON(current)
+	}
+
+	if current.Cancelled() && !c.QueryBool(allowConflictsQuery) {
+		if err := h.checkConflicts(c.Context(), &models.ConflictCheck{
+			TherapistID: current.TherapistID,
+			SessionIDs:  []uuid.UUID{id},
+			Slots:       []models.TimeSlot{{Start: current.StartDateTime, End: current.EndDateTime}},
+		}); err != nil {
+			return err
+		}
+	}
+
+	session, err := h.sessionRepository.UpdateSessionStatus(c.Context(), id, status, nil)
+	if err != nil {
+		return sessionStatusError(id, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(session)
+}
+
+func sessionStatusError(id uuid.UUID, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errs.NotFound("Session Not Found")
+	}
+
+	slog.Error("Failed to update session status", "id", id, "err", err)
+	return errs.InternalServerError("Failed to update session status")
+}
@@ -0,0 +1,22 @@
+package session_import
+
+import (
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+)
+
+type Handler struct {
+	sessionRepository        storage.SessionRepository
+	sessionStudentRepository storage.SessionStudentRepository
+	studentRepository        storage.StudentRepository
+	validator                *xvalidator.XValidator
+}
+
+func NewHandler(sessionRepository storage.SessionRepository, sessionStudentRepository storage.SessionStudentRepository, studentRepository storage.StudentRepository) *Handler {
+	return &Handler{
+		sessionRepository:        sessionRepository,
+		sessionStudentRepository: sessionStudentRepository,
+		studentRepository:        studentRepository,
+		validator:                xvalidator.Validator,
+	}
+}
@@ -0,0 +1,343 @@
+package session_import_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/session_import"
+	"specialstandard/internal/storage/mocks"
+	"specialstandard/internal/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var therapistID = uuid.MustParse("0b8e7a6c-2f4d-4c1e-9a3b-5d6f7e8a9b0c")
+
+// Tuesdays 9:00-9:30 in New York for four weeks, the third moved to Wednesday, plus a
+// monthly staff meeting and an all-day event
+const calendarFile = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:fluency@example.com\r\n" +
+	"DTSTART;TZID=America/New_York:20251028T090000\r\n" +
+	"DTEND;TZID=America/New_York:20251028T093000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=TU;COUNT=4\r\n" +
+	"SUMMARY:Fluency group\r\n" +
+	"LOCATION:Room 12\r\n" +
+	"ATTENDEE;CN=Ada Lovelace:mailto:ada@example.com\r\n" +
+	"ATTENDEE;CN=Grace:mailto:grace@example.com\r\n" +
+	"ATTENDEE;CN=Parent Volunteer:mailto:parent@example.com\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:fluency@example.com\r\n" +
+	"RECURRENCE-ID;TZID=America/New_York:20251111T090000\r\n" +
+	"DTSTART;TZID=America/New_York:20251112T090000\r\n" +
+	"DTEND;TZID=America/New_York:20251112T093000\r\n" +
+	"SUMMARY:Fluency group\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:staff@example.com\r\n" +
+	"DTSTART:20251104T200000Z\r\n" +
+	"DURATION:PT1H\r\n" +
+	"RRULE:FREQ=MONTHLY;BYDAY=1TU;COUNT=2\r\n" +
+	"SUMMARY:Staff meeting\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20251127\r\n" +
+	"SUMMARY:Thanksgiving\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+const from = "2025-10-01T00:00:00Z"
+
+func newApp(h *session_import.Handler) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Post("/sessions/import/preview", h.PreviewImport)
+	app.Post("/sessions/import", h.ImportSessions)
+	return app
+}
+
+// newSessionRepository is a repository mock for a therapist scheduling in UTC
+func newSessionRepository() *mocks.MockSessionRepository {
+	m := new(mocks.MockSessionRepository)
+	m.On("GetTherapistLocation", mock.Anything, therapistID).Return(time.UTC, nil).Maybe()
+	return m
+}
+
+func newRequest(t *testing.T, url, body string, multipartUpload bool) *http.Request {
+	if !multipartUpload {
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/calendar")
+		return req
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", "schedule.ics")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest("POST", url, &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestHandler_PreviewImport(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	adaID, graceID := uuid.New(), uuid.New()
+	students := []models.Student{
+		{ID: adaID, FirstName: "Ada", LastName: "Lovelace"},
+		{ID: graceID, FirstName: "Grace", LastName: "Hopper"},
+		{ID: uuid.New(), FirstName: "Alan", LastName: "Turing"},
+	}
+	existing := models.SessionConflict{
+		SessionID:     uuid.New(),
+		SessionName:   "Evaluation",
+		StartDateTime: time.Date(2025, 11, 4, 9, 15, 0, 0, newYork),
+		EndDateTime:   time.Date(2025, 11, 4, 10, 0, 0, 0, newYork),
+	}
+
+	t.Run("weekly series, overrides, conflicts and students", func(t *testing.T) {
+		sessionRepo := newSessionRepository()
+		studentRepo := new(mocks.MockStudentRepository)
+		sessionRepo.On("GetSessionConflicts", mock.Anything, therapistID, mock.Anything, mock.Anything).
+			Return([]models.SessionConflict{existing}, nil)
+		studentRepo.On("GetStudents", mock.Anything, (*int)(nil), (*int)(nil), therapistID, "", utils.Pagination{Page: 1, Limit: 1000}).
+			Return(students, nil)
+
+		app := newApp(session_import.NewHandler(sessionRepo, new(mocks.MockSessionStudentRepository), studentRepo))
+		url := "/sessions/import/preview?therapist_id=" + therapistID.String() + "&match_students=true&from=" + from
+		resp, err := app.Test(newRequest(t, url, calendarFile, true), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var preview models.SessionImportPreview
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&preview))
+		require.Len(t, preview.Sessions, 3)
+
+		// The moved instance leaves a gap, so the series is imported session by session
+		weekly := preview.Sessions[0]
+		assert.Equal(t, "Fluency group", weekly.SessionName)
+		assert.Equal(t, "Room 12", *weekly.Location)
+		assert.Nil(t, weekly.Repetition)
+		require.Len(t, weekly.Occurrences, 3)
+		assert.True(t, weekly.Occurrences[2].StartDateTime.Equal(time.Date(2025, 11, 18, 9, 0, 0, 0, newYork)))
+		assert.Equal(t, []uuid.UUID{adaID, graceID}, weekly.StudentIDs)
+		assert.Equal(t, []string{"Parent Volunteer"}, weekly.UnmatchedAttendees)
+
+		// 11/4 9:00-9:30 overlaps the evaluation at 9:15
+		assert.Empty(t, weekly.Occurrences[0].Conflicts)
+		require.Len(t, weekly.Occurrences[1].Conflicts, 1)
+		assert.Equal(t, existing.SessionID, weekly.Occurrences[1].Conflicts[0].SessionID)
+
+		moved := preview.Sessions[1]
+		require.Len(t, moved.Occurrences, 1)
+		assert.True(t, moved.Occurrences[0].StartDateTime.Equal(time.Date(2025, 11, 12, 9, 0, 0, 0, newYork)))
+
+		staff := preview.Sessions[2]
+		assert.Nil(t, staff.Repetition)
+		require.Len(t, staff.Occurrences, 2)
+		assert.True(t, staff.Occurrences[1].StartDateTime.Equal(time.Date(2025, 12, 2, 20, 0, 0, 0, time.UTC)))
+		assert.Equal(t, time.Hour, staff.Occurrences[1].EndDateTime.Sub(staff.Occurrences[1].StartDateTime))
+
+		require.Len(t, preview.Skipped, 1)
+		assert.Equal(t, "holiday@example.com", preview.Skipped[0].UID)
+
+		sessionRepo.AssertExpectations(t)
+		studentRepo.AssertExpectations(t)
+	})
+
+	t.Run("plain weekly series becomes a repetition", func(t *testing.T) {
+		file := strings.Replace(calendarFile, "RECURRENCE-ID", "X-RECURRENCE-ID", 1)
+		sessionRepo := newSessionRepository()
+		sessionRepo.On("GetSessionConflicts", mock.Anything, therapistID, mock.Anything, mock.Anything).
+			Return([]models.SessionConflict{}, nil)
+
+		app := newApp(session_import.NewHandler(sessionRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockStudentRepository)))
+		resp, err := app.Test(newRequest(t, "/sessions/import/preview?therapist_id="+therapistID.String()+"&from="+from, file, false), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var preview models.SessionImportPreview
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&preview))
+		weekly := preview.Sessions[0]
+		require.NotNil(t, weekly.Repetition)
+		assert.Equal(t, []int{int(time.Tuesday)}, weekly.Repetition.Days)
+		assert.Equal(t, 1, weekly.Repetition.EveryNWeeks)
+		assert.Len(t, weekly.Occurrences, 4)
+		// Attendees are only looked at when asked to
+		assert.Empty(t, weekly.StudentIDs)
+	})
+
+	t.Run("only occurrences from the given time", func(t *testing.T) {
+		sessionRepo := newSessionRepository()
+		sessionRepo.On("GetSessionConflicts", mock.Anything, therapistID, mock.Anything, mock.Anything).
+			Return([]models.SessionConflict{}, nil)
+
+		app := newApp(session_import.NewHandler(sessionRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockStudentRepository)))
+		resp, err := app.Test(newRequest(t, "/sessions/import/preview?therapist_id="+therapistID.String()+"&from=2025-11-20T00:00:00Z", calendarFile, false), -1)
+		require.NoError(t, err)
+
+		var preview models.SessionImportPreview
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&preview))
+		require.Len(t, preview.Sessions, 1)
+		assert.Equal(t, "Staff meeting", preview.Sessions[0].SessionName)
+	})
+
+	t.Run("floating times are in the therapist's zone", func(t *testing.T) {
+		file := "BEGIN:VCALENDAR\r\n" +
+			"VERSION:2.0\r\n" +
+			"BEGIN:VEVENT\r\n" +
+			"UID:floating@example.com\r\n" +
+			"DTSTART:20251104T090000\r\n" +
+			"DTEND:20251104T093000\r\n" +
+			"SUMMARY:Articulation\r\n" +
+			"END:VEVENT\r\n" +
+			"END:VCALENDAR\r\n"
+		sessionRepo := new(mocks.MockSessionRepository)
+		sessionRepo.On("GetTherapistLocation", mock.Anything, therapistID).Return(newYork, nil)
+		sessionRepo.On("GetSessionConflicts", mock.Anything, therapistID, mock.Anything, mock.Anything).
+			Return([]models.SessionConflict{}, nil)
+
+		app := newApp(session_import.NewHandler(sessionRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockStudentRepository)))
+		resp, err := app.Test(newRequest(t, "/sessions/import/preview?therapist_id="+therapistID.String()+"&from="+from, file, false), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var preview models.SessionImportPreview
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&preview))
+		require.Len(t, preview.Sessions, 1)
+		require.Len(t, preview.Sessions[0].Occurrences, 1)
+		assert.True(t, preview.Sessions[0].Occurrences[0].StartDateTime.Equal(time.Date(2025, 11, 4, 14, 0, 0, 0, time.UTC)))
+		sessionRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name           string
+		url            string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "not a calendar",
+			url:            "/sessions/import/preview?therapist_id=" + therapistID.String(),
+			body:           "name,start\nAda,9:00\n",
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "no file",
+			url:            "/sessions/import/preview?therapist_id=" + therapistID.String(),
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "missing therapist",
+			url:            "/sessions/import/preview",
+			body:           calendarFile,
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "unknown time zone",
+			url:            "/sessions/import/preview?therapist_id=" + therapistID.String() + "&tz=Mars/Olympus",
+			body:           calendarFile,
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApp(session_import.NewHandler(newSessionRepository(), new(mocks.MockSessionStudentRepository), new(mocks.MockStudentRepository)))
+			resp, err := app.Test(newRequest(t, tt.url, tt.body, false), -1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestHandler_ImportSessions(t *testing.T) {
+	t.Run("conflict check fails", func(t *testing.T) {
+		sessionRepo := newSessionRepository()
+		sessionRepo.On("GetSessionConflicts", mock.Anything, therapistID, mock.Anything, mock.Anything).
+			Return(nil, errors.New("db down"))
+
+		app := newApp(session_import.NewHandler(sessionRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockStudentRepository)))
+		resp, err := app.Test(newRequest(t, "/sessions/import?therapist_id="+therapistID.String()+"&from="+from, calendarFile, false), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		sessionRepo.AssertNotCalled(t, "PostSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("no database", func(t *testing.T) {
+		sessionRepo := newSessionRepository()
+		sessionRepo.On("GetSessionConflicts", mock.Anything, therapistID, mock.Anything, mock.Anything).
+			Return([]models.SessionConflict{}, nil)
+		sessionRepo.On("GetDB").Return((*pgxpool.Pool)(nil))
+
+		app := newApp(session_import.NewHandler(sessionRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockStudentRepository)))
+		resp, err := app.Test(newRequest(t, "/sessions/import?therapist_id="+therapistID.String()+"&from="+from, calendarFile, true), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+
+	t.Run("overlaps existing sessions", func(t *testing.T) {
+		existing := models.SessionConflict{
+			SessionID:     uuid.New(),
+			SessionName:   "Articulation",
+			StartDateTime: time.Date(2025, 10, 28, 13, 0, 0, 0, time.UTC),
+			EndDateTime:   time.Date(2025, 10, 28, 14, 0, 0, 0, time.UTC),
+			TherapistID:   therapistID,
+		}
+		sessionRepo := newSessionRepository()
+		sessionRepo.On("GetSessionConflicts", mock.Anything, therapistID, mock.Anything, mock.Anything).
+			Return([]models.SessionConflict{existing}, nil)
+
+		app := newApp(session_import.NewHandler(sessionRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockStudentRepository)))
+		resp, err := app.Test(newRequest(t, "/sessions/import?therapist_id="+therapistID.String()+"&from="+from, calendarFile, false), -1)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+		var body struct {
+			Message models.SchedulingConflicts `json:"message"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Len(t, body.Message.Conflicts, 1)
+		assert.Equal(t, existing.SessionID, body.Message.Conflicts[0].SessionID)
+		sessionRepo.AssertNotCalled(t, "GetDB")
+	})
+
+	t.Run("overlaps allowed", func(t *testing.T) {
+		sessionRepo := newSessionRepository()
+		sessionRepo.On("GetSessionConflicts", mock.Anything, therapistID, mock.Anything, mock.Anything).
+			Return([]models.SessionConflict{{
+				SessionID:     uuid.New(),
+				StartDateTime: time.Date(2025, 10, 28, 13, 0, 0, 0, time.UTC),
+				EndDateTime:   time.Date(2025, 10, 28, 14, 0, 0, 0, time.UTC),
+			}}, nil)
+		sessionRepo.On("GetDB").Return((*pgxpool.Pool)(nil))
+
+		app := newApp(session_import.NewHandler(sessionRepo, new(mocks.MockSessionStudentRepository), new(mocks.MockStudentRepository)))
+		resp, err := app.Test(newRequest(t, "/sessions/import?allow_conflicts=true&therapist_id="+therapistID.String()+"&from="+from, calendarFile, false), -1)
+		require.NoError(t, err)
+		// Gets as far as the transaction
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		sessionRepo.AssertCalled(t, "GetDB")
+	})
+}
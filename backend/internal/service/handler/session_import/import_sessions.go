@@ -0,0 +1,179 @@
+package session_import
+
+import (
+	"io"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// maxFileSize is the largest calendar accepted, a year of a busy schedule is far smaller
+const maxFileSize = 2 << 20
+
+// PreviewImport shows the sessions an .ics file would create, and the existing sessions
+// they overlap, without saving anything
+func (h *Handler) PreviewImport(c *fiber.Ctx) error {
+	query, therapistID, data, err := h.parseImport(c)
+	if err != nil {
+		return err
+	}
+
+	preview, err := h.preview(c.Context(), data, therapistID, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(preview)
+}
+
+// ImportSessions creates the sessions PreviewImport shows, all of them or none. The file
+// is read again rather than trusting a preview sent back by the client. Nothing is
+// imported over existing sessions unless allow_conflicts is set.
+func (h *Handler) ImportSessions(c *fiber.Ctx) error {
+	query, therapistID, data, err := h.parseImport(c)
+	if err != nil {
+		return err
+	}
+
+	preview, err := h.preview(c.Context(), data, therapistID, query)
+	if err != nil {
+		return err
+	}
+
+	if conflicts := previewConflicts(preview); len(conflicts) > 0 && !c.QueryBool("allow_conflicts") {
+		return errs.ConflictDetails(models.SchedulingConflicts{
+			Error:     "Imported sessions overlap existing sessions of the therapist",
+			Conflicts: conflicts,
+		})
+	}
+
+	db := h.sessionRepository.GetDB()
+	if db == nil {
+		return errs.InternalServerError("Failed to GetDB")
+	}
+
+	tx, err := db.Begin(c.Context())
+	if err != nil {
+		return errs.InternalServerError("Failed to start transaction")
+	}
+	defer func() {
+		// Does nothing once committed
+		_ = tx.Rollback(c.Context())
+	}()
+
+	result := models.SessionImportResult{
+		Sessions: []models.Session{},
+		Skipped:  preview.Skipped,
+	}
+	for _, imported := range preview.Sessions {
+		for _, input := range sessionInputs(imported, therapistID) {
+			created, err := h.sessionRepository.PostSession(c.Context(), tx, &input)
+			if err != nil {
+				slog.Error("Failed to import session", "uid", imported.UID, "err", err)
+				return errs.InternalServerError("Failed to import sessions")
+			}
+
+			if len(imported.StudentIDs) > 0 {
+				sessionIDs := make([]uuid.UUID, len(*created))
+				for i, s := range *created {
+					sessionIDs[i] = s.ID
+				}
+				_, err = h.sessionStudentRepository.CreateSessionStudent(c.Context(), tx, &models.CreateSessionStudentInput{
+					SessionIDs: sessionIDs,
+					StudentIDs: imported.StudentIDs,
+					Present:    true,
+				})
+				if err != nil {
+					slog.Error("Failed to add students to imported session", "uid", imported.UID, "err", err)
+					return errs.InternalServerError("Failed to import sessions")
+				}
+			}
+
+			result.Sessions = append(result.Sessions, *created...)
+		}
+	}
+
+	if err := tx.Commit(c.Context()); err != nil {
+		return errs.InternalServerError("Failed to commit transaction")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+// previewConflicts lists each existing session the import overlaps once
+func previewConflicts(preview *models.SessionImportPreview) []models.SessionConflict {
+	var conflicts []models.SessionConflict
+	seen := make(map[uuid.UUID]bool)
+	for _, s := range preview.Sessions {
+		for _, occ := range s.Occurrences {
+			for _, conflict := range occ.Conflicts {
+				if !seen[conflict.SessionID] {
+					seen[conflict.SessionID] = true
+					conflicts = append(conflicts, conflict)
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+func (h *Handler) parseImport(c *fiber.Ctx) (models.SessionImportQuery, uuid.UUID, []byte, error) {
+	var query models.SessionImportQuery
+	if err := c.QueryParser(&query); err != nil {
+		return query, uuid.Nil, nil, errs.BadRequest("Invalid query parameters")
+	}
+	if validationErrors := h.validator.Validate(query); len(validationErrors) > 0 {
+		return query, uuid.Nil, nil, errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	therapistID, err := uuid.Parse(query.TherapistID)
+	if err != nil {
+		return query, uuid.Nil, nil, errs.BadRequest("Invalid therapist ID format")
+	}
+
+	data, err := readCalendar(c)
+	if err != nil {
+		return query, uuid.Nil, nil, err
+	}
+
+	return query, therapistID, data, nil
+}
+
+// readCalendar takes the file from the "file" field of a multipart upload, or the raw
+// body when it is sent as text/calendar
+func readCalendar(c *fiber.Ctx) ([]byte, error) {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		if len(c.Body()) == 0 {
+			return nil, errs.BadRequest("Upload an .ics file")
+		}
+		if len(c.Body()) > maxFileSize {
+			return nil, errs.BadRequest("Calendar file is too large")
+		}
+		return c.Body(), nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, errs.BadRequest("Upload an .ics file in the file field")
+	}
+	if header.Size > maxFileSize {
+		return nil, errs.BadRequest("Calendar file is too large")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, errs.BadRequest("Failed to read the uploaded file")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxFileSize))
+	if err != nil {
+		return nil, errs.BadRequest("Failed to read the uploaded file")
+	}
+	return data, nil
+}
@@ -0,0 +1,337 @@
+package session_import
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/ical"
+	"specialstandard/internal/models"
+	"specialstandard/internal/utils"
+	"specialstandard/internal/xvalidator"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// maxOccurrences caps how many sessions a single event can become
+	maxOccurrences = 500
+	// maxStudents is how much of the caseload attendee names are matched against
+	maxStudents = 1000
+	// defaultSessionName is used for events without a SUMMARY
+	defaultSessionName = "Imported session"
+)
+
+// preview works out the sessions an import would create. Occurrences are imported from
+// from for a year, recurring events going on past that are marked as truncated.
+func (h *Handler) preview(ctx context.Context, data []byte, therapistID uuid.UUID, query models.SessionImportQuery) (*models.SessionImportPreview, error) {
+	loc, err := h.location(ctx, therapistID, query.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	events, problems, err := ical.Parse(bytes.NewReader(data), loc)
+	if err != nil {
+		return nil, errs.BadRequest("Invalid iCalendar file: " + err.Error())
+	}
+
+	from := time.Now()
+	if query.From != nil {
+		from = *query.From
+	}
+	to := from.AddDate(1, 0, 0)
+
+	preview := &models.SessionImportPreview{
+		Sessions: []models.ImportedSession{},
+		Skipped:  []models.SkippedEvent{},
+	}
+	for _, p := range problems {
+		preview.Skipped = append(preview.Skipped, models.SkippedEvent{UID: p.UID, Summary: p.Summary, Reason: p.Reason})
+	}
+
+	var students []models.Student
+	if query.MatchStudents {
+		students, err = h.studentRepository.GetStudents(ctx, nil, nil, therapistID, "", utils.Pagination{Page: 1, Limit: maxStudents})
+		if err != nil {
+			slog.Error("Failed to load students for import", "therapist_id", therapistID, "err", err)
+			return nil, errs.InternalServerError("Failed to match attendees to students")
+		}
+	}
+
+	// Edited instances of a recurring event come as separate events with a RECURRENCE-ID,
+	// they replace that instance of the series
+	overridden := make(map[string][]time.Time)
+	for _, e := range events {
+		if !e.RecurrenceID.IsZero() {
+			overridden[e.UID] = append(overridden[e.UID], e.RecurrenceID)
+		}
+	}
+
+	for _, e := range events {
+		if e.RecurrenceID.IsZero() {
+			e.ExceptDates = append(e.ExceptDates, overridden[e.UID]...)
+		}
+
+		skip := func(reason string) {
+			preview.Skipped = append(preview.Skipped, models.SkippedEvent{UID: e.UID, Summary: e.Summary, Reason: reason})
+		}
+
+		switch {
+		case e.Status == "CANCELLED":
+			skip("Event is cancelled")
+			continue
+		case e.AllDay:
+			skip("All-day events are not imported")
+			continue
+		case !e.End.After(e.Start):
+			skip("Event has no duration")
+			continue
+		}
+
+		starts, truncated := e.Occurrences(from, to, maxOccurrences)
+		if len(starts) == 0 {
+			skip("Event has no occurrences after " + from.Format(time.DateOnly))
+			continue
+		}
+
+		session := importedSession(e, starts, truncated)
+		if query.MatchStudents {
+			session.StudentIDs, session.UnmatchedAttendees = matchAttendees(e.Attendees, students)
+		}
+
+		// Checked the way POST /sessions would check it
+		if validationErrors := h.validator.Validate(sessionInputs(session, therapistID)[0]); len(validationErrors) > 0 {
+			var reasons []string
+			for field, message := range xvalidator.ConvertToMessages(validationErrors) {
+				reasons = append(reasons, field+": "+message)
+			}
+			slices.Sort(reasons)
+			skip(strings.Join(reasons, ", "))
+			continue
+		}
+
+		preview.Sessions = append(preview.Sessions, session)
+	}
+
+	if err := h.findConflicts(ctx, therapistID, preview.Sessions); err != nil {
+		slog.Error("Failed to check import for conflicts", "therapist_id", therapistID, "err", err)
+		return nil, errs.InternalServerError("Failed to check for conflicting sessions")
+	}
+
+	return preview, nil
+}
+
+func importedSession(e ical.Event, starts []time.Time, truncated bool) models.ImportedSession {
+	duration := e.End.Sub(e.Start)
+	session := models.ImportedSession{
+		UID:                e.UID,
+		SessionName:        strings.TrimSpace(e.Summary),
+		Location:           optionalText(e.Location),
+		Notes:              optionalText(e.Description),
+		Repetition:         weeklyRepetition(e, starts, duration),
+		StudentIDs:         []uuid.UUID{},
+		UnmatchedAttendees: []string{},
+		Truncated:          truncated,
+	}
+	if session.SessionName == "" {
+		session.SessionName = defaultSessionName
+	}
+
+	for _, start := range starts {
+		session.Occurrences = append(session.Occurrences, models.ImportedOccurrence{
+			StartDateTime: start,
+			EndDateTime:   start.Add(duration),
+			Conflicts:     []models.SessionConflict{},
+		})
+	}
+
+	return session
+}
+
+// weeklyRepetition expresses the occurrences as a session repetition when it generates
+// exactly them, otherwise each occurrence is imported as its own session
+func weeklyRepetition(e ical.Event, starts []time.Time, duration time.Duration) *models.Repetition {
+	r := e.Rule
+	if r == nil || r.Freq != ical.Weekly || len(r.ByMonth) > 0 || len(r.ByMonthDay) > 0 ||
+		len(e.ExtraDates) > 0 || len(starts) < 2 {
+		return nil
+	}
+
+	var days []int
+	for _, start := range starts {
+		if !slices.Contains(days, int(start.Weekday())) {
+			days = append(days, int(start.Weekday()))
+		}
+	}
+	slices.Sort(days)
+
+	rp := &models.Repetition{
+		RecurStart:  starts[0],
+		RecurEnd:    starts[len(starts)-1],
+		EveryNWeeks: max(r.Interval, 1),
+		Days:        days,
+	}
+
+	expected := rp.Occurrences(starts[0], starts[0].Add(duration), time.Time{})
+	if len(expected) != len(starts) {
+		return nil
+	}
+	for i, occ := range expected {
+		if !occ[0].Equal(starts[i]) {
+			return nil
+		}
+	}
+
+	return rp
+}
+
+// sessionInputs is what PostSession is called with to create an imported session
+func sessionInputs(s models.ImportedSession, therapistID uuid.UUID) []models.PostSessionInput {
+	input := models.PostSessionInput{
+		SessionName: s.SessionName,
+		TherapistID: therapistID,
+		Notes:       s.Notes,
+		Location:    s.Location,
+	}
+
+	if s.Repetition != nil {
+		input.StartTime = s.Occurrences[0].StartDateTime
+		input.EndTime = s.Occurrences[0].EndDateTime
+		input.Repetition = s.Repetition
+		return []models.PostSessionInput{input}
+	}
+
+	inputs := make([]models.PostSessionInput, len(s.Occurrences))
+	for i, occ := range s.Occurrences {
+		inputs[i] = input
+		inputs[i].StartTime = occ.StartDateTime
+		inputs[i].EndTime = occ.EndDateTime
+	}
+	return inputs
+}
+
+// findConflicts fills in the existing sessions each occurrence overlaps
+func (h *Handler) findConflicts(ctx context.Context, therapistID uuid.UUID, sessions []models.ImportedSession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	from, to := sessions[0].Occurrences[0].StartDateTime, sessions[0].Occurrences[0].EndDateTime
+	for _, s := range sessions {
+		for _, occ := range s.Occurrences {
+			if occ.StartDateTime.Before(from) {
+				from = occ.StartDateTime
+			}
+			if occ.EndDateTime.After(to) {
+				to = occ.EndDateTime
+			}
+		}
+	}
+
+	existing, err := h.sessionRepository.GetSessionConflicts(ctx, therapistID, from, to)
+	if err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		for j := range sessions[i].Occurrences {
+			occ := &sessions[i].Occurrences[j]
+			for _, c := range existing {
+				if occ.StartDateTime.Before(c.EndDateTime) && occ.EndDateTime.After(c.StartDateTime) {
+					occ.Conflicts = append(occ.Conflicts, c)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchAttendees finds the students named by an event's attendees, by full name or by a
+// first name only one student has. Attendees that match nobody are returned by name.
+func matchAttendees(attendees []ical.Attendee, students []models.Student) ([]uuid.UUID, []string) {
+	ids := []uuid.UUID{}
+	unmatched := []string{}
+
+	for _, a := range attendees {
+		name := normalizeName(a.Name)
+		if name == "" {
+			unmatched = append(unmatched, a.Email)
+			continue
+		}
+		// "Lovelace, Ada" as some directories write it
+		if last, first, ok := strings.Cut(name, ","); ok {
+			name = normalizeName(first + " " + last)
+		}
+
+		id, err := matchStudent(name, students)
+		if err != nil {
+			unmatched = append(unmatched, strings.TrimSpace(a.Name))
+			continue
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, unmatched
+}
+
+var errNoMatch = errors.New("no single matching student")
+
+func matchStudent(name string, students []models.Student) (uuid.UUID, error) {
+	var byFirstName []uuid.UUID
+	for _, s := range students {
+		first := normalizeName(s.FirstName)
+		if name == fmt.Sprintf("%s %s", first, normalizeName(s.LastName)) {
+			return s.ID, nil
+		}
+		if name == first {
+			byFirstName = append(byFirstName, s.ID)
+		}
+	}
+
+	if len(byFirstName) == 1 {
+		return byFirstName[0], nil
+	}
+	return uuid.Nil, errNoMatch
+}
+
+func normalizeName(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func optionalText(s string) *string {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	return &s
+}
+
+// location is the zone for times the file does not give one for, the therapist's own
+// unless the import names another
+func (h *Handler) location(ctx context.Context, therapistID uuid.UUID, timeZone string) (*time.Location, error) {
+	if timeZone != "" {
+		loc, err := time.LoadLocation(timeZone)
+		if err != nil {
+			return nil, errs.BadRequest("Unknown time zone")
+		}
+		return loc, nil
+	}
+
+	loc, err := h.sessionRepository.GetTherapistLocation(ctx, therapistID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.BadRequest("Invalid Reference")
+	}
+	if err != nil {
+		slog.Error("Failed to get therapist time zone", "therapist_id", therapistID, "err", err)
+		return nil, errs.InternalServerError("Failed to get therapist time zone")
+	}
+	return loc, nil
+}
@@ -0,0 +1,19 @@
+package session_note
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeleteNote handles DELETE /sessions/:id/students/:studentId/notes, for drafts only
+func (h *Handler) DeleteNote(c *fiber.Ctx) error {
+	sessionID, studentID, err := noteParams(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.sessionNoteRepository.DeleteNote(c.Context(), sessionID, studentID); err != nil {
+		return noteError(err, sessionID, studentID, "Failed to delete session note")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,20 @@
+package session_note
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetNote handles GET /sessions/:id/students/:studentId/notes
+func (h *Handler) GetNote(c *fiber.Ctx) error {
+	sessionID, studentID, err := noteParams(c)
+	if err != nil {
+		return err
+	}
+
+	note, err := h.sessionNoteRepository.GetNote(c.Context(), sessionID, studentID)
+	if err != nil {
+		return noteError(err, sessionID, studentID, "Failed to get session note")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(note)
+}
@@ -0,0 +1,47 @@
+package session_note
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	sessionNoteRepository storage.SessionNoteRepository
+	validator             *xvalidator.XValidator
+}
+
+func NewHandler(sessionNoteRepository storage.SessionNoteRepository) *Handler {
+	return &Handler{
+		sessionNoteRepository: sessionNoteRepository,
+		validator:             xvalidator.Validator,
+	}
+}
+
+// noteParams reads the session and student a note is for from the path
+func noteParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errs.BadRequest("Invalid UUID format for session ID")
+	}
+	studentID, err := uuid.Parse(c.Params("studentId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errs.BadRequest("Invalid UUID format for student ID")
+	}
+	return sessionID, studentID, nil
+}
+
+func noteError(err error, sessionID, studentID uuid.UUID, message string) error {
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	slog.Error(message, "session_id", sessionID, "student_id", studentID, "err", err)
+	return errs.InternalServerError(message)
+}
@@ -0,0 +1,305 @@
+package session_note_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/session_note"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	callerID  = uuid.MustParse("0b7c6a5e-2f4d-4e8a-9c1b-3d2e1f0a9b8c")
+	sessionID = uuid.New()
+	studentID = uuid.New()
+)
+
+func newApp(repo *mocks.MockSessionNoteRepository) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", callerID.String())
+		return c.Next()
+	})
+
+	handler := session_note.NewHandler(repo)
+	app.Get("/sessions/:id/students/:studentId/notes", handler.GetNote)
+	app.Put("/sessions/:id/students/:studentId/notes", handler.SaveNote)
+	app.Post("/sessions/:id/students/:studentId/notes/sign", handler.SignNote)
+	app.Delete("/sessions/:id/students/:studentId/notes", handler.DeleteNote)
+	return app
+}
+
+func ptrString(s string) *string {
+	return &s
+}
+
+func draftNote() *models.SessionNote {
+	return &models.SessionNote{
+		ID:               uuid.New(),
+		SessionStudentID: 7,
+		SessionID:        sessionID,
+		StudentID:        studentID,
+		Subjective:       ptrString("Reported practicing /r/ at home"),
+		Objective:        ptrString("80% accuracy on initial /r/ in words"),
+		CustomFields:     map[string]string{},
+		Status:           models.NoteDraft,
+	}
+}
+
+func signedNote() *models.SessionNote {
+	note := draftNote()
+	signedAt := time.Date(2025, 9, 2, 15, 0, 0, 0, time.UTC)
+	note.Status = models.NoteSigned
+	note.SignedAt = &signedAt
+	note.SignedBy = &callerID
+	return note
+}
+
+func TestHandler_GetNote(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func(*mocks.MockSessionNoteRepository)
+		expectedStatus int
+	}{
+		{
+			name: "draft note",
+			url:  "/sessions/" + sessionID.String() + "/students/" + studentID.String() + "/notes",
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("GetNote", mock.Anything, sessionID, studentID).Return(draftNote(), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "no note yet",
+			url:  "/sessions/" + sessionID.String() + "/students/" + studentID.String() + "/notes",
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("GetNote", mock.Anything, sessionID, studentID).Return(nil, errs.NotFound("Session note not found"))
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "invalid student ID",
+			url:            "/sessions/" + sessionID.String() + "/students/not-a-uuid/notes",
+			mockSetup:      func(m *mocks.MockSessionNoteRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "repository error",
+			url:  "/sessions/" + sessionID.String() + "/students/" + studentID.String() + "/notes",
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("GetNote", mock.Anything, sessionID, studentID).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSessionNoteRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_SaveNote(t *testing.T) {
+	url := "/sessions/" + sessionID.String() + "/students/" + studentID.String() + "/notes"
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.MockSessionNoteRepository)
+		expectedStatus int
+	}{
+		{
+			name: "sections and custom fields",
+			body: `{"subjective": "Reported practicing /r/ at home", "plan": "Move to phrases",
+				"custom_fields": {"Parent contact": "Called mom"}}`,
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("SaveNote", mock.Anything, sessionID, studentID, &models.SaveSessionNoteInput{
+					Subjective:   ptrString("Reported practicing /r/ at home"),
+					Plan:         ptrString("Move to phrases"),
+					CustomFields: map[string]string{"Parent contact": "Called mom"},
+				}).Return(draftNote(), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "signed note",
+			body: `{"assessment": "Progressing"}`,
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("SaveNote", mock.Anything, sessionID, studentID, mock.Anything).
+					Return(nil, errs.Conflict("A signed session note cannot be changed"))
+			},
+			expectedStatus: fiber.StatusConflict,
+		},
+		{
+			name: "student not in the session",
+			body: `{"assessment": "Progressing"}`,
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("SaveNote", mock.Anything, sessionID, studentID, mock.Anything).
+					Return(nil, errs.NotFound("Student is not in this session"))
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "section too long",
+			body:           `{"plan": "` + strings.Repeat("a", 10001) + `"}`,
+			mockSetup:      func(m *mocks.MockSessionNoteRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "custom field without a name",
+			body:           `{"custom_fields": {"": "Called mom"}}`,
+			mockSetup:      func(m *mocks.MockSessionNoteRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "invalid JSON",
+			body:           `{"plan":`,
+			mockSetup:      func(m *mocks.MockSessionNoteRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSessionNoteRepository)
+			tt.mockSetup(mockRepo)
+
+			req := httptest.NewRequest("PUT", url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := newApp(mockRepo).Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_SignNote(t *testing.T) {
+	url := "/sessions/" + sessionID.String() + "/students/" + studentID.String() + "/notes/sign"
+
+	tests := []struct {
+		name           string
+		mockSetup      func(*mocks.MockSessionNoteRepository)
+		expectedStatus int
+	}{
+		{
+			name: "signed by the caller",
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("GetNote", mock.Anything, sessionID, studentID).Return(draftNote(), nil)
+				m.On("SignNote", mock.Anything, sessionID, studentID, callerID).Return(signedNote(), nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "already signed",
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("GetNote", mock.Anything, sessionID, studentID).Return(signedNote(), nil)
+			},
+			expectedStatus: fiber.StatusConflict,
+		},
+		{
+			name: "nothing written",
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				empty := draftNote()
+				empty.Subjective, empty.Objective = nil, ptrString("")
+				empty.CustomFields = map[string]string{"Parent contact": ""}
+				m.On("GetNote", mock.Anything, sessionID, studentID).Return(empty, nil)
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "no note",
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("GetNote", mock.Anything, sessionID, studentID).Return(nil, errs.NotFound("Session note not found"))
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "signed in the meantime",
+			mockSetup: func(m *mocks.MockSessionNoteRepository) {
+				m.On("GetNote", mock.Anything, sessionID, studentID).Return(draftNote(), nil)
+				m.On("SignNote", mock.Anything, sessionID, studentID, callerID).
+					Return(nil, errs.Conflict("A signed session note cannot be changed"))
+			},
+			expectedStatus: fiber.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSessionNoteRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("POST", url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+
+			if resp.StatusCode == fiber.StatusOK {
+				var note models.SessionNote
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&note))
+				assert.Equal(t, models.NoteSigned, note.Status)
+				assert.Equal(t, &callerID, note.SignedBy)
+				assert.NotNil(t, note.SignedAt)
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteNote(t *testing.T) {
+	url := "/sessions/" + sessionID.String() + "/students/" + studentID.String() + "/notes"
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "draft",
+			expectedStatus: fiber.StatusNoContent,
+		},
+		{
+			name:           "signed",
+			err:            errs.Conflict("A signed session note cannot be changed"),
+			expectedStatus: fiber.StatusConflict,
+		},
+		{
+			name:           "repository error",
+			err:            errors.New("database error"),
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSessionNoteRepository)
+			mockRepo.On("DeleteNote", mock.Anything, sessionID, studentID).Return(tt.err)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("DELETE", url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package session_note
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SaveNote handles PUT /sessions/:id/students/:studentId/notes. The body is the whole
+// draft, so sections left out are cleared.
+func (h *Handler) SaveNote(c *fiber.Ctx) error {
+	sessionID, studentID, err := noteParams(c)
+	if err != nil {
+		return err
+	}
+
+	var input models.SaveSessionNoteInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse session note data")
+	}
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	note, err := h.sessionNoteRepository.SaveNote(c.Context(), sessionID, studentID, &input)
+	if err != nil {
+		return noteError(err, sessionID, studentID, "Failed to save session note")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(note)
+}
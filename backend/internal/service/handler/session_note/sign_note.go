@@ -0,0 +1,40 @@
+package session_note
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/service/authz"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SignNote handles POST /sessions/:id/students/:studentId/notes/sign. The note is signed
+// by the caller and cannot be changed or deleted afterwards.
+func (h *Handler) SignNote(c *fiber.Ctx) error {
+	sessionID, studentID, err := noteParams(c)
+	if err != nil {
+		return err
+	}
+
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	note, err := h.sessionNoteRepository.GetNote(c.Context(), sessionID, studentID)
+	if err != nil {
+		return noteError(err, sessionID, studentID, "Failed to sign session note")
+	}
+	if note.Signed() {
+		return errs.Conflict("The session note is already signed")
+	}
+	if note.Empty() {
+		return errs.BadRequest("An empty session note cannot be signed")
+	}
+
+	signed, err := h.sessionNoteRepository.SignNote(c.Context(), sessionID, studentID, callerID)
+	if err != nil {
+		return noteError(err, sessionID, studentID, "Failed to sign session note")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(signed)
+}
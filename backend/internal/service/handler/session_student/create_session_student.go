@@ -1,6 +1,7 @@
 package sessionstudent
 
 import (
+	"log/slog"
 	"specialstandard/internal/errs"
 	"specialstandard/internal/models"
 	"specialstandard/internal/xvalidator"
@@ -11,6 +12,8 @@ import (
 	"github.com/google/uuid"
 )
 
+// CreateSessionStudent adds students to sessions. A student who is already in another
+// session at the same time is refused with a 409, unless allow_conflicts is set.
 func (h *Handler) CreateSessionStudent(c *fiber.Ctx) error {
 	var req models.CreateSessionStudentInput
 
@@ -41,6 +44,20 @@ func (h *Handler) CreateSessionStudent(c *fiber.Ctx) error {
 		}
 	}
 
+	if !c.QueryBool("allow_conflicts") {
+		conflicts, err := h.sessionStudentRepository.FindStudentConflicts(c.Context(), req.SessionIDs, req.StudentIDs)
+		if err != nil {
+			slog.Error("Failed to check students for conflicting sessions", "err", err)
+			return errs.InternalServerError("Failed to check for conflicting sessions")
+		}
+		if len(conflicts) > 0 {
+			return errs.ConflictDetails(models.SchedulingConflicts{
+				Error:     "A student is already in another session at that time",
+				Conflicts: conflicts,
+			})
+		}
+	}
+
 	db := h.sessionStudentRepository.GetDB()
 	sessionStudents, err := h.sessionStudentRepository.CreateSessionStudent(c.Context(), db, &req)
 	if err != nil {
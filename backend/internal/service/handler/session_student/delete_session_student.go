@@ -1,6 +1,8 @@
 package sessionstudent
 
 import (
+	"errors"
+	"specialstandard/internal/errs"
 	"specialstandard/internal/models"
 	"strings"
 
@@ -32,6 +34,10 @@ func (h *Handler) DeleteSessionStudent(c *fiber.Ctx) error {
 
 	err := h.sessionStudentRepository.DeleteSessionStudent(c.Context(), &req)
 	if err != nil {
+		var httpErr errs.HTTPError
+		if errors.As(err, &httpErr) {
+			return httpErr
+		}
 		if strings.Contains(err.Error(), "no rows affected") || strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Session student relationship not found",
@@ -28,11 +28,73 @@ func TestHandler_CreateSessionStudent(t *testing.T) {
 
 	tests := []struct {
 		name           string
+		query          string
 		requestBody    string
 		mockSetup      func(*mocks.MockSessionStudentRepository)
 		expectedStatus int
 		wantErr        bool
 	}{
+		{
+			name: "student_in_another_session_at_that_time",
+			requestBody: `{
+				"session_ids": ["` + sessionID.String() + `"],
+				"student_ids": ["` + studentID.String() + `"],
+				"present": true
+			}`,
+			mockSetup: func(m *mocks.MockSessionStudentRepository) {
+				m.On("FindStudentConflicts", mock.Anything, []uuid.UUID{sessionID}, []uuid.UUID{studentID}).
+					Return([]models.SessionConflict{{
+						SessionID:     sessionID2,
+						StartDateTime: time.Now(),
+						EndDateTime:   time.Now().Add(time.Hour),
+						TherapistID:   uuid.New(),
+						StudentIDs:    []uuid.UUID{studentID},
+					}}, nil)
+			},
+			expectedStatus: fiber.StatusConflict,
+			wantErr:        true,
+		},
+		{
+			name:  "conflicts_allowed",
+			query: "?allow_conflicts=true",
+			requestBody: `{
+				"session_ids": ["` + sessionID.String() + `"],
+				"student_ids": ["` + studentID.String() + `"],
+				"present": true
+			}`,
+			mockSetup: func(m *mocks.MockSessionStudentRepository) {
+				m.On("GetDB").Return((*pgxpool.Pool)(nil))
+				m.On("CreateSessionStudent",
+					mock.AnythingOfType("*fasthttp.RequestCtx"),
+					(*pgxpool.Pool)(nil),
+					mock.AnythingOfType("*models.CreateSessionStudentInput"),
+				).Return(&[]models.SessionStudent{
+					{
+						SessionID: sessionID,
+						StudentID: studentID,
+						Present:   true,
+						CreatedAt: time.Now(),
+						UpdatedAt: time.Now(),
+					},
+				}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+			wantErr:        false,
+		},
+		{
+			name: "conflict_check_fails",
+			requestBody: `{
+				"session_ids": ["` + sessionID.String() + `"],
+				"student_ids": ["` + studentID.String() + `"],
+				"present": true
+			}`,
+			mockSetup: func(m *mocks.MockSessionStudentRepository) {
+				m.On("FindStudentConflicts", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+			wantErr:        true,
+		},
 		{
 			name: "successful_create_session_student",
 			requestBody: `{
@@ -265,11 +327,13 @@ func TestHandler_CreateSessionStudent(t *testing.T) {
 			})
 			mockRepo := new(mocks.MockSessionStudentRepository)
 			tt.mockSetup(mockRepo)
+			mockRepo.On("FindStudentConflicts", mock.Anything, mock.Anything, mock.Anything).
+				Return([]models.SessionConflict{}, nil).Maybe()
 
 			handler := sessionstudent.NewHandler(mockRepo)
 			app.Post("/session_students", handler.CreateSessionStudent)
 
-			req := httptest.NewRequest("POST", "/session_students", strings.NewReader(tt.requestBody))
+			req := httptest.NewRequest("POST", "/session_students"+tt.query, strings.NewReader(tt.requestBody))
 			req.Header.Set("Content-Type", "application/json")
 			resp, _ := app.Test(req, -1)
 
@@ -0,0 +1,28 @@
+package session_template
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateTemplate handles POST /session-templates
+func (h *Handler) CreateTemplate(c *fiber.Ctx) error {
+	var input models.CreateSessionTemplateInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse session template data")
+	}
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	template := input.Template()
+	created, err := h.sessionTemplateRepository.CreateTemplate(c.Context(), &template)
+	if err != nil {
+		return templateError(err, "Failed to create session template")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
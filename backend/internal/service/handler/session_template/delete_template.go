@@ -0,0 +1,22 @@
+package session_template
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeleteTemplate handles DELETE /session-templates/:id. Sessions created from the template
+// are kept.
+func (h *Handler) DeleteTemplate(c *fiber.Ctx) error {
+	id, err := templateParam(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.sessionTemplateRepository.DeleteTemplate(c.Context(), id); err != nil {
+		return templateError(err, "Failed to delete session template")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Session template deleted successfully",
+	})
+}
@@ -0,0 +1,20 @@
+package session_template
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTemplate handles GET /session-templates/:id
+func (h *Handler) GetTemplate(c *fiber.Ctx) error {
+	id, err := templateParam(c)
+	if err != nil {
+		return err
+	}
+
+	template, err := h.sessionTemplateRepository.GetTemplate(c.Context(), id)
+	if err != nil {
+		return templateError(err, "Failed to retrieve session template")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(template)
+}
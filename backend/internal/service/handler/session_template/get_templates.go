@@ -0,0 +1,27 @@
+package session_template
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTemplates handles GET /session-templates?therapist_id=
+func (h *Handler) GetTemplates(c *fiber.Ctx) error {
+	var query models.GetSessionTemplatesQuery
+	if err := c.QueryParser(&query); err != nil {
+		return errs.BadRequest("Invalid query parameters")
+	}
+	if validationErrors := h.validator.Validate(query); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	templates, err := h.sessionTemplateRepository.GetTemplates(c.Context(), query.TherapistID)
+	if err != nil {
+		return templateError(err, "Failed to retrieve session templates")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(templates)
+}
@@ -0,0 +1,42 @@
+package session_template
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/storage"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	sessionTemplateRepository storage.SessionTemplateRepository
+	validator                 *xvalidator.XValidator
+}
+
+func NewHandler(sessionTemplateRepository storage.SessionTemplateRepository) *Handler {
+	return &Handler{
+		sessionTemplateRepository: sessionTemplateRepository,
+		validator:                 xvalidator.Validator,
+	}
+}
+
+func templateParam(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, errs.BadRequest("Invalid UUID format for session template ID")
+	}
+	return id, nil
+}
+
+func templateError(err error, message string) error {
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	slog.Error(message, "err", err)
+	return errs.InternalServerError(message)
+}
@@ -0,0 +1,334 @@
+package session_template_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/handler/session_template"
+	"specialstandard/internal/storage/mocks"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newApp(m *mocks.MockSessionTemplateRepository) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	h := session_template.NewHandler(m)
+	app.Get("/session-templates", h.GetTemplates)
+	app.Post("/session-templates", h.CreateTemplate)
+	app.Get("/session-templates/:id", h.GetTemplate)
+	app.Patch("/session-templates/:id", h.UpdateTemplate)
+	app.Delete("/session-templates/:id", h.DeleteTemplate)
+	return app
+}
+
+func TestHandler_GetTemplates(t *testing.T) {
+	therapistID := uuid.New()
+
+	tests := []struct {
+		name               string
+		url                string
+		mockSetup          func(*mocks.MockSessionTemplateRepository)
+		expectedStatusCode int
+		expectedCount      int
+	}{
+		{
+			name: "Listed",
+			url:  "/session-templates?therapist_id=" + therapistID.String(),
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplates", mock.Anything, therapistID).Return([]models.SessionTemplate{
+					{ID: uuid.New(), TherapistID: therapistID, Name: "Articulation", DurationMinutes: 30},
+				}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedCount:      1,
+		},
+		{
+			name:               "Without a therapist",
+			url:                "/session-templates",
+			mockSetup:          func(m *mocks.MockSessionTemplateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Repository error",
+			url:  "/session-templates?therapist_id=" + therapistID.String(),
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplates", mock.Anything, therapistID).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSessionTemplateRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+
+			if tt.expectedCount > 0 {
+				var templates []models.SessionTemplate
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&templates))
+				assert.Len(t, templates, tt.expectedCount)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetTemplate(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name               string
+		url                string
+		mockSetup          func(*mocks.MockSessionTemplateRepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "Found",
+			url:  "/session-templates/" + id.String(),
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplate", mock.Anything, id).Return(&models.SessionTemplate{ID: id}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:               "Invalid ID",
+			url:                "/session-templates/abc",
+			mockSetup:          func(m *mocks.MockSessionTemplateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Not found",
+			url:  "/session-templates/" + id.String(),
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplate", mock.Anything, id).Return(nil, errs.NotFound("Session template", "id", id.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSessionTemplateRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("GET", tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_CreateTemplate(t *testing.T) {
+	therapistID := uuid.New()
+	resourceID := uuid.New()
+	week := 2
+
+	tests := []struct {
+		name               string
+		payload            string
+		mockSetup          func(*mocks.MockSessionTemplateRepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "With resources and game content",
+			payload: `{"therapist_id": "` + therapistID.String() + `", "name": "Articulation", "duration_minutes": 30,
+				"resource_ids": ["` + resourceID.String() + `"], "game_content_filter": {"theme_week": 2, "applicable_game_types": ["flashcards"]}}`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("CreateTemplate", mock.Anything, &models.SessionTemplate{
+					TherapistID:     therapistID,
+					Name:            "Articulation",
+					DurationMinutes: 30,
+					ResourceIDs:     []uuid.UUID{resourceID},
+					GameContentFilter: models.GameContentFilter{
+						ThemeWeek:           &week,
+						ApplicableGameTypes: []string{"flashcards"},
+					},
+				}).Return(&models.SessionTemplate{ID: uuid.New(), TherapistID: therapistID}, nil)
+			},
+			expectedStatusCode: fiber.StatusCreated,
+		},
+		{
+			name:               "Too long",
+			payload:            `{"therapist_id": "` + therapistID.String() + `", "name": "Articulation", "duration_minutes": 600}`,
+			mockSetup:          func(m *mocks.MockSessionTemplateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Unknown game type",
+			payload: `{"therapist_id": "` + therapistID.String() + `", "name": "Articulation", "duration_minutes": 30,
+				"game_content_filter": {"applicable_game_types": ["bingo"]}}`,
+			mockSetup:          func(m *mocks.MockSessionTemplateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:               "Invalid JSON",
+			payload:            `{"name":`,
+			mockSetup:          func(m *mocks.MockSessionTemplateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Unknown resource",
+			payload: `{"therapist_id": "` + therapistID.String() + `", "name": "Articulation", "duration_minutes": 30, "resource_ids": ["` + resourceID.String() + `"]}`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("CreateTemplate", mock.Anything, mock.Anything).Return(nil, errs.BadRequest("Therapist or resource does not exist"))
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Repository error",
+			payload: `{"therapist_id": "` + therapistID.String() + `", "name": "Articulation", "duration_minutes": 30}`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("CreateTemplate", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSessionTemplateRepository)
+			tt.mockSetup(mockRepo)
+
+			req := httptest.NewRequest("POST", "/session-templates", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := newApp(mockRepo).Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_UpdateTemplate(t *testing.T) {
+	id := uuid.New()
+	therapistID := uuid.New()
+	existing := func() *models.SessionTemplate {
+		return &models.SessionTemplate{
+			ID:              id,
+			TherapistID:     therapistID,
+			Name:            "Articulation",
+			DurationMinutes: 30,
+			ResourceIDs:     []uuid.UUID{uuid.New()},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		mockSetup          func(*mocks.MockSessionTemplateRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:    "Clears the resources",
+			payload: `{"duration_minutes": 45, "resource_ids": []}`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplate", mock.Anything, id).Return(existing(), nil)
+				m.On("UpdateTemplate", mock.Anything, &models.SessionTemplate{
+					ID:              id,
+					TherapistID:     therapistID,
+					Name:            "Articulation",
+					DurationMinutes: 45,
+					ResourceIDs:     []uuid.UUID{},
+				}).Return(existing(), nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:               "Empty name",
+			payload:            `{"name": ""}`,
+			mockSetup:          func(m *mocks.MockSessionTemplateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name:    "Not found",
+			payload: `{"name": "Fluency"}`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplate", mock.Anything, id).Return(nil, errs.NotFound("Session template", "id", id.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:    "Repository error",
+			payload: `{"name": "Fluency"}`,
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("GetTemplate", mock.Anything, id).Return(existing(), nil)
+				m.On("UpdateTemplate", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSessionTemplateRepository)
+			tt.mockSetup(mockRepo)
+
+			req := httptest.NewRequest("PATCH", "/session-templates/"+id.String(), strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := newApp(mockRepo).Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_DeleteTemplate(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name               string
+		url                string
+		mockSetup          func(*mocks.MockSessionTemplateRepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "Deleted",
+			url:  "/session-templates/" + id.String(),
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("DeleteTemplate", mock.Anything, id).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:               "Invalid ID",
+			url:                "/session-templates/abc",
+			mockSetup:          func(m *mocks.MockSessionTemplateRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+		{
+			name: "Not found",
+			url:  "/session-templates/" + id.String(),
+			mockSetup: func(m *mocks.MockSessionTemplateRepository) {
+				m.On("DeleteTemplate", mock.Anything, id).Return(errs.NotFound("Session template", "id", id.String()))
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockSessionTemplateRepository)
+			tt.mockSetup(mockRepo)
+
+			resp, err := newApp(mockRepo).Test(httptest.NewRequest("DELETE", tt.url, nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package session_template
+
+import (
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateTemplate handles PATCH /session-templates/:id
+func (h *Handler) UpdateTemplate(c *fiber.Ctx) error {
+	id, err := templateParam(c)
+	if err != nil {
+		return err
+	}
+
+	var input models.UpdateSessionTemplateInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse session template data")
+	}
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	template, err := h.sessionTemplateRepository.GetTemplate(c.Context(), id)
+	if err != nil {
+		return templateError(err, "Failed to update session template")
+	}
+
+	input.Apply(template)
+	updated, err := h.sessionTemplateRepository.UpdateTemplate(c.Context(), template)
+	if err != nil {
+		return templateError(err, "Failed to update session template")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(updated)
+}
@@ -1,6 +1,9 @@
 package student
 
 import (
+	"errors"
+	"specialstandard/internal/errs"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -16,6 +19,10 @@ func (h *Handler) DeleteStudent(c *fiber.Ctx) error {
 	}
 	
 	if err := h.studentRepository.DeleteStudent(c.Context(), id); err != nil {
+		var httpErr errs.HTTPError
+		if errors.As(err, &httpErr) {
+			return httpErr
+		}
     return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
         "error": "Database error",
     })
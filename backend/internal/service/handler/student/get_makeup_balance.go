@@ -0,0 +1,37 @@
+package student
+
+import (
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetMakeupBalance reports the minutes the student is owed for missed sessions, what
+// makeup sessions made up, and the ledger entries behind them
+func (h *Handler) GetMakeupBalance(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format for ID")
+	}
+
+	if _, err := h.studentRepository.GetStudent(c.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.NotFound("Student", "id", id.String())
+		}
+		slog.Error("Failed to get student", "id", id, "err", err)
+		return errs.InternalServerError("Failed to retrieve student")
+	}
+
+	entries, err := h.studentRepository.GetMakeupLedger(c.Context(), id)
+	if err != nil {
+		slog.Error("Failed to get makeup ledger", "id", id, "err", err)
+		return errs.InternalServerError("Failed to retrieve makeup ledger")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.NewMakeupBalance(id, entries))
+}
@@ -7,12 +7,14 @@ import (
 
 type Handler struct {
 	studentRepository storage.StudentRepository
+	jobRunRepository  storage.JobRunRepository
 	validator         *xvalidator.XValidator
 }
 
-func NewHandler(studentRepository storage.StudentRepository) *Handler {
+func NewHandler(studentRepository storage.StudentRepository, jobRunRepository storage.JobRunRepository) *Handler {
 	return &Handler{
 		studentRepository: studentRepository,
+		jobRunRepository:  jobRunRepository,
 		validator:         xvalidator.Validator,
 	}
 }
@@ -17,6 +17,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -242,7 +243,7 @@ func TestHandler_GetStudents(t *testing.T) {
 			mockRepo := new(mocks.MockStudentRepository)
 			tt.mockSetup(mockRepo)
 
-			handler := student.NewHandler(mockRepo)
+			handler := student.NewHandler(mockRepo, new(mocks.MockJobRunRepository))
 			app.Get("/students", handler.GetStudents)
 
 			req := httptest.NewRequest("GET", "/students"+tt.url, nil)
@@ -348,7 +349,7 @@ func TestHandler_GetStudent(t *testing.T) {
 			mockRepo := new(mocks.MockStudentRepository)
 			tt.mockSetup(mockRepo)
 
-			handler := student.NewHandler(mockRepo)
+			handler := student.NewHandler(mockRepo, new(mocks.MockJobRunRepository))
 			app.Get("/students/:id", handler.GetStudent)
 
 			// Make request
@@ -648,7 +649,7 @@ func TestHandler_UpdateStudent(t *testing.T) {
 			mockRepo := new(mocks.MockStudentRepository)
 			tt.mockSetup(mockRepo)
 
-			handler := student.NewHandler(mockRepo)
+			handler := student.NewHandler(mockRepo, new(mocks.MockJobRunRepository))
 			app.Patch("/students/:id", handler.UpdateStudent)
 
 			// Make request
@@ -828,7 +829,7 @@ func TestHandler_AddStudent(t *testing.T) {
 			mockRepo := new(mocks.MockStudentRepository)
 			tt.mockSetup(mockRepo)
 
-			handler := student.NewHandler(mockRepo)
+			handler := student.NewHandler(mockRepo, new(mocks.MockJobRunRepository))
 			app.Post("/students", handler.AddStudent)
 
 			// Make request
@@ -969,7 +970,7 @@ func TestHandler_DeleteStudent(t *testing.T) {
 			mockRepo := new(mocks.MockStudentRepository)
 			tt.mockSetup(mockRepo)
 
-			handler := student.NewHandler(mockRepo)
+			handler := student.NewHandler(mockRepo, new(mocks.MockJobRunRepository))
 			app.Delete("/students/:id", handler.DeleteStudent)
 
 			req := httptest.NewRequest("DELETE", "/students/"+tt.studentID, nil)
@@ -1004,3 +1005,170 @@ func TestHandler_DeleteStudent(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_GetMakeupBalance(t *testing.T) {
+	studentID := uuid.New()
+	missedID := uuid.New()
+	reason := models.SessionCancelledBySchool
+
+	tests := []struct {
+		name           string
+		studentID      string
+		mockSetup      func(*mocks.MockStudentRepository)
+		expectedStatus int
+		wantBalance    *models.MakeupBalance
+	}{
+		{
+			name:      "owed and made up minutes",
+			studentID: studentID.String(),
+			mockSetup: func(m *mocks.MockStudentRepository) {
+				m.On("GetStudent", mock.Anything, studentID).Return(models.Student{ID: studentID}, nil)
+				m.On("GetMakeupLedger", mock.Anything, studentID).Return([]models.MakeupEntry{
+					{StudentID: studentID, SessionID: missedID, Kind: models.MakeupMissed, Minutes: 45, Reason: &reason},
+					{StudentID: studentID, SessionID: uuid.New(), Kind: models.MakeupMissed, Minutes: 30},
+					{StudentID: studentID, SessionID: uuid.New(), Kind: models.MakeupMadeUp, Minutes: -45, MakeupForSessionID: &missedID},
+				}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantBalance:    &models.MakeupBalance{OwedMinutes: 75, MadeUpMinutes: 45, BalanceMinutes: 30},
+		},
+		{
+			name:      "nothing missed",
+			studentID: studentID.String(),
+			mockSetup: func(m *mocks.MockStudentRepository) {
+				m.On("GetStudent", mock.Anything, studentID).Return(models.Student{ID: studentID}, nil)
+				m.On("GetMakeupLedger", mock.Anything, studentID).Return(nil, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			wantBalance:    &models.MakeupBalance{},
+		},
+		{
+			name:      "student not found",
+			studentID: studentID.String(),
+			mockSetup: func(m *mocks.MockStudentRepository) {
+				m.On("GetStudent", mock.Anything, studentID).Return(models.Student{}, pgx.ErrNoRows)
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "invalid UUID format",
+			studentID:      "invalid-uuid",
+			mockSetup:      func(m *mocks.MockStudentRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:      "repository error",
+			studentID: studentID.String(),
+			mockSetup: func(m *mocks.MockStudentRepository) {
+				m.On("GetStudent", mock.Anything, studentID).Return(models.Student{ID: studentID}, nil)
+				m.On("GetMakeupLedger", mock.Anything, studentID).Return(nil, errors.New("database connection failed"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockStudentRepository)
+			tt.mockSetup(mockRepo)
+
+			handler := student.NewHandler(mockRepo, new(mocks.MockJobRunRepository))
+			app.Get("/students/:id/makeup", handler.GetMakeupBalance)
+
+			req := httptest.NewRequest("GET", "/students/"+tt.studentID+"/makeup", nil)
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+
+			if tt.wantBalance != nil {
+				var balance models.MakeupBalance
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&balance))
+				assert.Equal(t, studentID, balance.StudentID)
+				assert.Equal(t, tt.wantBalance.OwedMinutes, balance.OwedMinutes)
+				assert.Equal(t, tt.wantBalance.MadeUpMinutes, balance.MadeUpMinutes)
+				assert.Equal(t, tt.wantBalance.BalanceMinutes, balance.BalanceMinutes)
+				assert.NotNil(t, balance.Entries)
+			}
+		})
+	}
+}
+
+func TestHandler_PromoteStudents(t *testing.T) {
+	therapistID := uuid.New()
+	runAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.MockStudentRepository, *mocks.MockJobRunRepository)
+		expectedStatus int
+	}{
+		{
+			name: "promotes now",
+			body: `{"therapist_id":"` + therapistID.String() + `"}`,
+			mockSetup: func(m *mocks.MockStudentRepository, j *mocks.MockJobRunRepository) {
+				m.On("PromoteStudents", mock.Anything, models.PromoteStudentsInput{TherapistID: therapistID}).Return(nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name: "defers to run_at",
+			body: `{"therapist_id":"` + therapistID.String() + `","run_at":"` + runAt.Format(time.RFC3339) + `"}`,
+			mockSetup: func(m *mocks.MockStudentRepository, j *mocks.MockJobRunRepository) {
+				j.On("EnqueueJob", mock.Anything, mock.MatchedBy(func(input *models.EnqueueJobInput) bool {
+					var payload models.PromoteStudentsInput
+					return input.Name == models.JobPromoteStudents && input.RunAt.Equal(runAt) &&
+						json.Unmarshal(input.Payload, &payload) == nil &&
+						payload.TherapistID == therapistID && payload.RunAt == nil
+				})).Return(&models.JobRun{ID: uuid.New(), Name: models.JobPromoteStudents, Status: models.JobPending, RunAt: runAt}, nil)
+			},
+			expectedStatus: fiber.StatusAccepted,
+		},
+		{
+			name:           "run_at in the past",
+			body:           `{"therapist_id":"` + therapistID.String() + `","run_at":"2020-06-30T00:00:00Z"}`,
+			mockSetup:      func(m *mocks.MockStudentRepository, j *mocks.MockJobRunRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "scheduling fails",
+			body: `{"therapist_id":"` + therapistID.String() + `","run_at":"` + runAt.Format(time.RFC3339) + `"}`,
+			mockSetup: func(m *mocks.MockStudentRepository, j *mocks.MockJobRunRepository) {
+				j.On("EnqueueJob", mock.Anything, mock.Anything).Return(nil, errors.New("database connection failed"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+		{
+			name:           "missing therapist",
+			body:           `{}`,
+			mockSetup:      func(m *mocks.MockStudentRepository, j *mocks.MockJobRunRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockStudentRepository)
+			mockJobs := new(mocks.MockJobRunRepository)
+			tt.mockSetup(mockRepo, mockJobs)
+
+			handler := student.NewHandler(mockRepo, mockJobs)
+			app.Patch("/students/promote", handler.PromoteStudents)
+
+			req := httptest.NewRequest("PATCH", "/students/promote", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+			mockJobs.AssertExpectations(t)
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package student
 
 import (
+	"encoding/json"
 	"log/slog"
 	"specialstandard/internal/errs"
 	"specialstandard/internal/models"
 	"specialstandard/internal/xvalidator"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,6 +24,10 @@ func (h *Handler) PromoteStudents(c *fiber.Ctx) error {
 		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
 	}
 
+	if promoteStudents.RunAt != nil {
+		return h.deferPromotion(c, promoteStudents)
+	}
+
 	err := h.studentRepository.PromoteStudents(c.Context(), promoteStudents)
 	if err != nil {
 		slog.Error("Failed to promote students", "err", err)
@@ -40,3 +46,29 @@ func (h *Handler) PromoteStudents(c *fiber.Ctx) error {
 		"message": "Successfully Promoted Students!",
 	})
 }
+
+// deferPromotion queues the promotion as a background job to run at RunAt
+func (h *Handler) deferPromotion(c *fiber.Ctx, input models.PromoteStudentsInput) error {
+	runAt := *input.RunAt
+	if !runAt.After(time.Now()) {
+		return errs.BadRequest("run_at must be in the future")
+	}
+	input.RunAt = nil
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return errs.InternalServerError("Failed to schedule student promotion")
+	}
+
+	run, err := h.jobRunRepository.EnqueueJob(c.Context(), &models.EnqueueJobInput{
+		Name:    models.JobPromoteStudents,
+		Payload: payload,
+		RunAt:   runAt,
+	})
+	if err != nil {
+		slog.Error("Failed to schedule student promotion", "therapist_id", input.TherapistID, "err", err)
+		return errs.InternalServerError("Failed to schedule student promotion")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(run)
+}
@@ -0,0 +1,49 @@
+package therapist
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AddDelegate gives another therapist access to this therapist's students and sessions
+func (h *Handler) AddDelegate(c *fiber.Ctx) error {
+	therapistID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	var input models.CreateTherapistDelegateInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse delegate data")
+	}
+
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	if input.DelegateID == therapistID {
+		return errs.BadRequest("A therapist cannot be their own delegate")
+	}
+
+	delegate, err := h.therapistRepository.AddDelegate(c.Context(), therapistID, input.DelegateID)
+	if err != nil {
+		slog.Error("Failed to add therapist delegate", "therapist_id", therapistID, "err", err)
+		errStr := err.Error()
+		switch {
+		case strings.Contains(errStr, "foreign key"):
+			return errs.NotFound("Therapist not found")
+		case strings.Contains(errStr, "check constraint"):
+			return errs.BadRequest("A therapist cannot be their own delegate")
+		default:
+			return errs.InternalServerError("Failed to add delegate")
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(delegate)
+}
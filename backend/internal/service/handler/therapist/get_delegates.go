@@ -0,0 +1,25 @@
+package therapist
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetDelegates lists the therapists allowed to work on this therapist's caseload
+func (h *Handler) GetDelegates(c *fiber.Ctx) error {
+	therapistID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	delegates, err := h.therapistRepository.GetDelegates(c.Context(), therapistID)
+	if err != nil {
+		slog.Error("Failed to get therapist delegates", "therapist_id", therapistID, "err", err)
+		return errs.InternalServerError("Failed to retrieve delegates")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(delegates)
+}
@@ -277,6 +277,21 @@ func TestHandler_CreateTherapist(t *testing.T) {
 			expectedStatus: fiber.StatusBadRequest,
 			wantErr:        true,
 		},
+		{
+			name: "unknown time zone",
+			body: `{
+				"id": "423e4567-e89b-12d3-a456-426614174000",
+				"first_name": "Kevin",
+				"last_name": "Matula",
+				"email": "poop123@gmail.com",
+				"schools": [1],
+				"district_id": 1,
+				"time_zone": "Eastern"
+			}`,
+			mockSetup:      func(m *mocks.MockTherapistRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+			wantErr:        true,
+		},
 		{
 			name: "repository error",
 			body: `{
@@ -499,3 +514,263 @@ func TestHandler_PatchTherapist(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_GetDelegates(t *testing.T) {
+	tests := []struct {
+		name           string
+		therapistID    string
+		mockSetup      func(*mocks.MockTherapistRepository)
+		expectedStatus int
+	}{
+		{
+			name:        "successful get delegates",
+			therapistID: "4a9a4e58-ea6c-496a-915f-3e8214e77112",
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				delegates := []models.TherapistDelegate{
+					{
+						TherapistID: uuid.MustParse("4a9a4e58-ea6c-496a-915f-3e8214e77112"),
+						DelegateID:  uuid.New(),
+						CreatedAt:   time.Now(),
+					},
+				}
+				m.On("GetDelegates", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(delegates, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "invalid therapist id",
+			therapistID:    "not-a-uuid",
+			mockSetup:      func(m *mocks.MockTherapistRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:        "repository error",
+			therapistID: "4a9a4e58-ea6c-496a-915f-3e8214e77112",
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				m.On("GetDelegates", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockTherapistRepository)
+			tt.mockSetup(mockRepo)
+
+			handler := therapist.NewHandler(mockRepo)
+			app.Get("/therapists/:id/delegates", handler.GetDelegates)
+
+			req := httptest.NewRequest("GET", "/therapists/"+tt.therapistID+"/delegates", nil)
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AddDelegate(t *testing.T) {
+	therapistID := uuid.MustParse("4a9a4e58-ea6c-496a-915f-3e8214e77112")
+	delegateID := uuid.MustParse("9dad94d8-6534-4510-90d7-e4e97c175a65")
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.MockTherapistRepository)
+		expectedStatus int
+	}{
+		{
+			name: "successful add delegate",
+			body: `{"delegate_id": "` + delegateID.String() + `"}`,
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				m.On("AddDelegate", mock.Anything, therapistID, delegateID).Return(&models.TherapistDelegate{
+					TherapistID: therapistID,
+					DelegateID:  delegateID,
+					CreatedAt:   time.Now(),
+				}, nil)
+			},
+			expectedStatus: fiber.StatusCreated,
+		},
+		{
+			name:           "missing delegate id",
+			body:           `{}`,
+			mockSetup:      func(m *mocks.MockTherapistRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "invalid JSON",
+			body:           `{"delegate_id": `,
+			mockSetup:      func(m *mocks.MockTherapistRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "self delegation",
+			body:           `{"delegate_id": "` + therapistID.String() + `"}`,
+			mockSetup:      func(m *mocks.MockTherapistRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "delegate does not exist",
+			body: `{"delegate_id": "` + delegateID.String() + `"}`,
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				m.On("AddDelegate", mock.Anything, therapistID, delegateID).
+					Return(nil, errors.New("violates foreign key constraint"))
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "repository error",
+			body: `{"delegate_id": "` + delegateID.String() + `"}`,
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				m.On("AddDelegate", mock.Anything, therapistID, delegateID).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockTherapistRepository)
+			tt.mockSetup(mockRepo)
+
+			handler := therapist.NewHandler(mockRepo)
+			app.Post("/therapists/:id/delegates", handler.AddDelegate)
+
+			req := httptest.NewRequest("POST", "/therapists/"+therapistID.String()+"/delegates", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_RemoveDelegate(t *testing.T) {
+	tests := []struct {
+		name           string
+		delegateID     string
+		mockSetup      func(*mocks.MockTherapistRepository)
+		expectedStatus int
+	}{
+		{
+			name:       "successful remove delegate",
+			delegateID: "9dad94d8-6534-4510-90d7-e4e97c175a65",
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				m.On("RemoveDelegate", mock.Anything, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("uuid.UUID")).Return(nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "invalid delegate id",
+			delegateID:     "not-a-uuid",
+			mockSetup:      func(m *mocks.MockTherapistRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "repository error",
+			delegateID: "9dad94d8-6534-4510-90d7-e4e97c175a65",
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				m.On("RemoveDelegate", mock.Anything, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("uuid.UUID")).Return(errors.New("database error"))
+			},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockTherapistRepository)
+			tt.mockSetup(mockRepo)
+
+			handler := therapist.NewHandler(mockRepo)
+			app.Delete("/therapists/:id/delegates/:delegateId", handler.RemoveDelegate)
+
+			req := httptest.NewRequest("DELETE", "/therapists/4a9a4e58-ea6c-496a-915f-3e8214e77112/delegates/"+tt.delegateID, nil)
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_UpdateTherapistRole(t *testing.T) {
+	therapistID := uuid.MustParse("4a9a4e58-ea6c-496a-915f-3e8214e77112")
+	districtID := 1
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.MockTherapistRepository)
+		expectedStatus int
+	}{
+		{
+			name: "promote to district admin",
+			body: `{"role": "district_admin"}`,
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				m.On("UpdateTherapistRole", mock.Anything, therapistID, models.RoleDistrictAdmin).Return(&models.Therapist{
+					ID:         therapistID,
+					FirstName:  "Kevin",
+					LastName:   "Matula",
+					DistrictID: &districtID,
+					Role:       models.RoleDistrictAdmin,
+				}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "unknown role",
+			body:           `{"role": "superuser"}`,
+			mockSetup:      func(m *mocks.MockTherapistRepository) {},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "district admin without a district",
+			body: `{"role": "district_admin"}`,
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				m.On("UpdateTherapistRole", mock.Anything, therapistID, models.RoleDistrictAdmin).
+					Return(nil, errors.New("violates check constraint \"therapist_district_admin_district_check\""))
+			},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "therapist not found",
+			body: `{"role": "system_admin"}`,
+			mockSetup: func(m *mocks.MockTherapistRepository) {
+				m.On("UpdateTherapistRole", mock.Anything, therapistID, models.RoleSystemAdmin).
+					Return(nil, errs.NotFound("Therapist not found with given ID"))
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: errs.ErrorHandler,
+			})
+			mockRepo := new(mocks.MockTherapistRepository)
+			tt.mockSetup(mockRepo)
+
+			handler := therapist.NewHandler(mockRepo)
+			app.Patch("/therapists/:id/role", handler.UpdateTherapistRole)
+
+			req := httptest.NewRequest("PATCH", "/therapists/"+therapistID.String()+"/role", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
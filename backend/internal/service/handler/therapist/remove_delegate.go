@@ -0,0 +1,31 @@
+package therapist
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RemoveDelegate revokes a delegate's access to this therapist's caseload
+func (h *Handler) RemoveDelegate(c *fiber.Ctx) error {
+	therapistID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	delegateID, err := uuid.Parse(c.Params("delegateId"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	if err := h.therapistRepository.RemoveDelegate(c.Context(), therapistID, delegateID); err != nil {
+		slog.Error("Failed to remove therapist delegate", "therapist_id", therapistID, "err", err)
+		return errs.InternalServerError("Failed to remove delegate")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Delegate removed successfully",
+	})
+}
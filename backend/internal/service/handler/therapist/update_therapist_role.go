@@ -0,0 +1,44 @@
+package therapist
+
+import (
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/xvalidator"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UpdateTherapistRole handles PATCH /therapists/:id/role (system administrators only)
+func (h *Handler) UpdateTherapistRole(c *fiber.Ctx) error {
+	therapistID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errs.BadRequest("Invalid UUID format")
+	}
+
+	var input models.UpdateTherapistRoleInput
+	if err := c.BodyParser(&input); err != nil {
+		return errs.InvalidJSON("Failed to parse role data")
+	}
+
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	therapist, err := h.therapistRepository.UpdateTherapistRole(c.Context(), therapistID, input.Role)
+	if err != nil {
+		if httpErr, ok := err.(errs.HTTPError); ok {
+			return httpErr
+		}
+
+		slog.Error("Failed to update therapist role", "therapist_id", therapistID, "err", err)
+		if strings.Contains(err.Error(), "check constraint") {
+			return errs.BadRequest("District administrators must belong to a district")
+		}
+		return errs.InternalServerError("Failed to update role")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(therapist)
+}
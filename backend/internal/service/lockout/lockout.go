@@ -0,0 +1,121 @@
+// Package lockout throttles guessing (passwords, verification codes) with exponentially
+// growing lockouts. The counters live in Postgres so they survive restarts.
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/storage"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Policy describes when and for how long a key is locked. After MaxAttempts consecutive
+// failures the key is locked for BaseLockout, and every further failure doubles the lockout
+// up to MaxLockout. Failures further apart than Window start the count over.
+type Policy struct {
+	MaxAttempts int
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	Window      time.Duration
+}
+
+// LockoutFor returns how long a key with the given number of consecutive failures is locked
+func (p Policy) LockoutFor(failures int) time.Duration {
+	if failures < p.MaxAttempts {
+		return 0
+	}
+
+	exponent := failures - p.MaxAttempts
+	lockout := float64(p.BaseLockout) * math.Pow(2, float64(exponent))
+	if lockout > float64(p.MaxLockout) {
+		return p.MaxLockout
+	}
+	return time.Duration(lockout)
+}
+
+var (
+	// UserPolicy throttles guesses against a single account
+	UserPolicy = Policy{
+		MaxAttempts: 5,
+		BaseLockout: 30 * time.Second,
+		MaxLockout:  time.Hour,
+		Window:      time.Hour,
+	}
+
+	// IPPolicy throttles a single client across accounts. It is looser than UserPolicy as
+	// a school network can put many therapists behind the same address.
+	IPPolicy = Policy{
+		MaxAttempts: 20,
+		BaseLockout: time.Minute,
+		MaxLockout:  time.Hour,
+		Window:      time.Hour,
+	}
+)
+
+// Limiter applies a policy to the keys of one scope, e.g. "login:email"
+type Limiter struct {
+	attempts storage.AttemptRepository
+	scope    string
+	policy   Policy
+}
+
+func NewLimiter(attempts storage.AttemptRepository, scope string, policy Policy) *Limiter {
+	return &Limiter{attempts: attempts, scope: scope, policy: policy}
+}
+
+func (l *Limiter) key(subject string) string {
+	return l.scope + ":" + subject
+}
+
+// Check returns a 429, with the Retry-After header set, while the subject is locked
+func (l *Limiter) Check(c *fiber.Ctx, subject string) error {
+	key := l.key(subject)
+
+	lockedUntil, err := l.attempts.GetLockedUntil(c.Context(), key)
+	if err != nil {
+		// Failing closed would lock everyone out whenever the database hiccups
+		slog.Error("Failed to check lockout", "key", key, "err", err)
+		return nil
+	}
+	if lockedUntil == nil {
+		return nil
+	}
+
+	retryAfter := int(math.Ceil(time.Until(*lockedUntil).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return errs.TooManyRequests(fmt.Sprintf("Too many failed attempts, try again in %d seconds", retryAfter))
+}
+
+// Fail records a failed attempt of the subject and locks it once the policy says so
+func (l *Limiter) Fail(ctx context.Context, subject string) {
+	key := l.key(subject)
+
+	failures, err := l.attempts.RecordFailure(ctx, key, l.policy.Window)
+	if err != nil {
+		slog.Error("Failed to record failed attempt", "key", key, "err", err)
+		return
+	}
+
+	if lockout := l.policy.LockoutFor(failures); lockout > 0 {
+		if err := l.attempts.LockUntil(ctx, key, time.Now().Add(lockout)); err != nil {
+			slog.Error("Failed to lock key", "key", key, "err", err)
+		}
+	}
+}
+
+// Reset forgets the failures of the subject after a successful attempt
+func (l *Limiter) Reset(ctx context.Context, subject string) {
+	key := l.key(subject)
+	if err := l.attempts.ResetAttempts(ctx, key); err != nil {
+		slog.Error("Failed to reset attempts", "key", key, "err", err)
+	}
+}
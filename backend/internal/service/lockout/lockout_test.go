@@ -0,0 +1,135 @@
+package lockout_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/service/lockout"
+	"specialstandard/internal/storage/mocks"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPolicy_LockoutFor(t *testing.T) {
+	policy := lockout.Policy{
+		MaxAttempts: 3,
+		BaseLockout: 10 * time.Second,
+		MaxLockout:  time.Minute,
+		Window:      time.Hour,
+	}
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 1, expected: 0},
+		{failures: 2, expected: 0},
+		{failures: 3, expected: 10 * time.Second},
+		{failures: 4, expected: 20 * time.Second},
+		{failures: 5, expected: 40 * time.Second},
+		{failures: 6, expected: time.Minute},
+		{failures: 1000, expected: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.failures), func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.LockoutFor(tt.failures))
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	lockedUntil := time.Now().Add(42 * time.Second)
+
+	tests := []struct {
+		name               string
+		mockSetup          func(*mocks.MockAttemptRepository)
+		expectedStatusCode int
+		expectedRetryAfter string
+	}{
+		{
+			name: "Not locked",
+			mockSetup: func(m *mocks.MockAttemptRepository) {
+				m.On("GetLockedUntil", mock.Anything, "login:email:a@example.com").Return(nil, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name: "Locked",
+			mockSetup: func(m *mocks.MockAttemptRepository) {
+				m.On("GetLockedUntil", mock.Anything, "login:email:a@example.com").Return(&lockedUntil, nil)
+			},
+			expectedStatusCode: fiber.StatusTooManyRequests,
+			expectedRetryAfter: "42",
+		},
+		{
+			name: "Database error does not lock everyone out",
+			mockSetup: func(m *mocks.MockAttemptRepository) {
+				m.On("GetLockedUntil", mock.Anything, "login:email:a@example.com").Return(nil, errors.New("connection refused"))
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := new(mocks.MockAttemptRepository)
+			tt.mockSetup(attempts)
+			limiter := lockout.NewLimiter(attempts, "login:email", lockout.UserPolicy)
+
+			app := fiber.New(fiber.Config{ErrorHandler: errs.ErrorHandler})
+			app.Get("/", func(c *fiber.Ctx) error {
+				if err := limiter.Check(c, "a@example.com"); err != nil {
+					return err
+				}
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			res, err := app.Test(httptest.NewRequest("GET", "/", nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			assert.Equal(t, tt.expectedRetryAfter, res.Header.Get(fiber.HeaderRetryAfter))
+			attempts.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLimiter_Fail(t *testing.T) {
+	t.Run("Below the limit", func(t *testing.T) {
+		attempts := new(mocks.MockAttemptRepository)
+		attempts.On("RecordFailure", mock.Anything, "verify:user:u1", time.Hour).Return(4, nil)
+
+		lockout.NewLimiter(attempts, "verify:user", lockout.UserPolicy).Fail(context.Background(), "u1")
+
+		attempts.AssertExpectations(t)
+		attempts.AssertNotCalled(t, "LockUntil", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Lockout doubles past the limit", func(t *testing.T) {
+		attempts := new(mocks.MockAttemptRepository)
+		attempts.On("RecordFailure", mock.Anything, "verify:user:u1", time.Hour).Return(7, nil)
+		attempts.On("LockUntil", mock.Anything, "verify:user:u1", mock.MatchedBy(func(until time.Time) bool {
+			remaining := time.Until(until)
+			return remaining > 115*time.Second && remaining <= 120*time.Second
+		})).Return(nil)
+
+		lockout.NewLimiter(attempts, "verify:user", lockout.UserPolicy).Fail(context.Background(), "u1")
+
+		attempts.AssertExpectations(t)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		attempts := new(mocks.MockAttemptRepository)
+		attempts.On("ResetAttempts", mock.Anything, "verify:user:u1").Return(nil)
+
+		lockout.NewLimiter(attempts, "verify:user", lockout.UserPolicy).Reset(context.Background(), "u1")
+
+		attempts.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,167 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/totp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Required reports whether logins of the therapist need an authenticator app code
+func (h *Handler) Required(ctx context.Context, therapistID uuid.UUID) (bool, error) {
+	factor, err := h.repo.GetTOTPFactor(ctx, therapistID)
+	if err != nil {
+		return false, err
+	}
+
+	return factor != nil && factor.Enabled, nil
+}
+
+// StartChallenge holds on to the session of a password login and returns the restricted
+// token it can be collected with once the second factor is passed
+func (h *Handler) StartChallenge(ctx context.Context, therapistID uuid.UUID, refreshToken string, rememberMe bool) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	expiresAt := time.Now().Add(h.challengeTTL)
+
+	if err := h.repo.CreateMFAChallenge(ctx, therapistID, hashToken(token), refreshToken, rememberMe, expiresAt); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// CompleteChallenge checks the second factor of the login the token belongs to and spends
+// the challenge. Each challenge can be completed once.
+func (h *Handler) CompleteChallenge(c *fiber.Ctx, token, code string) (*models.MFAChallenge, error) {
+	challenge, err := h.repo.GetMFAChallenge(c.Context(), hashToken(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.Unauthorized("The login has expired, sign in again")
+	}
+	if err != nil {
+		slog.Error("Failed to load MFA challenge", "err", err)
+		return nil, errs.InternalServerError("Failed to verify code")
+	}
+
+	if err := h.Check(c, challenge.TherapistID, code); err != nil {
+		return nil, err
+	}
+
+	spent, err := h.repo.DeleteMFAChallenge(c.Context(), challenge.ID)
+	if err != nil {
+		slog.Error("Failed to spend MFA challenge", "err", err)
+		return nil, errs.InternalServerError("Failed to verify code")
+	}
+	if !spent {
+		return nil, errs.Unauthorized("The login has expired, sign in again")
+	}
+
+	return challenge, nil
+}
+
+// TrustSession lets the Supabase session through the auth middleware from now on. Sessions
+// of therapists with an authenticator app are turned away until they passed it.
+func (h *Handler) TrustSession(ctx context.Context, therapistID uuid.UUID, sessionID string) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return h.repo.RecordMFASession(ctx, therapistID, id)
+}
+
+// Check accepts a current code from the therapist's authenticator app or one of their
+// unused recovery codes. Wrong codes count towards a lockout like wrong passwords do.
+func (h *Handler) Check(c *fiber.Ctx, therapistID uuid.UUID, code string) error {
+	if err := h.userLimiter.Check(c, therapistID.String()); err != nil {
+		return err
+	}
+	if err := h.ipLimiter.Check(c, c.IP()); err != nil {
+		return err
+	}
+
+	factor, err := h.repo.GetTOTPFactor(c.Context(), therapistID)
+	if err != nil {
+		slog.Error("Failed to load TOTP factor", "therapist_id", therapistID, "err", err)
+		return errs.InternalServerError("Failed to verify code")
+	}
+	if factor == nil || !factor.Enabled {
+		return errs.BadRequest("No authenticator app is enabled")
+	}
+
+	ok, err := h.passes(c.Context(), factor, code)
+	if err != nil {
+		slog.Error("Failed to verify MFA code", "therapist_id", therapistID, "err", err)
+		return errs.InternalServerError("Failed to verify code")
+	}
+
+	if !ok {
+		h.userLimiter.Fail(c.Context(), therapistID.String())
+		h.ipLimiter.Fail(c.Context(), c.IP())
+		return errs.Unauthorized("Invalid code")
+	}
+
+	h.userLimiter.Reset(c.Context(), therapistID.String())
+	return nil
+}
+
+func (h *Handler) passes(ctx context.Context, factor *models.TOTPFactor, code string) (bool, error) {
+	code = normalizeCode(code)
+
+	if len(code) != totp.Digits || strings.Trim(code, "0123456789") != "" {
+		return h.repo.UseRecoveryCode(ctx, factor.TherapistID, hashRecoveryCode(factor.TherapistID, code))
+	}
+
+	step, ok, err := h.validate(factor, code)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	// A code seen once, even within its period, is not accepted again
+	return h.repo.UseTOTPStep(ctx, factor.TherapistID, step)
+}
+
+// validate checks the code against the factor's secret without recording its use
+func (h *Handler) validate(factor *models.TOTPFactor, code string) (int64, bool, error) {
+	if h.sealer == nil {
+		return 0, false, errors.New("MFA_ENCRYPTION_KEY is not configured")
+	}
+
+	secret, err := h.sealer.Open(factor.SecretSealed, factor.TherapistID.String())
+	if err != nil {
+		return 0, false, err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), clockSkew)
+	return step, ok, nil
+}
+
+// Recovery codes are shown grouped (ABCDE-FGHJK) and may be typed any which way
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func hashRecoveryCode(therapistID uuid.UUID, code string) string {
+	sum := sha256.Sum256([]byte(therapistID.String() + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
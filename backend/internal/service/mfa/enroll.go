@@ -0,0 +1,242 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"log/slog"
+	"math/big"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/service/authz"
+	"specialstandard/internal/totp"
+	"specialstandard/internal/xvalidator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Recovery code alphabet without the look-alikes 0/O and 1/I
+const recoveryAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GetStatus tells the caller whether an authenticator app is enabled
+func (h *Handler) GetStatus(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	factor, err := h.repo.GetTOTPFactor(c.Context(), callerID)
+	if err != nil {
+		slog.Error("Failed to load TOTP factor", "therapist_id", callerID, "err", err)
+		return errs.InternalServerError("Failed to retrieve MFA status")
+	}
+
+	status := models.MFAStatus{}
+	if factor != nil && factor.Enabled {
+		status.TOTPEnabled = true
+		status.ConfirmedAt = factor.ConfirmedAt
+		status.RecoveryCodesRemaining = factor.RecoveryCodesRemaining
+	}
+
+	return c.Status(fiber.StatusOK).JSON(status)
+}
+
+// EnrollTOTP creates a secret for an authenticator app. It only takes effect once a code
+// from the app is confirmed with ConfirmTOTP.
+func (h *Handler) EnrollTOTP(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	if h.sealer == nil {
+		return errs.InternalServerError("Authenticator apps are not configured")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		slog.Error("Failed to generate TOTP secret", "err", err)
+		return errs.InternalServerError("Failed to enroll authenticator app")
+	}
+
+	sealed, err := h.sealer.Seal(secret, callerID.String())
+	if err != nil {
+		slog.Error("Failed to encrypt TOTP secret", "err", err)
+		return errs.InternalServerError("Failed to enroll authenticator app")
+	}
+
+	if err := h.repo.SavePendingTOTP(c.Context(), callerID, sealed); err != nil {
+		if httpErr, ok := err.(errs.HTTPError); ok {
+			return httpErr
+		}
+		slog.Error("Failed to save TOTP secret", "therapist_id", callerID, "err", err)
+		return errs.InternalServerError("Failed to enroll authenticator app")
+	}
+
+	account, _ := c.Locals("email").(string)
+	if account == "" {
+		account = callerID.String()
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(h.issuer, account, secret),
+	})
+}
+
+// ConfirmTOTP enables the enrolled authenticator app with its first code and returns the
+// recovery codes, which are not shown again
+func (h *Handler) ConfirmTOTP(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	input, err := h.parseCode(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userLimiter.Check(c, callerID.String()); err != nil {
+		return err
+	}
+
+	factor, err := h.repo.GetTOTPFactor(c.Context(), callerID)
+	if err != nil {
+		slog.Error("Failed to load TOTP factor", "therapist_id", callerID, "err", err)
+		return errs.InternalServerError("Failed to confirm authenticator app")
+	}
+	if factor == nil {
+		return errs.NotFound("No authenticator app is waiting to be confirmed")
+	}
+	if factor.Enabled {
+		return errs.Conflict("An authenticator app is already enabled")
+	}
+
+	step, ok, err := h.validate(factor, normalizeCode(input.Code))
+	if err != nil {
+		slog.Error("Failed to check TOTP code", "therapist_id", callerID, "err", err)
+		return errs.InternalServerError("Failed to confirm authenticator app")
+	}
+	if !ok {
+		h.userLimiter.Fail(c.Context(), callerID.String())
+		return errs.Unauthorized("Invalid code")
+	}
+
+	codes, hashes, err := generateRecoveryCodes(callerID)
+	if err != nil {
+		slog.Error("Failed to generate recovery codes", "err", err)
+		return errs.InternalServerError("Failed to confirm authenticator app")
+	}
+
+	if err := h.repo.EnableTOTP(c.Context(), callerID, step, hashes); err != nil {
+		if httpErr, ok := err.(errs.HTTPError); ok {
+			return httpErr
+		}
+		slog.Error("Failed to enable TOTP factor", "therapist_id", callerID, "err", err)
+		return errs.InternalServerError("Failed to confirm authenticator app")
+	}
+
+	h.userLimiter.Reset(c.Context(), callerID.String())
+
+	// The session that confirmed the app has just passed it and should not be logged out
+	if sessionID, ok := c.Locals("sessionID").(string); ok && sessionID != "" {
+		if err := h.TrustSession(c.Context(), callerID, sessionID); err != nil {
+			slog.Error("Failed to record MFA session", "therapist_id", callerID, "err", err)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP removes the authenticator app. It takes a current code so a stolen session
+// alone cannot turn MFA off.
+func (h *Handler) DisableTOTP(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	input, err := h.parseCode(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Check(c, callerID, input.Code); err != nil {
+		return err
+	}
+
+	if err := h.repo.DeleteTOTP(c.Context(), callerID); err != nil {
+		slog.Error("Failed to delete TOTP factor", "therapist_id", callerID, "err", err)
+		return errs.InternalServerError("Failed to disable authenticator app")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Authenticator app disabled",
+	})
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the caller with new ones
+func (h *Handler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	callerID, err := authz.CallerID(c)
+	if err != nil {
+		return err
+	}
+
+	input, err := h.parseCode(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Check(c, callerID, input.Code); err != nil {
+		return err
+	}
+
+	codes, hashes, err := generateRecoveryCodes(callerID)
+	if err != nil {
+		slog.Error("Failed to generate recovery codes", "err", err)
+		return errs.InternalServerError("Failed to regenerate recovery codes")
+	}
+
+	if err := h.repo.ReplaceRecoveryCodes(c.Context(), callerID, hashes); err != nil {
+		slog.Error("Failed to replace recovery codes", "therapist_id", callerID, "err", err)
+		return errs.InternalServerError("Failed to regenerate recovery codes")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *Handler) parseCode(c *fiber.Ctx) (*models.MFACodeInput, error) {
+	var input models.MFACodeInput
+	if err := c.BodyParser(&input); err != nil {
+		return nil, errs.InvalidJSON("Failed to parse code")
+	}
+
+	if validationErrors := h.validator.Validate(input); len(validationErrors) > 0 {
+		return nil, errs.InvalidRequestData(xvalidator.ConvertToMessages(validationErrors))
+	}
+
+	return &input, nil
+}
+
+// generateRecoveryCodes returns the codes to show (ABCDE-FGHJK) and the hashes to store
+func generateRecoveryCodes(therapistID uuid.UUID) ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	max := big.NewInt(int64(len(recoveryAlphabet)))
+	for i := range codes {
+		code := make([]byte, 10)
+		for j := range code {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, err
+			}
+			code[j] = recoveryAlphabet[n.Int64()]
+		}
+
+		codes[i] = string(code[:5]) + "-" + string(code[5:])
+		hashes[i] = hashRecoveryCode(therapistID, string(code))
+	}
+
+	return codes, hashes, nil
+}
@@ -0,0 +1,51 @@
+// Package mfa manages authenticator app (TOTP) second factors: enrollment, recovery codes
+// and checking the second factor of a login.
+package mfa
+
+import (
+	"specialstandard/internal/config"
+	"specialstandard/internal/service/lockout"
+	"specialstandard/internal/storage"
+	"specialstandard/internal/totp"
+	"specialstandard/internal/xvalidator"
+	"time"
+)
+
+const (
+	// Periods of clock drift accepted either way between the server and the app
+	clockSkew = 1
+	// Recovery codes handed out at a time
+	recoveryCodeCount = 10
+)
+
+type Handler struct {
+	repo         storage.MFARepository
+	sealer       *totp.Sealer
+	issuer       string
+	challengeTTL time.Duration
+	validator    *xvalidator.XValidator
+	userLimiter  *lockout.Limiter
+	ipLimiter    *lockout.Limiter
+}
+
+// NewHandler fails when MFA_ENCRYPTION_KEY is set but unusable. Without a key enrolled
+// factors still cannot be bypassed, checking them fails instead.
+func NewHandler(repo storage.MFARepository, attemptRepo storage.AttemptRepository, cfg config.MFA) (*Handler, error) {
+	var sealer *totp.Sealer
+	if cfg.EncryptionKey != "" {
+		var err error
+		if sealer, err = totp.NewSealer(cfg.EncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Handler{
+		repo:         repo,
+		sealer:       sealer,
+		issuer:       cfg.Issuer,
+		challengeTTL: cfg.ChallengeTTL,
+		validator:    xvalidator.Validator,
+		userLimiter:  lockout.NewLimiter(attemptRepo, "mfa:user", lockout.UserPolicy),
+		ipLimiter:    lockout.NewLimiter(attemptRepo, "mfa:ip", lockout.IPPolicy),
+	}, nil
+}
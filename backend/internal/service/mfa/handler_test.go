@@ -0,0 +1,337 @@
+package mfa
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"specialstandard/internal/config"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/mocks"
+	"specialstandard/internal/totp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	testKey       = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	testSessionID = uuid.MustParse("5c1b6a0e-3f5d-4a8e-9a57-2d0c1e7b9f34")
+)
+
+func newTestApp(t *testing.T, repo *mocks.MockMFARepository, attempts *mocks.MockAttemptRepository, callerID uuid.UUID) *fiber.App {
+	handler, err := NewHandler(repo, attempts, config.MFA{Issuer: "The Special Standard", EncryptionKey: testKey})
+	assert.NoError(t, err)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errs.ErrorHandler,
+	})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", callerID.String())
+		c.Locals("email", "meow.thegato@gmail.com")
+		c.Locals("sessionID", testSessionID.String())
+		return c.Next()
+	})
+	app.Get("/mfa", handler.GetStatus)
+	app.Post("/mfa/totp", handler.EnrollTOTP)
+	app.Post("/mfa/totp/confirm", handler.ConfirmTOTP)
+	app.Delete("/mfa/totp", handler.DisableTOTP)
+	app.Post("/mfa/recovery-codes", handler.RegenerateRecoveryCodes)
+	return app
+}
+
+// sealedFactor returns a factor for a fresh secret and the code it currently accepts
+func sealedFactor(t *testing.T, therapistID uuid.UUID, enabled bool) (*models.TOTPFactor, string) {
+	sealer, err := totp.NewSealer(testKey)
+	assert.NoError(t, err)
+
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	sealed, err := sealer.Seal(secret, therapistID.String())
+	assert.NoError(t, err)
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	assert.NoError(t, err)
+
+	return &models.TOTPFactor{TherapistID: therapistID, SecretSealed: sealed, Enabled: enabled}, code
+}
+
+func TestNewHandler(t *testing.T) {
+	_, err := NewHandler(new(mocks.MockMFARepository), new(mocks.MockAttemptRepository), config.MFA{})
+	assert.NoError(t, err, "MFA without a key is allowed")
+
+	_, err = NewHandler(new(mocks.MockMFARepository), new(mocks.MockAttemptRepository), config.MFA{EncryptionKey: "c2hvcnQ="})
+	assert.Error(t, err)
+}
+
+func TestHandler_EnrollTOTP(t *testing.T) {
+	callerID := uuid.New()
+
+	tests := []struct {
+		name               string
+		mockSetup          func(*mocks.MockMFARepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "New secret",
+			mockSetup: func(m *mocks.MockMFARepository) {
+				m.On("SavePendingTOTP", mock.Anything, callerID, mock.AnythingOfType("string")).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusCreated,
+		},
+		{
+			name: "Already enabled",
+			mockSetup: func(m *mocks.MockMFARepository) {
+				m.On("SavePendingTOTP", mock.Anything, callerID, mock.AnythingOfType("string")).
+					Return(errs.Conflict("An authenticator app is already enabled"))
+			},
+			expectedStatusCode: fiber.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockMFARepository)
+			tt.mockSetup(repo)
+			app := newTestApp(t, repo, new(mocks.MockAttemptRepository), callerID)
+
+			res, _ := app.Test(httptest.NewRequest("POST", "/mfa/totp", nil), -1)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedStatusCode == fiber.StatusCreated {
+				var enrollment models.TOTPEnrollment
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&enrollment))
+
+				uri, err := url.Parse(enrollment.OTPAuthURI)
+				assert.NoError(t, err)
+				assert.Equal(t, "otpauth", uri.Scheme)
+				assert.Equal(t, "totp", uri.Host)
+				assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+				assert.Equal(t, "The Special Standard", uri.Query().Get("issuer"))
+
+				// The stored secret is encrypted
+				sealed := repo.Calls[0].Arguments.String(2)
+				assert.NotContains(t, sealed, enrollment.Secret)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ConfirmTOTP(t *testing.T) {
+	callerID := uuid.New()
+	pending, code := sealedFactor(t, callerID, false)
+	enabled, _ := sealedFactor(t, callerID, true)
+
+	tests := []struct {
+		name               string
+		payload            string
+		mockSetup          func(*mocks.MockMFARepository, *mocks.MockAttemptRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:    "Current code enables the app",
+			payload: fmt.Sprintf(`{"code": "%s"}`, code),
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetTOTPFactor", mock.Anything, callerID).Return(pending, nil)
+				m.On("EnableTOTP", mock.Anything, callerID, mock.AnythingOfType("int64"), mock.MatchedBy(func(hashes []string) bool {
+					return len(hashes) == recoveryCodeCount
+				})).Return(nil)
+				m.On("RecordMFASession", mock.Anything, callerID, testSessionID).Return(nil)
+				a.On("ResetAttempts", mock.Anything, "mfa:user:"+callerID.String()).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:    "Wrong code",
+			payload: `{"code": "000000"}`,
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetTOTPFactor", mock.Anything, callerID).Return(pending, nil)
+				a.On("RecordFailure", mock.Anything, "mfa:user:"+callerID.String(), time.Hour).Return(1, nil)
+			},
+			expectedStatusCode: fiber.StatusUnauthorized,
+		},
+		{
+			name:    "Nothing enrolled",
+			payload: fmt.Sprintf(`{"code": "%s"}`, code),
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetTOTPFactor", mock.Anything, callerID).Return(nil, nil)
+			},
+			expectedStatusCode: fiber.StatusNotFound,
+		},
+		{
+			name:    "Already enabled",
+			payload: fmt.Sprintf(`{"code": "%s"}`, code),
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetTOTPFactor", mock.Anything, callerID).Return(enabled, nil)
+			},
+			expectedStatusCode: fiber.StatusConflict,
+		},
+		{
+			name:               "Missing code",
+			payload:            `{}`,
+			mockSetup:          func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockMFARepository)
+			attempts := new(mocks.MockAttemptRepository)
+			attempts.On("GetLockedUntil", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			tt.mockSetup(repo, attempts)
+			app := newTestApp(t, repo, attempts, callerID)
+
+			req := httptest.NewRequest("POST", "/mfa/totp/confirm", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			res, _ := app.Test(req, -1)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedStatusCode == fiber.StatusOK {
+				var response models.RecoveryCodesResponse
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+				assert.Len(t, response.RecoveryCodes, recoveryCodeCount)
+				for _, recoveryCode := range response.RecoveryCodes {
+					assert.Regexp(t, `^[A-HJ-NP-Z2-9]{5}-[A-HJ-NP-Z2-9]{5}$`, recoveryCode)
+				}
+			}
+			repo.AssertExpectations(t)
+			attempts.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_DisableTOTP(t *testing.T) {
+	callerID := uuid.New()
+	factor, code := sealedFactor(t, callerID, true)
+
+	tests := []struct {
+		name               string
+		payload            string
+		mockSetup          func(*mocks.MockMFARepository, *mocks.MockAttemptRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:    "Current code",
+			payload: fmt.Sprintf(`{"code": "%s"}`, code),
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetTOTPFactor", mock.Anything, callerID).Return(factor, nil)
+				m.On("UseTOTPStep", mock.Anything, callerID, mock.AnythingOfType("int64")).Return(true, nil)
+				m.On("DeleteTOTP", mock.Anything, callerID).Return(nil)
+				a.On("ResetAttempts", mock.Anything, "mfa:user:"+callerID.String()).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:    "Recovery code is normalized before hashing",
+			payload: `{"code": "abcde fghjk"}`,
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetTOTPFactor", mock.Anything, callerID).Return(factor, nil)
+				m.On("UseRecoveryCode", mock.Anything, callerID, hashRecoveryCode(callerID, "ABCDEFGHJK")).Return(true, nil)
+				m.On("DeleteTOTP", mock.Anything, callerID).Return(nil)
+				a.On("ResetAttempts", mock.Anything, "mfa:user:"+callerID.String()).Return(nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+		},
+		{
+			name:    "Used recovery code",
+			payload: `{"code": "ABCDE-FGHJK"}`,
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetTOTPFactor", mock.Anything, callerID).Return(factor, nil)
+				m.On("UseRecoveryCode", mock.Anything, callerID, mock.AnythingOfType("string")).Return(false, nil)
+				a.On("RecordFailure", mock.Anything, mock.Anything, time.Hour).Return(1, nil)
+			},
+			expectedStatusCode: fiber.StatusUnauthorized,
+		},
+		{
+			name:    "No app enabled",
+			payload: fmt.Sprintf(`{"code": "%s"}`, code),
+			mockSetup: func(m *mocks.MockMFARepository, a *mocks.MockAttemptRepository) {
+				m.On("GetTOTPFactor", mock.Anything, callerID).Return(nil, nil)
+			},
+			expectedStatusCode: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockMFARepository)
+			attempts := new(mocks.MockAttemptRepository)
+			attempts.On("GetLockedUntil", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			tt.mockSetup(repo, attempts)
+			app := newTestApp(t, repo, attempts, callerID)
+
+			req := httptest.NewRequest("DELETE", "/mfa/totp", strings.NewReader(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			res, _ := app.Test(req, -1)
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			repo.AssertExpectations(t)
+			attempts.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_RegenerateRecoveryCodes(t *testing.T) {
+	callerID := uuid.New()
+	factor, code := sealedFactor(t, callerID, true)
+
+	repo := new(mocks.MockMFARepository)
+	repo.On("GetTOTPFactor", mock.Anything, callerID).Return(factor, nil)
+	repo.On("UseTOTPStep", mock.Anything, callerID, mock.AnythingOfType("int64")).Return(true, nil)
+	repo.On("ReplaceRecoveryCodes", mock.Anything, callerID, mock.MatchedBy(func(hashes []string) bool {
+		return len(hashes) == recoveryCodeCount
+	})).Return(nil)
+
+	attempts := new(mocks.MockAttemptRepository)
+	attempts.On("GetLockedUntil", mock.Anything, mock.Anything).Return(nil, nil)
+	attempts.On("ResetAttempts", mock.Anything, "mfa:user:"+callerID.String()).Return(nil)
+
+	app := newTestApp(t, repo, attempts, callerID)
+
+	req := httptest.NewRequest("POST", "/mfa/recovery-codes", strings.NewReader(fmt.Sprintf(`{"code": "%s"}`, code)))
+	req.Header.Set("Content-Type", "application/json")
+	res, _ := app.Test(req, -1)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var response models.RecoveryCodesResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+	assert.Len(t, response.RecoveryCodes, recoveryCodeCount)
+
+	// The stored hashes belong to the codes handed out
+	hashes := repo.Calls[len(repo.Calls)-1].Arguments.Get(2).([]string)
+	for i, recoveryCode := range response.RecoveryCodes {
+		assert.Equal(t, hashRecoveryCode(callerID, normalizeCode(recoveryCode)), hashes[i])
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestHandler_GetStatus(t *testing.T) {
+	callerID := uuid.New()
+	confirmedAt := time.Now()
+
+	repo := new(mocks.MockMFARepository)
+	repo.On("GetTOTPFactor", mock.Anything, callerID).Return(&models.TOTPFactor{
+		TherapistID:            callerID,
+		Enabled:                true,
+		ConfirmedAt:            &confirmedAt,
+		RecoveryCodesRemaining: 7,
+	}, nil)
+
+	app := newTestApp(t, repo, new(mocks.MockAttemptRepository), callerID)
+
+	res, _ := app.Test(httptest.NewRequest("GET", "/mfa", nil), -1)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	var status models.MFAStatus
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&status))
+	assert.True(t, status.TOTPEnabled)
+	assert.Equal(t, 7, status.RecoveryCodesRemaining)
+}
@@ -1,24 +1,41 @@
 package service
 
 import (
+	"log"
 	"log/slog"
 	"os"
 	"specialstandard/internal/config"
 	"specialstandard/internal/errs"
+	"specialstandard/internal/jobs"
+	"specialstandard/internal/mailer"
+	"specialstandard/internal/models"
+	"specialstandard/internal/notify"
 	"specialstandard/internal/s3_client"
+	"specialstandard/internal/service/authz"
+	"specialstandard/internal/service/handler/api_key"
 	"specialstandard/internal/service/handler/auth"
+	"specialstandard/internal/service/handler/calendar"
 	"specialstandard/internal/service/handler/game_content"
 	"specialstandard/internal/service/handler/game_result"
+	"specialstandard/internal/service/handler/invitation"
+	"specialstandard/internal/service/handler/job"
 	newsletterhandler "specialstandard/internal/service/handler/newsletter"
+	"specialstandard/internal/service/handler/notification"
 	"specialstandard/internal/service/handler/resource"
 	s3handler "specialstandard/internal/service/handler/s3"
 	"specialstandard/internal/service/handler/school"
+	"specialstandard/internal/service/handler/school_calendar"
+	"specialstandard/internal/service/handler/service_mandate"
 	"specialstandard/internal/service/handler/session"
+	"specialstandard/internal/service/handler/session_import"
+	"specialstandard/internal/service/handler/session_note"
 	"specialstandard/internal/service/handler/session_resource"
 	sessionstudent "specialstandard/internal/service/handler/session_student"
+	"specialstandard/internal/service/handler/session_template"
 	"specialstandard/internal/service/handler/student"
 	"specialstandard/internal/service/handler/theme"
 	"specialstandard/internal/service/handler/therapist"
+	"specialstandard/internal/service/mfa"
 	"specialstandard/internal/service/verification"
 	"specialstandard/internal/storage"
 	"specialstandard/internal/storage/postgres"
@@ -44,32 +61,70 @@ type App struct {
 	Server   *fiber.App
 	Repo     *storage.Repository
 	S3Bucket *s3_client.Client
+	Mailer   *mailer.Mailer
+	Jobs     *jobs.Scheduler
 }
 
 // Initialize the App union type containing a fiber app, a repository, and a climatiq client.
 func InitApp(config config.Config) *App {
 	ctx := context.Background()
-	repo := postgres.NewRepository(ctx, config.DB)
+	repo := postgres.NewRepository(ctx, config.DB, config.Verification)
 	bucket, err := s3_client.NewClient(config.S3Bucket)
 	if err != nil {
 		slog.Error("bucket cannot be configured")
 	}
 
-	app := SetupApp(config, repo, bucket)
+	mail, err := newMailer(config, repo)
+	if err != nil {
+		log.Fatalf("Failed to configure email delivery: %v", err)
+	}
+
+	scheduler := jobs.New(repo.JobRun, config.Jobs)
+	if err := jobs.RegisterBuiltins(scheduler, repo, config); err != nil {
+		log.Fatalf("Failed to configure background jobs: %v", err)
+	}
+	if err := notify.Register(scheduler, notify.New(repo.Session, repo.Notification, mail)); err != nil {
+		log.Fatalf("Failed to configure session notifications: %v", err)
+	}
+
+	app := setupApp(config, repo, bucket, mail)
 
 	return &App{
 		Server:   app,
 		Repo:     repo,
 		S3Bucket: bucket,
+		Mailer:   mail,
+		Jobs:     scheduler,
 	}
 }
 
+// newMailer delivers through the backend picked by MAIL_BACKEND, every email passes the outbox
+func newMailer(config config.Config, repo *storage.Repository) (*mailer.Mailer, error) {
+	backend, err := mailer.NewBackend(config.Mail, config.Resend)
+	if err != nil {
+		return nil, err
+	}
+
+	return mailer.New(backend, repo.EmailOutbox, mailer.FromAddress(config.Mail, config.Resend)), nil
+}
+
 // Setup the fiber app with the specified configuration, database, and S3 client.
 func SetupApp(config config.Config, repo *storage.Repository, bucket *s3_client.Client) *fiber.App {
+	mail, err := newMailer(config, repo)
+	if err != nil {
+		slog.Error("Failed to configure email delivery, keeping emails in memory", "err", err)
+		mail = mailer.New(mailer.NewLocalBackend(""), repo.EmailOutbox, mailer.FromAddress(config.Mail, config.Resend))
+	}
+
+	return setupApp(config, repo, bucket, mail)
+}
+
+func setupApp(config config.Config, repo *storage.Repository, bucket *s3_client.Client, mail *mailer.Mailer) *fiber.App {
 	app := fiber.New(fiber.Config{
 		JSONEncoder:  go_json.Marshal,
 		JSONDecoder:  go_json.Unmarshal,
 		ErrorHandler: errs.ErrorHandler,
+		ProxyHeader:  config.Application.ProxyHeader,
 	})
 
 	app.Use(recover.New())
@@ -119,16 +174,32 @@ func SetupApp(config config.Config, repo *storage.Repository, bucket *s3_client.
 		return c.SendStatus(http.StatusOK)
 	})
 
-	SupabaseAuthHandler := auth.NewHandler(config.Supabase, repo.Therapist, emailVerificationEnabled)
+	mfaHandler, err := mfa.NewHandler(repo.MFA, repo.Attempt, config.MFA)
+	if err != nil {
+		log.Fatalf("Failed to configure MFA: %v", err)
+	}
+
+	SupabaseAuthHandler := auth.NewHandler(config.Supabase, repo.Therapist, repo.Attempt, repo.Account, mfaHandler, mail, emailVerificationEnabled)
 
 	authGroup := apiV1.Group("/auth")
 	authGroup.Post("/login", SupabaseAuthHandler.Login)
+	authGroup.Post("/refresh", SupabaseAuthHandler.Refresh)
+	authGroup.Post("/mfa/verify", SupabaseAuthHandler.VerifyMFA)
+	authGroup.Post("/logout", SupabaseAuthHandler.Logout)
 	authGroup.Post("/signup", SupabaseAuthHandler.SignUp)
 	authGroup.Post("/forgot-password", SupabaseAuthHandler.ForgotPassword)
 	authGroup.Put("/update-password", SupabaseAuthHandler.UpdatePassword)
 
+	invitationHandler := invitation.NewHandler(repo.Invitation, config.Supabase, config.Invitation, mail)
+	authGroup.Get("/invitation", invitationHandler.GetInvitation)
+	authGroup.Post("/accept-invite", invitationHandler.AcceptInvitation)
+
+	// Calendar apps cannot log in, the secret token in the feed URL authenticates them
+	calendarHandler := calendar.NewHandler(repo.CalendarFeed, config.Calendar)
+	apiV1.Get("/calendar/:token", calendarHandler.GetFeed)
+
 	if !config.TestMode {
-		apiV1.Use(supabase_auth.Middleware(&config.Supabase))
+		apiV1.Use(supabase_auth.Middleware(&config.Supabase, repo.APIKey, repo.MFA))
 	} else {
 		apiV1.Use(func(c *fiber.Ctx) error {
 			c.Locals("user", "test-user")
@@ -136,11 +207,15 @@ func SetupApp(config config.Config, repo *storage.Repository, bucket *s3_client.
 		})
 	}
 
+	// Every caseload endpoint below is scoped to the caller and their delegations
+	guard := authz.NewGuard(repo.Access, !config.TestMode)
+	apiV1.Use(guard.APIKeyScopes("/api/v1"))
+
 	verificationHandler := verification.NewHandler(
 		repo.Verification,
+		repo.Attempt,
 		repo.GetDB(),
-		config.Resend.APIKey,
-		config.Resend.FromEmail,
+		mail,
 	)
 
 	apiV1.Route("/verification", func(r fiber.Router) {
@@ -150,6 +225,38 @@ func SetupApp(config config.Config, repo *storage.Repository, bucket *s3_client.
 	})
 
 	authGroup.Delete("/delete-account/:id", SupabaseAuthHandler.DeleteAccount)
+	authGroup.Get("/export/:id", SupabaseAuthHandler.ExportAccount)
+
+	apiV1.Route("/mfa", func(r fiber.Router) {
+		r.Get("/", mfaHandler.GetStatus)
+		r.Post("/totp", mfaHandler.EnrollTOTP)
+		r.Post("/totp/confirm", mfaHandler.ConfirmTOTP)
+		r.Delete("/totp", mfaHandler.DisableTOTP)
+		r.Post("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+	})
+
+	apiV1.Route("/calendar-feed", func(r fiber.Router) {
+		r.Get("/", calendarHandler.GetCalendarFeed)
+		r.Post("/", calendarHandler.CreateCalendarFeed)
+		r.Patch("/", calendarHandler.UpdateCalendarFeed)
+		r.Delete("/", calendarHandler.DeleteCalendarFeed)
+	})
+
+	// Digests and reminders of the caller's sessions, sent by the session_notifications job
+	notificationHandler := notification.NewHandler(repo.Notification)
+	apiV1.Route("/notification-preferences", func(r fiber.Router) {
+		r.Get("/", notificationHandler.GetNotificationPreference)
+		r.Patch("/", notificationHandler.UpdateNotificationPreference)
+	})
+
+	apiKeyHandler := api_key.NewHandler(repo.APIKey, repo.Access)
+	apiV1.Route("/api-keys", func(r fiber.Router) {
+		r.Post("/", apiKeyHandler.CreateAPIKey)
+		r.Get("/", apiKeyHandler.GetAPIKeys)
+		r.Get("/:id", apiKeyHandler.GetAPIKeyByID)
+		r.Patch("/:id", apiKeyHandler.UpdateAPIKey)
+		r.Delete("/:id", apiKeyHandler.RevokeAPIKey)
+	})
 
 	themeHandler := theme.NewHandler(repo.Theme)
 	apiV1.Route("/themes", func(r fiber.Router) {
@@ -165,10 +272,18 @@ func SetupApp(config config.Config, repo *storage.Repository, bucket *s3_client.
 		r.Get("/:id", therapistHandler.GetTherapistByID)
 		r.Post("/", therapistHandler.CreateTherapist)
 		r.Get("/", therapistHandler.GetTherapists)
-		r.Delete("/:id", therapistHandler.DeleteTherapist)
-		r.Patch("/:id", therapistHandler.PatchTherapist)
+		r.Delete("/:id", guard.Self("id"), therapistHandler.DeleteTherapist)
+		r.Patch("/:id", guard.Self("id"), guard.KeepDistrict("district_id"), therapistHandler.PatchTherapist)
+		r.Patch("/:id/role", guard.RequireRole(models.RoleSystemAdmin), therapistHandler.UpdateTherapistRole)
+		r.Get("/:id/delegates", guard.Self("id"), therapistHandler.GetDelegates)
+		r.Post("/:id/delegates", guard.Self("id"), therapistHandler.AddDelegate)
+		r.Delete("/:id/delegates/:delegateId", guard.Self("id"), therapistHandler.RemoveDelegate)
 	})
 
+	// Background jobs are run by the servers, only system administrators look into them
+	jobHandler := job.NewHandler(repo.JobRun)
+	apiV1.Get("/jobs/runs", guard.RequireRole(models.RoleSystemAdmin), jobHandler.GetJobRuns)
+
 	resourceHandler := resource.NewHandler(repo.Resource, bucket)
 	apiV1.Route("/resources", func(r fiber.Router) {
 		r.Post("/", resourceHandler.PostResource)
@@ -180,42 +295,72 @@ func SetupApp(config config.Config, repo *storage.Repository, bucket *s3_client.
 
 	sessionStudentHandler := sessionstudent.NewHandler(repo.SessionStudent)
 	apiV1.Route("/session_students", func(r fiber.Router) {
-		r.Post("/", sessionStudentHandler.CreateSessionStudent)
-		r.Delete("/", sessionStudentHandler.DeleteSessionStudent)
-		r.Patch("/", sessionStudentHandler.PatchStudentSessionRatings)
+		r.Post("/", guard.SessionsInBody("session_ids"), guard.StudentsInBody("student_ids"), sessionStudentHandler.CreateSessionStudent)
+		r.Delete("/", guard.SessionsInBody("session_id"), guard.StudentsInBody("student_id"), sessionStudentHandler.DeleteSessionStudent)
+		r.Patch("/", guard.SessionsInBody("session_id"), guard.StudentsInBody("student_id"), sessionStudentHandler.PatchStudentSessionRatings)
 	})
 
-	studentHandler := student.NewHandler(repo.Student)
+	studentHandler := student.NewHandler(repo.Student, repo.JobRun)
+	serviceMandateHandler := service_mandate.NewHandler(repo.ServiceMandate)
 	// Student route
 	apiV1.Route("/students", func(r fiber.Router) {
-		r.Get("/", studentHandler.GetStudents)
-		r.Get("/:id", studentHandler.GetStudent)
-		r.Delete("/:id", studentHandler.DeleteStudent)
-		r.Post("/", studentHandler.AddStudent)
-		r.Patch("/promote", studentHandler.PromoteStudents)
-		r.Patch("/:id", studentHandler.UpdateStudent)
-		r.Get("/:id/sessions", studentHandler.GetStudentSessions)
-		r.Get("/:id/ratings", studentHandler.GetStudentRatings)
-		r.Get("/:id/attendance", sessionStudentHandler.GetStudentAttendance)
+		r.Get("/", guard.TherapistQuery("therapist_id"), studentHandler.GetStudents)
+		r.Get("/:id", guard.StudentParam("id"), studentHandler.GetStudent)
+		r.Delete("/:id", guard.StudentParam("id"), studentHandler.DeleteStudent)
+		r.Post("/", guard.TherapistsInBody("therapist_id"), studentHandler.AddStudent)
+		r.Patch("/promote", guard.TherapistsInBody("therapist_id"), studentHandler.PromoteStudents)
+		r.Patch("/:id", guard.StudentParam("id"), guard.TherapistsInBody("therapist_id"), studentHandler.UpdateStudent)
+		r.Get("/:id/sessions", guard.StudentParam("id"), studentHandler.GetStudentSessions)
+		r.Get("/:id/makeup", guard.StudentParam("id"), studentHandler.GetMakeupBalance)
+		r.Get("/:id/ratings", guard.StudentParam("id"), studentHandler.GetStudentRatings)
+		r.Get("/:id/attendance", guard.StudentParam("id"), sessionStudentHandler.GetStudentAttendance)
+		r.Get("/:id/mandates", guard.StudentParam("id"), serviceMandateHandler.GetMandates)
+		r.Post("/:id/mandates", guard.StudentParam("id"), serviceMandateHandler.CreateMandate)
+		r.Patch("/:id/mandates/:mandateId", guard.StudentParam("id"), serviceMandateHandler.UpdateMandate)
+		r.Delete("/:id/mandates/:mandateId", guard.StudentParam("id"), serviceMandateHandler.DeleteMandate)
+		r.Get("/:id/compliance", guard.StudentParam("id"), serviceMandateHandler.GetStudentCompliance)
 	})
 
+	apiV1.Get("/compliance", guard.TherapistQuery("therapist_id"), serviceMandateHandler.GetCompliance)
+
 	sessionResourceHandler := session_resource.NewHandler(repo.SessionResource)
 	apiV1.Route("/session-resource", func(r fiber.Router) {
-		r.Post("/", sessionResourceHandler.PostSessionResource)
-		r.Delete("/", sessionResourceHandler.DeleteSessionResource)
+		r.Post("/", guard.SessionsInBody("session_id"), sessionResourceHandler.PostSessionResource)
+		r.Delete("/", guard.SessionsInBody("session_id"), sessionResourceHandler.DeleteSessionResource)
+	})
+
+	sessionTemplateHandler := session_template.NewHandler(repo.SessionTemplate)
+	apiV1.Route("/session-templates", func(r fiber.Router) {
+		r.Get("/", guard.TherapistQuery("therapist_id"), sessionTemplateHandler.GetTemplates)
+		r.Post("/", guard.TherapistsInBody("therapist_id"), sessionTemplateHandler.CreateTemplate)
+		r.Get("/:id", guard.SessionTemplateParam("id"), sessionTemplateHandler.GetTemplate)
+		r.Patch("/:id", guard.SessionTemplateParam("id"), sessionTemplateHandler.UpdateTemplate)
+		r.Delete("/:id", guard.SessionTemplateParam("id"), sessionTemplateHandler.DeleteTemplate)
 	})
 
-	sessionHandler := session.NewHandler(repo.Session, repo.SessionStudent)
+	sessionHandler := session.NewHandler(repo.Session, repo.SessionStudent, repo.SessionResource, repo.SessionTemplate)
+	sessionImportHandler := session_import.NewHandler(repo.Session, repo.SessionStudent, repo.Student)
+	sessionNoteHandler := session_note.NewHandler(repo.SessionNote)
 
 	apiV1.Route("/sessions", func(r fiber.Router) {
-		r.Get("/", sessionHandler.GetSessions)
-		r.Post("/", sessionHandler.PostSessions)
-		r.Get("/:id", sessionHandler.GetSessionByID)
-		r.Get("/:id/resources", sessionResourceHandler.GetSessionResources)
-		r.Patch("/:id", sessionHandler.PatchSessions)
-		r.Get("/:id/students", sessionHandler.GetSessionStudents)
-		r.Delete("/:id", sessionHandler.DeleteSessions)
-		r.Delete("/:id/recurring", sessionHandler.DeleteRecurringSessions)
+		r.Get("/", guard.TherapistQuery("therapist_id"), sessionHandler.GetSessions)
+		r.Post("/", guard.TherapistsInBody("therapist_id"), guard.StudentsInBody("student_ids"), guard.SessionsInBody("makeup_for_session_id"), guard.SessionTemplatesInBody("template_id"), sessionHandler.PostSessions)
+		r.Post("/import/preview", guard.TherapistQuery("therapist_id"), sessionImportHandler.PreviewImport)
+		r.Post("/import", guard.TherapistQuery("therapist_id"), sessionImportHandler.ImportSessions)
+		r.Get("/conflicts", guard.TherapistQuery("therapist_id"), sessionHandler.GetConflicts)
+		r.Get("/:id", guard.SessionParam("id"), sessionHandler.GetSessionByID)
+		r.Get("/:id/resources", guard.SessionParam("id"), sessionResourceHandler.GetSessionResources)
+		r.Patch("/:id", guard.SessionParam("id"), guard.TherapistsInBody("therapist_id"), sessionHandler.PatchSessions)
+		r.Get("/:id/students", guard.SessionParam("id"), sessionHandler.GetSessionStudents)
+		r.Put("/:id/attendance", guard.SessionParam("id"), sessionHandler.PutAttendance)
+		r.Get("/:id/students/:studentId/notes", guard.SessionParam("id"), sessionNoteHandler.GetNote)
+		r.Put("/:id/students/:studentId/notes", guard.SessionParam("id"), sessionNoteHandler.SaveNote)
+		r.Post("/:id/students/:studentId/notes/sign", guard.SessionParam("id"), sessionNoteHandler.SignNote)
+		r.Delete("/:id/students/:studentId/notes", guard.SessionParam("id"), sessionNoteHandler.DeleteNote)
+		r.Delete("/:id", guard.SessionParam("id"), sessionHandler.DeleteSessions)
+		r.Delete("/:id/recurring", guard.SessionParam("id"), sessionHandler.DeleteRecurringSessions)
+		r.Post("/:id/cancel", guard.SessionParam("id"), sessionHandler.CancelSession)
+		r.Post("/:id/restore", guard.SessionParam("id"), sessionHandler.RestoreSession)
 	})
 
 	gameContentHandler := game_content.NewHandler(repo.GameContent, bucket)
@@ -225,14 +370,32 @@ func SetupApp(config config.Config, repo *storage.Repository, bucket *s3_client.
 
 	gameResultsHandler := game_result.NewHandler(repo.GameResult)
 	apiV1.Route("/game-results", func(r fiber.Router) {
-		r.Get("/", gameResultsHandler.GetGameResults)
-		r.Post("/", gameResultsHandler.PostGameResult)
+		r.Get("/", guard.TherapistQuery("therapist_id"), gameResultsHandler.GetGameResults)
+		r.Post("/", guard.SessionStudentInBody("session_student_id"), gameResultsHandler.PostGameResult)
 	})
 
 	districtHandler := district.NewHandler(repo.District)
+	schoolCalendarHandler := school_calendar.NewHandler(repo.SchoolCalendar)
 	apiV1.Route("/districts", func(r fiber.Router) {
 		r.Get("/", districtHandler.GetDistricts)
 		r.Get("/:id", districtHandler.GetDistrictByID)
+		// Every therapist plans around the school calendar, only administrators change it
+		r.Get("/:id/calendar", schoolCalendarHandler.GetCalendar)
+
+		// Read access across a district is reserved for its administrators
+		r.Route("/:id", func(admin fiber.Router) {
+			admin.Use(guard.RequireRole(models.RoleDistrictAdmin, models.RoleSystemAdmin), guard.DistrictParam("id"))
+			admin.Get("/therapists", districtHandler.GetDistrictTherapists)
+			admin.Get("/students", districtHandler.GetDistrictStudents)
+			admin.Get("/sessions", districtHandler.GetDistrictSessions)
+			admin.Get("/attendance", districtHandler.GetDistrictAttendance)
+			admin.Get("/invitations", invitationHandler.GetInvitations)
+			admin.Post("/invitations", invitationHandler.CreateInvitation)
+			admin.Delete("/invitations/:invitationId", invitationHandler.RevokeInvitation)
+			admin.Post("/calendar", schoolCalendarHandler.CreateCalendarEvent)
+			admin.Patch("/calendar/:eventId", schoolCalendarHandler.UpdateCalendarEvent)
+			admin.Delete("/calendar/:eventId", schoolCalendarHandler.DeleteCalendarEvent)
+		})
 	})
 
 	schoolHandler := school.NewHandler(repo.School)
@@ -252,7 +415,7 @@ func SetupApp(config config.Config, repo *storage.Repository, bucket *s3_client.
 		})
 	})
 
-	app.Get("/secret", supabase_auth.Middleware(&config.Supabase), func(c *fiber.Ctx) error {
+	app.Get("/secret", supabase_auth.Middleware(&config.Supabase, nil, repo.MFA), func(c *fiber.Ctx) error {
 		return c.SendStatus(http.StatusOK)
 	})
 
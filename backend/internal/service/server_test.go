@@ -12,6 +12,7 @@ import (
 	"specialstandard/internal/s3_client"
 	"specialstandard/internal/service/handler/auth"
 	"specialstandard/internal/service/handler/session"
+	"specialstandard/internal/service/mfa"
 	"specialstandard/internal/utils"
 	"strings"
 	"testing"
@@ -705,7 +706,7 @@ func TestDeleteSessionsEndpoint(t *testing.T) {
 		mockRepo := new(mocks.MockSessionRepository)
 		mockRepoSSR := new(mocks.MockSessionStudentRepository)
 
-		handler := session.NewHandler(mockRepo, mockRepoSSR)
+		handler := session.NewHandler(mockRepo, mockRepoSSR, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
 		app.Delete("/sessions/:id", handler.DeleteSessions)
 
 		req := httptest.NewRequest("DELETE", "/sessions/0345", nil)
@@ -924,7 +925,7 @@ func TestHandler_PatchSessions(t *testing.T) {
 		mockRepo := new(mocks.MockSessionRepository)
 		mockRepoSSR := new(mocks.MockSessionStudentRepository)
 
-		handler := session.NewHandler(mockRepo, mockRepoSSR)
+		handler := session.NewHandler(mockRepo, mockRepoSSR, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
 		app.Patch("/sessions/:id", handler.PatchSessions)
 
 		req := httptest.NewRequest("PATCH", "/sessions/0345", nil)
@@ -943,8 +944,11 @@ func TestHandler_PatchSessions(t *testing.T) {
 			mockRepo := new(mocks.MockSessionRepository)
 			mockRepoSSR := new(mocks.MockSessionStudentRepository)
 			tt.mockSetup(mockRepo, tt.id)
+			mockRepo.On("GetSessionByID", mock.Anything, tt.id.String()).
+				Return(&models.Session{ID: tt.id, StartDateTime: time.Now(), EndDateTime: time.Now().Add(time.Hour)}, nil).Maybe()
+			mockRepo.On("FindConflicts", mock.Anything, mock.Anything).Return([]models.SessionConflict{}, nil).Maybe()
 
-			handler := session.NewHandler(mockRepo, mockRepoSSR)
+			handler := session.NewHandler(mockRepo, mockRepoSSR, new(mocks.MockSessionResourceRepository), new(mocks.MockSessionTemplateRepository))
 			app.Patch("/sessions/:id", handler.PatchSessions)
 
 			req := httptest.NewRequest("PATCH", "/sessions/"+tt.id.String(), strings.NewReader(tt.payload))
@@ -1469,6 +1473,8 @@ func TestCreateSessionStudentEndpoint(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockSessionStudentRepo := new(mocks.MockSessionStudentRepository)
 			tt.mockSetup(mockSessionStudentRepo)
+			mockSessionStudentRepo.On("FindStudentConflicts", mock.Anything, mock.Anything, mock.Anything).
+				Return([]models.SessionConflict{}, nil).Maybe()
 
 			repo := &storage.Repository{
 				SessionStudent: mockSessionStudentRepo,
@@ -2207,7 +2213,7 @@ func TestHandler_Signup(t *testing.T) {
 				ServiceRoleKey: "SRK",
 			}
 
-			handler := auth.NewHandler(mockConfig, mockRepo, true)
+			handler := auth.NewHandler(mockConfig, mockRepo, new(mocks.MockAttemptRepository), new(mocks.MockAccountRepository), nil, nil, true)
 			app.Post("/signup", handler.SignUp)
 
 			req := httptest.NewRequest("POST", "/signup", strings.NewReader(tt.payload))
@@ -2274,7 +2280,16 @@ func TestHandler_Login(t *testing.T) {
 				ServiceRoleKey: "SRK",
 			}
 
-			handler := auth.NewHandler(mockConfig, mockRepo, true)
+			attempts := new(mocks.MockAttemptRepository)
+			attempts.On("GetLockedUntil", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			attempts.On("ResetAttempts", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			mfaRepo := new(mocks.MockMFARepository)
+			mfaRepo.On("GetTOTPFactor", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			mfaHandler, err := mfa.NewHandler(mfaRepo, attempts, config.MFA{})
+			assert.NoError(t, err)
+
+			handler := auth.NewHandler(mockConfig, mockRepo, attempts, new(mocks.MockAccountRepository), mfaHandler, nil, true)
 			app.Post("/login", handler.Login)
 
 			req := httptest.NewRequest("POST", "/login", strings.NewReader(tt.payload))
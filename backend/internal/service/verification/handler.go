@@ -1,30 +1,31 @@
 package verification
 
 import (
+	"specialstandard/internal/mailer"
+	"specialstandard/internal/service/lockout"
 	"specialstandard/internal/storage"
 	"specialstandard/internal/storage/postgres/schema"
 
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/resend/resend-go/v3"
 )
 
 type Handler struct {
 	verificationRepo storage.VerificationRepository
 	db               *pgxpool.Pool
-	resendClient     *resend.Client
-	fromEmail        string
+	mailer           *mailer.Mailer
     authRepo         storage.AuthRepository          
+	userLimiter      *lockout.Limiter
+	ipLimiter        *lockout.Limiter
 }
 
 // Createing a new verification handler
-func NewHandler(verificationRepo storage.VerificationRepository, db *pgxpool.Pool, resendApiKey, fromEmail string) *Handler {
-	resendClient := resend.NewClient(resendApiKey)
-	
+func NewHandler(verificationRepo storage.VerificationRepository, attemptRepo storage.AttemptRepository, db *pgxpool.Pool, mail *mailer.Mailer) *Handler {
 	return &Handler{
 		verificationRepo: verificationRepo,
 		db:               db,
-		resendClient:     resendClient,
-		fromEmail:        fromEmail,
+		mailer:           mail,
 		authRepo:         schema.NewAuthRepository(db),
+		userLimiter:      lockout.NewLimiter(attemptRepo, "verify:user", lockout.UserPolicy),
+		ipLimiter:        lockout.NewLimiter(attemptRepo, "verify:ip", lockout.IPPolicy),
 	}
 }
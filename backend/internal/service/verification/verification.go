@@ -1,18 +1,26 @@
 package verification
 
 import (
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"log/slog"
-	"math/rand"
+	"math/big"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/mailer"
 	"specialstandard/internal/models"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/resend/resend-go/v3"
 )
 
-// SendVerificationCode handles sending verification codes via email
+const (
+	// Wrong guesses a single code survives before it is invalidated
+	maxCodeAttempts = 5
+	codeTTL         = 10 * time.Minute
+)
+
 // SendVerificationCode handles sending verification codes via email
 func (h *Handler) SendVerificationCode(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
@@ -40,17 +48,25 @@ func (h *Handler) SendVerificationCode(c *fiber.Ctx) error {
 	}
 
 	// Generate 6-digit verification code
-	code := h.generateVerificationCode()
+	code, err := h.generateVerificationCode()
+	if err != nil {
+		slog.Error("Failed to generate verification code", slog.Any("err", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(models.SendCodeResponse{
+			Success: false,
+			Error:   "Failed to generate verification code",
+		})
+	}
 
 	now := time.Now()
-	expiresAt := now.Add(10 * time.Minute)
+	expiresAt := now.Add(codeTTL)
 
 	// Store verification code in database
 	err = h.verificationRepo.CreateVerificationCode(c.Context(), models.VerificationCode{
-		UserID:    userID,
-		Code:      code,
-		ExpiresAt: expiresAt,
-		CreatedAt: now,
+		UserID:      userID,
+		Code:        code,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   now,
+		MaxAttempts: maxCodeAttempts,
 	})
 
 	if err != nil {
@@ -60,32 +76,27 @@ func (h *Handler) SendVerificationCode(c *fiber.Ctx) error {
 		})
 	}
 
-	// Send email using Resend
-	emailHTML := h.getEmailTemplate(code)
-
-	params := &resend.SendEmailRequest{
-		From:    h.fromEmail,
-		To:      []string{email},
-		Subject: "The Special Standard Verification Code",
-		Html:    emailHTML,
-	}
-
-	sent, err := h.resendClient.Emails.Send(params)
+	// Queued in the outbox, a failed delivery is retried in the background
+	sent, err := h.mailer.Send(c.Context(), []string{email}, mailer.TemplateVerificationCode, mailer.VerificationCodeData{
+		Code:             code,
+		ExpiresInMinutes: int(codeTTL.Minutes()),
+	})
 	if err != nil {
+		slog.Error("Failed to queue verification email", slog.Any("err", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(models.SendCodeResponse{
 			Success: false,
-			Error:   "Failed to send email: " + err.Error(),
+			Error:   "Failed to send email",
 		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(models.SendCodeResponse{
 		Success:   true,
-		MessageID: sent.Id,
+		MessageID: sent.ID.String(),
 	})
 }
 
-// Temporarily update your VerifyCode function with debugging:
-
+// VerifyCode checks the code sent by SendVerificationCode. Each code dies after
+// maxCodeAttempts wrong guesses and repeated failures lock the user out for a while.
 func (h *Handler) VerifyCode(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
 
@@ -106,6 +117,10 @@ func (h *Handler) VerifyCode(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := h.checkLockout(c, userID); err != nil {
+		return err
+	}
+
 	// Verify the code
 	valid, err := h.verificationRepo.VerifyCode(c.Context(), userID, code)
 	if err != nil {
@@ -116,6 +131,9 @@ func (h *Handler) VerifyCode(c *fiber.Ctx) error {
 	}
 
 	if !valid {
+		h.userLimiter.Fail(c.Context(), userID)
+		h.ipLimiter.Fail(c.Context(), c.IP())
+
 		return c.Status(fiber.StatusBadRequest).JSON(models.VerifyCodeResponse{
 			Success:  false,
 			Verified: false,
@@ -123,6 +141,8 @@ func (h *Handler) VerifyCode(c *fiber.Ctx) error {
 		})
 	}
 
+	h.userLimiter.Reset(c.Context(), userID)
+
 	err = h.authRepo.MarkEmailVerified(c.Context(), userID)
 	if err != nil {
 		slog.Warn("Failed to update user metadata", slog.Any("err", err))
@@ -139,45 +159,27 @@ func (h *Handler) ResendCode(c *fiber.Ctx) error {
 	return h.SendVerificationCode(c)
 }
 
-func (h *Handler) generateVerificationCode() string {
-	return fmt.Sprintf("%06d", rand.Intn(900000)+100000)
+// checkLockout answers 429 in the shape of the other verification responses
+func (h *Handler) checkLockout(c *fiber.Ctx, userID string) error {
+	err := h.userLimiter.Check(c, userID)
+	if err == nil {
+		err = h.ipLimiter.Check(c, c.IP())
+	}
+
+	var httpErr errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return c.Status(httpErr.Code).JSON(models.VerifyCodeResponse{
+			Success: false,
+			Error:   fmt.Sprint(httpErr.Message),
+		})
+	}
+	return err
 }
 
-// getEmailTemplate returns the HTML email template
-func (h *Handler) getEmailTemplate(code string) string {
-	return fmt.Sprintf(`
-	<!DOCTYPE html>
-	<html>
-	<head>
-		<meta charset="UTF-8">
-		<meta name="viewport" content="width=device-width, initial-scale=1.0">
-	</head>
-	<body>
-		<div style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; max-width: 600px; margin: 0 auto; padding: 20px;">
-			<div style="background-color: #ffffff; border-radius: 10px; padding: 30px; box-shadow: 0 2px 10px rgba(0,0,0,0.1);">
-				<h1 style="color: #333333; font-size: 24px; margin-bottom: 10px;">Verify Your Email</h1>
-				<p style="color: #666666; font-size: 16px; line-height: 1.5; margin-bottom: 30px;">
-					Please use the verification code below to confirm your email address.
-				</p>
-				
-				<div style="background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); padding: 20px; text-align: center; border-radius: 8px; margin-bottom: 30px;">
-					<div style="background: white; border-radius: 6px; padding: 15px; display: inline-block;">
-						<span style="font-size: 32px; font-weight: bold; letter-spacing: 8px; color: #333333;">%s</span>
-					</div>
-				</div>
-				
-				<p style="color: #666666; font-size: 14px; line-height: 1.5;">
-					This code will expire in <strong>10 minutes</strong>.
-				</p>
-				
-				<hr style="border: none; border-top: 1px solid #eeeeee; margin: 30px 0;">
-				
-				<p style="color: #999999; font-size: 13px; line-height: 1.5;">
-					If you didn't request this verification code, you can safely ignore this email.
-				</p>
-			</div>
-		</div>
-	</body>
-	</html>
-	`, code)
+func (h *Handler) generateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
 }
@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccessRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccessRepository) GetAccessibleTherapistIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockAccessRepository) GetSessionOwners(ctx context.Context, sessionIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	args := m.Called(ctx, sessionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]uuid.UUID), args.Error(1)
+}
+
+func (m *MockAccessRepository) GetStudentOwners(ctx context.Context, studentIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	args := m.Called(ctx, studentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]uuid.UUID), args.Error(1)
+}
+
+func (m *MockAccessRepository) GetSessionStudentOwners(ctx context.Context, sessionStudentIDs []int) (map[int]uuid.UUID, error) {
+	args := m.Called(ctx, sessionStudentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]uuid.UUID), args.Error(1)
+}
+
+func (m *MockAccessRepository) GetSessionTemplateOwners(ctx context.Context, templateIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	args := m.Called(ctx, templateIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]uuid.UUID), args.Error(1)
+}
+
+func (m *MockAccessRepository) GetPrincipal(ctx context.Context, userID uuid.UUID) (*models.Principal, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Principal), args.Error(1)
+}
+
+func (m *MockAccessRepository) GetDistrictTherapistIDs(ctx context.Context, districtID int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, districtID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/dbinterface"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) Begin(ctx context.Context) (pgx.Tx, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(pgx.Tx), args.Error(1)
+}
+
+func (m *MockAccountRepository) ExportTherapistData(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) (*models.TherapistExport, error) {
+	args := m.Called(ctx, q, therapistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TherapistExport), args.Error(1)
+}
+
+func (m *MockAccountRepository) TransferCaseload(ctx context.Context, q dbinterface.Queryable, fromID, toID uuid.UUID) (int, error) {
+	args := m.Called(ctx, q, fromID, toID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountRepository) ArchiveStudents(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) (int, error) {
+	args := m.Called(ctx, q, therapistID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountRepository) DeleteTherapistAccount(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) error {
+	args := m.Called(ctx, q, therapistID)
+	return args.Error(0)
+}
@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, createdBy uuid.UUID, prefix, keyHash string, input *models.CreateAPIKeyInput) (*models.APIKey, error) {
+	args := m.Called(ctx, createdBy, prefix, keyHash, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeys(ctx context.Context, createdBy uuid.UUID) ([]models.APIKey, error) {
+	args := m.Called(ctx, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByID(ctx context.Context, id, createdBy uuid.UUID) (*models.APIKey, error) {
+	args := m.Called(ctx, id, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) UpdateAPIKey(ctx context.Context, id, createdBy uuid.UUID, input *models.UpdateAPIKeyInput) (*models.APIKey, error) {
+	args := m.Called(ctx, id, createdBy, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, id, createdBy uuid.UUID) error {
+	args := m.Called(ctx, id, createdBy)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) AuthenticateAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
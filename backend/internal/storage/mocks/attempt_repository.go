@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttemptRepository) GetLockedUntil(ctx context.Context, key string) (*time.Time, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	args := m.Called(ctx, key, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAttemptRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	args := m.Called(ctx, key, until)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) ResetAttempts(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
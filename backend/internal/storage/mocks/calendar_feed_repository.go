@@ -0,0 +1,67 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCalendarFeedRepository struct {
+	mock.Mock
+}
+
+func (m *MockCalendarFeedRepository) CreateCalendarFeed(ctx context.Context, therapistID uuid.UUID, tokenHash string, input *models.CalendarFeedInput) (*models.CalendarFeed, error) {
+	args := m.Called(ctx, therapistID, tokenHash, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CalendarFeed), args.Error(1)
+}
+
+func (m *MockCalendarFeedRepository) GetCalendarFeed(ctx context.Context, therapistID uuid.UUID) (*models.CalendarFeed, error) {
+	args := m.Called(ctx, therapistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CalendarFeed), args.Error(1)
+}
+
+func (m *MockCalendarFeedRepository) GetCalendarFeedByToken(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CalendarFeed), args.Error(1)
+}
+
+func (m *MockCalendarFeedRepository) UpdateCalendarFeed(ctx context.Context, therapistID uuid.UUID, input *models.CalendarFeedInput) (*models.CalendarFeed, error) {
+	args := m.Called(ctx, therapistID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CalendarFeed), args.Error(1)
+}
+
+func (m *MockCalendarFeedRepository) DeleteCalendarFeed(ctx context.Context, therapistID uuid.UUID) error {
+	args := m.Called(ctx, therapistID)
+	return args.Error(0)
+}
+
+func (m *MockCalendarFeedRepository) GetCalendarSessions(ctx context.Context, therapistID uuid.UUID) ([]models.CalendarSession, error) {
+	args := m.Called(ctx, therapistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CalendarSession), args.Error(1)
+}
+
+func (m *MockCalendarFeedRepository) GetCalendarLocation(ctx context.Context, therapistID uuid.UUID) (*time.Location, error) {
+	args := m.Called(ctx, therapistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Location), args.Error(1)
+}
@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"specialstandard/internal/utils"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDistrictRepository struct {
+	mock.Mock
+}
+
+func (m *MockDistrictRepository) GetDistricts(ctx context.Context) ([]models.District, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.District), args.Error(1)
+}
+
+func (m *MockDistrictRepository) GetDistrictByID(ctx context.Context, id int) (*models.District, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.District), args.Error(1)
+}
+
+func (m *MockDistrictRepository) GetDistrictTherapists(ctx context.Context, districtID int, pagination utils.Pagination) ([]models.Therapist, error) {
+	args := m.Called(ctx, districtID, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Therapist), args.Error(1)
+}
+
+func (m *MockDistrictRepository) GetDistrictStudents(ctx context.Context, districtID int, schoolID *int, pagination utils.Pagination) ([]models.Student, error) {
+	args := m.Called(ctx, districtID, schoolID, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Student), args.Error(1)
+}
+
+func (m *MockDistrictRepository) GetDistrictSessions(ctx context.Context, districtID int, filter *models.GetDistrictSessionsQuery, pagination utils.Pagination) ([]models.Session, error) {
+	args := m.Called(ctx, districtID, filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Session), args.Error(1)
+}
+
+func (m *MockDistrictRepository) GetDistrictAttendance(ctx context.Context, districtID int, dateFrom, dateTo time.Time) ([]models.StudentAttendance, error) {
+	args := m.Called(ctx, districtID, dateFrom, dateTo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StudentAttendance), args.Error(1)
+}
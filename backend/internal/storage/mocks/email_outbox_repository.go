@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmailOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailOutboxRepository) EnqueueEmail(ctx context.Context, input *models.CreateOutboxEmailInput) (*models.OutboxEmail, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OutboxEmail), args.Error(1)
+}
+
+func (m *MockEmailOutboxRepository) ClaimDueEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OutboxEmail), args.Error(1)
+}
+
+func (m *MockEmailOutboxRepository) MarkEmailSent(ctx context.Context, id uuid.UUID, providerMessageID string) error {
+	args := m.Called(ctx, id, providerMessageID)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) MarkEmailFailed(ctx context.Context, id uuid.UUID, lastError string, retryAt *time.Time) error {
+	args := m.Called(ctx, id, lastError, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) DeleteFinishedEmails(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
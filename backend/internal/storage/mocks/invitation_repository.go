@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/dbinterface"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) CreateInvitation(ctx context.Context, districtID int, invitedBy uuid.UUID, input *models.CreateInvitationInput, expiresAt time.Time) (*models.Invitation, error) {
+	args := m.Called(ctx, districtID, invitedBy, input, expiresAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetPendingInvitations(ctx context.Context, districtID int) ([]models.Invitation, error) {
+	args := m.Called(ctx, districtID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetInvitationPreview(ctx context.Context, id uuid.UUID) (*models.InvitationPreview, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InvitationPreview), args.Error(1)
+}
+
+func (m *MockInvitationRepository) RevokeInvitation(ctx context.Context, districtID int, id uuid.UUID) error {
+	args := m.Called(ctx, districtID, id)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) Begin(ctx context.Context) (pgx.Tx, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(pgx.Tx), args.Error(1)
+}
+
+func (m *MockInvitationRepository) ClaimInvitation(ctx context.Context, q dbinterface.Queryable, id uuid.UUID) (*models.Invitation, error) {
+	args := m.Called(ctx, q, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) AcceptInvitation(ctx context.Context, q dbinterface.Queryable, id uuid.UUID, input *models.CreateTherapistInput) (*models.Therapist, error) {
+	args := m.Called(ctx, q, id, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Therapist), args.Error(1)
+}
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockJobRunRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRunRepository) EnqueueJob(ctx context.Context, input *models.EnqueueJobInput) (*models.JobRun, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JobRun), args.Error(1)
+}
+
+func (m *MockJobRunRepository) ClaimDueJobs(ctx context.Context, names []string, workerID string, limit int, lease time.Duration) ([]models.JobRun, error) {
+	args := m.Called(ctx, names, workerID, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.JobRun), args.Error(1)
+}
+
+func (m *MockJobRunRepository) CompleteJob(ctx context.Context, id uuid.UUID, workerID string) error {
+	args := m.Called(ctx, id, workerID)
+	return args.Error(0)
+}
+
+func (m *MockJobRunRepository) FailJob(ctx context.Context, id uuid.UUID, workerID, lastError string, retryAt *time.Time) error {
+	args := m.Called(ctx, id, workerID, lastError, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockJobRunRepository) GetJobRuns(ctx context.Context, query *models.GetJobRunsQuery) ([]models.JobRun, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.JobRun), args.Error(1)
+}
+
+func (m *MockJobRunRepository) DeleteFinishedJobRuns(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMFARepository struct {
+	mock.Mock
+}
+
+func (m *MockMFARepository) GetTOTPFactor(ctx context.Context, therapistID uuid.UUID) (*models.TOTPFactor, error) {
+	args := m.Called(ctx, therapistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TOTPFactor), args.Error(1)
+}
+
+func (m *MockMFARepository) SavePendingTOTP(ctx context.Context, therapistID uuid.UUID, secretSealed string) error {
+	args := m.Called(ctx, therapistID, secretSealed)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) EnableTOTP(ctx context.Context, therapistID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	args := m.Called(ctx, therapistID, step, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseTOTPStep(ctx context.Context, therapistID uuid.UUID, step int64) (bool, error) {
+	args := m.Called(ctx, therapistID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, therapistID uuid.UUID, codeHash string) (bool, error) {
+	args := m.Called(ctx, therapistID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx context.Context, therapistID uuid.UUID, codeHashes []string) error {
+	args := m.Called(ctx, therapistID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) DeleteTOTP(ctx context.Context, therapistID uuid.UUID) error {
+	args := m.Called(ctx, therapistID)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) CreateMFAChallenge(ctx context.Context, therapistID uuid.UUID, tokenHash, refreshToken string, rememberMe bool, expiresAt time.Time) error {
+	args := m.Called(ctx, therapistID, tokenHash, refreshToken, rememberMe, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) GetMFAChallenge(ctx context.Context, tokenHash string) (*models.MFAChallenge, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MFAChallenge), args.Error(1)
+}
+
+func (m *MockMFARepository) DeleteMFAChallenge(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) RecordMFASession(ctx context.Context, therapistID, sessionID uuid.UUID) error {
+	args := m.Called(ctx, therapistID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) SessionPassedMFA(ctx context.Context, therapistID, sessionID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, therapistID, sessionID)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) GetNotificationPreference(ctx context.Context, therapistID uuid.UUID) (*models.NotificationPreference, error) {
+	args := m.Called(ctx, therapistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationRepository) UpdateNotificationPreference(ctx context.Context, therapistID uuid.UUID, input *models.UpdateNotificationPreferenceInput) (*models.NotificationPreference, error) {
+	args := m.Called(ctx, therapistID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetNotificationRecipients(ctx context.Context) ([]models.NotificationRecipient, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NotificationRecipient), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ClaimNotification(ctx context.Context, therapistID uuid.UUID, kind, dedupeKey string) (bool, error) {
+	args := m.Called(ctx, therapistID, kind, dedupeKey)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ReleaseNotification(ctx context.Context, dedupeKey string) error {
+	args := m.Called(ctx, dedupeKey)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) DeleteSentNotifications(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
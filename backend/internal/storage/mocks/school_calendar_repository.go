@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSchoolCalendarRepository struct {
+	mock.Mock
+}
+
+func (m *MockSchoolCalendarRepository) GetCalendarEvents(ctx context.Context, districtID int, query *models.GetSchoolCalendarQuery) ([]models.SchoolCalendarEvent, error) {
+	args := m.Called(ctx, districtID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SchoolCalendarEvent), args.Error(1)
+}
+
+func (m *MockSchoolCalendarRepository) GetCalendarEvent(ctx context.Context, districtID int, id uuid.UUID) (*models.SchoolCalendarEvent, error) {
+	args := m.Called(ctx, districtID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SchoolCalendarEvent), args.Error(1)
+}
+
+func (m *MockSchoolCalendarRepository) CreateCalendarEvent(ctx context.Context, event *models.SchoolCalendarEvent) (*models.SchoolCalendarEvent, error) {
+	args := m.Called(ctx, event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SchoolCalendarEvent), args.Error(1)
+}
+
+func (m *MockSchoolCalendarRepository) UpdateCalendarEvent(ctx context.Context, event *models.SchoolCalendarEvent) (*models.SchoolCalendarEvent, error) {
+	args := m.Called(ctx, event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SchoolCalendarEvent), args.Error(1)
+}
+
+func (m *MockSchoolCalendarRepository) DeleteCalendarEvent(ctx context.Context, districtID int, id uuid.UUID) error {
+	args := m.Called(ctx, districtID, id)
+	return args.Error(0)
+}
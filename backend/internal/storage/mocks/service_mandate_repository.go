@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockServiceMandateRepository struct {
+	mock.Mock
+}
+
+func (m *MockServiceMandateRepository) GetMandates(ctx context.Context, studentID uuid.UUID) ([]models.ServiceMandate, error) {
+	args := m.Called(ctx, studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ServiceMandate), args.Error(1)
+}
+
+func (m *MockServiceMandateRepository) GetMandate(ctx context.Context, studentID, id uuid.UUID) (*models.ServiceMandate, error) {
+	args := m.Called(ctx, studentID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ServiceMandate), args.Error(1)
+}
+
+func (m *MockServiceMandateRepository) CreateMandate(ctx context.Context, mandate *models.ServiceMandate) (*models.ServiceMandate, error) {
+	args := m.Called(ctx, mandate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ServiceMandate), args.Error(1)
+}
+
+func (m *MockServiceMandateRepository) UpdateMandate(ctx context.Context, mandate *models.ServiceMandate) (*models.ServiceMandate, error) {
+	args := m.Called(ctx, mandate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ServiceMandate), args.Error(1)
+}
+
+func (m *MockServiceMandateRepository) DeleteMandate(ctx context.Context, studentID, id uuid.UUID) error {
+	args := m.Called(ctx, studentID, id)
+	return args.Error(0)
+}
+
+func (m *MockServiceMandateRepository) GetServiceRecords(ctx context.Context, query *models.ServiceRecordQuery) ([]models.ServiceRecord, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ServiceRecord), args.Error(1)
+}
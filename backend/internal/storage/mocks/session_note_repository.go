@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSessionNoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionNoteRepository) GetNote(ctx context.Context, sessionID, studentID uuid.UUID) (*models.SessionNote, error) {
+	args := m.Called(ctx, sessionID, studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SessionNote), args.Error(1)
+}
+
+func (m *MockSessionNoteRepository) SaveNote(ctx context.Context, sessionID, studentID uuid.UUID, input *models.SaveSessionNoteInput) (*models.SessionNote, error) {
+	args := m.Called(ctx, sessionID, studentID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SessionNote), args.Error(1)
+}
+
+func (m *MockSessionNoteRepository) SignNote(ctx context.Context, sessionID, studentID, signedBy uuid.UUID) (*models.SessionNote, error) {
+	args := m.Called(ctx, sessionID, studentID, signedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SessionNote), args.Error(1)
+}
+
+func (m *MockSessionNoteRepository) DeleteNote(ctx context.Context, sessionID, studentID uuid.UUID) error {
+	args := m.Called(ctx, sessionID, studentID)
+	return args.Error(0)
+}
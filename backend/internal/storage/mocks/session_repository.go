@@ -5,6 +5,7 @@ import (
 	"specialstandard/internal/models"
 	"specialstandard/internal/storage/dbinterface"
 	"specialstandard/internal/utils"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -67,6 +68,54 @@ func (m *MockSessionRepository) PatchSession(ctx context.Context, id uuid.UUID,
 	return args.Get(0).(*models.Session), args.Error(1)
 }
 
+func (m *MockSessionRepository) PatchRecurringSessions(ctx context.Context, id uuid.UUID, scope string, session *models.PatchSessionInput) (*[]models.Session, error) {
+	args := m.Called(ctx, id, scope, session)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]models.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) UpdateSessionStatus(ctx context.Context, id uuid.UUID, status string, reason *string) (*models.Session, error) {
+	args := m.Called(ctx, id, status, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) GetSessionConflicts(ctx context.Context, therapistID uuid.UUID, from, to time.Time) ([]models.SessionConflict, error) {
+	args := m.Called(ctx, therapistID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SessionConflict), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindConflicts(ctx context.Context, check *models.ConflictCheck) ([]models.SessionConflict, error) {
+	args := m.Called(ctx, check)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SessionConflict), args.Error(1)
+}
+
+func (m *MockSessionRepository) GetTherapistLocation(ctx context.Context, therapistID uuid.UUID) (*time.Location, error) {
+	args := m.Called(ctx, therapistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Location), args.Error(1)
+}
+
+func (m *MockSessionRepository) GetConflictReport(ctx context.Context, therapistID uuid.UUID, from, to time.Time) ([]models.SessionOverlap, error) {
+	args := m.Called(ctx, therapistID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SessionOverlap), args.Error(1)
+}
+
 func (m *MockSessionRepository) GetDB() *pgxpool.Pool {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -74,3 +123,11 @@ func (m *MockSessionRepository) GetDB() *pgxpool.Pool {
 	}
 	return args.Get(0).(*pgxpool.Pool)
 }
+
+func (m *MockSessionRepository) GetOwedMinutes(ctx context.Context, sessionID uuid.UUID) ([]models.OwedMinutes, error) {
+	args := m.Called(ctx, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OwedMinutes), args.Error(1)
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"specialstandard/internal/models"
+	"specialstandard/internal/storage/dbinterface"
 	"specialstandard/internal/utils"
 
 	"github.com/google/uuid"
@@ -21,6 +22,11 @@ func (m *MockSessionResourceRepository) PostSessionResource(ctx context.Context,
 	return args.Get(0).(*models.SessionResource), args.Error(1)
 }
 
+func (m *MockSessionResourceRepository) AddSessionResources(ctx context.Context, q dbinterface.Queryable, sessionIDs, resourceIDs []uuid.UUID) error {
+	args := m.Called(ctx, q, sessionIDs, resourceIDs)
+	return args.Error(0)
+}
+
 func (m *MockSessionResourceRepository) DeleteSessionResource(ctx context.Context, sessionResource models.DeleteSessionResource) error {
 	args := m.Called(mock.Anything, sessionResource)
 	return args.Error(0)
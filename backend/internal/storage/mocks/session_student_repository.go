@@ -5,6 +5,7 @@ import (
 	"specialstandard/internal/models"
 	"specialstandard/internal/storage/dbinterface"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/mock"
 )
@@ -22,6 +23,14 @@ func (m *MockSessionStudentRepository) CreateSessionStudent(ctx context.Context,
 	return args.Get(0).(*[]models.SessionStudent), args.Error(1)
 }
 
+func (m *MockSessionStudentRepository) FindStudentConflicts(ctx context.Context, sessionIDs, studentIDs []uuid.UUID) ([]models.SessionConflict, error) {
+	args := m.Called(ctx, sessionIDs, studentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SessionConflict), args.Error(1)
+}
+
 // DeleteSessionStudent - Updated to accept queryable as second parameter for transaction support
 func (m *MockSessionStudentRepository) DeleteSessionStudent(ctx context.Context, input *models.DeleteSessionStudentInput) error {
 	args := m.Called(ctx, input)
@@ -43,6 +52,14 @@ func (m *MockSessionStudentRepository) RateStudentSession(ctx context.Context, i
 	return args.Get(0).(*models.SessionStudent), args.Get(1).([]models.SessionRating), args.Error(2)
 }
 
+func (m *MockSessionStudentRepository) SaveAttendance(ctx context.Context, sessionID uuid.UUID, input *models.SessionAttendanceInput) ([]models.SessionStudentsOutput, error) {
+	args := m.Called(ctx, sessionID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SessionStudentsOutput), args.Error(1)
+}
+
 func (m *MockSessionStudentRepository) GetStudentAttendance(ctx context.Context, params models.GetStudentAttendanceParams) (*int, *int, error) {
 	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+	"specialstandard/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSessionTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionTemplateRepository) GetTemplates(ctx context.Context, therapistID uuid.UUID) ([]models.SessionTemplate, error) {
+	args := m.Called(ctx, therapistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SessionTemplate), args.Error(1)
+}
+
+func (m *MockSessionTemplateRepository) GetTemplate(ctx context.Context, id uuid.UUID) (*models.SessionTemplate, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SessionTemplate), args.Error(1)
+}
+
+func (m *MockSessionTemplateRepository) CreateTemplate(ctx context.Context, template *models.SessionTemplate) (*models.SessionTemplate, error) {
+	args := m.Called(ctx, template)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SessionTemplate), args.Error(1)
+}
+
+func (m *MockSessionTemplateRepository) UpdateTemplate(ctx context.Context, template *models.SessionTemplate) (*models.SessionTemplate, error) {
+	args := m.Called(ctx, template)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SessionTemplate), args.Error(1)
+}
+
+func (m *MockSessionTemplateRepository) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -64,3 +64,11 @@ func (m *MockStudentRepository) PromoteStudents(ctx context.Context, input model
 	args := m.Called(ctx, input)
 	return args.Error(0)
 }
+
+func (m *MockStudentRepository) GetMakeupLedger(ctx context.Context, studentID uuid.UUID) ([]models.MakeupEntry, error) {
+	args := m.Called(ctx, studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.MakeupEntry), args.Error(1)
+}
@@ -5,6 +5,7 @@ import (
 	"specialstandard/internal/models"
 	"specialstandard/internal/utils"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -52,3 +53,32 @@ func (m *MockTherapistRepository) PatchTherapist(ctx context.Context, therapistI
 
 	return args.Get(0).(*models.Therapist), args.Error(1)
 }
+
+func (m *MockTherapistRepository) GetDelegates(ctx context.Context, therapistID uuid.UUID) ([]models.TherapistDelegate, error) {
+	args := m.Called(ctx, therapistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TherapistDelegate), args.Error(1)
+}
+
+func (m *MockTherapistRepository) AddDelegate(ctx context.Context, therapistID, delegateID uuid.UUID) (*models.TherapistDelegate, error) {
+	args := m.Called(ctx, therapistID, delegateID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TherapistDelegate), args.Error(1)
+}
+
+func (m *MockTherapistRepository) RemoveDelegate(ctx context.Context, therapistID, delegateID uuid.UUID) error {
+	args := m.Called(ctx, therapistID, delegateID)
+	return args.Error(0)
+}
+
+func (m *MockTherapistRepository) UpdateTherapistRole(ctx context.Context, therapistID uuid.UUID, role string) (*models.Therapist, error) {
+	args := m.Called(ctx, therapistID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Therapist), args.Error(1)
+}
@@ -0,0 +1,150 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"specialstandard/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Therapists belong to a district either directly or through one of their schools
+const districtTherapistsQuery = `
+	SELECT t.id
+	FROM therapist t
+	WHERE t.district_id = $1
+	   OR t.schools && (SELECT COALESCE(array_agg(id), '{}') FROM school WHERE district_id = $1)`
+
+type AccessRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAccessRepository(db *pgxpool.Pool) *AccessRepository {
+	return &AccessRepository{db: db}
+}
+
+// GetPrincipal returns the role of the user. Users without a therapist record yet (e.g.
+// mid signup) are plain therapists.
+func (r *AccessRepository) GetPrincipal(ctx context.Context, userID uuid.UUID) (*models.Principal, error) {
+	query := `SELECT id, role, district_id FROM therapist WHERE id = $1`
+
+	principal := models.Principal{TherapistID: userID, Role: models.RoleTherapist}
+	err := r.db.QueryRow(ctx, query, userID).Scan(&principal.TherapistID, &principal.Role, &principal.DistrictID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	return &principal, nil
+}
+
+// GetDistrictTherapistIDs returns every therapist working in the district
+func (r *AccessRepository) GetDistrictTherapistIDs(ctx context.Context, districtID int) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, districtTherapistsQuery, districtID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// GetAccessibleTherapistIDs returns the user's own therapist ID plus every therapist who
+// made the user a delegate of their caseload
+func (r *AccessRepository) GetAccessibleTherapistIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+	SELECT $1::uuid
+	UNION
+	SELECT therapist_id FROM therapist_delegate WHERE delegate_id = $1`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetSessionOwners maps each existing session to the therapist of its session_parent.
+// IDs that do not exist are left out of the map.
+func (r *AccessRepository) GetSessionOwners(ctx context.Context, sessionIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	query := `
+	SELECT s.id, sp.therapist_id
+	FROM session s
+	JOIN session_parent sp ON s.session_parent_id = sp.id
+	WHERE s.id = ANY($1)`
+
+	return r.collectOwners(ctx, query, sessionIDs)
+}
+
+// GetStudentOwners maps each existing student to the therapist whose caseload they are on
+func (r *AccessRepository) GetStudentOwners(ctx context.Context, studentIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	query := `SELECT id, therapist_id FROM student WHERE id = ANY($1)`
+
+	return r.collectOwners(ctx, query, studentIDs)
+}
+
+// GetSessionTemplateOwners maps each existing session template to the therapist it belongs to
+func (r *AccessRepository) GetSessionTemplateOwners(ctx context.Context, templateIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	query := `SELECT id, therapist_id FROM session_template WHERE id = ANY($1)`
+
+	return r.collectOwners(ctx, query, templateIDs)
+}
+
+// GetSessionStudentOwners maps session_student rows to the therapist running the session
+func (r *AccessRepository) GetSessionStudentOwners(ctx context.Context, sessionStudentIDs []int) (map[int]uuid.UUID, error) {
+	query := `
+	SELECT ss.id, sp.therapist_id
+	FROM session_student ss
+	JOIN session s ON ss.session_id = s.id
+	JOIN session_parent sp ON s.session_parent_id = sp.id
+	WHERE ss.id = ANY($1)`
+
+	rows, err := r.db.Query(ctx, query, sessionStudentIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owners := make(map[int]uuid.UUID, len(sessionStudentIDs))
+	for rows.Next() {
+		var id int
+		var therapistID uuid.UUID
+		if err := rows.Scan(&id, &therapistID); err != nil {
+			return nil, err
+		}
+		owners[id] = therapistID
+	}
+
+	return owners, rows.Err()
+}
+
+func (r *AccessRepository) collectOwners(ctx context.Context, query string, ids []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owners := make(map[uuid.UUID]uuid.UUID, len(ids))
+	for rows.Next() {
+		var id, therapistID uuid.UUID
+		if err := rows.Scan(&id, &therapistID); err != nil {
+			return nil, err
+		}
+		owners[id] = therapistID
+	}
+
+	return owners, rows.Err()
+}
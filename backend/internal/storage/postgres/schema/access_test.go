@@ -0,0 +1,159 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessRepository_Ownership(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewAccessRepository(testDB)
+	therapistRepo := schema.NewTherapistRepository(testDB)
+	ctx := context.Background()
+
+	_, err := testDB.Exec(ctx, `
+		INSERT INTO district (id, name) VALUES (1, 'Test District') ON CONFLICT (id) DO NOTHING
+	`)
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO school (id, name, district_id) VALUES (1, 'Test School', 1) ON CONFLICT (id) DO NOTHING
+	`)
+	require.NoError(t, err)
+
+	owner := uuid.New()
+	delegate := uuid.New()
+	stranger := uuid.New()
+	for i, id := range []uuid.UUID{owner, delegate, stranger} {
+		_, err = testDB.Exec(ctx, `
+			INSERT INTO therapist (id, first_name, last_name, email)
+			VALUES ($1, 'Test', 'Therapist', $2)
+		`, id, id.String()+"@example.com")
+		require.NoError(t, err, "therapist %d", i)
+	}
+
+	studentID := uuid.New()
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO student (id, first_name, last_name, therapist_id, school_id)
+		VALUES ($1, 'Emma', 'Johnson', $2, 1)
+	`, studentID, owner)
+	require.NoError(t, err)
+
+	parentID := uuid.New()
+	sessionID := uuid.New()
+	start := time.Now().Truncate(time.Hour)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO session_parent (id, start_date, end_date, therapist_id)
+		VALUES ($1, $2, $2, $3)
+	`, parentID, start, owner)
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO session (id, start_datetime, end_datetime, session_parent_id)
+		VALUES ($1, $2, $3, $4)
+	`, sessionID, start, start.Add(time.Hour), parentID)
+	require.NoError(t, err)
+
+	var sessionStudentID int
+	err = testDB.QueryRow(ctx, `
+		INSERT INTO session_student (session_id, student_id) VALUES ($1, $2) RETURNING id
+	`, sessionID, studentID).Scan(&sessionStudentID)
+	require.NoError(t, err)
+
+	// Without a delegation the delegate only sees themselves
+	ids, err := repo.GetAccessibleTherapistIDs(ctx, delegate)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{delegate}, ids)
+
+	_, err = therapistRepo.AddDelegate(ctx, owner, delegate)
+	require.NoError(t, err)
+
+	ids, err = repo.GetAccessibleTherapistIDs(ctx, delegate)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{delegate, owner}, ids)
+
+	ids, err = repo.GetAccessibleTherapistIDs(ctx, stranger)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{stranger}, ids)
+
+	missing := uuid.New()
+
+	sessionOwners, err := repo.GetSessionOwners(ctx, []uuid.UUID{sessionID, missing})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]uuid.UUID{sessionID: owner}, sessionOwners)
+
+	studentOwners, err := repo.GetStudentOwners(ctx, []uuid.UUID{studentID, missing})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]uuid.UUID{studentID: owner}, studentOwners)
+
+	sessionStudentOwners, err := repo.GetSessionStudentOwners(ctx, []int{sessionStudentID, sessionStudentID + 1000})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]uuid.UUID{sessionStudentID: owner}, sessionStudentOwners)
+
+	// Revoking the delegation removes access again
+	require.NoError(t, therapistRepo.RemoveDelegate(ctx, owner, delegate))
+	ids, err = repo.GetAccessibleTherapistIDs(ctx, delegate)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{delegate}, ids)
+}
+
+func TestAccessRepository_Principal(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewAccessRepository(testDB)
+	ctx := context.Background()
+
+	_, err := testDB.Exec(ctx, `
+		INSERT INTO district (id, name) VALUES (1, 'Test District'), (2, 'Other District') ON CONFLICT (id) DO NOTHING
+	`)
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO school (id, name, district_id) VALUES (1, 'Test School', 1), (2, 'Other School', 2) ON CONFLICT (id) DO NOTHING
+	`)
+	require.NoError(t, err)
+
+	admin := uuid.New()
+	inDistrict := uuid.New()
+	viaSchool := uuid.New()
+	elsewhere := uuid.New()
+
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO therapist (id, first_name, last_name, email, schools, district_id, role) VALUES
+			($1, 'Ada', 'Admin', 'admin@example.com', '{}', 1, 'district_admin'),
+			($2, 'In', 'District', 'in@example.com', '{}', 1, 'therapist'),
+			($3, 'Via', 'School', 'via@example.com', '{1}', NULL, 'therapist'),
+			($4, 'Else', 'Where', 'else@example.com', '{2}', 2, 'therapist')
+	`, admin, inDistrict, viaSchool, elsewhere)
+	require.NoError(t, err)
+
+	principal, err := repo.GetPrincipal(ctx, admin)
+	require.NoError(t, err)
+	assert.Equal(t, "district_admin", principal.Role)
+	require.NotNil(t, principal.DistrictID)
+	assert.Equal(t, 1, *principal.DistrictID)
+
+	// Users without a therapist record are plain therapists
+	unknown := uuid.New()
+	principal, err = repo.GetPrincipal(ctx, unknown)
+	require.NoError(t, err)
+	assert.Equal(t, unknown, principal.TherapistID)
+	assert.Equal(t, "therapist", principal.Role)
+	assert.Nil(t, principal.DistrictID)
+
+	ids, err := repo.GetDistrictTherapistIDs(ctx, 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{admin, inDistrict, viaSchool}, ids)
+}
@@ -0,0 +1,209 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/dbinterface"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// session_student rows belonging to the caseload: attendance in the therapist's sessions
+// and anything recorded for the therapist's students in someone else's session
+const caseloadSessionStudentsCTE = `
+	WITH caseload_ss AS (
+		SELECT ss.id
+		FROM session_student ss
+		JOIN session s ON ss.session_id = s.id
+		JOIN session_parent sp ON s.session_parent_id = sp.id
+		WHERE sp.therapist_id = $1
+		UNION
+		SELECT ss.id
+		FROM session_student ss
+		JOIN student st ON ss.student_id = st.id
+		WHERE st.therapist_id = $1
+	)`
+
+type AccountRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAccountRepository(db *pgxpool.Pool) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// Begin starts the transaction an account deletion runs in
+func (r *AccountRepository) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.db.Begin(ctx)
+}
+
+func (r *AccountRepository) ExportTherapistData(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) (*models.TherapistExport, error) {
+	if q == nil {
+		q = r.db
+	}
+
+	query := caseloadSessionStudentsCTE + `
+	SELECT
+		(SELECT row_to_json(t) FROM therapist t WHERE t.id = $1),
+		(SELECT COALESCE(json_agg(d ORDER BY d.created_at), '[]')
+			FROM therapist_delegate d WHERE d.therapist_id = $1 OR d.delegate_id = $1),
+		(SELECT COALESCE(json_agg(st ORDER BY st.last_name, st.first_name), '[]')
+			FROM student st WHERE st.therapist_id = $1),
+		(SELECT COALESCE(json_agg(sp ORDER BY sp.start_date), '[]')
+			FROM session_parent sp WHERE sp.therapist_id = $1),
+		(SELECT COALESCE(json_agg(s ORDER BY s.start_datetime), '[]')
+			FROM session s JOIN session_parent sp ON s.session_parent_id = sp.id
+			WHERE sp.therapist_id = $1),
+		(SELECT COALESCE(json_agg(ss ORDER BY ss.id), '[]')
+			FROM session_student ss WHERE ss.id IN (SELECT id FROM caseload_ss)),
+		(SELECT COALESCE(json_agg(sr ORDER BY sr.id), '[]')
+			FROM session_rating sr WHERE sr.session_student_id IN (SELECT id FROM caseload_ss)),
+		(SELECT COALESCE(json_agg(gr ORDER BY gr.created_at), '[]')
+			FROM game_result gr WHERE gr.session_student_id IN (SELECT id FROM caseload_ss)),
+		(SELECT COALESCE(json_agg(n ORDER BY n.created_at), '[]')
+			FROM session_note n WHERE n.session_student_id IN (SELECT id FROM caseload_ss)),
+		(SELECT COALESCE(json_agg(sm ORDER BY sm.start_date), '[]')
+			FROM service_mandate sm JOIN student st ON sm.student_id = st.id
+			WHERE st.therapist_id = $1),
+		(SELECT COALESCE(json_agg(ml ORDER BY ml.created_at), '[]')
+			FROM makeup_ledger ml
+			WHERE ml.student_id IN (SELECT id FROM student WHERE therapist_id = $1)
+			   OR ml.session_id IN (
+				SELECT s.id FROM session s JOIN session_parent sp ON s.session_parent_id = sp.id
+				WHERE sp.therapist_id = $1
+			   ))`
+
+	var therapist []byte
+	sections := make([][]byte, 10)
+	err := q.QueryRow(ctx, query, therapistID).Scan(
+		&therapist, &sections[0], &sections[1], &sections[2], &sections[3], &sections[4], &sections[5], &sections[6],
+		&sections[7], &sections[8], &sections[9],
+	)
+	if err != nil {
+		return nil, err
+	}
+	if therapist == nil {
+		return nil, errs.NotFound("Therapist", "id", therapistID.String())
+	}
+
+	export := models.TherapistExport{
+		ExportedAt: time.Now().UTC(),
+		Therapist:  therapist,
+	}
+	targets := []*[]json.RawMessage{
+		&export.Delegates, &export.Students, &export.SessionParents, &export.Sessions,
+		&export.SessionStudents, &export.SessionRatings, &export.GameResults,
+		&export.SessionNotes, &export.ServiceMandates, &export.MakeupLedger,
+	}
+	for i, section := range sections {
+		if err := json.Unmarshal(section, targets[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &export, nil
+}
+
+// TransferCaseload hands the therapist's students and their sessions (with all attendance
+// and ratings) to another therapist and returns how many students moved
+func (r *AccountRepository) TransferCaseload(ctx context.Context, q dbinterface.Queryable, fromID, toID uuid.UUID) (int, error) {
+	tag, err := q.Exec(ctx, `
+	UPDATE student SET therapist_id = $2, updated_at = now() WHERE therapist_id = $1`, fromID, toID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := q.Exec(ctx, `
+	UPDATE session_parent SET therapist_id = $2, updated_at = now() WHERE therapist_id = $1`, fromID, toID); err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// ArchiveStudents snapshots the therapist's students with their attendance, ratings, game
+// results, session notes, mandates and makeup ledger into student_archive, removes the live
+// rows and returns how many were archived. Signed notes cannot be removed, so students who
+// have any must be transferred instead.
+func (r *AccountRepository) ArchiveStudents(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) (int, error) {
+	archiveQuery := `
+	INSERT INTO student_archive (id, former_therapist_id, school_id, first_name, last_name, data)
+	SELECT st.id, st.therapist_id, st.school_id, st.first_name, st.last_name,
+		jsonb_build_object(
+			'student', to_jsonb(st),
+			'session_students', (SELECT COALESCE(jsonb_agg(ss ORDER BY ss.id), '[]')
+				FROM session_student ss WHERE ss.student_id = st.id),
+			'session_ratings', (SELECT COALESCE(jsonb_agg(sr ORDER BY sr.id), '[]')
+				FROM session_rating sr JOIN session_student ss ON sr.session_student_id = ss.id
+				WHERE ss.student_id = st.id),
+			'game_results', (SELECT COALESCE(jsonb_agg(gr ORDER BY gr.created_at), '[]')
+				FROM game_result gr JOIN session_student ss ON gr.session_student_id = ss.id
+				WHERE ss.student_id = st.id),
+			'session_notes', (SELECT COALESCE(jsonb_agg(n ORDER BY n.created_at), '[]')
+				FROM session_note n JOIN session_student ss ON n.session_student_id = ss.id
+				WHERE ss.student_id = st.id),
+			'service_mandates', (SELECT COALESCE(jsonb_agg(sm ORDER BY sm.start_date), '[]')
+				FROM service_mandate sm WHERE sm.student_id = st.id),
+			'makeup_ledger', (SELECT COALESCE(jsonb_agg(ml ORDER BY ml.created_at), '[]')
+				FROM makeup_ledger ml WHERE ml.student_id = st.id)
+		)
+	FROM student st
+	WHERE st.therapist_id = $1`
+
+	if _, err := q.Exec(ctx, archiveQuery, therapistID); err != nil {
+		return 0, err
+	}
+
+	// Ratings do not cascade with their session_student
+	if _, err := q.Exec(ctx, `
+	DELETE FROM session_rating
+	WHERE session_student_id IN (
+		SELECT ss.id FROM session_student ss JOIN student st ON ss.student_id = st.id
+		WHERE st.therapist_id = $1
+	)`, therapistID); err != nil {
+		return 0, err
+	}
+
+	tag, err := q.Exec(ctx, `DELETE FROM student WHERE therapist_id = $1`, therapistID)
+	if err != nil {
+		return 0, signedNoteError(err, "Some students have signed session notes, transfer them instead of archiving")
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// DeleteTherapistAccount removes the therapist with their remaining sessions. Students must
+// have been transferred or archived before.
+func (r *AccountRepository) DeleteTherapistAccount(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) error {
+	if _, err := q.Exec(ctx, `
+	DELETE FROM session_rating
+	WHERE session_student_id IN (
+		SELECT ss.id
+		FROM session_student ss
+		JOIN session s ON ss.session_id = s.id
+		JOIN session_parent sp ON s.session_parent_id = sp.id
+		WHERE sp.therapist_id = $1
+	)`, therapistID); err != nil {
+		return err
+	}
+
+	// Sessions, their attendance, resources and game results cascade
+	if _, err := q.Exec(ctx, `DELETE FROM session_parent WHERE therapist_id = $1`, therapistID); err != nil {
+		return signedNoteError(err, "The account has signed session notes and cannot be deleted")
+	}
+
+	tag, err := q.Exec(ctx, `DELETE FROM therapist WHERE id = $1`, therapistID)
+	if err != nil {
+		return signedNoteError(err, "The account has signed session notes and cannot be deleted")
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.NotFound("Therapist", "id", therapistID.String())
+	}
+
+	return nil
+}
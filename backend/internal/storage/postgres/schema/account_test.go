@@ -0,0 +1,265 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"specialstandard/internal/errs"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedCaseload creates a therapist with one student who attended one rated session and
+// returns the therapist and student IDs
+func seedCaseload(t *testing.T, testDB *pgxpool.Pool) (uuid.UUID, uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := testDB.Exec(ctx, `
+		INSERT INTO district (id, name) VALUES (1, 'Test District') ON CONFLICT (id) DO NOTHING
+	`)
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO school (id, name, district_id) VALUES (1, 'Test School', 1) ON CONFLICT (id) DO NOTHING
+	`)
+	require.NoError(t, err)
+
+	therapistID := uuid.New()
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO therapist (id, first_name, last_name, email) VALUES ($1, 'Leaving', 'Therapist', $2)
+	`, therapistID, therapistID.String()+"@example.com")
+	require.NoError(t, err)
+
+	studentID := uuid.New()
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO student (id, first_name, last_name, therapist_id, school_id)
+		VALUES ($1, 'Emma', 'Johnson', $2, 1)
+	`, studentID, therapistID)
+	require.NoError(t, err)
+
+	parentID := uuid.New()
+	sessionID := uuid.New()
+	start := time.Now().Truncate(time.Hour)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO session_parent (id, start_date, end_date, therapist_id) VALUES ($1, $2, $2, $3)
+	`, parentID, start, therapistID)
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO session (id, start_datetime, end_datetime, session_parent_id) VALUES ($1, $2, $3, $4)
+	`, sessionID, start, start.Add(time.Hour), parentID)
+	require.NoError(t, err)
+
+	var sessionStudentID int
+	err = testDB.QueryRow(ctx, `
+		INSERT INTO session_student (session_id, student_id) VALUES ($1, $2) RETURNING id
+	`, sessionID, studentID).Scan(&sessionStudentID)
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO session_rating (session_student_id, category, level) VALUES ($1, 'engagement', 'high')
+	`, sessionStudentID)
+	require.NoError(t, err)
+
+	return therapistID, studentID
+}
+
+func countRows(t *testing.T, testDB *pgxpool.Pool, query string, args ...any) int {
+	t.Helper()
+	var count int
+	require.NoError(t, testDB.QueryRow(context.Background(), query, args...).Scan(&count))
+	return count
+}
+
+func TestAccountRepository_Export(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewAccountRepository(testDB)
+	ctx := context.Background()
+
+	therapistID, _ := seedCaseload(t, testDB)
+
+	export, err := repo.ExportTherapistData(ctx, nil, therapistID)
+	require.NoError(t, err)
+	assert.Contains(t, string(export.Therapist), therapistID.String())
+	assert.Len(t, export.Students, 1)
+	assert.Len(t, export.SessionParents, 1)
+	assert.Len(t, export.Sessions, 1)
+	assert.Len(t, export.SessionStudents, 1)
+	assert.Len(t, export.SessionRatings, 1)
+	assert.Empty(t, export.GameResults)
+	assert.Empty(t, export.SessionNotes)
+	assert.Empty(t, export.ServiceMandates)
+	assert.Empty(t, export.MakeupLedger)
+	assert.Empty(t, export.Delegates)
+
+	_, err = repo.ExportTherapistData(ctx, nil, uuid.New())
+	assert.Error(t, err)
+}
+
+func TestAccountRepository_ArchiveAndDelete(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewAccountRepository(testDB)
+	ctx := context.Background()
+
+	therapistID, studentID := seedCaseload(t, testDB)
+
+	tx, err := repo.Begin(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	archived, err := repo.ArchiveStudents(ctx, tx, therapistID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	require.NoError(t, repo.DeleteTherapistAccount(ctx, tx, therapistID))
+	require.NoError(t, tx.Commit(ctx))
+
+	assert.Equal(t, 0, countRows(t, testDB, `SELECT count(*) FROM therapist WHERE id = $1`, therapistID))
+	assert.Equal(t, 0, countRows(t, testDB, `SELECT count(*) FROM student WHERE id = $1`, studentID))
+	assert.Equal(t, 0, countRows(t, testDB, `SELECT count(*) FROM session_parent WHERE therapist_id = $1`, therapistID))
+	assert.Equal(t, 0, countRows(t, testDB, `SELECT count(*) FROM session_rating`))
+
+	assert.Equal(t, 1, countRows(t, testDB, `
+		SELECT count(*) FROM student_archive
+		WHERE id = $1 AND former_therapist_id = $2
+			AND jsonb_array_length(data->'session_students') = 1
+			AND jsonb_array_length(data->'session_ratings') = 1
+	`, studentID, therapistID))
+
+	err = repo.DeleteTherapistAccount(ctx, testDB, therapistID)
+	assert.Error(t, err)
+}
+
+func TestAccountRepository_TransferAndDelete(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewAccountRepository(testDB)
+	ctx := context.Background()
+
+	therapistID, studentID := seedCaseload(t, testDB)
+
+	targetID := uuid.New()
+	_, err := testDB.Exec(ctx, `
+		INSERT INTO therapist (id, first_name, last_name, email) VALUES ($1, 'Staying', 'Therapist', $2)
+	`, targetID, targetID.String()+"@example.com")
+	require.NoError(t, err)
+
+	tx, err := repo.Begin(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	transferred, err := repo.TransferCaseload(ctx, tx, therapistID, targetID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, transferred)
+	require.NoError(t, repo.DeleteTherapistAccount(ctx, tx, therapistID))
+	require.NoError(t, tx.Commit(ctx))
+
+	assert.Equal(t, 1, countRows(t, testDB, `SELECT count(*) FROM student WHERE id = $1 AND therapist_id = $2`, studentID, targetID))
+	assert.Equal(t, 1, countRows(t, testDB, `SELECT count(*) FROM session_parent WHERE therapist_id = $1`, targetID))
+	assert.Equal(t, 1, countRows(t, testDB, `SELECT count(*) FROM session_rating`))
+	assert.Equal(t, 0, countRows(t, testDB, `SELECT count(*) FROM student_archive`))
+}
+
+func TestAccountRepository_TransferAndDeleteWithSignedNote(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewAccountRepository(testDB)
+	ctx := context.Background()
+
+	therapistID, studentID := seedCaseload(t, testDB)
+
+	var noteID uuid.UUID
+	err := testDB.QueryRow(ctx, `
+		INSERT INTO session_note (session_student_id, plan, status, signed_at, signed_by)
+		SELECT id, 'Move to phrases', 'signed', now(), $2 FROM session_student WHERE student_id = $1
+		RETURNING id
+	`, studentID, therapistID).Scan(&noteID)
+	require.NoError(t, err)
+
+	targetID := uuid.New()
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO therapist (id, first_name, last_name, email) VALUES ($1, 'Staying', 'Therapist', $2)
+	`, targetID, targetID.String()+"@example.com")
+	require.NoError(t, err)
+
+	tx, err := repo.Begin(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = repo.TransferCaseload(ctx, tx, therapistID, targetID)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteTherapistAccount(ctx, tx, therapistID))
+	require.NoError(t, tx.Commit(ctx))
+
+	// The note outlives the therapist who signed it, otherwise unchanged
+	assert.Equal(t, 1, countRows(t, testDB, `
+		SELECT count(*) FROM session_note
+		WHERE id = $1 AND status = 'signed' AND signed_at IS NOT NULL AND signed_by IS NULL AND plan = 'Move to phrases'
+	`, noteID))
+
+	_, err = testDB.Exec(ctx, `UPDATE session_note SET plan = 'Changed' WHERE id = $1`, noteID)
+	assert.Error(t, err)
+}
+
+func TestAccountRepository_ArchiveWithSignedNote(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewAccountRepository(testDB)
+	ctx := context.Background()
+
+	therapistID, studentID := seedCaseload(t, testDB)
+
+	_, err := testDB.Exec(ctx, `
+		INSERT INTO session_note (session_student_id, plan, status, signed_at, signed_by)
+		SELECT id, 'Move to phrases', 'signed', now(), $2 FROM session_student WHERE student_id = $1
+	`, studentID, therapistID)
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO service_mandate (student_id, service, minutes, frequency, setting, start_date, end_date)
+		VALUES ($1, 'Speech', 60, 'weekly', 'individual', '2025-09-01', '2026-06-30')
+	`, studentID)
+	require.NoError(t, err)
+
+	export, err := repo.ExportTherapistData(ctx, nil, therapistID)
+	require.NoError(t, err)
+	assert.Len(t, export.SessionNotes, 1)
+	assert.Len(t, export.ServiceMandates, 1)
+
+	tx, err := repo.Begin(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = repo.ArchiveStudents(ctx, tx, therapistID)
+	var httpErr errs.HTTPError
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, 409, httpErr.Code)
+	}
+}
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const apiKeyColumns = `id, name, prefix, scopes, district_id, created_by, created_at, last_used_at, expires_at, revoked_at`
+
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, createdBy uuid.UUID, prefix, keyHash string, input *models.CreateAPIKeyInput) (*models.APIKey, error) {
+	query := `
+	INSERT INTO api_key (name, prefix, key_hash, scopes, district_id, created_by, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING ` + apiKeyColumns
+
+	rows, err := r.db.Query(ctx, query, input.Name, prefix, keyHash, input.Scopes, input.DistrictID, createdBy, input.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.APIKey])
+}
+
+// GetAPIKeys lists the keys a therapist created, revoked ones included
+func (r *APIKeyRepository) GetAPIKeys(ctx context.Context, createdBy uuid.UUID) ([]models.APIKey, error) {
+	query := `
+	SELECT ` + apiKeyColumns + `
+	FROM api_key
+	WHERE created_by = $1
+	ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.APIKey])
+}
+
+func (r *APIKeyRepository) GetAPIKeyByID(ctx context.Context, id, createdBy uuid.UUID) (*models.APIKey, error) {
+	query := `
+	SELECT ` + apiKeyColumns + `
+	FROM api_key
+	WHERE id = $1 AND created_by = $2`
+
+	rows, err := r.db.Query(ctx, query, id, createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.APIKey])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("API key", "id", id.String())
+	}
+	return key, err
+}
+
+// UpdateAPIKey renames or rescopes a key that has not been revoked
+func (r *APIKeyRepository) UpdateAPIKey(ctx context.Context, id, createdBy uuid.UUID, input *models.UpdateAPIKeyInput) (*models.APIKey, error) {
+	query := `
+	UPDATE api_key
+	SET name = COALESCE($3, name),
+		scopes = COALESCE($4, scopes)
+	WHERE id = $1 AND created_by = $2 AND revoked_at IS NULL
+	RETURNING ` + apiKeyColumns
+
+	rows, err := r.db.Query(ctx, query, id, createdBy, input.Name, input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.APIKey])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("API key", "id", id.String())
+	}
+	return key, err
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id, createdBy uuid.UUID) error {
+	query := `
+	UPDATE api_key
+	SET revoked_at = COALESCE(revoked_at, now())
+	WHERE id = $1 AND created_by = $2`
+
+	tag, err := r.db.Exec(ctx, query, id, createdBy)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.NotFound("API key", "id", id.String())
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey finds the live key with the given hash and records that it was used.
+// Unknown, revoked and expired keys are pgx.ErrNoRows.
+func (r *APIKeyRepository) AuthenticateAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+	UPDATE api_key
+	SET last_used_at = now()
+	WHERE key_hash = $1
+		AND revoked_at IS NULL
+		AND (expires_at IS NULL OR expires_at > now())
+	RETURNING ` + apiKeyColumns
+
+	rows, err := r.db.Query(ctx, query, keyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.APIKey])
+}
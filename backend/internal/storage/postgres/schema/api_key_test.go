@@ -0,0 +1,81 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewAPIKeyRepository(testDB)
+	ctx := context.Background()
+
+	owner := uuid.New()
+	_, err := testDB.Exec(ctx, `
+		INSERT INTO therapist (id, first_name, last_name, email) VALUES ($1, 'Key', 'Owner', $2)
+	`, owner, owner.String()+"@example.com")
+	require.NoError(t, err)
+
+	key, err := repo.CreateAPIKey(ctx, owner, "ssk_abcdefgh", "hash-1", &models.CreateAPIKeyInput{
+		Name:   "SIS sync",
+		Scopes: []string{models.ScopeStudentsRead},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SIS sync", key.Name)
+	assert.Equal(t, owner, key.CreatedBy)
+	assert.Nil(t, key.LastUsedAt)
+
+	authenticated, err := repo.AuthenticateAPIKey(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+	assert.NotNil(t, authenticated.LastUsedAt)
+
+	_, err = repo.AuthenticateAPIKey(ctx, "hash-2")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	scopes := []string{models.ScopeSessionsRead, models.ScopeSessionsWrite}
+	updated, err := repo.UpdateAPIKey(ctx, key.ID, owner, &models.UpdateAPIKeyInput{Scopes: &scopes})
+	require.NoError(t, err)
+	assert.Equal(t, scopes, updated.Scopes)
+	assert.Equal(t, "SIS sync", updated.Name)
+
+	// Keys are only visible to the therapist who created them
+	_, err = repo.GetAPIKeyByID(ctx, key.ID, uuid.New())
+	assert.Error(t, err)
+	assert.Error(t, repo.RevokeAPIKey(ctx, key.ID, uuid.New()))
+
+	require.NoError(t, repo.RevokeAPIKey(ctx, key.ID, owner))
+	_, err = repo.AuthenticateAPIKey(ctx, "hash-1")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = repo.UpdateAPIKey(ctx, key.ID, owner, &models.UpdateAPIKeyInput{Scopes: &scopes})
+	assert.Error(t, err)
+
+	expired := time.Now().Add(-time.Minute)
+	_, err = repo.CreateAPIKey(ctx, owner, "ssk_ijklmnop", "hash-3", &models.CreateAPIKeyInput{
+		Name:      "Expired",
+		Scopes:    []string{models.ScopeStudentsRead},
+		ExpiresAt: &expired,
+	})
+	require.NoError(t, err)
+	_, err = repo.AuthenticateAPIKey(ctx, "hash-3")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	keys, err := repo.GetAPIKeys(ctx, owner)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
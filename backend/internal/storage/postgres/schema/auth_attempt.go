@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AttemptRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttemptRepository(db *pgxpool.Pool) *AttemptRepository {
+	return &AttemptRepository{db: db}
+}
+
+// GetLockedUntil returns when the lockout of the key ends, nil when it is not locked
+func (r *AttemptRepository) GetLockedUntil(ctx context.Context, key string) (*time.Time, error) {
+	query := `
+	SELECT MAX(locked_until)
+	FROM auth_attempt
+	WHERE key = $1 AND locked_until > now()`
+
+	var lockedUntil *time.Time
+	if err := r.db.QueryRow(ctx, query, key).Scan(&lockedUntil); err != nil {
+		return nil, err
+	}
+
+	return lockedUntil, nil
+}
+
+// RecordFailure counts a failure for the key and returns the number of consecutive
+// failures. The count starts over when the previous failure is older than window.
+func (r *AttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	query := `
+	INSERT INTO auth_attempt AS a (key, failures, last_failure_at)
+	VALUES ($1, 1, now())
+	ON CONFLICT (key) DO UPDATE SET
+		failures = CASE
+			WHEN a.last_failure_at < now() - make_interval(secs => $2) THEN 1
+			ELSE a.failures + 1
+		END,
+		last_failure_at = now()
+	RETURNING failures`
+
+	var failures int
+	if err := r.db.QueryRow(ctx, query, key, window.Seconds()).Scan(&failures); err != nil {
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+// LockUntil locks the key, never shortening a lockout that is already longer
+func (r *AttemptRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	query := `
+	UPDATE auth_attempt
+	SET locked_until = GREATEST(COALESCE(locked_until, $2), $2)
+	WHERE key = $1`
+
+	_, err := r.db.Exec(ctx, query, key, until)
+	return err
+}
+
+// ResetAttempts forgets the failures of the key, e.g. after a successful login
+func (r *AttemptRepository) ResetAttempts(ctx context.Context, key string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM auth_attempt WHERE key = $1`, key)
+	return err
+}
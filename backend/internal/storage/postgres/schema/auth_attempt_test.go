@@ -0,0 +1,63 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttemptRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewAttemptRepository(testDB)
+	ctx := context.Background()
+
+	key := "login:email:someone@example.com"
+
+	lockedUntil, err := repo.GetLockedUntil(ctx, key)
+	require.NoError(t, err)
+	assert.Nil(t, lockedUntil)
+
+	for want := 1; want <= 3; want++ {
+		failures, err := repo.RecordFailure(ctx, key, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, want, failures)
+	}
+
+	until := time.Now().Add(time.Minute).Truncate(time.Microsecond)
+	require.NoError(t, repo.LockUntil(ctx, key, until))
+
+	// A shorter lockout never replaces a longer one
+	require.NoError(t, repo.LockUntil(ctx, key, time.Now().Add(time.Second)))
+
+	lockedUntil, err = repo.GetLockedUntil(ctx, key)
+	require.NoError(t, err)
+	require.NotNil(t, lockedUntil)
+	assert.WithinDuration(t, until, *lockedUntil, time.Millisecond)
+
+	// Other keys are unaffected
+	lockedUntil, err = repo.GetLockedUntil(ctx, "login:ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Nil(t, lockedUntil)
+
+	// Failures outside the window start the count over
+	_, err = testDB.Exec(ctx, `UPDATE auth_attempt SET last_failure_at = now() - interval '2 hours' WHERE key = $1`, key)
+	require.NoError(t, err)
+	failures, err := repo.RecordFailure(ctx, key, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	require.NoError(t, repo.ResetAttempts(ctx, key))
+	lockedUntil, err = repo.GetLockedUntil(ctx, key)
+	require.NoError(t, err)
+	assert.Nil(t, lockedUntil)
+}
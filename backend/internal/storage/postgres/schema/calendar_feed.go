@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const calendarFeedColumns = `therapist_id, include_student_names, created_at, updated_at`
+
+type CalendarFeedRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCalendarFeedRepository(db *pgxpool.Pool) *CalendarFeedRepository {
+	return &CalendarFeedRepository{db: db}
+}
+
+// CreateCalendarFeed gives the therapist a feed, or a new token for the feed they have so
+// the old URL stops working
+func (r *CalendarFeedRepository) CreateCalendarFeed(ctx context.Context, therapistID uuid.UUID, tokenHash string, input *models.CalendarFeedInput) (*models.CalendarFeed, error) {
+	query := `
+	INSERT INTO calendar_feed (therapist_id, token_hash, include_student_names)
+	VALUES ($1, $2, COALESCE($3, FALSE))
+	ON CONFLICT (therapist_id) DO UPDATE
+	SET token_hash = EXCLUDED.token_hash,
+		include_student_names = COALESCE($3, calendar_feed.include_student_names),
+		updated_at = now()
+	RETURNING ` + calendarFeedColumns
+
+	rows, err := r.db.Query(ctx, query, therapistID, tokenHash, input.IncludeStudentNames)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.CalendarFeed])
+}
+
+func (r *CalendarFeedRepository) GetCalendarFeed(ctx context.Context, therapistID uuid.UUID) (*models.CalendarFeed, error) {
+	query := `SELECT ` + calendarFeedColumns + ` FROM calendar_feed WHERE therapist_id = $1`
+
+	rows, err := r.db.Query(ctx, query, therapistID)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.CalendarFeed])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Calendar feed not found")
+	}
+	return feed, err
+}
+
+// GetCalendarFeedByToken resolves the hashed token from a feed URL, pgx.ErrNoRows means
+// the URL is not, or no longer, valid
+func (r *CalendarFeedRepository) GetCalendarFeedByToken(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	query := `SELECT ` + calendarFeedColumns + ` FROM calendar_feed WHERE token_hash = $1`
+
+	rows, err := r.db.Query(ctx, query, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.CalendarFeed])
+}
+
+func (r *CalendarFeedRepository) UpdateCalendarFeed(ctx context.Context, therapistID uuid.UUID, input *models.CalendarFeedInput) (*models.CalendarFeed, error) {
+	query := `
+	UPDATE calendar_feed
+	SET include_student_names = COALESCE($2, include_student_names),
+		updated_at = now()
+	WHERE therapist_id = $1
+	RETURNING ` + calendarFeedColumns
+
+	rows, err := r.db.Query(ctx, query, therapistID, input.IncludeStudentNames)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.CalendarFeed])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Calendar feed not found")
+	}
+	return feed, err
+}
+
+func (r *CalendarFeedRepository) DeleteCalendarFeed(ctx context.Context, therapistID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM calendar_feed WHERE therapist_id = $1`, therapistID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.NotFound("Calendar feed not found")
+	}
+	return nil
+}
+
+// GetCalendarLocation is the time zone the feed expands the therapist's series in
+func (r *CalendarFeedRepository) GetCalendarLocation(ctx context.Context, therapistID uuid.UUID) (*time.Location, error) {
+	return therapistLocation(ctx, r.db, therapistID)
+}
+
+// GetCalendarSessions lists every session of the therapist that was not cancelled, ordered
+// by series and start, with the repetition of its series and the first names of its
+// students
+func (r *CalendarFeedRepository) GetCalendarSessions(ctx context.Context, therapistID uuid.UUID) ([]models.CalendarSession, error) {
+	query := `
+	SELECT s.id, s.session_name, s.start_datetime, s.end_datetime,
+	       s.notes, s.location, s.status, s.status_reason, s.makeup_for_session_id, s.session_template_id, s.created_at, s.updated_at,
+	       s.session_parent_id, sp.therapist_id,
+	       ` + seriesColumns + `,
+	       COALESCE(
+	           ARRAY_AGG(st.first_name ORDER BY st.first_name) FILTER (WHERE st.id IS NOT NULL),
+	           '{}'
+	       )
+	FROM session s
+	INNER JOIN session_parent sp ON s.session_parent_id = sp.id
+	LEFT JOIN session_student ss ON ss.session_id = s.id
+	LEFT JOIN student st ON st.id = ss.student_id AND st.grade != -1
+	WHERE sp.therapist_id = $1
+	  AND s.status NOT IN ` + cancelledStatuses + `
+	GROUP BY s.id, sp.id
+	ORDER BY s.session_parent_id, s.start_datetime`
+
+	rows, err := r.db.Query(ctx, query, therapistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.CalendarSession
+	for rows.Next() {
+		var s models.CalendarSession
+		var sr series
+
+		dest := append([]any{
+			&s.ID,
+			&s.SessionName,
+			&s.StartDateTime,
+			&s.EndDateTime,
+			&s.Notes,
+			&s.Location,
+			&s.Status,
+			&s.StatusReason,
+			&s.MakeupForSessionID,
+			&s.SessionTemplateID,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+			&s.SessionParentID,
+			&s.TherapistID,
+		}, sr.dest()...)
+		if err := rows.Scan(append(dest, &s.StudentFirstNames)...); err != nil {
+			return nil, err
+		}
+
+		s.Repetition = sr.repetition()
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
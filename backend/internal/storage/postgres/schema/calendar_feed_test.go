@@ -0,0 +1,109 @@
+package schema_test
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarFeedRepository_Feed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewCalendarFeedRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Feed")
+
+	_, err := repo.GetCalendarFeed(ctx, therapistID)
+	assert.Error(t, err)
+
+	feed, err := repo.CreateCalendarFeed(ctx, therapistID, "hash-1", &models.CalendarFeedInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, therapistID, feed.TherapistID)
+	assert.False(t, feed.IncludeStudentNames)
+
+	byToken, err := repo.GetCalendarFeedByToken(ctx, "hash-1")
+	assert.NoError(t, err)
+	assert.Equal(t, therapistID, byToken.TherapistID)
+
+	// Rotating keeps the settings but retires the old token
+	on := true
+	_, err = repo.UpdateCalendarFeed(ctx, therapistID, &models.CalendarFeedInput{IncludeStudentNames: &on})
+	assert.NoError(t, err)
+	feed, err = repo.CreateCalendarFeed(ctx, therapistID, "hash-2", &models.CalendarFeedInput{})
+	assert.NoError(t, err)
+	assert.True(t, feed.IncludeStudentNames)
+
+	_, err = repo.GetCalendarFeedByToken(ctx, "hash-1")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = repo.GetCalendarFeedByToken(ctx, "hash-2")
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.DeleteCalendarFeed(ctx, therapistID))
+	assert.Error(t, repo.DeleteCalendarFeed(ctx, therapistID))
+	_, err = repo.GetCalendarFeedByToken(ctx, "hash-2")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestCalendarFeedRepository_GetCalendarSessions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewCalendarFeedRepository(testDB)
+	sessions := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Feed")
+	otherID := CreateSessionTestTherapist(t, testDB, ctx, "Other")
+	grace := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Grace", 2)
+	ada := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Ada", 2)
+
+	start := time.Date(2025, 9, 2, 14, 0, 0, 0, time.UTC)
+	series, err := sessions.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Articulation group",
+		StartTime:   start,
+		EndTime:     start.Add(45 * time.Minute),
+		TherapistID: therapistID,
+		Repetition: &models.Repetition{
+			RecurStart:  start,
+			RecurEnd:    start.AddDate(0, 0, 14),
+			EveryNWeeks: 1,
+			Days:        []int{2},
+		},
+	})
+	assert.NoError(t, err)
+	for _, studentID := range []uuid.UUID{grace, ada} {
+		_, err := testDB.Exec(ctx, `INSERT INTO session_student (session_id, student_id) VALUES ($1, $2)`, (*series)[0].ID, studentID)
+		assert.NoError(t, err)
+	}
+
+	_, err = sessions.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Not mine",
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		TherapistID: otherID,
+	})
+	assert.NoError(t, err)
+
+	got, err := repo.GetCalendarSessions(ctx, therapistID)
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+	assert.Equal(t, []string{"Ada", "Grace"}, got[0].StudentFirstNames)
+	assert.Empty(t, got[1].StudentFirstNames)
+	for _, s := range got {
+		assert.Equal(t, "Articulation group", s.SessionName)
+		assert.NotNil(t, s.Repetition)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"specialstandard/internal/models"
+	"specialstandard/internal/utils"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -56,4 +58,144 @@ func (r *DistrictRepository) GetDistrictByID(ctx context.Context, id int) (*mode
 	}
 	
 	return &district, nil
-}
\ No newline at end of file
+}
+// GetDistrictTherapists lists the therapists working in the district
+func (r *DistrictRepository) GetDistrictTherapists(ctx context.Context, districtID int, pagination utils.Pagination) ([]models.Therapist, error) {
+	query := `
+	SELECT t.id, t.first_name, t.last_name, t.email, t.active, t.schools, t.district_id, t.role, t.time_zone, t.created_at, t.updated_at
+	FROM therapist t
+	WHERE t.id IN (` + districtTherapistsQuery + `)
+	ORDER BY t.first_name ASC, t.last_name ASC
+	LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, districtID, pagination.Limit, pagination.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.Therapist])
+}
+
+// GetDistrictStudents lists the (non graduated) students enrolled at the district's schools
+func (r *DistrictRepository) GetDistrictStudents(ctx context.Context, districtID int, schoolID *int, pagination utils.Pagination) ([]models.Student, error) {
+	query := `
+	SELECT s.id, s.first_name, s.last_name, s.dob, s.therapist_id, s.school_id, sch.name AS school_name, sch.district_id, s.grade, s.iep, s.created_at, s.updated_at
+	FROM student s
+	JOIN school sch ON s.school_id = sch.id
+	WHERE sch.district_id = $1 AND s.grade != -1`
+
+	args := []interface{}{districtID}
+	argCount := 2
+
+	if schoolID != nil {
+		query += fmt.Sprintf(" AND s.school_id = $%d", argCount)
+		args = append(args, *schoolID)
+		argCount++
+	}
+
+	query += fmt.Sprintf(" ORDER BY sch.name ASC, s.first_name ASC, s.last_name ASC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, pagination.Limit, pagination.GetOffset())
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.Student])
+}
+
+// GetDistrictSessions lists the sessions run by therapists working in the district
+func (r *DistrictRepository) GetDistrictSessions(ctx context.Context, districtID int, filter *models.GetDistrictSessionsQuery, pagination utils.Pagination) ([]models.Session, error) {
+	query := `
+	SELECT s.id, s.session_name, s.start_datetime, s.end_datetime, s.notes, s.location,
+	       s.status, s.status_reason, s.makeup_for_session_id, s.session_template_id, s.created_at, s.updated_at, s.session_parent_id, sp.therapist_id
+	FROM session s
+	JOIN session_parent sp ON s.session_parent_id = sp.id
+	WHERE sp.therapist_id IN (` + districtTherapistsQuery + `)`
+
+	args := []interface{}{districtID}
+	argCount := 2
+
+	if filter != nil {
+		if filter.TherapistID != nil {
+			query += fmt.Sprintf(" AND sp.therapist_id = $%d", argCount)
+			args = append(args, *filter.TherapistID)
+			argCount++
+		}
+
+		if filter.StartTime != nil {
+			query += fmt.Sprintf(" AND s.start_datetime >= $%d", argCount)
+			args = append(args, *filter.StartTime)
+			argCount++
+		}
+
+		if filter.EndTime != nil {
+			query += fmt.Sprintf(" AND s.end_datetime <= $%d", argCount)
+			args = append(args, *filter.EndTime)
+			argCount++
+		}
+	}
+
+	query += fmt.Sprintf(" ORDER BY s.start_datetime ASC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, pagination.Limit, pagination.GetOffset())
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(
+			&s.ID,
+			&s.SessionName,
+			&s.StartDateTime,
+			&s.EndDateTime,
+			&s.Notes,
+			&s.Location,
+			&s.Status,
+			&s.StatusReason,
+			&s.MakeupForSessionID,
+			&s.SessionTemplateID,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+			&s.SessionParentID,
+			&s.TherapistID,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// GetDistrictAttendance reports, per student at the district's schools, how many of
+// their sessions ending in the given window they attended
+func (r *DistrictRepository) GetDistrictAttendance(ctx context.Context, districtID int, dateFrom, dateTo time.Time) ([]models.StudentAttendance, error) {
+	query := `
+	SELECT st.id AS student_id, st.first_name, st.last_name, st.school_id, sch.name AS school_name, st.therapist_id,
+	       COUNT(ss.id) FILTER (WHERE ss.present = true) AS present_count,
+	       COUNT(ss.id) AS total_count
+	FROM student st
+	JOIN school sch ON st.school_id = sch.id
+	LEFT JOIN (
+		session_student ss
+		JOIN session s ON ss.session_id = s.id AND s.end_datetime BETWEEN $2 AND $3
+	) ON ss.student_id = st.id
+	WHERE sch.district_id = $1 AND st.grade != -1
+	GROUP BY st.id, sch.name
+	ORDER BY sch.name ASC, st.first_name ASC, st.last_name ASC`
+
+	rows, err := r.db.Query(ctx, query, districtID, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.StudentAttendance])
+}
@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Bodies are cleared once an email is done with, and read back as empty
+const emailOutboxColumns = `
+	id, recipients, subject, COALESCE(html_body, '') AS html_body, COALESCE(text_body, '') AS text_body,
+	template, status, attempts, max_attempts, last_error, next_attempt_at, provider_message_id, created_at, sent_at`
+
+type EmailOutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEmailOutboxRepository(db *pgxpool.Pool) *EmailOutboxRepository {
+	return &EmailOutboxRepository{db: db}
+}
+
+func (r *EmailOutboxRepository) EnqueueEmail(ctx context.Context, input *models.CreateOutboxEmailInput) (*models.OutboxEmail, error) {
+	query := `
+	INSERT INTO email_outbox (recipients, subject, html_body, text_body, template, max_attempts, next_attempt_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING` + emailOutboxColumns
+
+	rows, err := r.db.Query(ctx, query,
+		input.Recipients, input.Subject, input.HTMLBody, input.TextBody, input.Template,
+		input.MaxAttempts, input.NextAttemptAt)
+	if err != nil {
+		return nil, err
+	}
+
+	email, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.OutboxEmail])
+	if err != nil {
+		return nil, err
+	}
+
+	return &email, nil
+}
+
+// ClaimDueEmails picks pending emails whose next attempt is due and pushes their next
+// attempt back by lease, so concurrent workers (and a crashed one) never send twice in a row
+func (r *EmailOutboxRepository) ClaimDueEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error) {
+	query := `
+	UPDATE email_outbox
+	SET next_attempt_at = now() + make_interval(secs => $2)
+	WHERE id IN (
+		SELECT id FROM email_outbox
+		WHERE status = 'pending' AND next_attempt_at <= now()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING` + emailOutboxColumns
+
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.OutboxEmail])
+}
+
+// MarkEmailSent records the delivery and clears the body, which may hold codes or links
+func (r *EmailOutboxRepository) MarkEmailSent(ctx context.Context, id uuid.UUID, providerMessageID string) error {
+	query := `
+	UPDATE email_outbox
+	SET status = 'sent', attempts = attempts + 1, provider_message_id = $2, sent_at = now(), last_error = NULL,
+		html_body = NULL, text_body = NULL
+	WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id, providerMessageID)
+	return err
+}
+
+// MarkEmailFailed records a failed delivery. The email is retried at retryAt, or given up
+// on when retryAt is nil and its body cleared.
+func (r *EmailOutboxRepository) MarkEmailFailed(ctx context.Context, id uuid.UUID, lastError string, retryAt *time.Time) error {
+	query := `
+	UPDATE email_outbox
+	SET attempts = attempts + 1,
+		last_error = $2,
+		status = CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+		next_attempt_at = COALESCE($3, next_attempt_at),
+		html_body = CASE WHEN $3::timestamptz IS NULL THEN NULL ELSE html_body END,
+		text_body = CASE WHEN $3::timestamptz IS NULL THEN NULL ELSE text_body END
+	WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id, lastError, retryAt)
+	return err
+}
+
+// DeleteFinishedEmails removes the emails sent or given up on that were queued before the
+// time, and returns how many there were
+func (r *EmailOutboxRepository) DeleteFinishedEmails(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+	DELETE FROM email_outbox
+	WHERE status IN ('sent', 'failed') AND created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,99 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailOutboxRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewEmailOutboxRepository(testDB)
+	ctx := context.Background()
+
+	enqueue := func(nextAttemptAt time.Time) *models.OutboxEmail {
+		email, err := repo.EnqueueEmail(ctx, &models.CreateOutboxEmailInput{
+			Recipients:    []string{"therapist@example.com"},
+			Subject:       "Subject",
+			HTMLBody:      "<p>Body</p>",
+			TextBody:      "Body",
+			Template:      "verification_code",
+			MaxAttempts:   3,
+			NextAttemptAt: nextAttemptAt,
+		})
+		require.NoError(t, err)
+		return email
+	}
+
+	due := enqueue(time.Now().Add(-time.Minute))
+	later := enqueue(time.Now().Add(time.Hour))
+
+	assert.Equal(t, models.EmailPending, due.Status)
+	assert.Equal(t, []string{"therapist@example.com"}, due.Recipients)
+	assert.Equal(t, 0, due.Attempts)
+
+	claimed, err := repo.ClaimDueEmails(ctx, 10, 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.ID, claimed[0].ID)
+	assert.True(t, claimed[0].NextAttemptAt.After(time.Now().Add(4*time.Minute)))
+
+	// Claimed emails are not handed out twice
+	claimed, err = repo.ClaimDueEmails(ctx, 10, 5*time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// A failure with a retry time makes the email due again at that time
+	require.NoError(t, repo.MarkEmailFailed(ctx, due.ID, "connection refused", &time.Time{}))
+	claimed, err = repo.ClaimDueEmails(ctx, 10, 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	require.NotNil(t, claimed[0].LastError)
+	assert.Equal(t, "connection refused", *claimed[0].LastError)
+
+	require.NoError(t, repo.MarkEmailSent(ctx, due.ID, "provider-id"))
+
+	// Without a retry time the email is given up on
+	require.NoError(t, repo.MarkEmailFailed(ctx, later.ID, "mailbox unavailable", nil))
+
+	var dueStatus, laterStatus string
+	var providerID *string
+	require.NoError(t, testDB.QueryRow(ctx, `SELECT status, provider_message_id FROM email_outbox WHERE id = $1`, due.ID).Scan(&dueStatus, &providerID))
+	require.NoError(t, testDB.QueryRow(ctx, `SELECT status FROM email_outbox WHERE id = $1`, later.ID).Scan(&laterStatus))
+	assert.Equal(t, models.EmailSent, dueStatus)
+	require.NotNil(t, providerID)
+	assert.Equal(t, "provider-id", *providerID)
+	assert.Equal(t, models.EmailFailed, laterStatus)
+
+	// Bodies may hold codes or links, they are gone once an email is done with
+	var htmlBody, textBody *string
+	for _, id := range []any{due.ID, later.ID} {
+		require.NoError(t, testDB.QueryRow(ctx, `SELECT html_body, text_body FROM email_outbox WHERE id = $1`, id).Scan(&htmlBody, &textBody))
+		assert.Nil(t, htmlBody)
+		assert.Nil(t, textBody)
+	}
+
+	pending := enqueue(time.Now().Add(time.Hour))
+	_, err = testDB.Exec(ctx, `UPDATE email_outbox SET created_at = now() - interval '40 days'`)
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteFinishedEmails(ctx, time.Now().Add(-30*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	var remaining string
+	require.NoError(t, testDB.QueryRow(ctx, `SELECT html_body FROM email_outbox WHERE id = $1`, pending.ID).Scan(&remaining))
+	assert.Equal(t, "<p>Body</p>", remaining)
+}
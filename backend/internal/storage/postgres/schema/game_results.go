@@ -25,13 +25,20 @@ func (r *GameResultRepository) GetGameResults(ctx context.Context, inputQuery *m
 	query := `SELECT gr.id, gr.session_student_id, gr.content_id, gr.time_taken_sec, gr.completed,
        					gr.count_of_incorrect_attempts, gr.incorrect_attempts, gr.created_at, gr.updated_at
 			  FROM game_result gr JOIN session_student ss ON gr.session_student_id = ss.id
-				JOIN game_content gc on gr.content_id = gc.id`
+				JOIN game_content gc on gr.content_id = gc.id
+				JOIN student st ON ss.student_id = st.id`
 
 	var conditions []string
 	var args []interface{}
 	argCount := 1
 
 	if inputQuery != nil {
+		if inputQuery.TherapistID != nil {
+			conditions = append(conditions, fmt.Sprintf("st.therapist_id = $%d", argCount))
+			args = append(args, inputQuery.TherapistID)
+			argCount++
+		}
+
 		if inputQuery.SessionID != nil {
 			conditions = append(conditions, fmt.Sprintf("ss.session_id = $%d", argCount))
 			args = append(args, inputQuery.SessionID)
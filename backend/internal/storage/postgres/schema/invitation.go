@@ -0,0 +1,195 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/dbinterface"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const invitationColumns = `id, email, first_name, last_name, district_id, schools, invited_by, expires_at, accepted_at, accepted_by, revoked_at, created_at`
+
+// An invitation that can still be accepted
+const pendingInvitation = `accepted_at IS NULL AND revoked_at IS NULL AND expires_at > now()`
+
+type InvitationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInvitationRepository(db *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// CreateInvitation records an invitation into the district. The schools must belong to the
+// district, and the email must not have an account or another pending invitation.
+func (r *InvitationRepository) CreateInvitation(ctx context.Context, districtID int, invitedBy uuid.UUID, input *models.CreateInvitationInput, expiresAt time.Time) (*models.Invitation, error) {
+	schools := slices.Compact(slices.Sorted(slices.Values(input.Schools)))
+
+	var inDistrict int
+	if err := r.db.QueryRow(ctx, `
+	SELECT count(*) FROM school WHERE id = ANY($1) AND district_id = $2`, schools, districtID).Scan(&inDistrict); err != nil {
+		return nil, err
+	}
+	if inDistrict != len(schools) {
+		return nil, errs.BadRequest("Every school must belong to the district")
+	}
+
+	var registered, invited bool
+	if err := r.db.QueryRow(ctx, `
+	SELECT
+		EXISTS (SELECT 1 FROM therapist WHERE lower(email) = lower($1)),
+		EXISTS (SELECT 1 FROM therapist_invitation WHERE lower(email) = lower($1) AND `+pendingInvitation+`)`,
+		input.Email).Scan(&registered, &invited); err != nil {
+		return nil, err
+	}
+	if registered {
+		return nil, errs.Conflict("A therapist with this email already exists")
+	}
+	if invited {
+		return nil, errs.Conflict("This email already has a pending invitation")
+	}
+
+	query := `
+	INSERT INTO therapist_invitation (email, first_name, last_name, district_id, schools, invited_by, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING ` + invitationColumns
+
+	rows, err := r.db.Query(ctx, query, input.Email, input.FirstName, input.LastName, districtID, schools, invitedBy, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.Invitation])
+}
+
+// GetPendingInvitations lists the invitations into the district that can still be accepted
+func (r *InvitationRepository) GetPendingInvitations(ctx context.Context, districtID int) ([]models.Invitation, error) {
+	query := `
+	SELECT ` + invitationColumns + `
+	FROM therapist_invitation
+	WHERE district_id = $1 AND ` + pendingInvitation + `
+	ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, districtID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.Invitation])
+}
+
+// GetInvitationPreview describes a pending invitation for the accept page
+func (r *InvitationRepository) GetInvitationPreview(ctx context.Context, id uuid.UUID) (*models.InvitationPreview, error) {
+	query := `
+	SELECT i.email, i.first_name, i.last_name, i.district_id, d.name, i.expires_at,
+		COALESCE(ARRAY(SELECT s.name FROM school s WHERE s.id = ANY(i.schools) ORDER BY s.name), '{}')
+	FROM therapist_invitation i
+	JOIN district d ON d.id = i.district_id
+	WHERE i.id = $1 AND ` + pendingInvitation
+
+	var preview models.InvitationPreview
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&preview.Email,
+		&preview.FirstName,
+		&preview.LastName,
+		&preview.DistrictID,
+		&preview.DistrictName,
+		&preview.ExpiresAt,
+		&preview.SchoolNames,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Invitation", "id", id.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &preview, nil
+}
+
+// RevokeInvitation withdraws a pending invitation into the district
+func (r *InvitationRepository) RevokeInvitation(ctx context.Context, districtID int, id uuid.UUID) error {
+	query := `
+	UPDATE therapist_invitation
+	SET revoked_at = now()
+	WHERE id = $1 AND district_id = $2 AND ` + pendingInvitation
+
+	tag, err := r.db.Exec(ctx, query, id, districtID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.NotFound("Invitation", "id", id.String())
+	}
+
+	return nil
+}
+
+// Begin starts the transaction an invitation is accepted in
+func (r *InvitationRepository) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.db.Begin(ctx)
+}
+
+// ClaimInvitation locks the invitation until the transaction ends, so it cannot be accepted
+// twice or revoked while it is being accepted. It is returned whatever its status.
+func (r *InvitationRepository) ClaimInvitation(ctx context.Context, q dbinterface.Queryable, id uuid.UUID) (*models.Invitation, error) {
+	query := `
+	SELECT ` + invitationColumns + `
+	FROM therapist_invitation
+	WHERE id = $1
+	FOR UPDATE`
+
+	rows, err := q.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.Invitation])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Invitation", "id", id.String())
+	}
+	return invitation, err
+}
+
+// AcceptInvitation creates the therapist the invitation was for and marks it accepted
+func (r *InvitationRepository) AcceptInvitation(ctx context.Context, q dbinterface.Queryable, id uuid.UUID, input *models.CreateTherapistInput) (*models.Therapist, error) {
+	therapist := &models.Therapist{}
+
+	err := q.QueryRow(ctx, `
+	INSERT INTO therapist (id, first_name, last_name, schools, district_id, email)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, first_name, last_name, schools, district_id, role, email, active, time_zone, created_at, updated_at`,
+		input.ID, input.FirstName, input.LastName, input.Schools, input.DistrictID, input.Email,
+	).Scan(
+		&therapist.ID,
+		&therapist.FirstName,
+		&therapist.LastName,
+		&therapist.Schools,
+		&therapist.DistrictID,
+		&therapist.Role,
+		&therapist.Email,
+		&therapist.Active,
+		&therapist.TimeZone,
+		&therapist.CreatedAt,
+		&therapist.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := q.Exec(ctx, `
+	UPDATE therapist_invitation
+	SET accepted_at = now(), accepted_by = $2
+	WHERE id = $1`, id, therapist.ID); err != nil {
+		return nil, err
+	}
+
+	return therapist, nil
+}
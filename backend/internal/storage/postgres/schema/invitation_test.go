@@ -0,0 +1,121 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewInvitationRepository(testDB)
+	ctx := context.Background()
+
+	_, err := testDB.Exec(ctx, `
+		INSERT INTO district (id, name) VALUES (1, 'Test District'), (2, 'Other District') ON CONFLICT (id) DO NOTHING
+	`)
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO school (id, name, district_id) VALUES (1, 'Lincoln', 1), (2, 'Adams', 1), (3, 'Other', 2) ON CONFLICT (id) DO NOTHING
+	`)
+	require.NoError(t, err)
+
+	adminID := uuid.New()
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO therapist (id, first_name, last_name, email, district_id, role)
+		VALUES ($1, 'District', 'Admin', 'admin@example.com', 1, 'district_admin')
+	`, adminID)
+	require.NoError(t, err)
+
+	expiresAt := time.Now().Add(time.Hour)
+	first := "Ada"
+
+	_, err = repo.CreateInvitation(ctx, 1, adminID, &models.CreateInvitationInput{
+		Email: "ada@example.com", Schools: []int{1, 3},
+	}, expiresAt)
+	assert.Error(t, err, "school of another district")
+
+	_, err = repo.CreateInvitation(ctx, 1, adminID, &models.CreateInvitationInput{
+		Email: "Admin@Example.com", Schools: []int{1},
+	}, expiresAt)
+	assert.Error(t, err, "email already has an account")
+
+	invitation, err := repo.CreateInvitation(ctx, 1, adminID, &models.CreateInvitationInput{
+		Email: "ada@example.com", FirstName: &first, Schools: []int{2, 1, 2},
+	}, expiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, invitation.Schools)
+	assert.Equal(t, models.InvitationPending, invitation.Status(time.Now()))
+
+	_, err = repo.CreateInvitation(ctx, 1, adminID, &models.CreateInvitationInput{
+		Email: "ADA@example.com", Schools: []int{1},
+	}, expiresAt)
+	assert.Error(t, err, "pending invitation for the email")
+
+	preview, err := repo.GetInvitationPreview(ctx, invitation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Test District", preview.DistrictName)
+	assert.Equal(t, []string{"Adams", "Lincoln"}, preview.SchoolNames)
+
+	pending, err := repo.GetPendingInvitations(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+	pending, err = repo.GetPendingInvitations(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// Accepting creates the therapist and uses up the invitation
+	tx, err := repo.Begin(ctx)
+	require.NoError(t, err)
+	claimed, err := repo.ClaimInvitation(ctx, tx, invitation.ID)
+	require.NoError(t, err)
+
+	therapistID := uuid.New()
+	therapist, err := repo.AcceptInvitation(ctx, tx, claimed.ID, &models.CreateTherapistInput{
+		ID:         therapistID,
+		FirstName:  "Ada",
+		LastName:   "Lovelace",
+		Email:      claimed.Email,
+		Schools:    claimed.Schools,
+		DistrictID: &claimed.DistrictID,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(ctx))
+	assert.Equal(t, []int{1, 2}, therapist.Schools)
+	assert.Equal(t, 1, *therapist.DistrictID)
+	assert.Equal(t, models.RoleTherapist, therapist.Role)
+
+	claimed, err = repo.ClaimInvitation(ctx, testDB, invitation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.InvitationAccepted, claimed.Status(time.Now()))
+	assert.Equal(t, therapistID, *claimed.AcceptedBy)
+
+	_, err = repo.GetInvitationPreview(ctx, invitation.ID)
+	assert.Error(t, err)
+	assert.Error(t, repo.RevokeInvitation(ctx, 1, invitation.ID), "accepted invitations cannot be revoked")
+
+	// Revoked invitations free the email for a new one
+	other, err := repo.CreateInvitation(ctx, 1, adminID, &models.CreateInvitationInput{
+		Email: "grace@example.com", Schools: []int{1},
+	}, expiresAt)
+	require.NoError(t, err)
+	assert.Error(t, repo.RevokeInvitation(ctx, 2, other.ID), "invitation of another district")
+	require.NoError(t, repo.RevokeInvitation(ctx, 1, other.ID))
+
+	_, err = repo.CreateInvitation(ctx, 1, adminID, &models.CreateInvitationInput{
+		Email: "grace@example.com", Schools: []int{1},
+	}, expiresAt)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"specialstandard/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const jobRunColumns = `
+	id, name, payload, status, attempts, max_attempts, run_at, dedupe_key, locked_by, locked_until,
+	last_error, created_at, started_at, finished_at`
+
+type JobRunRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewJobRunRepository(db *pgxpool.Pool) *JobRunRepository {
+	return &JobRunRepository{db: db}
+}
+
+// EnqueueJob queues a run of the job. A run with the same dedupe key queued before keeps
+// it from being queued again, and nil is returned for it.
+func (r *JobRunRepository) EnqueueJob(ctx context.Context, input *models.EnqueueJobInput) (*models.JobRun, error) {
+	payload := input.Payload
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+
+	query := `
+	INSERT INTO job_run (name, payload, run_at, max_attempts, dedupe_key)
+	VALUES ($1, $2, $3, COALESCE(NULLIF($4, 0), 3), $5)
+	ON CONFLICT (dedupe_key) DO NOTHING
+	RETURNING` + jobRunColumns
+
+	rows, err := r.db.Query(ctx, query, input.Name, payload, input.RunAt, input.MaxAttempts, input.DedupeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.JobRun])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return run, err
+}
+
+// ClaimDueJobs leases to the worker the due runs of the jobs named, and runs whose last
+// worker let their lease run out. A run is counted as attempted once claimed, so one that
+// keeps bringing its worker down is given up on all the same.
+func (r *JobRunRepository) ClaimDueJobs(ctx context.Context, names []string, workerID string, limit int, lease time.Duration) ([]models.JobRun, error) {
+	_, err := r.db.Exec(ctx, `
+	UPDATE job_run
+	SET status = 'failed', last_error = 'Lease expired before the run finished',
+		locked_by = NULL, locked_until = NULL, finished_at = now()
+	WHERE status = 'running' AND locked_until < now() AND attempts >= max_attempts`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	UPDATE job_run
+	SET status = 'running', attempts = attempts + 1, locked_by = $3,
+		locked_until = now() + make_interval(secs => $4), started_at = now()
+	WHERE id IN (
+		SELECT id FROM job_run
+		WHERE name = ANY($1) AND run_at <= now()
+		  AND (status = 'pending' OR (status = 'running' AND locked_until < now()))
+		ORDER BY run_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING` + jobRunColumns
+
+	rows, err := r.db.Query(ctx, query, names, limit, workerID, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.JobRun])
+}
+
+// CompleteJob records that the worker's run succeeded
+func (r *JobRunRepository) CompleteJob(ctx context.Context, id uuid.UUID, workerID string) error {
+	query := `
+	UPDATE job_run
+	SET status = 'succeeded', last_error = NULL, locked_by = NULL, locked_until = NULL, finished_at = now()
+	WHERE id = $1 AND locked_by = $2`
+
+	_, err := r.db.Exec(ctx, query, id, workerID)
+	return err
+}
+
+// FailJob records that the worker's run failed. The run is retried at retryAt, or given up
+// on when retryAt is nil.
+func (r *JobRunRepository) FailJob(ctx context.Context, id uuid.UUID, workerID, lastError string, retryAt *time.Time) error {
+	query := `
+	UPDATE job_run
+	SET last_error = $3,
+		status = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+		run_at = COALESCE($4, run_at),
+		finished_at = CASE WHEN $4::timestamptz IS NULL THEN now() END,
+		locked_by = NULL, locked_until = NULL
+	WHERE id = $1 AND locked_by = $2`
+
+	_, err := r.db.Exec(ctx, query, id, workerID, lastError, retryAt)
+	return err
+}
+
+// GetJobRuns lists runs, the latest queued first
+func (r *JobRunRepository) GetJobRuns(ctx context.Context, query *models.GetJobRunsQuery) ([]models.JobRun, error) {
+	args := []any{query.Limit, query.GetOffset()}
+	conditions := []string{"TRUE"}
+	if query.Name != "" {
+		args = append(args, query.Name)
+		conditions = append(conditions, fmt.Sprintf("name = $%d", len(args)))
+	}
+	if query.Status != "" {
+		args = append(args, query.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	rows, err := r.db.Query(ctx, `
+	SELECT`+jobRunColumns+`
+	FROM job_run
+	WHERE `+strings.Join(conditions, " AND ")+`
+	ORDER BY created_at DESC, id
+	LIMIT $1 OFFSET $2`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.JobRun])
+}
+
+// DeleteFinishedJobRuns removes the runs that finished before the time, and returns how
+// many there were
+func (r *JobRunRepository) DeleteFinishedJobRuns(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+	DELETE FROM job_run
+	WHERE status IN ('succeeded', 'failed') AND finished_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
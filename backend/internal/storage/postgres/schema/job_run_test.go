@@ -0,0 +1,155 @@
+package schema_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+	"specialstandard/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobRunRepository_Lifecycle(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewJobRunRepository(testDB)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute)
+	key := "purge@2025-09-02T15:00:00Z"
+
+	run, err := repo.EnqueueJob(ctx, &models.EnqueueJobInput{Name: "purge", RunAt: past, DedupeKey: &key})
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, models.JobPending, run.Status)
+	assert.Equal(t, 3, run.MaxAttempts)
+	assert.JSONEq(t, `{}`, string(run.Payload))
+
+	// Another instance queuing the same occurrence
+	duplicate, err := repo.EnqueueJob(ctx, &models.EnqueueJobInput{Name: "purge", RunAt: past, DedupeKey: &key})
+	require.NoError(t, err)
+	assert.Nil(t, duplicate)
+
+	later, err := repo.EnqueueJob(ctx, &models.EnqueueJobInput{
+		Name: "purge", Payload: json.RawMessage(`{"n":1}`), RunAt: time.Now().Add(time.Hour), MaxAttempts: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, later.MaxAttempts)
+
+	// Only the due run of the jobs named is claimed, and only once
+	claimed, err := repo.ClaimDueJobs(ctx, []string{"other"}, "worker-1", 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	claimed, err = repo.ClaimDueJobs(ctx, []string{"purge"}, "worker-1", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, run.ID, claimed[0].ID)
+	assert.Equal(t, models.JobRunning, claimed[0].Status)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	require.NotNil(t, claimed[0].LockedBy)
+	assert.Equal(t, "worker-1", *claimed[0].LockedBy)
+
+	claimed, err = repo.ClaimDueJobs(ctx, []string{"purge"}, "worker-2", 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// Another worker cannot record the outcome of the run
+	require.NoError(t, repo.CompleteJob(ctx, run.ID, "worker-2"))
+	runs, err := repo.GetJobRuns(ctx, &models.GetJobRunsQuery{Status: models.JobRunning, Pagination: utils.NewPagination()})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+
+	// Retried when due again
+	require.NoError(t, repo.FailJob(ctx, run.ID, "worker-1", "connection refused", &past))
+	claimed, err = repo.ClaimDueJobs(ctx, []string{"purge"}, "worker-2", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 2, claimed[0].Attempts)
+	require.NotNil(t, claimed[0].LastError)
+	assert.Equal(t, "connection refused", *claimed[0].LastError)
+
+	require.NoError(t, repo.CompleteJob(ctx, run.ID, "worker-2"))
+	runs, err = repo.GetJobRuns(ctx, &models.GetJobRunsQuery{Status: models.JobSucceeded, Pagination: utils.NewPagination()})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Nil(t, runs[0].LastError)
+	assert.Nil(t, runs[0].LockedBy)
+	assert.NotNil(t, runs[0].FinishedAt)
+}
+
+func TestJobRunRepository_GivesUp(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewJobRunRepository(testDB)
+	ctx := context.Background()
+
+	failed, err := repo.EnqueueJob(ctx, &models.EnqueueJobInput{Name: "work", RunAt: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+	abandoned, err := repo.EnqueueJob(ctx, &models.EnqueueJobInput{Name: "work", RunAt: time.Now().Add(-time.Minute), MaxAttempts: 1})
+	require.NoError(t, err)
+
+	claimed, err := repo.ClaimDueJobs(ctx, []string{"work"}, "worker-1", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	require.NoError(t, repo.FailJob(ctx, failed.ID, "worker-1", "invalid payload", nil))
+
+	// The worker running the other run went away, and it was its last attempt
+	_, err = testDB.Exec(ctx, `UPDATE job_run SET locked_until = now() - interval '1 second' WHERE id = $1`, abandoned.ID)
+	require.NoError(t, err)
+
+	claimed, err = repo.ClaimDueJobs(ctx, []string{"work"}, "worker-2", 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	runs, err := repo.GetJobRuns(ctx, &models.GetJobRunsQuery{Name: "work", Status: models.JobFailed, Pagination: utils.NewPagination()})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	for _, run := range runs {
+		assert.NotNil(t, run.LastError)
+		assert.NotNil(t, run.FinishedAt)
+		assert.Nil(t, run.LockedBy)
+	}
+}
+
+func TestJobRunRepository_DeleteFinishedJobRuns(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewJobRunRepository(testDB)
+	ctx := context.Background()
+
+	for _, status := range []string{models.JobSucceeded, models.JobFailed, models.JobPending} {
+		_, err := testDB.Exec(ctx, `
+		INSERT INTO job_run (name, status, run_at, finished_at)
+		VALUES ('work', $1, now() - interval '40 days', CASE WHEN $1 <> 'pending' THEN now() - interval '40 days' END)`, status)
+		require.NoError(t, err)
+	}
+	_, err := repo.EnqueueJob(ctx, &models.EnqueueJobInput{Name: "work", RunAt: time.Now()})
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteFinishedJobRuns(ctx, time.Now().Add(-30*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	runs, err := repo.GetJobRuns(ctx, &models.GetJobRunsQuery{Pagination: utils.NewPagination()})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	for _, run := range runs {
+		assert.Equal(t, models.JobPending, run.Status)
+	}
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/dbinterface"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// syncMakeupLedger brings the makeup ledger in step with the sessions. A student owes the
+// minutes of a session that was cancelled, or that the student was marked absent from,
+// unless the session was itself a makeup. A makeup session the student attends, from when
+// it is scheduled, makes up as many of the minutes still owed for the missed session as it
+// lasts. The other makeup sessions for the same missed sessions are brought in step
+// afterwards, earliest first, as what is left for them to make up may have changed.
+func syncMakeupLedger(ctx context.Context, q dbinterface.Queryable, sessionIDs []uuid.UUID) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	if err := syncMakeupEntries(ctx, q, sessionIDs); err != nil {
+		return err
+	}
+
+	rows, err := q.Query(ctx, `
+	SELECT id FROM session
+	WHERE (makeup_for_session_id = ANY($1)
+	       OR makeup_for_session_id IN (SELECT makeup_for_session_id FROM session WHERE id = ANY($1)))
+	  AND NOT (id = ANY($1))
+	ORDER BY start_datetime, id`, sessionIDs)
+	if err != nil {
+		return err
+	}
+	makeups, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return err
+	}
+	// One at a time, so each sees what the ones before it made up
+	for _, id := range makeups {
+		if err := syncMakeupEntries(ctx, q, []uuid.UUID{id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncMakeupEntries writes the ledger entries of the sessions' students, and removes the
+// ones that no longer apply
+func syncMakeupEntries(ctx context.Context, q dbinterface.Queryable, sessionIDs []uuid.UUID) error {
+	query := `
+	WITH entry AS (
+		SELECT ss.student_id, s.id AS session_id, s.makeup_for_session_id,
+		       CASE WHEN s.makeup_for_session_id IS NULL THEN 'missed' ELSE 'made_up' END AS kind,
+		       CASE WHEN s.makeup_for_session_id IS NULL THEN d.minutes
+		            ELSE -LEAST(d.minutes, GREATEST(0,
+		                COALESCE((
+		                    SELECT o.minutes FROM makeup_ledger o
+		                    WHERE o.student_id = ss.student_id AND o.session_id = s.makeup_for_session_id
+		                      AND o.kind = 'missed'
+		                ), 0) + COALESCE((
+		                    SELECT SUM(c.minutes) FROM makeup_ledger c
+		                    WHERE c.student_id = ss.student_id AND c.makeup_for_session_id = s.makeup_for_session_id
+		                      AND c.session_id <> s.id
+		                ), 0)))
+		       END AS minutes,
+		       CASE WHEN s.makeup_for_session_id IS NOT NULL THEN NULL
+		            WHEN s.status IN ` + missedStatuses + ` THEN s.status
+		            ELSE 'student_absent'
+		       END AS reason
+		FROM session s
+		JOIN session_student ss ON ss.session_id = s.id
+		CROSS JOIN LATERAL (
+			SELECT (EXTRACT(EPOCH FROM s.end_datetime - s.start_datetime) / 60)::int AS minutes
+		) d
+		WHERE s.id = ANY($1)
+		  AND CASE WHEN s.makeup_for_session_id IS NULL
+		           THEN s.status IN ` + missedStatuses + ` OR ss.present IS FALSE
+		           ELSE s.status NOT IN ` + missedStatuses + ` AND ss.present IS NOT FALSE
+		      END
+	), wanted AS (
+		SELECT * FROM entry WHERE minutes <> 0
+	), removed AS (
+		DELETE FROM makeup_ledger l
+		WHERE l.session_id = ANY($1)
+		  AND NOT EXISTS (SELECT 1 FROM wanted w WHERE w.student_id = l.student_id AND w.session_id = l.session_id)
+	)
+	INSERT INTO makeup_ledger (student_id, session_id, kind, minutes, reason, makeup_for_session_id)
+	SELECT student_id, session_id, kind, minutes, reason, makeup_for_session_id FROM wanted
+	ON CONFLICT (student_id, session_id) DO UPDATE
+	SET kind = EXCLUDED.kind, minutes = EXCLUDED.minutes, reason = EXCLUDED.reason,
+	    makeup_for_session_id = EXCLUDED.makeup_for_session_id, updated_at = now()
+	WHERE (makeup_ledger.kind, makeup_ledger.minutes, makeup_ledger.reason, makeup_ledger.makeup_for_session_id)
+	      IS DISTINCT FROM (EXCLUDED.kind, EXCLUDED.minutes, EXCLUDED.reason, EXCLUDED.makeup_for_session_id)`
+
+	_, err := q.Exec(ctx, query, sessionIDs)
+	return err
+}
+
+// GetMakeupLedger lists the student's makeup ledger entries, oldest session first
+func (r *StudentRepository) GetMakeupLedger(ctx context.Context, studentID uuid.UUID) ([]models.MakeupEntry, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT l.id, l.student_id, l.session_id, l.kind, l.minutes, l.reason, l.makeup_for_session_id,
+	       s.session_name, s.start_datetime, l.created_at, l.updated_at
+	FROM makeup_ledger l
+	JOIN session s ON s.id = l.session_id
+	WHERE l.student_id = $1
+	ORDER BY s.start_datetime, l.kind DESC`, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.MakeupEntry])
+}
+
+// GetOwedMinutes lists the students still owed minutes for the missed session, less what
+// makeup sessions for it made up
+func (r *SessionRepository) GetOwedMinutes(ctx context.Context, sessionID uuid.UUID) ([]models.OwedMinutes, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT o.student_id, o.minutes + COALESCE(SUM(c.minutes), 0) AS minutes
+	FROM makeup_ledger o
+	LEFT JOIN makeup_ledger c ON c.student_id = o.student_id AND c.makeup_for_session_id = o.session_id
+	WHERE o.session_id = $1 AND o.kind = 'missed'
+	GROUP BY o.student_id, o.minutes
+	HAVING o.minutes + COALESCE(SUM(c.minutes), 0) > 0
+	ORDER BY o.student_id`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.OwedMinutes])
+}
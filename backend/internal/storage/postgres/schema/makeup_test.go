@@ -0,0 +1,111 @@
+package schema_test
+
+import (
+	"context"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeupLedger(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	sessions := schema.NewSessionRepository(testDB)
+	sessionStudents := schema.NewSessionStudentRepository(testDB)
+	students := schema.NewStudentRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Makeup")
+	ada := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Ada", 3)
+	grace := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Grace", 3)
+
+	start := time.Date(2025, 9, 2, 14, 0, 0, 0, time.UTC)
+	missed := createAttendedSession(t, testDB, ctx, therapistID, start, models.SessionScheduled, true, ada, grace)
+
+	owed := func() map[uuid.UUID]int {
+		rows, err := sessions.GetOwedMinutes(ctx, missed)
+		assert.NoError(t, err)
+		byStudent := map[uuid.UUID]int{}
+		for _, o := range rows {
+			byStudent[o.StudentID] = o.Minutes
+		}
+		return byStudent
+	}
+
+	// Cancelling owes both students the hour, restoring it takes that back
+	_, err := sessions.UpdateSessionStatus(ctx, missed, models.SessionCancelledBySchool, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{ada: 60, grace: 60}, owed())
+
+	_, err = sessions.UpdateSessionStatus(ctx, missed, models.SessionScheduled, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, owed())
+
+	// Only the absent student is owed for a session that took place
+	absent := false
+	_, _, err = sessionStudents.RateStudentSession(ctx, &models.PatchSessionStudentInput{
+		SessionID: missed,
+		StudentID: ada,
+		Present:   &absent,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{ada: 60}, owed())
+
+	makeup := func(minutes int) uuid.UUID {
+		created, err := sessions.PostSession(ctx, testDB, &models.PostSessionInput{
+			SessionName:        "Makeup",
+			StartTime:          start.AddDate(0, 0, 7),
+			EndTime:            start.AddDate(0, 0, 7).Add(time.Duration(minutes) * time.Minute),
+			TherapistID:        therapistID,
+			MakeupForSessionID: &missed,
+		})
+		assert.NoError(t, err)
+		id := (*created)[0].ID
+		assert.Equal(t, models.SessionMakeup, (*created)[0].Status)
+
+		_, err = sessionStudents.CreateSessionStudent(ctx, testDB, &models.CreateSessionStudentInput{
+			SessionIDs: []uuid.UUID{id},
+			StudentIDs: []uuid.UUID{ada},
+			Present:    true,
+		})
+		assert.NoError(t, err)
+		return id
+	}
+
+	first := makeup(30)
+	assert.Equal(t, map[uuid.UUID]int{ada: 30}, owed())
+
+	// Made up no further than what is still owed
+	makeup(45)
+	assert.Empty(t, owed())
+
+	ledger, err := students.GetMakeupLedger(ctx, ada)
+	assert.NoError(t, err)
+	balance := models.NewMakeupBalance(ada, ledger)
+	assert.Len(t, balance.Entries, 3)
+	assert.Equal(t, 60, balance.OwedMinutes)
+	assert.Equal(t, 60, balance.MadeUpMinutes)
+	assert.Equal(t, 0, balance.BalanceMinutes)
+
+	// A cancelled makeup makes nothing up, and leaves the rest to the other makeup
+	_, err = sessions.UpdateSessionStatus(ctx, first, models.SessionCancelledByTherapist, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{ada: 15}, owed())
+
+	ledger, err = students.GetMakeupLedger(ctx, ada)
+	assert.NoError(t, err)
+	if assert.Len(t, ledger, 2) {
+		assert.Equal(t, models.MakeupMissed, ledger[0].Kind)
+		assert.Equal(t, models.SessionStudentAbsent, *ledger[0].Reason)
+		assert.Equal(t, -45, ledger[1].Minutes)
+		assert.Equal(t, &missed, ledger[1].MakeupForSessionID)
+	}
+}
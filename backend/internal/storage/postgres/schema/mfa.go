@@ -0,0 +1,234 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MFARepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMFARepository(db *pgxpool.Pool) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// GetTOTPFactor returns the therapist's authenticator app factor, nil when there is none
+func (r *MFARepository) GetTOTPFactor(ctx context.Context, therapistID uuid.UUID) (*models.TOTPFactor, error) {
+	query := `
+	SELECT m.therapist_id, m.secret_sealed, m.enabled, m.last_used_step, m.created_at, m.confirmed_at,
+		(SELECT count(*) FROM mfa_recovery_code rc
+			WHERE rc.therapist_id = m.therapist_id AND rc.used_at IS NULL)::int AS recovery_codes_remaining
+	FROM therapist_mfa m
+	WHERE m.therapist_id = $1`
+
+	rows, err := r.db.Query(ctx, query, therapistID)
+	if err != nil {
+		return nil, err
+	}
+
+	factor, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.TOTPFactor])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return factor, err
+}
+
+// SavePendingTOTP stores a new secret waiting to be confirmed, replacing an earlier
+// unconfirmed one. An enabled factor is never replaced.
+func (r *MFARepository) SavePendingTOTP(ctx context.Context, therapistID uuid.UUID, secretSealed string) error {
+	query := `
+	INSERT INTO therapist_mfa (therapist_id, secret_sealed)
+	VALUES ($1, $2)
+	ON CONFLICT (therapist_id) DO UPDATE
+	SET secret_sealed = EXCLUDED.secret_sealed, created_at = now(), last_used_step = 0
+	WHERE therapist_mfa.enabled = false`
+
+	tag, err := r.db.Exec(ctx, query, therapistID, secretSealed)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.Conflict("An authenticator app is already enabled")
+	}
+
+	return nil
+}
+
+// EnableTOTP confirms the pending factor with the step of its first code and gives the
+// therapist a fresh set of recovery codes
+func (r *MFARepository) EnableTOTP(ctx context.Context, therapistID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, `
+	UPDATE therapist_mfa
+	SET enabled = true, confirmed_at = now(), last_used_step = $2
+	WHERE therapist_id = $1 AND enabled = false`, therapistID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.NotFound("No authenticator app is waiting to be confirmed")
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, therapistID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseTOTPStep records that the code of step was used. It reports false when that code (or
+// a later one) was already used, so every code works once.
+func (r *MFARepository) UseTOTPStep(ctx context.Context, therapistID uuid.UUID, step int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+	UPDATE therapist_mfa
+	SET last_used_step = $2
+	WHERE therapist_id = $1 AND enabled = true AND last_used_step < $2`, therapistID, step)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode spends the recovery code with the given hash, reporting false when the
+// therapist has no such unused code
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, therapistID uuid.UUID, codeHash string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+	UPDATE mfa_recovery_code
+	SET used_at = now()
+	WHERE id = (
+		SELECT id FROM mfa_recovery_code
+		WHERE therapist_id = $1 AND code_hash = $2 AND used_at IS NULL
+		LIMIT 1
+	)`, therapistID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, therapistID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := replaceRecoveryCodes(ctx, tx, therapistID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, therapistID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_code WHERE therapist_id = $1`, therapistID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+	INSERT INTO mfa_recovery_code (therapist_id, code_hash)
+	SELECT $1, unnest($2::text[])`, therapistID, codeHashes)
+	return err
+}
+
+// DeleteTOTP removes the authenticator app together with its recovery codes
+func (r *MFARepository) DeleteTOTP(ctx context.Context, therapistID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_code WHERE therapist_id = $1`, therapistID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM therapist_mfa WHERE therapist_id = $1`, therapistID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_verified_session WHERE therapist_id = $1`, therapistID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateMFAChallenge stores a login waiting for its second factor. Expired challenges are
+// cleared on the way.
+func (r *MFARepository) CreateMFAChallenge(ctx context.Context, therapistID uuid.UUID, tokenHash, refreshToken string, rememberMe bool, expiresAt time.Time) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM mfa_challenge WHERE expires_at < now()`); err != nil {
+		return err
+	}
+
+	_, err := r.db.Exec(ctx, `
+	INSERT INTO mfa_challenge (therapist_id, token_hash, refresh_token, remember_me, expires_at)
+	VALUES ($1, $2, $3, $4, $5)`, therapistID, tokenHash, refreshToken, rememberMe, expiresAt)
+	return err
+}
+
+// GetMFAChallenge finds the live challenge with the given token hash. Unknown and expired
+// challenges are pgx.ErrNoRows.
+func (r *MFARepository) GetMFAChallenge(ctx context.Context, tokenHash string) (*models.MFAChallenge, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT id, therapist_id, refresh_token, remember_me, expires_at
+	FROM mfa_challenge
+	WHERE token_hash = $1 AND expires_at > now()`, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.MFAChallenge])
+}
+
+// DeleteMFAChallenge spends the challenge, reporting false when it was already spent
+func (r *MFARepository) DeleteMFAChallenge(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM mfa_challenge WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// RecordMFASession marks the Supabase session as one that passed the authenticator app
+func (r *MFARepository) RecordMFASession(ctx context.Context, therapistID, sessionID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+	INSERT INTO mfa_verified_session (session_id, therapist_id)
+	VALUES ($1, $2)
+	ON CONFLICT (session_id) DO NOTHING`, sessionID, therapistID)
+	return err
+}
+
+// SessionPassedMFA reports whether the session may be used: the therapist has no enabled
+// authenticator app, or the session passed it
+func (r *MFARepository) SessionPassedMFA(ctx context.Context, therapistID, sessionID uuid.UUID) (bool, error) {
+	var passed bool
+	err := r.db.QueryRow(ctx, `
+	SELECT NOT EXISTS (
+		SELECT 1 FROM therapist_mfa WHERE therapist_id = $1 AND enabled = true
+	) OR EXISTS (
+		SELECT 1 FROM mfa_verified_session WHERE session_id = $2 AND therapist_id = $1
+	)`, therapistID, sessionID).Scan(&passed)
+
+	return passed, err
+}
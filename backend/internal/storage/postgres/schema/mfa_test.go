@@ -0,0 +1,118 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFARepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewMFARepository(testDB)
+	ctx := context.Background()
+
+	therapistID := uuid.New()
+	_, err := testDB.Exec(ctx, `
+		INSERT INTO therapist (id, first_name, last_name, email) VALUES ($1, 'Two', 'Factor', $2)
+	`, therapistID, therapistID.String()+"@example.com")
+	require.NoError(t, err)
+
+	factor, err := repo.GetTOTPFactor(ctx, therapistID)
+	require.NoError(t, err)
+	assert.Nil(t, factor)
+
+	// Enrolling twice before confirming replaces the secret
+	require.NoError(t, repo.SavePendingTOTP(ctx, therapistID, "sealed-1"))
+	require.NoError(t, repo.SavePendingTOTP(ctx, therapistID, "sealed-2"))
+
+	factor, err = repo.GetTOTPFactor(ctx, therapistID)
+	require.NoError(t, err)
+	assert.Equal(t, "sealed-2", factor.SecretSealed)
+	assert.False(t, factor.Enabled)
+
+	require.NoError(t, repo.EnableTOTP(ctx, therapistID, 100, []string{"code-1", "code-2"}))
+	assert.Error(t, repo.EnableTOTP(ctx, therapistID, 101, nil), "already enabled")
+	assert.Error(t, repo.SavePendingTOTP(ctx, therapistID, "sealed-3"), "enabled factor is not replaced")
+
+	factor, err = repo.GetTOTPFactor(ctx, therapistID)
+	require.NoError(t, err)
+	assert.True(t, factor.Enabled)
+	assert.NotNil(t, factor.ConfirmedAt)
+	assert.Equal(t, 2, factor.RecoveryCodesRemaining)
+
+	// Steps only move forward, the confirming code cannot be replayed
+	used, err := repo.UseTOTPStep(ctx, therapistID, 100)
+	require.NoError(t, err)
+	assert.False(t, used)
+	used, err = repo.UseTOTPStep(ctx, therapistID, 101)
+	require.NoError(t, err)
+	assert.True(t, used)
+
+	used, err = repo.UseRecoveryCode(ctx, therapistID, "code-1")
+	require.NoError(t, err)
+	assert.True(t, used)
+	used, err = repo.UseRecoveryCode(ctx, therapistID, "code-1")
+	require.NoError(t, err)
+	assert.False(t, used)
+
+	require.NoError(t, repo.ReplaceRecoveryCodes(ctx, therapistID, []string{"code-3"}))
+	used, err = repo.UseRecoveryCode(ctx, therapistID, "code-2")
+	require.NoError(t, err)
+	assert.False(t, used, "replaced codes stop working")
+
+	// Challenges are spent once
+	require.NoError(t, repo.CreateMFAChallenge(ctx, therapistID, "token-hash", "refresh", true, time.Now().Add(time.Minute)))
+	challenge, err := repo.GetMFAChallenge(ctx, "token-hash")
+	require.NoError(t, err)
+	assert.Equal(t, therapistID, challenge.TherapistID)
+	assert.Equal(t, "refresh", challenge.RefreshToken)
+	assert.True(t, challenge.RememberMe)
+
+	deleted, err := repo.DeleteMFAChallenge(ctx, challenge.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.DeleteMFAChallenge(ctx, challenge.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	require.NoError(t, repo.CreateMFAChallenge(ctx, therapistID, "expired-hash", "refresh", false, time.Now().Add(-time.Minute)))
+	_, err = repo.GetMFAChallenge(ctx, "expired-hash")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// With the app enabled only sessions that passed it are let through
+	sessionID := uuid.New()
+	passed, err := repo.SessionPassedMFA(ctx, therapistID, sessionID)
+	require.NoError(t, err)
+	assert.False(t, passed)
+
+	require.NoError(t, repo.RecordMFASession(ctx, therapistID, sessionID))
+	require.NoError(t, repo.RecordMFASession(ctx, therapistID, sessionID), "recording twice is fine")
+	passed, err = repo.SessionPassedMFA(ctx, therapistID, sessionID)
+	require.NoError(t, err)
+	assert.True(t, passed)
+
+	passed, err = repo.SessionPassedMFA(ctx, uuid.New(), sessionID)
+	require.NoError(t, err)
+	assert.True(t, passed, "therapists without an app need no second factor")
+
+	require.NoError(t, repo.DeleteTOTP(ctx, therapistID))
+	factor, err = repo.GetTOTPFactor(ctx, therapistID)
+	require.NoError(t, err)
+	assert.Nil(t, factor)
+
+	passed, err = repo.SessionPassedMFA(ctx, therapistID, uuid.New())
+	require.NoError(t, err)
+	assert.True(t, passed)
+}
@@ -0,0 +1,133 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The defaults of notification_preference stand in for therapists who never saved theirs
+const notificationPreferenceColumns = `
+	t.id AS therapist_id,
+	COALESCE(np.channel, 'none') AS channel,
+	COALESCE(np.digest_enabled, TRUE) AS digest_enabled,
+	to_char(COALESCE(np.digest_time, '07:00'), 'HH24:MI') AS digest_time,
+	COALESCE(np.reminder_enabled, TRUE) AS reminder_enabled,
+	COALESCE(np.reminder_lead_minutes, 15) AS reminder_lead_minutes,
+	t.time_zone, np.created_at, np.updated_at`
+
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) GetNotificationPreference(ctx context.Context, therapistID uuid.UUID) (*models.NotificationPreference, error) {
+	query := `
+	SELECT` + notificationPreferenceColumns + `
+	FROM therapist t
+	LEFT JOIN notification_preference np ON np.therapist_id = t.id
+	WHERE t.id = $1`
+
+	rows, err := r.db.Query(ctx, query, therapistID)
+	if err != nil {
+		return nil, err
+	}
+
+	preference, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.NotificationPreference])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Therapist", "id", therapistID.String())
+	}
+	return preference, err
+}
+
+// UpdateNotificationPreference saves the preferences given, keeping the others
+func (r *NotificationRepository) UpdateNotificationPreference(ctx context.Context, therapistID uuid.UUID, input *models.UpdateNotificationPreferenceInput) (*models.NotificationPreference, error) {
+	query := `
+	WITH np AS (
+		INSERT INTO notification_preference
+			(therapist_id, channel, digest_enabled, digest_time, reminder_enabled, reminder_lead_minutes)
+		SELECT id, COALESCE($2, 'none'), COALESCE($3, TRUE), COALESCE($4::time, '07:00'),
+		       COALESCE($5, TRUE), COALESCE($6, 15)
+		FROM therapist WHERE id = $1
+		ON CONFLICT (therapist_id) DO UPDATE
+		SET channel = COALESCE($2, notification_preference.channel),
+			digest_enabled = COALESCE($3, notification_preference.digest_enabled),
+			digest_time = COALESCE($4::time, notification_preference.digest_time),
+			reminder_enabled = COALESCE($5, notification_preference.reminder_enabled),
+			reminder_lead_minutes = COALESCE($6, notification_preference.reminder_lead_minutes),
+			updated_at = now()
+		RETURNING *
+	)
+	SELECT` + notificationPreferenceColumns + `
+	FROM np
+	JOIN therapist t ON t.id = np.therapist_id`
+
+	rows, err := r.db.Query(ctx, query, therapistID, input.Channel, input.DigestEnabled, input.DigestTime,
+		input.ReminderEnabled, input.ReminderLeadMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	preference, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.NotificationPreference])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Therapist", "id", therapistID.String())
+	}
+	return preference, err
+}
+
+// GetNotificationRecipients lists the active therapists to be emailed a digest or reminders
+func (r *NotificationRepository) GetNotificationRecipients(ctx context.Context) ([]models.NotificationRecipient, error) {
+	query := `
+	SELECT` + notificationPreferenceColumns + `, t.email, t.first_name
+	FROM notification_preference np
+	JOIN therapist t ON t.id = np.therapist_id
+	WHERE np.channel = 'email' AND t.active
+	  AND (np.digest_enabled OR np.reminder_enabled)
+	ORDER BY t.id`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.NotificationRecipient])
+}
+
+// ClaimNotification records that the notification is being sent, and reports false when it
+// was already
+func (r *NotificationRepository) ClaimNotification(ctx context.Context, therapistID uuid.UUID, kind, dedupeKey string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+	INSERT INTO notification_sent (dedupe_key, therapist_id, kind)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (dedupe_key) DO NOTHING`, dedupeKey, therapistID, kind)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReleaseNotification forgets a claimed notification that could not be sent, so it is
+// tried again
+func (r *NotificationRepository) ReleaseNotification(ctx context.Context, dedupeKey string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM notification_sent WHERE dedupe_key = $1`, dedupeKey)
+	return err
+}
+
+// DeleteSentNotifications forgets the notifications sent before the time, and returns how
+// many there were
+func (r *NotificationRepository) DeleteSentNotifications(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM notification_sent WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
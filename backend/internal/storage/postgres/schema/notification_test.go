@@ -0,0 +1,167 @@
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationRepository_Preference(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewNotificationRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Ada")
+	_, err := testDB.Exec(ctx, `UPDATE therapist SET time_zone = 'America/Chicago' WHERE id = $1`, therapistID)
+	require.NoError(t, err)
+
+	// Defaults until saved, nothing is sent
+	preference, err := repo.GetNotificationPreference(ctx, therapistID)
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationNone, preference.Channel)
+	assert.True(t, preference.DigestEnabled)
+	assert.Equal(t, "07:00", preference.DigestTime)
+	assert.True(t, preference.ReminderEnabled)
+	assert.Equal(t, 15, preference.ReminderLeadMinutes)
+	assert.Equal(t, "America/Chicago", preference.TimeZone)
+	assert.Nil(t, preference.CreatedAt)
+
+	channel, digestTime := models.NotificationEmail, "06:30"
+	preference, err = repo.UpdateNotificationPreference(ctx, therapistID, &models.UpdateNotificationPreferenceInput{
+		Channel:    &channel,
+		DigestTime: &digestTime,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationEmail, preference.Channel)
+	assert.Equal(t, "06:30", preference.DigestTime)
+	assert.Equal(t, 15, preference.ReminderLeadMinutes)
+	assert.NotNil(t, preference.CreatedAt)
+
+	// Only what is given changes
+	lead, reminders := 45, false
+	preference, err = repo.UpdateNotificationPreference(ctx, therapistID, &models.UpdateNotificationPreferenceInput{
+		ReminderEnabled:     &reminders,
+		ReminderLeadMinutes: &lead,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationEmail, preference.Channel)
+	assert.Equal(t, "06:30", preference.DigestTime)
+	assert.False(t, preference.ReminderEnabled)
+	assert.Equal(t, 45, preference.ReminderLeadMinutes)
+
+	stored, err := repo.GetNotificationPreference(ctx, therapistID)
+	require.NoError(t, err)
+	assert.Equal(t, preference, stored)
+
+	for _, get := range []func() error{
+		func() error { _, err := repo.GetNotificationPreference(ctx, uuid.New()); return err },
+		func() error {
+			_, err := repo.UpdateNotificationPreference(ctx, uuid.New(), &models.UpdateNotificationPreferenceInput{Channel: &channel})
+			return err
+		},
+	} {
+		var httpErr errs.HTTPError
+		require.True(t, errors.As(get(), &httpErr))
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	}
+}
+
+func TestNotificationRepository_GetNotificationRecipients(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewNotificationRepository(testDB)
+	ctx := context.Background()
+
+	email, none := models.NotificationEmail, models.NotificationNone
+	off := false
+
+	emailed := CreateSessionTestTherapist(t, testDB, ctx, "Ada")
+	_, err := repo.UpdateNotificationPreference(ctx, emailed, &models.UpdateNotificationPreferenceInput{Channel: &email})
+	require.NoError(t, err)
+
+	optedOut := CreateSessionTestTherapist(t, testDB, ctx, "Grace")
+	_, err = repo.UpdateNotificationPreference(ctx, optedOut, &models.UpdateNotificationPreferenceInput{Channel: &none})
+	require.NoError(t, err)
+
+	allOff := CreateSessionTestTherapist(t, testDB, ctx, "Hedy")
+	_, err = repo.UpdateNotificationPreference(ctx, allOff, &models.UpdateNotificationPreferenceInput{
+		Channel: &email, DigestEnabled: &off, ReminderEnabled: &off,
+	})
+	require.NoError(t, err)
+
+	inactive := CreateSessionTestTherapist(t, testDB, ctx, "Joan")
+	_, err = repo.UpdateNotificationPreference(ctx, inactive, &models.UpdateNotificationPreferenceInput{Channel: &email})
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `UPDATE therapist SET active = FALSE WHERE id = $1`, inactive)
+	require.NoError(t, err)
+
+	// Never saved preferences
+	CreateSessionTestTherapist(t, testDB, ctx, "Katherine")
+
+	recipients, err := repo.GetNotificationRecipients(ctx)
+	require.NoError(t, err)
+	require.Len(t, recipients, 1)
+	assert.Equal(t, emailed, recipients[0].TherapistID)
+	assert.Equal(t, "Ada", recipients[0].FirstName)
+	assert.Contains(t, recipients[0].Email, "Ada_")
+	assert.Equal(t, "UTC", recipients[0].TimeZone)
+}
+
+func TestNotificationRepository_ClaimNotification(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewNotificationRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Ada")
+	key := "digest:" + therapistID.String() + ":2025-09-02"
+
+	claimed, err := repo.ClaimNotification(ctx, therapistID, models.NotificationDigest, key)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Another instance, or a later run
+	claimed, err = repo.ClaimNotification(ctx, therapistID, models.NotificationDigest, key)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Released when it could not be sent
+	require.NoError(t, repo.ReleaseNotification(ctx, key))
+	claimed, err = repo.ClaimNotification(ctx, therapistID, models.NotificationDigest, key)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	_, err = repo.ClaimNotification(ctx, therapistID, models.NotificationReminder, "reminder:old")
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, `UPDATE notification_sent SET created_at = now() - interval '8 days' WHERE dedupe_key = 'reminder:old'`)
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteSentNotifications(ctx, time.Now().Add(-7*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	claimed, err = repo.ClaimNotification(ctx, therapistID, models.NotificationDigest, key)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+}
@@ -0,0 +1,213 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/dbinterface"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const schoolCalendarColumns = `id, district_id, school_id, name, kind, start_date, end_date,
+	to_char(dismissal_time, 'HH24:MI') AS dismissal_time, created_at, updated_at`
+
+type SchoolCalendarRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSchoolCalendarRepository(db *pgxpool.Pool) *SchoolCalendarRepository {
+	return &SchoolCalendarRepository{db: db}
+}
+
+// GetCalendarEvents lists the district's events that overlap the query's dates, in the
+// order they start
+func (r *SchoolCalendarRepository) GetCalendarEvents(ctx context.Context, districtID int, query *models.GetSchoolCalendarQuery) ([]models.SchoolCalendarEvent, error) {
+	conditions := []string{"district_id = $1"}
+	args := []any{districtID}
+
+	if query.SchoolID != nil {
+		args = append(args, *query.SchoolID)
+		conditions = append(conditions, fmt.Sprintf("(school_id IS NULL OR school_id = $%d)", len(args)))
+	}
+	if query.From != nil {
+		args = append(args, *query.From)
+		conditions = append(conditions, fmt.Sprintf("end_date >= $%d::date", len(args)))
+	}
+	if query.To != nil {
+		args = append(args, *query.To)
+		conditions = append(conditions, fmt.Sprintf("start_date <= $%d::date", len(args)))
+	}
+
+	rows, err := r.db.Query(ctx, `
+	SELECT `+schoolCalendarColumns+`
+	FROM school_calendar_event
+	WHERE `+strings.Join(conditions, " AND ")+`
+	ORDER BY start_date, school_id NULLS FIRST, name`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.SchoolCalendarEvent])
+}
+
+func (r *SchoolCalendarRepository) GetCalendarEvent(ctx context.Context, districtID int, id uuid.UUID) (*models.SchoolCalendarEvent, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT `+schoolCalendarColumns+`
+	FROM school_calendar_event
+	WHERE id = $1 AND district_id = $2`, id, districtID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.SchoolCalendarEvent])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Calendar event", "id", id.String())
+	}
+	return event, err
+}
+
+// CreateCalendarEvent adds the event to its district's calendar. A school given must
+// belong to the district.
+func (r *SchoolCalendarRepository) CreateCalendarEvent(ctx context.Context, event *models.SchoolCalendarEvent) (*models.SchoolCalendarEvent, error) {
+	if err := r.checkSchool(ctx, event); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+	INSERT INTO school_calendar_event (district_id, school_id, name, kind, start_date, end_date, dismissal_time)
+	VALUES ($1, $2, $3, $4, $5::date, $6::date, $7::time)
+	RETURNING `+schoolCalendarColumns,
+		event.DistrictID, event.SchoolID, event.Name, event.Kind, event.StartDate, event.EndDate, event.DismissalTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.SchoolCalendarEvent])
+}
+
+// UpdateCalendarEvent saves every field of the event. Sessions already generated are not
+// changed.
+func (r *SchoolCalendarRepository) UpdateCalendarEvent(ctx context.Context, event *models.SchoolCalendarEvent) (*models.SchoolCalendarEvent, error) {
+	if err := r.checkSchool(ctx, event); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+	UPDATE school_calendar_event
+	SET school_id = $3, name = $4, kind = $5, start_date = $6::date, end_date = $7::date,
+		dismissal_time = $8::time, updated_at = now()
+	WHERE id = $1 AND district_id = $2
+	RETURNING `+schoolCalendarColumns,
+		event.ID, event.DistrictID, event.SchoolID, event.Name, event.Kind, event.StartDate, event.EndDate, event.DismissalTime)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.SchoolCalendarEvent])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Calendar event", "id", event.ID.String())
+	}
+	return updated, err
+}
+
+func (r *SchoolCalendarRepository) DeleteCalendarEvent(ctx context.Context, districtID int, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM school_calendar_event WHERE id = $1 AND district_id = $2`, id, districtID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.NotFound("Calendar event", "id", id.String())
+	}
+	return nil
+}
+
+func (r *SchoolCalendarRepository) checkSchool(ctx context.Context, event *models.SchoolCalendarEvent) error {
+	if event.SchoolID == nil {
+		return nil
+	}
+
+	var inDistrict bool
+	if err := r.db.QueryRow(ctx, `
+	SELECT EXISTS (SELECT 1 FROM school WHERE id = $1 AND district_id = $2)`,
+		*event.SchoolID, event.DistrictID).Scan(&inDistrict); err != nil {
+		return err
+	}
+	if !inDistrict {
+		return errs.BadRequest("The school must belong to the district")
+	}
+	return nil
+}
+
+// nonSchoolDays lists the days from-to without classes for the therapist: those of the
+// therapist's district, and those every one of the therapist's schools is closed.
+// Dates are keyed as YYYY-MM-DD.
+func nonSchoolDays(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID, from, to time.Time) (map[string]bool, error) {
+	query := `
+	WITH t AS (
+		SELECT district_id, COALESCE(schools, '{}') AS schools FROM therapist WHERE id = $1
+	), closed AS (
+		SELECT e.district_id, e.school_id, d::date AS day
+		FROM school_calendar_event e
+		CROSS JOIN LATERAL generate_series(
+			GREATEST(e.start_date, $2::date), LEAST(e.end_date, $3::date), interval '1 day'
+		) AS d
+		WHERE e.kind <> 'early_release'
+		  AND e.start_date <= $3::date
+		  AND e.end_date >= $2::date
+	)
+	SELECT DISTINCT c.day
+	FROM closed c, t
+	WHERE (c.school_id IS NULL AND c.district_id = t.district_id)
+	   OR (c.school_id = ANY(t.schools) AND NOT EXISTS (
+	       SELECT 1 FROM unnest(t.schools) AS s(id)
+	       WHERE NOT EXISTS (SELECT 1 FROM closed o WHERE o.school_id = s.id AND o.day = c.day)
+	   ))`
+
+	// A day either side covers occurrences whose local date differs from the UTC one
+	rows, err := q.Query(ctx, query, therapistID, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := pgx.CollectRows(rows, pgx.RowTo[time.Time])
+	if err != nil {
+		return nil, err
+	}
+
+	closed := make(map[string]bool, len(days))
+	for _, d := range days {
+		closed[d.Format(time.DateOnly)] = true
+	}
+	return closed, nil
+}
+
+// skipNonSchoolDays adds the days without classes the repetition would otherwise fall on
+// to its exception dates
+func skipNonSchoolDays(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID, rp *models.Repetition, startTime, endTime time.Time) (*models.Repetition, error) {
+	occurrences := rp.Occurrences(startTime, endTime, time.Time{})
+	if len(occurrences) == 0 {
+		return rp, nil
+	}
+	closed, err := nonSchoolDays(ctx, q, therapistID, occurrences[0][0], occurrences[len(occurrences)-1][0])
+	if err != nil {
+		return nil, err
+	}
+
+	skipping := *rp
+	skipping.ExceptionDates = slices.Clone(rp.ExceptionDates)
+	for _, occ := range occurrences {
+		if closed[occ[0].Format(time.DateOnly)] {
+			y, m, d := occ[0].Date()
+			skipping.ExceptionDates = append(skipping.ExceptionDates, time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+		}
+	}
+	return &skipping, nil
+}
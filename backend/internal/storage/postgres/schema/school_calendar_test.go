@@ -0,0 +1,166 @@
+package schema_test
+
+import (
+	"context"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSchoolCalendarRepository_Events(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSchoolCalendarRepository(testDB)
+	ctx := context.Background()
+
+	// District 1 and school 1
+	CreateSessionTestTherapist(t, testDB, ctx, "Calendar")
+	_, err := testDB.Exec(ctx, `
+		INSERT INTO district (id, name) VALUES (2, 'Other District') ON CONFLICT (id) DO NOTHING`)
+	assert.NoError(t, err)
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO school (id, name, district_id) VALUES (2, 'Other School', 2) ON CONFLICT (id) DO NOTHING`)
+	assert.NoError(t, err)
+
+	schoolID := 1
+	winterBreak, err := repo.CreateCalendarEvent(ctx, &models.SchoolCalendarEvent{
+		DistrictID: 1,
+		Name:       "Winter break",
+		Kind:       models.CalendarBreak,
+		StartDate:  date(2025, 12, 22),
+		EndDate:    date(2026, 1, 2),
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, winterBreak.ID)
+	assert.Nil(t, winterBreak.SchoolID)
+
+	dismissal := "12:30"
+	conferences, err := repo.CreateCalendarEvent(ctx, &models.SchoolCalendarEvent{
+		DistrictID:    1,
+		SchoolID:      &schoolID,
+		Name:          "Conferences",
+		Kind:          models.CalendarEarlyRelease,
+		StartDate:     date(2025, 11, 20),
+		EndDate:       date(2025, 11, 20),
+		DismissalTime: &dismissal,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "12:30", *conferences.DismissalTime)
+
+	// A school of another district
+	otherSchool := 2
+	_, err = repo.CreateCalendarEvent(ctx, &models.SchoolCalendarEvent{
+		DistrictID: 1,
+		SchoolID:   &otherSchool,
+		Name:       "Snow day",
+		Kind:       models.CalendarClosure,
+		StartDate:  date(2026, 1, 15),
+		EndDate:    date(2026, 1, 15),
+	})
+	var httpErr errs.HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+
+	events, err := repo.GetCalendarEvents(ctx, 1, &models.GetSchoolCalendarQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, conferences.ID, events[0].ID)
+
+	// Events overlapping the range, not only those inside it
+	from, to := date(2026, 1, 1), date(2026, 1, 31)
+	events, err = repo.GetCalendarEvents(ctx, 1, &models.GetSchoolCalendarQuery{From: &from, To: &to})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, winterBreak.ID, events[0].ID)
+
+	events, err = repo.GetCalendarEvents(ctx, 2, &models.GetSchoolCalendarQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	_, err = repo.GetCalendarEvent(ctx, 2, winterBreak.ID)
+	assert.Error(t, err)
+
+	winterBreak.EndDate = date(2026, 1, 5)
+	updated, err := repo.UpdateCalendarEvent(ctx, winterBreak)
+	assert.NoError(t, err)
+	assert.True(t, updated.EndDate.Equal(date(2026, 1, 5)))
+
+	assert.NoError(t, repo.DeleteCalendarEvent(ctx, 1, winterBreak.ID))
+	assert.Error(t, repo.DeleteCalendarEvent(ctx, 1, winterBreak.ID))
+}
+
+func TestSchoolCalendarRepository_SkipsNonSchoolDays(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	calendar := schema.NewSchoolCalendarRepository(testDB)
+	sessions := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Holidays")
+	_, err := testDB.Exec(ctx, `INSERT INTO school (id, name, district_id) VALUES (3, 'Second School', 1) ON CONFLICT (id) DO NOTHING`)
+	assert.NoError(t, err)
+	_, err = testDB.Exec(ctx, `UPDATE therapist SET schools = '{1,3}' WHERE id = $1`, therapistID)
+	assert.NoError(t, err)
+
+	schoolOne, schoolThree := 1, 3
+	for _, e := range []models.SchoolCalendarEvent{
+		// Skipped: the whole district is off
+		{DistrictID: 1, Name: "Thanksgiving", Kind: models.CalendarHoliday, StartDate: date(2025, 11, 27), EndDate: date(2025, 11, 27)},
+		// Kept: only one of the therapist's schools is closed
+		{DistrictID: 1, SchoolID: &schoolOne, Name: "Snow day", Kind: models.CalendarClosure, StartDate: date(2025, 12, 4), EndDate: date(2025, 12, 4)},
+		// Skipped: both schools are closed
+		{DistrictID: 1, SchoolID: &schoolOne, Name: "Teacher day", Kind: models.CalendarClosure, StartDate: date(2025, 12, 11), EndDate: date(2025, 12, 11)},
+		{DistrictID: 1, SchoolID: &schoolThree, Name: "Teacher day", Kind: models.CalendarClosure, StartDate: date(2025, 12, 11), EndDate: date(2025, 12, 11)},
+		// Kept: early release still has classes
+		{DistrictID: 1, Name: "Conferences", Kind: models.CalendarEarlyRelease, StartDate: date(2025, 11, 20), EndDate: date(2025, 11, 20)},
+	} {
+		_, err := calendar.CreateCalendarEvent(ctx, &e)
+		assert.NoError(t, err)
+	}
+
+	// Thursdays from November 13 to December 18
+	start := time.Date(2025, 11, 13, 14, 0, 0, 0, time.UTC)
+	posted, err := sessions.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Fluency group",
+		StartTime:   start,
+		EndTime:     start.Add(30 * time.Minute),
+		TherapistID: therapistID,
+		Repetition: &models.Repetition{
+			RecurStart:  start,
+			RecurEnd:    date(2025, 12, 18).Add(23 * time.Hour),
+			EveryNWeeks: 1,
+			Days:        []int{int(time.Thursday)},
+		},
+	})
+	assert.NoError(t, err)
+
+	var days []string
+	for _, s := range *posted {
+		days = append(days, s.StartDateTime.UTC().Format(time.DateOnly))
+	}
+	assert.Equal(t, []string{"2025-11-13", "2025-11-20", "2025-12-04", "2025-12-18"}, days)
+
+	series, err := sessions.GetSessionByID(ctx, (*posted)[0].ID.String())
+	assert.NoError(t, err)
+	assert.NotNil(t, series.Repetition)
+	var exceptions []string
+	for _, d := range series.Repetition.ExceptionDates {
+		exceptions = append(exceptions, d.Format(time.DateOnly))
+	}
+	assert.Equal(t, []string{"2025-11-27", "2025-12-11"}, exceptions)
+}
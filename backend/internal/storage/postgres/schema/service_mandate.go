@@ -0,0 +1,190 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const serviceMandateColumns = `id, student_id, service, minutes, frequency, setting, start_date, end_date,
+	created_at, updated_at`
+
+type ServiceMandateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewServiceMandateRepository(db *pgxpool.Pool) *ServiceMandateRepository {
+	return &ServiceMandateRepository{db: db}
+}
+
+// GetMandates lists the student's mandates in the order they start
+func (r *ServiceMandateRepository) GetMandates(ctx context.Context, studentID uuid.UUID) ([]models.ServiceMandate, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT `+serviceMandateColumns+`
+	FROM service_mandate
+	WHERE student_id = $1
+	ORDER BY start_date, created_at`, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.ServiceMandate])
+}
+
+func (r *ServiceMandateRepository) GetMandate(ctx context.Context, studentID, id uuid.UUID) (*models.ServiceMandate, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT `+serviceMandateColumns+`
+	FROM service_mandate
+	WHERE id = $1 AND student_id = $2`, id, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	mandate, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.ServiceMandate])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Service mandate", "id", id.String())
+	}
+	return mandate, err
+}
+
+func (r *ServiceMandateRepository) CreateMandate(ctx context.Context, mandate *models.ServiceMandate) (*models.ServiceMandate, error) {
+	rows, err := r.db.Query(ctx, `
+	INSERT INTO service_mandate (student_id, service, minutes, frequency, setting, start_date, end_date)
+	SELECT id, $2, $3, $4, $5, $6::date, $7::date FROM student WHERE id = $1
+	RETURNING `+serviceMandateColumns,
+		mandate.StudentID, mandate.Service, mandate.Minutes, mandate.Frequency, mandate.Setting, mandate.StartDate, mandate.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.ServiceMandate])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Student", "id", mandate.StudentID.String())
+	}
+	return created, err
+}
+
+// UpdateMandate saves every field of the mandate but the student it is for
+func (r *ServiceMandateRepository) UpdateMandate(ctx context.Context, mandate *models.ServiceMandate) (*models.ServiceMandate, error) {
+	rows, err := r.db.Query(ctx, `
+	UPDATE service_mandate
+	SET service = $3, minutes = $4, frequency = $5, setting = $6, start_date = $7::date,
+		end_date = $8::date, updated_at = now()
+	WHERE id = $1 AND student_id = $2
+	RETURNING `+serviceMandateColumns,
+		mandate.ID, mandate.StudentID, mandate.Service, mandate.Minutes, mandate.Frequency, mandate.Setting, mandate.StartDate, mandate.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.ServiceMandate])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Service mandate", "id", mandate.ID.String())
+	}
+	return updated, err
+}
+
+func (r *ServiceMandateRepository) DeleteMandate(ctx context.Context, studentID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM service_mandate WHERE id = $1 AND student_id = $2`, id, studentID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.NotFound("Service mandate", "id", id.String())
+	}
+	return nil
+}
+
+// GetServiceRecords gathers, per student the query selects, the mandates in effect over
+// the query's dates and the sessions attended in the weeks and months those dates touch.
+// Sessions count once they have ended, unless they were cancelled or the student was
+// marked absent. Sessions a day either side are included for students whose therapist's
+// day differs from the UTC one.
+func (r *ServiceMandateRepository) GetServiceRecords(ctx context.Context, query *models.ServiceRecordQuery) ([]models.ServiceRecord, error) {
+	args := []any{query.From, query.To}
+	conditions := []string{}
+	if query.StudentID != nil {
+		args = append(args, *query.StudentID)
+		conditions = append(conditions, fmt.Sprintf("st.id = $%d", len(args)))
+	} else {
+		conditions = append(conditions, `st.grade != -1`, `EXISTS (
+			SELECT 1 FROM service_mandate m
+			WHERE m.student_id = st.id AND m.start_date <= $2::date AND m.end_date >= $1::date
+		)`)
+	}
+	if query.TherapistID != nil {
+		args = append(args, *query.TherapistID)
+		conditions = append(conditions, fmt.Sprintf("st.therapist_id = $%d", len(args)))
+	}
+
+	rows, err := r.db.Query(ctx, `
+	SELECT st.id AS student_id, st.first_name, st.last_name, st.therapist_id, th.time_zone
+	FROM student st
+	JOIN therapist th ON th.id = st.therapist_id
+	WHERE $1::date <= $2::date AND `+strings.Join(conditions, " AND ")+`
+	ORDER BY st.first_name, st.last_name, st.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	records, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.ServiceRecord])
+	if err != nil || len(records) == 0 {
+		return records, err
+	}
+
+	studentIDs := make([]uuid.UUID, len(records))
+	byStudent := make(map[uuid.UUID]*models.ServiceRecord, len(records))
+	for i := range records {
+		studentIDs[i] = records[i].StudentID
+		byStudent[records[i].StudentID] = &records[i]
+	}
+
+	rows, err = r.db.Query(ctx, `
+	SELECT `+serviceMandateColumns+`
+	FROM service_mandate
+	WHERE student_id = ANY($1) AND start_date <= $3::date AND end_date >= $2::date
+	ORDER BY start_date, created_at`, studentIDs, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	mandates, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.ServiceMandate])
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mandates {
+		rec := byStudent[m.StudentID]
+		rec.Mandates = append(rec.Mandates, m)
+	}
+
+	rows, err = r.db.Query(ctx, `
+	SELECT ss.student_id, s.id AS session_id, s.start_datetime, s.end_datetime,
+		(SELECT COUNT(*) FROM session_student g WHERE g.session_id = s.id) AS group_size
+	FROM session_student ss
+	JOIN session s ON s.id = ss.session_id
+	WHERE ss.student_id = ANY($1)
+	  AND ss.present = true
+	  AND s.status NOT IN `+missedStatuses+`
+	  AND s.end_datetime <= now()
+	  AND s.start_datetime >= LEAST(date_trunc('month', $2::date), $2::date - 6) - interval '1 day'
+	  AND s.start_datetime < GREATEST(date_trunc('month', $3::date) + interval '1 month', $3::date + 7) + interval '1 day'
+	ORDER BY s.start_datetime`, studentIDs, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.ServiceDelivery])
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deliveries {
+		rec := byStudent[d.StudentID]
+		rec.Deliveries = append(rec.Deliveries, d)
+	}
+
+	return records, nil
+}
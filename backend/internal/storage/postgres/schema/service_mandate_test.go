@@ -0,0 +1,186 @@
+package schema_test
+
+import (
+	"context"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+// createAttendedSession adds an hour long session with the students, the first of them
+// marked present as given and the rest present
+func createAttendedSession(t *testing.T, db *pgxpool.Pool, ctx context.Context, therapistID uuid.UUID, start time.Time, status string, present bool, studentIDs ...uuid.UUID) uuid.UUID {
+	parentID, sessionID := uuid.New(), uuid.New()
+	_, err := db.Exec(ctx, `
+		INSERT INTO session_parent (id, start_date, end_date, therapist_id) VALUES ($1, $2::date, $2::date, $3)`,
+		parentID, start, therapistID)
+	assert.NoError(t, err)
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO session (id, session_name, start_datetime, end_datetime, session_parent_id, status)
+		VALUES ($1, 'Speech', $2, $3, $4, $5)`, sessionID, start, start.Add(time.Hour), parentID, status)
+	assert.NoError(t, err)
+
+	for i, studentID := range studentIDs {
+		_, err = db.Exec(ctx, `
+			INSERT INTO session_student (session_id, student_id, present) VALUES ($1, $2, $3)`,
+			sessionID, studentID, present || i > 0)
+		assert.NoError(t, err)
+	}
+	return sessionID
+}
+
+func TestServiceMandateRepository_Mandates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewServiceMandateRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Mandate")
+	studentID := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Ada", 3)
+
+	speech, err := repo.CreateMandate(ctx, &models.ServiceMandate{
+		StudentID: studentID,
+		Service:   "Speech-language therapy",
+		Minutes:   60,
+		Frequency: models.MandateWeekly,
+		Setting:   models.MandateIndividual,
+		StartDate: date(2025, 9, 1),
+		EndDate:   date(2026, 6, 30),
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, speech.ID)
+
+	_, err = repo.CreateMandate(ctx, &models.ServiceMandate{
+		StudentID: uuid.New(),
+		Service:   "Speech-language therapy",
+		Minutes:   60,
+		Frequency: models.MandateWeekly,
+		Setting:   models.MandateIndividual,
+		StartDate: date(2025, 9, 1),
+		EndDate:   date(2026, 6, 30),
+	})
+	var httpErr errs.HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+
+	speech.Minutes = 90
+	speech.Setting = models.MandateGroup
+	updated, err := repo.UpdateMandate(ctx, speech)
+	assert.NoError(t, err)
+	assert.Equal(t, 90, updated.Minutes)
+	assert.Equal(t, models.MandateGroup, updated.Setting)
+
+	// Scoped to the student
+	_, err = repo.GetMandate(ctx, uuid.New(), speech.ID)
+	assert.ErrorAs(t, err, &httpErr)
+
+	mandates, err := repo.GetMandates(ctx, studentID)
+	assert.NoError(t, err)
+	assert.Len(t, mandates, 1)
+
+	assert.NoError(t, repo.DeleteMandate(ctx, studentID, speech.ID))
+	assert.ErrorAs(t, repo.DeleteMandate(ctx, studentID, speech.ID), &httpErr)
+}
+
+func TestServiceMandateRepository_GetServiceRecords(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewServiceMandateRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Compliance")
+	_, err := testDB.Exec(ctx, `UPDATE therapist SET time_zone = 'America/New_York' WHERE id = $1`, therapistID)
+	assert.NoError(t, err)
+
+	ada := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Ada", 3)
+	grace := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Grace", 3)
+	// No mandate, left out of the therapist's report
+	alan := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Alan", 3)
+
+	_, err = repo.CreateMandate(ctx, &models.ServiceMandate{
+		StudentID: ada,
+		Service:   "Speech-language therapy",
+		Minutes:   60,
+		Frequency: models.MandateWeekly,
+		Setting:   models.MandateIndividual,
+		StartDate: date(2025, 9, 1),
+		EndDate:   date(2026, 6, 30),
+	})
+	assert.NoError(t, err)
+	// Ended before the report
+	_, err = repo.CreateMandate(ctx, &models.ServiceMandate{
+		StudentID: grace,
+		Service:   "Occupational therapy",
+		Minutes:   30,
+		Frequency: models.MandateMonthly,
+		Setting:   models.MandateGroup,
+		StartDate: date(2024, 9, 1),
+		EndDate:   date(2025, 6, 30),
+	})
+	assert.NoError(t, err)
+	_, err = repo.CreateMandate(ctx, &models.ServiceMandate{
+		StudentID: grace,
+		Service:   "Social skills",
+		Minutes:   120,
+		Frequency: models.MandateMonthly,
+		Setting:   models.MandateGroup,
+		StartDate: date(2025, 9, 1),
+		EndDate:   date(2026, 6, 30),
+	})
+	assert.NoError(t, err)
+
+	at := func(day, hour int) time.Time { return time.Date(2025, 9, day, hour, 0, 0, 0, time.UTC) }
+	attended := createAttendedSession(t, testDB, ctx, therapistID, at(2, 14), models.SessionCompleted, true, ada)
+	group := createAttendedSession(t, testDB, ctx, therapistID, at(3, 14), models.SessionScheduled, true, grace, ada)
+	createAttendedSession(t, testDB, ctx, therapistID, at(4, 14), models.SessionCompleted, false, ada)
+	createAttendedSession(t, testDB, ctx, therapistID, at(5, 14), models.SessionCancelledBySchool, true, ada)
+	// In October, past the range even a day either side
+	createAttendedSession(t, testDB, ctx, therapistID, at(20, 14).AddDate(0, 1, 0), models.SessionCompleted, true, ada, alan)
+
+	records, err := repo.GetServiceRecords(ctx, &models.ServiceRecordQuery{
+		TherapistID: &therapistID,
+		From:        date(2025, 9, 1),
+		To:          date(2025, 9, 30),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	assert.Equal(t, ada, records[0].StudentID)
+	assert.Equal(t, "America/New_York", records[0].TimeZone)
+	assert.Len(t, records[0].Mandates, 1)
+	if assert.Len(t, records[0].Deliveries, 2) {
+		assert.Equal(t, attended, records[0].Deliveries[0].SessionID)
+		assert.Equal(t, models.MandateIndividual, records[0].Deliveries[0].Setting())
+		assert.Equal(t, group, records[0].Deliveries[1].SessionID)
+		assert.Equal(t, 2, records[0].Deliveries[1].GroupSize)
+	}
+
+	assert.Equal(t, grace, records[1].StudentID)
+	assert.Len(t, records[1].Mandates, 1)
+	assert.Len(t, records[1].Deliveries, 1)
+
+	// A single student is reported on without a mandate
+	records, err = repo.GetServiceRecords(ctx, &models.ServiceRecordQuery{
+		StudentID: &alan,
+		From:      date(2025, 10, 1),
+		To:        date(2025, 10, 31),
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, records, 1) {
+		assert.Empty(t, records[0].Mandates)
+		assert.Len(t, records[0].Deliveries, 1)
+	}
+}
@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const sessionNoteColumns = `n.id, n.session_student_id, ss.session_id, ss.student_id, n.subjective, n.objective,
+	n.assessment, n.plan, n.custom_fields, n.status, n.signed_at, n.signed_by, n.created_at, n.updated_at`
+
+type SessionNoteRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionNoteRepository(db *pgxpool.Pool) *SessionNoteRepository {
+	return &SessionNoteRepository{db: db}
+}
+
+func (r *SessionNoteRepository) GetNote(ctx context.Context, sessionID, studentID uuid.UUID) (*models.SessionNote, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT `+sessionNoteColumns+`
+	FROM session_note n
+	JOIN session_student ss ON ss.id = n.session_student_id
+	WHERE ss.session_id = $1 AND ss.student_id = $2`, sessionID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.SessionNote])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Session note not found")
+	}
+	return note, err
+}
+
+// SaveNote creates the student's note for the session, or replaces the draft saved before
+func (r *SessionNoteRepository) SaveNote(ctx context.Context, sessionID, studentID uuid.UUID, input *models.SaveSessionNoteInput) (*models.SessionNote, error) {
+	customFields := input.CustomFields
+	if customFields == nil {
+		customFields = map[string]string{}
+	}
+
+	rows, err := r.db.Query(ctx, `
+	WITH n AS (
+		INSERT INTO session_note (session_student_id, subjective, objective, assessment, plan, custom_fields)
+		SELECT id, $3, $4, $5, $6, $7 FROM session_student WHERE session_id = $1 AND student_id = $2
+		ON CONFLICT (session_student_id) DO UPDATE
+		SET subjective = EXCLUDED.subjective, objective = EXCLUDED.objective, assessment = EXCLUDED.assessment,
+		    plan = EXCLUDED.plan, custom_fields = EXCLUDED.custom_fields, updated_at = now()
+		WHERE session_note.status = 'draft'
+		RETURNING *
+	)
+	SELECT `+sessionNoteColumns+`
+	FROM n
+	JOIN session_student ss ON ss.id = n.session_student_id`,
+		sessionID, studentID, input.Subjective, input.Objective, input.Assessment, input.Plan, customFields)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.SessionNote])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.noteNotChanged(ctx, sessionID, studentID)
+	}
+	return note, err
+}
+
+// SignNote locks the draft note, recording who signed it and when
+func (r *SessionNoteRepository) SignNote(ctx context.Context, sessionID, studentID, signedBy uuid.UUID) (*models.SessionNote, error) {
+	rows, err := r.db.Query(ctx, `
+	WITH n AS (
+		UPDATE session_note
+		SET status = 'signed', signed_at = now(), signed_by = $3, updated_at = now()
+		WHERE status = 'draft'
+		  AND session_student_id = (SELECT id FROM session_student WHERE session_id = $1 AND student_id = $2)
+		RETURNING *
+	)
+	SELECT `+sessionNoteColumns+`
+	FROM n
+	JOIN session_student ss ON ss.id = n.session_student_id`, sessionID, studentID, signedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.SessionNote])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.noteNotChanged(ctx, sessionID, studentID)
+	}
+	return note, err
+}
+
+// DeleteNote discards a draft note
+func (r *SessionNoteRepository) DeleteNote(ctx context.Context, sessionID, studentID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+	DELETE FROM session_note
+	WHERE status = 'draft'
+	  AND session_student_id = (SELECT id FROM session_student WHERE session_id = $1 AND student_id = $2)`,
+		sessionID, studentID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.noteNotChanged(ctx, sessionID, studentID)
+	}
+	return nil
+}
+
+// signedNoteError reports a delete that would have taken signed session notes with it,
+// which the database refuses
+func signedNoteError(err error, message string) error {
+	if err != nil && strings.Contains(err.Error(), "23001") {
+		return errs.Conflict(message)
+	}
+	return err
+}
+
+// noteNotChanged explains why a note was left alone: the student is not in the session,
+// there is no note to change, or the note is signed
+func (r *SessionNoteRepository) noteNotChanged(ctx context.Context, sessionID, studentID uuid.UUID) error {
+	var inSession bool
+	var status *string
+	err := r.db.QueryRow(ctx, `
+	SELECT EXISTS (SELECT 1 FROM session_student WHERE session_id = $1 AND student_id = $2),
+	       (SELECT n.status FROM session_note n
+	        JOIN session_student ss ON ss.id = n.session_student_id
+	        WHERE ss.session_id = $1 AND ss.student_id = $2)`, sessionID, studentID).Scan(&inSession, &status)
+	switch {
+	case err != nil:
+		return err
+	case !inSession:
+		return errs.NotFound("Student is not in this session")
+	case status == nil:
+		return errs.NotFound("Session note not found")
+	default:
+		return errs.Conflict("A signed session note cannot be changed")
+	}
+}
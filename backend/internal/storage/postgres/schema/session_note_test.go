@@ -0,0 +1,124 @@
+package schema_test
+
+import (
+	"context"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionNoteRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionNoteRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Notes")
+	ada := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Ada", 3)
+	grace := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Grace", 3)
+	sessionID := createAttendedSession(t, testDB, ctx, therapistID, time.Date(2025, 9, 2, 14, 0, 0, 0, time.UTC), models.SessionCompleted, true, ada)
+
+	var httpErr errs.HTTPError
+	_, err := repo.GetNote(ctx, sessionID, ada)
+	assert.ErrorAs(t, err, &httpErr)
+
+	// Grace is not in the session
+	_, err = repo.SaveNote(ctx, sessionID, grace, &models.SaveSessionNoteInput{Plan: ptrString("Move to phrases")})
+	assert.ErrorAs(t, err, &httpErr)
+
+	note, err := repo.SaveNote(ctx, sessionID, ada, &models.SaveSessionNoteInput{
+		Subjective:   ptrString("Reported practicing /r/ at home"),
+		Objective:    ptrString("80% accuracy on initial /r/ in words"),
+		CustomFields: map[string]string{"Parent contact": "Called mom"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.NoteDraft, note.Status)
+	assert.Equal(t, sessionID, note.SessionID)
+	assert.Equal(t, ada, note.StudentID)
+
+	// Saving again replaces the draft
+	note, err = repo.SaveNote(ctx, sessionID, ada, &models.SaveSessionNoteInput{
+		Subjective: ptrString("Reported practicing /r/ at home"),
+		Plan:       ptrString("Move to phrases"),
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, note.Objective)
+	assert.Equal(t, "Move to phrases", *note.Plan)
+	assert.Empty(t, note.CustomFields)
+
+	signed, err := repo.SignNote(ctx, sessionID, ada, therapistID)
+	assert.NoError(t, err)
+	assert.Equal(t, note.ID, signed.ID)
+	assert.Equal(t, models.NoteSigned, signed.Status)
+	assert.Equal(t, &therapistID, signed.SignedBy)
+	assert.NotNil(t, signed.SignedAt)
+
+	// Locked once signed
+	_, err = repo.SaveNote(ctx, sessionID, ada, &models.SaveSessionNoteInput{Plan: ptrString("Changed")})
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, 409, httpErr.Code)
+	}
+	_, err = repo.SignNote(ctx, sessionID, ada, uuid.New())
+	assert.ErrorAs(t, err, &httpErr)
+	assert.ErrorAs(t, repo.DeleteNote(ctx, sessionID, ada), &httpErr)
+
+	_, err = testDB.Exec(ctx, `UPDATE session_note SET plan = 'Changed' WHERE id = $1`, note.ID)
+	assert.Error(t, err)
+
+	got, err := repo.GetNote(ctx, sessionID, ada)
+	assert.NoError(t, err)
+	assert.Equal(t, "Move to phrases", *got.Plan)
+}
+
+func TestSessionNoteRepository_SignedNotesOutliveDeletes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionNoteRepository(testDB)
+	sessions := schema.NewSessionRepository(testDB)
+	sessionStudents := schema.NewSessionStudentRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Records")
+	ada := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Ada", 3)
+	signedID := createAttendedSession(t, testDB, ctx, therapistID, time.Date(2025, 9, 2, 14, 0, 0, 0, time.UTC), models.SessionCompleted, true, ada)
+	draftID := createAttendedSession(t, testDB, ctx, therapistID, time.Date(2025, 9, 9, 14, 0, 0, 0, time.UTC), models.SessionCompleted, true, ada)
+
+	_, err := repo.SaveNote(ctx, signedID, ada, &models.SaveSessionNoteInput{Plan: ptrString("Move to phrases")})
+	assert.NoError(t, err)
+	_, err = repo.SignNote(ctx, signedID, ada, therapistID)
+	assert.NoError(t, err)
+	_, err = repo.SaveNote(ctx, draftID, ada, &models.SaveSessionNoteInput{Plan: ptrString("Not sure yet")})
+	assert.NoError(t, err)
+
+	var httpErr errs.HTTPError
+	err = sessionStudents.DeleteSessionStudent(ctx, &models.DeleteSessionStudentInput{SessionID: signedID, StudentID: ada})
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, 409, httpErr.Code)
+	}
+	err = sessions.DeleteSession(ctx, signedID)
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, 409, httpErr.Code)
+	}
+
+	got, err := repo.GetNote(ctx, signedID, ada)
+	assert.NoError(t, err)
+	assert.Equal(t, models.NoteSigned, got.Status)
+
+	// Drafts still go with their session
+	assert.NoError(t, sessions.DeleteSession(ctx, draftID))
+	var drafts int
+	assert.NoError(t, testDB.QueryRow(ctx, `SELECT COUNT(*) FROM session_note WHERE status = 'draft'`).Scan(&drafts))
+	assert.Equal(t, 0, drafts)
+}
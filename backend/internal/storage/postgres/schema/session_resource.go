@@ -3,6 +3,7 @@ package schema
 import (
 	"context"
 	"specialstandard/internal/models"
+	"specialstandard/internal/storage/dbinterface"
 	"specialstandard/internal/utils"
 
 	"github.com/google/uuid"
@@ -27,6 +28,19 @@ func (sr *SessionResourceRepository) PostSessionResource(ctx context.Context, se
 	return &newSessionResource, nil
 }
 
+// AddSessionResources links every resource to every session, leaving links that exist alone
+func (sr *SessionResourceRepository) AddSessionResources(ctx context.Context, q dbinterface.Queryable, sessionIDs, resourceIDs []uuid.UUID) error {
+	if len(sessionIDs) == 0 || len(resourceIDs) == 0 {
+		return nil
+	}
+
+	_, err := q.Exec(ctx, `
+	INSERT INTO session_resource (session_id, resource_id)
+	SELECT s, r FROM unnest($1::uuid[]) s CROSS JOIN unnest($2::uuid[]) r
+	ON CONFLICT (session_id, resource_id) DO NOTHING`, sessionIDs, resourceIDs)
+	return err
+}
+
 func (sr *SessionResourceRepository) DeleteSessionResource(ctx context.Context, sessionResource models.DeleteSessionResource) error {
 	query := `DELETE FROM session_resource WHERE session_id = $1 AND resource_id = $2`
 	_, err := sr.db.Exec(ctx, query, sessionResource.SessionID, sessionResource.ResourceID)
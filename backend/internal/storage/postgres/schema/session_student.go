@@ -3,9 +3,12 @@ package schema
 import (
 	"context"
 	"fmt"
+	"slices"
+	"specialstandard/internal/errs"
 	"specialstandard/internal/models"
 	"specialstandard/internal/storage/dbinterface"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -52,16 +55,103 @@ func (r *SessionStudentRepository) CreateSessionStudent(ctx context.Context, q d
 		return nil, err
 	}
 
+	// Students added to a cancelled session are owed it too, and to a makeup session make up for it
+	if err := syncMakeupLedger(ctx, q, input.SessionIDs); err != nil {
+		return nil, err
+	}
+
 	return &sessionStudents, nil
 }
 
+// FindStudentConflicts lists the sessions the students are already in at the time of one
+// of the given sessions. Cancelled sessions do not take up the time, and names of other
+// therapists' sessions are left out.
+func (r *SessionStudentRepository) FindStudentConflicts(ctx context.Context, sessionIDs, studentIDs []uuid.UUID) ([]models.SessionConflict, error) {
+	query := `
+	SELECT DISTINCT ON (s.start_datetime, s.id)
+	       s.id,
+	       CASE WHEN sp.therapist_id = tsp.therapist_id THEN s.session_name ELSE '' END,
+	       s.start_datetime, s.end_datetime, sp.therapist_id,
+	       ARRAY(
+	           SELECT ss.student_id FROM session_student ss
+	           WHERE ss.session_id = s.id AND ss.student_id = ANY($2)
+	           ORDER BY ss.student_id
+	       )
+	FROM session t
+	INNER JOIN session_parent tsp ON t.session_parent_id = tsp.id
+	INNER JOIN session s ON s.start_datetime < t.end_datetime
+	    AND s.end_datetime > t.start_datetime
+	    AND NOT (s.id = ANY($1))
+	INNER JOIN session_parent sp ON s.session_parent_id = sp.id
+	WHERE t.id = ANY($1)
+	  AND s.status NOT IN ` + cancelledStatuses + `
+	  AND EXISTS (SELECT 1 FROM session_student ss WHERE ss.session_id = s.id AND ss.student_id = ANY($2))
+	ORDER BY s.start_datetime ASC, s.id`
+
+	rows, err := r.db.Query(ctx, query, sessionIDs, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conflicts := []models.SessionConflict{}
+	for rows.Next() {
+		var c models.SessionConflict
+		if err := rows.Scan(&c.SessionID, &c.SessionName, &c.StartDateTime, &c.EndDateTime, &c.TherapistID, &c.StudentIDs); err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, c)
+	}
+
+	return conflicts, rows.Err()
+}
+
+// DeleteSessionStudent removes the student from the session, together with what they were
+// owed for it
 func (r *SessionStudentRepository) DeleteSessionStudent(ctx context.Context, input *models.DeleteSessionStudentInput) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
 	query := `DELETE FROM session_student WHERE session_id = $1 AND student_id = $2`
-	_, err := r.db.Exec(ctx, query, input.SessionID, input.StudentID)
-	return err
+	if _, err := tx.Exec(ctx, query, input.SessionID, input.StudentID); err != nil {
+		return signedNoteError(err, "The student has a signed note for the session and cannot be removed")
+	}
+
+	if err := syncMakeupLedger(ctx, tx, []uuid.UUID{input.SessionID}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
+// PatchSessionStudent records the student's attendance and notes for the session, and what
+// they are owed for it
 func (r *SessionStudentRepository) PatchSessionStudent(ctx context.Context, input *models.PatchSessionStudentInput) (*models.SessionStudent, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	sessionStudent, err := patchSessionStudent(ctx, tx, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return sessionStudent, nil
+}
+
+func patchSessionStudent(ctx context.Context, q dbinterface.Queryable, input *models.PatchSessionStudentInput) (*models.SessionStudent, error) {
 	sessionStudent := &models.SessionStudent{}
 
 	query := `UPDATE session_student
@@ -71,7 +161,7 @@ func (r *SessionStudentRepository) PatchSessionStudent(ctx context.Context, inpu
 				WHERE session_id = $3 AND student_id = $4
 				RETURNING id, session_id, student_id, present, notes, created_at, updated_at`
 
-	row := r.db.QueryRow(ctx, query, input.Present, input.Notes, input.SessionID, input.StudentID)
+	row := q.QueryRow(ctx, query, input.Present, input.Notes, input.SessionID, input.StudentID)
 
 	if err := row.Scan(
 		&sessionStudent.ID,
@@ -85,6 +175,13 @@ func (r *SessionStudentRepository) PatchSessionStudent(ctx context.Context, inpu
 		return nil, err
 	}
 
+	// A student marked absent is owed the session
+	if input.Present != nil {
+		if err := syncMakeupLedger(ctx, q, []uuid.UUID{input.SessionID}); err != nil {
+			return nil, err
+		}
+	}
+
 	return sessionStudent, nil
 }
 
@@ -141,6 +238,124 @@ func (r *SessionStudentRepository) RateStudentSession(ctx context.Context, input
 	return sessionStudent, ratings, nil
 }
 
+// SaveAttendance records the attendance, notes and ratings of the session's students in
+// one transaction, marks the session completed once it has started, and returns the
+// session's students as they now are
+func (r *SessionStudentRepository) SaveAttendance(ctx context.Context, sessionID uuid.UUID, input *models.SessionAttendanceInput) ([]models.SessionStudentsOutput, error) {
+	studentIDs := make([]uuid.UUID, len(input.Students))
+	present := make([]bool, len(input.Students))
+	notes := make([]*string, len(input.Students))
+	var rated []uuid.UUID
+	var ratingStudentIDs []uuid.UUID
+	var categories, levels, descriptions []string
+	for i, s := range input.Students {
+		studentIDs[i], present[i], notes[i] = s.StudentID, *s.Present, s.Notes
+		if s.Ratings == nil {
+			continue
+		}
+		rated = append(rated, s.StudentID)
+		for _, rating := range *s.Ratings {
+			ratingStudentIDs = append(ratingStudentIDs, s.StudentID)
+			categories = append(categories, rating.Category)
+			levels = append(levels, rating.Level)
+			descriptions = append(descriptions, rating.Description)
+		}
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rows, err := tx.Query(ctx, `
+	UPDATE session_student ss
+	SET present = a.present, notes = COALESCE(a.notes, ss.notes), updated_at = now()
+	FROM unnest($2::uuid[], $3::bool[], $4::text[]) AS a(student_id, present, notes)
+	WHERE ss.session_id = $1 AND ss.student_id = a.student_id
+	RETURNING ss.student_id`, sessionID, studentIDs, present, notes)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, err
+	}
+	if len(updated) < len(studentIDs) {
+		for _, id := range studentIDs {
+			if !slices.Contains(updated, id) {
+				return nil, errs.NotFound("Session student", "student_id", id.String())
+			}
+		}
+	}
+
+	if len(rated) > 0 {
+		_, err = tx.Exec(ctx, `
+		DELETE FROM session_rating sr
+		USING session_student ss
+		WHERE sr.session_student_id = ss.id
+		  AND ss.session_id = $1 AND ss.student_id = ANY($2)
+		  AND NOT EXISTS (
+			SELECT 1 FROM unnest($3::uuid[], $4::text[]) AS r(student_id, category)
+			WHERE r.student_id = ss.student_id AND r.category = sr.category::text
+		  )`, sessionID, rated, ratingStudentIDs, categories)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec(ctx, `
+		INSERT INTO session_rating (session_student_id, category, level, description)
+		SELECT ss.id, r.category::category, r.level::response_level, r.description
+		FROM unnest($2::uuid[], $3::text[], $4::text[], $5::text[]) AS r(student_id, category, level, description)
+		JOIN session_student ss ON ss.session_id = $1 AND ss.student_id = r.student_id
+		ON CONFLICT (session_student_id, category)
+		DO UPDATE SET
+			level = EXCLUDED.level,
+			description = EXCLUDED.description,
+			updated_at = NOW()`, sessionID, ratingStudentIDs, categories, levels, descriptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Taking attendance is what completes a session that has started. Missed sessions keep
+	// their status.
+	_, err = tx.Exec(ctx, `
+	UPDATE session
+	SET status = 'completed', updated_at = now()
+	WHERE id = $1 AND status IN ('scheduled', 'makeup') AND start_datetime <= now()`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Students marked absent are owed the session
+	if err := syncMakeupLedger(ctx, tx, []uuid.UUID{sessionID}); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, `
+	SELECT ss.id, ss.session_id, ss.present, ss.notes, ss.created_at, ss.updated_at,
+	       s.id, s.first_name, s.last_name, s.dob, s.therapist_id,
+	       s.grade, s.iep, s.created_at, s.updated_at,
+	       sr.level, sr.category, sr.description
+	FROM session_student ss
+	JOIN student s ON ss.student_id = s.id
+	LEFT JOIN session_rating sr ON ss.id = sr.session_student_id
+	WHERE ss.session_id = $1
+	ORDER BY s.first_name, s.last_name, s.id, sr.category`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := collectSessionStudents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return roster, tx.Commit(ctx)
+}
+
 func (r *SessionStudentRepository) GetStudentAttendance(ctx context.Context, params models.GetStudentAttendanceParams) (*int, *int, error) {
 	query := `
 		SELECT 
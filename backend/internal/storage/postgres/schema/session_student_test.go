@@ -2,6 +2,7 @@ package schema_test
 
 import (
 	"context"
+	"specialstandard/internal/errs"
 	"specialstandard/internal/models"
 	"specialstandard/internal/storage/postgres/schema"
 	"specialstandard/internal/storage/postgres/testutil"
@@ -147,6 +148,49 @@ func TestSessionStudentRepository_CreateSessionStudent(t *testing.T) {
 	assert.Nil(t, invalidResult)
 }
 
+func TestSessionStudentRepository_FindStudentConflicts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionStudentRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateTestTherapist(t, testDB, ctx)
+	otherID := CreateTestTherapist(t, testDB, ctx)
+	// Both start now, so they overlap
+	sessionID := CreateTestSession(t, testDB, ctx, therapistID, "Joining")
+	otherSessionID := CreateTestSession(t, testDB, ctx, otherID, "Already in")
+	studentID := CreateTestStudent(t, testDB, ctx, therapistID, "Busy")
+	freeStudentID := CreateTestStudent(t, testDB, ctx, therapistID, "Free")
+
+	_, err := repo.CreateSessionStudent(ctx, testDB, &models.CreateSessionStudentInput{
+		SessionIDs: []uuid.UUID{otherSessionID},
+		StudentIDs: []uuid.UUID{studentID},
+	})
+	require.NoError(t, err)
+
+	conflicts, err := repo.FindStudentConflicts(ctx, []uuid.UUID{sessionID}, []uuid.UUID{studentID, freeStudentID})
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, otherSessionID, conflicts[0].SessionID)
+	assert.Equal(t, otherID, conflicts[0].TherapistID)
+	assert.Empty(t, conflicts[0].SessionName)
+	assert.Equal(t, []uuid.UUID{studentID}, conflicts[0].StudentIDs)
+
+	conflicts, err = repo.FindStudentConflicts(ctx, []uuid.UUID{sessionID}, []uuid.UUID{freeStudentID})
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// The time of a cancelled session is free again
+	_, err = testDB.Exec(ctx, `UPDATE session SET status = $2 WHERE id = $1`, otherSessionID, models.SessionCancelledBySchool)
+	require.NoError(t, err)
+	conflicts, err = repo.FindStudentConflicts(ctx, []uuid.UUID{sessionID}, []uuid.UUID{studentID})
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
 func TestSessionStudentRepository_DeleteSessionStudent(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping database test in short mode")
@@ -547,3 +591,84 @@ func TestSessionStudentRepository_GetStudentAttendance(t *testing.T) {
 		assert.Equal(t, 1, *totalCount)   // only 10 days ago
 	})
 }
+
+func TestSessionStudentRepository_SaveAttendance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionStudentRepository(testDB)
+	students := schema.NewStudentRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Attendance")
+	ada := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Ada", 3)
+	grace := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Grace", 3)
+	alan := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Alan", 3)
+	sessionID := createAttendedSession(t, testDB, ctx, therapistID, time.Date(2025, 9, 2, 14, 0, 0, 0, time.UTC), models.SessionScheduled, true, ada, grace)
+
+	_, _, err := repo.RateStudentSession(ctx, &models.PatchSessionStudentInput{
+		SessionID: sessionID,
+		StudentID: ada,
+		Notes:     ptrString("Initial notes"),
+		Ratings: &[]models.RateInput{
+			{Category: "engagement", Level: "low", Description: "Tired"},
+			{Category: "verbal_cue", Level: "moderate", Description: "Some prompting"},
+		},
+	})
+	require.NoError(t, err)
+
+	roster, err := repo.SaveAttendance(ctx, sessionID, &models.SessionAttendanceInput{
+		Students: []models.StudentAttendanceInput{
+			{StudentID: ada, Present: ptrBool(true), Ratings: &[]models.RateInput{
+				{Category: "engagement", Level: "high", Description: "Focused"},
+				{Category: "visual_cue", Level: "minimal", Description: "One prompt"},
+			}},
+			{StudentID: grace, Present: ptrBool(false), Notes: ptrString("Out sick")},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, roster, 2)
+
+	// By name
+	assert.Equal(t, ada, roster[0].Student.ID)
+	assert.True(t, roster[0].Present)
+	assert.Equal(t, "Initial notes", *roster[0].Notes)
+	assert.Len(t, roster[0].Ratings, 2)
+	for _, rating := range roster[0].Ratings {
+		assert.NotEqual(t, "verbal_cue", *rating.Category)
+		if *rating.Category == "engagement" {
+			assert.Equal(t, "high", *rating.Level)
+		}
+	}
+
+	assert.Equal(t, grace, roster[1].Student.ID)
+	assert.False(t, roster[1].Present)
+	assert.Equal(t, "Out sick", *roster[1].Notes)
+	assert.Empty(t, roster[1].Ratings)
+
+	// Taking attendance completed the session
+	var status string
+	require.NoError(t, testDB.QueryRow(ctx, `SELECT status FROM session WHERE id = $1`, sessionID).Scan(&status))
+	assert.Equal(t, models.SessionCompleted, status)
+
+	// The absent student is owed the session
+	ledger, err := students.GetMakeupLedger(ctx, grace)
+	require.NoError(t, err)
+	assert.Len(t, ledger, 1)
+
+	// A student not in the session fails the whole request
+	_, err = repo.SaveAttendance(ctx, sessionID, &models.SessionAttendanceInput{
+		Students: []models.StudentAttendanceInput{
+			{StudentID: grace, Present: ptrBool(true)},
+			{StudentID: alan, Present: ptrBool(true)},
+		},
+	})
+	var httpErr errs.HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+
+	ledger, err = students.GetMakeupLedger(ctx, grace)
+	require.NoError(t, err)
+	assert.Len(t, ledger, 1)
+}
@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/dbinterface"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const sessionTemplateColumns = `t.id, t.therapist_id, t.name, t.duration_minutes, t.location, t.game_content_filter,
+	t.created_at, t.updated_at,
+	COALESCE((SELECT array_agg(r.resource_id ORDER BY r.position) FROM session_template_resource r
+	          WHERE r.session_template_id = t.id), '{}') AS resource_ids`
+
+type SessionTemplateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionTemplateRepository(db *pgxpool.Pool) *SessionTemplateRepository {
+	return &SessionTemplateRepository{db: db}
+}
+
+// GetTemplates lists the therapist's templates by name
+func (r *SessionTemplateRepository) GetTemplates(ctx context.Context, therapistID uuid.UUID) ([]models.SessionTemplate, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT `+sessionTemplateColumns+`
+	FROM session_template t
+	WHERE t.therapist_id = $1
+	ORDER BY t.name, t.created_at`, therapistID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.SessionTemplate])
+}
+
+func (r *SessionTemplateRepository) GetTemplate(ctx context.Context, id uuid.UUID) (*models.SessionTemplate, error) {
+	return getSessionTemplate(ctx, r.db, id)
+}
+
+func (r *SessionTemplateRepository) CreateTemplate(ctx context.Context, template *models.SessionTemplate) (*models.SessionTemplate, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+	INSERT INTO session_template (therapist_id, name, duration_minutes, location, game_content_filter)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id`,
+		template.TherapistID, template.Name, template.DurationMinutes, template.Location, template.GameContentFilter,
+	).Scan(&id)
+	if err != nil {
+		return nil, sessionTemplateError(err)
+	}
+	if err := setSessionTemplateResources(ctx, tx, id, template.ResourceIDs); err != nil {
+		return nil, err
+	}
+
+	created, err := getSessionTemplate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit(ctx)
+}
+
+// UpdateTemplate saves every field of the template but the therapist it belongs to
+func (r *SessionTemplateRepository) UpdateTemplate(ctx context.Context, template *models.SessionTemplate) (*models.SessionTemplate, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, `
+	UPDATE session_template
+	SET name = $2, duration_minutes = $3, location = $4, game_content_filter = $5, updated_at = now()
+	WHERE id = $1`,
+		template.ID, template.Name, template.DurationMinutes, template.Location, template.GameContentFilter)
+	if err != nil {
+		return nil, sessionTemplateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, errs.NotFound("Session template", "id", template.ID.String())
+	}
+	if err := setSessionTemplateResources(ctx, tx, template.ID, template.ResourceIDs); err != nil {
+		return nil, err
+	}
+
+	updated, err := getSessionTemplate(ctx, tx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit(ctx)
+}
+
+// DeleteTemplate removes the template. Sessions created from it keep their resources.
+func (r *SessionTemplateRepository) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM session_template WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.NotFound("Session template", "id", id.String())
+	}
+	return nil
+}
+
+func getSessionTemplate(ctx context.Context, q dbinterface.Queryable, id uuid.UUID) (*models.SessionTemplate, error) {
+	rows, err := q.Query(ctx, `
+	SELECT `+sessionTemplateColumns+`
+	FROM session_template t
+	WHERE t.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.SessionTemplate])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errs.NotFound("Session template", "id", id.String())
+	}
+	return template, err
+}
+
+// setSessionTemplateResources replaces the template's resources, keeping their order
+func setSessionTemplateResources(ctx context.Context, q dbinterface.Queryable, id uuid.UUID, resourceIDs []uuid.UUID) error {
+	if _, err := q.Exec(ctx, `DELETE FROM session_template_resource WHERE session_template_id = $1`, id); err != nil {
+		return err
+	}
+	if len(resourceIDs) == 0 {
+		return nil
+	}
+
+	_, err := q.Exec(ctx, `
+	INSERT INTO session_template_resource (session_template_id, resource_id, position)
+	SELECT $1, resource_id, position
+	FROM unnest($2::uuid[]) WITH ORDINALITY AS r(resource_id, position)`, id, resourceIDs)
+	return sessionTemplateError(err)
+}
+
+// sessionTemplateError tells apart the resources or therapist that do not exist
+func sessionTemplateError(err error) error {
+	if err != nil && strings.Contains(err.Error(), "23503") {
+		return errs.BadRequest("Resource or therapist does not exist")
+	}
+	return err
+}
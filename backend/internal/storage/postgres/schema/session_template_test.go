@@ -0,0 +1,125 @@
+package schema_test
+
+import (
+	"context"
+	"specialstandard/internal/errs"
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTemplateResource(t *testing.T, db *pgxpool.Pool, ctx context.Context, themeID uuid.UUID, title string) uuid.UUID {
+	id := uuid.New()
+	_, err := db.Exec(ctx, `
+		INSERT INTO resource (id, theme_id, grade_level, week, type, title, category, content)
+		VALUES ($1, $2, 3, 1, 'worksheet', $3, 'speech', $3)`, id, themeID, title)
+	assert.NoError(t, err)
+	return id
+}
+
+func TestSessionTemplateRepository_Templates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionTemplateRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateTestTherapist(t, testDB, ctx)
+	themeID := CreateTestTheme(t, testDB, ctx)
+	first := createTemplateResource(t, testDB, ctx, themeID, "Minimal pairs")
+	second := createTemplateResource(t, testDB, ctx, themeID, "Story map")
+
+	week := 2
+	location := "Room 12"
+	created, err := repo.CreateTemplate(ctx, &models.SessionTemplate{
+		TherapistID:     therapistID,
+		Name:            "Articulation",
+		DurationMinutes: 45,
+		Location:        &location,
+		ResourceIDs:     []uuid.UUID{second, first},
+		GameContentFilter: models.GameContentFilter{
+			ThemeID:             &themeID,
+			ThemeWeek:           &week,
+			ApplicableGameTypes: []string{"flashcards"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, created.ID)
+	// In the order given
+	assert.Equal(t, []uuid.UUID{second, first}, created.ResourceIDs)
+	assert.Equal(t, &week, created.GameContentFilter.ThemeWeek)
+	assert.Equal(t, []string{"flashcards"}, created.GameContentFilter.ApplicableGameTypes)
+
+	_, err = repo.CreateTemplate(ctx, &models.SessionTemplate{
+		TherapistID:     therapistID,
+		Name:            "Fluency",
+		DurationMinutes: 30,
+		ResourceIDs:     []uuid.UUID{uuid.New()},
+	})
+	var httpErr errs.HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+
+	created.ResourceIDs = []uuid.UUID{first}
+	created.DurationMinutes = 30
+	updated, err := repo.UpdateTemplate(ctx, created)
+	assert.NoError(t, err)
+	assert.Equal(t, 30, updated.DurationMinutes)
+	assert.Equal(t, []uuid.UUID{first}, updated.ResourceIDs)
+
+	templates, err := repo.GetTemplates(ctx, therapistID)
+	assert.NoError(t, err)
+	assert.Len(t, templates, 1)
+
+	// Sessions created from the template outlive it
+	sessionID := createAttendedSession(t, testDB, ctx, therapistID, time.Date(2025, 9, 2, 15, 0, 0, 0, time.UTC), models.SessionScheduled, true)
+	_, err = testDB.Exec(ctx, `UPDATE session SET session_template_id = $1 WHERE id = $2`, created.ID, sessionID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.DeleteTemplate(ctx, created.ID))
+	assert.ErrorAs(t, repo.DeleteTemplate(ctx, created.ID), &httpErr)
+	_, err = repo.GetTemplate(ctx, created.ID)
+	assert.ErrorAs(t, err, &httpErr)
+
+	var templateID *uuid.UUID
+	assert.NoError(t, testDB.QueryRow(ctx, `SELECT session_template_id FROM session WHERE id = $1`, sessionID).Scan(&templateID))
+	assert.Nil(t, templateID)
+}
+
+func TestSessionResourceRepository_AddSessionResources(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionResourceRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateTestTherapist(t, testDB, ctx)
+	themeID := CreateTestTheme(t, testDB, ctx)
+	first := createTemplateResource(t, testDB, ctx, themeID, "Minimal pairs")
+	second := createTemplateResource(t, testDB, ctx, themeID, "Story map")
+
+	start := time.Date(2025, 9, 2, 15, 0, 0, 0, time.UTC)
+	monday := createAttendedSession(t, testDB, ctx, therapistID, start, models.SessionScheduled, true)
+	tuesday := createAttendedSession(t, testDB, ctx, therapistID, start.AddDate(0, 0, 1), models.SessionScheduled, true)
+
+	_, err := testDB.Exec(ctx, `INSERT INTO session_resource (session_id, resource_id) VALUES ($1, $2)`, monday, first)
+	assert.NoError(t, err)
+
+	// Links already there are kept
+	assert.NoError(t, repo.AddSessionResources(ctx, testDB, []uuid.UUID{monday, tuesday}, []uuid.UUID{first, second}))
+
+	var count int
+	assert.NoError(t, testDB.QueryRow(ctx, `SELECT COUNT(*) FROM session_resource WHERE session_id = ANY($1)`, []uuid.UUID{monday, tuesday}).Scan(&count))
+	assert.Equal(t, 4, count)
+
+	assert.NoError(t, repo.AddSessionResources(ctx, testDB, []uuid.UUID{monday}, nil))
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 )
@@ -389,6 +390,365 @@ func TestSessionRepository_PostSessions(t *testing.T) {
 	assert.Nil(t, invalidRepeatSessions)
 }
 
+func TestSessionRepository_PatchRecurringSessions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Series")
+	studentID := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Series", 3)
+
+	// Weekly series of four occurrences starting next week
+	y, m, d := time.Now().AddDate(0, 0, 7).Date()
+	startTime := time.Date(y, m, d, 10, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(time.Hour)
+	posted, err := repo.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Articulation group",
+		StartTime:   startTime,
+		EndTime:     endTime,
+		TherapistID: therapistID,
+		Location:    ptrString("Room 1"),
+		Repetition: &models.Repetition{
+			RecurStart:  startTime,
+			RecurEnd:    startTime.AddDate(0, 0, 27),
+			EveryNWeeks: 1,
+			Days:        []int{int(startTime.Weekday())},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *posted, 4)
+	series := *posted
+	for _, s := range series {
+		_, err := testDB.Exec(ctx, `INSERT INTO session_student (session_id, student_id) VALUES ($1, $2)`, s.ID, studentID)
+		assert.NoError(t, err)
+	}
+
+	// Unknown session
+	_, err = repo.PatchRecurringSessions(ctx, uuid.New(), models.SessionScopeFollowing, &models.PatchSessionInput{})
+	assert.Error(t, err)
+
+	// This and following splits the series at the third occurrence
+	updated, err := repo.PatchRecurringSessions(ctx, series[2].ID, models.SessionScopeFollowing, &models.PatchSessionInput{
+		Location: ptrString("Room 12"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *updated, 2)
+	for i, s := range *updated {
+		assert.Equal(t, "Room 12", *s.Location)
+		assert.Equal(t, "Articulation group", s.SessionName)
+		assert.True(t, s.StartDateTime.Equal(series[i+2].StartDateTime))
+		assert.NotEqual(t, series[0].SessionParentID, s.SessionParentID)
+		assert.NotNil(t, s.Repetition)
+
+		var enrolled int
+		err := testDB.QueryRow(ctx, `SELECT COUNT(*) FROM session_student WHERE session_id = $1 AND student_id = $2`, s.ID, studentID).Scan(&enrolled)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, enrolled)
+	}
+
+	var remaining int
+	err = testDB.QueryRow(ctx, `SELECT COUNT(*) FROM session WHERE session_parent_id = $1`, series[0].SessionParentID).Scan(&remaining)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, remaining)
+
+	first, err := repo.GetSessionByID(ctx, series[0].ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "Room 1", *first.Location)
+
+	// All regenerates what is left of the original series and drops its parent
+	updated, err = repo.PatchRecurringSessions(ctx, series[0].ID, models.SessionScopeAll, &models.PatchSessionInput{
+		Notes: ptrString("Bring worksheets"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *updated, 2)
+	for _, s := range *updated {
+		assert.Equal(t, "Bring worksheets", *s.Notes)
+	}
+
+	var parents int
+	err = testDB.QueryRow(ctx, `SELECT COUNT(*) FROM session_parent WHERE id = $1`, series[0].SessionParentID).Scan(&parents)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, parents)
+}
+
+func TestSessionRepository_PatchRecurringSessionsKeptOccurrences(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Kept")
+	regular := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Regular", 3)
+	dropIn := CreateSessionTestStudent(t, testDB, ctx, therapistID, "DropIn", 3)
+
+	// Weekly series of four occurrences starting next week
+	y, m, d := time.Now().AddDate(0, 0, 7).Date()
+	startTime := time.Date(y, m, d, 10, 0, 0, 0, time.UTC)
+	posted, err := repo.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Fluency group",
+		StartTime:   startTime,
+		EndTime:     startTime.Add(time.Hour),
+		TherapistID: therapistID,
+		Repetition: &models.Repetition{
+			RecurStart:  startTime,
+			RecurEnd:    startTime.AddDate(0, 0, 27),
+			EveryNWeeks: 1,
+			Days:        []int{int(startTime.Weekday())},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *posted, 4)
+	series := *posted
+
+	enrol := func(sessionID, studentID uuid.UUID) {
+		_, err := testDB.Exec(ctx, `INSERT INTO session_student (session_id, student_id) VALUES ($1, $2)`, sessionID, studentID)
+		assert.NoError(t, err)
+	}
+	for _, s := range series {
+		enrol(s.ID, regular)
+	}
+	enrol(series[3].ID, dropIn)
+
+	// The third occurrence is rated ahead of time, so the split keeps it
+	_, err = testDB.Exec(ctx, `
+		INSERT INTO session_rating (session_student_id, category, level)
+		SELECT id, 'engagement', 'high' FROM session_student WHERE session_id = $1`, series[2].ID)
+	assert.NoError(t, err)
+
+	updated, err := repo.PatchRecurringSessions(ctx, series[1].ID, models.SessionScopeFollowing, &models.PatchSessionInput{
+		Location: ptrString("Room 7"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *updated, 2, "the day of the kept occurrence is skipped")
+	assert.True(t, (*updated)[0].StartDateTime.Equal(series[1].StartDateTime))
+	assert.True(t, (*updated)[1].StartDateTime.Equal(series[3].StartDateTime))
+	assert.Contains(t, (*updated)[0].Repetition.ExceptionDates, time.Date(
+		series[2].StartDateTime.Year(), series[2].StartDateTime.Month(), series[2].StartDateTime.Day(), 0, 0, 0, 0, time.UTC))
+
+	var sameDay int
+	err = testDB.QueryRow(ctx, `SELECT COUNT(*) FROM session s JOIN session_parent sp ON sp.id = s.session_parent_id
+		WHERE sp.therapist_id = $1 AND s.start_datetime = $2`, therapistID, series[2].StartDateTime).Scan(&sameDay)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sameDay)
+
+	// Each regenerated occurrence keeps the students of the one it replaced
+	students := func(sessionID uuid.UUID) []uuid.UUID {
+		rows, err := testDB.Query(ctx, `SELECT student_id FROM session_student WHERE session_id = $1`, sessionID)
+		assert.NoError(t, err)
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+		assert.NoError(t, err)
+		return ids
+	}
+	assert.ElementsMatch(t, []uuid.UUID{regular}, students((*updated)[0].ID))
+	assert.ElementsMatch(t, []uuid.UUID{regular, dropIn}, students((*updated)[1].ID))
+}
+
+func TestSessionRepository_SessionStatus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Status")
+
+	// Weekly series of four occurrences starting next week
+	y, m, d := time.Now().AddDate(0, 0, 7).Date()
+	startTime := time.Date(y, m, d, 10, 0, 0, 0, time.UTC)
+	posted, err := repo.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Language group",
+		StartTime:   startTime,
+		EndTime:     startTime.Add(time.Hour),
+		TherapistID: therapistID,
+		Repetition: &models.Repetition{
+			RecurStart:  startTime,
+			RecurEnd:    startTime.AddDate(0, 0, 27),
+			EveryNWeeks: 1,
+			Days:        []int{int(startTime.Weekday())},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *posted, 4)
+	series := *posted
+	for _, s := range series {
+		assert.Equal(t, models.SessionScheduled, s.Status)
+	}
+
+	cancelled, err := repo.UpdateSessionStatus(ctx, series[1].ID, models.SessionCancelledBySchool, ptrString("Assembly"))
+	assert.NoError(t, err)
+	assert.Equal(t, models.SessionCancelledBySchool, cancelled.Status)
+	assert.Equal(t, "Assembly", *cancelled.StatusReason)
+	assert.True(t, cancelled.Cancelled())
+
+	_, err = repo.UpdateSessionStatus(ctx, uuid.New(), models.SessionCancelledBySchool, nil)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	sessions, err := repo.GetSessions(ctx, utils.NewPagination(), &models.GetSessionRepositoryRequest{
+		Statuses: []string{models.SessionCancelledBySchool, models.SessionStudentAbsent},
+	}, therapistID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, series[1].ID, sessions[0].ID)
+
+	// The time of a cancelled session is free again
+	conflicts, err := repo.FindConflicts(ctx, &models.ConflictCheck{
+		TherapistID: therapistID,
+		Slots: []models.TimeSlot{
+			{Start: series[0].StartDateTime, End: series[0].EndDateTime},
+			{Start: series[1].StartDateTime, End: series[1].EndDateTime},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, series[0].ID, conflicts[0].SessionID)
+
+	// Rescheduling the series keeps the missed session, and skips its day
+	newStart := series[0].StartDateTime.Add(time.Hour)
+	newEnd := series[0].EndDateTime.Add(time.Hour)
+	updated, err := repo.PatchRecurringSessions(ctx, series[0].ID, models.SessionScopeFollowing, &models.PatchSessionInput{
+		StartTime: &newStart,
+		EndTime:   &newEnd,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *updated, 3)
+	for _, s := range *updated {
+		assert.Equal(t, models.SessionScheduled, s.Status)
+		assert.Equal(t, 11, s.StartDateTime.UTC().Hour())
+		assert.NotEqual(t, series[1].StartDateTime.UTC().Format(time.DateOnly), s.StartDateTime.UTC().Format(time.DateOnly))
+	}
+
+	kept, err := repo.GetSessionByID(ctx, series[1].ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, models.SessionCancelledBySchool, kept.Status)
+	assert.True(t, kept.StartDateTime.Equal(series[1].StartDateTime))
+}
+
+func TestSessionRepository_RRule(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Rule")
+
+	// Second and fourth Wednesdays, six times, from next month
+	y, m, _ := time.Now().AddDate(0, 1, 0).Date()
+	recurStart := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	startTime := time.Date(y, m, 1, 13, 0, 0, 0, time.UTC)
+	rule := "FREQ=MONTHLY;BYDAY=2WE,4WE;COUNT=6"
+	posted, err := repo.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Social skills",
+		StartTime:   startTime,
+		EndTime:     startTime.Add(45 * time.Minute),
+		TherapistID: therapistID,
+		Repetition: &models.Repetition{
+			RecurStart: recurStart,
+			RRule:      &rule,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *posted, 6)
+	series := *posted
+	for _, s := range series {
+		start := s.StartDateTime.UTC()
+		assert.Equal(t, time.Wednesday, start.Weekday())
+		assert.True(t, (start.Day() >= 8 && start.Day() <= 14) || (start.Day() >= 22 && start.Day() <= 28))
+		assert.Equal(t, 13, start.Hour())
+	}
+
+	// The series ends on its last occurrence, and keeps its rule
+	last := series[5]
+	assert.NotNil(t, last.Repetition)
+	assert.Equal(t, rule, *last.Repetition.RRule)
+	assert.Zero(t, last.Repetition.EveryNWeeks)
+	assert.Equal(t, last.StartDateTime.UTC().Format(time.DateOnly), last.Repetition.RecurEnd.Format(time.DateOnly))
+
+	// Splitting it expands the rule again for the occurrences that follow
+	updated, err := repo.PatchRecurringSessions(ctx, series[2].ID, models.SessionScopeFollowing, &models.PatchSessionInput{
+		Location: ptrString("Room 4"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *updated, 4)
+	for i, s := range *updated {
+		assert.True(t, s.StartDateTime.Equal(series[i+2].StartDateTime))
+		assert.Equal(t, "Room 4", *s.Location)
+		assert.NotNil(t, s.Repetition)
+		assert.Equal(t, rule, *s.Repetition.RRule)
+	}
+
+	fetched, err := repo.GetSessionByID(ctx, (*updated)[0].ID.String())
+	assert.NoError(t, err)
+	assert.NotNil(t, fetched.Repetition)
+	assert.Equal(t, rule, *fetched.Repetition.RRule)
+}
+
+func TestSessionRepository_TimeZone(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Zone")
+	_, err := testDB.Exec(ctx, `UPDATE therapist SET time_zone = 'America/New_York' WHERE id = $1`, therapistID)
+	assert.NoError(t, err)
+
+	loc, err := repo.GetTherapistLocation(ctx, therapistID)
+	assert.NoError(t, err)
+	assert.Equal(t, "America/New_York", loc.String())
+
+	// Fridays at 20:30 New York time, across the end of daylight saving on November 2.
+	// The first one is on Saturday in UTC.
+	start := time.Date(2025, 10, 25, 0, 30, 0, 0, time.UTC)
+	posted, err := repo.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Evening group",
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		TherapistID: therapistID,
+		Repetition: &models.Repetition{
+			RecurStart:  time.Date(2025, 10, 24, 0, 0, 0, 0, time.UTC),
+			RecurEnd:    time.Date(2025, 11, 7, 0, 0, 0, 0, time.UTC),
+			EveryNWeeks: 1,
+			Days:        []int{int(time.Friday)},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *posted, 3)
+	for _, s := range *posted {
+		local := s.StartDateTime.In(loc)
+		assert.Equal(t, time.Friday, local.Weekday())
+		assert.Equal(t, 20, local.Hour())
+		assert.Equal(t, 30, local.Minute())
+	}
+	assert.Equal(t, 1, (*posted)[2].StartDateTime.UTC().Hour())
+
+	// October 31 at 20:30 is November 1 in UTC, but is counted in October
+	month, year := 10, 2025
+	sessions, err := repo.GetSessions(ctx, utils.NewPagination(), &models.GetSessionRepositoryRequest{
+		Month: &month,
+		Year:  &year,
+	}, therapistID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	_, err = repo.GetTherapistLocation(ctx, uuid.New())
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
 // func TestSessionRepository_PatchSessions(t *testing.T) {
 // 	if testing.Short() {
 // 		t.Skip("Skipping DB Tests in short mode")
@@ -507,6 +867,170 @@ func TestSessionRepository_PostSessions(t *testing.T) {
 // 	assert.Equal(t, *patchedSession.Location, "Area 52")
 // }
 
+func TestSessionRepository_GetSessionConflicts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Busy")
+	otherID := CreateSessionTestTherapist(t, testDB, ctx, "Other")
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	for _, input := range []models.PostSessionInput{
+		{SessionName: "Morning group", StartTime: start, EndTime: start.Add(time.Hour), TherapistID: therapistID},
+		{SessionName: "Late morning", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), TherapistID: therapistID},
+		{SessionName: "Someone else", StartTime: start, EndTime: start.Add(time.Hour), TherapistID: otherID},
+	} {
+		_, err := repo.PostSession(ctx, testDB, &input)
+		assert.NoError(t, err)
+	}
+
+	// 9:30-11:00 overlaps the first session and only touches the second
+	conflicts, err := repo.GetSessionConflicts(ctx, therapistID, start.Add(30*time.Minute), start.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "Morning group", conflicts[0].SessionName)
+
+	conflicts, err = repo.GetSessionConflicts(ctx, therapistID, start.Add(4*time.Hour), start.Add(5*time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestSessionRepository_PatchSessionWithoutTherapist(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Patching")
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	created, err := repo.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Morning group", StartTime: start, EndTime: start.Add(time.Hour), TherapistID: therapistID,
+	})
+	assert.NoError(t, err)
+
+	name := "Renamed"
+	patched, err := repo.PatchSession(ctx, (*created)[0].ID, &models.PatchSessionInput{SessionName: &name})
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", patched.SessionName)
+}
+
+func TestSessionRepository_FindConflicts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionRepository(testDB)
+	ssRepo := schema.NewSessionStudentRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Busy")
+	otherID := CreateSessionTestTherapist(t, testDB, ctx, "Other")
+	studentID := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Shared", 3)
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	post := func(input models.PostSessionInput) models.Session {
+		sessions, err := repo.PostSession(ctx, testDB, &input)
+		assert.NoError(t, err)
+		return (*sessions)[0]
+	}
+	own := post(models.PostSessionInput{SessionName: "Morning group", StartTime: start, EndTime: start.Add(time.Hour), TherapistID: therapistID})
+	theirs := post(models.PostSessionInput{SessionName: "Their group", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), TherapistID: otherID})
+	post(models.PostSessionInput{SessionName: "Unrelated", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), TherapistID: otherID})
+	_, err := ssRepo.CreateSessionStudent(ctx, testDB, &models.CreateSessionStudentInput{
+		SessionIDs: []uuid.UUID{theirs.ID},
+		StudentIDs: []uuid.UUID{studentID},
+	})
+	assert.NoError(t, err)
+
+	// The therapist's own session, then the other therapist's through the student
+	conflicts, err := repo.FindConflicts(ctx, &models.ConflictCheck{
+		TherapistID: therapistID,
+		StudentIDs:  []uuid.UUID{studentID},
+		Slots: []models.TimeSlot{
+			{Start: start.Add(30 * time.Minute), End: start.Add(90 * time.Minute)},
+			{Start: start.Add(2 * time.Hour), End: start.Add(150 * time.Minute)},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 2)
+	assert.Equal(t, own.ID, conflicts[0].SessionID)
+	assert.Equal(t, "Morning group", conflicts[0].SessionName)
+	assert.Equal(t, theirs.ID, conflicts[1].SessionID)
+	assert.Empty(t, conflicts[1].SessionName)
+	assert.Equal(t, otherID, conflicts[1].TherapistID)
+	assert.Equal(t, []uuid.UUID{studentID}, conflicts[1].StudentIDs)
+
+	// Moving a session over its own time is not a conflict
+	conflicts, err = repo.FindConflicts(ctx, &models.ConflictCheck{
+		TherapistID: therapistID,
+		SessionIDs:  []uuid.UUID{own.ID},
+		Slots:       []models.TimeSlot{{Start: start.Add(15 * time.Minute), End: start.Add(75 * time.Minute)}},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestSessionRepository_GetConflictReport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB tests in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewSessionRepository(testDB)
+	ssRepo := schema.NewSessionStudentRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Busy")
+	otherID := CreateSessionTestTherapist(t, testDB, ctx, "Other")
+	studentID := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Shared", 3)
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	post := func(input models.PostSessionInput) models.Session {
+		sessions, err := repo.PostSession(ctx, testDB, &input)
+		assert.NoError(t, err)
+		return (*sessions)[0]
+	}
+	first := post(models.PostSessionInput{SessionName: "First", StartTime: start, EndTime: start.Add(time.Hour), TherapistID: therapistID})
+	post(models.PostSessionInput{SessionName: "Second", StartTime: start.Add(30 * time.Minute), EndTime: start.Add(90 * time.Minute), TherapistID: therapistID})
+	theirs := post(models.PostSessionInput{SessionName: "Theirs", StartTime: start, EndTime: start.Add(time.Hour), TherapistID: otherID})
+	post(models.PostSessionInput{SessionName: "Later", StartTime: start.Add(4 * time.Hour), EndTime: start.Add(5 * time.Hour), TherapistID: therapistID})
+	_, err := ssRepo.CreateSessionStudent(ctx, testDB, &models.CreateSessionStudentInput{
+		SessionIDs: []uuid.UUID{first.ID, theirs.ID},
+		StudentIDs: []uuid.UUID{studentID},
+	})
+	assert.NoError(t, err)
+
+	overlaps, err := repo.GetConflictReport(ctx, therapistID, start.Add(-time.Hour), start.Add(6*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, overlaps, 2)
+
+	var shared *models.SessionOverlap
+	for i := range overlaps {
+		assert.Equal(t, therapistID, overlaps[i].Session.TherapistID)
+		if overlaps[i].ConflictsWith.SessionID == theirs.ID {
+			shared = &overlaps[i]
+		}
+	}
+	if assert.NotNil(t, shared) {
+		assert.Equal(t, first.ID, shared.Session.SessionID)
+		assert.Empty(t, shared.ConflictsWith.SessionName)
+		assert.Equal(t, []uuid.UUID{studentID}, shared.StudentIDs)
+	}
+
+	overlaps, err = repo.GetConflictReport(ctx, therapistID, start.Add(3*time.Hour), start.Add(6*time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, overlaps)
+}
+
 func TestGetSessionStudents(t *testing.T) {
 	// Setup
 	testDB := testutil.SetupTestWithCleanup(t)
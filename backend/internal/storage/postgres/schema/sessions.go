@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"specialstandard/internal/models"
 	"specialstandard/internal/storage/dbinterface"
 	"specialstandard/internal/utils"
@@ -26,12 +27,13 @@ func (r *SessionRepository) GetDB() *pgxpool.Pool {
 func (r *SessionRepository) GetSessions(ctx context.Context, pagination utils.Pagination, filter *models.GetSessionRepositoryRequest, therapistID uuid.UUID) ([]models.Session, error) {
 	query := `
 	SELECT s.id, s.session_name, s.start_datetime, s.end_datetime,
-	       s.notes, s.location, s.created_at, s.updated_at,
+	       s.notes, s.location, s.status, s.status_reason, s.makeup_for_session_id, s.session_template_id, s.created_at, s.updated_at,
 	       s.session_parent_id,
 		   sp.therapist_id,
-	       sp.start_date, sp.end_date, sp.every_n_weeks, sp.days
+	       ` + seriesColumns + `
 	FROM session s
 	INNER JOIN session_parent sp ON s.session_parent_id = sp.id
+	INNER JOIN therapist th ON th.id = sp.therapist_id
 	`
 
 	conditions := []string{}
@@ -46,16 +48,17 @@ func (r *SessionRepository) GetSessions(ctx context.Context, pagination utils.Pa
 	}
 
 	if filter != nil {
+		// Months and years are those of the therapist's local time
 		if filter.Month != nil && filter.Year != nil {
-			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime) = $%d AND EXTRACT(YEAR FROM s.start_datetime) = $%d", argCount, argCount+1))
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d AND EXTRACT(YEAR FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d", argCount, argCount+1))
 			args = append(args, *filter.Month, *filter.Year)
 			argCount += 2
 		} else if filter.Year != nil {
-			conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM s.start_datetime) = $%d", argCount))
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d", argCount))
 			args = append(args, *filter.Year)
 			argCount++
 		} else if filter.Month != nil {
-			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime) = $%d", argCount))
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d", argCount))
 			args = append(args, *filter.Month)
 			argCount++
 		}
@@ -72,6 +75,12 @@ func (r *SessionRepository) GetSessions(ctx context.Context, pagination utils.Pa
 			argCount++
 		}
 
+		if len(filter.Statuses) > 0 {
+			conditions = append(conditions, fmt.Sprintf("s.status = ANY($%d)", argCount))
+			args = append(args, filter.Statuses)
+			argCount++
+		}
+
 		if filter.StudentIDs != nil && len(*filter.StudentIDs) > 0 {
 			for _, studentID := range *filter.StudentIDs {
 				conditions = append(conditions, fmt.Sprintf(
@@ -100,40 +109,29 @@ func (r *SessionRepository) GetSessions(ctx context.Context, pagination utils.Pa
 	var sessions []models.Session
 	for rows.Next() {
 		var s models.Session
-		var recurStart, recurEnd *time.Time
-		var everyNWeeks *int
-		var days []int
+		var sr series
 
-		if err := rows.Scan(
+		if err := rows.Scan(append([]any{
 			&s.ID,
 			&s.SessionName,
 			&s.StartDateTime,
 			&s.EndDateTime,
 			&s.Notes,
 			&s.Location,
+			&s.Status,
+			&s.StatusReason,
+			&s.MakeupForSessionID,
+			&s.SessionTemplateID,
 			&s.CreatedAt,
 			&s.UpdatedAt,
 			&s.SessionParentID,
 			&s.TherapistID,
-			&recurStart,
-			&recurEnd,
-			&everyNWeeks,
-			&days,
-		); err != nil {
+		}, sr.dest()...)...); err != nil {
 			return nil, err
 		}
 
 		// Populate repetition only if recur_start != recur_end
-		if recurStart != nil && recurEnd != nil && !recurStart.Equal(*recurEnd) && everyNWeeks != nil {
-			s.Repetition = &models.Repetition{
-				RecurStart:  *recurStart,
-				RecurEnd:    *recurEnd,
-				EveryNWeeks: *everyNWeeks,
-				Days:        days,
-			}
-		} else {
-			s.Repetition = nil
-		}
+		s.Repetition = sr.repetition()
 
 		sessions = append(sessions, s)
 	}
@@ -144,9 +142,9 @@ func (r *SessionRepository) GetSessions(ctx context.Context, pagination utils.Pa
 func (r *SessionRepository) GetSessionByID(ctx context.Context, id string) (*models.Session, error) {
 	query := `
 	SELECT s.id, s.session_name, s.start_datetime, s.end_datetime,
-	       s.notes, s.location, s.created_at, s.updated_at,
-	       s.session_parent_id,
-	       sp.start_date, sp.end_date, sp.every_n_weeks, sp.days
+	       s.notes, s.location, s.status, s.status_reason, s.makeup_for_session_id, s.session_template_id, s.created_at, s.updated_at,
+	       s.session_parent_id, sp.therapist_id,
+	       ` + seriesColumns + `
 	FROM session s
 	INNER JOIN session_parent sp ON s.session_parent_id = sp.id
 	WHERE s.id = $1`
@@ -154,41 +152,31 @@ func (r *SessionRepository) GetSessionByID(ctx context.Context, id string) (*mod
 	row := r.db.QueryRow(ctx, query, id)
 
 	var s models.Session
-	var recurStart, recurEnd *time.Time
-	var everyNWeeks *int
-	var days []int
+	var sr series
 
-	if err := row.Scan(
+	if err := row.Scan(append([]any{
 		&s.ID,
 		&s.SessionName,
 		&s.StartDateTime,
 		&s.EndDateTime,
 		&s.Notes,
 		&s.Location,
+		&s.Status,
+		&s.StatusReason,
+		&s.MakeupForSessionID,
+		&s.SessionTemplateID,
 		&s.CreatedAt,
 		&s.UpdatedAt,
 		&s.SessionParentID,
-		&recurStart,
-		&recurEnd,
-		&everyNWeeks,
-		&days,
-	); err != nil {
+		&s.TherapistID,
+	}, sr.dest()...)...); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("session not found")
+			return nil, fmt.Errorf("session not found: %w", err)
 		}
 		return nil, err
 	}
 
-	if recurStart != nil && recurEnd != nil && !recurStart.Equal(*recurEnd) && everyNWeeks != nil {
-		s.Repetition = &models.Repetition{
-			RecurStart:  *recurStart,
-			RecurEnd:    *recurEnd,
-			EveryNWeeks: *everyNWeeks,
-			Days:        days,
-		}
-	} else {
-		s.Repetition = nil
-	}
+	s.Repetition = sr.repetition()
 
 	return &s, nil
 }
@@ -197,12 +185,35 @@ func (r *SessionRepository) DeleteSession(ctx context.Context, id uuid.UUID) err
 	query := `DELETE FROM session WHERE id = $1`
 
 	_, err := r.db.Exec(ctx, query, id)
-	return err
+	return signedNoteError(err, "The session has signed notes and cannot be deleted")
 }
 
-func addNWeeks(timestamp time.Time, nWeeks int) time.Time {
-	// Use AddDate instead of hour-based addition to handle DST correctly
-	return timestamp.AddDate(0, 0, 7*nWeeks)
+// UpdateSessionStatus records what became of the session, and why. The students of a
+// session that did not take place are owed its minutes.
+func (r *SessionRepository) UpdateSessionStatus(ctx context.Context, id uuid.UUID, status string, reason *string) (*models.Session, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, `UPDATE session SET status = $2, status_reason = $3 WHERE id = $1`, id, status, reason)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	if err := syncMakeupLedger(ctx, tx, []uuid.UUID{id}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetSessionByID(ctx, id.String())
 }
 
 func (r *SessionRepository) PostSession(
@@ -214,6 +225,19 @@ func (r *SessionRepository) PostSession(
 	fmt.Printf("Posting session with data: %+v\n", input)
 	fmt.Printf("Time details: %+v\n", input.StartTime)
 
+	// Sessions are scheduled in the therapist's time zone, so a series keeps its time of
+	// day across daylight saving changes. An unknown therapist fails on the insert.
+	loc, err := therapistLocation(ctx, q, input.TherapistID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if loc != nil {
+		local := *input
+		local.StartTime = input.StartTime.In(loc)
+		local.EndTime = input.EndTime.In(loc)
+		input = &local
+	}
+
 	// Insert session_parent first
 	var parentID uuid.UUID
 	var parentStart, parentEnd time.Time
@@ -234,18 +258,25 @@ func (r *SessionRepository) PostSession(
 		}
 
 	} else {
-		rp := input.Repetition
+		// No sessions on school holidays and breaks, the series keeps them as exceptions
+		rp, err := skipNonSchoolDays(ctx, q, input.TherapistID, input.Repetition, input.StartTime, input.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		recurring := *input
+		recurring.Repetition = rp
+		input = &recurring
 
 		parentStart = rp.RecurStart
-		parentEnd = rp.RecurEnd
+		parentEnd = seriesEnd(rp, input.StartTime, input.EndTime)
 		everyNWeeks := &rp.EveryNWeeks
 		days := rp.Days
 
-		err := q.QueryRow(ctx,
-			`INSERT INTO session_parent (start_date, end_date, every_n_weeks, days, therapist_id)
-             VALUES ($1, $2, $3, $4, $5)
+		err = q.QueryRow(ctx,
+			`INSERT INTO session_parent (start_date, end_date, every_n_weeks, days, rrule, extra_dates, exception_dates, therapist_id)
+             VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8)
              RETURNING id`,
-			parentStart, parentEnd, everyNWeeks, days, input.TherapistID,
+			parentStart, parentEnd, everyNWeeks, days, rp.RRule, sortedDates(rp.ExtraDates), sortedDates(rp.ExceptionDates), input.TherapistID,
 		).Scan(&parentID)
 		if err != nil {
 			return nil, err
@@ -262,52 +293,28 @@ func (r *SessionRepository) PostSession(
 
 	// Generate sessions only on specified recurrence days
 	if input.Repetition != nil {
-		rp := input.Repetition
-
-		// Start from the week containing the recurrence start
-		startOfWeek := rp.RecurStart
-
-		// For each N-week interval until rp.RecurEnd
-		for wkStart := startOfWeek; !wkStart.After(rp.RecurEnd); wkStart = addNWeeks(wkStart, rp.EveryNWeeks) {
-
-			// Only iterate through the explicitly selected recurrence days
-			for _, dayIndex := range rp.Days {
-				// Calculate when this day occurs in this week interval
-				occStart := setToWeekday(wkStart, dayIndex, input.StartTime)
-				occEnd := setToWeekday(wkStart, dayIndex, input.EndTime)
-
-				// Skip if this occurrence is before the recurrence should start
-				if occStart.Before(rp.RecurStart) {
-					continue
-				}
-
-				// Skip if this occurrence is after the recurrence should end
-				if occStart.After(rp.RecurEnd) {
-					continue
-				}
-
-				fmt.Printf("Inserting session for %s (day %d) at %v\n", occStart.Format("Monday"), dayIndex, occStart)
-				fmt.Printf("START > END? %v\n", occStart.After(occEnd))
-				fmt.Printf("START == END? %v\n", occStart.Equal(occEnd))
-
-				var id uuid.UUID
-				err := q.QueryRow(ctx,
-					`INSERT INTO session (session_name, start_datetime, end_datetime, notes, location, session_parent_id)
-                 VALUES ($1, $2, $3, $4, $5, $6)
+		// One session per occurrence, the days without classes left out
+		for _, occ := range input.Occurrences() {
+			occStart, occEnd := occ.Start, occ.End
+
+			var id uuid.UUID
+			err := q.QueryRow(ctx,
+				`INSERT INTO session (session_name, start_datetime, end_datetime, notes, location, session_parent_id,
+                 session_template_id)
+                 VALUES ($1, $2, $3, $4, $5, $6, $7)
                  RETURNING id, start_datetime, end_datetime`,
-					input.SessionName, occStart, occEnd,
-					input.Notes, input.Location, parentID,
-				).Scan(&id, &occStart, &occEnd)
-				if err != nil {
-					return nil, err
-				}
-
-				sessionsInserted = append(sessionsInserted, insertedSession{
-					ID:    id,
-					Start: occStart,
-					End:   occEnd,
-				})
+				input.SessionName, occStart, occEnd,
+				input.Notes, input.Location, parentID, input.TemplateID,
+			).Scan(&id, &occStart, &occEnd)
+			if err != nil {
+				return nil, err
 			}
+
+			sessionsInserted = append(sessionsInserted, insertedSession{
+				ID:    id,
+				Start: occStart,
+				End:   occEnd,
+			})
 		}
 	} else {
 		// Single non-recurring session
@@ -317,13 +324,15 @@ func (r *SessionRepository) PostSession(
 		fmt.Printf("START > END? %v\n", input.StartTime.After(input.EndTime))
 		fmt.Printf("START == END? %v\n", input.StartTime.Equal(input.EndTime))
 
+		// A makeup session is marked as one from the start
 		var id uuid.UUID
 		err := q.QueryRow(ctx,
-			`INSERT INTO session (session_name, start_datetime, end_datetime, notes, location, session_parent_id)
-             VALUES ($1, $2, $3, $4, $5, $6)
+			`INSERT INTO session (session_name, start_datetime, end_datetime, notes, location, session_parent_id,
+                 status, makeup_for_session_id, session_template_id)
+             VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $7::uuid IS NULL THEN 'scheduled' ELSE 'makeup' END, $7, $8)
              RETURNING id, start_datetime, end_datetime`,
 			input.SessionName, input.StartTime, input.EndTime,
-			input.Notes, input.Location, parentID,
+			input.Notes, input.Location, parentID, input.MakeupForSessionID, input.TemplateID,
 		).Scan(&id, &input.StartTime, &input.EndTime)
 		if err != nil {
 			return nil, err
@@ -343,49 +352,38 @@ func (r *SessionRepository) PostSession(
 
 		row := q.QueryRow(ctx, `
             SELECT s.id, s.session_name, s.start_datetime, s.end_datetime,
-                   s.notes, s.location, s.created_at, s.updated_at,
+                   s.notes, s.location, s.status, s.status_reason, s.makeup_for_session_id, s.session_template_id, s.created_at, s.updated_at,
                    s.session_parent_id,
-                   sp.start_date, sp.end_date, sp.every_n_weeks, sp.days
+                   `+seriesColumns+`
             FROM session s
             INNER JOIN session_parent sp ON s.session_parent_id = sp.id
             WHERE s.id = $1
         `, sInserted.ID)
 
 		var s models.Session
-		var recurStart, recurEnd *time.Time
-		var enWeeks *int
-		var d []int
+		var sr series
 
-		err := row.Scan(
+		err := row.Scan(append([]any{
 			&s.ID,
 			&s.SessionName,
 			&s.StartDateTime,
 			&s.EndDateTime,
 			&s.Notes,
 			&s.Location,
+			&s.Status,
+			&s.StatusReason,
+			&s.MakeupForSessionID,
+			&s.SessionTemplateID,
 			&s.CreatedAt,
 			&s.UpdatedAt,
 			&s.SessionParentID,
-			&recurStart,
-			&recurEnd,
-			&enWeeks,
-			&d,
-		)
+		}, sr.dest()...)...)
 		if err != nil {
 			return nil, err
 		}
 
 		// Same repetition logic as GetSessionByID
-		if recurStart != nil && recurEnd != nil && !recurStart.Equal(*recurEnd) && enWeeks != nil {
-			s.Repetition = &models.Repetition{
-				RecurStart:  *recurStart,
-				RecurEnd:    *recurEnd,
-				EveryNWeeks: *enWeeks,
-				Days:        d,
-			}
-		} else {
-			s.Repetition = nil
-		}
+		s.Repetition = sr.repetition()
 
 		sessions = append(sessions, s)
 	}
@@ -393,20 +391,31 @@ func (r *SessionRepository) PostSession(
 	return &sessions, nil
 }
 
-func setToWeekday(base time.Time, targetWeekday int, reference time.Time) time.Time {
-	baseWeekday := int(base.Weekday()) // Sunday=0
-	diff := targetWeekday - baseWeekday
-	d := base.AddDate(0, 0, diff)
+func (r *SessionRepository) PatchSession(ctx context.Context, id uuid.UUID, input *models.PatchSessionInput) (*models.Session, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	// Preserve the hour/min/sec from the reference
-	return time.Date(
-		d.Year(), d.Month(), d.Day(),
-		reference.Hour(), reference.Minute(), reference.Second(),
-		reference.Nanosecond(), reference.Location(),
-	)
+	session, err := patchSession(ctx, tx, id, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	if input.TherapistID != nil {
+		session.TherapistID = *input.TherapistID
+	}
+
+	return session, nil
 }
 
-func (r *SessionRepository) PatchSession(ctx context.Context, id uuid.UUID, input *models.PatchSessionInput) (*models.Session, error) {
+func patchSession(ctx context.Context, q dbinterface.Queryable, id uuid.UUID, input *models.PatchSessionInput) (*models.Session, error) {
 	session := &models.Session{}
 
 	query := `UPDATE session
@@ -417,9 +426,9 @@ func (r *SessionRepository) PatchSession(ctx context.Context, id uuid.UUID, inpu
 					notes = COALESCE($4, notes),
 					location = COALESCE($5, location)
 				WHERE id = $6
-				RETURNING id, session_name, start_datetime, end_datetime, notes, location, created_at, updated_at`
+				RETURNING id, session_name, start_datetime, end_datetime, notes, location, status, status_reason, makeup_for_session_id, session_template_id, created_at, updated_at, session_parent_id`
 
-	row := r.db.QueryRow(ctx, query, input.SessionName, input.StartTime, input.EndTime, input.Notes, input.Location, id)
+	row := q.QueryRow(ctx, query, input.SessionName, input.StartTime, input.EndTime, input.Notes, input.Location, id)
 
 	if err := row.Scan(
 		&session.ID,
@@ -428,17 +437,320 @@ func (r *SessionRepository) PatchSession(ctx context.Context, id uuid.UUID, inpu
 		&session.EndDateTime,
 		&session.Notes,
 		&session.Location,
+		&session.Status,
+		&session.StatusReason,
+		&session.MakeupForSessionID,
+		&session.SessionTemplateID,
 		&session.CreatedAt,
 		&session.UpdatedAt,
+		&session.SessionParentID,
 	); err != nil {
 		return nil, err
 	}
 
-	session.TherapistID = *input.TherapistID
+	// The minutes owed for a missed session follow its times
+	if err := syncMakeupLedger(ctx, q, []uuid.UUID{id}); err != nil {
+		return nil, err
+	}
 
 	return session, nil
 }
 
+// regeneratedOccurrences matches the occurrences of series $1 from $2 on that a split
+// replaces: those still scheduled and without ratings or notes
+const regeneratedOccurrences = `s.session_parent_id = $1
+		AND s.start_datetime >= $2
+		AND s.status = 'scheduled'
+		AND NOT EXISTS (
+			SELECT 1 FROM session_student ss
+			JOIN session_rating sr ON sr.session_student_id = ss.id
+			WHERE ss.session_id = s.id
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM session_student ss
+			JOIN session_note sn ON sn.session_student_id = ss.id
+			WHERE ss.session_id = s.id
+		)`
+
+// PatchRecurringSessions applies a patch to an occurrence and every later
+// occurrence in its series ("following") or to the whole series ("all").
+// The series is split at the pivot: occurrences that have already started, that
+// have ratings or notes recorded or that were missed stay on the original session_parent,
+// and the rest are regenerated under a new session_parent from the updated
+// repetition. A regenerated occurrence keeps the students of the occurrence it
+// replaces on the same day, and takes those of the edited occurrence on days
+// that had none. The new series skips the days of every occurrence kept after
+// the pivot.
+func (r *SessionRepository) PatchRecurringSessions(ctx context.Context, id uuid.UUID, scope string, input *models.PatchSessionInput) (*[]models.Session, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var (
+		target      models.Session
+		therapistID uuid.UUID
+		sr          series
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT s.id, s.session_name, s.start_datetime, s.end_datetime, s.notes, s.location,
+		       s.session_template_id, s.session_parent_id, sp.therapist_id, `+seriesColumns+`
+		FROM session s
+		INNER JOIN session_parent sp ON s.session_parent_id = sp.id
+		WHERE s.id = $1
+		FOR UPDATE OF sp`, id).Scan(append([]any{
+		&target.ID, &target.SessionName, &target.StartDateTime, &target.EndDateTime, &target.Notes, &target.Location,
+		&target.SessionTemplateID, &target.SessionParentID, &therapistID,
+	}, sr.dest()...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := input.Repetition
+	if rp == nil {
+		if rp = sr.repetition(); rp == nil {
+			// Not a series, so there is nothing to split
+			session, err := patchSession(ctx, tx, id, input)
+			if err != nil {
+				return nil, err
+			}
+			session.TherapistID = therapistID
+			if err := tx.Commit(ctx); err != nil {
+				return nil, err
+			}
+			return &[]models.Session{*session}, nil
+		}
+	}
+
+	pivot := target.StartDateTime
+	if scope == models.SessionScopeAll {
+		if err := tx.QueryRow(ctx, `SELECT MIN(start_datetime) FROM session WHERE session_parent_id = $1`,
+			target.SessionParentID).Scan(&pivot); err != nil {
+			return nil, err
+		}
+	}
+	if now := time.Now(); pivot.Before(now) {
+		pivot = now
+	}
+
+	rows, err := tx.Query(ctx, `SELECT student_id FROM session_student WHERE session_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	studentIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, err
+	}
+
+	// The new sessions keep the resources of the one patched
+	rows, err = tx.Query(ctx, `SELECT resource_id FROM session_resource WHERE session_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	resourceIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := therapistLocation(ctx, tx, therapistID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Students added to single occurrences stay with their day
+	rows, err = tx.Query(ctx, `
+		SELECT s.start_datetime, ARRAY_AGG(ss.student_id ORDER BY ss.created_at)
+		FROM session s
+		JOIN session_student ss ON ss.session_id = s.id
+		WHERE `+regeneratedOccurrences+`
+		GROUP BY s.id, s.start_datetime`, target.SessionParentID, pivot)
+	if err != nil {
+		return nil, err
+	}
+	rosters := make(map[string][]uuid.UUID)
+	var (
+		rosterStart time.Time
+		roster      []uuid.UUID
+	)
+	if _, err := pgx.ForEachRow(rows, []any{&rosterStart, &roster}, func() error {
+		day := rosterStart.In(loc).Format(time.DateOnly)
+		for _, studentID := range roster {
+			if !slices.Contains(rosters[day], studentID) {
+				rosters[day] = append(rosters[day], studentID)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM session s WHERE `+regeneratedOccurrences, target.SessionParentID, pivot)
+	if err != nil {
+		return nil, err
+	}
+
+	// Shrink the original series to what is left of it, or drop it entirely
+	var lastRemaining *time.Time
+	if err := tx.QueryRow(ctx, `SELECT MAX(start_datetime) FROM session WHERE session_parent_id = $1`,
+		target.SessionParentID).Scan(&lastRemaining); err != nil {
+		return nil, err
+	}
+	if lastRemaining == nil {
+		_, err = tx.Exec(ctx, `DELETE FROM session_parent WHERE id = $1`, target.SessionParentID)
+	} else {
+		_, err = tx.Exec(ctx, `
+			UPDATE session_parent
+			SET end_date = GREATEST(start_date, $2::date), updated_at = now()
+			WHERE id = $1`, target.SessionParentID, *lastRemaining)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	startTime, endTime := target.StartDateTime, target.EndDateTime
+	if input.StartTime != nil {
+		startTime = *input.StartTime
+	}
+	if input.EndTime != nil {
+		endTime = *input.EndTime
+	}
+	sessionName, notes, location := target.SessionName, target.Notes, target.Location
+	if input.SessionName != nil {
+		sessionName = *input.SessionName
+	}
+	if input.Notes != nil {
+		notes = input.Notes
+	}
+	if input.Location != nil {
+		location = input.Location
+	}
+
+	startTime, endTime = startTime.In(loc), endTime.In(loc)
+
+	rp, err = skipNonSchoolDays(ctx, tx, therapistID, rp, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Whatever is left after the pivot was kept, missed or rated, and must not be doubled
+	rows, err = tx.Query(ctx, `
+		SELECT start_datetime FROM session
+		WHERE session_parent_id = $1 AND start_datetime >= $2`,
+		target.SessionParentID, pivot)
+	if err != nil {
+		return nil, err
+	}
+	kept, err := pgx.CollectRows(rows, pgx.RowTo[time.Time])
+	if err != nil {
+		return nil, err
+	}
+	if len(kept) > 0 {
+		skipping := *rp
+		skipping.ExceptionDates = slices.Clone(rp.ExceptionDates)
+		for _, m := range kept {
+			y, mo, d := m.In(loc).Date()
+			skipping.ExceptionDates = append(skipping.ExceptionDates, time.Date(y, mo, d, 0, 0, 0, 0, time.UTC))
+		}
+		rp = &skipping
+	}
+
+	occurrences := rp.Occurrences(startTime, endTime, pivot)
+	if len(occurrences) == 0 {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return &[]models.Session{}, nil
+	}
+
+	// Anchor the new series on its first occurrence so the every-n-weeks
+	// cadence is kept if it is split again later
+	var parentID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO session_parent (start_date, end_date, every_n_weeks, days, rrule, extra_dates, exception_dates, therapist_id)
+		VALUES ($1::date, GREATEST($1::date, $2::date), NULLIF($3, 0), $4, $5, $6, $7, $8)
+		RETURNING id`,
+		occurrences[0][0], seriesEnd(rp, startTime, endTime), rp.EveryNWeeks, rp.Days, rp.RRule,
+		sortedDates(rp.ExtraDates), sortedDates(rp.ExceptionDates), therapistID,
+	).Scan(&parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]models.Session, 0, len(occurrences))
+	for _, occ := range occurrences {
+		s := models.Session{
+			SessionName:       sessionName,
+			TherapistID:       therapistID,
+			Notes:             notes,
+			Location:          location,
+			Status:            models.SessionScheduled,
+			SessionTemplateID: target.SessionTemplateID,
+			SessionParentID:   parentID,
+		}
+		err := tx.QueryRow(ctx, `
+			INSERT INTO session (session_name, start_datetime, end_datetime, notes, location, session_parent_id,
+			    session_template_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, start_datetime, end_datetime, created_at, updated_at`,
+			sessionName, occ[0], occ[1], notes, location, parentID, target.SessionTemplateID,
+		).Scan(&s.ID, &s.StartDateTime, &s.EndDateTime, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resourceIDs) > 0 {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO session_resource (session_id, resource_id)
+				SELECT $1, unnest($2::uuid[])`, s.ID, resourceIDs); err != nil {
+				return nil, err
+			}
+		}
+
+		students, ok := rosters[occ[0].In(loc).Format(time.DateOnly)]
+		if !ok {
+			students = studentIDs
+		}
+		for _, studentID := range students {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO session_student (session_id, student_id) VALUES ($1, $2)`,
+				s.ID, studentID,
+			); err != nil {
+				return nil, err
+			}
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	var parentStart, parentEnd time.Time
+	if err := tx.QueryRow(ctx, `SELECT start_date, end_date FROM session_parent WHERE id = $1`,
+		parentID).Scan(&parentStart, &parentEnd); err != nil {
+		return nil, err
+	}
+	if !parentStart.Equal(parentEnd) {
+		for i := range sessions {
+			sessions[i].Repetition = &models.Repetition{
+				RecurStart:     parentStart,
+				RecurEnd:       parentEnd,
+				EveryNWeeks:    rp.EveryNWeeks,
+				Days:           rp.Days,
+				RRule:          rp.RRule,
+				ExtraDates:     sortedDates(rp.ExtraDates),
+				ExceptionDates: sortedDates(rp.ExceptionDates),
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &sessions, nil
+}
+
 func (r *SessionRepository) DeleteRecurringSessions(ctx context.Context, id uuid.UUID) error {
 	query := `SELECT session_parent_id, start_datetime FROM session WHERE id = $1`
 
@@ -456,7 +768,172 @@ func (r *SessionRepository) DeleteRecurringSessions(ctx context.Context, id uuid
     `
 
 	_, err = r.db.Exec(ctx, query, sessionParentID, startDatetime)
-	return err
+	return signedNoteError(err, "Some of the sessions have signed notes and cannot be deleted")
+}
+
+// cancelledStatuses are those of the sessions that no longer take up their time, which
+// cannot conflict with others
+const cancelledStatuses = `('cancelled_by_therapist', 'cancelled_by_school')`
+
+// missedStatuses are those of the sessions that delivered no services
+const missedStatuses = `('cancelled_by_therapist', 'cancelled_by_school', 'student_absent')`
+
+// GetSessionConflicts lists the therapist's sessions that overlap the window from-to.
+// Sessions that only touch it, ending as it starts, do not count.
+func (r *SessionRepository) GetSessionConflicts(ctx context.Context, therapistID uuid.UUID, from, to time.Time) ([]models.SessionConflict, error) {
+	query := `
+	SELECT s.id, s.session_name, s.start_datetime, s.end_datetime, sp.therapist_id
+	FROM session s
+	INNER JOIN session_parent sp ON s.session_parent_id = sp.id
+	WHERE sp.therapist_id = $1
+	  AND s.start_datetime < $3
+	  AND s.end_datetime > $2
+	  AND s.status NOT IN ` + cancelledStatuses + `
+	ORDER BY s.start_datetime ASC`
+
+	rows, err := r.db.Query(ctx, query, therapistID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conflicts := []models.SessionConflict{}
+	for rows.Next() {
+		var c models.SessionConflict
+		if err := rows.Scan(&c.SessionID, &c.SessionName, &c.StartDateTime, &c.EndDateTime, &c.TherapistID); err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, c)
+	}
+
+	return conflicts, rows.Err()
+}
+
+// FindConflicts lists the sessions that would double-book the therapist or one of the
+// students of the check. Sessions of other therapists only conflict through a student,
+// and their names are left out.
+func (r *SessionRepository) FindConflicts(ctx context.Context, check *models.ConflictCheck) ([]models.SessionConflict, error) {
+	conflicts := []models.SessionConflict{}
+	if len(check.Slots) == 0 {
+		return conflicts, nil
+	}
+
+	starts := make([]time.Time, len(check.Slots))
+	ends := make([]time.Time, len(check.Slots))
+	for i, slot := range check.Slots {
+		starts[i], ends[i] = slot.Start, slot.End
+	}
+	studentIDs := check.StudentIDs
+	if studentIDs == nil {
+		studentIDs = []uuid.UUID{}
+	}
+	sessionIDs := check.SessionIDs
+	if sessionIDs == nil {
+		sessionIDs = []uuid.UUID{}
+	}
+
+	query := `
+	WITH slot AS (
+		SELECT * FROM unnest($1::timestamptz[], $2::timestamptz[]) AS t(start_at, end_at)
+	), checked_student AS (
+		SELECT unnest($4::uuid[]) AS student_id
+		UNION
+		SELECT student_id FROM session_student WHERE session_id = ANY($5)
+	)
+	SELECT s.id,
+	       CASE WHEN sp.therapist_id = $3 THEN s.session_name ELSE '' END,
+	       s.start_datetime, s.end_datetime, sp.therapist_id,
+	       ARRAY(
+	           SELECT ss.student_id FROM session_student ss
+	           WHERE ss.session_id = s.id
+	             AND ss.student_id IN (SELECT student_id FROM checked_student)
+	           ORDER BY ss.student_id
+	       )
+	FROM session s
+	INNER JOIN session_parent sp ON s.session_parent_id = sp.id
+	WHERE EXISTS (SELECT 1 FROM slot WHERE s.start_datetime < slot.end_at AND s.end_datetime > slot.start_at)
+	  AND NOT (s.id = ANY($5))
+	  AND s.status NOT IN ` + cancelledStatuses + `
+	  AND ($6::uuid IS NULL OR s.session_parent_id <> $6)
+	  AND (sp.therapist_id = $3 OR EXISTS (
+	      SELECT 1 FROM session_student ss
+	      WHERE ss.session_id = s.id
+	        AND ss.student_id IN (SELECT student_id FROM checked_student)
+	  ))
+	ORDER BY s.start_datetime ASC`
+
+	rows, err := r.db.Query(ctx, query, starts, ends, check.TherapistID, studentIDs, sessionIDs, check.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.SessionConflict
+		if err := rows.Scan(&c.SessionID, &c.SessionName, &c.StartDateTime, &c.EndDateTime, &c.TherapistID, &c.StudentIDs); err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, c)
+	}
+
+	return conflicts, rows.Err()
+}
+
+// GetConflictReport lists the pairs of sessions in the window from-to that double-book
+// the therapist, or one of the therapist's students with another therapist
+func (r *SessionRepository) GetConflictReport(ctx context.Context, therapistID uuid.UUID, from, to time.Time) ([]models.SessionOverlap, error) {
+	query := `
+	SELECT a.id, a.session_name, a.start_datetime, a.end_datetime, spa.therapist_id,
+	       b.id, CASE WHEN spb.therapist_id = $1 THEN b.session_name ELSE '' END,
+	       b.start_datetime, b.end_datetime, spb.therapist_id,
+	       ARRAY(
+	           SELECT sa.student_id FROM session_student sa
+	           INNER JOIN session_student sb ON sb.student_id = sa.student_id AND sb.session_id = b.id
+	           WHERE sa.session_id = a.id
+	           ORDER BY sa.student_id
+	       ) AS shared
+	FROM session a
+	INNER JOIN session_parent spa ON a.session_parent_id = spa.id
+	INNER JOIN session b ON b.id <> a.id
+	    AND b.start_datetime < a.end_datetime
+	    AND b.end_datetime > a.start_datetime
+	    AND b.status NOT IN ` + cancelledStatuses + `
+	INNER JOIN session_parent spb ON b.session_parent_id = spb.id
+	WHERE spa.therapist_id = $1
+	  AND a.start_datetime < $3
+	  AND a.end_datetime > $2
+	  AND a.status NOT IN ` + cancelledStatuses + `
+	  AND (
+	      -- Each pair of the therapist's own sessions once
+	      (spb.therapist_id = $1 AND a.id < b.id)
+	      OR (spb.therapist_id <> $1 AND EXISTS (
+	          SELECT 1 FROM session_student sa
+	          INNER JOIN session_student sb ON sb.student_id = sa.student_id AND sb.session_id = b.id
+	          WHERE sa.session_id = a.id
+	      ))
+	  )
+	ORDER BY a.start_datetime ASC, b.start_datetime ASC`
+
+	rows, err := r.db.Query(ctx, query, therapistID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overlaps := []models.SessionOverlap{}
+	for rows.Next() {
+		var o models.SessionOverlap
+		if err := rows.Scan(
+			&o.Session.SessionID, &o.Session.SessionName, &o.Session.StartDateTime, &o.Session.EndDateTime, &o.Session.TherapistID,
+			&o.ConflictsWith.SessionID, &o.ConflictsWith.SessionName, &o.ConflictsWith.StartDateTime, &o.ConflictsWith.EndDateTime, &o.ConflictsWith.TherapistID,
+			&o.StudentIDs,
+		); err != nil {
+			return nil, err
+		}
+		overlaps = append(overlaps, o)
+	}
+
+	return overlaps, rows.Err()
 }
 
 func (r *SessionRepository) GetSessionStudents(ctx context.Context, sessionID uuid.UUID, pagination utils.Pagination, therapistID uuid.UUID) ([]models.SessionStudentsOutput, error) {
@@ -483,9 +960,17 @@ func (r *SessionRepository) GetSessionStudents(ctx context.Context, sessionID uu
 	if err != nil {
 		return nil, err
 	}
+
+	return collectSessionStudents(rows)
+}
+
+// collectSessionStudents gathers rows of session students joined to their ratings, one per
+// rating, in the order the students first appear
+func collectSessionStudents(rows pgx.Rows) ([]models.SessionStudentsOutput, error) {
 	defer rows.Close()
 
-	sessionStudentsMap := make(map[string]*models.SessionStudentsOutput)
+	var sessionStudents []models.SessionStudentsOutput
+	seen := make(map[uuid.UUID]int)
 	for rows.Next() {
 		var result models.SessionStudentsOutput
 		var student models.Student
@@ -501,30 +986,96 @@ func (r *SessionRepository) GetSessionStudents(ctx context.Context, sessionID uu
 			return nil, err
 		}
 
-		if existing, exists := sessionStudentsMap[student.ID.String()]; exists {
-			// Student already exists
-			if rating.Level != nil && rating.Category != nil {
-				existing.Ratings = append(existing.Ratings, rating)
-			}
-		} else {
+		i, exists := seen[student.ID]
+		if !exists {
 			result.Student = student
 			result.Ratings = []models.SessionRating{}
+			sessionStudents = append(sessionStudents, result)
+			i = len(sessionStudents) - 1
+			seen[student.ID] = i
+		}
+		// Only add the rating if it's valid
+		if rating.Level != nil && rating.Category != nil {
+			sessionStudents[i].Ratings = append(sessionStudents[i].Ratings, rating)
+		}
+	}
 
-			// Only add the rating if it's valid
-			if rating.Level != nil && rating.Category != nil {
-				result.Ratings = append(result.Ratings, rating)
-			}
+	return sessionStudents, rows.Err()
+}
 
-			sessionStudentsMap[student.ID.String()] = &result
-		}
+// GetTherapistLocation is the time zone the therapist's sessions are scheduled in
+func (r *SessionRepository) GetTherapistLocation(ctx context.Context, therapistID uuid.UUID) (*time.Location, error) {
+	return therapistLocation(ctx, r.db, therapistID)
+}
+
+func therapistLocation(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) (*time.Location, error) {
+	var zone string
+	if err := q.QueryRow(ctx, `SELECT time_zone FROM therapist WHERE id = $1`, therapistID).Scan(&zone); err != nil {
+		return nil, err
 	}
+	return time.LoadLocation(zone)
+}
 
-	var sessionStudents []models.SessionStudentsOutput
-	for _, ss := range sessionStudentsMap {
-		sessionStudents = append(sessionStudents, *ss)
+// seriesColumns are the session_parent columns the repetition of a session is read from
+const seriesColumns = `sp.start_date, sp.end_date, sp.every_n_weeks, sp.days, sp.rrule, sp.extra_dates, sp.exception_dates`
+
+// series holds the seriesColumns of a row
+type series struct {
+	start, end     *time.Time
+	everyNWeeks    *int
+	days           []int
+	rrule          *string
+	extraDates     []time.Time
+	exceptionDates []time.Time
+}
+
+func (sr *series) dest() []any {
+	return []any{&sr.start, &sr.end, &sr.everyNWeeks, &sr.days, &sr.rrule, &sr.extraDates, &sr.exceptionDates}
+}
+
+// repetition is nil for a single session, whose session_parent starts and ends on the
+// same day
+func (sr *series) repetition() *models.Repetition {
+	if sr.start == nil || sr.end == nil || sr.start.Equal(*sr.end) || (sr.everyNWeeks == nil && sr.rrule == nil) {
+		return nil
 	}
 
-	return sessionStudents, nil
+	rp := &models.Repetition{
+		RecurStart:     *sr.start,
+		RecurEnd:       *sr.end,
+		Days:           sr.days,
+		RRule:          sr.rrule,
+		ExtraDates:     sr.extraDates,
+		ExceptionDates: sr.exceptionDates,
+	}
+	if sr.everyNWeeks != nil {
+		rp.EveryNWeeks = *sr.everyNWeeks
+	}
+	return rp
+}
+
+// seriesEnd is the last day of the repetition, the day of its last occurrence when a
+// rule's COUNT or UNTIL ends it instead
+func seriesEnd(rp *models.Repetition, startTime, endTime time.Time) time.Time {
+	if !rp.RecurEnd.IsZero() {
+		return rp.RecurEnd
+	}
+	occurrences := rp.Occurrences(startTime, endTime, time.Time{})
+	if len(occurrences) == 0 {
+		return rp.RecurStart
+	}
+	return occurrences[len(occurrences)-1][0]
+}
+
+// sortedDates are the dates in order without duplicates, as stored on session_parent
+func sortedDates(days []time.Time) []time.Time {
+	dates := make([]time.Time, 0, len(days))
+	for _, d := range days {
+		y, m, day := d.Date()
+		dates = append(dates, time.Date(y, m, day, 0, 0, 0, 0, time.UTC))
+	}
+	slices.SortFunc(dates, time.Time.Compare)
+	return slices.CompactFunc(dates, time.Time.Equal)
 }
 
 func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
@@ -985,3 +985,47 @@ func TestStudentRepository_GetStudents_MultipleSchoolsSameDistrict(t *testing.T)
 		assert.Equal(t, 1, student.SchoolID)
 	}
 }
+
+func TestStudentRepository_StudentSessionsMonthInTherapistTimeZone(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewStudentRepository(testDB)
+	sessions := schema.NewSessionRepository(testDB)
+	ctx := context.Background()
+
+	therapistID := CreateSessionTestTherapist(t, testDB, ctx, "Halloween")
+	studentID := CreateSessionTestStudent(t, testDB, ctx, therapistID, "Halloween", 3)
+	_, err := testDB.Exec(ctx, `UPDATE therapist SET time_zone = 'America/New_York' WHERE id = $1`, therapistID)
+	assert.NoError(t, err)
+
+	// 22:00 on October 31 in New York is already November in UTC
+	start := time.Date(2026, time.November, 1, 2, 0, 0, 0, time.UTC)
+	posted, err := sessions.PostSession(ctx, testDB, &models.PostSessionInput{
+		SessionName: "Late session",
+		StartTime:   start,
+		EndTime:     start.Add(30 * time.Minute),
+		TherapistID: therapistID,
+	})
+	assert.NoError(t, err)
+	_, err = testDB.Exec(ctx, `INSERT INTO session_student (session_id, student_id) VALUES ($1, $2)`, (*posted)[0].ID, studentID)
+	assert.NoError(t, err)
+
+	filter := models.GetStudentSessionsRepositoryRequest{Month: PtrInt(10), Year: PtrInt(2026)}
+	found, err := repo.GetStudentSessions(ctx, studentID, utils.NewPagination(), &filter)
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	ratings, err := repo.GetStudentRatings(ctx, studentID, utils.NewPagination(), &models.GetStudentSessionsRatingsRequest{
+		GetStudentSessionsRepositoryRequest: filter,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ratings, 1)
+
+	filter.Month = PtrInt(11)
+	found, err = repo.GetStudentSessions(ctx, studentID, utils.NewPagination(), &filter)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
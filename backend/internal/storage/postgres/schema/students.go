@@ -80,7 +80,7 @@ func (r *StudentRepository) DeleteStudent(ctx context.Context, id uuid.UUID) err
 	WHERE id = $1`
 
 	_, err := r.db.Exec(ctx, query, id)
-	return err
+	return signedNoteError(err, "The student has signed session notes and cannot be deleted")
 }
 
 func (r *StudentRepository) UpdateStudent(ctx context.Context, student models.Student) (models.Student, error) {
@@ -184,10 +184,11 @@ func (r *StudentRepository) GetStudentSessions(ctx context.Context, studentID uu
 	query := `
 	SELECT ss.student_id, ss.present, ss.notes, ss.created_at, ss.updated_at,
 	       s.id, s.session_name, s.start_datetime, s.end_datetime, sp.therapist_id, s.notes, s.location,
-	       s.created_at, s.updated_at, s.session_parent_id
+	       s.status, s.status_reason, s.makeup_for_session_id, s.session_template_id, s.created_at, s.updated_at, s.session_parent_id
 	FROM session_student ss
 	JOIN session s ON ss.session_id = s.id
 	JOIN session_parent sp ON s.session_parent_id = sp.id
+	JOIN therapist th ON th.id = sp.therapist_id
 	WHERE ss.student_id = $1`
 
 	conditions := []string{}
@@ -195,17 +196,17 @@ func (r *StudentRepository) GetStudentSessions(ctx context.Context, studentID uu
 	argCount := 2
 
 	if filter != nil {
-		// Date filtering - similar to sessions
+		// Date filtering - similar to sessions, months are the therapist's
 		if filter.Month != nil && filter.Year != nil {
-			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime) = $%d AND EXTRACT(YEAR FROM s.start_datetime) = $%d", argCount, argCount+1))
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d AND EXTRACT(YEAR FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d", argCount, argCount+1))
 			args = append(args, *filter.Month, *filter.Year)
 			argCount += 2
 		} else if filter.Year != nil {
-			conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM s.start_datetime) = $%d", argCount))
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d", argCount))
 			args = append(args, *filter.Year)
 			argCount++
 		} else if filter.Month != nil {
-			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime) = $%d", argCount))
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d", argCount))
 			args = append(args, *filter.Month)
 			argCount++
 		}
@@ -256,7 +257,7 @@ func (r *StudentRepository) GetStudentSessions(ctx context.Context, studentID uu
 		err := rows.Scan(
 			&result.StudentID, &result.Present, &result.Notes, &result.CreatedAt, &result.UpdatedAt,
 			&session.ID, &session.SessionName, &session.StartDateTime, &session.EndDateTime, &session.TherapistID, &session.Notes, &session.Location,
-			&session.CreatedAt, &session.UpdatedAt, &session.SessionParentID,
+			&session.Status, &session.StatusReason, &session.MakeupForSessionID, &session.SessionTemplateID, &session.CreatedAt, &session.UpdatedAt, &session.SessionParentID,
 		)
 		if err != nil {
 			return nil, err
@@ -274,6 +275,8 @@ func (r *StudentRepository) GetStudentRatings(ctx context.Context, studentID uui
 	SELECT ss.session_id, ss.student_id, s.start_datetime, sr.level, sr.category, sr.description
 	FROM session_student ss
 	JOIN session s ON ss.session_id = s.id
+	JOIN session_parent sp ON s.session_parent_id = sp.id
+	JOIN therapist th ON th.id = sp.therapist_id
 	LEFT JOIN session_rating sr ON ss.id = sr.session_student_id
 	WHERE ss.student_id = $1`
 
@@ -282,17 +285,17 @@ func (r *StudentRepository) GetStudentRatings(ctx context.Context, studentID uui
 	argCount := 2
 
 	if filter != nil {
-		// Date filtering
+		// Date filtering, months are the therapist's
 		if filter.Month != nil && filter.Year != nil {
-			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime) = $%d AND EXTRACT(YEAR FROM s.start_datetime) = $%d", argCount, argCount+1))
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d AND EXTRACT(YEAR FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d", argCount, argCount+1))
 			args = append(args, *filter.Month, *filter.Year)
 			argCount += 2
 		} else if filter.Year != nil {
-			conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM s.start_datetime) = $%d", argCount))
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d", argCount))
 			args = append(args, *filter.Year)
 			argCount++
 		} else if filter.Month != nil {
-			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime) = $%d", argCount))
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(MONTH FROM s.start_datetime AT TIME ZONE th.time_zone) = $%d", argCount))
 			args = append(args, *filter.Month)
 			argCount++
 		}
@@ -9,6 +9,7 @@ import (
 	"specialstandard/internal/utils"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -28,6 +29,8 @@ func (r *TherapistRepository) GetTherapistByID(ctx context.Context, therapistID
 			t.active, 
 			t.schools, 
 			t.district_id, 
+			t.role,
+			t.time_zone,
 			d.name as district_name,
 			t.created_at, 
 			t.updated_at
@@ -69,6 +72,8 @@ func (r *TherapistRepository) GetTherapistByID(ctx context.Context, therapistID
 		&therapist.Active,
 		&schools,
 		&therapist.DistrictID,
+		&therapist.Role,
+		&therapist.TimeZone,
 		&therapist.DistrictName,
 		&therapist.CreatedAt,
 		&therapist.UpdatedAt,
@@ -105,7 +110,7 @@ func (r *TherapistRepository) GetTherapistByID(ctx context.Context, therapistID
 
 func (r *TherapistRepository) GetTherapists(ctx context.Context, pagination utils.Pagination) ([]models.Therapist, error) {
 	query := `
-	SELECT t.id, t.first_name, t.last_name, t.email, t.active, t.schools, t.district_id, t.created_at, t.updated_at
+	SELECT t.id, t.first_name, t.last_name, t.email, t.active, t.schools, t.district_id, t.role, t.time_zone, t.created_at, t.updated_at
 	FROM therapist t
 	ORDER BY first_name ASC, last_name ASC
 	LIMIT $1 OFFSET $2`
@@ -133,11 +138,11 @@ func (r *TherapistRepository) CreateTherapist(ctx context.Context, input *models
 	therapist := &models.Therapist{}
 
 	query := `
-        INSERT INTO therapist (id, first_name, last_name, schools, district_id, email)
-        VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id, first_name, last_name, schools, district_id, email, active, created_at, updated_At`
+        INSERT INTO therapist (id, first_name, last_name, schools, district_id, email, time_zone)
+        VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, 'UTC'))
+        RETURNING id, first_name, last_name, schools, district_id, role, email, active, time_zone, created_at, updated_At`
 
-	row := r.db.QueryRow(ctx, query, input.ID, input.FirstName, input.LastName, input.Schools, input.DistrictID, input.Email)
+	row := r.db.QueryRow(ctx, query, input.ID, input.FirstName, input.LastName, input.Schools, input.DistrictID, input.Email, input.TimeZone)
 
 	// Scan into the therapist object
 	if err := row.Scan(
@@ -146,8 +151,10 @@ func (r *TherapistRepository) CreateTherapist(ctx context.Context, input *models
 		&therapist.LastName,
 		&therapist.Schools,
 		&therapist.DistrictID,
+		&therapist.Role,
 		&therapist.Email,
 		&therapist.Active,
+		&therapist.TimeZone,
 		&therapist.CreatedAt,
 		&therapist.UpdatedAt,
 	); err != nil {
@@ -169,7 +176,7 @@ func (r *TherapistRepository) DeleteTherapist(ctx context.Context, therapistID s
 
 	// We will handle in the handler!
 	if err != nil {
-		return err
+		return signedNoteError(err, "The therapist has signed session notes and cannot be deleted")
 	}
 
 	return nil
@@ -218,6 +225,12 @@ func (r *TherapistRepository) PatchTherapist(ctx context.Context, therapistID st
 		argCount++
 	}
 
+	if updatedValue.TimeZone != nil {
+		updates = append(updates, fmt.Sprintf("time_zone = $%d", argCount))
+		args = append(args, *updatedValue.TimeZone)
+		argCount++
+	}
+
 	if len(updates) == 0 {
 		return nil, errs.BadRequest("No fields given to update.")
 	}
@@ -226,7 +239,7 @@ func (r *TherapistRepository) PatchTherapist(ctx context.Context, therapistID st
 	query += fmt.Sprintf(" WHERE id = $%d", argCount)
 	args = append(args, therapistID)
 
-	query += " RETURNING id, first_name, last_name, email, schools, district_id, active, created_at, updated_At"
+	query += " RETURNING id, first_name, last_name, email, schools, district_id, role, active, time_zone, created_at, updated_At"
 
 	rows, err := r.db.Query(ctx, query, args...)
 
@@ -245,6 +258,75 @@ func (r *TherapistRepository) PatchTherapist(ctx context.Context, therapistID st
 	return &therapist, nil
 }
 
+// UpdateTherapistRole is kept apart from PatchTherapist so that a therapist can never
+// grant themselves a role through their own profile
+func (r *TherapistRepository) UpdateTherapistRole(ctx context.Context, therapistID uuid.UUID, role string) (*models.Therapist, error) {
+	query := `
+	UPDATE therapist
+	SET role = $1, updated_at = now()
+	WHERE id = $2
+	RETURNING id, first_name, last_name, email, schools, district_id, role, active, time_zone, created_at, updated_at`
+
+	rows, err := r.db.Query(ctx, query, role, therapistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	therapist, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[models.Therapist])
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errs.NotFound("Therapist not found with given ID")
+		}
+		return nil, err
+	}
+
+	return &therapist, nil
+}
+
+func (r *TherapistRepository) GetDelegates(ctx context.Context, therapistID uuid.UUID) ([]models.TherapistDelegate, error) {
+	query := `
+	SELECT therapist_id, delegate_id, created_at
+	FROM therapist_delegate
+	WHERE therapist_id = $1
+	ORDER BY created_at ASC`
+
+	rows, err := r.db.Query(ctx, query, therapistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.TherapistDelegate])
+}
+
+func (r *TherapistRepository) AddDelegate(ctx context.Context, therapistID, delegateID uuid.UUID) (*models.TherapistDelegate, error) {
+	query := `
+	INSERT INTO therapist_delegate (therapist_id, delegate_id)
+	VALUES ($1, $2)
+	ON CONFLICT (therapist_id, delegate_id) DO UPDATE SET therapist_id = EXCLUDED.therapist_id
+	RETURNING therapist_id, delegate_id, created_at`
+
+	var delegate models.TherapistDelegate
+	err := r.db.QueryRow(ctx, query, therapistID, delegateID).Scan(
+		&delegate.TherapistID,
+		&delegate.DelegateID,
+		&delegate.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &delegate, nil
+}
+
+func (r *TherapistRepository) RemoveDelegate(ctx context.Context, therapistID, delegateID uuid.UUID) error {
+	query := `DELETE FROM therapist_delegate WHERE therapist_id = $1 AND delegate_id = $2`
+
+	_, err := r.db.Exec(ctx, query, therapistID, delegateID)
+	return err
+}
+
 func NewTherapistRepository(db *pgxpool.Pool) *TherapistRepository {
 	return &TherapistRepository{
 		db,
@@ -2,6 +2,11 @@ package schema
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"specialstandard/internal/errs"
 	"specialstandard/internal/models"
 	"time"
@@ -10,22 +15,45 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Used when the caller does not set a limit on the code
+const defaultMaxCodeAttempts = 5
+
 type VerificationRepository struct {
-	db *pgxpool.Pool
+	db  *pgxpool.Pool
+	key []byte
+}
+
+// NewVerificationRepository stores codes hashed with the key, see config.Verification
+func NewVerificationRepository(db *pgxpool.Pool, key string) *VerificationRepository {
+	return &VerificationRepository{db: db, key: []byte(key)}
 }
 
-func NewVerificationRepository(db *pgxpool.Pool) *VerificationRepository {
-	return &VerificationRepository{db: db}
+// hashVerificationCode keys the hash with a server secret, as there are few enough codes
+// to try them all against a plain hash, and salts it with the user so equal codes of
+// different users do not share a hash
+func (r *VerificationRepository) hashVerificationCode(userID, code string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(userID + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
 func (r *VerificationRepository) CreateVerificationCode(ctx context.Context, code models.VerificationCode) error {
+	if len(r.key) == 0 {
+		return errs.InternalServerError("Verification codes are not configured")
+	}
+
+	maxAttempts := code.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxCodeAttempts
+	}
+
 	// When creating, 'used' defaults to false
 	query := `
-		INSERT INTO verification_codes (user_id, code, expires_at, created_at, used)
-		VALUES ($1, $2, $3, $4, false)
+		INSERT INTO verification_codes (user_id, code_hash, expires_at, created_at, used, attempts, max_attempts)
+		VALUES ($1, $2, $3, $4, false, 0, $5)
 	`
 
-	_, err := r.db.Exec(ctx, query, code.UserID, code.Code, code.ExpiresAt, code.CreatedAt)
+	_, err := r.db.Exec(ctx, query, code.UserID, r.hashVerificationCode(code.UserID, code.Code), code.ExpiresAt, code.CreatedAt, maxAttempts)
 	if err != nil {
 		return errs.InternalServerError("Failed to create verification code")
 	}
@@ -33,6 +61,8 @@ func (r *VerificationRepository) CreateVerificationCode(ctx context.Context, cod
 	return nil
 }
 
+// VerifyCode checks the code against the user's latest active code. Every wrong guess
+// counts against that code, which is invalidated once it runs out of attempts.
 func (r *VerificationRepository) VerifyCode(ctx context.Context, userID, code string) (bool, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -41,25 +71,28 @@ func (r *VerificationRepository) VerifyCode(ctx context.Context, userID, code st
 	// i know this is an ugly ass line but i just want to pass the linter
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	// Check if code exists and is valid - 'used' is boolean
 	var verificationCode models.VerificationCode
 	query := `
-		SELECT id, user_id, code, expires_at, used
+		SELECT id, user_id, code_hash, expires_at, used, attempts, max_attempts
 		FROM verification_codes
-		WHERE user_id = $1 AND code = $2 AND used = false
+		WHERE user_id = $1 AND used = false
+		ORDER BY created_at DESC
+		LIMIT 1
 		FOR UPDATE
 	`
 
-	err = tx.QueryRow(ctx, query, userID, code).Scan(
+	err = tx.QueryRow(ctx, query, userID).Scan(
 		&verificationCode.ID,
 		&verificationCode.UserID,
-		&verificationCode.Code,
+		&verificationCode.CodeHash,
 		&verificationCode.ExpiresAt,
-		&verificationCode.Used, // Now it's a boolean
+		&verificationCode.Used,
+		&verificationCode.Attempts,
+		&verificationCode.MaxAttempts,
 	)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
 		}
 		return false, err
@@ -70,6 +103,22 @@ func (r *VerificationRepository) VerifyCode(ctx context.Context, userID, code st
 		return false, nil
 	}
 
+	hash := r.hashVerificationCode(userID, code)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(verificationCode.CodeHash)) != 1 {
+		failQuery := `
+			UPDATE verification_codes
+			SET attempts = attempts + 1,
+			    used = attempts + 1 >= max_attempts
+			WHERE id = $1
+		`
+
+		if _, err = tx.Exec(ctx, failQuery, verificationCode.ID); err != nil {
+			return false, err
+		}
+
+		return false, tx.Commit(ctx)
+	}
+
 	// Mark code as used - set boolean to true
 	updateQuery := `
 		UPDATE verification_codes
@@ -106,3 +155,13 @@ func (r *VerificationRepository) InvalidatePreviousCodes(ctx context.Context, us
 
 	return nil
 }
+
+// DeleteExpiredCodes removes the codes that expired before the time, used or not, and
+// returns how many there were
+func (r *VerificationRepository) DeleteExpiredCodes(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM verification_codes WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,112 @@
+package schema_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"specialstandard/internal/models"
+	"specialstandard/internal/storage/postgres/schema"
+	"specialstandard/internal/storage/postgres/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationRepository_VerifyCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewVerificationRepository(testDB, "test-verification-key")
+	ctx := context.Background()
+
+	userID := uuid.NewString()
+	err := repo.CreateVerificationCode(ctx, models.VerificationCode{
+		UserID:      userID,
+		Code:        "123456",
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+		CreatedAt:   time.Now(),
+		MaxAttempts: 3,
+	})
+	require.NoError(t, err)
+
+	// The code itself is never stored
+	var stored string
+	err = testDB.QueryRow(ctx, `SELECT code_hash FROM verification_codes WHERE user_id = $1`, userID).Scan(&stored)
+	require.NoError(t, err)
+	assert.NotContains(t, stored, "123456")
+
+	// The hash is keyed, so it cannot be checked without the key
+	plain := sha256.Sum256([]byte(userID + ":123456"))
+	assert.NotEqual(t, hex.EncodeToString(plain[:]), stored)
+	valid, err := schema.NewVerificationRepository(testDB, "another-key").VerifyCode(ctx, userID, "123456")
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	valid, err = repo.VerifyCode(ctx, userID, "000000")
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	valid, err = repo.VerifyCode(ctx, userID, "123456")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Used codes cannot be replayed
+	valid, err = repo.VerifyCode(ctx, userID, "123456")
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	// A code is invalidated after running out of attempts, even for the right guess
+	err = repo.CreateVerificationCode(ctx, models.VerificationCode{
+		UserID:      userID,
+		Code:        "654321",
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+		CreatedAt:   time.Now(),
+		MaxAttempts: 3,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		valid, err = repo.VerifyCode(ctx, userID, "111111")
+		require.NoError(t, err)
+		assert.False(t, valid)
+	}
+
+	valid, err = repo.VerifyCode(ctx, userID, "654321")
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestVerificationRepository_DeleteExpiredCodes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database test in short mode")
+	}
+
+	testDB := testutil.SetupTestWithCleanup(t)
+	repo := schema.NewVerificationRepository(testDB, "test-verification-key")
+	ctx := context.Background()
+
+	for _, expiresAt := range []time.Time{time.Now().Add(-time.Hour), time.Now().Add(10 * time.Minute)} {
+		err := repo.CreateVerificationCode(ctx, models.VerificationCode{
+			UserID:      uuid.NewString(),
+			Code:        "123456",
+			ExpiresAt:   expiresAt,
+			CreatedAt:   time.Now(),
+			MaxAttempts: 3,
+		})
+		require.NoError(t, err)
+	}
+
+	deleted, err := repo.DeleteExpiredCodes(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	var remaining int
+	require.NoError(t, testDB.QueryRow(ctx, `SELECT count(*) FROM verification_codes`).Scan(&remaining))
+	assert.Equal(t, 1, remaining)
+}
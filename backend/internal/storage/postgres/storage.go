@@ -84,10 +84,10 @@ func ConnectDatabase(ctx context.Context, dbConfig config.DB) (*pgxpool.Pool, er
 	return conn, nil
 }
 
-func NewRepository(ctx context.Context, dbConfig config.DB) *storage.Repository {
+func NewRepository(ctx context.Context, dbConfig config.DB, verification config.Verification) *storage.Repository {
 	db, err := ConnectDatabase(ctx, dbConfig)
 	if err != nil {
 		log.Fatalf("Failed to connect to the database: %v", err)
 	}
-	return storage.NewRepository(db)
+	return storage.NewRepository(db, verification)
 }
@@ -174,7 +174,173 @@ func createAllTables(pool *pgxpool.Pool) error {
 			created_at TIMESTAMPTZ DEFAULT now(),
 			updated_at TIMESTAMPTZ DEFAULT now(),
 			schools INTEGER[],
-			district_id INTEGER REFERENCES district(id)
+			district_id INTEGER REFERENCES district(id),
+			role TEXT NOT NULL DEFAULT 'therapist' CHECK (role IN ('therapist', 'district_admin', 'system_admin')),
+			time_zone TEXT NOT NULL DEFAULT 'UTC',
+			CHECK (role <> 'district_admin' OR district_id IS NOT NULL)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS therapist_delegate (
+			therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+			delegate_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (therapist_id, delegate_id),
+			CHECK (therapist_id <> delegate_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS auth_attempt (
+			key TEXT PRIMARY KEY,
+			failures INTEGER NOT NULL DEFAULT 0,
+			last_failure_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			locked_until TIMESTAMPTZ
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS verification_codes (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			user_id UUID NOT NULL,
+			code_hash TEXT NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT now(),
+			expires_at TIMESTAMPTZ NOT NULL,
+			used BOOLEAN DEFAULT false,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			max_attempts INTEGER NOT NULL DEFAULT 5
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS email_outbox (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			recipients TEXT[] NOT NULL,
+			subject TEXT NOT NULL,
+			html_body TEXT,
+			text_body TEXT,
+			template TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+			attempts INTEGER NOT NULL DEFAULT 0,
+			max_attempts INTEGER NOT NULL DEFAULT 5,
+			last_error TEXT,
+			next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			provider_message_id TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			sent_at TIMESTAMPTZ
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS job_run (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			name TEXT NOT NULL,
+			payload JSONB NOT NULL DEFAULT '{}',
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'succeeded', 'failed')),
+			attempts INTEGER NOT NULL DEFAULT 0,
+			max_attempts INTEGER NOT NULL DEFAULT 3 CHECK (max_attempts > 0),
+			run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			dedupe_key TEXT UNIQUE,
+			locked_by TEXT,
+			locked_until TIMESTAMPTZ,
+			last_error TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			started_at TIMESTAMPTZ,
+			finished_at TIMESTAMPTZ
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS api_key (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			name TEXT NOT NULL,
+			prefix TEXT NOT NULL,
+			key_hash TEXT NOT NULL UNIQUE,
+			scopes TEXT[] NOT NULL,
+			district_id INTEGER REFERENCES district(id) ON DELETE CASCADE,
+			created_by UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			last_used_at TIMESTAMPTZ,
+			expires_at TIMESTAMPTZ,
+			revoked_at TIMESTAMPTZ
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS therapist_mfa (
+			therapist_id UUID PRIMARY KEY REFERENCES therapist(id) ON DELETE CASCADE,
+			secret_sealed TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT false,
+			last_used_step BIGINT NOT NULL DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			confirmed_at TIMESTAMPTZ
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS mfa_recovery_code (
+			id SERIAL PRIMARY KEY,
+			therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+			code_hash TEXT NOT NULL,
+			used_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS mfa_challenge (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+			token_hash TEXT NOT NULL UNIQUE,
+			refresh_token TEXT NOT NULL,
+			remember_me BOOLEAN NOT NULL DEFAULT false,
+			expires_at TIMESTAMPTZ NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS mfa_verified_session (
+			session_id UUID PRIMARY KEY,
+			therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS therapist_invitation (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			email VARCHAR(255) NOT NULL,
+			first_name VARCHAR(255),
+			last_name VARCHAR(255),
+			district_id INTEGER NOT NULL REFERENCES district(id) ON DELETE CASCADE,
+			schools INTEGER[] NOT NULL DEFAULT '{}',
+			invited_by UUID REFERENCES therapist(id) ON DELETE SET NULL,
+			expires_at TIMESTAMPTZ NOT NULL,
+			accepted_at TIMESTAMPTZ,
+			accepted_by UUID REFERENCES therapist(id) ON DELETE SET NULL,
+			revoked_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS calendar_feed (
+			therapist_id UUID PRIMARY KEY REFERENCES therapist(id) ON DELETE CASCADE,
+			token_hash TEXT NOT NULL UNIQUE,
+			include_student_names BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS notification_preference (
+			therapist_id UUID PRIMARY KEY REFERENCES therapist(id) ON DELETE CASCADE,
+			channel TEXT NOT NULL DEFAULT 'none' CHECK (channel IN ('email', 'none')),
+			digest_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			digest_time TIME NOT NULL DEFAULT '07:00',
+			reminder_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			reminder_lead_minutes INTEGER NOT NULL DEFAULT 15 CHECK (reminder_lead_minutes BETWEEN 1 AND 1440),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS notification_sent (
+			dedupe_key TEXT PRIMARY KEY,
+			therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+			kind TEXT NOT NULL CHECK (kind IN ('digest', 'reminder')),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS school_calendar_event (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			district_id INTEGER NOT NULL REFERENCES district(id) ON DELETE CASCADE,
+			school_id INTEGER REFERENCES school(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			kind VARCHAR(32) NOT NULL CHECK (kind IN ('holiday', 'break', 'closure', 'early_release')),
+			start_date DATE NOT NULL,
+			end_date DATE NOT NULL,
+			dismissal_time TIME,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			CHECK (end_date >= start_date),
+			CHECK (dismissal_time IS NULL OR kind = 'early_release')
 		)`,
 
 		`CREATE TABLE IF NOT EXISTS theme (
@@ -199,6 +365,30 @@ func createAllTables(pool *pgxpool.Pool) error {
 			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		)`,
 
+		`CREATE TABLE IF NOT EXISTS service_mandate (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			student_id UUID NOT NULL REFERENCES student(id) ON DELETE CASCADE,
+			service VARCHAR(255) NOT NULL,
+			minutes INTEGER NOT NULL CHECK (minutes > 0),
+			frequency VARCHAR(16) NOT NULL CHECK (frequency IN ('weekly', 'monthly')),
+			setting VARCHAR(16) NOT NULL CHECK (setting IN ('individual', 'group')),
+			start_date DATE NOT NULL,
+			end_date DATE NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			CHECK (end_date >= start_date)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS student_archive (
+			id UUID PRIMARY KEY,
+			former_therapist_id UUID NOT NULL,
+			school_id INTEGER REFERENCES school(id) ON DELETE SET NULL,
+			first_name VARCHAR(100) NOT NULL,
+			last_name VARCHAR(100) NOT NULL,
+			data JSONB NOT NULL,
+			archived_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+
 		`CREATE TABLE IF NOT EXISTS session_parent (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     start_date DATE NOT NULL,
@@ -206,6 +396,9 @@ func createAllTables(pool *pgxpool.Pool) error {
     therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE RESTRICT,
     days SMALLINT[],
     every_n_weeks INT,
+    rrule TEXT,
+    extra_dates DATE[] NOT NULL DEFAULT '{}',
+    exception_dates DATE[] NOT NULL DEFAULT '{}',
     created_at TIMESTAMPTZ DEFAULT now(),
     updated_at TIMESTAMPTZ DEFAULT now(),
     CHECK (end_date >= start_date)
@@ -316,7 +509,91 @@ func createAllTables(pool *pgxpool.Pool) error {
 
 		`ALTER TABLE session
 			ADD COLUMN session_name VARCHAR(255) NOT NULL,
-			ADD COLUMN location VARCHAR(255);
+			ADD COLUMN location VARCHAR(255),
+			ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'scheduled'
+				CHECK (status IN ('scheduled', 'completed', 'cancelled_by_therapist', 'cancelled_by_school', 'student_absent', 'makeup')),
+			ADD COLUMN status_reason TEXT,
+			ADD COLUMN makeup_for_session_id UUID REFERENCES session(id) ON DELETE SET NULL;
+		`,
+
+		`CREATE TABLE IF NOT EXISTS makeup_ledger (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			student_id UUID NOT NULL REFERENCES student(id) ON DELETE CASCADE,
+			session_id UUID NOT NULL REFERENCES session(id) ON DELETE CASCADE,
+			kind VARCHAR(16) NOT NULL CHECK (kind IN ('missed', 'made_up')),
+			minutes INTEGER NOT NULL,
+			reason VARCHAR(32),
+			makeup_for_session_id UUID REFERENCES session(id) ON DELETE CASCADE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			UNIQUE (student_id, session_id),
+			CHECK ((kind = 'missed' AND minutes > 0) OR (kind = 'made_up' AND minutes < 0))
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS session_note (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			session_student_id INT NOT NULL UNIQUE REFERENCES session_student(id) ON DELETE CASCADE,
+			subjective TEXT,
+			objective TEXT,
+			assessment TEXT,
+			plan TEXT,
+			custom_fields JSONB NOT NULL DEFAULT '{}',
+			status VARCHAR(16) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'signed')),
+			signed_at TIMESTAMPTZ,
+			signed_by UUID REFERENCES therapist(id) ON DELETE SET NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			CONSTRAINT session_note_signature CHECK ((status = 'signed') = (signed_at IS NOT NULL))
+		);
+
+		CREATE OR REPLACE FUNCTION lock_signed_session_note()
+		RETURNS TRIGGER AS $$
+		DECLARE
+			unsigned session_note;
+		BEGIN
+			IF OLD.status = 'signed' THEN
+				IF TG_OP = 'DELETE' THEN
+					RAISE EXCEPTION 'session note % is signed and cannot be deleted', OLD.id
+						USING ERRCODE = 'restrict_violation';
+				END IF;
+				unsigned := OLD;
+				unsigned.signed_by := NULL;
+				IF NEW IS NOT DISTINCT FROM unsigned THEN
+					RETURN NEW;
+				END IF;
+				RAISE EXCEPTION 'session note % is signed and cannot be changed', OLD.id
+					USING ERRCODE = 'restrict_violation';
+			END IF;
+			IF TG_OP = 'DELETE' THEN
+				RETURN OLD;
+			END IF;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		CREATE TRIGGER lock_signed_session_note BEFORE UPDATE OR DELETE ON session_note
+			FOR EACH ROW EXECUTE FUNCTION lock_signed_session_note();`,
+
+		`CREATE TABLE IF NOT EXISTS session_template (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			duration_minutes INTEGER NOT NULL CHECK (duration_minutes > 0 AND duration_minutes <= 480),
+			location VARCHAR(255),
+			game_content_filter JSONB NOT NULL DEFAULT '{}',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS session_template_resource (
+			session_template_id UUID NOT NULL REFERENCES session_template(id) ON DELETE CASCADE,
+			resource_id UUID NOT NULL REFERENCES resource(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			PRIMARY KEY (session_template_id, resource_id)
+		)`,
+
+		`ALTER TABLE session
+			ADD COLUMN session_template_id UUID REFERENCES session_template(id) ON DELETE SET NULL;
 		`,
 
 		`CREATE TYPE exercise_type AS ENUM ('game', 'pdf');
@@ -397,11 +674,32 @@ func (db *SharedTestDB) CleanupTestData(t testing.TB) {
 		TRUNCATE TABLE 
 			session_rating,
 			session_resource,
+			makeup_ledger,
+			session_note,
 			session_student,
+			session_template_resource,
 			resource,
+			service_mandate,
 			student,
+			student_archive,
 			session,
+			session_template,
 			theme,
+			therapist_delegate,
+			auth_attempt,
+			verification_codes,
+			email_outbox,
+			job_run,
+			api_key,
+			therapist_mfa,
+			mfa_recovery_code,
+			mfa_challenge,
+			mfa_verified_session,
+			therapist_invitation,
+			calendar_feed,
+			notification_preference,
+			notification_sent,
+			school_calendar_event,
 			therapist,
 			school,
 			district
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"specialstandard/internal/config"
 	"specialstandard/internal/models"
 	"specialstandard/internal/storage/dbinterface"
 	"specialstandard/internal/storage/postgres/schema"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -23,15 +25,24 @@ type SessionRepository interface {
 	DeleteRecurringSessions(ctx context.Context, id uuid.UUID) error
 	PostSession(ctx context.Context, q dbinterface.Queryable, session *models.PostSessionInput) (*[]models.Session, error)
 	PatchSession(ctx context.Context, id uuid.UUID, session *models.PatchSessionInput) (*models.Session, error)
+	PatchRecurringSessions(ctx context.Context, id uuid.UUID, scope string, session *models.PatchSessionInput) (*[]models.Session, error)
+	UpdateSessionStatus(ctx context.Context, id uuid.UUID, status string, reason *string) (*models.Session, error)
 	GetSessionStudents(ctx context.Context, sessionID uuid.UUID, pagination utils.Pagination, therapistId uuid.UUID) ([]models.SessionStudentsOutput, error)
+	GetSessionConflicts(ctx context.Context, therapistID uuid.UUID, from, to time.Time) ([]models.SessionConflict, error)
+	FindConflicts(ctx context.Context, check *models.ConflictCheck) ([]models.SessionConflict, error)
+	GetConflictReport(ctx context.Context, therapistID uuid.UUID, from, to time.Time) ([]models.SessionOverlap, error)
+	GetTherapistLocation(ctx context.Context, therapistID uuid.UUID) (*time.Location, error)
+	GetOwedMinutes(ctx context.Context, sessionID uuid.UUID) ([]models.OwedMinutes, error)
 
 	GetDB() *pgxpool.Pool
 }
 
 type SessionStudentRepository interface {
 	CreateSessionStudent(ctx context.Context, q dbinterface.Queryable, input *models.CreateSessionStudentInput) (*[]models.SessionStudent, error)
+	FindStudentConflicts(ctx context.Context, sessionIDs, studentIDs []uuid.UUID) ([]models.SessionConflict, error)
 	DeleteSessionStudent(ctx context.Context, input *models.DeleteSessionStudentInput) error
 	RateStudentSession(ctx context.Context, input *models.PatchSessionStudentInput) (*models.SessionStudent, []models.SessionRating, error)
+	SaveAttendance(ctx context.Context, sessionID uuid.UUID, input *models.SessionAttendanceInput) ([]models.SessionStudentsOutput, error)
 	GetStudentAttendance(ctx context.Context, params models.GetStudentAttendanceParams) (*int, *int, error)
 	GetDB() *pgxpool.Pool
 }
@@ -45,6 +56,7 @@ type StudentRepository interface {
 	GetStudentSessions(ctx context.Context, studentID uuid.UUID, pagination utils.Pagination, filter *models.GetStudentSessionsRepositoryRequest) ([]models.StudentSessionsOutput, error)
 	GetStudentRatings(ctx context.Context, studentID uuid.UUID, pagination utils.Pagination, filter *models.GetStudentSessionsRatingsRequest) ([]models.StudentSessionsWithRatingsOutput, error)
 	PromoteStudents(ctx context.Context, input models.PromoteStudentsInput) error
+	GetMakeupLedger(ctx context.Context, studentID uuid.UUID) ([]models.MakeupEntry, error)
 }
 
 type ThemeRepository interface {
@@ -61,6 +73,10 @@ type TherapistRepository interface {
 	CreateTherapist(ctx context.Context, therapist *models.CreateTherapistInput) (*models.Therapist, error)
 	DeleteTherapist(ctx context.Context, therapistID string) error
 	PatchTherapist(ctx context.Context, therapistID string, updatedValue *models.UpdateTherapist) (*models.Therapist, error)
+	GetDelegates(ctx context.Context, therapistID uuid.UUID) ([]models.TherapistDelegate, error)
+	AddDelegate(ctx context.Context, therapistID, delegateID uuid.UUID) (*models.TherapistDelegate, error)
+	RemoveDelegate(ctx context.Context, therapistID, delegateID uuid.UUID) error
+	UpdateTherapistRole(ctx context.Context, therapistID uuid.UUID, role string) (*models.Therapist, error)
 }
 
 type ResourceRepository interface {
@@ -73,6 +89,7 @@ type ResourceRepository interface {
 
 type SessionResourceRepository interface {
 	PostSessionResource(ctx context.Context, sessionResource models.CreateSessionResource) (*models.SessionResource, error)
+	AddSessionResources(ctx context.Context, q dbinterface.Queryable, sessionIDs, resourceIDs []uuid.UUID) error
 	DeleteSessionResource(ctx context.Context, sessionResource models.DeleteSessionResource) error
 	GetResourcesBySessionID(ctx context.Context, sessionID uuid.UUID, pagination utils.Pagination) ([]models.Resource, error)
 }
@@ -89,6 +106,10 @@ type GameResultRepository interface {
 type DistrictRepository interface {
 	GetDistricts(ctx context.Context) ([]models.District, error)
 	GetDistrictByID(ctx context.Context, id int) (*models.District, error)
+	GetDistrictTherapists(ctx context.Context, districtID int, pagination utils.Pagination) ([]models.Therapist, error)
+	GetDistrictStudents(ctx context.Context, districtID int, schoolID *int, pagination utils.Pagination) ([]models.Student, error)
+	GetDistrictSessions(ctx context.Context, districtID int, filter *models.GetDistrictSessionsQuery, pagination utils.Pagination) ([]models.Session, error)
+	GetDistrictAttendance(ctx context.Context, districtID int, dateFrom, dateTo time.Time) ([]models.StudentAttendance, error)
 }
 
 type SchoolRepository interface {
@@ -101,6 +122,152 @@ type VerificationRepository interface {
 	CreateVerificationCode(ctx context.Context, code models.VerificationCode) error
 	VerifyCode(ctx context.Context, userID, code string) (bool, error)
 	InvalidatePreviousCodes(ctx context.Context, userID string) error
+	DeleteExpiredCodes(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AttemptRepository persists failed authentication attempts for lockouts
+type AttemptRepository interface {
+	GetLockedUntil(ctx context.Context, key string) (*time.Time, error)
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	LockUntil(ctx context.Context, key string, until time.Time) error
+	ResetAttempts(ctx context.Context, key string) error
+}
+
+// EmailOutboxRepository persists rendered emails until they are delivered
+type EmailOutboxRepository interface {
+	EnqueueEmail(ctx context.Context, input *models.CreateOutboxEmailInput) (*models.OutboxEmail, error)
+	ClaimDueEmails(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEmail, error)
+	MarkEmailSent(ctx context.Context, id uuid.UUID, providerMessageID string) error
+	MarkEmailFailed(ctx context.Context, id uuid.UUID, lastError string, retryAt *time.Time) error
+	DeleteFinishedEmails(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AccountRepository exports and removes everything belonging to a therapist account
+type AccountRepository interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	ExportTherapistData(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) (*models.TherapistExport, error)
+	TransferCaseload(ctx context.Context, q dbinterface.Queryable, fromID, toID uuid.UUID) (int, error)
+	ArchiveStudents(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) (int, error)
+	DeleteTherapistAccount(ctx context.Context, q dbinterface.Queryable, therapistID uuid.UUID) error
+}
+
+// APIKeyRepository stores API keys for server-to-server integrations
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, createdBy uuid.UUID, prefix, keyHash string, input *models.CreateAPIKeyInput) (*models.APIKey, error)
+	GetAPIKeys(ctx context.Context, createdBy uuid.UUID) ([]models.APIKey, error)
+	GetAPIKeyByID(ctx context.Context, id, createdBy uuid.UUID) (*models.APIKey, error)
+	UpdateAPIKey(ctx context.Context, id, createdBy uuid.UUID, input *models.UpdateAPIKeyInput) (*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id, createdBy uuid.UUID) error
+	AuthenticateAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+}
+
+// MFARepository stores authenticator app factors, recovery codes and logins waiting for
+// their second factor
+type MFARepository interface {
+	GetTOTPFactor(ctx context.Context, therapistID uuid.UUID) (*models.TOTPFactor, error)
+	SavePendingTOTP(ctx context.Context, therapistID uuid.UUID, secretSealed string) error
+	EnableTOTP(ctx context.Context, therapistID uuid.UUID, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, therapistID uuid.UUID, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, therapistID uuid.UUID, codeHash string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, therapistID uuid.UUID, codeHashes []string) error
+	DeleteTOTP(ctx context.Context, therapistID uuid.UUID) error
+	CreateMFAChallenge(ctx context.Context, therapistID uuid.UUID, tokenHash, refreshToken string, rememberMe bool, expiresAt time.Time) error
+	GetMFAChallenge(ctx context.Context, tokenHash string) (*models.MFAChallenge, error)
+	DeleteMFAChallenge(ctx context.Context, id uuid.UUID) (bool, error)
+	RecordMFASession(ctx context.Context, therapistID, sessionID uuid.UUID) error
+	SessionPassedMFA(ctx context.Context, therapistID, sessionID uuid.UUID) (bool, error)
+}
+
+// InvitationRepository stores invitations of therapists into a district
+type InvitationRepository interface {
+	CreateInvitation(ctx context.Context, districtID int, invitedBy uuid.UUID, input *models.CreateInvitationInput, expiresAt time.Time) (*models.Invitation, error)
+	GetPendingInvitations(ctx context.Context, districtID int) ([]models.Invitation, error)
+	GetInvitationPreview(ctx context.Context, id uuid.UUID) (*models.InvitationPreview, error)
+	RevokeInvitation(ctx context.Context, districtID int, id uuid.UUID) error
+	Begin(ctx context.Context) (pgx.Tx, error)
+	ClaimInvitation(ctx context.Context, q dbinterface.Queryable, id uuid.UUID) (*models.Invitation, error)
+	AcceptInvitation(ctx context.Context, q dbinterface.Queryable, id uuid.UUID, input *models.CreateTherapistInput) (*models.Therapist, error)
+}
+
+// SchoolCalendarRepository stores the holidays, breaks, closures and early releases of
+// districts and their schools
+type SchoolCalendarRepository interface {
+	GetCalendarEvents(ctx context.Context, districtID int, query *models.GetSchoolCalendarQuery) ([]models.SchoolCalendarEvent, error)
+	GetCalendarEvent(ctx context.Context, districtID int, id uuid.UUID) (*models.SchoolCalendarEvent, error)
+	CreateCalendarEvent(ctx context.Context, event *models.SchoolCalendarEvent) (*models.SchoolCalendarEvent, error)
+	UpdateCalendarEvent(ctx context.Context, event *models.SchoolCalendarEvent) (*models.SchoolCalendarEvent, error)
+	DeleteCalendarEvent(ctx context.Context, districtID int, id uuid.UUID) error
+}
+
+// ServiceMandateRepository stores the service minutes students' IEPs mandate and gathers
+// what was delivered against them
+type ServiceMandateRepository interface {
+	GetMandates(ctx context.Context, studentID uuid.UUID) ([]models.ServiceMandate, error)
+	GetMandate(ctx context.Context, studentID, id uuid.UUID) (*models.ServiceMandate, error)
+	CreateMandate(ctx context.Context, mandate *models.ServiceMandate) (*models.ServiceMandate, error)
+	UpdateMandate(ctx context.Context, mandate *models.ServiceMandate) (*models.ServiceMandate, error)
+	DeleteMandate(ctx context.Context, studentID, id uuid.UUID) error
+	GetServiceRecords(ctx context.Context, query *models.ServiceRecordQuery) ([]models.ServiceRecord, error)
+}
+
+// SessionNoteRepository stores the SOAP notes on sessions' students, which are locked once
+// signed
+type SessionNoteRepository interface {
+	GetNote(ctx context.Context, sessionID, studentID uuid.UUID) (*models.SessionNote, error)
+	SaveNote(ctx context.Context, sessionID, studentID uuid.UUID, input *models.SaveSessionNoteInput) (*models.SessionNote, error)
+	SignNote(ctx context.Context, sessionID, studentID, signedBy uuid.UUID) (*models.SessionNote, error)
+	DeleteNote(ctx context.Context, sessionID, studentID uuid.UUID) error
+}
+
+// SessionTemplateRepository stores the session plans therapists create sessions from
+type SessionTemplateRepository interface {
+	GetTemplates(ctx context.Context, therapistID uuid.UUID) ([]models.SessionTemplate, error)
+	GetTemplate(ctx context.Context, id uuid.UUID) (*models.SessionTemplate, error)
+	CreateTemplate(ctx context.Context, template *models.SessionTemplate) (*models.SessionTemplate, error)
+	UpdateTemplate(ctx context.Context, template *models.SessionTemplate) (*models.SessionTemplate, error)
+	DeleteTemplate(ctx context.Context, id uuid.UUID) error
+}
+
+// JobRunRepository is the queue background jobs are run from
+type JobRunRepository interface {
+	EnqueueJob(ctx context.Context, input *models.EnqueueJobInput) (*models.JobRun, error)
+	ClaimDueJobs(ctx context.Context, names []string, workerID string, limit int, lease time.Duration) ([]models.JobRun, error)
+	CompleteJob(ctx context.Context, id uuid.UUID, workerID string) error
+	FailJob(ctx context.Context, id uuid.UUID, workerID, lastError string, retryAt *time.Time) error
+	GetJobRuns(ctx context.Context, query *models.GetJobRunsQuery) ([]models.JobRun, error)
+	DeleteFinishedJobRuns(ctx context.Context, before time.Time) (int64, error)
+}
+
+type CalendarFeedRepository interface {
+	CreateCalendarFeed(ctx context.Context, therapistID uuid.UUID, tokenHash string, input *models.CalendarFeedInput) (*models.CalendarFeed, error)
+	GetCalendarFeed(ctx context.Context, therapistID uuid.UUID) (*models.CalendarFeed, error)
+	GetCalendarFeedByToken(ctx context.Context, tokenHash string) (*models.CalendarFeed, error)
+	UpdateCalendarFeed(ctx context.Context, therapistID uuid.UUID, input *models.CalendarFeedInput) (*models.CalendarFeed, error)
+	DeleteCalendarFeed(ctx context.Context, therapistID uuid.UUID) error
+	GetCalendarSessions(ctx context.Context, therapistID uuid.UUID) ([]models.CalendarSession, error)
+	GetCalendarLocation(ctx context.Context, therapistID uuid.UUID) (*time.Location, error)
+}
+
+// NotificationRepository keeps therapists' notification preferences, and which digests and
+// reminders were sent
+type NotificationRepository interface {
+	GetNotificationPreference(ctx context.Context, therapistID uuid.UUID) (*models.NotificationPreference, error)
+	UpdateNotificationPreference(ctx context.Context, therapistID uuid.UUID, input *models.UpdateNotificationPreferenceInput) (*models.NotificationPreference, error)
+	GetNotificationRecipients(ctx context.Context) ([]models.NotificationRecipient, error)
+	ClaimNotification(ctx context.Context, therapistID uuid.UUID, kind, dedupeKey string) (bool, error)
+	ReleaseNotification(ctx context.Context, dedupeKey string) error
+	DeleteSentNotifications(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AccessRepository resolves who owns a resource so handlers can be scoped to the caller
+type AccessRepository interface {
+	GetPrincipal(ctx context.Context, userID uuid.UUID) (*models.Principal, error)
+	GetAccessibleTherapistIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	GetDistrictTherapistIDs(ctx context.Context, districtID int) ([]uuid.UUID, error)
+	GetSessionOwners(ctx context.Context, sessionIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error)
+	GetStudentOwners(ctx context.Context, studentIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error)
+	GetSessionStudentOwners(ctx context.Context, sessionStudentIDs []int) (map[int]uuid.UUID, error)
+	GetSessionTemplateOwners(ctx context.Context, templateIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error)
 }
 
 type AuthRepository interface {
@@ -123,7 +290,21 @@ type Repository struct {
 	School          SchoolRepository
 	Newsletter      NewsletterRepository
 	Verification    VerificationRepository
+	Attempt         AttemptRepository
+	EmailOutbox     EmailOutboxRepository
+	Account         AccountRepository
+	APIKey          APIKeyRepository
+	MFA             MFARepository
+	Invitation      InvitationRepository
+	CalendarFeed    CalendarFeedRepository
+	Notification    NotificationRepository
+	SchoolCalendar  SchoolCalendarRepository
+	ServiceMandate  ServiceMandateRepository
+	SessionNote     SessionNoteRepository
+	SessionTemplate SessionTemplateRepository
+	JobRun          JobRunRepository
 	Auth            AuthRepository
+	Access          AccessRepository
 }
 
 func (r *Repository) Close() error {
@@ -135,7 +316,7 @@ func (r *Repository) GetDB() *pgxpool.Pool {
 	return r.db
 }
 
-func NewRepository(db *pgxpool.Pool) *Repository {
+func NewRepository(db *pgxpool.Pool, verification config.Verification) *Repository {
 	return &Repository{
 		db:              db,
 		Resource:        schema.NewResourceRepository(db),
@@ -150,6 +331,20 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 		District:        schema.NewDistrictRepository(db),
 		School:          schema.NewSchoolRepository(db),
 		Newsletter:      schema.NewNewsletterRepository(db),
-		Verification:    schema.NewVerificationRepository(db),
+		Verification:    schema.NewVerificationRepository(db, verification.CodeKey),
+		Attempt:         schema.NewAttemptRepository(db),
+		EmailOutbox:     schema.NewEmailOutboxRepository(db),
+		Account:         schema.NewAccountRepository(db),
+		APIKey:          schema.NewAPIKeyRepository(db),
+		MFA:             schema.NewMFARepository(db),
+		Invitation:      schema.NewInvitationRepository(db),
+		CalendarFeed:    schema.NewCalendarFeedRepository(db),
+		Notification:    schema.NewNotificationRepository(db),
+		SchoolCalendar:  schema.NewSchoolCalendarRepository(db),
+		ServiceMandate:  schema.NewServiceMandateRepository(db),
+		SessionNote:     schema.NewSessionNoteRepository(db),
+		SessionTemplate: schema.NewSessionTemplateRepository(db),
+		JobRun:          schema.NewJobRunRepository(db),
+		Access:          schema.NewAccessRepository(db),
 	}
 }
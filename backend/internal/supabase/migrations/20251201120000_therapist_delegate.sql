@@ -0,0 +1,10 @@
+-- A delegate (e.g. a covering therapist) may act on another therapist's caseload
+CREATE TABLE IF NOT EXISTS therapist_delegate (
+  therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+  delegate_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (therapist_id, delegate_id),
+  CHECK (therapist_id <> delegate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_therapist_delegate_delegate ON therapist_delegate(delegate_id);
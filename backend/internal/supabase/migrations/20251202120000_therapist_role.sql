@@ -0,0 +1,15 @@
+-- Roles: a therapist works on their own caseload, a district administrator can
+-- additionally read everything in their district, a system administrator everything
+ALTER TABLE therapist
+  ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'therapist';
+
+ALTER TABLE therapist
+  ADD CONSTRAINT therapist_role_check
+  CHECK (role IN ('therapist', 'district_admin', 'system_admin'));
+
+-- A district administrator administers the district on their therapist record
+ALTER TABLE therapist
+  ADD CONSTRAINT therapist_district_admin_district_check
+  CHECK (role <> 'district_admin' OR district_id IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_therapist_district ON therapist(district_id);
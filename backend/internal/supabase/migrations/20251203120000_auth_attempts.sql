@@ -0,0 +1,25 @@
+-- Failed login and verification attempts, keyed by what is being throttled
+-- (e.g. 'login:email:someone@example.com', 'login:ip:203.0.113.7'). Kept in the
+-- database so lockouts survive restarts and are shared between instances.
+CREATE TABLE IF NOT EXISTS auth_attempt (
+  key TEXT PRIMARY KEY,
+  failures INTEGER NOT NULL DEFAULT 0,
+  last_failure_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  locked_until TIMESTAMPTZ
+);
+
+ALTER TABLE auth_attempt ENABLE ROW LEVEL SECURITY;
+
+-- Verification codes are stored as hashes and die after too many wrong guesses.
+-- Codes issued before this migration are plain text and cannot be checked anymore.
+UPDATE verification_codes SET used = true WHERE used = false;
+
+DROP INDEX IF EXISTS idx_verification_codes_code;
+
+ALTER TABLE verification_codes RENAME COLUMN code TO code_hash;
+
+UPDATE verification_codes SET attempts = 0 WHERE attempts IS NULL;
+
+ALTER TABLE verification_codes
+  ALTER COLUMN attempts SET NOT NULL,
+  ADD COLUMN IF NOT EXISTS max_attempts INTEGER NOT NULL DEFAULT 5;
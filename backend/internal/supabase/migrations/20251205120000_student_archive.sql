@@ -0,0 +1,16 @@
+-- Students of a deleted account that were not transferred to another therapist. The
+-- student, their attendance, ratings and game results are kept as a snapshot because the
+-- live rows are removed together with the account.
+CREATE TABLE IF NOT EXISTS student_archive (
+  id UUID PRIMARY KEY,
+  former_therapist_id UUID NOT NULL,
+  school_id INTEGER REFERENCES school(id) ON DELETE SET NULL,
+  first_name VARCHAR(100) NOT NULL,
+  last_name VARCHAR(100) NOT NULL,
+  data JSONB NOT NULL,
+  archived_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE student_archive ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_student_archive_school ON student_archive(school_id);
@@ -0,0 +1,19 @@
+-- Keys for server-to-server integrations such as SIS syncs and reporting jobs. Only a
+-- SHA-256 hash of the key is stored; the key itself is shown once when it is created.
+CREATE TABLE IF NOT EXISTS api_key (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL,
+  prefix TEXT NOT NULL,
+  key_hash TEXT NOT NULL UNIQUE,
+  scopes TEXT[] NOT NULL,
+  district_id INTEGER REFERENCES district(id) ON DELETE CASCADE,
+  created_by UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_used_at TIMESTAMPTZ,
+  expires_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ
+);
+
+ALTER TABLE api_key ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_api_key_created_by ON api_key(created_by);
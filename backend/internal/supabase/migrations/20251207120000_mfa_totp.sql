@@ -0,0 +1,39 @@
+-- Authenticator app (TOTP) second factor. The secret is encrypted by the API with
+-- MFA_ENCRYPTION_KEY. A factor stays disabled until the first code from it is confirmed,
+-- and last_used_step stops a code from being used twice.
+CREATE TABLE IF NOT EXISTS therapist_mfa (
+  therapist_id UUID PRIMARY KEY REFERENCES therapist(id) ON DELETE CASCADE,
+  secret_sealed TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT false,
+  last_used_step BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  confirmed_at TIMESTAMPTZ
+);
+
+-- Single-use codes for when the authenticator app is lost, stored hashed
+CREATE TABLE IF NOT EXISTS mfa_recovery_code (
+  id SERIAL PRIMARY KEY,
+  therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+  code_hash TEXT NOT NULL,
+  used_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- A password login waiting for its second factor. The Supabase session is held here and
+-- only handed out once the factor is passed.
+CREATE TABLE IF NOT EXISTS mfa_challenge (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+  token_hash TEXT NOT NULL UNIQUE,
+  refresh_token TEXT NOT NULL,
+  remember_me BOOLEAN NOT NULL DEFAULT false,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE therapist_mfa ENABLE ROW LEVEL SECURITY;
+ALTER TABLE mfa_recovery_code ENABLE ROW LEVEL SECURITY;
+ALTER TABLE mfa_challenge ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_code_therapist ON mfa_recovery_code(therapist_id);
+CREATE INDEX IF NOT EXISTS idx_mfa_challenge_expires ON mfa_challenge(expires_at);
@@ -0,0 +1,22 @@
+-- Invitations sent by district administrators. The emailed link carries a signed token
+-- naming the invitation, accepting it creates the therapist in the district and schools
+-- chosen here.
+CREATE TABLE IF NOT EXISTS therapist_invitation (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  email VARCHAR(255) NOT NULL,
+  first_name VARCHAR(255),
+  last_name VARCHAR(255),
+  district_id INTEGER NOT NULL REFERENCES district(id) ON DELETE CASCADE,
+  schools INTEGER[] NOT NULL DEFAULT '{}',
+  invited_by UUID REFERENCES therapist(id) ON DELETE SET NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  accepted_at TIMESTAMPTZ,
+  accepted_by UUID REFERENCES therapist(id) ON DELETE SET NULL,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE therapist_invitation ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_therapist_invitation_district ON therapist_invitation(district_id);
+CREATE INDEX IF NOT EXISTS idx_therapist_invitation_email ON therapist_invitation(lower(email));
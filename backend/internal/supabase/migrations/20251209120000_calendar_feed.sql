@@ -0,0 +1,11 @@
+-- Secret iCalendar feed URLs, one per therapist. Only a SHA-256 hash of the token in the
+-- URL is stored; the URL is shown when the feed is created or its token rotated.
+CREATE TABLE IF NOT EXISTS calendar_feed (
+  therapist_id UUID PRIMARY KEY REFERENCES therapist(id) ON DELETE CASCADE,
+  token_hash TEXT NOT NULL UNIQUE,
+  include_student_names BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE calendar_feed ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,24 @@
+-- Days a district, or one school of it, has no classes (holidays, breaks and closures such
+-- as snow days) or lets out early. Recurring sessions are not generated on days without
+-- classes; the dates left out are kept on the session_parent as exceptions.
+CREATE TABLE IF NOT EXISTS school_calendar_event (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  district_id INTEGER NOT NULL REFERENCES district(id) ON DELETE CASCADE,
+  school_id INTEGER REFERENCES school(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  kind VARCHAR(32) NOT NULL CHECK (kind IN ('holiday', 'break', 'closure', 'early_release')),
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  dismissal_time TIME,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT school_calendar_event_dates CHECK (end_date >= start_date),
+  CONSTRAINT school_calendar_event_dismissal CHECK (dismissal_time IS NULL OR kind = 'early_release')
+);
+
+ALTER TABLE school_calendar_event ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_school_calendar_event_district ON school_calendar_event(district_id, start_date);
+
+-- Dates a recurring series skips, such as school holidays
+ALTER TABLE session_parent ADD COLUMN IF NOT EXISTS exception_dates DATE[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,6 @@
+-- Series can repeat by an RFC 5545 recurrence rule instead of every_n_weeks and days.
+-- The rule is kept so the series can be expanded again when it is edited.
+ALTER TABLE session_parent ADD COLUMN IF NOT EXISTS rrule TEXT;
+
+-- Days a series meets on besides those its rule generates (RDATE)
+ALTER TABLE session_parent ADD COLUMN IF NOT EXISTS extra_dates DATE[] NOT NULL DEFAULT '{}';
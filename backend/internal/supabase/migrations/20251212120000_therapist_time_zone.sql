@@ -0,0 +1,4 @@
+-- The IANA time zone a therapist works in. Recurring sessions are expanded in it, so they
+-- keep their time of day across daylight saving changes, and month and year filters on
+-- sessions use the therapist's local dates.
+ALTER TABLE therapist ADD COLUMN IF NOT EXISTS time_zone TEXT NOT NULL DEFAULT 'UTC';
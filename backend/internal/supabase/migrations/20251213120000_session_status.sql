@@ -0,0 +1,6 @@
+-- What became of a session. Missed sessions are kept, with the reason they were missed,
+-- so service delivery reports can tell missed services apart from removed sessions.
+ALTER TABLE session
+  ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'scheduled'
+    CHECK (status IN ('scheduled', 'completed', 'cancelled_by_therapist', 'cancelled_by_school', 'student_absent', 'makeup')),
+  ADD COLUMN IF NOT EXISTS status_reason TEXT;
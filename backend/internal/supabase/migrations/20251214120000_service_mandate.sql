@@ -0,0 +1,19 @@
+-- Service minutes a student's IEP mandates, per week or per month, delivered one on one
+-- or in a group. Compliance reports compare them with the sessions the student attended.
+CREATE TABLE IF NOT EXISTS service_mandate (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  student_id UUID NOT NULL REFERENCES student(id) ON DELETE CASCADE,
+  service VARCHAR(255) NOT NULL,
+  minutes INTEGER NOT NULL CHECK (minutes > 0),
+  frequency VARCHAR(16) NOT NULL CHECK (frequency IN ('weekly', 'monthly')),
+  setting VARCHAR(16) NOT NULL CHECK (setting IN ('individual', 'group')),
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT service_mandate_dates CHECK (end_date >= start_date)
+);
+
+ALTER TABLE service_mandate ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_service_mandate_student ON service_mandate(student_id, start_date);
@@ -0,0 +1,28 @@
+-- Makeup sessions and the minutes students are owed for missed sessions. A student owes
+-- the minutes of a session that was cancelled or that the student missed; a makeup
+-- session the student attends makes them up. The ledger is kept in step with the
+-- sessions by the application.
+ALTER TABLE session
+  ADD COLUMN IF NOT EXISTS makeup_for_session_id UUID REFERENCES session(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_session_makeup_for ON session(makeup_for_session_id)
+  WHERE makeup_for_session_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS makeup_ledger (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  student_id UUID NOT NULL REFERENCES student(id) ON DELETE CASCADE,
+  session_id UUID NOT NULL REFERENCES session(id) ON DELETE CASCADE,
+  kind VARCHAR(16) NOT NULL CHECK (kind IN ('missed', 'made_up')),
+  -- Positive for minutes owed, negative for minutes made up
+  minutes INTEGER NOT NULL,
+  reason VARCHAR(32),
+  makeup_for_session_id UUID REFERENCES session(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT makeup_ledger_session_student UNIQUE (student_id, session_id),
+  CONSTRAINT makeup_ledger_minutes CHECK ((kind = 'missed' AND minutes > 0) OR (kind = 'made_up' AND minutes < 0))
+);
+
+ALTER TABLE makeup_ledger ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_makeup_ledger_student ON makeup_ledger(student_id, created_at);
@@ -0,0 +1,36 @@
+-- Structured SOAP notes on how a session went for one of its students. Notes are drafts
+-- until the therapist signs them, and cannot be changed once signed.
+CREATE TABLE IF NOT EXISTS session_note (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  session_student_id INT NOT NULL UNIQUE REFERENCES session_student(id) ON DELETE CASCADE,
+  subjective TEXT,
+  objective TEXT,
+  assessment TEXT,
+  plan TEXT,
+  custom_fields JSONB NOT NULL DEFAULT '{}',
+  status VARCHAR(16) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'signed')),
+  signed_at TIMESTAMPTZ,
+  signed_by UUID REFERENCES therapist(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT session_note_signature CHECK ((status = 'signed') = (signed_at IS NOT NULL))
+);
+
+ALTER TABLE session_note ENABLE ROW LEVEL SECURITY;
+
+-- The API only changes drafts, this keeps anything else from changing a signed note
+CREATE OR REPLACE FUNCTION lock_signed_session_note()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.status = 'signed' THEN
+        RAISE EXCEPTION 'session note % is signed and cannot be changed', OLD.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER lock_signed_session_note BEFORE UPDATE ON session_note
+    FOR EACH ROW EXECUTE FUNCTION lock_signed_session_note();
+
+CREATE TRIGGER table_accessed_session_note AFTER UPDATE OR INSERT OR DELETE ON session_note
+    FOR EACH ROW EXECUTE FUNCTION log_table_access();
@@ -0,0 +1,28 @@
+-- Session plans a therapist runs again and again: a name, how long the session lasts,
+-- where it is held, the resources used and the game content to play. Sessions created
+-- from a template keep a link to it, and get its resources.
+CREATE TABLE IF NOT EXISTS session_template (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  duration_minutes INTEGER NOT NULL CHECK (duration_minutes > 0 AND duration_minutes <= 480),
+  location VARCHAR(255),
+  game_content_filter JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS session_template_resource (
+  session_template_id UUID NOT NULL REFERENCES session_template(id) ON DELETE CASCADE,
+  resource_id UUID NOT NULL REFERENCES resource(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  PRIMARY KEY (session_template_id, resource_id)
+);
+
+ALTER TABLE session
+  ADD COLUMN IF NOT EXISTS session_template_id UUID REFERENCES session_template(id) ON DELETE SET NULL;
+
+ALTER TABLE session_template ENABLE ROW LEVEL SECURITY;
+ALTER TABLE session_template_resource ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_session_template_therapist ON session_template(therapist_id, name);
@@ -0,0 +1,25 @@
+-- Background jobs, queued for the workers of every server instance. A run is claimed with
+-- SKIP LOCKED and leased to one worker until it finishes or its lease runs out, failed
+-- runs are retried with backoff until max_attempts. Scheduled runs carry a dedupe_key so
+-- every instance can queue the same occurrence and only one run of it is kept.
+CREATE TABLE IF NOT EXISTS job_run (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL,
+  payload JSONB NOT NULL DEFAULT '{}',
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'succeeded', 'failed')),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL DEFAULT 3 CHECK (max_attempts > 0),
+  run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  dedupe_key TEXT UNIQUE,
+  locked_by TEXT,
+  locked_until TIMESTAMPTZ,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ
+);
+
+ALTER TABLE job_run ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_job_run_due ON job_run(run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_job_run_name ON job_run(name, created_at DESC);
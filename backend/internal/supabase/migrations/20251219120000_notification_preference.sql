@@ -0,0 +1,29 @@
+-- How therapists are told about their sessions: a digest of the day's sessions at
+-- digest_time, in the therapist's time zone, and a reminder reminder_lead_minutes before
+-- each one. Nothing is sent until a therapist picks a channel.
+CREATE TABLE IF NOT EXISTS notification_preference (
+  therapist_id UUID PRIMARY KEY REFERENCES therapist(id) ON DELETE CASCADE,
+  channel TEXT NOT NULL DEFAULT 'none' CHECK (channel IN ('email', 'none')),
+  digest_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  digest_time TIME NOT NULL DEFAULT '07:00',
+  reminder_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  reminder_lead_minutes INTEGER NOT NULL DEFAULT 15 CHECK (reminder_lead_minutes BETWEEN 1 AND 1440),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE notification_preference ENABLE ROW LEVEL SECURITY;
+
+-- Digests and reminders already sent, so every server instance sends each one only once.
+-- A reminder's key carries the start of the session, a rescheduled session is reminded of
+-- again.
+CREATE TABLE IF NOT EXISTS notification_sent (
+  dedupe_key TEXT PRIMARY KEY,
+  therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL CHECK (kind IN ('digest', 'reminder')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE notification_sent ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_notification_sent_created_at ON notification_sent(created_at);
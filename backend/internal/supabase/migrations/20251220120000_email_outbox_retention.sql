@@ -0,0 +1,8 @@
+-- Email bodies carry verification codes and password reset links, they are only kept until
+-- the email is sent or given up on. The rows themselves are purged after a retention period.
+ALTER TABLE email_outbox ALTER COLUMN html_body DROP NOT NULL;
+ALTER TABLE email_outbox ALTER COLUMN text_body DROP NOT NULL;
+
+UPDATE email_outbox SET html_body = NULL, text_body = NULL WHERE status IN ('sent', 'failed');
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_created_at ON email_outbox(created_at);
@@ -0,0 +1,12 @@
+-- Supabase sessions that passed the authenticator app at /auth/mfa/verify. Tokens of
+-- therapists with an enabled app are only accepted for these sessions, so a password grant
+-- made straight against Supabase cannot skip the second factor. Rows go with the session.
+CREATE TABLE IF NOT EXISTS mfa_verified_session (
+  session_id UUID PRIMARY KEY REFERENCES auth.sessions(id) ON DELETE CASCADE,
+  therapist_id UUID NOT NULL REFERENCES therapist(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE mfa_verified_session ENABLE ROW LEVEL SECURITY;
+
+CREATE INDEX IF NOT EXISTS idx_mfa_verified_session_therapist ON mfa_verified_session(therapist_id);
@@ -0,0 +1,32 @@
+-- Signed notes are part of the student's record, so deleting a session, its attendance,
+-- the student or the therapist must not take them along through the cascades. Drafts
+-- still go with them. Deleting the therapist who signed a note only clears signed_by.
+CREATE OR REPLACE FUNCTION lock_signed_session_note()
+RETURNS TRIGGER AS $$
+DECLARE
+    unsigned session_note;
+BEGIN
+    IF OLD.status = 'signed' THEN
+        IF TG_OP = 'DELETE' THEN
+            RAISE EXCEPTION 'session note % is signed and cannot be deleted', OLD.id
+                USING ERRCODE = 'restrict_violation';
+        END IF;
+        unsigned := OLD;
+        unsigned.signed_by := NULL;
+        IF NEW IS NOT DISTINCT FROM unsigned THEN
+            RETURN NEW;
+        END IF;
+        RAISE EXCEPTION 'session note % is signed and cannot be changed', OLD.id
+            USING ERRCODE = 'restrict_violation';
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS lock_signed_session_note ON session_note;
+
+CREATE TRIGGER lock_signed_session_note BEFORE UPDATE OR DELETE ON session_note
+    FOR EACH ROW EXECUTE FUNCTION lock_signed_session_note();
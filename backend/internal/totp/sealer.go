@@ -0,0 +1,67 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Sealer encrypts secrets at rest with AES-256-GCM
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer takes a base64 encoded 32 byte key
+func NewSealer(key string) (*Sealer, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not base64: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(raw))
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal encrypts the secret, binding it to owner so a sealed secret cannot be moved to
+// another account
+func (s *Sealer) Seal(secret, owner string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, []byte(secret), []byte(owner))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *Sealer) Open(sealed, owner string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < s.aead.NonceSize() {
+		return "", errors.New("sealed secret is too short")
+	}
+
+	nonce, ciphertext := raw[:s.aead.NonceSize()], raw[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, ciphertext, []byte(owner))
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
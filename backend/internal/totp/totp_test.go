@@ -0,0 +1,98 @@
+package totp
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The SHA1 secret and vectors of RFC 6238 appendix B, truncated to six digits
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238(t *testing.T) {
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(v.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, v.code, code, "at %d", v.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	step, ok := Validate(rfcSecret, "050471", now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// One period of drift either way is accepted, two are not
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	step, ok = Validate(rfcSecret, previous, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now)-1, step)
+
+	stale, _ := Code(rfcSecret, Step(now)-2)
+	_, ok = Validate(rfcSecret, stale, now, 1)
+	assert.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "12345", now, 1)
+	assert.False(t, ok)
+	_, ok = Validate("not base32!", "050471", now, 1)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := Code(secret, Step(time.Now()))
+	require.NoError(t, err)
+	_, ok := Validate(secret, code, time.Now(), 0)
+	assert.True(t, ok)
+
+	uri, err := url.Parse(URI("The Special Standard", "therapist@example.com", secret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/The Special Standard:therapist@example.com", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "The Special Standard", uri.Query().Get("issuer"))
+}
+
+func TestSealer(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	sealer, err := NewSealer(key)
+	require.NoError(t, err)
+
+	sealed, err := sealer.Seal("JBSWY3DPEHPK3PXP", "owner-1")
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "JBSWY3DPEHPK3PXP")
+
+	secret, err := sealer.Open(sealed, "owner-1")
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", secret)
+
+	_, err = sealer.Open(sealed, "owner-2")
+	assert.Error(t, err)
+
+	_, err = NewSealer("c2hvcnQ=")
+	assert.Error(t, err)
+}